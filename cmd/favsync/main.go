@@ -0,0 +1,75 @@
+// Command favsync copies the asset catalog, users and their favorites
+// between two running instances of the service through its HTTP API, e.g.
+// to refresh staging from anonymized production data.
+//
+//	favsync -source https://prod.example.com -target https://staging.example.com \
+//	        -users user1,user2 -rules rules.json -dry-run
+//
+// Credentials are taken from flags or from FAVSYNC_SOURCE_TOKEN,
+// FAVSYNC_SOURCE_ADMIN_KEY, FAVSYNC_TARGET_TOKEN and FAVSYNC_TARGET_ADMIN_KEY.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+)
+
+func main() {
+	source := flag.String("source", "", "base URL of the instance to copy from")
+	target := flag.String("target", "", "base URL of the instance to copy to")
+	users := flag.String("users", "", "comma-separated user IDs to copy")
+	rulesFile := flag.String("rules", "", "optional JSON file with mapping rules")
+	dryRun := flag.Bool("dry-run", false, "report what would be copied without writing to the target")
+	pageSize := flag.Int("page-size", 100, "assets and favorites fetched per request")
+	catalog := flag.Bool("catalog", true, "copy the whole asset catalog, which needs admin credentials")
+	sourceToken := flag.String("source-token", os.Getenv("FAVSYNC_SOURCE_TOKEN"), "bearer token for the source")
+	sourceAdminKey := flag.String("source-admin-key", os.Getenv("FAVSYNC_SOURCE_ADMIN_KEY"), "X-Admin-Key for the source")
+	targetToken := flag.String("target-token", os.Getenv("FAVSYNC_TARGET_TOKEN"), "bearer token for the target")
+	targetAdminKey := flag.String("target-admin-key", os.Getenv("FAVSYNC_TARGET_ADMIN_KEY"), "X-Admin-Key for the target")
+	flag.Parse()
+
+	log := logger.NewLogger()
+
+	if *source == "" || *target == "" || *users == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	rules := Rules{}
+	if *rulesFile != "" {
+		data, err := os.ReadFile(*rulesFile)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to read rules file")
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			log.WithError(err).Fatal("Failed to parse rules file")
+		}
+	}
+
+	syncer := &Syncer{
+		client:   httpclient.NewDefault(),
+		source:   Endpoint{URL: strings.TrimRight(*source, "/"), Token: *sourceToken, AdminKey: *sourceAdminKey},
+		target:   Endpoint{URL: strings.TrimRight(*target, "/"), Token: *targetToken, AdminKey: *targetAdminKey},
+		rules:    rules,
+		catalog:  *catalog,
+		dryRun:   *dryRun,
+		pageSize: *pageSize,
+		logger:   log,
+	}
+
+	report := syncer.Run(context.Background(), strings.Split(*users, ","))
+
+	log.WithField("dry_run", *dryRun).WithField("copied", report.Copied).
+		WithField("skipped", report.Skipped).WithField("failed", report.Failed).
+		Info("Sync finished")
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
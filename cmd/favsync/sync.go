@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gwi-favorites-service/pkg/httpclient"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Rules map source identifiers onto the target environment
+type Rules struct {
+	// Users renames user IDs; users not listed keep their ID
+	Users map[string]string `json:"users"`
+	// AssetIDPrefix is prepended to every copied asset ID
+	AssetIDPrefix string `json:"asset_id_prefix"`
+	// SkipTypes lists asset types that are not copied
+	SkipTypes []string `json:"skip_types"`
+	// ClearDescriptions blanks asset descriptions on the target
+	ClearDescriptions bool `json:"clear_descriptions"`
+	// AnonymizeUsers replaces each user's email and name with ones derived
+	// from their target ID
+	AnonymizeUsers bool `json:"anonymize_users"`
+}
+
+// Report summarises a sync run
+type Report struct {
+	Copied  int
+	Skipped int
+	Failed  int
+}
+
+// count adds the outcome of one write to the target
+func (r *Report) count(log *logrus.Entry, status int, err error) {
+	switch {
+	case err != nil:
+		log.WithError(err).Error("Failed to copy")
+		r.Failed++
+	case status == http.StatusConflict:
+		log.Debug("Already exists on target")
+		r.Skipped++
+	case status == http.StatusCreated:
+		r.Copied++
+	default:
+		log.WithField("status", status).Error("Target rejected copy")
+		r.Failed++
+	}
+}
+
+// Endpoint is a running instance and the credentials to call it with
+type Endpoint struct {
+	URL string
+	// Token is sent as a bearer token when set
+	Token string
+	// AdminKey is sent as X-Admin-Key when set; the catalog routes need it
+	// unless Token carries the admin role
+	AdminKey string
+}
+
+func (e Endpoint) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Always request full assets, including chart data
+	req.Header.Set("X-API-Version", "v2")
+	if e.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.Token)
+	}
+	if e.AdminKey != "" {
+		req.Header.Set("X-Admin-Key", e.AdminKey)
+	}
+	return req, nil
+}
+
+// Syncer copies the catalog, users and their favorites from one instance
+// to another
+type Syncer struct {
+	client   *httpclient.Client
+	source   Endpoint
+	target   Endpoint
+	rules    Rules
+	catalog  bool
+	dryRun   bool
+	pageSize int
+	logger   *logrus.Logger
+}
+
+// response is the envelope of every API response
+type response struct {
+	Success    bool            `json:"success"`
+	Data       json.RawMessage `json:"data"`
+	Pagination *struct {
+		NextOffset *int `json:"next_offset"`
+		HasMore    bool `json:"has_more"`
+	} `json:"pagination"`
+	Error string `json:"error"`
+}
+
+type assetPage struct {
+	Assets     []map[string]interface{} `json:"assets"`
+	NextOffset *int                     `json:"next_offset"`
+	HasMore    bool                     `json:"has_more"`
+}
+
+type favorite struct {
+	AssetID string                 `json:"asset_id"`
+	Asset   map[string]interface{} `json:"asset"`
+}
+
+type user struct {
+	ID    string `json:"id"`
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Run copies the catalog, when enabled, then every listed user and their
+// favorites
+func (s *Syncer) Run(ctx context.Context, userIDs []string) Report {
+	var report Report
+
+	if s.catalog {
+		s.syncCatalog(ctx, &report)
+	}
+
+	for _, userID := range userIDs {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+
+		targetUser := userID
+		if mapped, exists := s.rules.Users[userID]; exists {
+			targetUser = mapped
+		}
+
+		log := s.logger.WithFields(logrus.Fields{"source_user": userID, "target_user": targetUser})
+
+		if !s.syncUser(ctx, log, userID, targetUser, &report) {
+			continue
+		}
+
+		assets, err := s.fetchFavorites(ctx, userID)
+		if err != nil {
+			log.WithError(err).Error("Failed to read favorites from source")
+			report.Failed++
+			continue
+		}
+
+		for _, asset := range assets {
+			if !s.apply(asset) {
+				report.Skipped++
+				continue
+			}
+
+			assetLog := log.WithField("asset_id", asset["id"])
+			if s.dryRun {
+				assetLog.Info("Would copy favorite")
+				report.Copied++
+				continue
+			}
+
+			status, err := s.post(ctx, fmt.Sprintf("/api/users/%s/favorites", url.PathEscape(targetUser)), asset)
+			report.count(assetLog, status, err)
+		}
+	}
+
+	return report
+}
+
+// syncCatalog copies every asset of the source catalog, deleted ones aside
+func (s *Syncer) syncCatalog(ctx context.Context, report *Report) {
+	for offset := 0; ; {
+		var page assetPage
+		_, err := s.get(ctx, fmt.Sprintf("/api/admin/assets?limit=%d&offset=%d", s.pageSize, offset), &page)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to read catalog from source")
+			report.Failed++
+			return
+		}
+
+		for _, asset := range page.Assets {
+			if asset["deleted_at"] != nil || !s.apply(asset) {
+				report.Skipped++
+				continue
+			}
+
+			assetLog := s.logger.WithField("asset_id", asset["id"])
+			if s.dryRun {
+				assetLog.Info("Would copy asset")
+				report.Copied++
+				continue
+			}
+
+			status, err := s.post(ctx, "/api/admin/assets", asset)
+			report.count(assetLog, status, err)
+		}
+
+		if !page.HasMore || page.NextOffset == nil {
+			return
+		}
+		offset = *page.NextOffset
+	}
+}
+
+// syncUser creates the user on the target and reports whether their
+// favorites can be copied
+func (s *Syncer) syncUser(ctx context.Context, log *logrus.Entry, userID, targetUser string, report *Report) bool {
+	var source user
+	if _, err := s.get(ctx, "/api/users/"+url.PathEscape(userID), &source); err != nil {
+		log.WithError(err).Error("Failed to read user from source")
+		report.Failed++
+		return false
+	}
+	if s.dryRun {
+		log.Info("Would copy user")
+		report.Copied++
+		return true
+	}
+
+	status, err := s.post(ctx, "/api/users", s.applyUser(source, targetUser))
+	report.count(log, status, err)
+	return err == nil && (status == http.StatusCreated || status == http.StatusConflict)
+}
+
+// fetchFavorites pages through all of a user's favorites on the source
+func (s *Syncer) fetchFavorites(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	var assets []map[string]interface{}
+
+	for offset := 0; ; {
+		var favorites []favorite
+		page, err := s.get(ctx, fmt.Sprintf("/api/users/%s/favorites?limit=%d&offset=%d",
+			url.PathEscape(userID), s.pageSize, offset), &favorites)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, favorite := range favorites {
+			assets = append(assets, favorite.Asset)
+		}
+		if page.Pagination == nil || !page.Pagination.HasMore || page.Pagination.NextOffset == nil {
+			return assets, nil
+		}
+		offset = *page.Pagination.NextOffset
+	}
+}
+
+// get reads path from the source, decoding the response data into data
+func (s *Syncer) get(ctx context.Context, path string, data interface{}) (*response, error) {
+	req, err := s.source.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page response
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source returned %d: %s", resp.StatusCode, page.Error)
+	}
+	if err := json.Unmarshal(page.Data, data); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// post writes body to path on the target and returns the status code
+func (s *Syncer) post(ctx context.Context, path string, body interface{}) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := s.target.newRequest(ctx, http.MethodPost, path, data)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// apply rewrites an asset according to the rules and reports whether it should be copied
+func (s *Syncer) apply(asset map[string]interface{}) bool {
+	assetType, _ := asset["type"].(string)
+	for _, skip := range s.rules.SkipTypes {
+		if skip == assetType {
+			return false
+		}
+	}
+
+	if id, ok := asset["id"].(string); ok {
+		asset["id"] = s.rules.AssetIDPrefix + id
+	}
+	if s.rules.ClearDescriptions {
+		asset["description"] = ""
+	}
+
+	// Timestamps are assigned by the target, and offloaded data is stored
+	// where only the source can read it
+	delete(asset, "created_at")
+	delete(asset, "updated_at")
+	delete(asset, "data_ref")
+	return true
+}
+
+// applyUser returns the user to create on the target for source. The
+// anonymous email uses the reserved .invalid domain, so nothing sent to it
+// reaches anyone.
+func (s *Syncer) applyUser(source user, targetUser string) user {
+	if s.rules.AnonymizeUsers {
+		return user{ID: targetUser, Email: targetUser + "@example.invalid", Name: "User " + targetUser}
+	}
+	return user{ID: targetUser, Email: source.Email, Name: source.Name}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminKey = "favsync-admin-key"
+
+// instance is a running instance reduced to the routes favsync calls
+type instance struct {
+	mu        sync.Mutex
+	users     map[string]user
+	catalog   []map[string]interface{}
+	favorites map[string][]map[string]interface{}
+}
+
+// newInstance serves an empty instance over HTTP
+func newInstance(t *testing.T) (*instance, Endpoint) {
+	t.Helper()
+	inst := &instance{users: make(map[string]user), favorites: make(map[string][]map[string]interface{})}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/admin/assets", inst.listCatalog).Methods("GET")
+	r.HandleFunc("/api/admin/assets", inst.createAsset).Methods("POST")
+	r.HandleFunc("/api/users", inst.createUser).Methods("POST")
+	r.HandleFunc("/api/users/{userID}", inst.getUser).Methods("GET")
+	r.HandleFunc("/api/users/{userID}/favorites", inst.listFavorites).Methods("GET")
+	r.HandleFunc("/api/users/{userID}/favorites", inst.addFavorite).Methods("POST")
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return inst, Endpoint{URL: server.URL, AdminKey: testAdminKey}
+}
+
+func reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// page returns the window of n entries selected by the limit and offset
+// query parameters, and the offset of the next page when there is one
+func page(r *http.Request, n int) (from, to int, next *int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	from, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	from = min(from, n)
+	to = min(from+limit, n)
+	if to < n {
+		next = &to
+	}
+	return from, to, next
+}
+
+func (inst *instance) listCatalog(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Admin-Key") != testAdminKey {
+		reply(w, http.StatusUnauthorized, map[string]interface{}{"success": false, "error": "unauthorized"})
+		return
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	from, to, next := page(r, len(inst.catalog))
+	reply(w, http.StatusOK, map[string]interface{}{"success": true, "data": map[string]interface{}{
+		"assets": inst.catalog[from:to], "next_offset": next, "has_more": next != nil,
+	}})
+}
+
+func (inst *instance) createAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Admin-Key") != testAdminKey {
+		reply(w, http.StatusUnauthorized, map[string]interface{}{"success": false, "error": "unauthorized"})
+		return
+	}
+	var asset map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&asset); err != nil {
+		reply(w, http.StatusBadRequest, map[string]interface{}{"success": false})
+		return
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.asset(asset["id"]) != nil {
+		reply(w, http.StatusConflict, map[string]interface{}{"success": false})
+		return
+	}
+	inst.catalog = append(inst.catalog, asset)
+	reply(w, http.StatusCreated, map[string]interface{}{"success": true, "data": asset})
+}
+
+func (inst *instance) createUser(w http.ResponseWriter, r *http.Request) {
+	var u user
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		reply(w, http.StatusBadRequest, map[string]interface{}{"success": false})
+		return
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if _, exists := inst.users[u.ID]; exists {
+		reply(w, http.StatusConflict, map[string]interface{}{"success": false})
+		return
+	}
+	inst.users[u.ID] = u
+	reply(w, http.StatusCreated, map[string]interface{}{"success": true, "data": u})
+}
+
+func (inst *instance) getUser(w http.ResponseWriter, r *http.Request) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	u, exists := inst.users[mux.Vars(r)["userID"]]
+	if !exists {
+		reply(w, http.StatusNotFound, map[string]interface{}{"success": false, "error": "user not found"})
+		return
+	}
+	reply(w, http.StatusOK, map[string]interface{}{"success": true, "data": u})
+}
+
+func (inst *instance) listFavorites(w http.ResponseWriter, r *http.Request) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	assets := inst.favorites[mux.Vars(r)["userID"]]
+	from, to, next := page(r, len(assets))
+	favorites := make([]favorite, 0, to-from)
+	for _, asset := range assets[from:to] {
+		favorites = append(favorites, favorite{AssetID: asset["id"].(string), Asset: asset})
+	}
+	reply(w, http.StatusOK, map[string]interface{}{"success": true, "data": favorites,
+		"pagination": map[string]interface{}{"next_offset": next, "has_more": next != nil}})
+}
+
+func (inst *instance) addFavorite(w http.ResponseWriter, r *http.Request) {
+	var asset map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&asset); err != nil {
+		reply(w, http.StatusBadRequest, map[string]interface{}{"success": false})
+		return
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	userID := mux.Vars(r)["userID"]
+	for _, favorite := range inst.favorites[userID] {
+		if favorite["id"] == asset["id"] {
+			reply(w, http.StatusConflict, map[string]interface{}{"success": false})
+			return
+		}
+	}
+	inst.favorites[userID] = append(inst.favorites[userID], asset)
+	reply(w, http.StatusCreated, map[string]interface{}{"success": true})
+}
+
+// asset returns the catalog asset with the given ID, or nil
+func (inst *instance) asset(id interface{}) map[string]interface{} {
+	for _, asset := range inst.catalog {
+		if asset["id"] == id {
+			return asset
+		}
+	}
+	return nil
+}
+
+// newSource serves five catalog assets, one of them deleted, and user1
+// with four favorites
+func newSource(t *testing.T) Endpoint {
+	t.Helper()
+	inst, endpoint := newInstance(t)
+	inst.users["user1"] = user{ID: "user1", Email: "real@corp.com", Name: "Real Person"}
+
+	inst.catalog = []map[string]interface{}{
+		{"id": "chart1", "type": "chart", "title": "Sales", "description": "Private notes", "created_at": "2025-01-01T00:00:00Z"},
+		{"id": "chart2", "type": "chart", "title": "Visits"},
+		{"id": "insight1", "type": "insight", "content": "Most users are mobile"},
+		{"id": "audience1", "type": "audience", "description": "Gen Z"},
+		{"id": "chart3", "type": "chart", "title": "Retired", "deleted_at": "2025-01-01T00:00:00Z"},
+	}
+	for _, asset := range inst.catalog[:4] {
+		copied := make(map[string]interface{}, len(asset))
+		for key, value := range asset {
+			copied[key] = value
+		}
+		inst.favorites["user1"] = append(inst.favorites["user1"], copied)
+	}
+	return endpoint
+}
+
+func newSyncer(source, target Endpoint, rules Rules, dryRun bool) *Syncer {
+	return &Syncer{
+		client:   httpclient.New(httpclient.Config{Timeout: 5 * time.Second}),
+		source:   source,
+		target:   target,
+		rules:    rules,
+		catalog:  true,
+		dryRun:   dryRun,
+		pageSize: 2,
+		logger:   logger.NewLogger(),
+	}
+}
+
+var testRules = Rules{
+	Users:             map[string]string{"user1": "stg1"},
+	AssetIDPrefix:     "stg-",
+	SkipTypes:         []string{"audience"},
+	ClearDescriptions: true,
+	AnonymizeUsers:    true,
+}
+
+func TestSyncer_CopiesCatalogUsersAndFavorites(t *testing.T) {
+	source := newSource(t)
+	target, targetEndpoint := newInstance(t)
+	syncer := newSyncer(source, targetEndpoint, testRules, false)
+
+	// Three catalog assets, the user and three favorites are copied across
+	// pages of two; the deleted asset and the audience twice are skipped
+	report := syncer.Run(context.Background(), []string{"user1"})
+	assert.Equal(t, Report{Copied: 7, Skipped: 3}, report)
+
+	chart := target.asset("stg-chart1")
+	require.NotNil(t, chart)
+	assert.Empty(t, chart["description"], "descriptions are cleared")
+	assert.NotContains(t, chart, "created_at", "timestamps are left to the target")
+	assert.Nil(t, target.asset("stg-chart3"), "deleted assets are not copied")
+	assert.Nil(t, target.asset("stg-audience1"), "skipped types are not copied")
+
+	assert.Equal(t, user{ID: "stg1", Email: "stg1@example.invalid", Name: "User stg1"}, target.users["stg1"])
+
+	ids := make([]interface{}, 0, len(target.favorites["stg1"]))
+	for _, favorite := range target.favorites["stg1"] {
+		ids = append(ids, favorite["id"])
+	}
+	assert.ElementsMatch(t, []interface{}{"stg-chart1", "stg-chart2", "stg-insight1"}, ids)
+
+	// A second run finds everything on the target already
+	report = syncer.Run(context.Background(), []string{"user1"})
+	assert.Equal(t, Report{Skipped: 10}, report)
+}
+
+func TestSyncer_KeepsUserDetailsWithoutAnonymizing(t *testing.T) {
+	source := newSource(t)
+	target, targetEndpoint := newInstance(t)
+
+	report := newSyncer(source, targetEndpoint, Rules{}, false).Run(context.Background(), []string{"user1"})
+	assert.Equal(t, 0, report.Failed)
+	assert.Equal(t, user{ID: "user1", Email: "real@corp.com", Name: "Real Person"}, target.users["user1"])
+}
+
+func TestSyncer_DryRunWritesNothing(t *testing.T) {
+	source := newSource(t)
+	target, targetEndpoint := newInstance(t)
+
+	report := newSyncer(source, targetEndpoint, testRules, true).Run(context.Background(), []string{"user1", " "})
+	assert.Equal(t, Report{Copied: 7, Skipped: 3}, report)
+
+	assert.Empty(t, target.users)
+	assert.Empty(t, target.catalog)
+	assert.Empty(t, target.favorites)
+}
+
+func TestSyncer_ReportsUnknownUsers(t *testing.T) {
+	source := newSource(t)
+	_, targetEndpoint := newInstance(t)
+	syncer := newSyncer(source, targetEndpoint, testRules, false)
+	syncer.catalog = false
+
+	report := syncer.Run(context.Background(), []string{"missing"})
+	assert.Equal(t, Report{Failed: 1}, report)
+}
+
+func TestSyncer_CatalogNeedsAdminKey(t *testing.T) {
+	source := newSource(t)
+	source.AdminKey = ""
+	_, targetEndpoint := newInstance(t)
+
+	report := newSyncer(source, targetEndpoint, testRules, false).Run(context.Background(), nil)
+	assert.Equal(t, Report{Failed: 1}, report)
+}
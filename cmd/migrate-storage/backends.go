@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/cache"
+	"gwi-favorites-service/internal/repository/cassandra"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/mysql"
+	"gwi-favorites-service/internal/repository/postgres"
+	"gwi-favorites-service/internal/repository/redis"
+	"gwi-favorites-service/internal/repository/sqlite"
+)
+
+// Settings apply to backends whose location alone is not enough to connect
+type Settings struct {
+	RedisPassword     string
+	RedisDB           int
+	RedisKeyPrefix    string
+	CacheTTL          time.Duration
+	CassandraKeyspace string
+}
+
+// Store is an opened backend
+type Store struct {
+	Repo  repository.FavoritesRepository
+	close func() error
+	once  sync.Once
+	err   error
+}
+
+// Close releases the backend; it is safe to call more than once
+func (s *Store) Close() error {
+	s.once.Do(func() { s.err = s.close() })
+	return s.err
+}
+
+// IsCache reports whether spec names the Redis read cache
+func IsCache(spec string) bool {
+	return strings.HasPrefix(spec, "cache:")
+}
+
+// Open opens the backend named by spec, kind:location
+func Open(spec string, settings Settings) (*Store, error) {
+	kind, location, found := strings.Cut(spec, ":")
+	if !found || location == "" {
+		return nil, fmt.Errorf("backend %q: expected kind:location", spec)
+	}
+
+	switch kind {
+	case "memory":
+		// The snapshot is loaded when present and saved on close
+		repo := memory.NewRepository()
+		if _, err := repo.LoadSnapshot(location); err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: func() error { return repo.SaveSnapshot(location) }}, nil
+	case "postgres":
+		repo, err := postgres.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	case "mysql":
+		repo, err := mysql.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	case "sqlite":
+		repo, err := sqlite.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	case "bolt":
+		repo, err := embedded.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	case "redis":
+		repo, err := redis.Open(redis.Options{
+			Addr:      location,
+			Password:  settings.RedisPassword,
+			DB:        settings.RedisDB,
+			KeyPrefix: settings.RedisKeyPrefix,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	case "cassandra":
+		repo, err := cassandra.Open(cassandra.Options{
+			Hosts:    strings.Split(location, ","),
+			Keyspace: settings.CassandraKeyspace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Store{Repo: repo, close: repo.Close}, nil
+	default:
+		return nil, fmt.Errorf("backend %q: unknown kind %q", spec, kind)
+	}
+}
+
+// OpenCache opens the Redis read cache over backend, with the key prefix
+// the server uses
+func OpenCache(spec string, backend repository.FavoritesRepository, settings Settings, onError func(error)) (*Store, error) {
+	addr := strings.TrimPrefix(spec, "cache:")
+	repo, err := cache.Open(backend, cache.Options{
+		Addr:      addr,
+		Password:  settings.RedisPassword,
+		DB:        settings.RedisDB,
+		KeyPrefix: settings.RedisKeyPrefix + "cache:",
+		TTL:       settings.CacheTTL,
+		OnError:   onError,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Repo: repo, close: func() error { repo.Close(); return nil }}, nil
+}
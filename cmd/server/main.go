@@ -2,19 +2,61 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"gwi-favorites-service/internal/anomaly"
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/backup"
+	"gwi-favorites-service/internal/blobstore"
+	"gwi-favorites-service/internal/capture"
+	"gwi-favorites-service/internal/catalog"
 	"gwi-favorites-service/internal/config"
+	"gwi-favorites-service/internal/deprecation"
+	"gwi-favorites-service/internal/directory"
 	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/engagement"
+	"gwi-favorites-service/internal/experiment"
 	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/health"
+	"gwi-favorites-service/internal/kpi"
+	"gwi-favorites-service/internal/moderation"
+	"gwi-favorites-service/internal/notification"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/cache"
+	"gwi-favorites-service/internal/repository/cassandra"
+	"gwi-favorites-service/internal/repository/embedded"
 	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/mysql"
+	"gwi-favorites-service/internal/repository/offload"
+	"gwi-favorites-service/internal/repository/postgres"
+	"gwi-favorites-service/internal/repository/redis"
+	"gwi-favorites-service/internal/repository/sharded"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/seed"
 	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/sharing"
+	"gwi-favorites-service/internal/taxonomy"
+	"gwi-favorites-service/internal/trash"
+	"gwi-favorites-service/internal/usage"
+	"gwi-favorites-service/internal/validation"
+	"gwi-favorites-service/pkg/handoff"
+	"gwi-favorites-service/pkg/httpclient"
 	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/metrics"
+	"gwi-favorites-service/pkg/retry"
+	"gwi-favorites-service/pkg/s3"
 
 	"github.com/sirupsen/logrus"
 )
@@ -24,20 +66,328 @@ func main() {
 	log := logger.NewLogger()
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load configuration")
+	}
 	log.WithField("port", cfg.Port).Info("Starting GWI Favorites Service")
 
-	// Initialize repository
-	repo := memory.NewRepository()
+	// Take over the sockets of the process this one replaces, if any. Its
+	// storage may only be ready once it has stopped, so wait for it then.
+	listeners, err := handoff.New()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to inherit listeners")
+	}
+	if listeners.Inherited() && handsOffState(cfg) {
+		if err := listeners.Ready(); err != nil {
+			log.WithError(err).Fatal("Failed to report ready to the previous process")
+		}
+		log.Info("Waiting for the previous process to save its state")
+		if err := listeners.WaitRelease(context.Background()); err != nil {
+			log.WithError(err).Fatal("Restart handoff failed")
+		}
+	}
+
+	// Initialize repository, waiting for it to come up when configured
+	store, err := waitForStorage(cfg, log)
+	if err != nil {
+		log.WithError(err).WithField("backend", cfg.StorageBackend).Fatal("Failed to initialize storage backend")
+	}
+	repo := store.repo
+	log.WithField("backend", cfg.StorageBackend).Info("Storage backend initialized")
+
+	// Seed generated sample data, unless a snapshot brought back earlier
+	// data or this is a read-only replica
+	seedProfiles, err := seed.LoadProfiles(cfg.SeedProfileDir)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load seed profiles")
+	}
+	seedProfile, err := seedProfiles.Get(cfg.SeedProfile)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid seed profile")
+	}
+	if cfg.SeedScale <= 0 {
+		log.WithField("scale", cfg.SeedScale).Fatal("Invalid seed scale")
+	}
+	generator := seed.NewGenerator(repo, log)
+	if seedOpts := seedProfile.Scale(cfg.SeedScale); (seedOpts.Users > 0 || seedOpts.Assets > 0) && !store.restored && !cfg.ReadOnly {
+		log.WithField("profile", seedProfile.Name).Info("Seeding sample data")
+		if _, err := generator.Generate(seedOpts); err != nil {
+			log.WithError(err).Error("Failed to seed sample data")
+		}
+	}
+
+	auditLog, closeAuditLog, err := openAuditLog(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to open audit log")
+	}
+	defer func() {
+		if err := closeAuditLog(); err != nil {
+			log.WithError(err).Error("Failed to close audit log")
+		}
+	}()
+
+	// Initialize services
+	detector := anomaly.NewDetector(anomaly.Options{
+		Window:    cfg.AnomalyWindow,
+		Threshold: cfg.AnomalyThreshold,
+		Cooldown:  cfg.AnomalyCooldown,
+	}, securityEventSink(log))
+	moderationStore := moderation.NewStore(cfg.ModerationReportThreshold)
+	lengthPolicy, err := validation.ParseLengthPolicy(cfg.LengthPolicy)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid length policy")
+	}
+	lengthLimits := validation.LengthLimits{
+		Description: cfg.MaxDescriptionLength,
+		Content:     cfg.MaxContentLength,
+		Policy:      lengthPolicy,
+	}
+	conflictPolicy, err := service.ParseConflictPolicy(cfg.NoteConflictPolicy)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid note conflict policy")
+	}
+	typeRules, err := service.ParseAssetTypeRules(cfg.BlockedAssetTypes)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid blocked asset type configuration")
+	}
+	favoritesOptions := []service.FavoritesOption{
+		service.WithAnomalyDetector(detector),
+		service.WithModeration(moderationStore),
+		service.WithLengthLimits(lengthLimits),
+		service.WithChartCoercion(cfg.ChartDataCoercion),
+		service.WithAuditLog(auditLog),
+		service.WithConflictPolicy(conflictPolicy),
+		service.WithAssetTypeRules(typeRules),
+	}
+	users, err := userDirectory(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid user directory configuration")
+	}
+	if users != nil {
+		favoritesOptions = append(favoritesOptions, service.WithUserDirectory(users))
+	}
+	stopKPIs := func() {}
+	if cfg.BusinessMetrics {
+		var tracker *kpi.Tracker
+		tracker, stopKPIs, err = businessMetrics(cfg, repo, log)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid business metrics configuration")
+		}
+		favoritesOptions = append(favoritesOptions, service.WithKPITracker(tracker))
+	}
+	var notifier *engagement.Notifier
+	stopWebhooks := func() {}
+	if cfg.OwnerWebhooks {
+		if cfg.OwnerWebhookInterval <= 0 {
+			log.Fatal("OWNER_WEBHOOK_INTERVAL must be positive")
+		}
+		var notifierOptions []engagement.Option
+		if cfg.OwnerWebhookPrivateTargets {
+			notifierOptions = append(notifierOptions, engagement.WithPrivateTargets())
+		}
+		notifier = engagement.NewNotifier(httpclient.DefaultConfig(), log, notifierOptions...)
+		stopWebhooks = notifier.Start(cfg.OwnerWebhookInterval)
+		favoritesOptions = append(favoritesOptions, service.WithEngagement(notifier))
+	}
+	var trashStore *trash.Store
+	if cfg.TrashTTL > 0 {
+		trashStore = trash.NewStore(cfg.TrashTTL, cfg.TrashPerUser, nil)
+		favoritesOptions = append(favoritesOptions, service.WithTrash(trashStore))
+	}
+	favoritesService := service.NewFavoritesService(repo, log, favoritesOptions...)
+	var shares *sharing.Store
+	if cfg.ShareLinks {
+		shares = sharing.NewStore(nil)
+	}
+	storageService := service.NewStorageService(repo, log)
+	stopIntegrityChecks := func() {}
+	if cfg.IntegrityCheckInterval > 0 {
+		// Replicas leave repairs to the primary
+		repair := cfg.IntegrityRepair && !cfg.ReadOnly
+		// Failed checks are logged by the storage service
+		stopIntegrityChecks = storageService.StartIntegrityChecks(cfg.IntegrityCheckInterval, repair, func(report repository.IntegrityReport, err error) {
+			if err == nil {
+				handler.RecordIntegrityCheck(report)
+			}
+		})
+	}
+	deletePolicy, err := service.ParseDeletePolicy(cfg.AssetDeletePolicy)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid asset delete policy")
+	}
+	assetOptions := []service.AssetOption{
+		service.WithAssetLengthLimits(lengthLimits),
+		service.WithAssetChartCoercion(cfg.ChartDataCoercion),
+		service.WithAssetAuditLog(auditLog),
+		service.WithAssetModeration(moderationStore),
+	}
+	var notices *notification.Store
+	if deletePolicy == service.DeleteGrace {
+		if cfg.AssetDeleteGrace <= 0 || cfg.AssetRemovalInterval <= 0 {
+			log.Fatal("ASSET_DELETE_GRACE and ASSET_REMOVAL_INTERVAL must be positive")
+		}
+		notices = notification.NewStore(cfg.NotificationsPerUser)
+		assetOptions = append(assetOptions,
+			service.WithAssetGracePeriod(cfg.AssetDeleteGrace),
+			service.WithAssetNotifications(notices),
+		)
+	}
+	// Read-only replicas leave catalog syncs and removals to the primary
+	if cfg.AssetSourceURL != "" && !cfg.ReadOnly {
+		assetOptions = append(assetOptions, service.WithAssetSource(
+			catalog.NewHTTPSource(httpclient.NewDefault(), cfg.AssetSourceURL, cfg.AssetSourceToken),
+		))
+	}
+	assetService := service.NewAssetService(repo, deletePolicy, log, assetOptions...)
+	stopRemovals := func() {}
+	if deletePolicy == service.DeleteGrace && !cfg.ReadOnly {
+		stopRemovals = assetService.StartRemovals(cfg.AssetRemovalInterval)
+	}
+	moderationService := service.NewModerationService(repo, moderationStore, log)
+
+	// Request logging policy
+	logPolicy, err := handler.ParseRequestLogPolicy(cfg.LogSampleRate, cfg.LogSlowThreshold.String(), cfg.LogErrorsOnly, cfg.LogRouteLevels)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid request logging configuration")
+	}
+	tracePolicy, err := handler.ParseTraceSamplingPolicy(cfg.TraceSampleRate, cfg.TraceRouteSampleRates)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid trace sampling configuration")
+	}
+	pathPrefix, err := handler.ParsePathPrefix(cfg.PathPrefix)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid path prefix")
+	}
+
+	experiments, err := experiment.Parse(cfg.Experiments)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid experiment configuration")
+	}
+
+	deprecations, err := deprecation.Parse(cfg.Deprecations)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid deprecation configuration")
+	}
+
+	userIDRule, err := validation.ParseIDRule(cfg.UserIDPattern, cfg.IDMaxLength)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid user ID validation configuration")
+	}
+	assetIDRule, err := validation.ParseIDRule(cfg.AssetIDPattern, cfg.IDMaxLength)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid asset ID validation configuration")
+	}
+
+	corsPolicy := handler.DefaultCORSPolicy()
+	corsPolicy.AllowedOrigins = cfg.CORSAllowedOrigins
+	corsPolicy.AllowedHeaders = cfg.CORSAllowedHeaders
+	corsPolicy.MaxAge = cfg.CORSMaxAge
+	corsPolicy.AllowCredentials = cfg.CORSAllowCredentials
+	if err := corsPolicy.Validate(); err != nil {
+		log.WithError(err).Fatal("Invalid CORS configuration")
+	}
+
+	accessOptions, err := authOptions(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid authentication or rate limit configuration")
+	}
+	if cfg.AdminAPIKey == "" && (cfg.AuthMode == "none" || cfg.AdminRole == "") {
+		log.Warn("Admin routes are disabled; set ADMIN_API_KEY, or enable authentication with AUTH_ADMIN_ROLE")
+	}
+
+	if cfg.LoginEnabled {
+		if cfg.AuthMode != "hs256" {
+			log.WithField("auth_mode", cfg.AuthMode).Fatal("Login requires AUTH_MODE=hs256")
+		}
+		credentials := auth.NewCredentials(cfg.LoginPasswords, cfg.LoginRoles, cfg.LoginDefaultPassword)
+		issuer := auth.NewIssuer(cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+		accessOptions = append(accessOptions, handler.WithAuthService(service.NewAuthService(repo, credentials, issuer, log)))
+	}
+
+	var userOptions []service.UserOption
+	if trashStore != nil {
+		userOptions = append(userOptions, service.WithUserTrash(trashStore))
+	}
+	userService := service.NewUserService(repo, log, userOptions...)
+	accessOptions = append(accessOptions, handler.WithUsers(userService))
+	if cfg.SCIMToken != "" {
+		accessOptions = append(accessOptions, handler.WithSCIM(userService, cfg.SCIMToken))
+	}
+	if cfg.ReadOnly {
+		accessOptions = append(accessOptions, handler.WithReadOnly(cfg.PrimaryURL))
+	}
 
-	// Seed some sample data
-	seedSampleData(repo, log)
+	var backups *backup.Manager
+	stopBackups := func() {}
+	if cfg.BackupS3Bucket != "" {
+		backups, err = backupManager(cfg, repo)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid backup configuration")
+		}
+		if cfg.BackupInterval > 0 {
+			stopBackups = backups.Start(cfg.BackupInterval, cfg.BackupTimeout, backupSink(log))
+		}
+		log.WithFields(logrus.Fields{
+			"bucket":    cfg.BackupS3Bucket,
+			"interval":  cfg.BackupInterval.String(),
+			"retain":    cfg.BackupRetain,
+			"encrypted": cfg.BackupEncryptionKey != "",
+		}).Info("Backups enabled")
+	}
 
-	// Initialize service
-	favoritesService := service.NewFavoritesService(repo, log)
+	taxonomyCatalog := taxonomy.NewCatalog()
+	if cfg.TaxonomyFile != "" {
+		if err := taxonomyCatalog.LoadFile(cfg.TaxonomyFile); err != nil {
+			log.WithError(err).Fatal("Failed to load taxonomy names")
+		}
+	}
+
+	var usageTracker *usage.Tracker
+	if cfg.UsageTracking {
+		usageTracker = usage.NewTracker(metrics.DefaultRegistry, usage.DefaultMaxValues)
+	}
+
+	var healthScorer *health.Scorer
+	var requestWindow *health.RequestWindow
+	if cfg.HealthScore {
+		healthScorer, requestWindow, err = healthScore(cfg, repo, moderationService)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid health score configuration")
+		}
+	}
 
 	// Initialize HTTP handler
-	httpHandler := handler.NewHandler(favoritesService, log)
+	httpHandler := handler.NewHandler(favoritesService, log, append(accessOptions,
+		handler.WithCORSPolicy(corsPolicy),
+		handler.WithIDValidator(&validation.IDValidator{User: userIDRule, Asset: assetIDRule}),
+		handler.WithExperiments(experiment.NewAssigner(experiments, exposureSink(log))),
+		handler.WithStorageService(storageService),
+		handler.WithAssetService(assetService),
+		handler.WithModerationService(moderationService),
+		handler.WithSeedGenerator(generator),
+		handler.WithSeedProfiles(seedProfiles),
+		handler.WithRequestLogPolicy(logPolicy),
+		handler.WithTraceSampling(tracePolicy),
+		handler.WithDebugCapture(capture.NewStore(capture.Options{
+			MaxRecordings: cfg.DebugCaptureMaxRecordings,
+			Retention:     cfg.DebugCaptureRetention,
+			MaxWindow:     cfg.DebugCaptureMaxWindow,
+			MaxBodyBytes:  cfg.DebugCaptureMaxBodyBytes,
+		}), cfg.DebugCaptureKey),
+		handler.WithDeprecations(deprecation.NewEngine(deprecations, cfg.DeprecationEnforce), cfg.DeprecationLink),
+		handler.WithAuditLog(auditLog),
+		handler.WithBackups(backups),
+		handler.WithUsageTracker(usageTracker),
+		handler.WithHealthScore(healthScorer, requestWindow),
+		handler.WithTaxonomy(taxonomyCatalog),
+		handler.WithNotifications(notices),
+		handler.WithEngagement(notifier),
+		handler.WithSharing(shares),
+		handler.WithDeployment(deployment(cfg)),
+		handler.WithPathPrefix(pathPrefix),
+	)...)
+	logCapabilities(log, httpHandler.Capabilities())
 
 	// Create HTTP server
 	server := &http.Server{
@@ -48,18 +398,81 @@ func main() {
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
+	useTLS := cfg.TLSCertFile != "" || cfg.TLSKeyFile != ""
+	if useTLS {
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			log.Fatal("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		}
+		server.TLSConfig = tlsConfig()
+	}
+
 	// Start server in a goroutine
+	listener, err := listeners.Listen(server.Addr)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to start HTTP server")
+	}
 	go func() {
-		log.WithField("addr", server.Addr).Info("HTTP server starting")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.WithFields(logrus.Fields{"addr": server.Addr, "tls": useTLS, "inherited": listeners.Inherited()}).Info("HTTP server starting")
+		var err error
+		if useTLS {
+			err = server.ServeTLS(listener, cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.WithError(err).Fatal("Failed to start HTTP server")
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown
+	var redirectServer *http.Server
+	if useTLS && cfg.HTTPRedirectPort != 0 {
+		redirectServer = &http.Server{
+			Addr:         fmt.Sprintf(":%d", cfg.HTTPRedirectPort),
+			Handler:      httpsRedirect(cfg.Port),
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		}
+		redirectListener, err := listeners.Listen(redirectServer.Addr)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to start HTTPS redirect server")
+		}
+		go func() {
+			log.WithField("addr", redirectServer.Addr).Info("HTTPS redirect server starting")
+			if err := redirectServer.Serve(redirectListener); err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Fatal("Failed to start HTTPS redirect server")
+			}
+		}()
+	}
+	if err := listeners.Ready(); err != nil {
+		log.WithError(err).Error("Failed to report ready to the previous process")
+	}
+
+	// Wait for interrupt signal to gracefully shutdown. With graceful
+	// restarts, SIGHUP starts a successor and shuts down once it is ready.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	switch {
+	case cfg.GracefulRestart && handoff.Supported:
+		signal.Notify(quit, syscall.SIGHUP)
+	case cfg.GracefulRestart:
+		log.Warn("GRACEFUL_RESTART is not supported on this platform and is ignored")
+	}
+	var successor *handoff.Successor
+	for successor == nil {
+		if sig := <-quit; sig != syscall.SIGHUP {
+			break
+		}
+		log.Info("Restarting, starting successor")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GracefulRestartTimeout)
+		successor, err = listeners.Restart(ctx)
+		cancel()
+		if err != nil {
+			log.WithError(err).Error("Restart failed, still serving")
+			continue
+		}
+		log.WithField("pid", successor.PID()).Info("Successor ready, handing off")
+	}
 
 	log.Info("Shutting down server...")
 
@@ -68,56 +481,668 @@ func main() {
 	defer cancel()
 
 	// Shutdown server
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			log.WithError(err).Error("HTTPS redirect server forced to shutdown")
+		}
+	}
+	// Requests still running at the deadline are cut off, but state is
+	// still saved below
 	if err := server.Shutdown(ctx); err != nil {
-		log.WithError(err).Fatal("Server forced to shutdown")
+		log.WithError(err).Error("Server forced to shutdown")
+	}
+	stopBackups()
+	stopIntegrityChecks()
+	stopRemovals()
+	stopWebhooks()
+	stopKPIs()
+
+	// Closing storage takes the final snapshot, which a successor loads
+	if err := store.close(); err != nil {
+		log.WithError(err).Error("Failed to close storage backend")
+	}
+	if successor != nil {
+		if err := successor.Release(); err != nil {
+			log.WithError(err).Error("Failed to release successor")
+		}
 	}
 
 	log.Info("Server exited")
 }
 
-func seedSampleData(repo *memory.Repository, log *logrus.Logger) {
-	log.Info("Seeding sample data...")
+// handsOffState reports whether a successor may only open storage once this
+// process has closed it: memory snapshots are taken on close, and a bolt
+// file is locked while open, whether it is the only one or one of several
+// shards
+func handsOffState(cfg *config.Config) bool {
+	switch cfg.StorageBackend {
+	case "memory":
+		return cfg.MemorySnapshotPath != "" && len(cfg.StorageShards) == 0
+	case "bolt":
+		return true
+	}
+	return false
+}
 
-	// Create sample users
-	users := []*domain.User{
-		domain.NewUser("user1", "john@example.com", "John Doe"),
-		domain.NewUser("user2", "jane@example.com", "Jane Smith"),
-		domain.NewUser("user3", "bob@example.com", "Bob Johnson"),
+// tlsConfig accepts TLS 1.2 and later. TLS 1.2 is limited to forward-secret
+// AEAD cipher suites; TLS 1.3 suites are not configurable and all modern.
+func tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
 	}
+}
 
-	for _, user := range users {
-		if err := repo.CreateUser(user); err != nil {
-			log.WithError(err).Error("Failed to create sample user")
+// httpsRedirect permanently redirects every request to the same URL on the
+// HTTPS port
+func httpsRedirect(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
 		}
+		host = strings.Trim(host, "[]")
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// storage is an opened repository backend
+type storage struct {
+	repo repository.FavoritesRepository
+	// restored is set when the memory backend reloaded a snapshot
+	restored bool
+	close    func() error
+}
+
+// waitForStorage opens storage, retrying with backoff while the backend or
+// cache is unreachable so the server outlasts dependencies that start slower
+// than it does
+func waitForStorage(cfg *config.Config, log *logrus.Logger) (*storage, error) {
+	policy := retry.Policy{
+		Attempts:       cfg.StartupWaitAttempts,
+		InitialBackoff: cfg.StartupWaitInitialBackoff,
+		MaxBackoff:     cfg.StartupWaitMaxBackoff,
 	}
 
-	// Create sample assets
-	chartData := []domain.ChartDataPoint{
-		{X: "Jan", Y: 100},
-		{X: "Feb", Y: 150},
-		{X: "Mar", Y: 200},
+	var store *storage
+	err := retry.Do(context.Background(), policy, func() (err error) {
+		store, err = openStorage(cfg, log)
+		return err
+	}, func(attempt int, err error, wait time.Duration) {
+		log.WithError(err).WithFields(logrus.Fields{
+			"backend":  cfg.StorageBackend,
+			"attempt":  attempt,
+			"attempts": policy.Attempts,
+			"retry_in": wait.String(),
+		}).Warn("Storage not ready, waiting")
+	})
+	return store, err
+}
+
+// openStorage is the repository factory: it opens the backend selected by
+// STORAGE_BACKEND and checks that it answers before the server starts
+func openStorage(cfg *config.Config, log *logrus.Logger) (*storage, error) {
+	store := &storage{close: func() error { return nil }}
+
+	backend := cfg.StorageBackend
+	if len(cfg.StorageShards) > 0 {
+		backend = "sharded"
 	}
 
-	assets := []domain.Asset{
-		domain.NewChart("chart1", "Monthly Sales", "Month", "Sales ($)", "Sales performance chart", chartData),
-		domain.NewInsight("insight1", "40% of millennials spend more than 3 hours on social media daily", "Social media usage insight", []string{"social", "millennials"}, "demographics"),
-		domain.NewAudience("audience1", "Gaming enthusiasts aged 24-35"),
+	switch backend {
+	case "sharded":
+		repo, closeShards, err := openShards(cfg)
+		if err != nil {
+			return nil, err
+		}
+		store.repo, store.close = repo, closeShards
+		log.WithFields(logrus.Fields{"backend": cfg.StorageBackend, "shards": len(cfg.StorageShards)}).Info("Storage sharded by user")
+	case "memory":
+		memoryRepo := memory.NewRepositoryWithOptions(memory.Options{
+			MaxAssets:    cfg.MemoryMaxAssets,
+			MaxFavorites: cfg.MemoryMaxFavorites,
+		})
+		registerMemoryMetrics(memoryRepo)
+		store.repo = memoryRepo
+
+		if cfg.MemorySnapshotPath != "" {
+			restored, err := memoryRepo.LoadSnapshot(cfg.MemorySnapshotPath)
+			if err != nil {
+				return nil, retry.Permanent(fmt.Errorf("restoring snapshot: %w", err))
+			}
+			store.restored = restored
+			log.WithFields(logrus.Fields{"path": cfg.MemorySnapshotPath, "restored": restored}).Info("Snapshot persistence enabled")
+
+			store.close = memoryRepo.StartSnapshots(cfg.MemorySnapshotPath, cfg.MemorySnapshotInterval, func(err error) {
+				log.WithError(err).Error("Failed to save snapshot")
+			})
+		}
+	case "postgres":
+		postgresRepo, err := postgres.Open(cfg.PostgresDSN)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to PostgreSQL: %w", err)
+		}
+		store.repo, store.close = postgresRepo, postgresRepo.Close
+	case "mysql":
+		mysqlRepo, err := mysql.Open(cfg.MySQLDSN)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to MySQL: %w", err)
+		}
+		store.repo, store.close = mysqlRepo, mysqlRepo.Close
+	case "sqlite":
+		sqliteRepo, err := sqlite.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("opening SQLite database: %w", err)
+		}
+		store.repo, store.close = sqliteRepo, sqliteRepo.Close
+	case "bolt":
+		boltRepo, err := embedded.Open(cfg.BoltPath)
+		if err != nil {
+			return nil, fmt.Errorf("opening bolt database: %w", err)
+		}
+		store.repo, store.close = boltRepo, boltRepo.Close
+	case "redis":
+		redisRepo, err := redis.Open(redis.Options{
+			Addr:      cfg.RedisAddr,
+			Password:  cfg.RedisPassword,
+			DB:        cfg.RedisDB,
+			KeyPrefix: cfg.RedisKeyPrefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connecting to Redis: %w", err)
+		}
+		store.repo, store.close = redisRepo, redisRepo.Close
+	case "cassandra":
+		cassandraRepo, err := cassandra.Open(cassandra.Options{
+			Hosts:             cfg.CassandraHosts,
+			Keyspace:          cfg.CassandraKeyspace,
+			ReplicationFactor: cfg.CassandraReplication,
+			ReadConsistency:   cfg.CassandraReadConsistency,
+			WriteConsistency:  cfg.CassandraWriteConsistency,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connecting to Cassandra: %w", err)
+		}
+		store.repo, store.close = cassandraRepo, cassandraRepo.Close
+	default:
+		return nil, retry.Permanent(fmt.Errorf("unknown storage backend %q", cfg.StorageBackend))
 	}
 
-	// Set audience properties
-	if audience, ok := assets[2].(*domain.Audience); ok {
-		audience.Gender = []string{"Male", "Female"}
-		audience.AgeGroups = []string{"24-35"}
-		audience.SocialMediaHours = "3+"
-		audience.PurchasesLastMonth = 5
-		audience.BirthCountries = []string{"US", "UK", "CA"}
+	// A read of a user that cannot exist exercises the connection and schema
+	if _, err := store.repo.GetUser(startupCheckUserID); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		store.close()
+		return nil, fmt.Errorf("startup check against %s failed: %w", cfg.StorageBackend, err)
 	}
 
-	for _, asset := range assets {
-		if err := repo.CreateAsset(asset); err != nil {
-			log.WithError(err).Error("Failed to create sample asset")
+	if cfg.CacheRedisAddr != "" {
+		if err := enableCache(cfg, log, store); err != nil {
+			store.close()
+			return nil, fmt.Errorf("connecting to cache: %w", err)
 		}
 	}
 
-	log.Info("Sample data seeded successfully")
+	// Offloading wraps the cache, so cached listings hold references only
+	if cfg.AssetBlobStore != "none" {
+		if err := enableBlobOffload(cfg, log, store); err != nil {
+			store.close()
+			return nil, retry.Permanent(fmt.Errorf("opening asset blob store: %w", err))
+		}
+	}
+
+	return store, nil
+}
+
+// openShards opens a STORAGE_BACKEND database at each STORAGE_SHARDS
+// location and spreads users over them
+func openShards(cfg *config.Config) (repository.FavoritesRepository, func() error, error) {
+	var shards []sharded.Shard
+	var closers []func() error
+	closeAll := func() error {
+		var first error
+		for _, closeShard := range closers {
+			if err := closeShard(); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+
+	for i, location := range cfg.StorageShards {
+		repo, closeShard, err := openShard(cfg, location)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("opening shard %d: %w", i, err)
+		}
+		shards = append(shards, sharded.Shard{Name: "shard" + strconv.Itoa(i), Repo: repo})
+		closers = append(closers, closeShard)
+	}
+
+	repo, err := sharded.New(shards)
+	if err != nil {
+		closeAll()
+		return nil, nil, retry.Permanent(err)
+	}
+	return repo, closeAll, nil
+}
+
+// openShard opens one shard; location replaces the backend's DSN, path or
+// address
+func openShard(cfg *config.Config, location string) (repository.FavoritesRepository, func() error, error) {
+	switch cfg.StorageBackend {
+	case "postgres":
+		repo, err := postgres.Open(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case "mysql":
+		repo, err := mysql.Open(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case "sqlite":
+		repo, err := sqlite.Open(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case "bolt":
+		repo, err := embedded.Open(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case "redis":
+		repo, err := redis.Open(redis.Options{
+			Addr:      location,
+			Password:  cfg.RedisPassword,
+			DB:        cfg.RedisDB,
+			KeyPrefix: cfg.RedisKeyPrefix,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	default:
+		return nil, nil, retry.Permanent(fmt.Errorf("storage backend %q cannot be sharded", cfg.StorageBackend))
+	}
+}
+
+// enableCache layers the Redis read cache over the opened backend
+func enableCache(cfg *config.Config, log *logrus.Logger, store *storage) error {
+	cacheRepo, err := cache.Open(store.repo, cache.Options{
+		Addr:      cfg.CacheRedisAddr,
+		Password:  cfg.RedisPassword,
+		DB:        cfg.RedisDB,
+		KeyPrefix: cfg.RedisKeyPrefix + "cache:",
+		TTL:       cfg.CacheTTL,
+		OnError: func(err error) {
+			log.WithError(err).Warn("Favorites cache unavailable")
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.DefaultRegistry.CounterFunc("favorites_cache_hits_total", "Favorites reads served from the cache", nil, func() float64 {
+		return float64(cacheRepo.Stats().Hits)
+	})
+	metrics.DefaultRegistry.CounterFunc("favorites_cache_misses_total", "Favorites reads passed to the storage backend", nil, func() float64 {
+		return float64(cacheRepo.Stats().Misses)
+	})
+
+	closeBackend := store.close
+	store.repo = cacheRepo
+	store.close = func() error {
+		cacheRepo.Close()
+		return closeBackend()
+	}
+	log.WithFields(logrus.Fields{"addr": cfg.CacheRedisAddr, "ttl": cfg.CacheTTL.String()}).Info("Favorites cache enabled")
+	return nil
+}
+
+// backupManager configures backups to the BACKUP_S3_BUCKET bucket
+func backupManager(cfg *config.Config, repo repository.FavoritesRepository) (*backup.Manager, error) {
+	client, err := s3.New(httpclient.NewDefault(), s3.Config{
+		Bucket:      cfg.BackupS3Bucket,
+		Region:      cfg.AWSRegion,
+		Endpoint:    cfg.BackupS3Endpoint,
+		Credentials: cfg.AWSCredentials(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	if cfg.BackupEncryptionKey != "" {
+		if key, err = backup.ParseKey(cfg.BackupEncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return backup.NewManager(repo, client, backup.Options{
+		Prefix:               cfg.BackupS3Prefix,
+		Key:                  key,
+		ServerSideEncryption: cfg.BackupServerSideEncryption,
+		Retain:               cfg.BackupRetain,
+	}), nil
+}
+
+// backupSink logs and counts scheduled backups
+func backupSink(log *logrus.Logger) func(backup.Info, error) {
+	return func(info backup.Info, err error) {
+		handler.CountBackup("schedule", err)
+		if err != nil {
+			log.WithError(err).Error("Scheduled backup failed")
+			return
+		}
+		log.WithFields(logrus.Fields{"backup": info.Name, "bytes": info.Size}).Info("Scheduled backup completed")
+	}
+}
+
+// enableBlobOffload moves large chart data from the backend to a blob store
+func enableBlobOffload(cfg *config.Config, log *logrus.Logger, store *storage) error {
+	var blobs repository.AssetBlobStore
+	switch cfg.AssetBlobStore {
+	case "local":
+		local, err := blobstore.NewLocal(cfg.AssetBlobPath)
+		if err != nil {
+			return err
+		}
+		blobs = local
+	case "s3":
+		client, err := s3.New(httpclient.NewDefault(), s3.Config{
+			Bucket:      cfg.AssetBlobS3Bucket,
+			Region:      cfg.AWSRegion,
+			Endpoint:    cfg.AssetBlobS3Endpoint,
+			Credentials: cfg.AWSCredentials(),
+		})
+		if err != nil {
+			return err
+		}
+		blobs = blobstore.NewS3(client, cfg.AssetBlobS3Prefix, cfg.AssetBlobTimeout)
+	default:
+		return fmt.Errorf("unknown asset blob store %q", cfg.AssetBlobStore)
+	}
+
+	store.repo = offload.New(store.repo, blobs, offload.Options{
+		Threshold: cfg.AssetBlobThreshold,
+		OnError: func(err error) {
+			log.WithError(err).Warn("Failed to delete unreferenced chart data")
+		},
+	})
+	log.WithFields(logrus.Fields{"store": cfg.AssetBlobStore, "threshold_bytes": cfg.AssetBlobThreshold}).Info("Chart data offloading enabled")
+	return nil
+}
+
+// deployment describes the configured environment for capability discovery
+func deployment(cfg *config.Config) handler.Deployment {
+	d := handler.Deployment{StorageBackend: cfg.StorageBackend}
+	if cfg.CacheRedisAddr != "" {
+		d.Cache = "redis"
+	}
+	if cfg.UserDirectory != "none" {
+		d.UserDirectory = cfg.UserDirectory
+	}
+	if cfg.AuthMode != "none" {
+		d.AuthModes = []string{cfg.AuthMode}
+	}
+	if cfg.AdminAPIKey != "" {
+		d.AuthModes = append(d.AuthModes, "admin_api_key")
+	}
+	return d
+}
+
+// openAuditLog opens the configured audit log; it returns a nil log when
+// auditing is disabled
+func openAuditLog(cfg *config.Config) (audit.Log, func() error, error) {
+	switch cfg.AuditLog {
+	case "none":
+		return nil, func() error { return nil }, nil
+	case "memory":
+		return audit.NewMemoryLog(), func() error { return nil }, nil
+	case "file":
+		fileLog, err := audit.OpenFileLog(cfg.AuditLogPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fileLog, fileLog.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown audit log %q", cfg.AuditLog)
+	}
+}
+
+// userDirectory opens the configured user directory behind a cache; it
+// returns nil when user IDs are not checked against a directory
+func userDirectory(cfg *config.Config) (directory.UserDirectory, error) {
+	switch cfg.UserDirectory {
+	case "none":
+		return nil, nil
+	case "scim":
+		if cfg.UserDirectoryURL == "" {
+			return nil, errors.New("USER_DIRECTORY_URL is required for the scim directory")
+		}
+	default:
+		return nil, fmt.Errorf("unknown user directory %q", cfg.UserDirectory)
+	}
+
+	cached := directory.NewCached(directory.NewSCIM(httpclient.NewDefault(), cfg.UserDirectoryURL, cfg.UserDirectoryToken), directory.CacheOptions{
+		TTL:         cfg.UserDirectoryCacheTTL,
+		NegativeTTL: cfg.UserDirectoryNegativeTTL,
+		MaxEntries:  cfg.UserDirectoryCacheSize,
+	})
+	metrics.DefaultRegistry.CounterFunc("user_directory_cache_hits_total", "User lookups answered from the directory cache", nil, func() float64 {
+		return float64(cached.Stats().Hits)
+	})
+	metrics.DefaultRegistry.CounterFunc("user_directory_cache_misses_total", "User lookups sent to the directory", nil, func() float64 {
+		return float64(cached.Stats().Misses)
+	})
+	return cached, nil
+}
+
+// authOptions configures caller authentication and rate limit tiers
+func authOptions(cfg *config.Config) ([]handler.Option, error) {
+	opts := []handler.Option{handler.WithAdminRole(cfg.AdminRole), handler.WithAdminAPIKey(cfg.AdminAPIKey)}
+
+	switch cfg.AuthMode {
+	case "none":
+	case "hs256":
+		opts = append(opts, handler.WithAuthenticator(auth.NewHMACVerifier(cfg.JWTSecret)))
+	case "oidc":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		verifier, err := auth.NewOIDCVerifier(ctx, auth.OIDCOptions{
+			Issuer:          cfg.OIDCIssuer,
+			JWKSURL:         cfg.OIDCJWKSURL,
+			Audience:        cfg.OIDCAudience,
+			RefreshInterval: cfg.OIDCJWKSRefresh,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, handler.WithAuthenticator(verifier))
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.AuthMode)
+	}
+
+	if len(cfg.RateLimitTiers) > 0 {
+		tiers, err := ratelimit.ParseTiers(cfg.RateLimitTiers)
+		if err != nil {
+			return nil, err
+		}
+		limiter, err := ratelimit.NewLimiter(tiers, cfg.RateLimitDefaultTier)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, handler.WithRateLimiter(limiter))
+	}
+	if cfg.MaxConcurrentPerCaller > 0 {
+		opts = append(opts, handler.WithConcurrencyLimiter(ratelimit.NewConcurrencyLimiter(cfg.MaxConcurrentPerCaller)))
+	}
+	if cfg.QueryCostBudget > 0 {
+		budget, err := ratelimit.NewCostBudget(cfg.QueryCostBudget)
+		if err != nil {
+			return nil, err
+		}
+		weights, err := ratelimit.ParseCostWeights(cfg.QueryCostWeights)
+		if err != nil {
+			return nil, err
+		}
+		var maxWait time.Duration
+		switch cfg.QueryCostPolicy {
+		case "reject":
+		case "queue":
+			maxWait = cfg.QueryCostMaxWait
+		default:
+			return nil, fmt.Errorf("unknown query cost policy %q", cfg.QueryCostPolicy)
+		}
+		opts = append(opts, handler.WithQueryCostBudget(budget, weights, maxWait))
+	}
+	if cfg.CallerUsage {
+		opts = append(opts, handler.WithRequestHistory(ratelimit.NewHistory(nil)))
+	}
+
+	return opts, nil
+}
+
+// logCapabilities prints the startup banner: what this instance serves
+func logCapabilities(log *logrus.Logger, capabilities handler.Capabilities) {
+	log.WithFields(logrus.Fields{
+		"storage":         capabilities.StorageBackend,
+		"cache":           capabilities.Cache,
+		"search":          capabilities.Search,
+		"event_transport": capabilities.EventTransport,
+		"user_directory":  capabilities.UserDirectory,
+		"auth_modes":      capabilities.AuthModes,
+		"asset_types":     capabilities.AssetTypes,
+		"api_versions":    capabilities.APIVersions,
+		"features":        capabilities.Features,
+	}).Info("Capabilities")
+}
+
+// startupCheckUserID is looked up once at startup to verify storage is reachable
+const startupCheckUserID = "__startup_check__"
+
+// businessMetrics exports the business KPIs. When the backend can count its
+// favorites, the total is counted at startup and recounted periodically.
+func businessMetrics(cfg *config.Config, repo repository.FavoritesRepository, log *logrus.Logger) (*kpi.Tracker, func(), error) {
+	if cfg.BusinessMetricsResync <= 0 {
+		return nil, nil, errors.New("BUSINESS_METRICS_RESYNC must be positive")
+	}
+
+	tracker := kpi.NewTracker(nil)
+	stop := func() {}
+	if inspector, ok := repo.(repository.StorageInspector); ok {
+		count := func() (int, error) {
+			stats, err := inspector.StorageStats()
+			return stats.Favorites, err
+		}
+		favorites, err := count()
+		if err != nil {
+			return nil, nil, fmt.Errorf("counting favorites: %w", err)
+		}
+		tracker.Resync(favorites)
+		stop = tracker.StartResync(cfg.BusinessMetricsResync, count, func(err error) {
+			log.WithError(err).Warn("Failed to recount favorites for business metrics")
+		})
+	} else {
+		log.Warn("Storage backend cannot count favorites; business_favorites not exported")
+	}
+
+	tracker.Register(metrics.DefaultRegistry)
+	return tracker, stop, nil
+}
+
+// registerMemoryMetrics exposes the in-memory repository budget usage
+func registerMemoryMetrics(repo *memory.Repository) {
+	metrics.DefaultRegistry.GaugeFunc("memory_repository_assets", "Assets held by the in-memory repository", nil, func() float64 {
+		return float64(repo.Usage().Assets)
+	})
+	metrics.DefaultRegistry.GaugeFunc("memory_repository_assets_limit", "Configured asset cap (0 means unlimited)", nil, func() float64 {
+		return float64(repo.Usage().MaxAssets)
+	})
+	metrics.DefaultRegistry.GaugeFunc("memory_repository_favorites", "Favorites held by the in-memory repository", nil, func() float64 {
+		return float64(repo.Usage().Favorites)
+	})
+	metrics.DefaultRegistry.GaugeFunc("memory_repository_favorites_limit", "Configured favorites cap (0 means unlimited)", nil, func() float64 {
+		return float64(repo.Usage().MaxFavorites)
+	})
+	metrics.DefaultRegistry.CounterFunc("memory_repository_evictions_total", "Assets evicted to stay within the memory budget", nil, func() float64 {
+		return float64(repo.Usage().Evictions)
+	})
+}
+
+// securityEventSink logs detected anomalies and counts them for alerting
+func securityEventSink(log *logrus.Logger) anomaly.Sink {
+	events := metrics.DefaultRegistry.Counter("security_events_total", "Security events raised by anomaly detection", metrics.Labels{"kind": anomaly.EventKindFavoritesBurst})
+	return func(event anomaly.Event) {
+		events.Inc()
+		log.WithFields(logrus.Fields{
+			"security_event":  event.Kind,
+			"key":             event.Key,
+			"count":           event.Count,
+			"window":          event.Window.String(),
+			"throttled_until": event.ThrottledUntil,
+		}).Warn("Anomalous favorites activity detected")
+	}
+}
+
+// exposureSink counts experiment exposures per variant and logs them for analysis
+func exposureSink(log *logrus.Logger) experiment.ExposureSink {
+	return func(userID string, assignment experiment.Assignment) {
+		metrics.DefaultRegistry.Counter("experiment_exposures_total", "Responses served to users in an experiment variant", metrics.Labels{
+			"experiment": assignment.Experiment,
+			"variant":    assignment.Variant,
+		}).Inc()
+		log.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"experiment": assignment.Experiment,
+			"variant":    assignment.Variant,
+		}).Debug("Experiment exposure")
+	}
+}
+
+// healthScore builds the soft health score from the API error rate, the
+// moderation backlog, the read cache and user directory hit rates and the
+// storage backend's round trip
+func healthScore(cfg *config.Config, repo repository.FavoritesRepository, moderation *service.ModerationService) (*health.Scorer, *health.RequestWindow, error) {
+	switch {
+	case cfg.HealthWindow <= 0:
+		return nil, nil, errors.New("HEALTH_WINDOW must be positive")
+	case cfg.HealthErrorRateLimit <= 0 || cfg.HealthErrorRateLimit > 1:
+		return nil, nil, errors.New("HEALTH_ERROR_RATE_LIMIT must be in (0, 1]")
+	case cfg.HealthBacklogLimit <= 0:
+		return nil, nil, errors.New("HEALTH_BACKLOG_LIMIT must be positive")
+	case cfg.HealthCacheHitTarget <= 0 || cfg.HealthCacheHitTarget > 1:
+		return nil, nil, errors.New("HEALTH_CACHE_HIT_TARGET must be in (0, 1]")
+	case cfg.HealthLatencyTarget <= 0:
+		return nil, nil, errors.New("HEALTH_LATENCY_TARGET must be positive")
+	}
+
+	requests := health.NewRequestWindow(cfg.HealthWindow, nil)
+	scorer := health.NewScorer(nil)
+	scorer.Add(3, health.ErrorRate("api", requests, cfg.HealthErrorRateLimit))
+	scorer.Add(1, health.QueueBacklog("moderation", func() int {
+		return len(moderation.Queue(context.Background()))
+	}, cfg.HealthBacklogLimit))
+	scorer.Add(1, health.CacheHitRate("favorites_cache", metrics.DefaultRegistry,
+		"favorites_cache_hits_total", "favorites_cache_misses_total", cfg.HealthCacheHitTarget))
+	scorer.Add(1, health.CacheHitRate("user_directory_cache", metrics.DefaultRegistry,
+		"user_directory_cache_hits_total", "user_directory_cache_misses_total", cfg.HealthCacheHitTarget))
+	if pinger, ok := repo.(repository.Pinger); ok {
+		scorer.Add(2, health.Latency("storage", pinger.Ping, cfg.HealthLatencyTarget))
+	}
+	return scorer, requests, nil
 }
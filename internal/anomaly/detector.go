@@ -0,0 +1,149 @@
+package anomaly
+
+import (
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Options configures burst detection
+type Options struct {
+	// Window is the period over which activity is counted
+	Window time.Duration
+	// Threshold is the number of events per window that counts as an
+	// anomaly; 0 disables detection
+	Threshold int
+	// Cooldown is how long an offending key is throttled; 0 only reports
+	Cooldown time.Duration
+}
+
+// Event describes detected abusive activity
+type Event struct {
+	Kind           string        `json:"kind"`
+	Key            string        `json:"key"`
+	Count          int           `json:"count"`
+	Window         time.Duration `json:"window"`
+	ThrottledUntil time.Time     `json:"throttled_until,omitempty"`
+	DetectedAt     time.Time     `json:"detected_at"`
+}
+
+// EventKindFavoritesBurst is reported when a key exceeds the threshold
+const EventKindFavoritesBurst = "favorites_burst"
+
+// Sink receives security events; it is called outside the detector lock
+type Sink func(Event)
+
+// maxTrackedKeys bounds memory before stale windows are swept
+const maxTrackedKeys = 10000
+
+type activity struct {
+	windowStart    time.Time
+	count          int
+	reported       bool
+	throttledUntil time.Time
+}
+
+// Detector counts activity per key (the caller's identity) in fixed
+// windows and throttles keys that exceed the threshold
+type Detector struct {
+	opts Options
+	sink Sink
+	now  func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*activity
+}
+
+// NewDetector creates a detector; sink may be nil
+func NewDetector(opts Options, sink Sink) *Detector {
+	return &Detector{
+		opts: opts,
+		sink: sink,
+		now:  time.Now,
+		keys: make(map[string]*activity),
+	}
+}
+
+// Observe records one event for key. It returns domain.ErrRateLimited when
+// the key is throttled, either already or because this event crossed the
+// threshold.
+func (d *Detector) Observe(key string) error {
+	if d == nil || d.opts.Threshold <= 0 {
+		return nil
+	}
+
+	now := d.now()
+	var event *Event
+
+	d.mu.Lock()
+	a, ok := d.keys[key]
+	if !ok {
+		if len(d.keys) >= maxTrackedKeys {
+			d.sweepLocked(now)
+		}
+		a = &activity{windowStart: now}
+		d.keys[key] = a
+	}
+
+	if now.Before(a.throttledUntil) {
+		d.mu.Unlock()
+		return domain.ErrRateLimited
+	}
+
+	if now.Sub(a.windowStart) >= d.opts.Window {
+		a.windowStart = now
+		a.count = 0
+		a.reported = false
+	}
+	a.count++
+
+	throttled := false
+	if a.count > d.opts.Threshold {
+		if !a.reported {
+			a.reported = true
+			if d.opts.Cooldown > 0 {
+				a.throttledUntil = now.Add(d.opts.Cooldown)
+			}
+			event = &Event{
+				Kind:           EventKindFavoritesBurst,
+				Key:            key,
+				Count:          a.count,
+				Window:         d.opts.Window,
+				ThrottledUntil: a.throttledUntil,
+				DetectedAt:     now,
+			}
+		}
+		throttled = d.opts.Cooldown > 0
+	}
+	d.mu.Unlock()
+
+	if event != nil && d.sink != nil {
+		d.sink(*event)
+	}
+	if throttled {
+		return domain.ErrRateLimited
+	}
+	return nil
+}
+
+// Throttled reports whether key is currently throttled
+func (d *Detector) Throttled(key string) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	a, ok := d.keys[key]
+	return ok && d.now().Before(a.throttledUntil)
+}
+
+// sweepLocked drops keys whose window and throttle have both expired
+func (d *Detector) sweepLocked(now time.Time) {
+	for key, a := range d.keys {
+		if now.Sub(a.windowStart) >= d.opts.Window && !now.Before(a.throttledUntil) {
+			delete(d.keys, key)
+		}
+	}
+}
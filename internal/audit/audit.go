@@ -0,0 +1,171 @@
+// Package audit records favorite mutations for compliance: who changed
+// what, when, and the values before and after.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/pkg/tracing"
+)
+
+// Action is the kind of change recorded
+type Action string
+
+const (
+	FavoriteAdded   Action = "favorite.add"
+	FavoriteRemoved Action = "favorite.remove"
+	FavoriteUpdated Action = "favorite.update"
+	// Catalog changes are recorded once per asset, since they change or
+	// remove every favorite pointing at it
+	AssetUpdated Action = "asset.update"
+	AssetDeleted Action = "asset.delete"
+)
+
+// Anonymous is the actor of requests without an authenticated caller
+const Anonymous = "anonymous"
+
+// Entry is one audited change
+type Entry struct {
+	ID      uint64          `json:"id"`
+	Time    time.Time       `json:"time"`
+	Actor   string          `json:"actor"`
+	Action  Action          `json:"action"`
+	UserID  string          `json:"user_id,omitempty"`
+	AssetID string          `json:"asset_id,omitempty"`
+	Old     json.RawMessage `json:"old,omitempty"`
+	New     json.RawMessage `json:"new,omitempty"`
+	TraceID string          `json:"trace_id,omitempty"`
+}
+
+// NewEntry describes a change made on behalf of the caller in ctx. old and
+// new are stored as JSON; nil values are omitted.
+func NewEntry(ctx context.Context, action Action, userID, assetID string, old, new interface{}) (Entry, error) {
+	entry := Entry{
+		Time:    time.Now().UTC(),
+		Actor:   Anonymous,
+		Action:  action,
+		UserID:  userID,
+		AssetID: assetID,
+	}
+	if claims := auth.ClaimsFromContext(ctx); claims != nil && claims.Subject != "" {
+		entry.Actor = claims.Subject
+	}
+	if span, ok := tracing.SpanFromContext(ctx); ok {
+		entry.TraceID = span.TraceID
+	}
+
+	var err error
+	if entry.Old, err = encode(old); err != nil {
+		return Entry{}, err
+	}
+	if entry.New, err = encode(new); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func encode(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// Filter selects entries; zero fields match everything. An entry must
+// match every field set, and a field listing several values matches any
+// of them.
+type Filter struct {
+	Actors   []string
+	Actions  []Action
+	UserIDs  []string
+	AssetIDs []string
+	Since    time.Time
+	Until    time.Time
+	// Before and After are cursors: when set, only entries with lower or
+	// higher IDs match
+	Before uint64
+	After  uint64
+	// Limit caps the number of entries returned; 0 means DefaultLimit
+	Limit int
+}
+
+// DefaultLimit bounds queries that do not set a limit
+const DefaultLimit = 100
+
+func (f Filter) matches(e Entry) bool {
+	return anyOf(f.Actors, e.Actor) &&
+		anyOf(f.Actions, e.Action) &&
+		anyOf(f.UserIDs, e.UserID) &&
+		anyOf(f.AssetIDs, e.AssetID) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until)) &&
+		(f.Before == 0 || e.ID < f.Before) &&
+		(f.After == 0 || e.ID > f.After)
+}
+
+// anyOf reports whether value is among values, or values is empty
+func anyOf[T comparable](values []T, value T) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultLimit
+	}
+	return f.Limit
+}
+
+// Log is an append-only audit store. Entries are never changed or removed
+// through it; Append assigns increasing IDs.
+type Log interface {
+	Append(entry Entry) error
+	// Query returns matching entries, newest first
+	Query(filter Filter) ([]Entry, error)
+	// Scan calls fn with every matching entry, oldest first, regardless of
+	// the filter's limit, and stops at the first error fn returns. Appends
+	// are not held up by a scan, and entries appended meanwhile may be
+	// left out.
+	Scan(filter Filter, fn func(Entry) error) error
+}
+
+// query returns the newest limit entries a scan finds, newest first,
+// holding no more than twice the limit at a time
+func query(scan func(Filter, func(Entry) error) error, filter Filter) ([]Entry, error) {
+	limit := filter.limit()
+	var matches []Entry
+	err := scan(filter, func(entry Entry) error {
+		matches = append(matches, entry)
+		if len(matches) >= 2*limit {
+			matches = append(matches[:0], matches[len(matches)-limit:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newestFirst(matches, limit), nil
+}
+
+// newestFirst keeps the last limit matches of entries in ascending ID
+// order and returns them newest first
+func newestFirst(matches []Entry, limit int) []Entry {
+	if len(matches) > limit {
+		matches = matches[len(matches)-limit:]
+	}
+	result := make([]Entry, len(matches))
+	for i, entry := range matches {
+		result[len(matches)-1-i] = entry
+	}
+	return result
+}
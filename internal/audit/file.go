@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileLog appends entries as JSON lines to a file opened in append-only
+// mode, syncing each entry before Append returns. Queries scan the file.
+type FileLog struct {
+	path string
+
+	mu     sync.Mutex
+	file   *os.File
+	lastID uint64
+	// size is the length of the file's complete entries; scans read no
+	// further, so they never see a half-written line
+	size int64
+}
+
+var _ Log = (*FileLog)(nil)
+
+// OpenFileLog opens or creates the log at path and resumes its ID sequence
+func OpenFileLog(path string) (*FileLog, error) {
+	l := &FileLog{path: path}
+
+	err := l.scan(-1, func(entry Entry) error {
+		l.lastID = entry.ID
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	l.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := l.file.Stat()
+	if err != nil {
+		l.file.Close()
+		return nil, err
+	}
+	l.size = info.Size()
+	return l, nil
+}
+
+// Close closes the file
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+func (l *FileLog) Append(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.ID = l.lastID + 1
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+
+	l.lastID = entry.ID
+	l.size += int64(len(line))
+	return nil
+}
+
+func (l *FileLog) Query(filter Filter) ([]Entry, error) {
+	return query(l.Scan, filter)
+}
+
+func (l *FileLog) Scan(filter Filter, fn func(Entry) error) error {
+	l.mu.Lock()
+	size := l.size
+	l.mu.Unlock()
+
+	return l.scan(size, func(entry Entry) error {
+		if !filter.matches(entry) {
+			return nil
+		}
+		return fn(entry)
+	})
+}
+
+// scan calls fn for every entry in the first size bytes of the file, or
+// the whole file when size is negative, in file order
+func (l *FileLog) scan(size int64, fn func(Entry) error) error {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if size >= 0 {
+		reader = io.LimitReader(file, size)
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("audit log %s line %d: %w", l.path, line, err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,48 @@
+package audit
+
+import "sync"
+
+// MemoryLog keeps entries in memory. It is lost on restart, so it suits
+// development; use FileLog where the audit trail must be kept.
+type MemoryLog struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+var _ Log = (*MemoryLog)(nil)
+
+// NewMemoryLog creates an empty log
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+func (l *MemoryLog) Append(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.ID = uint64(len(l.entries)) + 1
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *MemoryLog) Query(filter Filter) ([]Entry, error) {
+	return query(l.Scan, filter)
+}
+
+func (l *MemoryLog) Scan(filter Filter, fn func(Entry) error) error {
+	// Entries are never changed once appended, so the slice can be read
+	// after the lock is released
+	l.mu.RLock()
+	entries := l.entries
+	l.mu.RUnlock()
+
+	for _, entry := range entries {
+		if !filter.matches(entry) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
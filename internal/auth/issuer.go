@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Token types carried in the typ claim of issued tokens
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenPair is the result of a login or refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// Issuer signs HS256 access and refresh tokens. Access tokens are accepted
+// by an HMACVerifier with the same secret. Refresh tokens are single use:
+// redeeming one rotates it.
+type Issuer struct {
+	secret     string
+	verifier   *HMACVerifier
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+
+	mu sync.Mutex
+	// redeemed holds used refresh token IDs until the tokens expire
+	redeemed map[string]time.Time
+}
+
+// NewIssuer creates an issuer signing with secret
+func NewIssuer(secret string, accessTTL, refreshTTL time.Duration) *Issuer {
+	return &Issuer{
+		secret:     secret,
+		verifier:   NewHMACVerifier(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+		redeemed:   make(map[string]time.Time),
+	}
+}
+
+// Issue creates a token pair for subject
+func (i *Issuer) Issue(subject, role string) (*TokenPair, error) {
+	now := i.now()
+
+	access, err := i.sign(Claims{Subject: subject, Role: role, Type: TokenTypeAccess}, now, i.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := i.sign(Claims{Subject: subject, Type: TokenTypeRefresh}, now, i.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(i.accessTTL.Seconds()),
+	}, nil
+}
+
+// Redeem verifies a refresh token and marks it used, returning its claims.
+// Invalid, expired and already redeemed tokens yield domain.ErrUnauthorized.
+func (i *Issuer) Redeem(refreshToken string) (*Claims, error) {
+	claims, err := i.verifier.verify(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeRefresh || claims.ID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	now := i.now()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for id, expires := range i.redeemed {
+		if now.After(expires.Add(clockSkew)) {
+			delete(i.redeemed, id)
+		}
+	}
+	if _, used := i.redeemed[claims.ID]; used {
+		return nil, domain.ErrUnauthorized
+	}
+	i.redeemed[claims.ID] = time.Unix(claims.ExpiresAt, 0)
+
+	return claims, nil
+}
+
+func (i *Issuer) sign(claims Claims, now time.Time, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	claims.ID = hex.EncodeToString(id)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+	return SignHS256(i.secret, claims)
+}
+
+// Credentials holds the passwords accepted at login
+type Credentials struct {
+	passwords       map[string][sha256.Size]byte
+	roles           map[string]string
+	defaultPassword *[sha256.Size]byte
+}
+
+// NewCredentials creates credentials from passwords and roles by user ID.
+// Users without a password of their own may log in with defaultPassword,
+// when it is set.
+func NewCredentials(passwords, roles map[string]string, defaultPassword string) *Credentials {
+	c := &Credentials{
+		passwords: make(map[string][sha256.Size]byte, len(passwords)),
+		roles:     roles,
+	}
+	for userID, password := range passwords {
+		c.passwords[userID] = sha256.Sum256([]byte(password))
+	}
+	if defaultPassword != "" {
+		sum := sha256.Sum256([]byte(defaultPassword))
+		c.defaultPassword = &sum
+	}
+	return c
+}
+
+// Check reports whether password is valid for userID. listed is false when
+// the default password was used, so the caller can confirm the user exists.
+func (c *Credentials) Check(userID, password string) (ok, listed bool) {
+	sum := sha256.Sum256([]byte(password))
+	if expected, found := c.passwords[userID]; found {
+		return subtle.ConstantTimeCompare(sum[:], expected[:]) == 1, true
+	}
+	if c.defaultPassword == nil {
+		return false, false
+	}
+	return subtle.ConstantTimeCompare(sum[:], c.defaultPassword[:]) == 1, false
+}
+
+// Listed reports whether userID has a password of its own
+func (c *Credentials) Listed(userID string) bool {
+	_, found := c.passwords[userID]
+	return found
+}
+
+// Role returns the role granted to userID, if any
+func (c *Credentials) Role(userID string) string {
+	return c.roles[userID]
+}
@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// OIDCOptions configures verification of tokens issued by an OIDC provider
+type OIDCOptions struct {
+	// Issuer must match the iss claim. When JWKSURL is empty the key set
+	// location is discovered from the issuer's openid-configuration.
+	Issuer  string
+	JWKSURL string
+	// Audience, when set, must be one of the token audiences
+	Audience string
+	// RefreshInterval is how long fetched keys are trusted before the key
+	// set is fetched again; 0 uses DefaultJWKSRefresh
+	RefreshInterval time.Duration
+	// MinRefetchInterval stops tokens naming unknown keys from hammering the
+	// provider; 0 uses DefaultMinRefetch
+	MinRefetchInterval time.Duration
+	// Client fetches discovery documents and key sets; nil uses a default client
+	Client *httpclient.Client
+}
+
+// DefaultJWKSRefresh is how often the provider key set is refetched
+const DefaultJWKSRefresh = time.Hour
+
+// DefaultMinRefetch is the shortest gap between two key set fetches
+const DefaultMinRefetch = 30 * time.Second
+
+// OIDCVerifier verifies RS256/ES256 family tokens against the provider's
+// JWKS. Keys are cached and refetched on expiry or when a token names an
+// unknown key, which picks up provider key rotation.
+type OIDCVerifier struct {
+	opts   OIDCOptions
+	client *httpclient.Client
+	now    func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+
+	// refreshMu lets one caller refresh while others wait for the result
+	refreshMu sync.Mutex
+}
+
+// NewOIDCVerifier discovers the key set if needed and fetches it once, so
+// misconfiguration fails at startup
+func NewOIDCVerifier(ctx context.Context, opts OIDCOptions) (*OIDCVerifier, error) {
+	if opts.Issuer == "" {
+		return nil, errors.New("OIDC issuer is required")
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = DefaultJWKSRefresh
+	}
+	if opts.MinRefetchInterval <= 0 {
+		opts.MinRefetchInterval = DefaultMinRefetch
+	}
+	client := opts.Client
+	if client == nil {
+		client = httpclient.NewDefault()
+	}
+
+	v := &OIDCVerifier{opts: opts, client: client, now: time.Now}
+
+	if v.opts.JWKSURL == "" {
+		jwksURL, err := v.discover(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("discovering OIDC configuration: %w", err)
+		}
+		v.opts.JWKSURL = jwksURL
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	return v, nil
+}
+
+func (v *OIDCVerifier) Verify(token string) (*Claims, error) {
+	header, claims, signed, signature, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := v.key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Algorithm, key, signed, signature); err != nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if claims.Issuer != v.opts.Issuer {
+		return nil, domain.ErrUnauthorized
+	}
+	if v.opts.Audience != "" && !claims.Audience.Contains(v.opts.Audience) {
+		return nil, domain.ErrUnauthorized
+	}
+	// Provider tokens must expire
+	if claims.ExpiresAt == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+	if err := checkTimes(claims, v.now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// key returns the public key for kid, refetching the key set when it is
+// stale or does not contain kid
+func (v *OIDCVerifier) key(kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	stale := v.now().Sub(v.fetchedAt) >= v.opts.RefreshInterval
+	v.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	// A failed refresh keeps serving the keys already held
+	_ = v.refresh(context.Background())
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, domain.ErrUnauthorized
+}
+
+// jsonWebKey holds the JWK fields for RSA and EC public keys
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// refresh fetches the key set, unless an attempt was made within
+// MinRefetchInterval
+func (v *OIDCVerifier) refresh(ctx context.Context) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	v.mu.RLock()
+	recentlyTried := !v.lastAttempt.IsZero() && v.now().Sub(v.lastAttempt) < v.opts.MinRefetchInterval
+	v.mu.RUnlock()
+	if recentlyTried {
+		return nil
+	}
+
+	v.mu.Lock()
+	v.lastAttempt = v.now()
+	v.mu.Unlock()
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.opts.JWKSURL, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys with unsupported types are skipped rather than failing the set
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	if len(keys) == 0 {
+		return errors.New("key set contains no usable signing keys")
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = v.now()
+	v.mu.Unlock()
+	return nil
+}
+
+// discover reads jwks_uri from the issuer's openid-configuration
+func (v *OIDCVerifier) discover(ctx context.Context) (string, error) {
+	var config struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	url := strings.TrimSuffix(v.opts.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := v.getJSON(ctx, url, &config); err != nil {
+		return "", err
+	}
+	if config.Issuer != v.opts.Issuer {
+		return "", fmt.Errorf("provider reports issuer %q, expected %q", config.Issuer, v.opts.Issuer)
+	}
+	if config.JWKSURI == "" {
+		return "", errors.New("provider configuration has no jwks_uri")
+	}
+	return config.JWKSURI, nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	resp, err := v.client.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// verifySignature checks an asymmetric JWS signature. HMAC algorithms are
+// refused: a provider's public key must never be usable as a shared secret.
+func verifySignature(algorithm string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch algorithm {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(algorithm, "RS") {
+			return errors.New("algorithm does not match key type")
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(algorithm, "ES") {
+			return errors.New("algorithm does not match key type")
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported key")
+}
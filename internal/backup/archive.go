@@ -0,0 +1,342 @@
+// Package backup takes backups of the repository contents that do not
+// depend on the storage backend, so they restore into any backend
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// archiveVersion identifies the archive layout
+const archiveVersion = 1
+
+// encryptedMagic prefixes encrypted archives, so restoring one without a key
+// fails clearly rather than as a gzip error
+var encryptedMagic = []byte("GWIFAVB1")
+
+// Archive is the full contents of a repository. Assets use the versioned
+// storage encoding so archives of older builds keep restoring.
+type Archive struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Assets    []json.RawMessage `json:"assets"`
+	Users     []*domain.User    `json:"users"`
+	Favorites []Favorite        `json:"favorites"`
+}
+
+// Favorite is a favorite in an archive
+type Favorite struct {
+	UserID  string    `json:"user_id"`
+	AssetID string    `json:"asset_id"`
+	AddedAt time.Time `json:"added_at"`
+	Note    string    `json:"note,omitempty"`
+	// Asset is the favorite's own copy of the asset, kept only when it
+	// differs from the catalog, e.g. for a per-user description
+	Asset json.RawMessage `json:"asset,omitempty"`
+}
+
+// RestoreResult counts what a restore wrote. Entries already present are
+// skipped, so restoring twice is harmless.
+type RestoreResult struct {
+	Assets    int `json:"assets"`
+	Users     int `json:"users"`
+	Favorites int `json:"favorites"`
+	Skipped   int `json:"skipped"`
+}
+
+// Dump reads the whole repository, which must be able to list its users.
+// Reads are not isolated from concurrent writes, so a backup taken under
+// load may hold a favorite added after its asset was read, but never a
+// favorite without its asset. Offloaded chart data is included.
+func Dump(repo repository.FavoritesRepository, batchSize int) (*Archive, error) {
+	lister, ok := repo.(repository.UserLister)
+	if !ok {
+		return nil, fmt.Errorf("backend cannot list users: %w", domain.ErrNotSupported)
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	archive := &Archive{Version: archiveVersion, CreatedAt: time.Now().UTC()}
+
+	// Stored forms of catalog assets, to tell which favorites hold their own copy
+	stored := make(map[string][]byte)
+	for offset := 0; ; offset += batchSize {
+		assets, err := repo.ListAssets(batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("listing assets: %w", err)
+		}
+		for _, asset := range assets {
+			encoded, err := repository.EncodeAsset(asset)
+			if err != nil {
+				return nil, err
+			}
+			stored[asset.GetID()] = encoded
+			full, err := encodeFull(repo, asset)
+			if err != nil {
+				return nil, err
+			}
+			archive.Assets = append(archive.Assets, full)
+		}
+		if len(assets) < batchSize {
+			break
+		}
+	}
+
+	after := ""
+	for {
+		ids, err := lister.ListUserIDs(after, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("listing users: %w", err)
+		}
+		for _, userID := range ids {
+			if err := dumpUser(repo, archive, stored, userID); err != nil {
+				return nil, fmt.Errorf("user %s: %w", userID, err)
+			}
+			after = userID
+		}
+		if len(ids) < batchSize {
+			return archive, nil
+		}
+	}
+}
+
+func dumpUser(repo repository.FavoritesRepository, archive *Archive, stored map[string][]byte, userID string) error {
+	user, err := repo.GetUser(userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// Deleted since it was listed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	archive.Users = append(archive.Users, user)
+
+	favorites, err := repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return err
+	}
+	for _, favorite := range favorites {
+		record := Favorite{UserID: userID, AssetID: favorite.AssetID, AddedAt: favorite.AddedAt, Note: favorite.Note}
+		encoded, err := repository.EncodeAsset(favorite.Asset)
+		if err != nil {
+			return err
+		}
+		if catalog, ok := stored[favorite.AssetID]; !ok || !bytes.Equal(encoded, catalog) {
+			if record.Asset, err = encodeFull(repo, favorite.Asset); err != nil {
+				return err
+			}
+			if !ok {
+				// Added after the catalog was read
+				archive.Assets = append(archive.Assets, record.Asset)
+				stored[favorite.AssetID] = encoded
+				record.Asset = nil
+			}
+		}
+		archive.Favorites = append(archive.Favorites, record)
+	}
+	return nil
+}
+
+// encodeFull encodes an asset with any offloaded chart data loaded, since
+// the blob store is not part of the backup
+func encodeFull(repo repository.FavoritesRepository, asset domain.Asset) ([]byte, error) {
+	if chart, ok := asset.(*domain.Chart); ok && chart.DataRef != nil && len(chart.Data) == 0 {
+		loader, ok := repo.(repository.ChartDataLoader)
+		if !ok {
+			return nil, fmt.Errorf("chart %s references offloaded data: %w", chart.ID, domain.ErrNotSupported)
+		}
+		data, err := loader.LoadChartData(*chart.DataRef)
+		if err != nil {
+			return nil, err
+		}
+		full := *chart
+		full.Data = data
+		full.DataRef = nil
+		asset = &full
+	}
+	return repository.EncodeAsset(asset)
+}
+
+// Restore writes the archive into repo. Existing entries are kept, so
+// restoring into a store that is not empty merges the two.
+func Restore(repo repository.FavoritesRepository, archive *Archive) (RestoreResult, error) {
+	var result RestoreResult
+	if archive.Version > archiveVersion {
+		return result, fmt.Errorf("archive version %d is newer than supported version %d", archive.Version, archiveVersion)
+	}
+
+	assets := make(map[string]domain.Asset, len(archive.Assets))
+	for _, raw := range archive.Assets {
+		asset, err := repository.DecodeAsset(raw)
+		if err != nil {
+			return result, fmt.Errorf("decoding asset: %w", err)
+		}
+		assets[asset.GetID()] = asset
+
+		err = repo.CreateAsset(asset)
+		switch {
+		case err == nil:
+			result.Assets++
+		case errors.Is(err, domain.ErrAssetAlreadyExists):
+			result.Skipped++
+		default:
+			return result, fmt.Errorf("restoring asset %s: %w", asset.GetID(), err)
+		}
+	}
+
+	for _, user := range archive.Users {
+		// Some backends replace existing users on create, so existing
+		// users are looked up first
+		if _, err := repo.GetUser(user.ID); err == nil {
+			result.Skipped++
+			continue
+		}
+		err := repo.CreateUser(user)
+		switch {
+		case err == nil:
+			result.Users++
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			result.Skipped++
+		default:
+			return result, fmt.Errorf("restoring user %s: %w", user.ID, err)
+		}
+	}
+
+	// Favorites keep the time they were added where the backend can import
+	// them. Elsewhere they are stamped with the restore time; they are
+	// archived oldest first, so adding them in order still keeps listings
+	// in order.
+	importer, _ := repo.(repository.FavoriteImporter)
+	for _, favorite := range archive.Favorites {
+		asset := assets[favorite.AssetID]
+		if favorite.Asset != nil {
+			own, err := repository.DecodeAsset(favorite.Asset)
+			if err != nil {
+				return result, fmt.Errorf("decoding favorite %s of %s: %w", favorite.AssetID, favorite.UserID, err)
+			}
+			asset = own
+		}
+		if asset == nil {
+			return result, fmt.Errorf("favorite %s of %s: %w", favorite.AssetID, favorite.UserID, domain.ErrAssetNotFound)
+		}
+
+		err := restoreFavorite(repo, importer, favorite, asset)
+		if err == nil && favorite.Note != "" {
+			_, err = repo.UpdateFavoriteNote(favorite.UserID, favorite.AssetID, favorite.Note)
+		}
+		switch {
+		case err == nil:
+			result.Favorites++
+		case errors.Is(err, domain.ErrFavoriteAlreadyExists):
+			result.Skipped++
+		default:
+			return result, fmt.Errorf("restoring favorite %s of %s: %w", favorite.AssetID, favorite.UserID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// restoreFavorite adds favorite with its original added_at when importer
+// is set and the backend behind it supports importing
+func restoreFavorite(repo repository.FavoritesRepository, importer repository.FavoriteImporter, favorite Favorite, asset domain.Asset) error {
+	if importer != nil && !favorite.AddedAt.IsZero() {
+		err := importer.ImportFavorite(favorite.UserID, asset, favorite.AddedAt)
+		if !errors.Is(err, domain.ErrNotSupported) {
+			return err
+		}
+	}
+	return repo.AddFavorite(favorite.UserID, asset)
+}
+
+// ParseKey decodes a base64 AES-256 key
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("backup encryption key is not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("backup encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// Encode serializes an archive as gzipped JSON, encrypted with AES-256-GCM
+// when key is set
+func Encode(archive *Archive, key []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(archive); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return buf.Bytes(), nil
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(append([]byte(nil), encryptedMagic...), nonce...)
+	return gcm.Seal(sealed, nonce, buf.Bytes(), encryptedMagic), nil
+}
+
+// Decode reverses Encode
+func Decode(data, key []byte) (*Archive, error) {
+	if bytes.HasPrefix(data, encryptedMagic) {
+		if key == nil {
+			return nil, errors.New("backup is encrypted and no key is configured")
+		}
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		data = data[len(encryptedMagic):]
+		if len(data) < gcm.NonceSize() {
+			return nil, errors.New("backup is truncated")
+		}
+		nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+		if data, err = gcm.Open(nil, nonce, sealed, encryptedMagic); err != nil {
+			return nil, errors.New("backup cannot be decrypted with the configured key")
+		}
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	defer zr.Close()
+
+	var archive Archive
+	if err := json.NewDecoder(zr).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	return &archive, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
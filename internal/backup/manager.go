@@ -0,0 +1,206 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/s3"
+)
+
+// nameLayout timestamps backup names, so they sort by age
+const nameLayout = "20060102T150405.000Z"
+
+var namePattern = regexp.MustCompile(`^favorites-\d{8}T\d{6}\.\d{3}Z\.json\.gz(\.enc)?$`)
+
+// Options configures where and how backups are kept
+type Options struct {
+	// Prefix of backup object keys
+	Prefix string
+	// Key, when set, encrypts backups with AES-256-GCM before upload
+	Key []byte
+	// ServerSideEncryption is sent as x-amz-server-side-encryption, e.g.
+	// AES256 or aws:kms; empty leaves it to the bucket default
+	ServerSideEncryption string
+	// Retain is the number of backups kept; older ones are deleted after
+	// each backup. Zero keeps every backup.
+	Retain int
+	// BatchSize is the number of assets or users read per page
+	BatchSize int
+}
+
+// Info describes a stored backup
+type Info struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Encrypted bool      `json:"encrypted"`
+}
+
+// Manager backs the repository up to an S3-compatible bucket and restores
+// it. Every backup is a new object named after its time, so earlier
+// backups stay available until retention removes them.
+type Manager struct {
+	repo   repository.FavoritesRepository
+	client *s3.Client
+	opts   Options
+	now    func() time.Time
+
+	// running is held by a backup or restore; they never overlap
+	running sync.Mutex
+}
+
+// NewManager creates a manager storing backups through client
+func NewManager(repo repository.FavoritesRepository, client *s3.Client, opts Options) *Manager {
+	return &Manager{repo: repo, client: client, opts: opts, now: time.Now}
+}
+
+// Backup uploads a full backup and applies retention
+func (m *Manager) Backup(ctx context.Context) (Info, error) {
+	if !m.running.TryLock() {
+		return Info{}, domain.ErrBackupInProgress
+	}
+	defer m.running.Unlock()
+
+	archive, err := Dump(m.repo, m.opts.BatchSize)
+	if err != nil {
+		return Info{}, err
+	}
+	data, err := Encode(archive, m.opts.Key)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		Name:      "favorites-" + m.now().UTC().Format(nameLayout) + ".json.gz",
+		Size:      int64(len(data)),
+		CreatedAt: archive.CreatedAt,
+		Encrypted: m.opts.Key != nil,
+	}
+	if info.Encrypted {
+		info.Name += ".enc"
+	}
+
+	headers := map[string]string{"Content-Type": "application/octet-stream"}
+	if m.opts.ServerSideEncryption != "" {
+		headers["x-amz-server-side-encryption"] = m.opts.ServerSideEncryption
+	}
+	if err := m.client.Put(ctx, m.opts.Prefix+info.Name, data, headers); err != nil {
+		return Info{}, fmt.Errorf("uploading backup: %w", err)
+	}
+
+	if err := m.prune(ctx); err != nil {
+		return info, fmt.Errorf("applying backup retention: %w", err)
+	}
+	return info, nil
+}
+
+// List returns the stored backups, newest first
+func (m *Manager) List(ctx context.Context) ([]Info, error) {
+	objects, err := m.client.List(ctx, m.opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Info, 0, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, m.opts.Prefix)
+		if !namePattern.MatchString(name) {
+			continue
+		}
+		createdAt, _ := time.Parse(nameLayout, strings.TrimPrefix(name, "favorites-")[:len(nameLayout)])
+		backups = append(backups, Info{
+			Name:      name,
+			Size:      object.Size,
+			CreatedAt: createdAt,
+			Encrypted: strings.HasSuffix(name, ".enc"),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// Restore writes the named backup into the repository, keeping entries
+// that already exist
+func (m *Manager) Restore(ctx context.Context, name string) (RestoreResult, error) {
+	if !namePattern.MatchString(name) {
+		return RestoreResult{}, domain.WithContext(domain.ErrBackupNotFound, "backup", name)
+	}
+	if !m.running.TryLock() {
+		return RestoreResult{}, domain.ErrBackupInProgress
+	}
+	defer m.running.Unlock()
+
+	data, err := m.client.Get(ctx, m.opts.Prefix+name)
+	if errors.Is(err, s3.ErrNotFound) {
+		return RestoreResult{}, domain.WithContext(domain.ErrBackupNotFound, "backup", name)
+	}
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("downloading backup: %w", err)
+	}
+
+	archive, err := Decode(data, m.opts.Key)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	return Restore(m.repo, archive)
+}
+
+// prune deletes all but the newest Retain backups
+func (m *Manager) prune(ctx context.Context) error {
+	if m.opts.Retain <= 0 {
+		return nil
+	}
+	backups, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	for i := m.opts.Retain; i < len(backups); i++ {
+		if err := m.client.Delete(ctx, m.opts.Prefix+backups[i].Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start takes a backup every interval until the returned stop function is
+// called. Each result is passed to onResult.
+func (m *Manager) Start(interval, timeout time.Duration, onResult func(Info, error)) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				info, err := m.Backup(ctx)
+				cancel()
+				onResult(info, err)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package blobstore
+
+import (
+	"context"
+	"time"
+
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/s3"
+)
+
+// S3 stores blobs as objects below a prefix of a bucket
+type S3 struct {
+	client  *s3.Client
+	prefix  string
+	timeout time.Duration
+}
+
+var _ repository.AssetBlobStore = (*S3)(nil)
+
+// NewS3 creates a store in the client's bucket. Each request is bounded by
+// timeout, since repository calls carry no context.
+func NewS3(client *s3.Client, prefix string, timeout time.Duration) *S3 {
+	return &S3{client: client, prefix: prefix, timeout: timeout}
+}
+
+func (s *S3) Put(key string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.Put(ctx, s.prefix+key, data, map[string]string{"Content-Type": "application/json"})
+}
+
+func (s *S3) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.Get(ctx, s.prefix+key)
+}
+
+func (s *S3) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.Delete(ctx, s.prefix+key)
+}
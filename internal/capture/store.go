@@ -0,0 +1,267 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options bounds what the store keeps
+type Options struct {
+	// MaxRecordings is the number of recordings kept; older ones are dropped
+	MaxRecordings int
+	// Retention is how long a recording is kept
+	Retention time.Duration
+	// MaxWindow caps how long capture can be enabled for a user
+	MaxWindow time.Duration
+	// MaxBodyBytes truncates recorded bodies
+	MaxBodyBytes int
+}
+
+// Recording is one captured request and its response
+type Recording struct {
+	ID              uint64              `json:"id"`
+	UserID          string              `json:"user_id,omitempty"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	Query           string              `json:"query,omitempty"`
+	TraceID         string              `json:"trace_id,omitempty"`
+	Status          int                 `json:"status"`
+	Duration        time.Duration       `json:"duration"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	RequestBody     string              `json:"request_body,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ResponseBody    string              `json:"response_body,omitempty"`
+	Truncated       bool                `json:"truncated,omitempty"`
+	RecordedAt      time.Time           `json:"recorded_at"`
+}
+
+// Session is a window during which a user's requests are captured
+type Session struct {
+	UserID string    `json:"user_id"`
+	Until  time.Time `json:"until"`
+}
+
+// Store holds capture sessions and recordings in memory
+type Store struct {
+	opts Options
+	now  func() time.Time
+
+	mu         sync.Mutex
+	sessions   map[string]time.Time
+	recordings []Recording
+	nextID     uint64
+}
+
+// Defaults for unset Options fields
+const (
+	DefaultMaxRecordings = 500
+	DefaultRetention     = time.Hour
+	DefaultMaxWindow     = time.Hour
+	DefaultMaxBodyBytes  = 64 * 1024
+)
+
+// NewStore creates an empty store
+func NewStore(opts Options) *Store {
+	if opts.MaxRecordings <= 0 {
+		opts.MaxRecordings = DefaultMaxRecordings
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.MaxWindow <= 0 {
+		opts.MaxWindow = DefaultMaxWindow
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Store{
+		opts:     opts,
+		now:      time.Now,
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// MaxBodyBytes is the recorded body size limit
+func (s *Store) MaxBodyBytes() int {
+	return s.opts.MaxBodyBytes
+}
+
+// Enable captures the user's requests for window, capped at MaxWindow
+func (s *Store) Enable(userID string, window time.Duration) Session {
+	if window <= 0 || window > s.opts.MaxWindow {
+		window = s.opts.MaxWindow
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until := s.now().Add(window)
+	s.sessions[userID] = until
+	return Session{UserID: userID, Until: until}
+}
+
+// Disable stops capturing the user's requests
+func (s *Store) Disable(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, userID)
+}
+
+// Active reports whether the user's requests are being captured
+func (s *Store) Active(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.sessions[userID]
+	if ok && !s.now().Before(until) {
+		delete(s.sessions, userID)
+		return false
+	}
+	return ok
+}
+
+// Sessions lists the active capture windows
+func (s *Store) Sessions() []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	sessions := make([]Session, 0, len(s.sessions))
+	for userID, until := range s.sessions {
+		if now.Before(until) {
+			sessions = append(sessions, Session{UserID: userID, Until: until})
+		}
+	}
+	return sessions
+}
+
+// Record stores a recording, sanitizing headers and bodies first
+func (s *Store) Record(recording Recording) {
+	recording.RequestHeaders = SanitizeHeaders(recording.RequestHeaders)
+	recording.ResponseHeaders = SanitizeHeaders(recording.ResponseHeaders)
+	recording.RequestBody = SanitizeBody(recording.RequestBody)
+	recording.ResponseBody = SanitizeBody(recording.ResponseBody)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	recording.ID = s.nextID
+	recording.RecordedAt = s.now()
+
+	s.expireLocked()
+	if len(s.recordings) >= s.opts.MaxRecordings {
+		s.recordings = s.recordings[len(s.recordings)-s.opts.MaxRecordings+1:]
+	}
+	s.recordings = append(s.recordings, recording)
+}
+
+// Recordings returns retained recordings, newest first. An empty userID
+// returns every recording.
+func (s *Store) Recordings(userID string) []Recording {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireLocked()
+	result := []Recording{}
+	for i := len(s.recordings) - 1; i >= 0; i-- {
+		if userID == "" || s.recordings[i].UserID == userID {
+			result = append(result, s.recordings[i])
+		}
+	}
+	return result
+}
+
+// expireLocked drops recordings older than the retention period
+func (s *Store) expireLocked() {
+	cutoff := s.now().Add(-s.opts.Retention)
+	i := 0
+	for i < len(s.recordings) && s.recordings[i].RecordedAt.Before(cutoff) {
+		i++
+	}
+	s.recordings = s.recordings[i:]
+}
+
+// redacted replaces sensitive values
+const redacted = "[REDACTED]"
+
+// unparseableBody replaces JSON bodies that could not be sanitized
+const unparseableBody = "[OMITTED: JSON body could not be sanitized]"
+
+// sensitiveHeaders are never recorded verbatim
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+	"X-Debug-Capture":     true,
+	"X-Admin-Key":         true,
+}
+
+// sensitiveFields are JSON keys whose values are redacted, compared case-insensitively
+var sensitiveFields = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"api_key":       true,
+	"authorization": true,
+	"email":         true,
+}
+
+// SanitizeHeaders copies headers with credentials redacted
+func SanitizeHeaders(headers map[string][]string) map[string][]string {
+	sanitized := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			sanitized[name] = []string{redacted}
+			continue
+		}
+		sanitized[name] = append([]string(nil), values...)
+	}
+	return sanitized
+}
+
+// SanitizeBody redacts sensitive fields of a JSON body. Plain text bodies
+// are returned unchanged; JSON that cannot be parsed, such as a truncated
+// body, is omitted since its fields cannot be redacted.
+func SanitizeBody(body string) string {
+	if body == "" {
+		return body
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		if trimmed := strings.TrimSpace(body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			return unparseableBody
+		}
+		return body
+	}
+
+	data, err := json.Marshal(redact(doc))
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redact(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
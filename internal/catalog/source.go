@@ -0,0 +1,83 @@
+// Package catalog hydrates assets from the upstream asset catalog, the
+// system of record that owns asset content.
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// Source fetches assets from the upstream catalog. Fetch returns the asset
+// with the upstream version it was fetched at, domain.ErrAssetNotFound for
+// IDs the catalog does not know, and domain.ErrCatalogUnavailable when the
+// catalog cannot answer.
+type Source interface {
+	Fetch(ctx context.Context, assetID string) (domain.Asset, string, error)
+}
+
+// HTTPSource reads assets from an HTTP catalog serving GET /assets/{assetID}
+// with the asset as JSON, in the same format as this API accepts
+type HTTPSource struct {
+	client  *httpclient.Client
+	baseURL string
+	token   string
+}
+
+var _ Source = (*HTTPSource)(nil)
+
+// NewHTTPSource creates a source for the catalog at baseURL, e.g.
+// https://catalog.example.com/v1. token, when set, is sent as a bearer token.
+func NewHTTPSource(client *httpclient.Client, baseURL, token string) *HTTPSource {
+	return &HTTPSource{client: client, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+// Fetch gets /assets/{assetID}. The version is the response's ETag, or its
+// Last-Modified date when the catalog sends no ETag.
+func (s *HTTPSource) Fetch(ctx context.Context, assetID string) (domain.Asset, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/assets/"+url.PathEscape(assetID), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", domain.ErrCatalogUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", domain.ErrAssetNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("%w: status %d", domain.ErrCatalogUnavailable, resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, "", fmt.Errorf("%w: decoding asset: %v", domain.ErrCatalogUnavailable, err)
+	}
+	asset, err := domain.AssetFromJSON(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: decoding asset: %v", domain.ErrCatalogUnavailable, err)
+	}
+	if asset.GetID() != assetID {
+		return nil, "", fmt.Errorf("%w: asked for asset %q, got %q", domain.ErrCatalogUnavailable, assetID, asset.GetID())
+	}
+
+	version := strings.Trim(strings.TrimPrefix(resp.Header.Get("ETag"), "W/"), `"`)
+	if version == "" {
+		version = resp.Header.Get("Last-Modified")
+	}
+	return asset, version, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"gwi-favorites-service/internal/secrets"
+	"gwi-favorites-service/pkg/awsv4"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// SecretResolver replaces secret references with their values
+type SecretResolver interface {
+	Resolve(ctx context.Context, value string) (string, error)
+}
+
+// newSecretsResolver registers the secret stores that are configured
+func newSecretsResolver(cfg *Config) *secrets.Resolver {
+	providers := make(map[string]secrets.Provider)
+	client := httpclient.NewDefault()
+
+	if cfg.VaultAddr != "" {
+		providers[secrets.SchemeVault] = secrets.NewVault(client, cfg.VaultAddr, cfg.VaultToken)
+	}
+	if cfg.AWSRegion != "" {
+		providers[secrets.SchemeAWS] = secrets.NewAWSSecretsManager(client, cfg.AWSRegion, cfg.SecretsAWSEndpoint, cfg.AWSCredentials())
+	}
+
+	return secrets.NewResolver(providers, cfg.SecretsCacheTTL)
+}
+
+// AWSCredentials returns the configured AWS credentials
+func (c *Config) AWSCredentials() awsv4.Credentials {
+	return awsv4.Credentials{
+		AccessKeyID:     c.AWSAccessKeyID,
+		SecretAccessKey: c.AWSSecretAccessKey,
+		SessionToken:    c.AWSSessionToken,
+	}
+}
+
+// ResolveSecrets replaces secret references in the settings holding
+// credentials. Other settings are taken literally. Errors name the setting
+// but never include a secret value.
+func (c *Config) ResolveSecrets(ctx context.Context, resolver SecretResolver) error {
+	fields := []struct {
+		env   string
+		value *string
+	}{
+		{"JWT_SECRET", &c.JWTSecret},
+		{"ADMIN_API_KEY", &c.AdminAPIKey},
+		{"AUTH_LOGIN_DEFAULT_PASSWORD", &c.LoginDefaultPassword},
+		{"POSTGRES_DSN", &c.PostgresDSN},
+		{"MYSQL_DSN", &c.MySQLDSN},
+		{"REDIS_PASSWORD", &c.RedisPassword},
+		{"SCIM_TOKEN", &c.SCIMToken},
+		{"USER_DIRECTORY_TOKEN", &c.UserDirectoryToken},
+		{"ASSET_SOURCE_TOKEN", &c.AssetSourceToken},
+		{"DEBUG_CAPTURE_KEY", &c.DebugCaptureKey},
+		{"BACKUP_ENCRYPTION_KEY", &c.BackupEncryptionKey},
+	}
+
+	for _, field := range fields {
+		if !secrets.IsReference(*field.value) {
+			continue
+		}
+		value, err := resolver.Resolve(ctx, *field.value)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", field.env, err)
+		}
+		*field.value = value
+	}
+
+	for user, password := range c.LoginPasswords {
+		if !secrets.IsReference(password) {
+			continue
+		}
+		value, err := resolver.Resolve(ctx, password)
+		if err != nil {
+			return fmt.Errorf("resolving AUTH_LOGIN_PASSWORDS for %s: %w", user, err)
+		}
+		c.LoginPasswords[user] = value
+	}
+
+	return nil
+}
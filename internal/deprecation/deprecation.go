@@ -0,0 +1,152 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kind is what a deprecation applies to
+type Kind string
+
+const (
+	// KindRoute matches a named route, e.g. favorites.count
+	KindRoute Kind = "route"
+	// KindPath matches request paths by prefix, e.g. /api/users
+	KindPath Kind = "path"
+	// KindQuery matches requests using a query parameter, e.g. version
+	KindQuery Kind = "query"
+	// KindHeader matches requests sending a header, e.g. X-API-Version
+	KindHeader Kind = "header"
+)
+
+// Policy deprecates one feature. After Sunset the feature may be retired.
+type Policy struct {
+	Kind         Kind      `json:"kind"`
+	Name         string    `json:"name"`
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	// Sunset is zero when no retirement date is set
+	Sunset time.Time `json:"sunset,omitempty"`
+}
+
+// Feature identifies the policy in metrics and logs, e.g. "route:favorites.count"
+func (p Policy) Feature() string {
+	return string(p.Kind) + ":" + p.Name
+}
+
+// Parse reads deprecation policies of the form
+//
+//	route:favorites.count=2025-01-01,2025-07-01;query:version=2025-03-01
+//
+// Policies are separated by semicolons. Each names a kind and feature, the
+// date it was deprecated and an optional sunset date.
+func Parse(spec string) ([]Policy, error) {
+	var policies []Policy
+
+	for _, definition := range strings.Split(spec, ";") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		feature, dates, found := strings.Cut(definition, "=")
+		kind, name, hasKind := strings.Cut(strings.TrimSpace(feature), ":")
+		if !found || !hasKind || name == "" {
+			return nil, fmt.Errorf("deprecation %q: expected kind:name=date[,sunset]", definition)
+		}
+
+		policy := Policy{Kind: Kind(kind), Name: name}
+		switch policy.Kind {
+		case KindRoute, KindPath, KindQuery:
+		case KindHeader:
+			policy.Name = http.CanonicalHeaderKey(name)
+		default:
+			return nil, fmt.Errorf("deprecation %q: unknown kind %q", definition, kind)
+		}
+
+		deprecatedAt, sunset, hasSunset := strings.Cut(dates, ",")
+		var err error
+		if policy.DeprecatedAt, err = time.Parse(time.DateOnly, strings.TrimSpace(deprecatedAt)); err != nil {
+			return nil, fmt.Errorf("deprecation %q: invalid date %q", definition, deprecatedAt)
+		}
+		if hasSunset {
+			if policy.Sunset, err = time.Parse(time.DateOnly, strings.TrimSpace(sunset)); err != nil {
+				return nil, fmt.Errorf("deprecation %q: invalid sunset date %q", definition, sunset)
+			}
+			if policy.Sunset.Before(policy.DeprecatedAt) {
+				return nil, fmt.Errorf("deprecation %q: sunset precedes deprecation", definition)
+			}
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// Engine matches requests against deprecation policies
+type Engine struct {
+	policies []Policy
+	// enforceSunset retires features once their sunset date has passed
+	enforceSunset bool
+	now           func() time.Time
+}
+
+// NewEngine creates an engine. With enforceSunset, requests using a feature
+// past its sunset date are refused.
+func NewEngine(policies []Policy, enforceSunset bool) *Engine {
+	return &Engine{policies: policies, enforceSunset: enforceSunset, now: time.Now}
+}
+
+// Policies returns the configured policies
+func (e *Engine) Policies() []Policy {
+	return append([]Policy(nil), e.policies...)
+}
+
+// Match returns the policies in effect for a request to the named route,
+// earliest sunset first
+func (e *Engine) Match(r *http.Request, route string) []Policy {
+	now := e.now()
+	var matched []Policy
+
+	for _, policy := range e.policies {
+		if now.Before(policy.DeprecatedAt) {
+			continue
+		}
+
+		var ok bool
+		switch policy.Kind {
+		case KindRoute:
+			ok = route == policy.Name
+		case KindPath:
+			ok = strings.HasPrefix(r.URL.Path, policy.Name)
+		case KindQuery:
+			ok = r.URL.Query().Has(policy.Name)
+		case KindHeader:
+			ok = r.Header.Get(policy.Name) != ""
+		}
+		if ok {
+			matched = append(matched, policy)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return sunsetOrder(matched[i]).Before(sunsetOrder(matched[j]))
+	})
+	return matched
+}
+
+// Retired reports whether the policy's feature should no longer be served
+func (e *Engine) Retired(policy Policy) bool {
+	return e.enforceSunset && !policy.Sunset.IsZero() && !e.now().Before(policy.Sunset)
+}
+
+// sunsetOrder sorts policies without a sunset last
+func sunsetOrder(p Policy) time.Time {
+	if p.Sunset.IsZero() {
+		return time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return p.Sunset
+}
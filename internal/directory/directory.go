@@ -0,0 +1,146 @@
+// Package directory resolves user IDs against the platform's system of
+// record for users, so favorites are only created for real users.
+package directory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// UserDirectory looks up users in an external directory. LookupUser returns
+// domain.ErrUserNotFound for IDs the directory does not know, and
+// domain.ErrDirectoryUnavailable when the directory cannot answer.
+type UserDirectory interface {
+	LookupUser(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// CacheOptions configures a Cached directory
+type CacheOptions struct {
+	// TTL of found users
+	TTL time.Duration
+	// NegativeTTL of unknown IDs; kept short so new users are found quickly
+	NegativeTTL time.Duration
+	// MaxEntries bounds memory use
+	MaxEntries int
+}
+
+// DefaultCacheOptions returns the defaults applied to zero options
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		TTL:         5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		MaxEntries:  10000,
+	}
+}
+
+// Stats counts cache lookups
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type cacheEntry struct {
+	user    *domain.User
+	expires time.Time
+}
+
+// Cached remembers directory answers. Unavailability is never cached, so
+// lookups retry the directory once it recovers.
+type Cached struct {
+	directory UserDirectory
+	opts      CacheOptions
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+var _ UserDirectory = (*Cached)(nil)
+
+// NewCached wraps directory with a cache
+func NewCached(directory UserDirectory, opts CacheOptions) *Cached {
+	defaults := DefaultCacheOptions()
+	if opts.TTL <= 0 {
+		opts.TTL = defaults.TTL
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = defaults.NegativeTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaults.MaxEntries
+	}
+	return &Cached{
+		directory: directory,
+		opts:      opts,
+		now:       time.Now,
+		entries:   make(map[string]cacheEntry),
+	}
+}
+
+// LookupUser returns the cached answer or asks the directory
+func (c *Cached) LookupUser(ctx context.Context, userID string) (*domain.User, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		c.hits.Add(1)
+		if entry.user == nil {
+			return nil, domain.ErrUserNotFound
+		}
+		return entry.user, nil
+	}
+	c.misses.Add(1)
+
+	user, err := c.directory.LookupUser(ctx, userID)
+	switch {
+	case err == nil:
+		c.store(userID, cacheEntry{user: user, expires: now.Add(c.opts.TTL)})
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.store(userID, cacheEntry{expires: now.Add(c.opts.NegativeTTL)})
+	}
+	return user, err
+}
+
+// Stats returns the hit and miss counts since startup
+func (c *Cached) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+func (c *Cached) store(userID string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[userID]; !exists && len(c.entries) >= c.opts.MaxEntries {
+		c.evict()
+	}
+	c.entries[userID] = entry
+}
+
+// evict drops expired entries, or the entry closest to expiry when none
+// have expired
+func (c *Cached) evict() {
+	now := c.now()
+	var oldest string
+	for userID, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, userID)
+			continue
+		}
+		if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+			oldest = userID
+		}
+	}
+	if len(c.entries) >= c.opts.MaxEntries {
+		delete(c.entries, oldest)
+	}
+}
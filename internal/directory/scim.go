@@ -0,0 +1,88 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// SCIM resolves users through a SCIM 2.0 service provider (RFC 7644), as
+// exposed by most identity providers and the platform user service
+type SCIM struct {
+	client  *httpclient.Client
+	baseURL string
+	token   string
+}
+
+var _ UserDirectory = (*SCIM)(nil)
+
+// NewSCIM creates a directory for the SCIM endpoint at baseURL, e.g.
+// https://idp.example.com/scim/v2. token, when set, is sent as a bearer token.
+func NewSCIM(client *httpclient.Client, baseURL, token string) *SCIM {
+	return &SCIM{client: client, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+// scimUser holds the SCIM core user attributes used here
+type scimUser struct {
+	ID          string `json:"id"`
+	UserName    string `json:"userName"`
+	DisplayName string `json:"displayName"`
+	Active      *bool  `json:"active"`
+	Emails      []struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	} `json:"emails"`
+}
+
+// LookupUser fetches /Users/{userID}. Users marked inactive are treated as
+// unknown.
+func (s *SCIM) LookupUser(ctx context.Context, userID string) (*domain.User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/Users/"+url.PathEscape(userID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/scim+json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrDirectoryUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, domain.ErrUserNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: status %d", domain.ErrDirectoryUnavailable, resp.StatusCode)
+	}
+
+	var found scimUser
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("%w: decoding user: %v", domain.ErrDirectoryUnavailable, err)
+	}
+	if found.Active != nil && !*found.Active {
+		return nil, domain.ErrUserNotFound
+	}
+
+	var email string
+	for _, candidate := range found.Emails {
+		if email == "" || candidate.Primary {
+			email = candidate.Value
+		}
+	}
+	name := found.DisplayName
+	if name == "" {
+		name = found.UserName
+	}
+
+	return domain.NewUser(userID, email, name), nil
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"sort"
 	"time"
 )
 
@@ -13,6 +14,20 @@ const (
 	AssetTypeAudience AssetType = "audience"
 )
 
+// AssetTypes lists every supported asset type
+func AssetTypes() []AssetType {
+	return []AssetType{AssetTypeChart, AssetTypeInsight, AssetTypeAudience}
+}
+
+// IsValid reports whether the asset type is one of the supported types
+func (t AssetType) IsValid() bool {
+	switch t {
+	case AssetTypeChart, AssetTypeInsight, AssetTypeAudience:
+		return true
+	}
+	return false
+}
+
 // Asset interface defines common behavior for all asset types
 type Asset interface {
 	GetID() string
@@ -22,7 +37,17 @@ type Asset interface {
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	SetUpdatedAt(time.Time)
+	GetDeletedAt() *time.Time
+	MarkDeleted(time.Time)
+	GetLastSyncedAt() *time.Time
+	GetSourceVersion() string
+	MarkSynced(version string, t time.Time)
+	GetOwnerID() string
+	SetOwnerID(ownerID string)
 	Validate() error
+	// Normalize puts fields holding sets of values in canonical form, so
+	// logically identical assets compare, hash and diff alike
+	Normalize()
 }
 
 // BaseAsset contains common fields for all assets
@@ -32,6 +57,16 @@ type BaseAsset struct {
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// DeletedAt marks an asset removed from the catalog whose favorites were kept
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// LastSyncedAt and SourceVersion are set on assets hydrated from the
+	// upstream catalog: when they were last fetched, and the upstream
+	// version fetched
+	LastSyncedAt  *time.Time `json:"last_synced_at,omitempty"`
+	SourceVersion string     `json:"source_version,omitempty"`
+	// OwnerID is the user who created the asset in the catalog, notified
+	// of engagement with it when they subscribe to owner webhooks
+	OwnerID string `json:"owner_id,omitempty"`
 }
 
 func (b *BaseAsset) GetID() string          { return b.ID }
@@ -44,6 +79,19 @@ func (b *BaseAsset) SetDescription(desc string) {
 func (b *BaseAsset) GetCreatedAt() time.Time  { return b.CreatedAt }
 func (b *BaseAsset) GetUpdatedAt() time.Time  { return b.UpdatedAt }
 func (b *BaseAsset) SetUpdatedAt(t time.Time) { b.UpdatedAt = t }
+func (b *BaseAsset) GetDeletedAt() *time.Time { return b.DeletedAt }
+func (b *BaseAsset) MarkDeleted(t time.Time) {
+	b.DeletedAt = &t
+	b.UpdatedAt = t
+}
+func (b *BaseAsset) GetLastSyncedAt() *time.Time { return b.LastSyncedAt }
+func (b *BaseAsset) GetSourceVersion() string    { return b.SourceVersion }
+func (b *BaseAsset) GetOwnerID() string          { return b.OwnerID }
+func (b *BaseAsset) SetOwnerID(ownerID string)   { b.OwnerID = ownerID }
+func (b *BaseAsset) MarkSynced(version string, t time.Time) {
+	b.LastSyncedAt = &t
+	b.SourceVersion = version
+}
 
 // Chart represents a chart asset
 type Chart struct {
@@ -52,6 +100,17 @@ type Chart struct {
 	XAxisTitle string           `json:"x_axis_title"`
 	YAxisTitle string           `json:"y_axis_title"`
 	Data       []ChartDataPoint `json:"data"`
+	// DataRef is set when Data was too large to store inline and was moved
+	// to the blob store. Listings then carry the reference without Data.
+	DataRef *BlobRef `json:"data_ref,omitempty"`
+}
+
+// BlobRef points at content held in the asset blob store
+type BlobRef struct {
+	Key    string `json:"key"`
+	Size   int    `json:"size"`
+	Points int    `json:"points"`
+	SHA256 string `json:"sha256"`
 }
 
 type ChartDataPoint struct {
@@ -69,6 +128,9 @@ func (c *Chart) Validate() error {
 	return nil
 }
 
+// Normalize leaves charts alone; data points are ordered
+func (c *Chart) Normalize() {}
+
 // Insight represents an insight asset
 type Insight struct {
 	BaseAsset
@@ -87,23 +149,89 @@ func (i *Insight) Validate() error {
 	return nil
 }
 
+func (i *Insight) Normalize() {
+	i.Tags = normalizeSet(i.Tags)
+}
+
 // Audience represents an audience asset
 type Audience struct {
 	BaseAsset
-	Gender             []string `json:"gender,omitempty"`
-	BirthCountries     []string `json:"birth_countries,omitempty"`
-	AgeGroups          []string `json:"age_groups,omitempty"`
-	SocialMediaHours   string   `json:"social_media_hours,omitempty"`
-	PurchasesLastMonth int      `json:"purchases_last_month,omitempty"`
+	Gender             []string         `json:"gender,omitempty"`
+	BirthCountries     []string         `json:"birth_countries,omitempty"`
+	AgeGroups          []AgeGroup       `json:"age_groups,omitempty"`
+	SocialMediaHours   SocialMediaHours `json:"social_media_hours,omitempty"`
+	PurchasesLastMonth int              `json:"purchases_last_month,omitempty"`
 }
 
 func (a *Audience) Validate() error {
 	if a.ID == "" {
 		return ErrMissingRequiredField
 	}
+	for _, group := range a.AgeGroups {
+		if !group.IsValid() {
+			return WithContext(ErrInvalidInput, "field", "age_groups")
+		}
+	}
+	if !a.SocialMediaHours.IsValid() {
+		return WithContext(ErrInvalidInput, "field", "social_media_hours")
+	}
 	return nil
 }
 
+// Normalize also rewrites age groups and social media hours given in a
+// legacy form canonically
+func (a *Audience) Normalize() {
+	a.Gender = normalizeSet(a.Gender)
+	a.BirthCountries = normalizeSet(a.BirthCountries)
+	a.AgeGroups = normalizeSet(canonicalAgeGroups(a.AgeGroups))
+	a.SocialMediaHours = SocialMediaHours(canonicalBand(string(a.SocialMediaHours), maxSocialMediaHours))
+}
+
+// canonicalAgeGroups returns groups in canonical form, copying them first
+// when any needs rewriting
+func canonicalAgeGroups(groups []AgeGroup) []AgeGroup {
+	for i, group := range groups {
+		canonical := AgeGroup(canonicalBand(string(group), maxAge))
+		if canonical == group {
+			continue
+		}
+		rewritten := append([]AgeGroup(nil), groups...)
+		rewritten[i] = canonical
+		for j := i + 1; j < len(rewritten); j++ {
+			rewritten[j] = AgeGroup(canonicalBand(string(rewritten[j]), maxAge))
+		}
+		return rewritten
+	}
+	return groups
+}
+
+// normalizeSet returns values sorted and without duplicates. Values
+// needing changes are copied first, as they may share a caller's array.
+func normalizeSet[T ~string](values []T) []T {
+	if isNormalSet(values) {
+		return values
+	}
+	sorted := append([]T(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	unique := sorted[:1]
+	for _, value := range sorted[1:] {
+		if value != unique[len(unique)-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// isNormalSet reports whether values are strictly increasing
+func isNormalSet[T ~string](values []T) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i-1] >= values[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // AssetFromJSON creates assets from JSON
 func AssetFromJSON(data []byte) (Asset, error) {
 	var base struct {
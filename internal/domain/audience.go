@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AgeGroup is an age band in years, written "25-34", or "65+" for a band
+// with no upper bound. Legacy free-text forms such as "25 to 34 years" or
+// "over 65" are read as the band they describe.
+type AgeGroup string
+
+// SocialMediaHours is a band of daily hours spent on social media,
+// written like age groups: "1-3", or "3+". Legacy forms such as
+// "1-3 hours" or "less than 1" are read as the band they describe.
+type SocialMediaHours string
+
+// Upper limits of the bands; open bands are written with "+" instead
+const (
+	maxAge              = 120
+	maxSocialMediaHours = 24
+)
+
+// ParseAgeGroup reads an age group in its canonical or a legacy form
+func ParseAgeGroup(text string) (AgeGroup, error) {
+	b, ok := parseBand(text, maxAge)
+	if !ok {
+		return "", WithContext(ErrInvalidInput, "field", "age_groups")
+	}
+	return AgeGroup(b.String()), nil
+}
+
+// IsValid reports whether the age group can be read as a band
+func (g AgeGroup) IsValid() bool {
+	_, ok := parseBand(string(g), maxAge)
+	return ok
+}
+
+// Bounds returns the band's youngest and oldest ages. max is -1 for an
+// open band; ok is false when the group cannot be read.
+func (g AgeGroup) Bounds() (min, max int, ok bool) {
+	b, ok := parseBand(string(g), maxAge)
+	return b.min, b.max, ok
+}
+
+// Contains reports whether age falls in the band
+func (g AgeGroup) Contains(age int) bool {
+	b, ok := parseBand(string(g), maxAge)
+	return ok && b.contains(age)
+}
+
+// UnmarshalText reads the canonical form of a legacy age group. Text that
+// cannot be read is kept as it is, so stored assets still load; Validate
+// refuses it when the asset is next written.
+func (g *AgeGroup) UnmarshalText(text []byte) error {
+	*g = AgeGroup(canonicalBand(string(text), maxAge))
+	return nil
+}
+
+// ParseSocialMediaHours reads daily social media hours in their canonical
+// or a legacy form
+func ParseSocialMediaHours(text string) (SocialMediaHours, error) {
+	b, ok := parseBand(text, maxSocialMediaHours)
+	if !ok {
+		return "", WithContext(ErrInvalidInput, "field", "social_media_hours")
+	}
+	return SocialMediaHours(b.String()), nil
+}
+
+// IsValid reports whether the hours can be read as a band. Empty hours,
+// meaning unknown, are valid.
+func (h SocialMediaHours) IsValid() bool {
+	_, ok := parseBand(string(h), maxSocialMediaHours)
+	return ok || h == ""
+}
+
+// Bounds returns the band's fewest and most hours. max is -1 for an open
+// band; ok is false when the hours are empty or cannot be read.
+func (h SocialMediaHours) Bounds() (min, max int, ok bool) {
+	b, ok := parseBand(string(h), maxSocialMediaHours)
+	return b.min, b.max, ok
+}
+
+// Contains reports whether hours falls in the band
+func (h SocialMediaHours) Contains(hours int) bool {
+	b, ok := parseBand(string(h), maxSocialMediaHours)
+	return ok && b.contains(hours)
+}
+
+// UnmarshalText reads the canonical form of legacy hours, keeping text
+// that cannot be read as AgeGroup.UnmarshalText does
+func (h *SocialMediaHours) UnmarshalText(text []byte) error {
+	*h = SocialMediaHours(canonicalBand(string(text), maxSocialMediaHours))
+	return nil
+}
+
+// band is an inclusive range of whole numbers; max is -1 when it is open
+type band struct {
+	min, max int
+}
+
+func (b band) String() string {
+	if b.max < 0 {
+		return strconv.Itoa(b.min) + "+"
+	}
+	return strconv.Itoa(b.min) + "-" + strconv.Itoa(b.max)
+}
+
+func (b band) contains(n int) bool {
+	return n >= b.min && (b.max < 0 || n <= b.max)
+}
+
+// Legacy spellings of bands, matched after lower-casing and dropping units
+var (
+	bandRange = regexp.MustCompile(`^(\d+)\s*(?:-|–|—|to)\s*(\d+)$`)
+	bandAbove = regexp.MustCompile(`^(?:(\d+)\s*(?:\+|or more|and over|and above)|(?:over|more than|above|>=?)\s*(\d+))$`)
+	bandBelow = regexp.MustCompile(`^(?:under|less than|below|up to|<=?)\s*(\d+)$`)
+	bandExact = regexp.MustCompile(`^(\d+)$`)
+	bandUnits = regexp.MustCompile(`\s*(?:years? old|years?|yrs?|y/o|hours?|hrs?|h)?\s*(?:a day|per day|/day|daily)?$`)
+)
+
+// parseBand reads a band in canonical or legacy form, with bounds at most
+// limit. Open bands keep their lower bound as written, so "more than 3"
+// and "3+" are the same band.
+func parseBand(text string, limit int) (band, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.TrimSpace(bandUnits.ReplaceAllString(text, ""))
+
+	var b band
+	switch {
+	case bandRange.MatchString(text):
+		match := bandRange.FindStringSubmatch(text)
+		b.min, _ = strconv.Atoi(match[1])
+		b.max, _ = strconv.Atoi(match[2])
+	case bandAbove.MatchString(text):
+		match := bandAbove.FindStringSubmatch(text)
+		b.min, _ = strconv.Atoi(match[1] + match[2])
+		b.max = -1
+	case bandBelow.MatchString(text):
+		match := bandBelow.FindStringSubmatch(text)
+		b.max, _ = strconv.Atoi(match[1])
+	case bandExact.MatchString(text):
+		b.min, _ = strconv.Atoi(text)
+		b.max = b.min
+	default:
+		return band{}, false
+	}
+
+	if b.min > limit || b.max > limit || (b.max >= 0 && b.min > b.max) {
+		return band{}, false
+	}
+	return b, true
+}
+
+// canonicalBand rewrites text in canonical form when it can be read
+func canonicalBand(text string, limit int) string {
+	if b, ok := parseBand(text, limit); ok {
+		return b.String()
+	}
+	return text
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// MaxCollectionNameLength caps collection names, in bytes
+const MaxCollectionNameLength = 100
+
+// Collection is a named folder a user sorts their favorites into. A
+// favorite can be in any number of the user's collections, and leaves them
+// all when it is removed from favorites.
+type Collection struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	Name          string    `json:"name"`
+	FavoriteCount int       `json:"favorite_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewCollectionAt creates an empty collection created at now
+func NewCollectionAt(id, userID, name string, now time.Time) *Collection {
+	return &Collection{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -1,27 +1,179 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Error is a domain error with a stable, machine-readable code. Domain
+// errors are sentinels: compare with errors.Is, since callers may wrap them
+// with context.
+type Error struct {
+	Code    string
+	message string
+}
+
+func newError(code, message string) *Error {
+	return &Error{Code: code, message: message}
+}
+
+func (e *Error) Error() string { return e.message }
 
 var (
 	// Asset errors
-	ErrAssetNotFound      = errors.New("asset not found")
-	ErrInvalidAssetType   = errors.New("invalid asset type")
-	ErrAssetAlreadyExists = errors.New("asset already exists")
+	ErrAssetNotFound      = newError("asset_not_found", "asset not found")
+	ErrInvalidAssetType   = newError("invalid_asset_type", "invalid asset type")
+	ErrAssetAlreadyExists = newError("asset_already_exists", "asset already exists")
+	ErrInvalidAssetID     = newError("invalid_asset_id", "invalid asset ID")
+	ErrAssetInUse         = newError("asset_in_use", "asset is still favorited")
+	ErrAssetDeleted       = newError("asset_deleted", "asset has been deleted")
+	ErrAssetNotFeatured   = newError("asset_not_featured", "asset is not featured")
 
 	// User errors
-	ErrUserNotFound  = errors.New("user not found")
-	ErrInvalidUserID = errors.New("invalid user ID")
+	ErrUserNotFound      = newError("user_not_found", "user not found")
+	ErrInvalidUserID     = newError("invalid_user_id", "invalid user ID")
+	ErrUserAlreadyExists = newError("user_already_exists", "user already exists")
+	// ErrDirectoryUnavailable is returned when the user directory cannot
+	// confirm whether a user exists
+	ErrDirectoryUnavailable = newError("directory_unavailable", "user directory unavailable")
+	// ErrCatalogUnavailable is returned when the upstream asset catalog
+	// cannot be reached or returns something unusable
+	ErrCatalogUnavailable = newError("catalog_unavailable", "asset catalog unavailable")
 
 	// Favorite errors
-	ErrFavoriteNotFound      = errors.New("favorite not found")
-	ErrFavoriteAlreadyExists = errors.New("favorite already exists")
-	ErrMaxFavoritesReached   = errors.New("maximum favorites limit reached")
+	ErrFavoriteNotFound      = newError("favorite_not_found", "favorite not found")
+	ErrFavoriteAlreadyExists = newError("favorite_already_exists", "favorite already exists")
+	ErrMaxFavoritesReached   = newError("max_favorites_reached", "maximum favorites limit reached")
+	// ErrAssetTypeBlocked is returned when the caller's role may not
+	// favorite assets of this type
+	ErrAssetTypeBlocked = newError("asset_type_blocked", "asset type not allowed for role")
+
+	// Collection errors
+	ErrCollectionNotFound      = newError("collection_not_found", "collection not found")
+	ErrCollectionAlreadyExists = newError("collection_already_exists", "a collection with this name already exists")
+	ErrNotInCollection         = newError("not_in_collection", "favorite is not in the collection")
+
+	// Trash errors
+	ErrTrashEmpty = newError("trash_empty", "no recently removed favorites")
+
+	// Storage errors
+	ErrStorageLimitReached = newError("storage_limit_reached", "storage limit reached")
+	ErrNotSupported        = newError("not_supported", "operation not supported by storage backend")
+	// ErrReadOnly refuses writes on read-only replicas, which point callers
+	// at the primary
+	ErrReadOnly = newError("read_only", "this instance is read-only")
+
+	// Backup errors
+	ErrBackupNotFound   = newError("backup_not_found", "backup not found")
+	ErrBackupInProgress = newError("backup_in_progress", "a backup or restore is already running")
+
+	// Taxonomy errors
+	ErrTaxonomyKeyNotFound = newError("taxonomy_key_not_found", "no display names for taxonomy key")
+
+	// Moderation errors
+	ErrAssetHidden     = newError("asset_hidden", "asset hidden by moderation")
+	ErrAlreadyReported = newError("already_reported", "asset already reported by this user")
+	ErrReportNotFound  = newError("report_not_found", "no reports for asset")
+
+	// Owner webhook errors
+	ErrWebhookNotFound = newError("webhook_not_found", "no webhook subscription for user")
+
+	// Share link errors
+	ErrShareNotFound = newError("share_not_found", "no share link")
 
 	// Validation errors
-	ErrInvalidInput         = errors.New("invalid input")
-	ErrMissingRequiredField = errors.New("missing required field")
+	ErrInvalidInput         = newError("invalid_input", "invalid input")
+	ErrMissingRequiredField = newError("missing_required_field", "missing required field")
+	ErrFieldTooLong         = newError("field_too_long", "field exceeds maximum length")
+
+	// API lifecycle errors
+	ErrSunset = newError("sunset", "feature has been retired")
 
 	// Auth errors
-	ErrUnauthorized = errors.New("unauthorized")
-	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = newError("unauthorized", "unauthorized")
+	// ErrInvalidCredentials is a failed login
+	ErrInvalidCredentials = newError("invalid_credentials", "invalid credentials")
+	ErrForbidden          = newError("forbidden", "forbidden")
+	ErrRateLimited        = newError("rate_limited", "rate limited")
+	// ErrTooManyConcurrent is distinct from ErrRateLimited so clients can
+	// tell parallelism apart from request volume
+	ErrTooManyConcurrent = newError("too_many_concurrent_requests", "too many concurrent requests")
+	// ErrQueryCostExceeded refuses expensive queries once the caller's
+	// query cost budget is spent, while cheap requests may still pass
+	ErrQueryCostExceeded = newError("query_cost_exceeded", "query cost budget exceeded")
 )
+
+// ContextError attaches identifying context, such as the user and asset
+// IDs involved, to an error
+type ContextError struct {
+	Err    error
+	Fields map[string]string
+}
+
+func (e *ContextError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + e.Fields[key]
+	}
+	return e.Err.Error() + " (" + strings.Join(pairs, ", ") + ")"
+}
+
+func (e *ContextError) Unwrap() error { return e.Err }
+
+// WithContext wraps err with key/value context pairs. Empty values are
+// skipped, and context already on err is kept. A nil err stays nil.
+func WithContext(err error, keyvals ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	fields := ContextOf(err)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i+1] != "" {
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[keyvals[i]] = keyvals[i+1]
+		}
+	}
+	if fields == nil {
+		return err
+	}
+
+	// Merge into a direct ContextError rather than nesting another one
+	if existing, ok := err.(*ContextError); ok {
+		err = existing.Err
+	}
+	return &ContextError{Err: err, Fields: fields}
+}
+
+// CodeOf returns the code of the domain error in err's chain, or "" when
+// err is not a domain error
+func CodeOf(err error) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ""
+}
+
+// ContextOf returns a copy of the context attached to err, or nil
+func ContextOf(err error) map[string]string {
+	var contextErr *ContextError
+	if !errors.As(err, &contextErr) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(contextErr.Fields))
+	for key, value := range contextErr.Fields {
+		fields[key] = value
+	}
+	return fields
+}
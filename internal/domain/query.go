@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// SortField is the favorite attribute a listing is ordered by
+type SortField string
+
+const (
+	SortAddedAt   SortField = "added_at"
+	SortUpdatedAt SortField = "updated_at"
+	SortType      SortField = "type"
+	// SortTitle orders by chart title, insight content or audience
+	// description, ignoring case
+	SortTitle SortField = "title"
+	// SortPosition orders by the user's manual order, with favorites that
+	// have not been placed after those that have
+	SortPosition SortField = "position"
+)
+
+// IsValid reports whether the sort field is one of the supported fields
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortAddedAt, SortUpdatedAt, SortType, SortTitle, SortPosition:
+		return true
+	}
+	return false
+}
+
+// FavoritesQuery describes which of a user's favorites to return and how to page through them
+type FavoritesQuery struct {
+	// Type restricts results to a single asset type; empty means all types
+	Type AssetType
+	// Search restricts results to assets whose title, description, insight
+	// content or tags contain every whitespace-separated term, ignoring case
+	Search string
+	// AddedSince and AddedUntil restrict results to favorites added at or
+	// after AddedSince and before AddedUntil; zero times leave them open
+	AddedSince time.Time
+	AddedUntil time.Time
+	// Sort orders results; empty means SortAddedAt. Ties are broken by
+	// added_at, then asset ID, in the same direction. Pinned favorites
+	// come first whatever the sort.
+	Sort       SortField
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
+// AssetQuery describes which catalog assets to return and how to page
+// through them. Offsets count every asset scanned, including those filtered
+// out: backends that search the catalog themselves scan only the assets of
+// the type searched for that contain the terms, others scan the whole
+// catalog.
+type AssetQuery struct {
+	// Type restricts results to a single asset type; empty means all types
+	Type AssetType
+	// Search matches assets as FavoritesQuery.Search does
+	Search string
+	// IncludeUnavailable keeps deleted assets and those hidden by moderation
+	IncludeUnavailable bool
+	Limit              int
+	Offset             int
+}
+
+// AssetPage is a page of catalog assets. There is no total, as counting
+// would scan the whole catalog; NextOffset is where the next page starts.
+type AssetPage struct {
+	Assets     []Asset `json:"assets"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextOffset *int    `json:"next_offset,omitempty"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// MaxSearchTerms bounds the terms of one search. Each term is a condition
+// of the storage query, so the bound also bounds the distinct queries a
+// backend prepares.
+const MaxSearchTerms = 8
+
+// SearchTerms returns the lower-cased terms of the search text
+func (q FavoritesQuery) SearchTerms() []string {
+	return strings.Fields(strings.ToLower(q.Search))
+}
+
+// Matches reports whether a favorite satisfies the query filters (pagination is not considered)
+func (q FavoritesQuery) Matches(favorite *UserFavorite) bool {
+	if q.Type != "" && (favorite.Asset == nil || favorite.Asset.GetType() != q.Type) {
+		return false
+	}
+	if (!q.AddedSince.IsZero() && favorite.AddedAt.Before(q.AddedSince)) ||
+		(!q.AddedUntil.IsZero() && !favorite.AddedAt.Before(q.AddedUntil)) {
+		return false
+	}
+	if terms := q.SearchTerms(); len(terms) > 0 {
+		return favorite.Asset != nil && containsTerms(favorite.Asset, terms)
+	}
+	return true
+}
+
+// SearchTerms returns the lower-cased terms of the search text
+func (q AssetQuery) SearchTerms() []string {
+	return strings.Fields(strings.ToLower(q.Search))
+}
+
+// Matches reports whether an asset satisfies the query's type and search
+// filters. Availability and pagination are not considered.
+func (q AssetQuery) Matches(asset Asset) bool {
+	if q.Type != "" && asset.GetType() != q.Type {
+		return false
+	}
+	return containsTerms(asset, q.SearchTerms())
+}
+
+// containsTerms reports whether the asset's searchable text contains every
+// lower-cased term
+func containsTerms(asset Asset, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text := strings.ToLower(SearchableText(asset))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// AssetSortTitle returns the lower-cased text an asset is ordered by for SortTitle
+func AssetSortTitle(asset Asset) string {
+	title := asset.GetDescription()
+	switch a := asset.(type) {
+	case *Chart:
+		title = a.Title
+	case *Insight:
+		title = a.Content
+	}
+	return strings.ToLower(title)
+}
+
+// SearchableText joins the asset fields covered by search
+func SearchableText(asset Asset) string {
+	fields := []string{asset.GetDescription()}
+	switch a := asset.(type) {
+	case *Chart:
+		fields = append(fields, a.Title)
+	case *Insight:
+		fields = append(fields, a.Content)
+		fields = append(fields, a.Tags...)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Pagination places a page of results within the full list, so clients can
+// render pagers without counting separately
+type Pagination struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"`
+	HasMore    bool `json:"has_more"`
+}
+
+// NewPagination describes the page at offset with up to limit results out of total
+func NewPagination(total, limit, offset int) Pagination {
+	p := Pagination{Total: total, Limit: limit, Offset: offset}
+	if next := offset + limit; limit > 0 && next < total {
+		p.NextOffset = &next
+		p.HasMore = true
+	}
+	return p
+}
@@ -13,16 +13,28 @@ type User struct {
 
 // UserFavorite represents the relationship between a user and their favorite asset
 type UserFavorite struct {
-	UserID    string    `json:"user_id"`
-	AssetID   string    `json:"asset_id"`
-	Asset     Asset     `json:"asset"`
+	UserID  string `json:"user_id"`
+	AssetID string `json:"asset_id"`
+	Asset   Asset  `json:"asset"`
+	// Note is the user's personal note on the asset; it never changes the
+	// shared asset other users see
+	Note string `json:"note,omitempty"`
+	// Pinned favorites are listed before all others
+	Pinned bool `json:"pinned,omitempty"`
+	// Position is the favorite's place in the user's manual order, from 1;
+	// 0 means it has not been placed
+	Position  int       `json:"position,omitempty"`
 	AddedAt   time.Time `json:"added_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // NewUser creates a new user
 func NewUser(id, email, name string) *User {
-	now := time.Now()
+	return NewUserAt(id, email, name, time.Now())
+}
+
+// NewUserAt creates a new user created at now
+func NewUserAt(id, email, name string, now time.Time) *User {
 	return &User{
 		ID:        id,
 		Email:     email,
@@ -34,7 +46,11 @@ func NewUser(id, email, name string) *User {
 
 // NewUserFavorite creates a new user favorite relationship
 func NewUserFavorite(userID string, asset Asset) *UserFavorite {
-	now := time.Now()
+	return NewUserFavoriteAt(userID, asset, time.Now())
+}
+
+// NewUserFavoriteAt creates a new user favorite relationship added at now
+func NewUserFavoriteAt(userID string, asset Asset, now time.Time) *UserFavorite {
 	return &UserFavorite{
 		UserID:    userID,
 		AssetID:   asset.GetID(),
@@ -0,0 +1,340 @@
+// Package engagement tells asset owners when users favorite or unfavorite
+// their assets, batching the changes into periodic webhook deliveries so a
+// popular asset cannot flood its owner
+package engagement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/metrics"
+	"gwi-favorites-service/pkg/webhook"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventHeader names the event of a delivery
+const EventHeader = "X-Webhook-Event"
+
+// EventFavorites is the event of batched favorite activity
+const EventFavorites = "favorites.engagement"
+
+// Action is a change to an asset's favorites
+type Action string
+
+const (
+	Favorited   Action = "favorited"
+	Unfavorited Action = "unfavorited"
+)
+
+// Subscription is where an owner receives engagement webhooks. The secret
+// signs deliveries and is never returned.
+type Subscription struct {
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AssetActivity counts the favorite changes to one asset within a batch.
+// Who favorited is not shared with the owner.
+type AssetActivity struct {
+	AssetID     string `json:"asset_id"`
+	Favorited   int    `json:"favorited"`
+	Unfavorited int    `json:"unfavorited"`
+}
+
+// Batch is the payload of one delivery: an owner's activity since from
+type Batch struct {
+	Event   string          `json:"event"`
+	OwnerID string          `json:"owner_id"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Assets  []AssetActivity `json:"assets"`
+}
+
+type pending struct {
+	from   time.Time
+	assets map[string]*AssetActivity
+}
+
+// Notifier keeps owner subscriptions in memory and collects favorite
+// activity per owner until Flush delivers it. Activity on assets of owners
+// without a subscription is not kept.
+type Notifier struct {
+	client        *httpclient.Client
+	logger        *logrus.Logger
+	now           func() time.Time
+	allowPrivate  bool
+	lookupTimeout time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+	pending       map[string]*pending
+}
+
+// Option configures a Notifier
+type Option func(*Notifier)
+
+// WithPrivateTargets lets owners subscribe endpoints on loopback, private
+// and link-local addresses, for deployments whose owners live on an
+// internal network
+func WithPrivateTargets() Option {
+	return func(n *Notifier) {
+		n.allowPrivate = true
+	}
+}
+
+// NewNotifier creates a notifier delivering with cfg. Unless
+// WithPrivateTargets is given, deliveries refuse to connect to anything
+// but public addresses, whatever the subscribed host resolves to by then.
+func NewNotifier(cfg httpclient.Config, logger *logrus.Logger, opts ...Option) *Notifier {
+	n := &Notifier{
+		logger:        logger,
+		now:           time.Now,
+		lookupTimeout: 5 * time.Second,
+		subscriptions: make(map[string]Subscription),
+		pending:       make(map[string]*pending),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	if !n.allowPrivate {
+		cfg.DialControl = publicOnly
+	}
+	n.client = httpclient.New(cfg)
+	return n
+}
+
+// publicAddress reports whether ip may receive webhooks. Loopback,
+// private, link-local (cloud metadata lives at 169.254.169.254),
+// unspecified and multicast addresses are refused.
+func publicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() &&
+		!ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// publicOnly is the dial check of deliveries, run on the address actually
+// being connected to so a host that resolves differently since it was
+// subscribed cannot reach internal services
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// checkHost refuses a subscription whose host is, or resolves to, an
+// address deliveries would refuse
+func (n *Notifier) checkHost(host string) error {
+	if n.allowPrivate {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicAddress(ip) {
+			return domain.WithContext(domain.ErrInvalidInput, "field", "url")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.lookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "url")
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr.IP) {
+			return domain.WithContext(domain.ErrInvalidInput, "field", "url")
+		}
+	}
+	return nil
+}
+
+// Subscribe sends ownerID's engagement to rawURL, signed with secret,
+// replacing any previous subscription. The URL's host must resolve to
+// public addresses only, unless WithPrivateTargets was given.
+func (n *Notifier) Subscribe(ownerID, rawURL, secret string) (Subscription, error) {
+	if ownerID == "" {
+		return Subscription{}, domain.ErrInvalidUserID
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Subscription{}, domain.WithContext(domain.ErrInvalidInput, "field", "url")
+	}
+	if err := n.checkHost(parsed.Hostname()); err != nil {
+		return Subscription{}, err
+	}
+	if secret == "" {
+		return Subscription{}, domain.WithContext(domain.ErrMissingRequiredField, "field", "secret")
+	}
+
+	sub := Subscription{URL: rawURL, Secret: secret, CreatedAt: n.now()}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscriptions[ownerID] = sub
+	return sub, nil
+}
+
+// Subscription returns ownerID's subscription
+func (n *Notifier) Subscription(ownerID string) (Subscription, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sub, ok := n.subscriptions[ownerID]
+	if !ok {
+		return Subscription{}, domain.WithContext(domain.ErrWebhookNotFound, "user_id", ownerID)
+	}
+	return sub, nil
+}
+
+// Unsubscribe stops ownerID's deliveries, dropping activity not yet sent
+func (n *Notifier) Unsubscribe(ownerID string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.subscriptions[ownerID]; !ok {
+		return domain.WithContext(domain.ErrWebhookNotFound, "user_id", ownerID)
+	}
+	delete(n.subscriptions, ownerID)
+	delete(n.pending, ownerID)
+	return nil
+}
+
+// Record notes that userID changed their favorites of an asset owned by
+// ownerID. Owners are not told about their own favorites.
+func (n *Notifier) Record(ownerID, userID, assetID string, action Action) {
+	if n == nil || ownerID == "" || ownerID == userID {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.subscriptions[ownerID]; !ok {
+		return
+	}
+	batch, ok := n.pending[ownerID]
+	if !ok {
+		batch = &pending{from: n.now(), assets: make(map[string]*AssetActivity)}
+		n.pending[ownerID] = batch
+	}
+	activity, ok := batch.assets[assetID]
+	if !ok {
+		activity = &AssetActivity{AssetID: assetID}
+		batch.assets[assetID] = activity
+	}
+	switch action {
+	case Favorited:
+		activity.Favorited++
+	case Unfavorited:
+		activity.Unfavorited++
+	}
+}
+
+// Flush delivers each owner's pending activity as one batch. A failed
+// delivery is logged and dropped rather than retried on the next flush, so
+// a dead endpoint cannot build up a backlog.
+func (n *Notifier) Flush(ctx context.Context) {
+	n.mu.Lock()
+	due := n.pending
+	n.pending = make(map[string]*pending)
+	subscriptions := make(map[string]Subscription, len(due))
+	for ownerID := range due {
+		subscriptions[ownerID] = n.subscriptions[ownerID]
+	}
+	n.mu.Unlock()
+
+	to := n.now()
+	for ownerID, batch := range due {
+		payload := Batch{
+			Event:   EventFavorites,
+			OwnerID: ownerID,
+			From:    batch.from,
+			To:      to,
+			Assets:  make([]AssetActivity, 0, len(batch.assets)),
+		}
+		for _, activity := range batch.assets {
+			payload.Assets = append(payload.Assets, *activity)
+		}
+		sort.Slice(payload.Assets, func(i, j int) bool { return payload.Assets[i].AssetID < payload.Assets[j].AssetID })
+
+		outcome := "delivered"
+		if err := n.deliver(ctx, subscriptions[ownerID], payload); err != nil {
+			outcome = "failed"
+			n.logger.WithError(err).WithField("owner_id", ownerID).Warn("Failed to deliver engagement webhook")
+		}
+		metrics.DefaultRegistry.Counter("owner_webhook_deliveries_total", "Engagement webhook deliveries to asset owners", metrics.Labels{
+			"outcome": outcome,
+		}).Inc()
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, sub Subscription, batch Batch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, EventFavorites)
+	webhook.SignRequest(req, sub.Secret, body, n.now())
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Start flushes every interval until stop is called, which flushes what
+// is still pending and waits for delivery
+func (n *Notifier) Start(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				n.Flush(context.Background())
+			case <-done:
+				n.Flush(context.Background())
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
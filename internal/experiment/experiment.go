@@ -0,0 +1,161 @@
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variant is one arm of an experiment; Weight is its relative share of users
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// Experiment splits users between variants
+type Experiment struct {
+	Name     string    `json:"name"`
+	Variants []Variant `json:"variants"`
+}
+
+// Assignment is the variant a user sees in an experiment
+type Assignment struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+// Parse reads experiment definitions of the form
+//
+//	ranking=control:50,recency:50;layout=list,grid
+//
+// Experiments are separated by semicolons and variants by commas. A variant
+// weight defaults to 1.
+func Parse(spec string) ([]Experiment, error) {
+	var experiments []Experiment
+	seen := make(map[string]bool)
+
+	for _, definition := range strings.Split(spec, ";") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		name, variants, found := strings.Cut(definition, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("experiment %q: expected name=variants", definition)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("experiment %q defined twice", name)
+		}
+		seen[name] = true
+
+		experiment := Experiment{Name: name}
+		for _, variant := range strings.Split(variants, ",") {
+			variantName, weightText, hasWeight := strings.Cut(strings.TrimSpace(variant), ":")
+			if variantName == "" {
+				return nil, fmt.Errorf("experiment %q: empty variant name", name)
+			}
+
+			weight := 1
+			if hasWeight {
+				var err error
+				if weight, err = strconv.Atoi(weightText); err != nil || weight < 0 {
+					return nil, fmt.Errorf("experiment %q: invalid weight %q for variant %q", name, weightText, variantName)
+				}
+			}
+			experiment.Variants = append(experiment.Variants, Variant{Name: variantName, Weight: weight})
+		}
+
+		if totalWeight(experiment.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %q: variants have no weight", name)
+		}
+		experiments = append(experiments, experiment)
+	}
+
+	return experiments, nil
+}
+
+// ExposureSink receives an event each time a user is exposed to an assignment
+type ExposureSink func(userID string, assignment Assignment)
+
+// Assigner deterministically assigns users to experiment variants
+type Assigner struct {
+	experiments []Experiment
+	sink        ExposureSink
+}
+
+// NewAssigner creates an assigner; sink may be nil
+func NewAssigner(experiments []Experiment, sink ExposureSink) *Assigner {
+	sorted := append([]Experiment(nil), experiments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	return &Assigner{experiments: sorted, sink: sink}
+}
+
+// Experiments returns the configured experiments ordered by name
+func (a *Assigner) Experiments() []Experiment {
+	return a.experiments
+}
+
+// Assign returns the user's variant in every experiment. The same user
+// always lands in the same variant as long as the definition is unchanged,
+// and assignments in different experiments are independent.
+func (a *Assigner) Assign(userID string) []Assignment {
+	assignments := make([]Assignment, 0, len(a.experiments))
+	for _, experiment := range a.experiments {
+		assignments = append(assignments, Assignment{
+			Experiment: experiment.Name,
+			Variant:    pick(experiment, userID),
+		})
+	}
+	return assignments
+}
+
+// Expose assigns the user and records an exposure event for each assignment
+func (a *Assigner) Expose(userID string) []Assignment {
+	assignments := a.Assign(userID)
+	if a.sink != nil {
+		for _, assignment := range assignments {
+			a.sink(userID, assignment)
+		}
+	}
+	return assignments
+}
+
+// Header renders assignments for the X-Experiment response header,
+// e.g. "layout=grid, ranking=recency"
+func Header(assignments []Assignment) string {
+	parts := make([]string, len(assignments))
+	for i, assignment := range assignments {
+		parts[i] = assignment.Experiment + "=" + assignment.Variant
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pick hashes the experiment name with the user ID onto the weighted variants
+func pick(experiment Experiment, userID string) string {
+	h := fnv.New64a()
+	h.Write([]byte(experiment.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+
+	bucket := int(h.Sum64() % uint64(totalWeight(experiment.Variants)))
+	for _, variant := range experiment.Variants {
+		if bucket < variant.Weight {
+			return variant.Name
+		}
+		bucket -= variant.Weight
+	}
+	return experiment.Variants[len(experiment.Variants)-1].Name
+}
+
+func totalWeight(variants []Variant) int {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	return total
+}
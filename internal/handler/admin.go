@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/seed"
+	"gwi-favorites-service/internal/validation"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateAsset handles POST /api/admin/assets
+func (h *Handler) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	var rawAsset json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&rawAsset); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	asset, err := domain.AssetFromJSON(rawAsset)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	ctx, warnings := validation.WithWarnings(r.Context())
+	if err := h.assetService.CreateAsset(ctx, asset); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success:  true,
+		Data:     asset,
+		Warnings: warnings.List(),
+	})
+}
+
+// GetAsset handles GET /api/admin/assets/{assetID}
+func (h *Handler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+	maxAge, refresh, err := parseMinFreshness(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	ctx, warnings := validation.WithWarnings(r.Context())
+	var asset domain.Asset
+	if refresh {
+		asset, err = h.assetService.GetFreshAsset(ctx, assetID, maxAge)
+	} else {
+		asset, err = h.assetService.GetAsset(ctx, assetID)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success:  true,
+		Data:     asset,
+		Warnings: warnings.List(),
+	})
+}
+
+// UpdateAsset handles PUT /api/admin/assets/{assetID}, replacing the asset
+// for every favorite pointing at it
+func (h *Handler) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+
+	var rawAsset json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&rawAsset); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	asset, err := domain.AssetFromJSON(rawAsset)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	ctx, warnings := validation.WithWarnings(r.Context())
+	if err := h.assetService.UpdateAsset(ctx, assetID, asset); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success:  true,
+		Data:     asset,
+		Warnings: warnings.List(),
+	})
+}
+
+// DeleteAsset handles DELETE /api/admin/assets/{assetID}, applying the configured delete policy
+func (h *Handler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+
+	if err := h.assetService.DeleteAsset(r.Context(), assetID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Asset deleted"},
+	})
+}
+
+// GetPendingRemovals handles GET /api/admin/assets/removals
+func (h *Handler) GetPendingRemovals(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.assetService.PendingRemovals(),
+	})
+}
+
+// GetStorageStats handles GET /api/admin/storage/stats
+func (h *Handler) GetStorageStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.storageService.Stats(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// CompactStorage handles POST /api/admin/storage/compact
+func (h *Handler) CompactStorage(w http.ResponseWriter, r *http.Request) {
+	result, err := h.storageService.Compact(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// LogPolicyRequest is the wire representation of a RequestLogPolicy
+type LogPolicyRequest struct {
+	SampleRate    float64           `json:"sample_rate"`
+	SlowThreshold string            `json:"slow_threshold"`
+	ErrorsOnly    bool              `json:"errors_only"`
+	RouteLevels   map[string]string `json:"route_levels"`
+}
+
+// LogPolicyUpdate changes the request logging policy. Omitted members keep
+// their current values; route_levels, when given, replaces every route level.
+type LogPolicyUpdate struct {
+	SampleRate    *float64          `json:"sample_rate"`
+	SlowThreshold *string           `json:"slow_threshold"`
+	ErrorsOnly    *bool             `json:"errors_only"`
+	RouteLevels   map[string]string `json:"route_levels"`
+}
+
+// newLogPolicyRequest writes a policy in its wire representation
+func newLogPolicyRequest(policy *RequestLogPolicy) LogPolicyRequest {
+	routeLevels := make(map[string]string, len(policy.RouteLevels))
+	for route, level := range policy.RouteLevels {
+		routeLevels[route] = level.String()
+	}
+
+	return LogPolicyRequest{
+		SampleRate:    policy.SampleRate,
+		SlowThreshold: policy.SlowThreshold.String(),
+		ErrorsOnly:    policy.ErrorsOnly,
+		RouteLevels:   routeLevels,
+	}
+}
+
+// GetLogPolicy handles GET /api/admin/logging
+func (h *Handler) GetLogPolicy(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    newLogPolicyRequest(h.logPolicy.Load()),
+	})
+}
+
+// UpdateLogPolicy handles PUT /api/admin/logging, changing the request logging policy at runtime
+func (h *Handler) UpdateLogPolicy(w http.ResponseWriter, r *http.Request) {
+	var update LogPolicyUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	req := newLogPolicyRequest(h.logPolicy.Load())
+	if update.SampleRate != nil {
+		req.SampleRate = *update.SampleRate
+	}
+	if update.SlowThreshold != nil {
+		req.SlowThreshold = *update.SlowThreshold
+	}
+	if update.ErrorsOnly != nil {
+		req.ErrorsOnly = *update.ErrorsOnly
+	}
+	if update.RouteLevels != nil {
+		req.RouteLevels = update.RouteLevels
+	}
+
+	policy, err := ParseRequestLogPolicy(req.SampleRate, req.SlowThreshold, req.ErrorsOnly, req.RouteLevels)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.logPolicy.Store(&policy)
+	h.logger.WithFields(logrus.Fields{
+		"sample_rate":    policy.SampleRate,
+		"slow_threshold": policy.SlowThreshold,
+		"errors_only":    policy.ErrorsOnly,
+	}).Info("Request logging policy updated")
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Logging policy updated"},
+	})
+}
+
+const (
+	// maxSeedVolume bounds a single seed request
+	maxSeedVolume = 100000
+	// maxSeedFavorites bounds the favorites a single seed request generates
+	maxSeedFavorites = 1000000
+)
+
+// SeedRequest asks for generated data, either by explicit volume or by
+// naming a seed profile. Scale multiplies the profile's users and assets
+// and defaults to 1; explicit volumes are ignored when a profile is named.
+type SeedRequest struct {
+	Profile string  `json:"profile,omitempty"`
+	Scale   float64 `json:"scale,omitempty"`
+	seed.Options
+}
+
+// ListSeedProfiles handles GET /api/admin/seed/profiles
+func (h *Handler) ListSeedProfiles(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.seedProfiles.List(),
+	})
+}
+
+// GenerateSeedData handles POST /api/admin/seed
+func (h *Handler) GenerateSeedData(w http.ResponseWriter, r *http.Request) {
+	var req SeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	opts := req.Options
+	if req.Profile != "" {
+		profile, ok := h.seedProfiles[req.Profile]
+		if !ok {
+			h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "profile"))
+			return
+		}
+		if req.Scale < 0 {
+			h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "scale"))
+			return
+		}
+		if req.Scale == 0 {
+			req.Scale = 1
+		}
+		opts = profile.Scale(req.Scale)
+	}
+
+	if opts.Users < 0 || opts.Assets < 0 || opts.FavoritesPerUser < 0 ||
+		opts.Users > maxSeedVolume || opts.Assets > maxSeedVolume || opts.FavoritesPerUser > maxSeedVolume {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if opts.Users*min(opts.FavoritesPerUser, opts.Assets) > maxSeedFavorites {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "favorites_per_user", "max", strconv.Itoa(maxSeedFavorites)))
+		return
+	}
+
+	result, err := h.seedGenerator.Generate(opts)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// parseAssetQuery reads the paging, type filter and ?q= search of a catalog
+// listing, with the same limits as favorites listings
+func parseAssetQuery(r *http.Request) (domain.AssetQuery, error) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	query := domain.AssetQuery{
+		Type:   domain.AssetType(r.URL.Query().Get("type")),
+		Search: strings.TrimSpace(r.URL.Query().Get("q")),
+		Limit:  limit,
+		Offset: offset,
+	}
+	return query, checkSearch(query.Search)
+}
+
+// ListAssets handles GET /api/assets, browsing the catalog for assets to
+// favorite by ID. Deleted assets and those hidden by moderation are left out.
+func (h *Handler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAssetQuery(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.listAssets(w, r, query)
+}
+
+// ListAllAssets handles GET /api/admin/assets, which lists deleted and
+// hidden assets too
+func (h *Handler) ListAllAssets(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAssetQuery(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	query.IncludeUnavailable = true
+	h.listAssets(w, r, query)
+}
+
+func (h *Handler) listAssets(w http.ResponseWriter, r *http.Request, query domain.AssetQuery) {
+	page, err := h.assetService.ListAssets(r.Context(), query)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    page,
+	})
+}
+
+// GetCatalogAsset handles GET /api/assets/{assetID}. Unlike the admin
+// route it refuses deleted and hidden assets.
+func (h *Handler) GetCatalogAsset(w http.ResponseWriter, r *http.Request) {
+	asset, err := h.assetService.GetAvailableAsset(r.Context(), mux.Vars(r)["assetID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    asset,
+	})
+}
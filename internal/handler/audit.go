@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/domain"
+)
+
+const (
+	// maxAuditLimit bounds a single audit query; larger limits are capped
+	maxAuditLimit = 1000
+	// auditExportFlushEvery is how many exported entries are sent at a time
+	auditExportFlushEvery = 500
+	// auditExportStall is how long an export may wait on a slow client
+	// before giving up; the server's write timeout does not apply
+	auditExportStall = time.Minute
+)
+
+// WithAuditLog enables the audit query admin routes
+func WithAuditLog(log audit.Log) Option {
+	return func(h *Handler) {
+		h.auditLog = log
+	}
+}
+
+// CursorPagination accompanies pages of endpoints paged by cursor. Next is
+// passed as ?cursor= to fetch the following page.
+type CursorPagination struct {
+	Limit   int    `json:"limit"`
+	Next    string `json:"next_cursor,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// GetAuditLog handles GET /api/admin/audit. Entries are filtered by the
+// actor, action, user_id and asset_id parameters, each of which may be
+// repeated or list values separated by commas, and by the RFC 3339 since
+// and until times. They are returned newest first, a page at a time.
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	var warnings []string
+	filter.Limit = audit.DefaultLimit
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "limit"))
+			return
+		}
+		if filter.Limit > maxAuditLimit {
+			filter.Limit = maxAuditLimit
+			warnings = append(warnings, fmt.Sprintf("limit capped at %d", maxAuditLimit))
+		}
+	}
+	if filter.Before, err = parseAuditCursor(query.Get("cursor"), "cursor"); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	// One entry past the page tells whether there is another
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := h.auditLog.Query(filter)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	pagination := &CursorPagination{Limit: limit}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		pagination.HasMore = true
+		pagination.Next = strconv.FormatUint(entries[limit-1].ID, 10)
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success:  true,
+		Data:     entries,
+		Warnings: warnings,
+		Cursor:   pagination,
+	})
+}
+
+// ExportAuditLog handles GET /api/admin/audit/export, streaming every
+// entry matching the filters of GetAuditLog as newline-delimited JSON,
+// oldest first. An interrupted export resumes with ?after= set to the ID
+// of the last entry received. A failure once entries are being sent can
+// only be reported by cutting the download short.
+func (h *Handler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if filter.After, err = parseAuditCursor(r.URL.Query().Get("after"), "after"); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	extendDeadline := func() {
+		// Writers that cannot take deadlines are left as they are
+		controller.SetWriteDeadline(time.Now().Add(auditExportStall))
+	}
+	extendDeadline()
+
+	// Headers are sent with the first entry so errors found before then
+	// still get a regular error response
+	started := false
+	start := func() {
+		started = true
+		filename := fmt.Sprintf("audit-%s.ndjson", time.Now().UTC().Format("20060102"))
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+	}
+
+	encoder := json.NewEncoder(w)
+	sent := 0
+	err = h.auditLog.Scan(filter, func(entry audit.Entry) error {
+		if !started {
+			start()
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		if sent++; sent%auditExportFlushEvery == 0 {
+			controller.Flush()
+			extendDeadline()
+		}
+		return r.Context().Err()
+	})
+	if err != nil {
+		if !started {
+			h.handleError(w, r, err)
+			return
+		}
+		h.logger.WithError(err).WithField("entries", sent).Error("Audit export cut short")
+		return
+	}
+	if !started {
+		start()
+	}
+}
+
+// parseAuditFilter reads the entry filters shared by the audit routes
+func parseAuditFilter(r *http.Request) (audit.Filter, error) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Actors:   auditValues(query["actor"]),
+		UserIDs:  auditValues(query["user_id"]),
+		AssetIDs: auditValues(query["asset_id"]),
+	}
+	for _, action := range auditValues(query["action"]) {
+		filter.Actions = append(filter.Actions, audit.Action(action))
+	}
+
+	var err error
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(param); value != "" {
+			if *target, err = time.Parse(time.RFC3339, value); err != nil {
+				return audit.Filter{}, domain.WithContext(domain.ErrInvalidInput, "field", param)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// auditValues splits the comma-separated values of a repeatable parameter
+func auditValues(params []string) []string {
+	var values []string
+	for _, param := range params {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseAuditCursor reads an entry ID cursor; empty means none
+func parseAuditCursor(value, field string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, domain.WithContext(domain.ErrInvalidInput, "field", field)
+	}
+	return id, nil
+}
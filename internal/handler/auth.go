@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// routeCapabilities is public so clients can discover how to authenticate
+const routeCapabilities = "capabilities"
+
+// publicRoutes are served without a bearer token
+var publicRoutes = map[string]bool{
+	routeCapabilities: true,
+	routeLogin:        true,
+	routeRefresh:      true,
+	routeOpenAPI:      true,
+	// Share links are their own credential
+	routeSharedFavorites: true,
+}
+
+// WithAuthenticator requires a valid bearer token on API routes
+func WithAuthenticator(verifier auth.Verifier) Option {
+	return func(h *Handler) {
+		h.verifier = verifier
+	}
+}
+
+// WithAdminRole sets the role claim that grants access to admin routes and
+// to every user's favorites when authentication is enabled
+func WithAdminRole(role string) Option {
+	return func(h *Handler) {
+		h.adminRole = role
+	}
+}
+
+// WithAdminAPIKey lets operators reach admin routes by sending key in
+// X-Admin-Key instead of a token with the admin role
+func WithAdminAPIKey(key string) Option {
+	return func(h *Handler) {
+		h.adminKey = key
+	}
+}
+
+// adminKeyHeader carries the admin API key
+const adminKeyHeader = "X-Admin-Key"
+
+// WithRateLimiter limits API requests per caller, with the budget chosen
+// by the role claim of the caller's token
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.rateLimiter = limiter
+	}
+}
+
+// WithConcurrencyLimiter caps the requests each caller may have in flight
+func WithConcurrencyLimiter(limiter *ratelimit.ConcurrencyLimiter) Option {
+	return func(h *Handler) {
+		h.concurrency = limiter
+	}
+}
+
+// AuthMiddleware verifies the bearer token and stores its claims in the
+// request context
+func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && publicRoutes[route.GetName()] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if h.hasAdminKey(r) {
+			next.ServeHTTP(w, h.withAdminKeyClaims(r))
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.handleError(w, r, domain.ErrUnauthorized)
+			return
+		}
+		claims, err := h.verifier.Verify(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			h.handleError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+	})
+}
+
+// OwnerMiddleware lets authenticated callers reach only their own
+// {userID} routes, unless they hold the admin role
+func (h *Handler) OwnerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := mux.Vars(r)["userID"]
+		if ok && !h.mayActAs(r, userID) {
+			h.handleError(w, r, domain.WithContext(domain.ErrForbidden, "user_id", userID))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminMiddleware restricts admin routes to callers holding the admin role
+// or the admin API key
+func (h *Handler) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.isAdmin(r) {
+			h.handleError(w, r, domain.ErrForbidden)
+			return
+		}
+		if h.hasAdminKey(r) {
+			r = h.withAdminKeyClaims(r)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mayActAs reports whether the caller may read and change userID's data.
+// Without authentication every caller may.
+func (h *Handler) mayActAs(r *http.Request, userID string) bool {
+	if h.verifier == nil || h.isAdmin(r) {
+		return true
+	}
+	claims := auth.ClaimsFromContext(r.Context())
+	return claims != nil && claims.Subject == userID
+}
+
+func (h *Handler) isAdmin(r *http.Request) bool {
+	if h.hasAdminKey(r) {
+		return true
+	}
+	claims := auth.ClaimsFromContext(r.Context())
+	return claims != nil && h.adminRole != "" && claims.Role == h.adminRole
+}
+
+// adminKeySubject identifies admin API key callers, e.g. in the audit log
+const adminKeySubject = "admin_api_key"
+
+// withAdminKeyClaims attributes a request carrying the admin API key to an
+// admin caller
+func (h *Handler) withAdminKeyClaims(r *http.Request) *http.Request {
+	if auth.ClaimsFromContext(r.Context()) != nil {
+		return r
+	}
+	return r.WithContext(auth.WithClaims(r.Context(), &auth.Claims{Subject: adminKeySubject, Role: h.adminRole}))
+}
+
+// hasAdminKey reports whether the request carries the admin API key
+func (h *Handler) hasAdminKey(r *http.Request) bool {
+	key := r.Header.Get(adminKeyHeader)
+	return h.adminKey != "" && key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(h.adminKey)) == 1
+}
+
+// RateLimitMiddleware applies the caller's tier. Authenticated callers are
+// limited per subject, anonymous callers per client address.
+func (h *Handler) RateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() == routeCallerUsage {
+			next.ServeHTTP(w, r)
+			return
+		}
+		role, key := callerKey(r)
+		decision := h.rateLimiter.Allow(role, key)
+		w.Header().Set("X-RateLimit-Tier", decision.Tier)
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+
+		outcome := "allowed"
+		if !decision.Allowed {
+			outcome = "limited"
+		}
+		metrics.DefaultRegistry.Counter("rate_limit_requests_total", "API requests checked against a rate limit tier", metrics.Labels{
+			"tier":    decision.Tier,
+			"outcome": outcome,
+		}).Inc()
+
+		if !decision.Allowed {
+			seconds := int(decision.RetryAfter.Seconds())
+			if decision.RetryAfter > 0 && seconds == 0 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			h.handleError(w, r, domain.ErrRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConcurrencyMiddleware caps the requests each caller has in flight. It is
+// keyed like RateLimitMiddleware and refuses excess requests immediately
+// rather than queueing them.
+func (h *Handler) ConcurrencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, key := callerKey(r)
+		release, ok := h.concurrency.Acquire(key)
+		if !ok {
+			metrics.DefaultRegistry.Counter("concurrency_limited_requests_total", "API requests refused for exceeding the per caller concurrency limit", nil).Inc()
+			w.Header().Set("Retry-After", "1")
+			h.handleError(w, r, domain.WithContext(domain.ErrTooManyConcurrent, "limit", strconv.Itoa(h.concurrency.Max())))
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerKey identifies the caller by token subject, falling back to the
+// client address for anonymous requests, along with the caller's role
+func callerKey(r *http.Request) (role, key string) {
+	if claims := auth.ClaimsFromContext(r.Context()); claims != nil {
+		return claims.Role, "sub:" + claims.Subject
+	}
+	return "", clientAddr(r)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "addr:" + r.RemoteAddr
+	}
+	return "addr:" + host
+}
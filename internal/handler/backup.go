@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/backup"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// WithBackups enables the backup admin routes
+func WithBackups(manager *backup.Manager) Option {
+	return func(h *Handler) {
+		h.backups = manager
+	}
+}
+
+// CountBackup records the outcome of a backup; trigger is manual or schedule
+func CountBackup(trigger string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	metrics.DefaultRegistry.Counter("backups_total", "Backups taken by trigger and outcome", metrics.Labels{"trigger": trigger, "outcome": outcome}).Inc()
+}
+
+// ListBackups handles GET /api/admin/backups
+func (h *Handler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.backups.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    backups,
+	})
+}
+
+// CreateBackup handles POST /api/admin/backups, taking a backup now
+func (h *Handler) CreateBackup(w http.ResponseWriter, r *http.Request) {
+	info, err := h.backups.Backup(r.Context())
+	CountBackup("manual", err)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"backup": info.Name,
+		"bytes":  info.Size,
+	}).Info("Backup created")
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data:    info,
+	})
+}
+
+// RestoreBackup handles POST /api/admin/backups/{name}/restore. Data
+// already in storage is kept.
+func (h *Handler) RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	result, err := h.backups.Restore(r.Context(), name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"backup":    name,
+		"assets":    result.Assets,
+		"users":     result.Users,
+		"favorites": result.Favorites,
+		"skipped":   result.Skipped,
+	}).Warn("Backup restored")
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
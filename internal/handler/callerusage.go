@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/ratelimit"
+)
+
+// WithRequestHistory counts each caller's recent requests and enables
+// GET /api/users/{userID}/usage
+func WithRequestHistory(history *ratelimit.History) Option {
+	return func(h *Handler) {
+		h.requestHistory = history
+	}
+}
+
+// routeCallerUsage is exempt from rate limits, so that throttled callers
+// can still see when they may retry
+const routeCallerUsage = "caller.usage"
+
+// CallerUsage is the caller's standing against the API limits. Limits that
+// are not enforced are left out.
+type CallerUsage struct {
+	RateLimit   *RateLimitUsage         `json:"rate_limit,omitempty"`
+	QueryCost   *QueryCostUsage         `json:"query_cost,omitempty"`
+	Concurrency *ConcurrencyUsage       `json:"concurrency,omitempty"`
+	Requests    ratelimit.RequestCounts `json:"requests"`
+}
+
+// RateLimitUsage is the state of the caller's request budget
+type RateLimitUsage struct {
+	Tier string `json:"tier"`
+	// Rate is requests per second on average, with bursts of up to Burst
+	Rate      float64 `json:"rate"`
+	Burst     int     `json:"burst"`
+	Remaining int     `json:"remaining"`
+	// RetryAfterSeconds is set while the caller is throttled
+	RetryAfterSeconds float64 `json:"retry_after_seconds,omitempty"`
+}
+
+// QueryCostUsage is the state of the caller's query cost budget
+type QueryCostUsage struct {
+	BudgetPerMinute int `json:"budget_per_minute"`
+	Remaining       int `json:"remaining"`
+}
+
+// ConcurrencyUsage counts the caller's requests in flight, this one included
+type ConcurrencyUsage struct {
+	Max      int `json:"max"`
+	InFlight int `json:"in_flight"`
+}
+
+// RequestHistoryMiddleware counts each caller's requests, and those refused
+// with 429, for GetCallerUsage. It is keyed like RateLimitMiddleware.
+func (h *Handler) RequestHistoryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := &responseWriterWrapper{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+
+		_, key := callerKey(r)
+		h.requestHistory.Record(key, wrapped.statusCode == http.StatusTooManyRequests)
+	})
+}
+
+// GetCallerUsage handles GET /api/users/{userID}/usage, reporting the
+// caller's rate limit, query cost budget, requests in flight and recent
+// requests, so clients can tell why they are throttled. Limits are keyed by
+// caller, so admins acting for another user see their own standing.
+func (h *Handler) GetCallerUsage(w http.ResponseWriter, r *http.Request) {
+	role, key := callerKey(r)
+	usage := CallerUsage{Requests: h.requestHistory.Counts(key)}
+
+	if h.rateLimiter != nil {
+		tier := h.rateLimiter.Tier(role)
+		decision := h.rateLimiter.Peek(role, key)
+		usage.RateLimit = &RateLimitUsage{
+			Tier:              tier.Name,
+			Rate:              tier.Rate,
+			Burst:             tier.Burst,
+			Remaining:         decision.Remaining,
+			RetryAfterSeconds: decision.RetryAfter.Seconds(),
+		}
+	}
+	if h.queryCosts != nil {
+		decision := h.queryCosts.Peek(key)
+		usage.QueryCost = &QueryCostUsage{BudgetPerMinute: decision.Limit, Remaining: decision.Remaining}
+	}
+	if h.concurrency != nil {
+		usage.Concurrency = &ConcurrencyUsage{Max: h.concurrency.Max(), InFlight: h.concurrency.InFlight(key)}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendResponse(w, http.StatusOK, APIResponse{Success: true, Data: usage})
+}
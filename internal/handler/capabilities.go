@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/serializer"
+)
+
+// Deployment describes environment-specific settings reported by
+// GET /api/capabilities. Empty fields are reported as "none".
+type Deployment struct {
+	StorageBackend string
+	Cache          string
+	Search         string
+	EventTransport string
+	UserDirectory  string
+	AuthModes      []string
+}
+
+// Capabilities lets clients feature-detect instead of hardcoding
+// differences between environments
+type Capabilities struct {
+	Service        string               `json:"service"`
+	StorageBackend string               `json:"storage_backend"`
+	Cache          string               `json:"cache"`
+	Search         string               `json:"search"`
+	EventTransport string               `json:"event_transport"`
+	UserDirectory  string               `json:"user_directory"`
+	AuthModes      []string             `json:"auth_modes"`
+	AssetTypes     []domain.AssetType   `json:"asset_types"`
+	APIVersions    []serializer.Version `json:"api_versions"`
+	DefaultVersion serializer.Version   `json:"default_api_version"`
+	RateLimitTiers []ratelimit.Tier     `json:"rate_limit_tiers,omitempty"`
+	// QueryCostBudget is the cost units each caller may spend per minute
+	QueryCostBudget int      `json:"query_cost_budget,omitempty"`
+	Features        []string `json:"features"`
+}
+
+// WithDeployment sets the deployment details reported as capabilities
+func WithDeployment(deployment Deployment) Option {
+	return func(h *Handler) {
+		h.deployment = deployment
+	}
+}
+
+// Capabilities describes what this handler serves. Features follow the
+// optional dependencies the handler was built with.
+func (h *Handler) Capabilities() Capabilities {
+	features := []string{"favorites", "etag"}
+	if h.favoritesService.SupportsCollections() {
+		features = append(features, "collections")
+	}
+	if h.favoritesService.SupportsOrdering() {
+		features = append(features, "ordering")
+	}
+	if h.favoritesService.SupportsUndo() {
+		features = append(features, "undo")
+	}
+	if h.favoritesService.SupportsTransfers() {
+		features = append(features, "favorites_transfer")
+	}
+	if h.verifier != nil {
+		features = append(features, "authentication")
+	}
+	if h.authService != nil {
+		features = append(features, "login")
+	}
+	if h.rateLimiter != nil {
+		features = append(features, "rate_limits")
+	}
+	if h.concurrency != nil {
+		features = append(features, "concurrency_limits")
+	}
+	if h.queryCosts != nil {
+		features = append(features, "query_costs")
+	}
+	if h.requestHistory != nil {
+		features = append(features, "caller_usage")
+	}
+	if h.readOnly {
+		features = append(features, "read_only")
+	}
+	if h.idValidator != nil {
+		features = append(features, "id_validation")
+	}
+	if h.experiments != nil {
+		features = append(features, "experiments")
+	}
+	if h.moderation != nil {
+		features = append(features, "moderation")
+	}
+	if h.assetService != nil {
+		features = append(features, "asset_deletion", "featured_assets")
+	}
+	if h.assetService != nil && h.assetService.HasSource() {
+		features = append(features, "asset_freshness")
+	}
+	if h.notices != nil {
+		features = append(features, "notifications")
+	}
+	if h.engagement != nil {
+		features = append(features, "owner_webhooks")
+	}
+	if h.storageService != nil {
+		features = append(features, "storage_admin")
+	}
+	if h.seedGenerator != nil {
+		features = append(features, "seed")
+	}
+	if h.seedGenerator != nil && h.seedProfiles != nil {
+		features = append(features, "seed_profiles")
+	}
+	if h.captures != nil {
+		features = append(features, "debug_capture")
+	}
+	if h.deprecations != nil {
+		features = append(features, "deprecations")
+	}
+	if h.auditLog != nil {
+		features = append(features, "audit_log")
+	}
+	if h.users != nil {
+		features = append(features, "user_management")
+	}
+	if h.users != nil && h.scimToken != "" {
+		features = append(features, "scim_provisioning")
+	}
+	if h.backups != nil {
+		features = append(features, "backups")
+	}
+	if h.taxonomy != nil {
+		features = append(features, "localized_taxonomy")
+	}
+	if h.usage != nil {
+		features = append(features, "usage_summary")
+	}
+	if h.healthScorer != nil {
+		features = append(features, "health_score")
+	}
+	if h.shares != nil {
+		features = append(features, "share_links")
+	}
+
+	authModes := h.deployment.AuthModes
+	if authModes == nil {
+		authModes = []string{}
+	}
+
+	var tiers []ratelimit.Tier
+	if h.rateLimiter != nil {
+		tiers = h.rateLimiter.Tiers()
+	}
+	var costBudget int
+	if h.queryCosts != nil {
+		costBudget = h.queryCosts.PerMinute()
+	}
+
+	return Capabilities{
+		Service:         "gwi-favorites-service",
+		StorageBackend:  orNone(h.deployment.StorageBackend),
+		Cache:           orNone(h.deployment.Cache),
+		Search:          orNone(h.deployment.Search),
+		EventTransport:  orNone(h.deployment.EventTransport),
+		UserDirectory:   orNone(h.deployment.UserDirectory),
+		AuthModes:       authModes,
+		AssetTypes:      domain.AssetTypes(),
+		APIVersions:     h.serializers.Versions(),
+		DefaultVersion:  serializer.DefaultVersion,
+		RateLimitTiers:  tiers,
+		QueryCostBudget: costBudget,
+		Features:        features,
+	}
+}
+
+// GetCapabilities handles GET /api/capabilities
+func (h *Handler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{Success: true, Data: h.Capabilities()})
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"gwi-favorites-service/internal/capture"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/tracing"
+
+	"github.com/gorilla/mux"
+)
+
+// captureHeader requests capture of a single request; its value must match
+// the configured capture key
+const captureHeader = "X-Debug-Capture"
+
+// WithDebugCapture records sanitized request and response bodies for users
+// an admin enabled capture for, and for requests carrying the capture key
+// in X-Debug-Capture. An empty key disables per-request capture.
+func WithDebugCapture(store *capture.Store, key string) Option {
+	return func(h *Handler) {
+		h.captures = store
+		h.captureKey = key
+	}
+}
+
+// EnableCaptureRequest is the body of PUT /api/admin/debug/sessions/{userID}
+type EnableCaptureRequest struct {
+	// Duration such as "15m"; empty uses the maximum window
+	Duration string `json:"duration"`
+}
+
+// CaptureMiddleware records requests selected for debug capture
+func (h *Handler) CaptureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := mux.Vars(r)["userID"]
+		if !h.shouldCapture(r, userID) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := h.captures.MaxBodyBytes()
+		requestBody, truncated := readPrefix(r, limit)
+		recorder := &captureWriter{ResponseWriter: w, statusCode: http.StatusOK, limit: limit}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, r)
+
+		recording := capture.Recording{
+			UserID:          userID,
+			Method:          r.Method,
+			Path:            r.URL.Path,
+			Query:           r.URL.RawQuery,
+			Status:          recorder.statusCode,
+			Duration:        time.Since(start),
+			RequestHeaders:  r.Header,
+			RequestBody:     requestBody,
+			ResponseHeaders: w.Header(),
+			ResponseBody:    recorder.body.String(),
+			Truncated:       truncated || recorder.truncated,
+		}
+		if sc, ok := tracing.SpanFromContext(r.Context()); ok {
+			recording.TraceID = sc.TraceID
+		}
+		h.captures.Record(recording)
+	})
+}
+
+func (h *Handler) shouldCapture(r *http.Request, userID string) bool {
+	if userID != "" && h.captures.Active(userID) {
+		return true
+	}
+	value := r.Header.Get(captureHeader)
+	return h.captureKey != "" && value != "" &&
+		subtle.ConstantTimeCompare([]byte(value), []byte(h.captureKey)) == 1
+}
+
+// GetCaptures handles GET /api/admin/debug/captures, optionally filtered by ?user_id=
+func (h *Handler) GetCaptures(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.captures.Recordings(r.URL.Query().Get("user_id")),
+	})
+}
+
+// GetCaptureSessions handles GET /api/admin/debug/sessions
+func (h *Handler) GetCaptureSessions(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.captures.Sessions(),
+	})
+}
+
+// EnableCapture handles PUT /api/admin/debug/sessions/{userID}
+func (h *Handler) EnableCapture(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	var req EnableCaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	var window time.Duration
+	if req.Duration != "" {
+		var err error
+		if window, err = time.ParseDuration(req.Duration); err != nil || window <= 0 {
+			h.handleError(w, r, domain.ErrInvalidInput)
+			return
+		}
+	}
+
+	h.logger.WithField("user_id", userID).Warn("Debug capture enabled")
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.captures.Enable(userID, window),
+	})
+}
+
+// DisableCapture handles DELETE /api/admin/debug/sessions/{userID}
+func (h *Handler) DisableCapture(w http.ResponseWriter, r *http.Request) {
+	h.captures.Disable(mux.Vars(r)["userID"])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readPrefix reads up to limit bytes of the request body for recording and
+// leaves the full body readable by the handler
+func readPrefix(r *http.Request, limit int) (string, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", false
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	truncated := len(prefix) > limit
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	if err != nil {
+		return "", false
+	}
+	if truncated {
+		prefix = prefix[:limit]
+	}
+	return string(prefix), truncated
+}
+
+// captureWriter keeps the first limit bytes of the response body
+type captureWriter struct {
+	http.ResponseWriter
+	statusCode int
+	limit      int
+	body       bytes.Buffer
+	truncated  bool
+}
+
+func (w *captureWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.body.Len(); room > 0 {
+		if len(p) > room {
+			w.body.Write(p[:room])
+			w.truncated = true
+		} else {
+			w.body.Write(p)
+		}
+	} else if len(p) > 0 {
+		w.truncated = true
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateCollectionRequest is the body of POST /api/users/{userID}/collections
+type CreateCollectionRequest struct {
+	Name string `json:"name"`
+}
+
+// AddToCollectionRequest is the body of POST /api/users/{userID}/collections/{collectionID}/favorites
+type AddToCollectionRequest struct {
+	AssetID string `json:"asset_id"`
+}
+
+// ListCollections handles GET /api/users/{userID}/collections
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.favoritesService.ListCollections(r.Context(), mux.Vars(r)["userID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    collections,
+	})
+}
+
+// CreateCollection handles POST /api/users/{userID}/collections
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	collection, err := h.favoritesService.CreateCollection(r.Context(), mux.Vars(r)["userID"], req.Name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data:    collection,
+	})
+}
+
+// DeleteCollection handles DELETE /api/users/{userID}/collections/{collectionID}
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.favoritesService.DeleteCollection(r.Context(), vars["userID"], vars["collectionID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Collection deleted successfully"},
+	})
+}
+
+// ListCollectionFavorites handles GET /api/users/{userID}/collections/{collectionID},
+// taking the same paging, filter, ordering and freshness parameters as
+// GetUserFavorites
+func (h *Handler) ListCollectionFavorites(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	query, err := parseFavoritesQuery(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	maxAge, refresh, err := parseMinFreshness(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	favorites, pagination, err := h.favoritesService.ListCollectionFavorites(r.Context(), vars["userID"], vars["collectionID"], query)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	warnings := h.refreshFavorites(r.Context(), favorites, maxAge, refresh)
+
+	w.Header().Set(apiVersionHeader, string(version))
+	response := APIResponse{
+		Success:    true,
+		Data:       h.localizedSerializers(w, r).SerializeFavorites(version, favorites),
+		Warnings:   warnings,
+		Pagination: &pagination,
+	}
+	if h.notModified(w, r, response) {
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, response)
+}
+
+// AddToCollection handles POST /api/users/{userID}/collections/{collectionID}/favorites
+func (h *Handler) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req AddToCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := h.favoritesService.AddToCollection(r.Context(), vars["userID"], vars["collectionID"], req.AssetID); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Favorite added to collection"},
+	})
+}
+
+// RemoveFromCollection handles DELETE /api/users/{userID}/collections/{collectionID}/favorites/{assetID}
+func (h *Handler) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.favoritesService.RemoveFromCollection(r.Context(), vars["userID"], vars["collectionID"], vars["assetID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Favorite removed from collection"},
+	})
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy controls which browser origins may call the API
+type CORSPolicy struct {
+	// AllowedOrigins lists origins such as https://app.example.com. An entry
+	// may wildcard subdomains (https://*.example.com), and "*" allows any origin.
+	AllowedOrigins []string `json:"allowed_origins"`
+	AllowedHeaders []string `json:"allowed_headers"`
+	// ExposedHeaders are response headers readable by browser clients
+	ExposedHeaders []string `json:"exposed_headers"`
+	// AllowCredentials lets browsers send cookies and Authorization headers
+	AllowCredentials bool `json:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration `json:"max_age"`
+}
+
+// corsMethods are the methods the API serves
+const corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// DefaultCORSPolicy allows any origin without credentials
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "If-None-Match", "X-API-Version", "X-Admin-Key", "X-Debug-Capture"},
+		ExposedHeaders: []string{
+			"ETag", "Retry-After", "Deprecation", "Sunset", "Link",
+			"X-RateLimit-Tier", "X-RateLimit-Limit", "X-RateLimit-Remaining",
+		},
+		MaxAge: 10 * time.Minute,
+	}
+}
+
+// Validate rejects policies browsers would refuse or that would be unsafe
+func (p CORSPolicy) Validate() error {
+	if p.AllowCredentials {
+		for _, origin := range p.AllowedOrigins {
+			if origin == "*" {
+				return errors.New("CORS credentials cannot be allowed for any origin")
+			}
+		}
+	}
+	if p.MaxAge < 0 {
+		return errors.New("CORS max age must not be negative")
+	}
+	return nil
+}
+
+// allows reports whether origin is on the allowlist
+func (p CORSPolicy) allows(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// https://*.example.com matches https://app.example.com, not https://example.com
+		if prefix, suffix, found := strings.Cut(allowed, "*."); found {
+			if len(origin) > len(prefix)+len(suffix)+1 &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyOrigin reports whether any origin is allowed
+func (p CORSPolicy) anyOrigin() bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WithCORSPolicy replaces DefaultCORSPolicy
+func WithCORSPolicy(policy CORSPolicy) Option {
+	return func(h *Handler) {
+		h.cors = policy
+	}
+}
+
+// CORSMiddleware applies the CORS policy. Preflight requests are answered
+// here; requests from origins off the allowlist get no CORS headers, so
+// browsers withhold the response.
+func (h *Handler) CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// Responses differ by origin unless every origin gets "*"
+		wildcard := h.cors.anyOrigin() && !h.cors.AllowCredentials
+		if !wildcard {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if origin == "" || !h.cors.allows(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wildcard {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if h.cors.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(h.cors.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(h.cors.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+		if len(h.cors.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(h.cors.AllowedHeaders, ", "))
+		}
+		if h.cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(h.cors.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// preflight matches OPTIONS requests to any API path so they reach
+// CORSMiddleware; routes only match their own methods
+func (h *Handler) preflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", corsMethods)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gwi-favorites-service/internal/deprecation"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// WithDeprecations marks deprecated routes and parameters with Deprecation
+// and Sunset headers. link, when set, is advertised as the migration guide.
+func WithDeprecations(engine *deprecation.Engine, link string) Option {
+	return func(h *Handler) {
+		h.deprecations = engine
+		h.deprecationLink = link
+	}
+}
+
+// DeprecationMiddleware announces deprecated features in use (RFC 9745 and
+// RFC 8594), counts their use, and refuses features past their sunset when
+// the engine enforces it
+func (h *Handler) DeprecationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route string
+		if current := mux.CurrentRoute(r); current != nil {
+			route = current.GetName()
+		}
+
+		policies := h.deprecations.Match(r, route)
+		if len(policies) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		deprecatedAt := policies[0].DeprecatedAt
+		for _, policy := range policies {
+			if policy.DeprecatedAt.Before(deprecatedAt) {
+				deprecatedAt = policy.DeprecatedAt
+			}
+			metrics.DefaultRegistry.Counter("deprecated_feature_requests_total", "Requests using a deprecated route or parameter", metrics.Labels{
+				"feature": policy.Feature(),
+			}).Inc()
+		}
+
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+		// Policies are ordered by sunset, so the first one is the nearest
+		if sunset := policies[0].Sunset; !sunset.IsZero() {
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if h.deprecationLink != "" {
+			w.Header().Add("Link", "<"+h.deprecationLink+`>; rel="deprecation"`)
+		}
+
+		for _, policy := range policies {
+			if h.deprecations.Retired(policy) {
+				h.handleError(w, r, domain.WithContext(domain.ErrSunset, "feature", policy.Feature()))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetDeprecations handles GET /api/deprecations
+func (h *Handler) GetDeprecations(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.deprecations.Policies(),
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/experiment"
+
+	"github.com/gorilla/mux"
+)
+
+// experimentHeader carries the caller's experiment assignments on user routes
+const experimentHeader = "X-Experiment"
+
+// ExperimentMiddleware exposes the path user to their experiment variants,
+// advertising them in the X-Experiment header and recording the exposure
+func (h *Handler) ExperimentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID := mux.Vars(r)["userID"]; userID != "" {
+			if assignments := h.experiments.Expose(userID); len(assignments) > 0 {
+				w.Header().Set(experimentHeader, experiment.Header(assignments))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetUserExperiments handles GET /api/users/{userID}/experiments
+func (h *Handler) GetUserExperiments(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.experiments.Assign(userID),
+	})
+}
+
+// GetExperiments handles GET /api/admin/experiments
+func (h *Handler) GetExperiments(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.experiments.Experiments(),
+	})
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/serializer"
+
+	"github.com/gorilla/mux"
+)
+
+// Export formats of GET /api/users/{userID}/favorites/export
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+)
+
+// ExportFavorites handles GET /api/users/{userID}/favorites/export, sending
+// all of the user's favorites as a downloadable file. format is json, the
+// default, or csv. The file is streamed page by page, so a failure after
+// the first page can only be reported by cutting the download short.
+func (h *Handler) ExportFavorites(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatJSON
+	}
+	if format != exportFormatJSON && format != exportFormatCSV {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "format"))
+		return
+	}
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	serializers := h.localizedSerializers(w, r)
+
+	// Headers are sent with the first page so errors found before then
+	// still get a regular error response
+	started := false
+	start := func() {
+		started = true
+		filename := fmt.Sprintf("favorites-%s-%s.%s", exportFilenamePart(userID), time.Now().UTC().Format("20060102"), format)
+		if format == exportFormatCSV {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(apiVersionHeader, string(version))
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+	}
+
+	var writePage func([]*domain.UserFavorite) error
+	var finish func() error
+	if format == exportFormatCSV {
+		out := csv.NewWriter(w)
+		writePage = func(favorites []*domain.UserFavorite) error {
+			if !started {
+				start()
+				if err := out.Write(serializer.CSVHeader); err != nil {
+					return err
+				}
+			}
+			for _, favorite := range favorites {
+				record, err := serializer.FavoriteCSVRecord(favorite)
+				if err != nil {
+					return err
+				}
+				if err := out.Write(record); err != nil {
+					return err
+				}
+			}
+			out.Flush()
+			return out.Error()
+		}
+		// Without favorites the file holds the header row alone
+		finish = func() error {
+			return writePage(nil)
+		}
+	} else {
+		encoder := json.NewEncoder(w)
+		written := 0
+		writePage = func(favorites []*domain.UserFavorite) error {
+			if !started {
+				start()
+			}
+			for _, favorite := range favorites {
+				separator := ","
+				if written == 0 {
+					separator = "["
+				}
+				if _, err := w.Write([]byte(separator)); err != nil {
+					return err
+				}
+				if err := encoder.Encode(serializers.SerializeFavorite(version, favorite)); err != nil {
+					return err
+				}
+				written++
+			}
+			return nil
+		}
+		finish = func() error {
+			if !started {
+				start()
+			}
+			closing := "]\n"
+			if written == 0 {
+				closing = "[]\n"
+			}
+			_, err := w.Write([]byte(closing))
+			return err
+		}
+	}
+
+	if err := h.favoritesService.ExportFavorites(r.Context(), userID, writePage); err != nil {
+		if !started {
+			h.handleError(w, r, err)
+			return
+		}
+		h.logger.WithError(err).WithField("user_id", userID).Error("Favorites export cut short")
+		return
+	}
+	if err := finish(); err != nil {
+		h.logger.WithError(err).WithField("user_id", userID).Error("Favorites export cut short")
+	}
+}
+
+// exportFilenamePart keeps the characters of s that are safe in a
+// Content-Disposition filename, replacing the others
+func exportFilenamePart(s string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			return c
+		}
+		return '_'
+	}, s)
+}
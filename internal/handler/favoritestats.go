@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultStatsWeeks is the number of weekly counts without ?weeks=
+	defaultStatsWeeks = 12
+	// maxStatsWeeks bounds the number of weekly counts
+	maxStatsWeeks = 52
+)
+
+// GetFavoriteStats handles GET /api/users/{userID}/favorites/stats,
+// returning the user's favorites total, counts by asset type and the number
+// added in each of the last ?weeks= weeks, 12 by default, oldest first
+func (h *Handler) GetFavoriteStats(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	weeks := defaultStatsWeeks
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		var err error
+		if weeks, err = strconv.Atoi(value); err != nil || weeks <= 0 || weeks > maxStatsWeeks {
+			h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "weeks", "max", strconv.Itoa(maxStatsWeeks)))
+			return
+		}
+	}
+
+	stats, err := h.favoritesService.GetFavoriteStats(r.Context(), userID, weeks)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response := APIResponse{Success: true, Data: stats}
+	if h.notModified(w, r, response) {
+		return
+	}
+	h.sendResponse(w, http.StatusOK, response)
+}
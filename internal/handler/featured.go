@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// GetFeaturedAssets handles GET /api/assets/featured
+func (h *Handler) GetFeaturedAssets(w http.ResponseWriter, r *http.Request) {
+	featured, err := h.assetService.FeaturedAssets(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    featured,
+	})
+}
+
+// FeatureAsset handles PUT /api/admin/assets/{assetID}/featured
+func (h *Handler) FeatureAsset(w http.ResponseWriter, r *http.Request) {
+	featured, err := h.assetService.FeatureAsset(r.Context(), mux.Vars(r)["assetID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    featured,
+	})
+}
+
+// UnfeatureAsset handles DELETE /api/admin/assets/{assetID}/featured
+func (h *Handler) UnfeatureAsset(w http.ResponseWriter, r *http.Request) {
+	if err := h.assetService.UnfeatureAsset(r.Context(), mux.Vars(r)["assetID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Asset no longer featured"},
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/validation"
+
+	"github.com/gorilla/mux"
+)
+
+// parseMinFreshness reads ?min_freshness=, the oldest sync a read accepts
+// for assets hydrated from the upstream catalog, as a duration such as 10m.
+// ok is false when the parameter is absent.
+func parseMinFreshness(r *http.Request) (maxAge time.Duration, ok bool, err error) {
+	value := r.URL.Query().Get("min_freshness")
+	if value == "" {
+		return 0, false, nil
+	}
+	maxAge, err = time.ParseDuration(value)
+	if err != nil || maxAge < 0 {
+		return 0, false, domain.WithContext(domain.ErrInvalidInput, "field", "min_freshness")
+	}
+	return maxAge, true, nil
+}
+
+// refreshFavorites refreshes assets older than ?min_freshness= when the
+// catalog is configured, returning warnings for failed refreshes
+func (h *Handler) refreshFavorites(ctx context.Context, favorites []*domain.UserFavorite, maxAge time.Duration, ok bool) []string {
+	if !ok || h.assetService == nil || !h.assetService.HasSource() {
+		return nil
+	}
+	ctx, warnings := validation.WithWarnings(ctx)
+	h.assetService.RefreshFavorites(ctx, favorites, maxAge)
+	return warnings.List()
+}
+
+// SyncAsset handles POST /api/admin/assets/{assetID}/sync, hydrating the
+// asset from the upstream catalog now
+func (h *Handler) SyncAsset(w http.ResponseWriter, r *http.Request) {
+	asset, err := h.assetService.SyncAsset(r.Context(), mux.Vars(r)["assetID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    asset,
+	})
+}
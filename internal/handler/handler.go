@@ -1,42 +1,272 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/backup"
+	"gwi-favorites-service/internal/capture"
+	"gwi-favorites-service/internal/deprecation"
 	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/engagement"
+	"gwi-favorites-service/internal/experiment"
+	"gwi-favorites-service/internal/health"
+	"gwi-favorites-service/internal/notification"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/seed"
+	"gwi-favorites-service/internal/serializer"
 	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/sharing"
+	"gwi-favorites-service/internal/taxonomy"
+	"gwi-favorites-service/internal/usage"
+	"gwi-favorites-service/internal/validation"
+	"gwi-favorites-service/pkg/canonicaljson"
+	"gwi-favorites-service/pkg/metrics"
+	"gwi-favorites-service/pkg/tracing"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// apiVersionHeader lets clients pick the wire format version of asset payloads
+const apiVersionHeader = "X-API-Version"
+
 type Handler struct {
 	favoritesService *service.FavoritesService
+	storageService   *service.StorageService
+	assetService     *service.AssetService
+	moderation       *service.ModerationService
+	experiments      *experiment.Assigner
+	idValidator      *validation.IDValidator
+	seedGenerator    *seed.Generator
+	seedProfiles     seed.Profiles
+	serializers      *serializer.Registry
+	logPolicy        requestLogPolicy
+	tracePolicy      traceSamplingPolicy
+	deployment       Deployment
+	verifier         auth.Verifier
+	adminRole        string
+	adminKey         string
+	users            *service.UserService
+	authService      *service.AuthService
+	auditLog         audit.Log
+	backups          *backup.Manager
+	usage            *usage.Tracker
+	healthScorer     *health.Scorer
+	requestWindow    *health.RequestWindow
+	taxonomy         *taxonomy.Catalog
+	notices          *notification.Store
+	engagement       *engagement.Notifier
+	shares           *sharing.Store
+	scimToken        string
+	rateLimiter      *ratelimit.Limiter
+	requestHistory   *ratelimit.History
+	concurrency      *ratelimit.ConcurrencyLimiter
+	queryCosts       *ratelimit.CostBudget
+	queryCostWeights ratelimit.CostWeights
+	queryCostWait    time.Duration
+	cors             CORSPolicy
+	captures         *capture.Store
+	deprecations     *deprecation.Engine
+	deprecationLink  string
+	pathPrefix       string
+	captureKey       string
+	readOnly         bool
+	primaryURL       string
 	logger           *logrus.Logger
 }
 
+// Option configures optional Handler dependencies
+type Option func(*Handler)
+
+// WithSeedGenerator enables the seed data admin route
+func WithSeedGenerator(generator *seed.Generator) Option {
+	return func(h *Handler) {
+		h.seedGenerator = generator
+	}
+}
+
+// WithSeedProfiles lets seed requests name a profile and lists the profiles
+func WithSeedProfiles(profiles seed.Profiles) Option {
+	return func(h *Handler) {
+		h.seedProfiles = profiles
+	}
+}
+
+// WithStorageService enables the storage admin routes
+func WithStorageService(storageService *service.StorageService) Option {
+	return func(h *Handler) {
+		h.storageService = storageService
+	}
+}
+
+// WithAssetService enables the asset management admin routes
+func WithAssetService(assetService *service.AssetService) Option {
+	return func(h *Handler) {
+		h.assetService = assetService
+	}
+}
+
+// WithModerationService enables asset reporting and the moderation admin routes
+func WithModerationService(moderationService *service.ModerationService) Option {
+	return func(h *Handler) {
+		h.moderation = moderationService
+	}
+}
+
+// WithExperiments assigns users on favorites routes to experiment variants
+func WithExperiments(assigner *experiment.Assigner) Option {
+	return func(h *Handler) {
+		h.experiments = assigner
+	}
+}
+
+// WithIDValidator rejects malformed userID and assetID path parameters with 400
+func WithIDValidator(validator *validation.IDValidator) Option {
+	return func(h *Handler) {
+		h.idValidator = validator
+	}
+}
+
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success  bool        `json:"success"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Warnings []string    `json:"warnings,omitempty"`
+	// Code and Context identify a failure for clients: a stable error code
+	// and the IDs involved
+	Code    string            `json:"code,omitempty"`
+	Context map[string]string `json:"context,omitempty"`
+	// Pagination accompanies pages of list endpoints
+	Pagination *domain.Pagination `json:"pagination,omitempty"`
+	Cursor     *CursorPagination  `json:"cursor,omitempty"`
+}
+
+// Precomputed bodies for the hottest and simplest responses
+var (
+	isFavoriteBody    = []byte(`{"success":true,"data":{"is_favorite":true}}` + "\n")
+	notFavoriteBody   = []byte(`{"success":true,"data":{"is_favorite":false}}` + "\n")
+	internalErrorBody = []byte(`{"success":false,"error":"Internal server error"}` + "\n")
+
+	// Shared header value; assigning it avoids allocating a new slice per response
+	jsonContentType = []string{"application/json"}
+)
+
+// Route names, used to configure per-route behaviour such as log levels
+const (
+	routeListFavorites   = "favorites.list"
+	routeRecentFavorites = "favorites.recent"
+	routeAddFavorite     = "favorites.add"
+	routeAddFavoriteRef  = "favorites.add_by_id"
+	routeGetFavorite     = "favorites.get"
+	routeFavoriteCount   = "favorites.count"
+	routeFavoriteStats   = "favorites.stats"
+	routeRemoveFavorite  = "favorites.remove"
+	routeUpdateFavorite  = "favorites.update"
+	routePatchFavorite   = "favorites.patch"
+	routeCheckFavorite   = "favorites.check"
+	routeCheckFavorites  = "favorites.check_batch"
+	routeFavoriteData    = "favorites.data"
+	routeReorder         = "favorites.reorder"
+	routeExportFavorites = "favorites.export"
+	routeImportFavorites = "favorites.import"
+	routeUndoRemoval     = "favorites.undo"
+	routeSharedFavorites = "shared.favorites"
+	routePinFavorite     = "favorites.pin"
+	routeUnpinFavorite   = "favorites.unpin"
+	routeListCollection  = "collections.favorites"
+	routeCreateBackup    = "backups.create"
+	routeExportAudit     = "audit.export"
+	routeListAssets      = "assets.list"
+	routeListAllAssets   = "assets.list_all"
+	routeLogPolicy       = "admin.logging"
+	routeTraceSampling   = "admin.tracing"
+	routeEnableCapture   = "captures.enable"
+	routeDisableCapture  = "captures.disable"
+)
+
+// bufferPool recycles response encoding buffers across requests
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
 }
 
-type UpdateDescriptionRequest struct {
-	Description string `json:"description"`
+// maxSearchLength bounds the ?q= search text of listings, in bytes
+const maxSearchLength = 200
+
+// checkSearch refuses ?q= search text that is too long or has more than
+// domain.MaxSearchTerms terms
+func checkSearch(search string) error {
+	if len(search) > maxSearchLength {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "q", "max", strconv.Itoa(maxSearchLength))
+	}
+	if len(strings.Fields(search)) > domain.MaxSearchTerms {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "q", "max_terms", strconv.Itoa(domain.MaxSearchTerms))
+	}
+	return nil
+}
+
+// maxCheckAssetIDs bounds the asset IDs of one batch favorite check
+const maxCheckAssetIDs = 100
+
+// CheckFavoritesRequest is the body of a batch favorite check
+type CheckFavoritesRequest struct {
+	AssetIDs []string `json:"asset_ids"`
 }
 
-func NewHandler(favoritesService *service.FavoritesService, logger *logrus.Logger) *Handler {
-	return &Handler{
+// UpdateNoteRequest sets the caller's personal note on a favorite
+type UpdateNoteRequest struct {
+	Note *string `json:"note,omitempty"`
+	// BaseNote is the note the client started editing from, used to detect
+	// concurrent edits
+	BaseNote *string `json:"base_note,omitempty"`
+	// Description and BaseDescription are accepted for clients written
+	// before notes, and are ignored when Note is set
+	Description     string  `json:"description,omitempty"`
+	BaseDescription *string `json:"base_description,omitempty"`
+}
+
+// note returns the note and base to save, falling back to the legacy fields
+func (r UpdateNoteRequest) note() (string, *string) {
+	if r.Note != nil {
+		return *r.Note, r.BaseNote
+	}
+	return r.Description, r.BaseDescription
+}
+
+// UpdateNoteResponse reports the saved note and whether it replaced a
+// concurrent edit
+type UpdateNoteResponse struct {
+	Message string `json:"message"`
+	service.NoteUpdate
+}
+
+func NewHandler(favoritesService *service.FavoritesService, logger *logrus.Logger, opts ...Option) *Handler {
+	h := &Handler{
 		favoritesService: favoritesService,
+		serializers:      serializer.DefaultRegistry(),
+		adminRole:        "admin",
+		cors:             DefaultCORSPolicy(),
 		logger:           logger,
 	}
+	h.logPolicy.Store(ptr(DefaultRequestLogPolicy()))
+	h.tracePolicy.Store(&TraceSamplingPolicy{})
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) SetupRoutes() http.Handler {
 	r := mux.NewRouter()
+	r.Use(tracing.MiddlewareWithSampler(h.sampleTrace))
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
@@ -44,26 +274,362 @@ func (h *Handler) SetupRoutes() http.Handler {
 	// Apply middleware
 	api.Use(h.LoggingMiddleware)
 	api.Use(h.CORSMiddleware)
+	if h.captures != nil {
+		api.Use(h.CaptureMiddleware)
+	}
+	if h.deprecations != nil {
+		api.Use(h.DeprecationMiddleware)
+	}
+	if h.readOnly {
+		api.Use(h.ReadOnlyMiddleware)
+	}
+	if h.verifier != nil {
+		api.Use(h.AuthMiddleware, h.OwnerMiddleware)
+	}
+	if h.requestHistory != nil {
+		api.Use(h.RequestHistoryMiddleware)
+	}
+	if h.rateLimiter != nil {
+		api.Use(h.RateLimitMiddleware)
+	}
+	if h.concurrency != nil {
+		api.Use(h.ConcurrencyMiddleware)
+	}
+	if h.queryCosts != nil {
+		api.Use(h.QueryCostMiddleware)
+	}
+	if h.idValidator != nil {
+		api.Use(h.IDValidationMiddleware)
+	}
 
 	// User favorites routes
 	userRoutes := api.PathPrefix("/users/{userID}/favorites").Subrouter()
-	userRoutes.HandleFunc("", h.GetUserFavorites).Methods("GET")
-	userRoutes.HandleFunc("", h.AddFavorite).Methods("POST")
-	userRoutes.HandleFunc("/{assetID}", h.RemoveFavorite).Methods("DELETE")
-	userRoutes.HandleFunc("/{assetID}", h.UpdateFavoriteDescription).Methods("PUT")
-	userRoutes.HandleFunc("/{assetID}/check", h.CheckIsFavorite).Methods("GET")
+	userRoutes.HandleFunc("", h.GetUserFavorites).Methods("GET").Name(routeListFavorites)
+	userRoutes.HandleFunc("", h.AddFavorite).Methods("POST").Name(routeAddFavorite)
+	userRoutes.HandleFunc("/count", h.GetFavoriteCount).Methods("GET").Name(routeFavoriteCount)
+	userRoutes.HandleFunc("/recent", h.GetRecentFavorites).Methods("GET").Name(routeRecentFavorites)
+	userRoutes.HandleFunc("/stats", h.GetFavoriteStats).Methods("GET").Name(routeFavoriteStats)
+	userRoutes.HandleFunc("/check", h.CheckFavorites).Methods("POST").Name(routeCheckFavorites)
+	userRoutes.HandleFunc("/order", h.ReorderFavorites).Methods("PUT").Name(routeReorder)
+	userRoutes.HandleFunc("/export", h.ExportFavorites).Methods("GET").Name(routeExportFavorites)
+	userRoutes.HandleFunc("/import", h.ImportFavorites).Methods("POST").Name(routeImportFavorites)
+	if h.favoritesService.SupportsUndo() {
+		userRoutes.HandleFunc("/undo", h.UndoRemoval).Methods("POST").Name(routeUndoRemoval)
+	}
+	if h.shares != nil {
+		userRoutes.HandleFunc("/share", h.CreateShare).Methods("POST")
+		userRoutes.HandleFunc("/share", h.GetShare).Methods("GET")
+		userRoutes.HandleFunc("/share", h.RevokeShare).Methods("DELETE")
+		api.HandleFunc("/shared/{token}", h.GetSharedFavorites).Methods("GET").Name(routeSharedFavorites)
+	}
+	userRoutes.HandleFunc("/{assetID}", h.GetFavorite).Methods("GET").Name(routeGetFavorite)
+	userRoutes.HandleFunc("/{assetID}", h.AddFavoriteByID).Methods("POST").Name(routeAddFavoriteRef)
+	userRoutes.HandleFunc("/{assetID}", h.RemoveFavorite).Methods("DELETE").Name(routeRemoveFavorite)
+	userRoutes.HandleFunc("/{assetID}", h.UpdateFavoriteNote).Methods("PUT").Name(routeUpdateFavorite)
+	userRoutes.HandleFunc("/{assetID}", h.PatchFavorite).Methods("PATCH").Name(routePatchFavorite)
+	userRoutes.HandleFunc("/{assetID}/check", h.CheckIsFavorite).Methods("GET").Name(routeCheckFavorite)
+	userRoutes.HandleFunc("/{assetID}/data", h.GetFavoriteChartData).Methods("GET").Name(routeFavoriteData)
+	userRoutes.HandleFunc("/{assetID}/pin", h.PinFavorite).Methods("PUT").Name(routePinFavorite)
+	userRoutes.HandleFunc("/{assetID}/pin", h.UnpinFavorite).Methods("DELETE").Name(routeUnpinFavorite)
+	if h.notices != nil {
+		api.HandleFunc("/users/{userID}/notifications", h.GetNotifications).Methods("GET")
+	}
+	if h.engagement != nil {
+		api.HandleFunc("/users/{userID}/webhook", h.GetWebhook).Methods("GET")
+		api.HandleFunc("/users/{userID}/webhook", h.PutWebhook).Methods("PUT")
+		api.HandleFunc("/users/{userID}/webhook", h.DeleteWebhook).Methods("DELETE")
+	}
+	if h.requestHistory != nil {
+		api.HandleFunc("/users/{userID}/usage", h.GetCallerUsage).Methods("GET").Name(routeCallerUsage)
+	}
+	if h.experiments != nil {
+		userRoutes.Use(h.ExperimentMiddleware)
+		api.HandleFunc("/users/{userID}/experiments", h.GetUserExperiments).Methods("GET")
+	}
+
+	if h.users != nil {
+		api.HandleFunc("/users", h.CreateUser).Methods("POST")
+		api.HandleFunc("/users/{userID}", h.GetUser).Methods("GET")
+		api.HandleFunc("/users/{userID}", h.UpdateUser).Methods("PUT")
+		api.HandleFunc("/users/{userID}", h.DeleteUser).Methods("DELETE")
+	}
+
+	// Collection routes
+	collectionRoutes := api.PathPrefix("/users/{userID}/collections").Subrouter()
+	collectionRoutes.HandleFunc("", h.ListCollections).Methods("GET")
+	collectionRoutes.HandleFunc("", h.CreateCollection).Methods("POST")
+	collectionRoutes.HandleFunc("/{collectionID}", h.ListCollectionFavorites).Methods("GET").Name(routeListCollection)
+	collectionRoutes.HandleFunc("/{collectionID}", h.DeleteCollection).Methods("DELETE")
+	collectionRoutes.HandleFunc("/{collectionID}/favorites", h.AddToCollection).Methods("POST")
+	collectionRoutes.HandleFunc("/{collectionID}/favorites/{assetID}", h.RemoveFromCollection).Methods("DELETE")
+
+	api.HandleFunc("/capabilities", h.GetCapabilities).Methods("GET").Name(routeCapabilities)
+	api.HandleFunc("/openapi/{scope:public|authenticated}.json", h.serveOpenAPI(r)).Methods("GET").Name(routeOpenAPI)
+	if h.authService != nil {
+		api.HandleFunc("/auth/login", h.Login).Methods("POST").Name(routeLogin)
+		api.HandleFunc("/auth/refresh", h.RefreshToken).Methods("POST").Name(routeRefresh)
+	}
+	if h.deprecations != nil {
+		api.HandleFunc("/deprecations", h.GetDeprecations).Methods("GET")
+	}
+
+	if h.assetService != nil {
+		api.HandleFunc("/assets", h.ListAssets).Methods("GET").Name(routeListAssets)
+		api.HandleFunc("/assets/featured", h.GetFeaturedAssets).Methods("GET")
+		api.HandleFunc("/assets/{assetID}", h.GetCatalogAsset).Methods("GET")
+	}
+	if h.moderation != nil {
+		api.HandleFunc("/assets/{assetID}/report", h.ReportAsset).Methods("POST")
+	}
+
+	// Admin routes. They fail closed: without an admin API key or a
+	// verifier with an admin role, every admin request is forbidden.
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(h.AdminMiddleware)
+	admin.HandleFunc("/logging", h.GetLogPolicy).Methods("GET")
+	admin.HandleFunc("/logging", h.UpdateLogPolicy).Methods("PUT").Name(routeLogPolicy)
+	admin.HandleFunc("/tracing", h.GetTraceSampling).Methods("GET")
+	admin.HandleFunc("/tracing", h.UpdateTraceSampling).Methods("PUT").Name(routeTraceSampling)
+	admin.HandleFunc("/favorites/transfer", h.TransferFavorites).Methods("POST")
+	admin.HandleFunc("/openapi.json", h.serveOpenAPI(r)).Methods("GET")
+	if h.assetService != nil {
+		admin.HandleFunc("/assets", h.ListAllAssets).Methods("GET").Name(routeListAllAssets)
+		admin.HandleFunc("/assets", h.CreateAsset).Methods("POST")
+		admin.HandleFunc("/assets/removals", h.GetPendingRemovals).Methods("GET")
+		admin.HandleFunc("/assets/{assetID}", h.GetAsset).Methods("GET")
+		admin.HandleFunc("/assets/{assetID}", h.UpdateAsset).Methods("PUT")
+		admin.HandleFunc("/assets/{assetID}", h.PatchAsset).Methods("PATCH")
+		admin.HandleFunc("/assets/{assetID}", h.DeleteAsset).Methods("DELETE")
+		admin.HandleFunc("/assets/{assetID}/featured", h.FeatureAsset).Methods("PUT")
+		admin.HandleFunc("/assets/{assetID}/featured", h.UnfeatureAsset).Methods("DELETE")
+		if h.assetService.HasSource() {
+			admin.HandleFunc("/assets/{assetID}/sync", h.SyncAsset).Methods("POST")
+		}
+	}
+	if h.storageService != nil {
+		admin.HandleFunc("/storage/stats", h.GetStorageStats).Methods("GET")
+		admin.HandleFunc("/storage/compact", h.CompactStorage).Methods("POST")
+		admin.HandleFunc("/storage/integrity", h.CheckStorageIntegrity).Methods("GET")
+		admin.HandleFunc("/storage/integrity", h.RepairStorageIntegrity).Methods("POST")
+	}
+	if h.experiments != nil {
+		admin.HandleFunc("/experiments", h.GetExperiments).Methods("GET")
+	}
+	if h.moderation != nil {
+		admin.HandleFunc("/moderation/reports", h.GetModerationQueue).Methods("GET")
+		admin.HandleFunc("/moderation/assets/{assetID}", h.ResolveReports).Methods("PUT")
+	}
+	if h.captures != nil {
+		admin.HandleFunc("/debug/captures", h.GetCaptures).Methods("GET")
+		admin.HandleFunc("/debug/sessions", h.GetCaptureSessions).Methods("GET")
+		admin.HandleFunc("/debug/sessions/{userID}", h.EnableCapture).Methods("PUT").Name(routeEnableCapture)
+		admin.HandleFunc("/debug/sessions/{userID}", h.DisableCapture).Methods("DELETE").Name(routeDisableCapture)
+	}
+	if h.auditLog != nil {
+		admin.HandleFunc("/audit", h.GetAuditLog).Methods("GET")
+		admin.HandleFunc("/audit/export", h.ExportAuditLog).Methods("GET").Name(routeExportAudit)
+	}
+	if h.seedGenerator != nil {
+		admin.HandleFunc("/seed", h.GenerateSeedData).Methods("POST")
+		if h.seedProfiles != nil {
+			admin.HandleFunc("/seed/profiles", h.ListSeedProfiles).Methods("GET")
+		}
+	}
+	if h.taxonomy != nil {
+		admin.HandleFunc("/taxonomy", h.ListTaxonomy).Methods("GET")
+		admin.HandleFunc("/taxonomy/{kind}/{key}", h.SetTaxonomyNames).Methods("PUT")
+		admin.HandleFunc("/taxonomy/{kind}/{key}", h.DeleteTaxonomyNames).Methods("DELETE")
+	}
+	if h.usage != nil {
+		admin.HandleFunc("/usage", h.GetUsageSummary).Methods("GET")
+	}
+	if h.healthScorer != nil {
+		admin.HandleFunc("/health/score", h.GetHealthScore).Methods("GET")
+	}
+	if h.backups != nil {
+		admin.HandleFunc("/backups", h.ListBackups).Methods("GET")
+		admin.HandleFunc("/backups", h.CreateBackup).Methods("POST").Name(routeCreateBackup)
+		admin.HandleFunc("/backups/{name}/restore", h.RestoreBackup).Methods("POST")
+	}
+
+	// Preflight requests for any API route
+	api.PathPrefix("/").Methods(http.MethodOptions).HandlerFunc(h.preflight)
+
+	// SCIM provisioning for the identity platform
+	if h.users != nil && h.scimToken != "" {
+		scim := r.PathPrefix("/scim/v2").Subrouter()
+		scim.Use(h.LoggingMiddleware, h.SCIMAuthMiddleware)
+		if h.readOnly {
+			scim.Use(h.ReadOnlyMiddleware)
+		}
+		scim.HandleFunc("/Users", h.CreateSCIMUser).Methods("POST")
+		scim.HandleFunc("/Users/{userID}", h.GetSCIMUser).Methods("GET")
+		scim.HandleFunc("/Users/{userID}", h.PatchSCIMUser).Methods("PATCH")
+		scim.HandleFunc("/Users/{userID}", h.DeleteSCIMUser).Methods("DELETE")
+	}
 
 	// Health check
 	r.HandleFunc("/health", h.HealthCheck).Methods("GET")
 
+	// Prometheus metrics
+	r.Handle("/metrics", metrics.DefaultRegistry.Handler()).Methods("GET")
+
+	if h.pathPrefix != "" {
+		return h.stripPathPrefix(r)
+	}
 	return r
 }
 
 // GetUserFavorites handles GET /api/users/{userID}/favorites
+//
+// An optional ?type=chart|insight|audience restricts the listing to one asset
+// type; limit and offset then page through that type independently. ?q=
+// searches asset titles, descriptions, insight content and tags.
+// ?sort=added_at|updated_at|type|title|position and ?order=asc|desc order
+// the results, oldest added first by default. Pinned favorites come first.
+// ?min_freshness=10m refreshes catalog assets last synced longer ago.
+// Clients sending Accept: application/vnd.api+json receive a JSON:API document.
 func (h *Handler) GetUserFavorites(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
+	jsonAPI := wantsJSONAPI(r)
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+
+	query, err := parseFavoritesQuery(r)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+	maxAge, refresh, err := parseMinFreshness(r)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+
+	favorites, pagination, err := h.favoritesService.ListUserFavoritesPage(r.Context(), userID, query)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+	warnings := h.refreshFavorites(r.Context(), favorites, maxAge, refresh)
+
+	format, paging := "json", "first_page"
+	if jsonAPI {
+		format = "jsonapi"
+	}
+	if query.Offset > 0 {
+		paging = "offset"
+	}
+	h.usage.Record(usageListFormat, format)
+	h.usage.Record(usageListVersion, string(version))
+	h.usage.Record(usageListTypeFilter, typeFilterValue(query.Type))
+	h.usage.Record(usageListPaging, paging)
+	h.usage.Record(usageListSearch, strconv.FormatBool(query.Search != ""))
+	h.usage.Record(usageListSort, sortValue(query))
+
+	w.Header().Set(apiVersionHeader, string(version))
+	serializers := h.localizedSerializers(w, r)
+
+	if jsonAPI {
+		document, err := serializers.FavoritesJSONAPIDocument(version, favorites)
+		if err != nil {
+			h.handleNegotiatedError(w, r, err, jsonAPI)
+			return
+		}
+		document.Meta["total"] = pagination.Total
+		document.Meta["limit"] = pagination.Limit
+		document.Meta["offset"] = pagination.Offset
+		document.Meta["has_more"] = pagination.HasMore
+		if pagination.NextOffset != nil {
+			document.Meta["next_offset"] = *pagination.NextOffset
+		}
+		if len(warnings) > 0 {
+			document.Meta["warnings"] = warnings
+		}
+		if h.notModified(w, r, document) {
+			return
+		}
+		h.sendJSONAPI(w, http.StatusOK, document)
+		return
+	}
+
+	// The ETag covers the pagination too, as the total changes when
+	// favorites outside the page are added or removed
+	response := APIResponse{
+		Success:    true,
+		Data:       serializers.SerializeFavorites(version, favorites),
+		Warnings:   warnings,
+		Pagination: &pagination,
+	}
+	if h.notModified(w, r, response) {
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, response)
+}
+
+// GetFavorite handles GET /api/users/{userID}/favorites/{assetID},
+// returning one favorite with its asset and note
+func (h *Handler) GetFavorite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jsonAPI := wantsJSONAPI(r)
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+	maxAge, refresh, err := parseMinFreshness(r)
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+
+	favorite, err := h.favoritesService.GetFavorite(r.Context(), vars["userID"], vars["assetID"])
+	if err != nil {
+		h.handleNegotiatedError(w, r, err, jsonAPI)
+		return
+	}
+	warnings := h.refreshFavorites(r.Context(), []*domain.UserFavorite{favorite}, maxAge, refresh)
+
+	w.Header().Set(apiVersionHeader, string(version))
+	serializers := h.localizedSerializers(w, r)
+
+	if jsonAPI {
+		document, err := serializers.FavoriteJSONAPIDocument(version, favorite)
+		if err != nil {
+			h.handleNegotiatedError(w, r, err, jsonAPI)
+			return
+		}
+		if len(warnings) > 0 {
+			document.Meta = map[string]interface{}{"warnings": warnings}
+		}
+		if h.notModified(w, r, document) {
+			return
+		}
+		h.sendJSONAPI(w, http.StatusOK, document)
+		return
+	}
 
+	response := APIResponse{
+		Success:  true,
+		Data:     serializers.SerializeFavorite(version, favorite),
+		Warnings: warnings,
+	}
+	if h.notModified(w, r, response) {
+		return
+	}
+	h.sendResponse(w, http.StatusOK, response)
+}
+
+// parseFavoritesQuery reads the paging, filter and ordering parameters of a
+// favorites listing
+func parseFavoritesQuery(r *http.Request) (domain.FavoritesQuery, error) {
 	// Parse pagination parameters
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	if limit <= 0 || limit > 100 {
@@ -75,39 +641,95 @@ func (h *Handler) GetUserFavorites(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	favorites, err := h.favoritesService.GetUserFavorites(r.Context(), userID, limit, offset)
-	if err != nil {
-		h.handleError(w, err)
-		return
+	query := domain.FavoritesQuery{
+		Type:   domain.AssetType(r.URL.Query().Get("type")),
+		Search: strings.TrimSpace(r.URL.Query().Get("q")),
+		Sort:   domain.SortField(r.URL.Query().Get("sort")),
+		Limit:  limit,
+		Offset: offset,
 	}
+	switch order := r.URL.Query().Get("order"); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, domain.WithContext(domain.ErrInvalidInput, "field", "order")
+	}
+	if err := checkSearch(query.Search); err != nil {
+		return query, err
+	}
+	return query, nil
+}
 
-	h.sendResponse(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    favorites,
-	})
+// FavoriteReference is a POST /api/users/{userID}/favorites body that
+// favorites a catalog asset by ID instead of submitting the asset
+type FavoriteReference struct {
+	AssetID string `json:"asset_id"`
 }
 
-// AddFavorite handles POST /api/users/{userID}/favorites
+// AddFavorite handles POST /api/users/{userID}/favorites. The body is
+// either a full asset, created in the catalog when new, or a
+// FavoriteReference to an existing one.
 func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
 
 	var rawAsset json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&rawAsset); err != nil {
-		h.handleError(w, domain.ErrInvalidInput)
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	// Assets carry a type and an id, references only an asset_id
+	var ref struct {
+		FavoriteReference
+		Type domain.AssetType `json:"type"`
+	}
+	if json.Unmarshal(rawAsset, &ref) == nil && ref.AssetID != "" && ref.Type == "" {
+		if h.idValidator != nil {
+			if err := h.idValidator.ValidateAssetID(ref.AssetID); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
+		}
+		h.addFavoriteByID(w, r, userID, ref.AssetID)
 		return
 	}
 
 	asset, err := domain.AssetFromJSON(rawAsset)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	if err := h.favoritesService.AddFavorite(r.Context(), userID, asset); err != nil {
-		h.handleError(w, err)
+	ctx, warnings := validation.WithWarnings(r.Context())
+	if err := h.favoritesService.AddFavorite(ctx, userID, asset); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.usage.Record(usageAddAssetType, string(asset.GetType()))
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success:  true,
+		Data:     map[string]string{"message": "Asset added to favorites"},
+		Warnings: warnings.List(),
+	})
+}
+
+// AddFavoriteByID handles POST /api/users/{userID}/favorites/{assetID},
+// favoriting a catalog asset without resubmitting it
+func (h *Handler) AddFavoriteByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.addFavoriteByID(w, r, vars["userID"], vars["assetID"])
+}
+
+func (h *Handler) addFavoriteByID(w http.ResponseWriter, r *http.Request, userID, assetID string) {
+	asset, err := h.favoritesService.AddFavoriteByID(r.Context(), userID, assetID)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
+	h.usage.Record(usageAddAssetType, string(asset.GetType()))
 
 	h.sendResponse(w, http.StatusCreated, APIResponse{
 		Success: true,
@@ -122,7 +744,7 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 	assetID := vars["assetID"]
 
 	if err := h.favoritesService.RemoveFavorite(r.Context(), userID, assetID); err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
@@ -132,30 +754,43 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// UpdateFavoriteDescription handles PUT /api/users/{userID}/favorites/{assetID}
-func (h *Handler) UpdateFavoriteDescription(w http.ResponseWriter, r *http.Request) {
+// UpdateFavoriteNote handles PUT /api/users/{userID}/favorites/{assetID}
+func (h *Handler) UpdateFavoriteNote(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
 	assetID := vars["assetID"]
 
-	var req UpdateDescriptionRequest
+	var req UpdateNoteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.handleError(w, domain.ErrInvalidInput)
+		h.handleError(w, r, domain.ErrInvalidInput)
 		return
 	}
+	note, base := req.note()
 
-	if err := h.favoritesService.UpdateFavoriteDescription(r.Context(), userID, assetID, req.Description); err != nil {
-		h.handleError(w, err)
+	ctx, warnings := validation.WithWarnings(r.Context())
+	result, err := h.favoritesService.EditFavoriteNote(ctx, userID, assetID, note, base)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
+	if result.Conflict {
+		metrics.DefaultRegistry.Counter("favorite_note_conflicts_total", "Concurrent note edits overwritten", nil).Inc()
+	}
 
 	h.sendResponse(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data:    map[string]string{"message": "Asset description updated"},
+		Data: UpdateNoteResponse{
+			Message:    "Favorite note updated",
+			NoteUpdate: result,
+		},
+		Warnings: warnings.List(),
 	})
 }
 
 // CheckIsFavorite handles GET /api/users/{userID}/favorites/{assetID}/check
+//
+// This route is called once per rendered asset card, so the response bodies
+// are precomputed instead of encoded per request.
 func (h *Handler) CheckIsFavorite(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
@@ -163,16 +798,99 @@ func (h *Handler) CheckIsFavorite(w http.ResponseWriter, r *http.Request) {
 
 	isFavorite, err := h.favoritesService.IsFavorite(r.Context(), userID, assetID)
 	if err != nil {
-		h.handleError(w, err)
+		h.handleError(w, r, err)
+		return
+	}
+
+	if isFavorite {
+		writeJSON(w, http.StatusOK, isFavoriteBody)
+		return
+	}
+	writeJSON(w, http.StatusOK, notFavoriteBody)
+}
+
+// CheckFavorites handles POST /api/users/{userID}/favorites/check, answering
+// for up to maxCheckAssetIDs assets at once so asset grids need one request
+// instead of one per card. The response maps each asset ID to whether it is
+// favorited.
+func (h *Handler) CheckFavorites(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	var req CheckFavoritesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if len(req.AssetIDs) == 0 || len(req.AssetIDs) > maxCheckAssetIDs {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "asset_ids", "max", strconv.Itoa(maxCheckAssetIDs)))
+		return
+	}
+	if h.idValidator != nil {
+		for _, assetID := range req.AssetIDs {
+			if err := h.idValidator.ValidateAssetID(assetID); err != nil {
+				h.handleError(w, r, domain.WithContext(err, "asset_id", assetID))
+				return
+			}
+		}
+	}
+
+	results, err := h.favoritesService.CheckFavorites(r.Context(), userID, req.AssetIDs)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
 	h.sendResponse(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data:    map[string]bool{"is_favorite": isFavorite},
+		Data:    results,
 	})
 }
 
+// GetFavoriteChartData handles GET /api/users/{userID}/favorites/{assetID}/data,
+// returning the points of a favorited chart. Listings omit the points of
+// charts whose data was moved to the blob store.
+func (h *Handler) GetFavoriteChartData(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["userID"]
+	assetID := vars["assetID"]
+
+	data, err := h.favoritesService.GetFavoriteChartData(r.Context(), userID, assetID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.usage.Record(usageChartData, "loaded")
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"asset_id": assetID,
+			"data":     data,
+		},
+	})
+}
+
+// GetFavoriteCount handles GET /api/users/{userID}/favorites/count
+func (h *Handler) GetFavoriteCount(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	count, err := h.favoritesService.GetFavoriteCount(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	buf.WriteString(`{"success":true,"data":{"count":`)
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(count), 10))
+	buf.WriteString("}}\n")
+
+	writeJSON(w, http.StatusOK, buf.Bytes())
+}
+
 // HealthCheck handles GET /health
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	h.sendResponse(w, http.StatusOK, APIResponse{
@@ -185,80 +903,272 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 }
 
 // Helper methods
+
+// apiVersion resolves the requested wire format from the X-API-Version header
+// or the ?version= query parameter, falling back to the default version
+func (h *Handler) apiVersion(r *http.Request) (serializer.Version, error) {
+	requested := r.Header.Get(apiVersionHeader)
+	if requested == "" {
+		requested = r.URL.Query().Get("version")
+	}
+	if requested == "" {
+		return serializer.DefaultVersion, nil
+	}
+
+	version := serializer.Version(requested)
+	if !h.serializers.HasVersion(version) {
+		return "", domain.ErrInvalidInput
+	}
+	return version, nil
+}
+
 func (h *Handler) sendResponse(w http.ResponseWriter, statusCode int, response APIResponse) {
-	w.Header().Set("Content-Type", "application/json")
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	if err := json.NewEncoder(buf).Encode(response); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+		writeJSON(w, http.StatusInternalServerError, internalErrorBody)
+		return
+	}
+
+	writeJSON(w, statusCode, buf.Bytes())
+}
+
+func (h *Handler) sendJSONAPI(w http.ResponseWriter, statusCode int, document serializer.JSONAPIDocument) {
+	w.Header().Set("Content-Type", serializer.JSONAPIMediaType)
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(document)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	statusCode, message := h.errorStatus(err)
+	code, context := domain.CodeOf(err), domain.ContextOf(err)
+
+	if wantsProblemJSON(r) {
+		h.sendProblem(w, Problem{
+			Type:    "about:blank",
+			Title:   message,
+			Status:  statusCode,
+			Code:    code,
+			Context: context,
+		})
+		return
+	}
+
+	h.sendResponse(w, statusCode, APIResponse{
+		Success: false,
+		Error:   message,
+		Code:    code,
+		Context: context,
+	})
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, err error) {
+// handleNegotiatedError reports an error in the representation the client asked for
+func (h *Handler) handleNegotiatedError(w http.ResponseWriter, r *http.Request, err error, jsonAPI bool) {
+	if !jsonAPI {
+		h.handleError(w, r, err)
+		return
+	}
+
+	statusCode, message := h.errorStatus(err)
+	h.sendJSONAPI(w, statusCode, serializer.JSONAPIErrorDocument(statusCode, domain.CodeOf(err), message, domain.ContextOf(err)))
+}
+
+// errorStatus maps domain errors onto HTTP status codes and client-facing messages
+func (h *Handler) errorStatus(err error) (int, string) {
 	var statusCode int
 	var message string
 
-	switch err {
-	case domain.ErrUserNotFound:
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
 		statusCode = http.StatusNotFound
 		message = "User not found"
-	case domain.ErrAssetNotFound:
+	case errors.Is(err, domain.ErrAssetNotFound):
 		statusCode = http.StatusNotFound
 		message = "Asset not found"
-	case domain.ErrFavoriteNotFound:
+	case errors.Is(err, domain.ErrAssetNotFeatured):
+		statusCode = http.StatusNotFound
+		message = "Asset is not featured"
+	case errors.Is(err, domain.ErrCollectionNotFound):
+		statusCode = http.StatusNotFound
+		message = "Collection not found"
+	case errors.Is(err, domain.ErrNotInCollection):
+		statusCode = http.StatusNotFound
+		message = "Favorite is not in the collection"
+	case errors.Is(err, domain.ErrTrashEmpty):
+		statusCode = http.StatusNotFound
+		message = "Nothing to undo"
+	case errors.Is(err, domain.ErrCollectionAlreadyExists):
+		statusCode = http.StatusConflict
+		message = "A collection with this name already exists"
+	case errors.Is(err, domain.ErrFavoriteNotFound):
 		statusCode = http.StatusNotFound
 		message = "Favorite not found"
-	case domain.ErrFavoriteAlreadyExists:
+	case errors.Is(err, domain.ErrFavoriteAlreadyExists):
 		statusCode = http.StatusConflict
 		message = "Asset is already in favorites"
-	case domain.ErrInvalidInput, domain.ErrMissingRequiredField:
+	case errors.Is(err, domain.ErrAssetAlreadyExists):
+		statusCode = http.StatusConflict
+		message = "Asset already exists"
+	case errors.Is(err, domain.ErrUserAlreadyExists):
+		statusCode = http.StatusConflict
+		message = "User already exists"
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrMissingRequiredField):
 		statusCode = http.StatusBadRequest
 		message = "Invalid input"
-	case domain.ErrInvalidUserID:
+	case errors.Is(err, domain.ErrInvalidUserID):
 		statusCode = http.StatusBadRequest
 		message = "Invalid user ID"
-	case domain.ErrInvalidAssetType:
+	case errors.Is(err, domain.ErrInvalidAssetID):
+		statusCode = http.StatusBadRequest
+		message = "Invalid asset ID"
+	case errors.Is(err, domain.ErrAssetInUse):
+		statusCode = http.StatusConflict
+		message = "Asset is still in favorites"
+	case errors.Is(err, domain.ErrAssetDeleted):
+		statusCode = http.StatusGone
+		message = "Asset has been deleted"
+	case errors.Is(err, domain.ErrFieldTooLong):
+		statusCode = http.StatusBadRequest
+		message = "Field exceeds maximum length"
+	case errors.Is(err, domain.ErrInvalidAssetType):
 		statusCode = http.StatusBadRequest
 		message = "Invalid asset type"
+	case errors.Is(err, domain.ErrMaxFavoritesReached):
+		statusCode = http.StatusInsufficientStorage
+		message = "Maximum favorites limit reached"
+	case errors.Is(err, domain.ErrStorageLimitReached):
+		statusCode = http.StatusInsufficientStorage
+		message = "Storage limit reached"
+	case errors.Is(err, domain.ErrNotSupported):
+		statusCode = http.StatusNotImplemented
+		message = "Not supported by the storage backend"
+	case errors.Is(err, domain.ErrReadOnly):
+		statusCode = http.StatusMethodNotAllowed
+		message = "This instance is read-only; send writes to the primary"
+	case errors.Is(err, domain.ErrShareNotFound):
+		statusCode = http.StatusNotFound
+		message = "Share link not found"
+	case errors.Is(err, domain.ErrBackupNotFound):
+		statusCode = http.StatusNotFound
+		message = "Backup not found"
+	case errors.Is(err, domain.ErrBackupInProgress):
+		statusCode = http.StatusConflict
+		message = "A backup or restore is already running"
+	case errors.Is(err, domain.ErrTaxonomyKeyNotFound):
+		statusCode = http.StatusNotFound
+		message = "No display names for key"
+	case errors.Is(err, domain.ErrAssetTypeBlocked):
+		statusCode = http.StatusForbidden
+		message = "Your role may not favorite this asset type"
+	case errors.Is(err, domain.ErrAssetHidden):
+		statusCode = http.StatusForbidden
+		message = "Asset is unavailable"
+	case errors.Is(err, domain.ErrAlreadyReported):
+		statusCode = http.StatusConflict
+		message = "Asset already reported"
+	case errors.Is(err, domain.ErrReportNotFound):
+		statusCode = http.StatusNotFound
+		message = "No reports for asset"
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		statusCode = http.StatusNotFound
+		message = "No webhook subscription"
+	case errors.Is(err, domain.ErrDirectoryUnavailable):
+		statusCode = http.StatusServiceUnavailable
+		message = "User directory unavailable"
+	case errors.Is(err, domain.ErrCatalogUnavailable):
+		statusCode = http.StatusServiceUnavailable
+		message = "Asset catalog unavailable"
+	case errors.Is(err, domain.ErrSunset):
+		statusCode = http.StatusGone
+		message = "This feature has been retired"
+	case errors.Is(err, domain.ErrUnauthorized):
+		statusCode = http.StatusUnauthorized
+		message = "Unauthorized"
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		statusCode = http.StatusUnauthorized
+		message = "Invalid username or password"
+	case errors.Is(err, domain.ErrForbidden):
+		statusCode = http.StatusForbidden
+		message = "Forbidden"
+	case errors.Is(err, domain.ErrRateLimited):
+		statusCode = http.StatusTooManyRequests
+		message = "Too many requests"
+	case errors.Is(err, domain.ErrTooManyConcurrent):
+		statusCode = http.StatusTooManyRequests
+		message = "Too many concurrent requests"
+	case errors.Is(err, domain.ErrQueryCostExceeded):
+		statusCode = http.StatusTooManyRequests
+		message = "Query cost budget exceeded"
 	default:
 		statusCode = http.StatusInternalServerError
 		message = "Internal server error"
 		h.logger.WithError(err).Error("Unexpected error occurred")
 	}
 
-	h.sendResponse(w, statusCode, APIResponse{
-		Success: false,
-		Error:   message,
-	})
+	return statusCode, message
 }
 
-// Middleware
-func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+// notModified sets a strong ETag derived from the canonical encoding of
+// payload and answers 304 when the client already holds that representation
+func (h *Handler) notModified(w http.ResponseWriter, r *http.Request, payload interface{}) bool {
+	hash, err := canonicaljson.Hash(payload)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to compute ETag")
+		return false
+	}
 
-		// Create a custom response writer to capture status code
-		wrapped := &responseWriterWrapper{ResponseWriter: w}
+	etag := `"` + hash + `"`
+	w.Header().Set("ETag", etag)
 
-		next.ServeHTTP(wrapped, r)
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == etag || candidate == "*" {
+			h.usage.Record(usageRevalidation, "not_modified")
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	h.usage.Record(usageRevalidation, "modified")
+	return false
+}
 
-		duration := time.Since(start)
-		h.logger.WithFields(logrus.Fields{
-			"method":     r.Method,
-			"path":       r.URL.Path,
-			"status":     wrapped.statusCode,
-			"duration":   duration,
-			"user_agent": r.UserAgent(),
-		}).Info("HTTP request completed")
-	})
+// writeJSON writes an already encoded JSON body
+func writeJSON(w http.ResponseWriter, statusCode int, body []byte) {
+	w.Header()["Content-Type"] = jsonContentType
+	w.WriteHeader(statusCode)
+	w.Write(body)
 }
 
-func (h *Handler) CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+func ptr[T any](v T) *T {
+	return &v
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// wantsJSONAPI reports whether the client negotiated the JSON:API media type
+func wantsJSONAPI(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), serializer.JSONAPIMediaType)
+}
+
+// IDValidationMiddleware checks path IDs before the request reaches a handler
+func (h *Handler) IDValidationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if userID, ok := vars["userID"]; ok {
+			if err := h.idValidator.ValidateUserID(userID); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
+		}
+		if assetID, ok := vars["assetID"]; ok {
+			if err := h.idValidator.ValidateAssetID(assetID); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
 		}
 
 		next.ServeHTTP(w, r)
@@ -274,3 +1184,8 @@ func (w *responseWriterWrapper) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
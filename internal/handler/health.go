@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/health"
+)
+
+// WithHealthScore enables the health score admin route. API responses are
+// counted in requests, which the scorer's error rate check reads.
+func WithHealthScore(scorer *health.Scorer, requests *health.RequestWindow) Option {
+	return func(h *Handler) {
+		h.healthScorer = scorer
+		h.requestWindow = requests
+	}
+}
+
+// GetHealthScore handles GET /api/admin/health/score
+func (h *Handler) GetHealthScore(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.healthScorer.Score(r.Context()),
+	})
+}
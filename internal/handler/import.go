@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/serializer"
+	"gwi-favorites-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// importFileField is the multipart field holding the imported file
+	importFileField = "file"
+	// maxImportBytes bounds the request body of an import
+	maxImportBytes = 10 << 20
+	// maxImportRows bounds the favorites of one import
+	maxImportRows = 10000
+)
+
+// ImportFavorites handles POST /api/users/{userID}/favorites/import. The
+// multipart field "file" holds favorites as exported by ExportFavorites, in
+// JSON or CSV, told apart by ?format=, the file name or its content type.
+// Rows naming only an asset_id favorite that catalog asset. The response
+// reports how many rows were created, skipped as already favorited, or
+// failed, with the reasons of the latter two.
+func (h *Handler) ImportFavorites(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, header, err := r.FormFile(importFileField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.handleError(w, r, domain.WithContext(domain.ErrFieldTooLong, "field", importFileField))
+			return
+		}
+		h.handleError(w, r, domain.WithContext(domain.ErrMissingRequiredField, "field", importFileField))
+		return
+	}
+	defer file.Close()
+
+	format, err := importFormat(r, header)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	var rows []service.ImportRow
+	if format == exportFormatCSV {
+		rows, err = readCSVImport(file)
+	} else {
+		rows, err = readJSONImport(file)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if len(rows) > maxImportRows {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", importFileField, "reason", "too many rows"))
+		return
+	}
+	if h.idValidator != nil {
+		for i, row := range rows {
+			assetID := row.AssetID
+			if row.Asset != nil {
+				assetID = row.Asset.GetID()
+			}
+			if err := h.idValidator.ValidateAssetID(assetID); err != nil && row.Err == nil {
+				rows[i].Err = err
+			}
+		}
+	}
+
+	report, err := h.favoritesService.ImportFavorites(r.Context(), userID, rows)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// importFormat tells the format of an imported file: ?format= when given,
+// otherwise the file's extension or content type
+func importFormat(r *http.Request, header *multipart.FileHeader) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch strings.ToLower(path.Ext(header.Filename)) {
+		case ".csv":
+			format = exportFormatCSV
+		case ".json":
+			format = exportFormatJSON
+		}
+	}
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = exportFormatCSV
+		case "application/json":
+			format = exportFormatJSON
+		}
+	}
+	if format != exportFormatJSON && format != exportFormatCSV {
+		return "", domain.WithContext(domain.ErrInvalidInput, "field", "format")
+	}
+	return format, nil
+}
+
+// readJSONImport reads a JSON array whose elements are exported favorites,
+// bare assets or {"asset_id": ...} references to catalog assets. Elements
+// that are not valid assets become failed rows.
+func readJSONImport(file io.Reader) ([]service.ImportRow, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(file).Decode(&elements); err != nil {
+		return nil, domain.WithContext(domain.ErrInvalidInput, "field", importFileField)
+	}
+
+	rows := make([]service.ImportRow, 0, len(elements))
+	for _, element := range elements {
+		var favorite struct {
+			AssetID string           `json:"asset_id"`
+			Asset   json.RawMessage  `json:"asset"`
+			Note    string           `json:"note"`
+			Type    domain.AssetType `json:"type"`
+		}
+		if err := json.Unmarshal(element, &favorite); err != nil {
+			rows = append(rows, service.ImportRow{Err: domain.ErrInvalidInput})
+			continue
+		}
+
+		row := service.ImportRow{AssetID: favorite.AssetID, Note: favorite.Note}
+		rawAsset := favorite.Asset
+		if favorite.Type != "" {
+			rawAsset = element
+		}
+		if len(rawAsset) > 0 && string(rawAsset) != "null" {
+			row.Asset, row.Err = domain.AssetFromJSON(rawAsset)
+			if row.Err != nil && !errors.Is(row.Err, domain.ErrInvalidAssetType) {
+				row.Err = domain.ErrInvalidInput
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readCSVImport reads CSV with a header row naming the columns of
+// serializer.CSVHeader. Only asset_id is required; unknown columns are
+// ignored. Rows that are not valid assets become failed rows.
+func readCSVImport(file io.Reader) ([]service.ImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, domain.WithContext(domain.ErrInvalidInput, "field", importFileField)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	hasAssetID := false
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		hasAssetID = hasAssetID || header[i] == "asset_id"
+	}
+	if !hasAssetID {
+		return nil, domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_id")
+	}
+
+	var rows []service.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, domain.WithContext(domain.ErrInvalidInput, "field", importFileField)
+		}
+
+		cells := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				cells[header[i]] = value
+			}
+		}
+		asset, note, err := serializer.ParseFavoriteCSVRecord(cells)
+		rows = append(rows, service.ImportRow{
+			Asset:   asset,
+			AssetID: strings.TrimSpace(cells["asset_id"]),
+			Note:    note,
+			Err:     err,
+		})
+	}
+	return rows, nil
+}
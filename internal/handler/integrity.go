@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/metrics"
+)
+
+// RecordIntegrityCheck exports the findings of the latest storage integrity
+// check, whether run on schedule or by an admin
+func RecordIntegrityCheck(report repository.IntegrityReport) {
+	for kind, count := range map[string]int{
+		"missing_asset": report.MissingAssetCount,
+		"invalid_asset": report.InvalidAssetCount,
+		"counter_drift": len(report.CounterDrift),
+	} {
+		metrics.DefaultRegistry.Gauge("storage_integrity_issues", "Inconsistencies found by the latest storage integrity check, by kind", metrics.Labels{"kind": kind}).Set(float64(count))
+	}
+	metrics.DefaultRegistry.Counter("storage_integrity_repairs_total", "Favorites removed and counters corrected by integrity repairs", nil).Add(uint64(report.Repaired))
+}
+
+// CheckStorageIntegrity handles GET /api/admin/storage/integrity
+func (h *Handler) CheckStorageIntegrity(w http.ResponseWriter, r *http.Request) {
+	h.checkIntegrity(w, r, false)
+}
+
+// RepairStorageIntegrity handles POST /api/admin/storage/integrity,
+// reporting the inconsistencies found and repaired
+func (h *Handler) RepairStorageIntegrity(w http.ResponseWriter, r *http.Request) {
+	h.checkIntegrity(w, r, true)
+}
+
+func (h *Handler) checkIntegrity(w http.ResponseWriter, r *http.Request, repair bool) {
+	report, err := h.storageService.CheckIntegrity(r.Context(), repair)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	RecordIntegrityCheck(report)
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
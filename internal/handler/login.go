@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/metrics"
+)
+
+// Route names of the token endpoints, which are reachable without a token
+const (
+	routeLogin   = "auth.login"
+	routeRefresh = "auth.refresh"
+)
+
+// WithAuthService enables the login and token refresh routes
+func WithAuthService(authService *service.AuthService) Option {
+	return func(h *Handler) {
+		h.authService = authService
+	}
+}
+
+// LoginRequest is the body of POST /api/auth/login
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest is the body of POST /api/auth/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Login handles POST /api/auth/login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		h.handleError(w, r, domain.ErrMissingRequiredField)
+		return
+	}
+
+	tokens, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	outcome := "success"
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		outcome = "failure"
+	}
+	metrics.DefaultRegistry.Counter("auth_logins_total", "Login attempts by outcome", metrics.Labels{"outcome": outcome}).Inc()
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendResponse(w, http.StatusOK, APIResponse{Success: true, Data: tokens})
+}
+
+// RefreshToken handles POST /api/auth/refresh. The refresh token is
+// single use; the response carries its replacement.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if req.RefreshToken == "" {
+		h.handleError(w, r, domain.ErrMissingRequiredField)
+		return
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendResponse(w, http.StatusOK, APIResponse{Success: true, Data: tokens})
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/moderation"
+
+	"github.com/gorilla/mux"
+)
+
+// ReportAssetRequest is the body of an abuse report
+type ReportAssetRequest struct {
+	ReporterID string            `json:"reporter_id"`
+	Reason     moderation.Reason `json:"reason"`
+	Details    string            `json:"details"`
+}
+
+// ResolveReportsRequest is the moderator's decision on a reported asset
+type ResolveReportsRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// ReportAsset handles POST /api/assets/{assetID}/report
+func (h *Handler) ReportAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+
+	var req ReportAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	// Authenticated callers report as themselves. Without authentication a
+	// reporter_id is unverified, so reports are counted per client address.
+	if h.verifier == nil {
+		req.ReporterID = clientAddr(r)
+	} else if claims := auth.ClaimsFromContext(r.Context()); claims != nil && req.ReporterID == "" {
+		req.ReporterID = claims.Subject
+	}
+	if req.ReporterID == "" {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "reporter_id"))
+		return
+	}
+	if !h.mayActAs(r, req.ReporterID) {
+		h.handleError(w, r, domain.WithContext(domain.ErrForbidden, "reporter_id", req.ReporterID))
+		return
+	}
+
+	hidden, err := h.moderation.ReportAsset(r.Context(), assetID, req.ReporterID, req.Reason, req.Details)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusAccepted, APIResponse{
+		Success: true,
+		Data:    map[string]bool{"hidden": hidden},
+	})
+}
+
+// GetModerationQueue handles GET /api/admin/moderation/reports
+func (h *Handler) GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.moderation.Queue(r.Context()),
+	})
+}
+
+// ResolveReports handles PUT /api/admin/moderation/assets/{assetID}
+func (h *Handler) ResolveReports(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+
+	var req ResolveReportsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := h.moderation.Resolve(r.Context(), assetID, req.Hidden); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]bool{"hidden": req.Hidden},
+	})
+}
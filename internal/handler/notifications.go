@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"gwi-favorites-service/internal/notification"
+
+	"github.com/gorilla/mux"
+)
+
+// WithNotifications enables the per-user notifications route
+func WithNotifications(store *notification.Store) Option {
+	return func(h *Handler) {
+		h.notices = store
+	}
+}
+
+// GetNotifications handles GET /api/users/{userID}/notifications
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.notices.List(mux.Vars(r)["userID"]),
+	})
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gwi-favorites-service/internal/serializer"
+
+	"github.com/gorilla/mux"
+)
+
+// openAPIScope selects the routes an OpenAPI document describes. Each
+// scope covers the routes its callers can reach, so the authenticated
+// document includes the public routes and the admin document every route.
+type openAPIScope int
+
+const (
+	openAPIPublic openAPIScope = iota
+	openAPIAuthenticated
+	openAPIAdmin
+)
+
+var openAPIScopes = map[string]openAPIScope{
+	"public":        openAPIPublic,
+	"authenticated": openAPIAuthenticated,
+	"admin":         openAPIAdmin,
+}
+
+// routeOpenAPI serves the public and authenticated documents, which are
+// published to partners and so need no token
+const routeOpenAPI = "openapi"
+
+// Security scheme names used in the documents
+const (
+	openAPIBearer   = "bearerAuth"
+	openAPIAdminKey = "adminKey"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Servers    []openAPIServer                        `json:"servers"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   map[string]any `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string         `json:"description"`
+	Content     map[string]any `json:"content,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]any `json:"schemas"`
+	SecuritySchemes map[string]any `json:"securitySchemes,omitempty"`
+}
+
+// openAPIErrorSchema describes the envelope of every failed response
+var openAPIErrorSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"success": map[string]any{"type": "boolean"},
+		"error":   map[string]any{"type": "string"},
+		"code":    map[string]any{"type": "string"},
+		"context": map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"success", "error"},
+}
+
+// openAPIPathParam matches a route variable, with or without a pattern
+var openAPIPathParam = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// serveOpenAPI serves the OpenAPI document of the API routes of router.
+// The scope comes from the {scope} path variable, or is admin on routes
+// without one. Documents are generated per request from the routes
+// actually registered, so they follow the handler's options.
+func (h *Handler) serveOpenAPI(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope := openAPIAdmin
+		if name, ok := mux.Vars(r)["scope"]; ok {
+			scope = openAPIScopes[name]
+		}
+
+		document, err := h.openAPIDocument(router, scope)
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(document)
+	}
+}
+
+// openAPIDocument describes the API routes of router that callers with
+// scope can reach. Operations list their path parameters and credentials;
+// request and response bodies are documented in the README.
+func (h *Handler) openAPIDocument(router *mux.Router, scope openAPIScope) (openAPIDocument, error) {
+	server := h.pathPrefix
+	if server == "" {
+		server = "/"
+	}
+	document := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "GWI Favorites Service",
+			Version: string(serializer.DefaultVersion),
+		},
+		Servers: []openAPIServer{{URL: server}},
+		Paths:   make(map[string]map[string]openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: map[string]any{"Error": openAPIErrorSchema},
+		},
+	}
+
+	schemes := make(map[string]any)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		required := openAPIAuthenticated
+		switch {
+		case publicRoutes[route.GetName()]:
+			required = openAPIPublic
+		case strings.HasPrefix(template, "/api/admin/"):
+			required = openAPIAdmin
+		}
+		if required > scope {
+			return nil
+		}
+		security := h.openAPISecurity(required, schemes)
+
+		path := openAPIPathParam.ReplaceAllString(template, "{$1}")
+		var parameters []openAPIParameter
+		for _, match := range openAPIPathParam.FindAllStringSubmatch(template, -1) {
+			parameters = append(parameters, openAPIParameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   map[string]any{"type": "string"},
+			})
+		}
+
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			if document.Paths[path] == nil {
+				document.Paths[path] = make(map[string]openAPIOperation)
+			}
+			document.Paths[path][strings.ToLower(method)] = openAPIOperation{
+				OperationID: openAPIOperationID(method, path),
+				Parameters:  parameters,
+				Security:    security,
+				Responses: map[string]openAPIResponse{
+					"2XX": {Description: "Success"},
+					"default": {
+						Description: "Error",
+						Content: map[string]any{
+							"application/json": map[string]any{
+								"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+							},
+						},
+					},
+				},
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return openAPIDocument{}, err
+	}
+	if len(schemes) > 0 {
+		document.Components.SecuritySchemes = schemes
+	}
+	return document, nil
+}
+
+// openAPISecurity lists the credentials accepted by routes requiring
+// scope, adding the schemes it names to schemes. Public routes, and every
+// route when the handler checks no credentials, need none.
+func (h *Handler) openAPISecurity(scope openAPIScope, schemes map[string]any) []map[string][]string {
+	if scope == openAPIPublic {
+		return nil
+	}
+
+	var security []map[string][]string
+	if h.verifier != nil {
+		schemes[openAPIBearer] = map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+		security = append(security, map[string][]string{openAPIBearer: {}})
+	}
+	if scope == openAPIAdmin && h.adminKey != "" {
+		schemes[openAPIAdminKey] = map[string]any{"type": "apiKey", "in": "header", "name": adminKeyHeader}
+		security = append(security, map[string][]string{openAPIAdminKey: {}})
+	}
+	return security
+}
+
+// openAPIOperationID derives a unique operation ID from the method and
+// path, e.g. getUsersByUserIDFavoritesCount
+func openAPIOperationID(method, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api"), "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			name, suffix, _ := strings.Cut(name, "}")
+			id.WriteString("By" + openAPIWord(name) + openAPIWord(suffix))
+			continue
+		}
+		id.WriteString(openAPIWord(segment))
+	}
+	return id.String()
+}
+
+// openAPIWord capitalizes word and drops anything but letters and digits
+func openAPIWord(word string) string {
+	var out strings.Builder
+	upper := true
+	for _, c := range word {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		out.WriteRune(c)
+		upper = false
+	}
+	return out.String()
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// ReorderFavoritesRequest is the body of PUT /api/users/{userID}/favorites/order
+type ReorderFavoritesRequest struct {
+	// AssetIDs lists favorites in their new order; favorites left out lose
+	// their position
+	AssetIDs []string `json:"asset_ids"`
+}
+
+// ReorderFavorites handles PUT /api/users/{userID}/favorites/order
+func (h *Handler) ReorderFavorites(w http.ResponseWriter, r *http.Request) {
+	var req ReorderFavoritesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	if err := h.favoritesService.ReorderFavorites(r.Context(), mux.Vars(r)["userID"], req.AssetIDs); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Favorites reordered"},
+	})
+}
+
+// PinFavorite handles PUT /api/users/{userID}/favorites/{assetID}/pin
+func (h *Handler) PinFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true, "Favorite pinned")
+}
+
+// UnpinFavorite handles DELETE /api/users/{userID}/favorites/{assetID}/pin
+func (h *Handler) UnpinFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false, "Favorite unpinned")
+}
+
+func (h *Handler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool, message string) {
+	vars := mux.Vars(r)
+	if err := h.favoritesService.PinFavorite(r.Context(), vars["userID"], vars["assetID"], pinned); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": message},
+	})
+}
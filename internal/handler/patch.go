@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/validation"
+
+	"github.com/gorilla/mux"
+)
+
+// PatchAsset handles PATCH /api/admin/assets/{assetID}, applying a JSON
+// merge patch so clients can change a field without resending the asset
+func (h *Handler) PatchAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetID"]
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	ctx, warnings := validation.WithWarnings(r.Context())
+	asset, err := h.assetService.PatchAsset(ctx, assetID, patch)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success:  true,
+		Data:     asset,
+		Warnings: warnings.List(),
+	})
+}
+
+// PatchFavorite handles PATCH /api/users/{userID}/favorites/{assetID},
+// applying a JSON merge patch to the user's note and pin
+func (h *Handler) PatchFavorite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	patch, err := service.ParseFavoritePatch(body)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	ctx, warnings := validation.WithWarnings(r.Context())
+	favorite, err := h.favoritesService.PatchFavorite(ctx, vars["userID"], vars["assetID"], patch)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set(apiVersionHeader, string(version))
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success:  true,
+		Data:     h.localizedSerializers(w, r).SerializeFavorite(version, favorite),
+		Warnings: warnings.List(),
+	})
+}
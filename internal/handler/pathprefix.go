@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// WithPathPrefix serves the service under prefix, e.g. /favorites-service,
+// for running behind a gateway that routes on it. Requests without the
+// prefix are still served, so the gateway may strip it or not. Links the
+// service generates carry the prefix either way.
+func WithPathPrefix(prefix string) Option {
+	return func(h *Handler) {
+		h.pathPrefix = prefix
+	}
+}
+
+// ParsePathPrefix checks and normalizes a configured path prefix: it needs
+// a leading slash and loses any trailing ones, so "/" means no prefix
+func ParsePathPrefix(prefix string) (string, error) {
+	prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "?#% \t") || strings.Contains(prefix, "//") {
+		return "", domain.WithContext(domain.ErrInvalidInput, "field", "path_prefix")
+	}
+	return prefix, nil
+}
+
+// link returns the externally visible URL path of a service path
+func (h *Handler) link(path string) string {
+	return h.pathPrefix + path
+}
+
+// stripPathPrefix removes the path prefix from requests carrying it before
+// they are routed. Other requests are routed as they are.
+func (h *Handler) stripPathPrefix(next http.Handler) http.Handler {
+	strip := http.StripPrefix(h.pathPrefix, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, found := strings.CutPrefix(r.URL.Path, h.pathPrefix)
+		if found && (rest == "" || strings.HasPrefix(rest, "/")) {
+			strip.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// problemMediaType is the RFC 7807 media type for error responses
+const problemMediaType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Code and Context are
+// extension members carrying the domain error code and the IDs involved.
+type Problem struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Status  int               `json:"status"`
+	Code    string            `json:"code,omitempty"`
+	Context map[string]string `json:"context,omitempty"`
+}
+
+// wantsProblemJSON reports whether the client asked for problem details errors
+func wantsProblemJSON(r *http.Request) bool {
+	return r != nil && strings.Contains(r.Header.Get("Accept"), problemMediaType)
+}
+
+func (h *Handler) sendProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", problemMediaType)
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		h.logger.WithError(err).Error("Failed to encode problem response")
+	}
+}
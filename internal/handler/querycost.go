@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// WithQueryCostBudget meters expensive queries against a per caller cost
+// budget. A request the budget cannot cover waits up to maxWait for it to
+// refill; with a zero maxWait it is refused straight away.
+func WithQueryCostBudget(budget *ratelimit.CostBudget, weights ratelimit.CostWeights, maxWait time.Duration) Option {
+	return func(h *Handler) {
+		h.queryCosts = budget
+		h.queryCostWeights = weights
+		h.queryCostWait = maxWait
+	}
+}
+
+// QueryCostMiddleware charges priced routes to the caller's query cost
+// budget. It is keyed like RateLimitMiddleware; routes without a cost pass
+// through untouched.
+func (h *Handler) QueryCostMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost := h.queryCost(r)
+		if cost == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, key := callerKey(r)
+		decision, queued := h.spendQueryCost(r.Context(), key, cost)
+		w.Header().Set("X-Query-Cost", strconv.Itoa(cost))
+		w.Header().Set("X-Query-Cost-Remaining", strconv.Itoa(decision.Remaining))
+
+		outcome := "allowed"
+		switch {
+		case !decision.Allowed:
+			outcome = "rejected"
+		case queued:
+			outcome = "queued"
+		}
+		metrics.DefaultRegistry.Counter("query_cost_requests_total", "Priced API requests checked against the caller's query cost budget", metrics.Labels{
+			"outcome": outcome,
+		}).Inc()
+
+		if !decision.Allowed {
+			seconds := int(decision.RetryAfter.Seconds())
+			if decision.RetryAfter > 0 && seconds == 0 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			h.handleError(w, r, domain.WithContext(domain.ErrQueryCostExceeded,
+				"cost", strconv.Itoa(cost),
+				"budget", strconv.Itoa(decision.Limit),
+			))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// queryCost prices the matched route, or returns 0 for unpriced routes.
+// Listings with invalid parameters are left for the handler to reject.
+func (h *Handler) queryCost(r *http.Request) int {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return 0
+	}
+
+	switch route.GetName() {
+	case routeListFavorites, routeRecentFavorites, routeListCollection, routeSharedFavorites:
+		query, err := parseFavoritesQuery(r)
+		if err != nil {
+			return 0
+		}
+		return h.queryCostWeights.ListingCost(query.Limit, query.Offset, query.Search)
+	case routeListAssets, routeListAllAssets:
+		query, err := parseAssetQuery(r)
+		if err != nil {
+			return 0
+		}
+		return h.queryCostWeights.ListingCost(query.Limit, query.Offset, query.Search)
+	case routeCreateBackup, routeExportFavorites, routeImportFavorites, routeExportAudit:
+		return h.queryCostWeights.Export
+	}
+	return 0
+}
+
+// spendQueryCost spends cost from key's budget, queueing the request while
+// the budget will cover it within queryCostWait. It reports whether the
+// request had to wait.
+func (h *Handler) spendQueryCost(ctx context.Context, key string, cost int) (ratelimit.Decision, bool) {
+	decision := h.queryCosts.Spend(key, cost)
+
+	var waited time.Duration
+	for !decision.Allowed && waited+decision.RetryAfter <= h.queryCostWait {
+		timer := time.NewTimer(decision.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return decision, waited > 0
+		case <-timer.C:
+		}
+		waited += decision.RetryAfter
+		// Others may have spent the refill meanwhile, so check again
+		decision = h.queryCosts.Spend(key, cost)
+	}
+	return decision, waited > 0
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// WithReadOnly serves reads only, for replicas over replicated storage.
+// Writes are refused with a pointer to primaryURL, the base URL of the
+// primary, when it is set.
+func WithReadOnly(primaryURL string) Option {
+	return func(h *Handler) {
+		h.readOnly = true
+		h.primaryURL = strings.TrimSuffix(primaryURL, "/")
+	}
+}
+
+// readOnlySafeRoutes are served by read-only replicas despite their
+// method, since they leave storage alone
+var readOnlySafeRoutes = map[string]bool{
+	routeCheckFavorites: true,
+	routeLogin:          true,
+	routeRefresh:        true,
+	// Backups only read storage
+	routeCreateBackup: true,
+	// Per-instance settings
+	routeLogPolicy:      true,
+	routeTraceSampling:  true,
+	routeEnableCapture:  true,
+	routeDisableCapture: true,
+}
+
+// readOnlyMethods are the methods read-only replicas serve on every route
+const readOnlyMethods = "GET, HEAD, OPTIONS"
+
+// ReadOnlyMiddleware refuses requests that would write storage with 405
+// and the same path on the primary
+func (h *Handler) ReadOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if route := mux.CurrentRoute(r); route != nil && readOnlySafeRoutes[route.GetName()] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", readOnlyMethods)
+		var err error = domain.ErrReadOnly
+		if h.primaryURL != "" {
+			// RequestURI keeps any path prefix stripped from the URL
+			primary := h.primaryURL + r.RequestURI
+			w.Header().Set("Link", "<"+primary+`>; rel="primary"`)
+			err = domain.WithContext(err, "primary", primary)
+		}
+		h.handleError(w, r, err)
+	})
+}
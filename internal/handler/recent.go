@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultRecentDays is the window of recent favorites without ?days=
+	defaultRecentDays = 7
+	// maxRecentDays bounds the window of recent favorites
+	maxRecentDays = 365
+)
+
+// GetRecentFavorites handles GET /api/users/{userID}/favorites/recent,
+// listing the favorites added in the last ?days= days, 7 by default, newest
+// first. The paging and type and search filters of GetUserFavorites apply.
+func (h *Handler) GetRecentFavorites(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	days := defaultRecentDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		if days, err = strconv.Atoi(value); err != nil || days <= 0 || days > maxRecentDays {
+			h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "days", "max", strconv.Itoa(maxRecentDays)))
+			return
+		}
+	}
+	query, err := parseFavoritesQuery(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	window := time.Duration(days) * 24 * time.Hour
+	favorites, pagination, err := h.favoritesService.ListRecentFavorites(r.Context(), userID, window, query)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set(apiVersionHeader, string(version))
+	response := APIResponse{
+		Success:    true,
+		Data:       h.localizedSerializers(w, r).SerializeFavorites(version, favorites),
+		Pagination: &pagination,
+	}
+	if h.notModified(w, r, response) {
+		return
+	}
+	h.sendResponse(w, http.StatusOK, response)
+}
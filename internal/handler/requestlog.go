@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestLogPolicy controls which completed requests the logging middleware records.
+// Client and server errors and slow requests are always logged; everything
+// else is filtered by ErrorsOnly, the route level and the sample rate.
+type RequestLogPolicy struct {
+	// SampleRate is the fraction (0..1) of unremarkable requests that are logged
+	SampleRate float64
+	// SlowThreshold marks requests at or above this duration as slow; 0 disables it
+	SlowThreshold time.Duration
+	// ErrorsOnly suppresses logging of successful, fast requests
+	ErrorsOnly bool
+	// RouteLevels overrides the log level of successful requests per route name
+	RouteLevels map[string]logrus.Level
+}
+
+// DefaultRequestLogPolicy logs every request at info, except the high-volume
+// check and count routes which are logged at debug
+func DefaultRequestLogPolicy() RequestLogPolicy {
+	return RequestLogPolicy{
+		SampleRate: 1,
+		RouteLevels: map[string]logrus.Level{
+			routeCheckFavorite: logrus.DebugLevel,
+			routeFavoriteCount: logrus.DebugLevel,
+		},
+	}
+}
+
+// WithRequestLogPolicy sets the initial request logging policy
+func WithRequestLogPolicy(policy RequestLogPolicy) Option {
+	return func(h *Handler) {
+		h.logPolicy.Store(&policy)
+	}
+}
+
+// ParseRequestLogPolicy builds a policy from its textual configuration,
+// e.g. a slow threshold of "500ms" and route levels such as {"favorites.list": "debug"}
+func ParseRequestLogPolicy(sampleRate float64, slowThreshold string, errorsOnly bool, routeLevels map[string]string) (RequestLogPolicy, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return RequestLogPolicy{}, domain.ErrInvalidInput
+	}
+
+	policy := RequestLogPolicy{
+		SampleRate:  sampleRate,
+		ErrorsOnly:  errorsOnly,
+		RouteLevels: make(map[string]logrus.Level, len(routeLevels)),
+	}
+
+	if slowThreshold != "" {
+		threshold, err := time.ParseDuration(slowThreshold)
+		if err != nil || threshold < 0 {
+			return RequestLogPolicy{}, domain.ErrInvalidInput
+		}
+		policy.SlowThreshold = threshold
+	}
+
+	for route, name := range routeLevels {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return RequestLogPolicy{}, domain.ErrInvalidInput
+		}
+		policy.RouteLevels[route] = level
+	}
+
+	return policy, nil
+}
+
+// requestLogPolicy holds the active policy; it can be swapped at runtime
+type requestLogPolicy struct {
+	atomic.Pointer[RequestLogPolicy]
+}
+
+// decide returns the level to log a completed request at, or false to skip it
+func (p *RequestLogPolicy) decide(r *http.Request, status int, duration time.Duration) (logrus.Level, bool) {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return logrus.ErrorLevel, true
+	case status >= http.StatusBadRequest:
+		return logrus.WarnLevel, true
+	case p.SlowThreshold > 0 && duration >= p.SlowThreshold:
+		return logrus.WarnLevel, true
+	case p.ErrorsOnly:
+		return 0, false
+	}
+
+	level := logrus.InfoLevel
+	if route := mux.CurrentRoute(r); route != nil {
+		if routeLevel, exists := p.RouteLevels[route.GetName()]; exists {
+			level = routeLevel
+		}
+	}
+
+	if p.SampleRate < 1 && rand.Float64() >= p.SampleRate {
+		return 0, false
+	}
+
+	return level, true
+}
+
+// LoggingMiddleware logs completed requests according to the active RequestLogPolicy
+func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Create a custom response writer to capture status code
+		wrapped := &responseWriterWrapper{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(wrapped, r)
+
+		duration := time.Since(start)
+		h.requestWindow.Record(wrapped.statusCode)
+		level, ok := h.logPolicy.Load().decide(r, wrapped.statusCode, duration)
+
+		// Avoid building log fields that would be discarded
+		if !ok || !h.logger.IsLevelEnabled(level) {
+			return
+		}
+
+		fields := logrus.Fields{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     wrapped.statusCode,
+			"duration":   duration,
+			"user_agent": r.UserAgent(),
+		}
+		if sc, ok := tracing.SpanFromContext(r.Context()); ok {
+			fields["trace_id"] = sc.TraceID
+		}
+
+		h.logger.WithFields(fields).Log(level, "HTTP request completed")
+	})
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// SCIM 2.0 (RFC 7643, RFC 7644) media type and schema URNs
+const (
+	scimMediaType   = "application/scim+json"
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// WithSCIM serves the /scim/v2/Users provisioning endpoint for the identity
+// platform, authenticated with a shared bearer token
+func WithSCIM(users *service.UserService, token string) Option {
+	return func(h *Handler) {
+		h.users = users
+		h.scimToken = token
+	}
+}
+
+// SCIMEmail is a SCIM multi-valued email attribute
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMeta is the SCIM resource metadata
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMUser is the subset of the SCIM core user schema the service keeps.
+// userName is the platform user ID and doubles as the resource id.
+type SCIMUser struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	UserName    string   `json:"userName"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Name        *struct {
+		Formatted string `json:"formatted,omitempty"`
+	} `json:"name,omitempty"`
+	Emails []SCIMEmail `json:"emails,omitempty"`
+	Active *bool       `json:"active,omitempty"`
+	Meta   *SCIMMeta   `json:"meta,omitempty"`
+}
+
+// SCIMPatchRequest is a SCIM PATCH request; only deactivation is supported
+type SCIMPatchRequest struct {
+	Schemas    []string `json:"schemas"`
+	Operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"Operations"`
+}
+
+// SCIMError is a SCIM error response
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// SCIMAuthMiddleware requires the SCIM bearer token
+func (h *Handler) SCIMAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok || h.scimToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.scimToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.sendSCIMError(w, domain.ErrUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CreateSCIMUser handles POST /scim/v2/Users
+func (h *Handler) CreateSCIMUser(w http.ResponseWriter, r *http.Request) {
+	var req SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendSCIMError(w, domain.ErrInvalidInput)
+		return
+	}
+	if req.UserName == "" {
+		h.sendSCIMError(w, domain.WithContext(domain.ErrMissingRequiredField, "field", "userName"))
+		return
+	}
+	if req.Active != nil && !*req.Active {
+		h.sendSCIMError(w, domain.WithContext(domain.ErrInvalidInput, "field", "active"))
+		return
+	}
+	if h.idValidator != nil {
+		if err := h.idValidator.ValidateUserID(req.UserName); err != nil {
+			h.sendSCIMError(w, err)
+			return
+		}
+	}
+
+	name := req.DisplayName
+	if name == "" && req.Name != nil {
+		name = req.Name.Formatted
+	}
+	var email string
+	for _, candidate := range req.Emails {
+		if email == "" || candidate.Primary {
+			email = candidate.Value
+		}
+	}
+
+	user := domain.NewUser(req.UserName, email, name)
+	if err := h.users.CreateUser(r.Context(), user); err != nil {
+		h.sendSCIMError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", h.scimLocation(user.ID))
+	h.sendSCIM(w, http.StatusCreated, h.scimResource(user))
+}
+
+// GetSCIMUser handles GET /scim/v2/Users/{userID}
+func (h *Handler) GetSCIMUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.users.GetUser(r.Context(), mux.Vars(r)["userID"])
+	if err != nil {
+		h.sendSCIMError(w, err)
+		return
+	}
+
+	h.sendSCIM(w, http.StatusOK, h.scimResource(user))
+}
+
+// PatchSCIMUser handles PATCH /scim/v2/Users/{userID}. Setting active to
+// false deprovisions the user and erases their data.
+func (h *Handler) PatchSCIMUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	var req SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendSCIMError(w, domain.ErrInvalidInput)
+		return
+	}
+
+	deactivate := false
+	for _, op := range req.Operations {
+		active, ok := patchActive(op.Path, op.Value)
+		if !strings.EqualFold(op.Op, "replace") || !ok {
+			h.sendSCIMError(w, domain.WithContext(domain.ErrInvalidInput, "op", op.Op, "path", op.Path))
+			return
+		}
+		deactivate = deactivate || !active
+	}
+
+	if !deactivate {
+		// Activating an existing user changes nothing
+		user, err := h.users.GetUser(r.Context(), userID)
+		if err != nil {
+			h.sendSCIMError(w, err)
+			return
+		}
+		h.sendSCIM(w, http.StatusOK, h.scimResource(user))
+		return
+	}
+
+	h.deprovision(w, r, userID)
+}
+
+// DeleteSCIMUser handles DELETE /scim/v2/Users/{userID}, deprovisioning the
+// user and erasing their data
+func (h *Handler) DeleteSCIMUser(w http.ResponseWriter, r *http.Request) {
+	h.deprovision(w, r, mux.Vars(r)["userID"])
+}
+
+func (h *Handler) deprovision(w http.ResponseWriter, r *http.Request, userID string) {
+	if err := h.users.EraseUser(r.Context(), userID); err != nil {
+		h.sendSCIMError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// patchActive reads the active value of a replace operation, given either
+// as path "active" or as an attribute of the value object. Some identity
+// providers send booleans as strings.
+func patchActive(path string, value json.RawMessage) (bool, bool) {
+	if path == "" {
+		var attributes struct {
+			Active json.RawMessage `json:"active"`
+		}
+		if err := json.Unmarshal(value, &attributes); err != nil || attributes.Active == nil {
+			return false, false
+		}
+		return patchActive("active", attributes.Active)
+	}
+	if !strings.EqualFold(path, "active") {
+		return false, false
+	}
+
+	var active bool
+	if err := json.Unmarshal(value, &active); err == nil {
+		return active, true
+	}
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		if active, err := strconv.ParseBool(text); err == nil {
+			return active, true
+		}
+	}
+	return false, false
+}
+
+func (h *Handler) scimLocation(userID string) string {
+	return h.link("/scim/v2/Users/" + userID)
+}
+
+func (h *Handler) scimResource(user *domain.User) SCIMUser {
+	active := true
+	resource := SCIMUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          user.ID,
+		UserName:    user.ID,
+		DisplayName: user.Name,
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     h.scimLocation(user.ID),
+		},
+	}
+	if user.Email != "" {
+		resource.Emails = []SCIMEmail{{Value: user.Email, Primary: true}}
+	}
+	return resource
+}
+
+func (h *Handler) sendSCIM(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", scimMediaType)
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.WithError(err).Error("Failed to encode SCIM response")
+	}
+}
+
+func (h *Handler) sendSCIMError(w http.ResponseWriter, err error) {
+	statusCode, message := h.errorStatus(err)
+
+	var scimType string
+	switch statusCode {
+	case http.StatusConflict:
+		scimType = "uniqueness"
+	case http.StatusBadRequest:
+		scimType = "invalidValue"
+	}
+
+	h.sendSCIM(w, statusCode, SCIMError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(statusCode),
+		ScimType: scimType,
+		Detail:   message,
+	})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/sharing"
+
+	"github.com/gorilla/mux"
+)
+
+// WithSharing enables share links, through which users publish a read-only
+// view of their favorites that needs no authentication
+func WithSharing(store *sharing.Store) Option {
+	return func(h *Handler) {
+		h.shares = store
+	}
+}
+
+// ShareRequest creates a share link. Without ExpiresAt the link lasts until
+// it is revoked or replaced.
+type ShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ShareResponse is a share link and, when just created, its URL
+type ShareResponse struct {
+	sharing.Share
+	URL string `json:"url,omitempty"`
+}
+
+// SharedFavoriteView is a favorite as shown through a share link, without
+// the owner's ID or private note
+type SharedFavoriteView struct {
+	AssetID     string      `json:"asset_id"`
+	Asset       interface{} `json:"asset"`
+	Pinned      bool        `json:"pinned,omitempty"`
+	Position    int         `json:"position,omitempty"`
+	AddedAt     time.Time   `json:"added_at"`
+	Unavailable bool        `json:"unavailable,omitempty"`
+}
+
+// CreateShare handles POST /api/users/{userID}/favorites/share. It replaces
+// any previous link of the user; the token is only returned here.
+func (h *Handler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	share, err := h.shares.Create(mux.Vars(r)["userID"], req.ExpiresAt)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data: ShareResponse{
+			Share: share,
+			URL:   h.link("/api/shared/" + share.Token),
+		},
+	})
+}
+
+// GetShare handles GET /api/users/{userID}/favorites/share
+func (h *Handler) GetShare(w http.ResponseWriter, r *http.Request) {
+	share, err := h.shares.Get(mux.Vars(r)["userID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    ShareResponse{Share: share},
+	})
+}
+
+// RevokeShare handles DELETE /api/users/{userID}/favorites/share
+func (h *Handler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	if err := h.shares.Revoke(mux.Vars(r)["userID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Share link revoked"},
+	})
+}
+
+// GetSharedFavorites handles GET /api/shared/{token}, listing the favorites
+// of the link's owner with the paging and filters of GetUserFavorites.
+// Unknown, revoked and expired tokens all give 404.
+func (h *Handler) GetSharedFavorites(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.shares.Resolve(mux.Vars(r)["token"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	version, err := h.apiVersion(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	query, err := parseFavoritesQuery(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	favorites, pagination, err := h.favoritesService.ListUserFavoritesPage(r.Context(), userID, query)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		err = domain.ErrShareNotFound
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set(apiVersionHeader, string(version))
+	views := h.localizedSerializers(w, r).SerializeFavorites(version, favorites)
+	shared := make([]SharedFavoriteView, len(views))
+	for i, view := range views {
+		shared[i] = SharedFavoriteView{
+			AssetID:     view.AssetID,
+			Asset:       view.Asset,
+			Pinned:      view.Pinned,
+			Position:    view.Position,
+			AddedAt:     view.AddedAt,
+			Unavailable: view.Unavailable,
+		}
+	}
+
+	response := APIResponse{
+		Success:    true,
+		Data:       shared,
+		Pagination: &pagination,
+	}
+	if h.notModified(w, r, response) {
+		return
+	}
+	h.sendResponse(w, http.StatusOK, response)
+}
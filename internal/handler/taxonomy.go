@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/serializer"
+	"gwi-favorites-service/internal/taxonomy"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// WithTaxonomy localizes category and tag names in favorites listings by
+// Accept-Language and enables the taxonomy admin routes
+func WithTaxonomy(catalog *taxonomy.Catalog) Option {
+	return func(h *Handler) {
+		h.taxonomy = catalog
+	}
+}
+
+// TaxonomyNamesRequest is the body of PUT /api/admin/taxonomy/{kind}/{key}
+type TaxonomyNamesRequest struct {
+	Names map[string]string `json:"names"`
+}
+
+// localizedSerializers returns the serializers for a response in the
+// languages the client accepts
+func (h *Handler) localizedSerializers(w http.ResponseWriter, r *http.Request) *serializer.Registry {
+	if h.taxonomy == nil {
+		return h.serializers
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+	languages := taxonomy.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if len(languages) == 0 {
+		return h.serializers
+	}
+	return h.serializers.WithDecorator(func(view interface{}) interface{} {
+		return h.taxonomy.Localize(languages, view)
+	})
+}
+
+// ListTaxonomy handles GET /api/admin/taxonomy
+func (h *Handler) ListTaxonomy(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    h.taxonomy.List(),
+	})
+}
+
+// SetTaxonomyNames handles PUT /api/admin/taxonomy/{kind}/{key}, replacing
+// the display names of a category or tag
+func (h *Handler) SetTaxonomyNames(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req TaxonomyNamesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	entry, err := h.taxonomy.Set(taxonomy.Kind(vars["kind"]), vars["key"], req.Names)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"kind": entry.Kind,
+		"key":  entry.Key,
+	}).Info("Taxonomy names updated")
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// DeleteTaxonomyNames handles DELETE /api/admin/taxonomy/{kind}/{key}
+func (h *Handler) DeleteTaxonomyNames(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := h.taxonomy.Delete(taxonomy.Kind(vars["kind"]), vars["key"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Taxonomy names removed"},
+	})
+}
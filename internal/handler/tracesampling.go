@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/metrics"
+	"gwi-favorites-service/pkg/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// forceTraceHeader asks for the request's trace to be sampled. It is only
+// honoured on requests carrying the admin API key.
+const forceTraceHeader = "X-Force-Trace"
+
+// TraceSamplingPolicy decides which of the traces this service starts are
+// sampled. Traces continued from a caller keep the caller's decision.
+type TraceSamplingPolicy struct {
+	// SampleRate is the fraction (0..1) of new traces sampled
+	SampleRate float64
+	// RouteRates overrides SampleRate per route name
+	RouteRates map[string]float64
+}
+
+// WithTraceSampling sets the initial trace sampling policy
+func WithTraceSampling(policy TraceSamplingPolicy) Option {
+	return func(h *Handler) {
+		h.tracePolicy.Store(&policy)
+	}
+}
+
+// ParseTraceSamplingPolicy builds a policy from its textual configuration,
+// e.g. route rates such as {"assets.list": "1", "favorites.check": "0.001"}
+func ParseTraceSamplingPolicy(sampleRate float64, routeRates map[string]string) (TraceSamplingPolicy, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return TraceSamplingPolicy{}, domain.WithContext(domain.ErrInvalidInput, "field", "sample_rate")
+	}
+
+	policy := TraceSamplingPolicy{
+		SampleRate: sampleRate,
+		RouteRates: make(map[string]float64, len(routeRates)),
+	}
+	for route, value := range routeRates {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return TraceSamplingPolicy{}, domain.WithContext(domain.ErrInvalidInput, "field", "route_rates", "route", route)
+		}
+		policy.RouteRates[route] = rate
+	}
+
+	return policy, nil
+}
+
+// traceSamplingPolicy holds the active policy; it can be swapped at runtime
+type traceSamplingPolicy struct {
+	atomic.Pointer[TraceSamplingPolicy]
+}
+
+// rate returns the sampling rate of new traces for the request's route
+func (p *TraceSamplingPolicy) rate(r *http.Request) float64 {
+	if route := mux.CurrentRoute(r); route != nil {
+		if rate, exists := p.RouteRates[route.GetName()]; exists {
+			return rate
+		}
+	}
+	return p.SampleRate
+}
+
+// sampleTrace is the tracing.Sampler of the service: admins may force a
+// trace, callers' decisions are kept, and new traces are sampled at their
+// route's rate
+func (h *Handler) sampleTrace(r *http.Request, parent *tracing.SpanContext) bool {
+	if forced, _ := strconv.ParseBool(r.Header.Get(forceTraceHeader)); forced && h.hasAdminKey(r) {
+		metrics.DefaultRegistry.Counter("traces_forced_total", "Traces sampled on request by an admin", nil).Inc()
+		return true
+	}
+	if parent != nil {
+		return parent.Sampled
+	}
+
+	rate := h.tracePolicy.Load().rate(r)
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// TraceSamplingRequest is the wire representation of a TraceSamplingPolicy
+type TraceSamplingRequest struct {
+	SampleRate float64            `json:"sample_rate"`
+	RouteRates map[string]float64 `json:"route_rates"`
+}
+
+// GetTraceSampling handles GET /api/admin/tracing
+func (h *Handler) GetTraceSampling(w http.ResponseWriter, r *http.Request) {
+	policy := h.tracePolicy.Load()
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: TraceSamplingRequest{
+			SampleRate: policy.SampleRate,
+			RouteRates: policy.RouteRates,
+		},
+	})
+}
+
+// UpdateTraceSampling handles PUT /api/admin/tracing, replacing the trace
+// sampling policy at runtime
+func (h *Handler) UpdateTraceSampling(w http.ResponseWriter, r *http.Request) {
+	var req TraceSamplingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	routeRates := make(map[string]string, len(req.RouteRates))
+	for route, rate := range req.RouteRates {
+		routeRates[route] = strconv.FormatFloat(rate, 'g', -1, 64)
+	}
+	policy, err := ParseTraceSamplingPolicy(req.SampleRate, routeRates)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.tracePolicy.Store(&policy)
+	h.logger.WithFields(logrus.Fields{
+		"sample_rate": policy.SampleRate,
+		"route_rates": policy.RouteRates,
+	}).Info("Trace sampling policy updated")
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Trace sampling policy updated"},
+	})
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// TransferFavoritesRequest is the body of POST /api/admin/favorites/transfer
+type TransferFavoritesRequest struct {
+	FromUserID string `json:"from_user_id"`
+	ToUserID   string `json:"to_user_id"`
+	// AssetIDs selects the favorites to transfer; omitted, all of them are
+	AssetIDs []string `json:"asset_ids"`
+	// Move removes the favorites from FromUserID instead of copying them
+	Move bool `json:"move"`
+}
+
+// TransferFavorites handles POST /api/admin/favorites/transfer, copying or
+// moving favorites between users in one step. The response lists the
+// favorites transferred and those skipped because the target had them.
+func (h *Handler) TransferFavorites(w http.ResponseWriter, r *http.Request) {
+	var req TransferFavoritesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if h.idValidator != nil {
+		for _, userID := range []string{req.FromUserID, req.ToUserID} {
+			if err := h.idValidator.ValidateUserID(userID); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
+		}
+		for _, assetID := range req.AssetIDs {
+			if err := h.idValidator.ValidateAssetID(assetID); err != nil {
+				h.handleError(w, r, err)
+				return
+			}
+		}
+	}
+
+	result, err := h.favoritesService.TransferFavorites(r.Context(), req.FromUserID, req.ToUserID, req.AssetIDs, req.Move)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// UndoRemoval handles POST /api/users/{userID}/favorites/undo, restoring
+// the user's most recently removed favorite
+func (h *Handler) UndoRemoval(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	assetID, err := h.favoritesService.UndoRemoval(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Favorite restored", "asset_id": assetID},
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/usage"
+)
+
+// Tracked features. Values are limited to small fixed sets so client
+// input cannot grow the metrics.
+const (
+	usageListFormat     = "favorites.list.format"
+	usageListVersion    = "favorites.list.api_version"
+	usageListTypeFilter = "favorites.list.type_filter"
+	usageListPaging     = "favorites.list.paging"
+	usageListSearch     = "favorites.list.search"
+	usageListSort       = "favorites.list.sort"
+	usageAddAssetType   = "favorites.add.asset_type"
+	usageChartData      = "favorites.chart_data"
+	usageRevalidation   = "conditional_requests"
+)
+
+// WithUsageTracker counts the use of optional API features and enables the
+// usage summary admin route
+func WithUsageTracker(tracker *usage.Tracker) Option {
+	return func(h *Handler) {
+		h.usage = tracker
+	}
+}
+
+// typeFilterValue maps a requested type filter to a bounded value
+func typeFilterValue(assetType domain.AssetType) string {
+	switch {
+	case assetType == "":
+		return "none"
+	case assetType.IsValid():
+		return string(assetType)
+	default:
+		return "invalid"
+	}
+}
+
+// sortValue combines a listing's sort field and direction, e.g. title_desc
+func sortValue(query domain.FavoritesQuery) string {
+	field := query.Sort
+	if field == "" {
+		field = domain.SortAddedAt
+	}
+	if query.Descending {
+		return string(field) + "_desc"
+	}
+	return string(field) + "_asc"
+}
+
+// UsageSummaryResponse is returned by the usage summary route
+type UsageSummaryResponse struct {
+	Since    time.Time       `json:"since"`
+	Features []usage.Feature `json:"features"`
+}
+
+// GetUsageSummary handles GET /api/admin/usage
+func (h *Handler) GetUsageSummary(w http.ResponseWriter, r *http.Request) {
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: UsageSummaryResponse{
+			Since:    h.usage.Since(),
+			Features: h.usage.Summary(),
+		},
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// WithUsers enables the user API, through which users are created,
+// updated and erased without an identity platform
+func WithUsers(users *service.UserService) Option {
+	return func(h *Handler) {
+		h.users = users
+	}
+}
+
+// UserRequest creates or updates a user. The ID is only read on creation.
+type UserRequest struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// CreateUser handles POST /api/users. With authentication, callers may only
+// create themselves unless they are admins.
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if req.ID == "" {
+		h.handleError(w, r, domain.WithContext(domain.ErrMissingRequiredField, "field", "id"))
+		return
+	}
+	if h.idValidator != nil {
+		if err := h.idValidator.ValidateUserID(req.ID); err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+	}
+	if !h.mayActAs(r, req.ID) {
+		h.handleError(w, r, domain.WithContext(domain.ErrForbidden, "user_id", req.ID))
+		return
+	}
+
+	user := domain.NewUser(req.ID, req.Email, req.Name)
+	if err := h.users.CreateUser(r.Context(), user); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", h.link("/api/users/"+user.ID))
+	h.sendResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data:    user,
+	})
+}
+
+// GetUser handles GET /api/users/{userID}
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.users.GetUser(r.Context(), mux.Vars(r)["userID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    user,
+	})
+}
+
+// UpdateUser handles PUT /api/users/{userID}, replacing the user's email
+// and name
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+	if req.ID != "" && req.ID != userID {
+		h.handleError(w, r, domain.WithContext(domain.ErrInvalidInput, "field", "id"))
+		return
+	}
+
+	user, err := h.users.UpdateUser(r.Context(), userID, req.Email, req.Name)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    user,
+	})
+}
+
+// DeleteUser handles DELETE /api/users/{userID}, erasing the user and all
+// of their favorites
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.users.EraseUser(r.Context(), mux.Vars(r)["userID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "User deleted"},
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/engagement"
+
+	"github.com/gorilla/mux"
+)
+
+// WithEngagement enables the owner webhook routes, through which asset
+// owners subscribe to batched favorite activity on their assets
+func WithEngagement(notifier *engagement.Notifier) Option {
+	return func(h *Handler) {
+		h.engagement = notifier
+	}
+}
+
+// WebhookRequest subscribes an asset owner to engagement webhooks
+type WebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// PutWebhook handles PUT /api/users/{userID}/webhook
+func (h *Handler) PutWebhook(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, domain.ErrInvalidInput)
+		return
+	}
+
+	sub, err := h.engagement.Subscribe(mux.Vars(r)["userID"], req.URL, req.Secret)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    sub,
+	})
+}
+
+// GetWebhook handles GET /api/users/{userID}/webhook. The secret is not returned.
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.engagement.Subscription(mux.Vars(r)["userID"])
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    sub,
+	})
+}
+
+// DeleteWebhook handles DELETE /api/users/{userID}/webhook
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.engagement.Unsubscribe(mux.Vars(r)["userID"]); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	h.sendResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Webhook removed"},
+	})
+}
@@ -0,0 +1,172 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/metrics"
+)
+
+// requestBuckets is the number of slices a RequestWindow is divided into;
+// old slices drop out as the window moves on
+const requestBuckets = 10
+
+type requestBucket struct {
+	start  time.Time
+	total  int
+	failed int
+}
+
+// RequestWindow counts API responses over a sliding window to derive the
+// recent server error rate
+type RequestWindow struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	buckets [requestBuckets]requestBucket
+}
+
+// NewRequestWindow creates a window covering the last window of requests;
+// a nil clock uses the system clock
+func NewRequestWindow(window time.Duration, c clock.Clock) *RequestWindow {
+	return &RequestWindow{window: window, clock: clock.OrSystem(c)}
+}
+
+// Record counts a response; 5xx statuses count as errors
+func (w *RequestWindow) Record(status int) {
+	if w == nil {
+		return
+	}
+
+	now := w.clock.Now()
+	span := w.window / requestBuckets
+	start := now.Truncate(span)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	bucket := &w.buckets[(start.UnixNano()/int64(span))%requestBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = requestBucket{start: start}
+	}
+	bucket.total++
+	if status >= 500 {
+		bucket.failed++
+	}
+}
+
+// Counts returns the responses and server errors within the window
+func (w *RequestWindow) Counts() (total, failed int) {
+	cutoff := w.clock.Now().Add(-w.window)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, bucket := range w.buckets {
+		if bucket.start.After(cutoff) {
+			total += bucket.total
+			failed += bucket.failed
+		}
+	}
+	return total, failed
+}
+
+// ErrorRate scores the window's server error rate, falling linearly from 1
+// with no errors to 0 when the rate reaches limit
+func ErrorRate(name string, window *RequestWindow, limit float64) Check {
+	return func(context.Context) (Factor, bool) {
+		total, failed := window.Counts()
+		factor := Factor{Name: name, Kind: KindErrorRate, Score: 1}
+		if total == 0 {
+			factor.Detail = "no recent requests"
+			return factor, true
+		}
+
+		factor.Value = float64(failed) / float64(total)
+		factor.Score = clamp(1 - factor.Value/limit)
+		factor.Detail = fmt.Sprintf("%d of %d requests failed in the last %s", failed, total, window.window)
+		return factor, true
+	}
+}
+
+// QueueBacklog scores the length of a queue, falling linearly from 1 when
+// empty to 0 at limit
+func QueueBacklog(name string, depth func() int, limit int) Check {
+	return func(context.Context) (Factor, bool) {
+		n := depth()
+		return Factor{
+			Name:   name,
+			Kind:   KindQueueBacklog,
+			Score:  clamp(1 - float64(n)/float64(limit)),
+			Value:  float64(n),
+			Detail: fmt.Sprintf("%d queued, limit %d", n, limit),
+		}, true
+	}
+}
+
+// CacheHitRate scores the hit rate of a cache between consecutive
+// evaluations, read from the hits and misses counters in registry. The
+// score is the ratio of the hit rate to target. The factor does not apply
+// while the counters are not registered, i.e. the cache is not configured.
+func CacheHitRate(name string, registry *metrics.Registry, hitsMetric, missesMetric string, target float64) Check {
+	var mu sync.Mutex
+	var lastHits, lastMisses float64
+
+	return func(context.Context) (Factor, bool) {
+		hits, ok := registry.Value(hitsMetric, nil)
+		if !ok {
+			return Factor{}, false
+		}
+		misses, _ := registry.Value(missesMetric, nil)
+
+		mu.Lock()
+		recentHits, recentMisses := hits-lastHits, misses-lastMisses
+		lastHits, lastMisses = hits, misses
+		mu.Unlock()
+
+		factor := Factor{Name: name, Kind: KindCacheHitRate, Score: 1}
+		lookups := recentHits + recentMisses
+		if lookups <= 0 {
+			factor.Detail = "no lookups since the last evaluation"
+			return factor, true
+		}
+
+		factor.Value = recentHits / lookups
+		factor.Score = clamp(factor.Value / target)
+		factor.Detail = fmt.Sprintf("%.0f of %.0f lookups hit since the last evaluation", recentHits, lookups)
+		return factor, true
+	}
+}
+
+// Latency scores the round trip of ping: 1 up to target, falling linearly
+// to 0 at four times target, and 0 when ping fails or times out. Pings
+// returning domain.ErrNotSupported make the factor not apply.
+func Latency(name string, ping func(ctx context.Context) error, target time.Duration) Check {
+	return func(ctx context.Context) (Factor, bool) {
+		ctx, cancel := context.WithTimeout(ctx, 4*target)
+		defer cancel()
+
+		start := time.Now()
+		err := ping(ctx)
+		elapsed := time.Since(start)
+		if errors.Is(err, domain.ErrNotSupported) {
+			return Factor{}, false
+		}
+
+		factor := Factor{Name: name, Kind: KindLatency, Value: float64(elapsed.Milliseconds())}
+		if err != nil {
+			factor.Detail = err.Error()
+			return factor, true
+		}
+
+		factor.Score = clamp(1 - float64(elapsed-target)/float64(3*target))
+		factor.Detail = fmt.Sprintf("round trip %s, target %s", elapsed.Round(time.Millisecond), target)
+		return factor, true
+	}
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/pkg/clock"
+)
+
+// Status buckets a health score for dashboards and alerting
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// Scores at or above these thresholds are healthy or degraded respectively
+const (
+	healthyThreshold  = 80
+	degradedThreshold = 50
+)
+
+// Factor is one signal contributing to the health score. Score runs from
+// 0 (failing) to 1 (fine); Value is the raw measurement it was derived from.
+type Factor struct {
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Value  float64 `json:"value"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// Factor kinds
+const (
+	KindErrorRate    = "error_rate"
+	KindQueueBacklog = "queue_backlog"
+	KindCacheHitRate = "cache_hit_rate"
+	KindLatency      = "dependency_latency"
+)
+
+// Check measures one factor. It reports false when the factor does not
+// apply, such as a cache hit rate when no cache is configured.
+type Check func(ctx context.Context) (Factor, bool)
+
+// Report is the outcome of scoring: a weighted average of the factors,
+// from 0 to 100, with the factors sorted worst first
+type Report struct {
+	Score       int       `json:"score"`
+	Status      Status    `json:"status"`
+	Factors     []Factor  `json:"factors"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+type weightedCheck struct {
+	weight float64
+	check  Check
+}
+
+// Scorer aggregates checks into a single soft health score. Unlike the
+// liveness check, a low score does not mean the service is down, only
+// that something deserves a look.
+type Scorer struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	checks []weightedCheck
+}
+
+// NewScorer creates a scorer without checks; a nil clock uses the system clock
+func NewScorer(c clock.Clock) *Scorer {
+	return &Scorer{clock: clock.OrSystem(c)}
+}
+
+// Add registers check with the given weight relative to the other checks
+func (s *Scorer) Add(weight float64, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, weightedCheck{weight: weight, check: check})
+}
+
+// Score runs every check and combines the applicable factors. With no
+// applicable factors the score is 100.
+func (s *Scorer) Score(ctx context.Context) Report {
+	s.mu.Lock()
+	checks := append([]weightedCheck(nil), s.checks...)
+	s.mu.Unlock()
+
+	report := Report{Factors: []Factor{}, EvaluatedAt: s.clock.Now()}
+	var total, weights float64
+	for _, wc := range checks {
+		factor, ok := wc.check(ctx)
+		if !ok || wc.weight <= 0 {
+			continue
+		}
+		factor.Score = clamp(factor.Score)
+		factor.Weight = wc.weight
+		report.Factors = append(report.Factors, factor)
+		total += factor.Score * wc.weight
+		weights += wc.weight
+	}
+
+	report.Score = 100
+	if weights > 0 {
+		report.Score = int(math.Round(100 * total / weights))
+	}
+	switch {
+	case report.Score >= healthyThreshold:
+		report.Status = StatusHealthy
+	case report.Score >= degradedThreshold:
+		report.Status = StatusDegraded
+	default:
+		report.Status = StatusUnhealthy
+	}
+
+	sort.SliceStable(report.Factors, func(i, j int) bool {
+		return report.Factors[i].Score < report.Factors[j].Score
+	})
+	return report
+}
+
+func clamp(score float64) float64 {
+	return math.Max(0, math.Min(1, score))
+}
@@ -0,0 +1,182 @@
+// Package kpi keeps business-level figures, such as how many favorites
+// exist and how many users touched their favorites today, up to date as
+// favorites change, so exporting them costs no storage queries
+package kpi
+
+import (
+	"sync"
+	"time"
+
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/metrics"
+)
+
+// Snapshot is the current value of every KPI
+type Snapshot struct {
+	// Favorites is the number of favorites in storage, as of the last
+	// resync plus the changes seen since
+	Favorites int
+	// ActiveUsers counts the distinct users who added, removed or edited
+	// a favorite since midnight UTC
+	ActiveUsers int
+	// AddedToday counts the favorites added since midnight UTC
+	AddedToday int
+}
+
+// Tracker updates the KPIs from favorites events. A nil Tracker ignores
+// events, so callers need not check whether tracking is enabled.
+type Tracker struct {
+	clock clock.Clock
+
+	mu         sync.Mutex
+	favorites  int
+	known      bool
+	day        time.Time
+	active     map[string]struct{}
+	addedToday int
+}
+
+// NewTracker creates a tracker; a nil clock uses the system clock
+func NewTracker(c clock.Clock) *Tracker {
+	t := &Tracker{clock: clock.OrSystem(c)}
+	t.day = t.today()
+	t.active = make(map[string]struct{})
+	return t
+}
+
+func (t *Tracker) today() time.Time {
+	return t.clock.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// rollLocked starts a new day's figures once midnight UTC has passed
+func (t *Tracker) rollLocked() {
+	if today := t.today(); today.After(t.day) {
+		t.day = today
+		t.active = make(map[string]struct{})
+		t.addedToday = 0
+	}
+}
+
+// FavoriteAdded records that userID added a favorite
+func (t *Tracker) FavoriteAdded(userID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollLocked()
+	t.favorites++
+	t.addedToday++
+	t.active[userID] = struct{}{}
+}
+
+// FavoriteRemoved records that userID removed a favorite
+func (t *Tracker) FavoriteRemoved(userID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollLocked()
+	if t.favorites > 0 {
+		t.favorites--
+	}
+	t.active[userID] = struct{}{}
+}
+
+// FavoriteEdited records that userID changed one of their favorites
+func (t *Tracker) FavoriteEdited(userID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollLocked()
+	t.active[userID] = struct{}{}
+}
+
+// Resync replaces the running favorites total with a count taken from
+// storage. This corrects for changes the tracker does not see, such as
+// favorites removed along with their asset or user, and for writes made
+// by other instances.
+func (t *Tracker) Resync(favorites int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.favorites = favorites
+	t.known = true
+}
+
+// Snapshot returns the current figures
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollLocked()
+	return Snapshot{
+		Favorites:   t.favorites,
+		ActiveUsers: len(t.active),
+		AddedToday:  t.addedToday,
+	}
+}
+
+// Register exports the KPIs as gauges in registry. The favorites total is
+// only exported when Resync was called beforehand, since the tracker cannot
+// know it otherwise.
+func (t *Tracker) Register(registry *metrics.Registry) {
+	t.mu.Lock()
+	known := t.known
+	t.mu.Unlock()
+
+	if known {
+		registry.GaugeFunc("business_favorites", "Favorites in storage", nil, func() float64 {
+			return float64(t.Snapshot().Favorites)
+		})
+	}
+	registry.GaugeFunc("business_daily_active_favorite_users", "Distinct users who changed their favorites since midnight UTC", nil, func() float64 {
+		return float64(t.Snapshot().ActiveUsers)
+	})
+	registry.GaugeFunc("business_favorites_added_today", "Favorites added since midnight UTC", nil, func() float64 {
+		return float64(t.Snapshot().AddedToday)
+	})
+}
+
+// StartResync calls Resync with the result of count every interval until
+// the returned stop function is called. Failed counts are passed to onError
+// and leave the running total as it is.
+func (t *Tracker) StartResync(interval time.Duration, count func() (int, error), onError func(error)) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				favorites, err := count()
+				if err != nil {
+					onError(err)
+					continue
+				}
+				t.Resync(favorites)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,336 @@
+// Package migration copies the contents of one storage backend into another
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/canonicaljson"
+)
+
+// Phases of a migration, in order
+const (
+	PhaseAssets = "assets"
+	PhaseUsers  = "users"
+	PhaseDone   = "done"
+)
+
+// Options controls a migration run
+type Options struct {
+	// BatchSize is the number of assets or users read per page
+	BatchSize int
+	// CheckpointPath, when set, records progress after every batch so an
+	// interrupted run resumes where it stopped
+	CheckpointPath string
+	// OnProgress is called after every batch
+	OnProgress func(Progress)
+}
+
+// Progress is the state of a run. It is also the checkpoint format.
+type Progress struct {
+	Phase string `json:"phase"`
+	// AssetOffset is the number of source assets already copied
+	AssetOffset int `json:"asset_offset"`
+	// LastUserID is the last user whose favorites were copied
+	LastUserID string `json:"last_user_id"`
+
+	Assets    int `json:"assets"`
+	Users     int `json:"users"`
+	Favorites int `json:"favorites"`
+	// Skipped counts entries already present in the target
+	Skipped int `json:"skipped"`
+	// Mismatched lists users whose favorites differ in the target after copying
+	Mismatched []string `json:"mismatched,omitempty"`
+
+	// SourceChecksum and TargetChecksum combine the checksums of every
+	// copied user's favorites; they are equal when the copy is faithful
+	SourceChecksum string `json:"source_checksum"`
+	TargetChecksum string `json:"target_checksum"`
+}
+
+// clone copies progress so a batch is only counted once it completes. A
+// resumed run repeats the interrupted batch.
+func (p *Progress) clone() Progress {
+	clone := *p
+	clone.Mismatched = append([]string(nil), p.Mismatched...)
+	return clone
+}
+
+// Migrator copies assets, users and favorites from source to target.
+// Writes are idempotent: entries already in the target are skipped.
+type Migrator struct {
+	source repository.FavoritesRepository
+	users  repository.UserLister
+	target repository.FavoritesRepository
+	opts   Options
+}
+
+// New creates a migrator. The source must be able to list its users.
+func New(source, target repository.FavoritesRepository, opts Options) (*Migrator, error) {
+	users, ok := source.(repository.UserLister)
+	if !ok {
+		return nil, fmt.Errorf("source backend cannot list users: %w", domain.ErrNotSupported)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &Migrator{source: source, users: users, target: target, opts: opts}, nil
+}
+
+// Run copies everything not yet copied according to the checkpoint. The
+// returned progress lists users whose copies failed verification.
+func (m *Migrator) Run() (*Progress, error) {
+	progress, err := LoadCheckpoint(m.opts.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress.Phase == PhaseAssets {
+		if err := m.copyAssets(progress); err != nil {
+			return progress, err
+		}
+		progress.Phase = PhaseUsers
+		if err := m.checkpoint(progress); err != nil {
+			return progress, err
+		}
+	}
+
+	if progress.Phase == PhaseUsers {
+		if err := m.eachUser(progress, m.copyUser); err != nil {
+			return progress, err
+		}
+		progress.Phase = PhaseDone
+		if err := m.checkpoint(progress); err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, nil
+}
+
+// copyAssets copies the asset catalog, including assets nobody favorited
+func (m *Migrator) copyAssets(progress *Progress) error {
+	for {
+		assets, err := m.source.ListAssets(m.opts.BatchSize, progress.AssetOffset)
+		if err != nil {
+			return fmt.Errorf("listing assets at offset %d: %w", progress.AssetOffset, err)
+		}
+		if len(assets) == 0 {
+			return nil
+		}
+
+		batch := progress.clone()
+		for _, asset := range assets {
+			err := m.target.CreateAsset(asset)
+			switch {
+			case err == nil:
+				batch.Assets++
+			case errors.Is(err, domain.ErrAssetAlreadyExists):
+				batch.Skipped++
+			default:
+				return fmt.Errorf("copying asset %s: %w", asset.GetID(), err)
+			}
+		}
+
+		batch.AssetOffset += len(assets)
+		*progress = batch
+		if err := m.checkpoint(progress); err != nil {
+			return err
+		}
+	}
+}
+
+// Warm reads every user's favorites through the target, which must be a
+// read-through cache over the source, so the cache is populated before
+// traffic arrives. query should match the listing clients request most.
+func (m *Migrator) Warm(query domain.FavoritesQuery) (*Progress, error) {
+	progress, err := LoadCheckpoint(m.opts.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Phase == PhaseAssets {
+		progress.Phase = PhaseUsers
+	}
+
+	if progress.Phase == PhaseUsers {
+		err := m.eachUser(progress, func(userID string, batch *Progress) error {
+			favorites, err := m.target.GetUserFavorites(userID, query)
+			if err != nil {
+				return err
+			}
+			batch.Users++
+			batch.Favorites += len(favorites)
+			return nil
+		})
+		if err != nil {
+			return progress, err
+		}
+		progress.Phase = PhaseDone
+		if err := m.checkpoint(progress); err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, nil
+}
+
+// eachUser calls fn for every source user in ID order, after the last
+// checkpointed user
+func (m *Migrator) eachUser(progress *Progress, fn func(userID string, batch *Progress) error) error {
+	for {
+		ids, err := m.users.ListUserIDs(progress.LastUserID, m.opts.BatchSize)
+		if err != nil {
+			return fmt.Errorf("listing users after %q: %w", progress.LastUserID, err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		batch := progress.clone()
+		for _, userID := range ids {
+			if err := fn(userID, &batch); err != nil {
+				return fmt.Errorf("user %s: %w", userID, err)
+			}
+			batch.LastUserID = userID
+		}
+
+		*progress = batch
+		if err := m.checkpoint(progress); err != nil {
+			return err
+		}
+	}
+}
+
+// copyUser copies a user and their favorites, then verifies the copy
+func (m *Migrator) copyUser(userID string, progress *Progress) error {
+	user, err := m.source.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := m.target.CreateUser(user); err != nil {
+		return err
+	}
+	progress.Users++
+
+	// Favorites are added oldest first so listings keep their order
+	favorites, err := m.source.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return err
+	}
+	for _, favorite := range favorites {
+		err := m.target.AddFavorite(userID, favorite.Asset)
+		switch {
+		case err == nil:
+			progress.Favorites++
+		case errors.Is(err, domain.ErrFavoriteAlreadyExists):
+			progress.Skipped++
+		default:
+			return fmt.Errorf("adding favorite %s: %w", favorite.AssetID, err)
+		}
+		// Notes are copied even onto skipped favorites, so a resumed run
+		// converges on the source
+		if favorite.Note != "" {
+			if _, err := m.target.UpdateFavoriteNote(userID, favorite.AssetID, favorite.Note); err != nil {
+				return fmt.Errorf("copying note of favorite %s: %w", favorite.AssetID, err)
+			}
+		}
+	}
+
+	copied, err := m.target.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return err
+	}
+	sourceSum, err := Checksum(favorites)
+	if err != nil {
+		return err
+	}
+	targetSum, err := Checksum(copied)
+	if err != nil {
+		return err
+	}
+	if sourceSum != targetSum {
+		progress.Mismatched = append(progress.Mismatched, userID)
+	}
+
+	progress.SourceChecksum = combine(progress.SourceChecksum, userID, sourceSum)
+	progress.TargetChecksum = combine(progress.TargetChecksum, userID, targetSum)
+	return nil
+}
+
+func (m *Migrator) checkpoint(progress *Progress) error {
+	if m.opts.OnProgress != nil {
+		m.opts.OnProgress(*progress)
+	}
+	if m.opts.CheckpointPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash never leaves a torn checkpoint
+	tmp := m.opts.CheckpointPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return os.Rename(tmp, m.opts.CheckpointPath)
+}
+
+// LoadCheckpoint reads saved progress. A missing file, or an empty path,
+// starts a new run.
+func LoadCheckpoint(path string) (*Progress, error) {
+	progress := &Progress{Phase: PhaseAssets}
+	if path == "" {
+		return progress, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	return progress, nil
+}
+
+// checksumEntry is the part of a favorite that must survive a copy.
+// Timestamps are excluded since the target records its own.
+type checksumEntry struct {
+	AssetID string       `json:"asset_id"`
+	Asset   domain.Asset `json:"asset"`
+	Note    string       `json:"note,omitempty"`
+}
+
+// Checksum hashes favorites independently of their order and timestamps
+func Checksum(favorites []*domain.UserFavorite) (string, error) {
+	entries := make([]checksumEntry, len(favorites))
+	for i, favorite := range favorites {
+		entries[i] = checksumEntry{AssetID: favorite.AssetID, Asset: favorite.Asset, Note: favorite.Note}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AssetID < entries[j].AssetID })
+	return canonicaljson.Hash(entries)
+}
+
+// combine folds a user's checksum into a running total by XOR, so the total
+// does not depend on the order users were copied in or on resuming
+func combine(total, userID, checksum string) string {
+	sum := sha256.Sum256([]byte(userID + "\x00" + checksum))
+	if previous, err := hex.DecodeString(total); err == nil && len(previous) == len(sum) {
+		for i := range sum {
+			sum[i] ^= previous[i]
+		}
+	}
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package moderation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Reason classifies why an asset was reported
+type Reason string
+
+const (
+	ReasonSpam          Reason = "spam"
+	ReasonAbuse         Reason = "abuse"
+	ReasonInappropriate Reason = "inappropriate"
+	ReasonCopyright     Reason = "copyright"
+	ReasonOther         Reason = "other"
+)
+
+// IsValid reports whether the reason is one of the supported reasons
+func (r Reason) IsValid() bool {
+	switch r {
+	case ReasonSpam, ReasonAbuse, ReasonInappropriate, ReasonCopyright, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// Report is a single complaint about an asset
+type Report struct {
+	AssetID    string    `json:"asset_id"`
+	ReporterID string    `json:"reporter_id"`
+	Reason     Reason    `json:"reason"`
+	Details    string    `json:"details,omitempty"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// QueueEntry summarises the open reports against one asset
+type QueueEntry struct {
+	AssetID      string         `json:"asset_id"`
+	Reports      []Report       `json:"reports"`
+	Reasons      map[Reason]int `json:"reasons"`
+	Hidden       bool           `json:"hidden"`
+	LastReported time.Time      `json:"last_reported"`
+}
+
+type assetState struct {
+	reports []Report
+	hidden  bool
+}
+
+// Store keeps reports and hidden assets in memory
+type Store struct {
+	// threshold is the number of open reports that hides an asset; 0 disables auto-hiding
+	threshold int
+
+	mu     sync.RWMutex
+	assets map[string]*assetState
+}
+
+// NewStore creates a store that hides assets once they collect threshold open reports
+func NewStore(threshold int) *Store {
+	return &Store{
+		threshold: threshold,
+		assets:    make(map[string]*assetState),
+	}
+}
+
+// Add records a report and returns whether the asset is now hidden.
+// Reports must name their reporter, who may only have one open report per
+// asset.
+func (s *Store) Add(report Report) (bool, error) {
+	if report.ReporterID == "" {
+		return false, domain.WithContext(domain.ErrInvalidInput, "field", "reporter_id")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.assets[report.AssetID]
+	if !ok {
+		state = &assetState{}
+		s.assets[report.AssetID] = state
+	}
+
+	for _, existing := range state.reports {
+		if existing.ReporterID == report.ReporterID {
+			return state.hidden, domain.ErrAlreadyReported
+		}
+	}
+
+	state.reports = append(state.reports, report)
+	if s.threshold > 0 && len(state.reports) >= s.threshold {
+		state.hidden = true
+	}
+
+	return state.hidden, nil
+}
+
+// Queue lists assets with open reports, most reported first
+func (s *Store) Queue() []QueueEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	queue := make([]QueueEntry, 0, len(s.assets))
+	for assetID, state := range s.assets {
+		if len(state.reports) == 0 {
+			continue
+		}
+
+		entry := QueueEntry{
+			AssetID: assetID,
+			Reports: append([]Report(nil), state.reports...),
+			Reasons: make(map[Reason]int),
+			Hidden:  state.hidden,
+		}
+		for _, report := range state.reports {
+			entry.Reasons[report.Reason]++
+			if report.ReportedAt.After(entry.LastReported) {
+				entry.LastReported = report.ReportedAt
+			}
+		}
+		queue = append(queue, entry)
+	}
+
+	sort.Slice(queue, func(i, j int) bool {
+		if len(queue[i].Reports) != len(queue[j].Reports) {
+			return len(queue[i].Reports) > len(queue[j].Reports)
+		}
+		return queue[i].AssetID < queue[j].AssetID
+	})
+
+	return queue
+}
+
+// Resolve closes the open reports against an asset and sets whether it
+// stays hidden. Assets without reports can still be hidden directly.
+func (s *Store) Resolve(assetID string, hidden bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.assets[assetID]
+	if !ok {
+		if !hidden {
+			return domain.ErrReportNotFound
+		}
+		state = &assetState{}
+		s.assets[assetID] = state
+	}
+
+	state.reports = nil
+	state.hidden = hidden
+	if !hidden {
+		delete(s.assets, assetID)
+	}
+
+	return nil
+}
+
+// IsHidden reports whether an asset has been hidden by moderation
+func (s *Store) IsHidden(assetID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.assets[assetID]
+	return ok && state.hidden
+}
@@ -0,0 +1,76 @@
+// Package notification keeps per-user inboxes of service notices, such as
+// a favorited asset being withdrawn from the catalog
+package notification
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kind classifies a notification
+type Kind string
+
+const (
+	// KindAssetUnavailable tells a user that a favorited asset was deleted
+	// and will leave their favorites once its grace period ends
+	KindAssetUnavailable Kind = "asset_unavailable"
+)
+
+// Notification is a single notice for a user
+type Notification struct {
+	ID        string     `json:"id"`
+	Kind      Kind       `json:"kind"`
+	AssetID   string     `json:"asset_id,omitempty"`
+	Message   string     `json:"message"`
+	RemovesAt *time.Time `json:"removes_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Store keeps notifications in memory, dropping a user's oldest once they
+// have more than maxPerUser
+type Store struct {
+	maxPerUser int
+
+	mu      sync.RWMutex
+	seq     uint64
+	inboxes map[string][]Notification
+}
+
+// NewStore creates a store keeping up to maxPerUser notifications per user;
+// 0 keeps them all
+func NewStore(maxPerUser int) *Store {
+	return &Store{
+		maxPerUser: maxPerUser,
+		inboxes:    make(map[string][]Notification),
+	}
+}
+
+// Add delivers n to a user and returns it with its ID assigned
+func (s *Store) Add(userID string, n Notification) Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	n.ID = strconv.FormatUint(s.seq, 10)
+
+	inbox := append(s.inboxes[userID], n)
+	if s.maxPerUser > 0 && len(inbox) > s.maxPerUser {
+		inbox = append([]Notification(nil), inbox[len(inbox)-s.maxPerUser:]...)
+	}
+	s.inboxes[userID] = inbox
+	return n
+}
+
+// List returns a user's notifications, newest first
+func (s *Store) List(userID string) []Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inbox := s.inboxes[userID]
+	list := make([]Notification, 0, len(inbox))
+	for i := len(inbox) - 1; i >= 0; i-- {
+		list = append(list, inbox[i])
+	}
+	return list
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import "sync"
+
+// ConcurrencyLimiter caps the requests in flight per key, so a single
+// client cannot hold every worker with parallel slow requests
+type ConcurrencyLimiter struct {
+	max int
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing max requests in flight
+// per key
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{max: max, inFlight: make(map[string]int)}
+}
+
+// Max returns the per key limit
+func (l *ConcurrencyLimiter) Max() int {
+	return l.max
+}
+
+// Acquire takes a slot for key. When ok, release must be called once the
+// request completes.
+func (l *ConcurrencyLimiter) Acquire(key string) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= l.max {
+		return nil, false
+	}
+	l.inFlight[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			// Idle keys are dropped so the map only holds active callers
+			if l.inFlight[key]--; l.inFlight[key] <= 0 {
+				delete(l.inFlight, key)
+			}
+		})
+	}, true
+}
+
+// InFlight returns the requests in flight for key
+func (l *ConcurrencyLimiter) InFlight(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight[key]
+}
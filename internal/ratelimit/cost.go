@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CostWeights prices requests in cost units, so that queries that are
+// expensive for the database draw down a caller's budget faster
+type CostWeights struct {
+	// Listing is the base cost of any favorites listing
+	Listing int `json:"listing"`
+	// DeepOffset is added per DeepOffsetStep rows skipped
+	DeepOffset int `json:"deep_offset"`
+	// Search is added when the listing filters by search text
+	Search int `json:"search"`
+	// BroadSearch is added on top of Search when a search term is shorter
+	// than BroadSearchLength, since it matches most of the table
+	BroadSearch int `json:"broad_search"`
+	// LargePage is added when a page holds more than LargePageSize rows
+	LargePage int `json:"large_page"`
+	// Export is the cost of a full data export, such as a backup
+	Export int `json:"export"`
+}
+
+const (
+	// DeepOffsetStep is the number of skipped rows priced at one DeepOffset
+	DeepOffsetStep = 1000
+	// BroadSearchLength is the term length below which a search is broad
+	BroadSearchLength = 3
+	// LargePageSize is the page size above which LargePage applies
+	LargePageSize = 50
+)
+
+// DefaultCostWeights are used for weights not set explicitly
+var DefaultCostWeights = CostWeights{
+	Listing:     1,
+	DeepOffset:  1,
+	Search:      1,
+	BroadSearch: 3,
+	LargePage:   1,
+	Export:      50,
+}
+
+// ParseCostWeights parses weight overrides of the form name -> cost, e.g.
+// {"broad_search": "5", "export": "100"}. Weights left out keep their
+// defaults.
+func ParseCostWeights(specs map[string]string) (CostWeights, error) {
+	weights := DefaultCostWeights
+	fields := map[string]*int{
+		"listing":      &weights.Listing,
+		"deep_offset":  &weights.DeepOffset,
+		"search":       &weights.Search,
+		"broad_search": &weights.BroadSearch,
+		"large_page":   &weights.LargePage,
+		"export":       &weights.Export,
+	}
+
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			return weights, fmt.Errorf("unknown query cost weight %q", name)
+		}
+		cost, err := strconv.Atoi(strings.TrimSpace(specs[name]))
+		if err != nil || cost < 0 {
+			return weights, fmt.Errorf("query cost weight %q: invalid cost %q", name, specs[name])
+		}
+		*field = cost
+	}
+	return weights, nil
+}
+
+// ListingCost prices a favorites listing from its paging and search terms
+func (w CostWeights) ListingCost(limit, offset int, search string) int {
+	cost := w.Listing
+	cost += offset / DeepOffsetStep * w.DeepOffset
+	if limit > LargePageSize {
+		cost += w.LargePage
+	}
+
+	terms := strings.Fields(search)
+	if len(terms) > 0 {
+		cost += w.Search
+		for _, term := range terms {
+			if len([]rune(term)) < BroadSearchLength {
+				cost += w.BroadSearch
+				break
+			}
+		}
+	}
+	return cost
+}
+
+// CostBudget gives each key a budget of cost units per minute, refilled
+// continuously, so a caller may spend the whole budget in a burst but no
+// more than it over time
+type CostBudget struct {
+	perMinute int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewCostBudget creates a budget of perMinute cost units for every key
+func NewCostBudget(perMinute int) (*CostBudget, error) {
+	if perMinute <= 0 {
+		return nil, fmt.Errorf("query cost budget must be positive, got %d", perMinute)
+	}
+	return &CostBudget{
+		perMinute: perMinute,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+	}, nil
+}
+
+// PerMinute returns the budget of each key
+func (b *CostBudget) PerMinute() int {
+	return b.perMinute
+}
+
+// Spend takes cost units from key's budget. A refused request spends
+// nothing, and its RetryAfter is when the budget will cover it. A cost
+// above the whole budget is charged as the whole budget, so it waits for a
+// full refill rather than being refused forever.
+func (b *CostBudget) Spend(key string, cost int) Decision {
+	if cost > b.perMinute {
+		cost = b.perMinute
+	}
+	rate := float64(b.perMinute) / time.Minute.Seconds()
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk, ok := b.buckets[key]
+	if !ok {
+		if len(b.buckets) >= maxTrackedKeys {
+			b.sweepLocked(now, rate)
+		}
+		bk = &bucket{tokens: float64(b.perMinute), updated: now}
+		b.buckets[key] = bk
+	}
+
+	bk.tokens = math.Min(float64(b.perMinute), bk.tokens+now.Sub(bk.updated).Seconds()*rate)
+	bk.updated = now
+
+	decision := Decision{Limit: b.perMinute}
+	if bk.tokens >= float64(cost) {
+		bk.tokens -= float64(cost)
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((float64(cost) - bk.tokens) / rate * float64(time.Second))
+	}
+	decision.Remaining = int(bk.tokens)
+
+	return decision
+}
+
+// Peek reports key's budget without spending from it. Allowed reports
+// whether any of the budget is left.
+func (b *CostBudget) Peek(key string) Decision {
+	rate := float64(b.perMinute) / time.Minute.Seconds()
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens := float64(b.perMinute)
+	if bk, ok := b.buckets[key]; ok {
+		tokens = math.Min(tokens, bk.tokens+now.Sub(bk.updated).Seconds()*rate)
+	}
+
+	decision := Decision{Limit: b.perMinute, Allowed: tokens >= 1, Remaining: int(tokens)}
+	if !decision.Allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return decision
+}
+
+// sweepLocked drops budgets that have refilled, since a new budget starts full
+func (b *CostBudget) sweepLocked(now time.Time, rate float64) {
+	for key, bk := range b.buckets {
+		if bk.tokens+now.Sub(bk.updated).Seconds()*rate >= float64(b.perMinute) {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"sync"
+
+	"gwi-favorites-service/pkg/clock"
+)
+
+// historyMinutes is how far back History counts, in one-minute buckets
+const historyMinutes = 60
+
+// RequestCounts are a caller's recent requests, counted in whole clock
+// minutes: the last hour is the current minute and the 59 before it
+type RequestCounts struct {
+	CurrentMinute int `json:"current_minute"`
+	LastHour      int `json:"last_hour"`
+	// ThrottledLastHour counts the requests refused with 429 Too Many
+	// Requests in the last hour
+	ThrottledLastHour int `json:"throttled_last_hour"`
+}
+
+type minuteCount struct {
+	minute    int64
+	requests  int
+	throttled int
+}
+
+// History counts each key's requests over the last hour, so callers can
+// see how close they run to their limits
+type History struct {
+	clock clock.Clock
+
+	mu   sync.Mutex
+	keys map[string]*[historyMinutes]minuteCount
+}
+
+// NewHistory creates an empty history. A nil clock uses the system clock.
+func NewHistory(c clock.Clock) *History {
+	return &History{
+		clock: clock.OrSystem(c),
+		keys:  make(map[string]*[historyMinutes]minuteCount),
+	}
+}
+
+// Record counts a request by key, and whether it was throttled
+func (h *History) Record(key string, throttled bool) {
+	minute := h.clock.Now().Unix() / 60
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counts, ok := h.keys[key]
+	if !ok {
+		if len(h.keys) >= maxTrackedKeys {
+			h.sweepLocked(minute)
+		}
+		counts = new([historyMinutes]minuteCount)
+		h.keys[key] = counts
+	}
+
+	slot := &counts[minute%historyMinutes]
+	if slot.minute != minute {
+		*slot = minuteCount{minute: minute}
+	}
+	slot.requests++
+	if throttled {
+		slot.throttled++
+	}
+}
+
+// Counts returns key's recent requests
+func (h *History) Counts(key string) RequestCounts {
+	minute := h.clock.Now().Unix() / 60
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var counts RequestCounts
+	slots, ok := h.keys[key]
+	if !ok {
+		return counts
+	}
+	for _, slot := range slots {
+		if slot.minute <= minute-historyMinutes || slot.minute > minute {
+			continue
+		}
+		counts.LastHour += slot.requests
+		counts.ThrottledLastHour += slot.throttled
+		if slot.minute == minute {
+			counts.CurrentMinute = slot.requests
+		}
+	}
+	return counts
+}
+
+// sweepLocked drops keys without requests in the last hour
+func (h *History) sweepLocked(minute int64) {
+	for key, slots := range h.keys {
+		idle := true
+		for _, slot := range slots {
+			if slot.minute > minute-historyMinutes {
+				idle = false
+				break
+			}
+		}
+		if idle {
+			delete(h.keys, key)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tier is a named request budget: Rate requests per second on average,
+// with bursts of up to Burst requests
+type Tier struct {
+	Name  string  `json:"name"`
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// ParseTiers parses tier definitions of the form name -> "rate:burst",
+// e.g. {"free": "5:10", "partner": "50:100"}. The burst defaults to the
+// rate rounded up.
+func ParseTiers(specs map[string]string) ([]Tier, error) {
+	tiers := make([]Tier, 0, len(specs))
+	for name, spec := range specs {
+		rateSpec, burstSpec, hasBurst := strings.Cut(spec, ":")
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateSpec), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("tier %q: invalid rate %q", name, rateSpec)
+		}
+
+		burst := int(math.Ceil(rate))
+		if hasBurst {
+			burst, err = strconv.Atoi(strings.TrimSpace(burstSpec))
+			if err != nil || burst <= 0 {
+				return nil, fmt.Errorf("tier %q: invalid burst %q", name, burstSpec)
+			}
+		}
+
+		tiers = append(tiers, Tier{Name: name, Rate: rate, Burst: burst})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Name < tiers[j].Name })
+	return tiers, nil
+}
+
+// Decision is the outcome of a rate limit check
+type Decision struct {
+	Tier      string
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed
+	RetryAfter time.Duration
+}
+
+// maxTrackedKeys bounds memory before full buckets are swept
+const maxTrackedKeys = 10000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter applies a token bucket per key, sized by the key's tier
+type Limiter struct {
+	tiers       map[string]Tier
+	defaultTier string
+	now         func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewLimiter creates a limiter. Roles without a tier of their own use
+// defaultTier, which must be one of tiers.
+func NewLimiter(tiers []Tier, defaultTier string) (*Limiter, error) {
+	l := &Limiter{
+		tiers:       make(map[string]Tier, len(tiers)),
+		defaultTier: defaultTier,
+		now:         time.Now,
+		buckets:     make(map[string]*bucket),
+	}
+	for _, tier := range tiers {
+		l.tiers[tier.Name] = tier
+	}
+	if _, ok := l.tiers[defaultTier]; !ok {
+		return nil, fmt.Errorf("default rate limit tier %q is not defined", defaultTier)
+	}
+	return l, nil
+}
+
+// Tier resolves the tier for a role
+func (l *Limiter) Tier(role string) Tier {
+	if tier, ok := l.tiers[role]; ok {
+		return tier
+	}
+	return l.tiers[l.defaultTier]
+}
+
+// Tiers returns the configured tiers ordered by name
+func (l *Limiter) Tiers() []Tier {
+	tiers := make([]Tier, 0, len(l.tiers))
+	for _, tier := range l.tiers {
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Name < tiers[j].Name })
+	return tiers
+}
+
+// Allow takes one token from key's bucket in the tier resolved from role
+func (l *Limiter) Allow(role, key string) Decision {
+	tier := l.Tier(role)
+	now := l.now()
+	// Buckets are per tier so a role change starts a fresh budget
+	bucketKey := tier.Name + "\x00" + key
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		if len(l.buckets) >= maxTrackedKeys {
+			l.sweepLocked(now)
+		}
+		b = &bucket{tokens: float64(tier.Burst), updated: now}
+		l.buckets[bucketKey] = b
+	}
+
+	b.tokens = math.Min(float64(tier.Burst), b.tokens+now.Sub(b.updated).Seconds()*tier.Rate)
+	b.updated = now
+
+	decision := Decision{Tier: tier.Name, Limit: tier.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - b.tokens) / tier.Rate * float64(time.Second))
+	}
+	decision.Remaining = int(b.tokens)
+
+	return decision
+}
+
+// Peek reports the state of key's bucket in the tier resolved from role
+// without taking a token: whether a request would be allowed now, and the
+// whole tokens left
+func (l *Limiter) Peek(role, key string) Decision {
+	tier := l.Tier(role)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := float64(tier.Burst)
+	if b, ok := l.buckets[tier.Name+"\x00"+key]; ok {
+		tokens = math.Min(tokens, b.tokens+now.Sub(b.updated).Seconds()*tier.Rate)
+	}
+
+	decision := Decision{Tier: tier.Name, Limit: tier.Burst, Allowed: tokens >= 1, Remaining: int(tokens)}
+	if !decision.Allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) / tier.Rate * float64(time.Second))
+	}
+	return decision
+}
+
+// sweepLocked drops buckets that have refilled, since a new bucket starts full
+func (l *Limiter) sweepLocked(now time.Time) {
+	for key, b := range l.buckets {
+		name, _, _ := strings.Cut(key, "\x00")
+		tier := l.tiers[name]
+		if b.tokens+now.Sub(b.updated).Seconds()*tier.Rate >= float64(tier.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
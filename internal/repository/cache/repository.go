@@ -0,0 +1,627 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL bounds how long a cached read can outlive a missed invalidation
+const DefaultTTL = 5 * time.Minute
+
+// Options configures the cache
+type Options struct {
+	// Redis connection settings, used by Open
+	Addr     string
+	Password string
+	DB       int
+	// TTL of cached entries; zero uses DefaultTTL
+	TTL time.Duration
+	// KeyPrefix namespaces all cache keys
+	KeyPrefix string
+	// OnError is called when Redis fails. Reads fall back to the wrapped
+	// repository, so errors are reported rather than returned.
+	OnError func(err error)
+}
+
+// Stats counts cache lookups
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// Repository is a FavoritesRepository that caches GetUserFavorites and
+// IsFavorite results of a persistent backend in Redis. Writes go to the
+// backend first and then invalidate the affected entries.
+//
+// Keys (all prefixed with Options.KeyPrefix):
+//
+//	gen:{userID}                         counter bumped to invalidate a user's entries
+//	favorites:{userID}:{gen}:{query}     string, page of favorites JSON
+//	is_favorite:{userID}:{gen}:{assetID} string, "1" or "0"
+//	readers:{assetID}                    set of user IDs with cached entries holding the asset
+//
+// Entries are keyed by the user's generation, which is read before the
+// backend is queried, so a read racing a write is stored under a
+// generation that is already stale and never served.
+type Repository struct {
+	repository.FavoritesRepository
+	client  *goredis.Client
+	prefix  string
+	ttl     time.Duration
+	onError func(err error)
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.StorageInspector    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.UserEraser          = (*Repository)(nil)
+	_ repository.Pinger              = (*Repository)(nil)
+	_ repository.FavoritesCounter    = (*Repository)(nil)
+	_ repository.FavoriteGetter      = (*Repository)(nil)
+	_ repository.FavoritesSummarizer = (*Repository)(nil)
+	_ repository.AssetSearcher       = (*Repository)(nil)
+	_ repository.CollectionStore     = (*Repository)(nil)
+	_ repository.FavoriteOrderStore  = (*Repository)(nil)
+	_ repository.FavoritesTransferer = (*Repository)(nil)
+	_ repository.IntegrityChecker    = (*Repository)(nil)
+)
+
+// NewRepository wraps backend with a cache stored through client
+func NewRepository(backend repository.FavoritesRepository, client *goredis.Client, opts Options) *Repository {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(error) {}
+	}
+	return &Repository{
+		FavoritesRepository: backend,
+		client:              client,
+		prefix:              opts.KeyPrefix,
+		ttl:                 opts.TTL,
+		onError:             opts.OnError,
+	}
+}
+
+// Open connects to Redis, verifies the connection and wraps backend
+func Open(backend repository.FavoritesRepository, opts Options) (*Repository, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return NewRepository(backend, client, opts), nil
+}
+
+// Close closes the Redis client; the wrapped backend is left open
+func (r *Repository) Close() error {
+	return r.client.Close()
+}
+
+// Ping checks the cache's Redis server and then the backend, when the
+// backend has a remote service of its own
+func (r *Repository) Ping(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if pinger, ok := r.FavoritesRepository.(repository.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Stats returns the hit and miss counts since startup
+func (r *Repository) Stats() Stats {
+	return Stats{Hits: r.hits.Load(), Misses: r.misses.Load()}
+}
+
+func (r *Repository) genKey(userID string) string { return r.prefix + "gen:" + userID }
+func (r *Repository) favoritesKey(userID string, gen int64, query domain.FavoritesQuery) string {
+	return fmt.Sprintf("%sfavorites:%s:%d:%s:%d:%d:%s:%t:%q:%d:%d", r.prefix, userID, gen, query.Type, query.Limit, query.Offset, query.Sort, query.Descending, query.Search,
+		unixNanos(query.AddedSince), unixNanos(query.AddedUntil))
+}
+func (r *Repository) isFavoriteKey(userID string, gen int64, assetID string) string {
+	return fmt.Sprintf("%sis_favorite:%s:%d:%s", r.prefix, userID, gen, assetID)
+}
+func (r *Repository) readersKey(assetID string) string { return r.prefix + "readers:" + assetID }
+
+// unixNanos keys a time range bound, with 0 for an open bound
+func unixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// Asset operations
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	if err := r.FavoritesRepository.UpdateAsset(asset); err != nil {
+		return err
+	}
+	r.invalidateAsset(asset.GetID())
+	return nil
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	if err := r.FavoritesRepository.DeleteAsset(assetID); err != nil {
+		return err
+	}
+	r.invalidateAsset(assetID)
+	return nil
+}
+
+// Favorites operations
+
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	if err := r.FavoritesRepository.AddFavorite(userID, asset); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	importer, ok := r.FavoritesRepository.(repository.FavoriteImporter)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	if err := importer.ImportFavorite(userID, asset, addedAt); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	if err := r.FavoritesRepository.RemoveFavorite(userID, assetID); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+// UpdateFavoriteAsset changes the shared asset, so every reader is invalidated
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	if err := r.FavoritesRepository.UpdateFavoriteAsset(userID, assetID, asset); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	r.invalidateAsset(assetID)
+	return nil
+}
+
+// UpdateFavoriteNote only changes the user's own favorite
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	previous, err := r.FavoritesRepository.UpdateFavoriteNote(userID, assetID, note)
+	if err != nil {
+		return "", err
+	}
+	r.invalidateUser(userID)
+	return previous, nil
+}
+
+// cachedFavorite is the stored form of a UserFavorite
+type cachedFavorite struct {
+	UserID    string          `json:"user_id"`
+	AssetID   string          `json:"asset_id"`
+	Asset     json.RawMessage `json:"asset"`
+	Note      string          `json:"note,omitempty"`
+	Pinned    bool            `json:"pinned,omitempty"`
+	Position  int             `json:"position,omitempty"`
+	AddedAt   time.Time       `json:"added_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	ctx := context.Background()
+
+	gen, ok := r.generation(ctx, userID)
+	if !ok {
+		return r.FavoritesRepository.GetUserFavorites(userID, query)
+	}
+	key := r.favoritesKey(userID, gen, query)
+
+	if data, err := r.client.Get(ctx, key).Bytes(); err == nil {
+		if favorites, err := decodeFavorites(data); err == nil {
+			r.hits.Add(1)
+			return favorites, nil
+		}
+	} else if err != goredis.Nil {
+		r.onError(err)
+	}
+	r.misses.Add(1)
+
+	favorites, err := r.FavoritesRepository.GetUserFavorites(userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encodeFavorites(favorites)
+	if err != nil {
+		r.onError(err)
+		return favorites, nil
+	}
+
+	assetIDs := make([]string, len(favorites))
+	for i, favorite := range favorites {
+		assetIDs[i] = favorite.AssetID
+	}
+	r.store(ctx, userID, assetIDs, key, data)
+
+	return favorites, nil
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	ctx := context.Background()
+
+	gen, ok := r.generation(ctx, userID)
+	if !ok {
+		return r.FavoritesRepository.IsFavorite(userID, assetID)
+	}
+	key := r.isFavoriteKey(userID, gen, assetID)
+
+	if value, err := r.client.Get(ctx, key).Result(); err == nil {
+		r.hits.Add(1)
+		return value == "1", nil
+	} else if err != goredis.Nil {
+		r.onError(err)
+	}
+	r.misses.Add(1)
+
+	isFavorite, err := r.FavoritesRepository.IsFavorite(userID, assetID)
+	if err != nil {
+		return false, err
+	}
+
+	// Only a positive answer can be invalidated by deleting the asset
+	var assetIDs []string
+	value := "0"
+	if isFavorite {
+		assetIDs, value = []string{assetID}, "1"
+	}
+	r.store(ctx, userID, assetIDs, key, value)
+
+	return isFavorite, nil
+}
+
+// Optional capabilities are forwarded to the backend
+
+func (r *Repository) StorageStats() (repository.StorageStats, error) {
+	inspector, ok := r.FavoritesRepository.(repository.StorageInspector)
+	if !ok {
+		return repository.StorageStats{}, domain.ErrNotSupported
+	}
+	return inspector.StorageStats()
+}
+
+func (r *Repository) Compact() (repository.CompactionResult, error) {
+	inspector, ok := r.FavoritesRepository.(repository.StorageInspector)
+	if !ok {
+		return repository.CompactionResult{}, domain.ErrNotSupported
+	}
+	return inspector.Compact()
+}
+
+// CheckIntegrity retires the cached entries of the users whose favorites a
+// repair removed. Users beyond the listed findings see the repair once
+// their entries expire.
+func (r *Repository) CheckIntegrity(repair bool) (repository.IntegrityReport, error) {
+	checker, ok := r.FavoritesRepository.(repository.IntegrityChecker)
+	if !ok {
+		return repository.IntegrityReport{}, domain.ErrNotSupported
+	}
+	report, err := checker.CheckIntegrity(repair)
+	if err == nil && repair {
+		for _, ref := range report.MissingAssets {
+			r.invalidateUser(ref.UserID)
+		}
+	}
+	return report, err
+}
+
+// CountUserFavorites is not cached: a count with a search filter depends on
+// the content of every asset the user holds, which readers do not track
+func (r *Repository) CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error) {
+	counter, ok := r.FavoritesRepository.(repository.FavoritesCounter)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return counter.CountUserFavorites(userID, query)
+}
+
+// GetFavorite is not cached; single lookups go straight to the backend
+func (r *Repository) GetFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	getter, ok := r.FavoritesRepository.(repository.FavoriteGetter)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return getter.GetFavorite(userID, assetID)
+}
+
+// FavoriteStats is not cached, like the counts it is made of
+func (r *Repository) FavoriteStats(userID string, weeks []time.Time) (repository.FavoriteStats, error) {
+	reporter, ok := r.FavoritesRepository.(repository.FavoritesSummarizer)
+	if !ok {
+		return repository.FavoriteStats{}, domain.ErrNotSupported
+	}
+	return reporter.FavoriteStats(userID, weeks)
+}
+
+// SearchAssets is not cached, like the asset listings it filters
+func (r *Repository) SearchAssets(query domain.AssetQuery) ([]domain.Asset, error) {
+	searcher, ok := r.FavoritesRepository.(repository.AssetSearcher)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return searcher.SearchAssets(query)
+}
+
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	references, ok := r.FavoritesRepository.(repository.AssetReferences)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return references.CountAssetReferences(assetID)
+}
+
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	references, ok := r.FavoritesRepository.(repository.AssetReferences)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return references.ListAssetFavoriters(assetID)
+}
+
+func (r *Repository) DeleteUser(userID string) error {
+	eraser, ok := r.FavoritesRepository.(repository.UserEraser)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	if err := eraser.DeleteUser(userID); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	lister, ok := r.FavoritesRepository.(repository.UserLister)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return lister.ListUserIDs(after, limit)
+}
+
+// Helper methods
+
+// generation returns the user's current cache generation; ok is false when
+// Redis is unavailable and the cache should be bypassed
+func (r *Repository) generation(ctx context.Context, userID string) (int64, bool) {
+	value, err := r.client.Get(ctx, r.genKey(userID)).Result()
+	if err == goredis.Nil {
+		return 0, true
+	}
+	if err != nil {
+		r.onError(err)
+		return 0, false
+	}
+	gen, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.onError(err)
+		return 0, false
+	}
+	return gen, true
+}
+
+// store caches value and records userID as a reader of each asset
+func (r *Repository) store(ctx context.Context, userID string, assetIDs []string, key string, value interface{}) {
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, assetID := range assetIDs {
+			pipe.SAdd(ctx, r.readersKey(assetID), userID)
+			pipe.Expire(ctx, r.readersKey(assetID), r.ttl)
+		}
+		pipe.Set(ctx, key, value, r.ttl)
+		return nil
+	})
+	if err != nil {
+		r.onError(err)
+	}
+}
+
+// invalidateUser retires every cached entry of the user. Generation keys
+// carry no TTL: an expired counter would restart at a generation that may
+// still have live entries.
+func (r *Repository) invalidateUser(userID string) {
+	if err := r.client.Incr(context.Background(), r.genKey(userID)).Err(); err != nil {
+		r.onError(err)
+	}
+}
+
+// invalidateAsset retires the cached entries of every user holding the asset
+func (r *Repository) invalidateAsset(assetID string) {
+	ctx := context.Background()
+
+	readers, err := r.client.SMembers(ctx, r.readersKey(assetID)).Result()
+	if err != nil {
+		r.onError(err)
+		return
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, userID := range readers {
+			pipe.Incr(ctx, r.genKey(userID))
+		}
+		pipe.Del(ctx, r.readersKey(assetID))
+		return nil
+	})
+	if err != nil {
+		r.onError(err)
+	}
+}
+
+func encodeFavorites(favorites []*domain.UserFavorite) ([]byte, error) {
+	cached := make([]cachedFavorite, len(favorites))
+	for i, favorite := range favorites {
+		asset, err := repository.EncodeAsset(favorite.Asset)
+		if err != nil {
+			return nil, err
+		}
+		cached[i] = cachedFavorite{
+			UserID:    favorite.UserID,
+			AssetID:   favorite.AssetID,
+			Asset:     asset,
+			Note:      favorite.Note,
+			Pinned:    favorite.Pinned,
+			Position:  favorite.Position,
+			AddedAt:   favorite.AddedAt,
+			UpdatedAt: favorite.UpdatedAt,
+		}
+	}
+	return json.Marshal(cached)
+}
+
+func decodeFavorites(data []byte) ([]*domain.UserFavorite, error) {
+	var cached []cachedFavorite
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+
+	favorites := make([]*domain.UserFavorite, len(cached))
+	for i, entry := range cached {
+		asset, err := repository.DecodeAsset(entry.Asset)
+		if err != nil {
+			return nil, err
+		}
+		favorites[i] = &domain.UserFavorite{
+			UserID:    entry.UserID,
+			AssetID:   entry.AssetID,
+			Asset:     asset,
+			Note:      entry.Note,
+			Pinned:    entry.Pinned,
+			Position:  entry.Position,
+			AddedAt:   entry.AddedAt,
+			UpdatedAt: entry.UpdatedAt,
+		}
+	}
+	return favorites, nil
+}
+
+// Collections are not cached; collection listings go to the backend
+
+func (r *Repository) CreateCollection(c *domain.Collection) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.CreateCollection(c)
+}
+
+func (r *Repository) GetCollection(userID, collectionID string) (*domain.Collection, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollection(userID, collectionID)
+}
+
+func (r *Repository) ListCollections(userID string) ([]*domain.Collection, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.ListCollections(userID)
+}
+
+func (r *Repository) DeleteCollection(userID, collectionID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.DeleteCollection(userID, collectionID)
+}
+
+func (r *Repository) AddToCollection(userID, collectionID, assetID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.AddToCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) RemoveFromCollection(userID, collectionID, assetID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.RemoveFromCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollectionFavorites(userID, collectionID, query)
+}
+
+// Order and pins change the user's listings, so their cache is invalidated
+
+func (r *Repository) ReorderFavorites(userID string, assetIDs []string) error {
+	store, ok := r.FavoritesRepository.(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	if err := store.ReorderFavorites(userID, assetIDs); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+func (r *Repository) SetFavoritePinned(userID, assetID string, pinned bool) error {
+	store, ok := r.FavoritesRepository.(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	if err := store.SetFavoritePinned(userID, assetID, pinned); err != nil {
+		return err
+	}
+	r.invalidateUser(userID)
+	return nil
+}
+
+// A transfer changes the listings of both users
+
+func (r *Repository) TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	transferer, ok := r.FavoritesRepository.(repository.FavoritesTransferer)
+	if !ok {
+		return repository.TransferResult{}, domain.ErrNotSupported
+	}
+	result, err := transferer.TransferFavorites(fromUserID, toUserID, assetIDs, move)
+	if err != nil {
+		return repository.TransferResult{}, err
+	}
+	r.invalidateUser(fromUserID)
+	r.invalidateUser(toUserID)
+	return result, nil
+}
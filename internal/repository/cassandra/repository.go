@@ -0,0 +1,546 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
+
+	"github.com/gocql/gocql"
+)
+
+// Options configures the Cassandra/Scylla connection
+type Options struct {
+	Hosts    []string
+	Keyspace string
+	// ReplicationFactor is used when the keyspace has to be created
+	ReplicationFactor int
+	// ReadConsistency and WriteConsistency are level names such as ONE,
+	// LOCAL_QUORUM or QUORUM
+	ReadConsistency  string
+	WriteConsistency string
+	Timeout          time.Duration
+	// Clock stamps favorites and asset updates; nil means the wall clock
+	Clock clock.Clock
+}
+
+// Repository implements FavoritesRepository on top of Cassandra or Scylla.
+//
+// Tables:
+//
+//	assets              asset documents by ID
+//	users               users by ID
+//	favorites_by_user   partitioned by user_id, so a user's favorites are one partition read
+//	users_by_asset      partitioned by asset_id, the reverse lookup used when assets change
+//	favorite_notes      personal notes, partitioned by user_id like favorites_by_user
+//
+// Uniqueness checks use lightweight transactions (IF NOT EXISTS / IF EXISTS).
+type Repository struct {
+	session *gocql.Session
+	read    gocql.Consistency
+	write   gocql.Consistency
+	clock   clock.Clock
+}
+
+// Open connects to the cluster, creating the keyspace and tables if needed
+func Open(opts Options) (*Repository, error) {
+	read, err := parseConsistency(opts.ReadConsistency)
+	if err != nil {
+		return nil, err
+	}
+	write, err := parseConsistency(opts.WriteConsistency)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := gocql.NewCluster(opts.Hosts...)
+	if opts.Timeout > 0 {
+		cluster.Timeout = opts.Timeout
+	}
+
+	// Schema changes run without a keyspace, since it may not exist yet
+	admin, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+	err = ensureSchema(admin, opts.Keyspace, opts.ReplicationFactor)
+	admin.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.Keyspace = opts.Keyspace
+	cluster.Consistency = read
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{session: session, read: read, write: write, clock: clock.OrSystem(opts.Clock)}, nil
+}
+
+// Close closes the session
+func (r *Repository) Close() error {
+	r.session.Close()
+	return nil
+}
+
+// Ping checks a node of the cluster answers queries
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.session.Query("SELECT release_version FROM system.local").WithContext(ctx).Exec()
+}
+
+func parseConsistency(level string) (gocql.Consistency, error) {
+	if level == "" {
+		return gocql.LocalQuorum, nil
+	}
+
+	consistency, err := gocql.ParseConsistencyWrapper(level)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency level %q: %w", level, err)
+	}
+	return consistency, nil
+}
+
+func (r *Repository) readQuery(stmt string, values ...interface{}) *gocql.Query {
+	return r.session.Query(stmt, values...).Consistency(r.read)
+}
+
+func (r *Repository) writeQuery(stmt string, values ...interface{}) *gocql.Query {
+	return r.session.Query(stmt, values...).Consistency(r.write)
+}
+
+// Asset operations
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	applied, err := r.writeQuery(
+		`INSERT INTO assets (id, type, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS`,
+		asset.GetID(), string(asset.GetType()), data, asset.GetCreatedAt(), asset.GetUpdatedAt(),
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrAssetAlreadyExists
+	}
+	return nil
+}
+
+func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
+	var data []byte
+	err := r.readQuery(`SELECT data FROM assets WHERE id = ?`, assetID).Scan(&data)
+	if err == gocql.ErrNotFound {
+		return nil, domain.ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.DecodeAsset(data)
+}
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	now := r.clock.Now()
+	asset.SetUpdatedAt(now)
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	applied, err := r.writeQuery(
+		`UPDATE assets SET type = ?, data = ?, updated_at = ? WHERE id = ? IF EXISTS`,
+		string(asset.GetType()), data, now, asset.GetID(),
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrAssetNotFound
+	}
+
+	// Update in all user favorites. The reverse lookup may still list a
+	// favorite being removed, so only existing rows are touched: an UPDATE
+	// would otherwise upsert the removed favorite back.
+	userIDs, err := r.usersByAsset(asset.GetID())
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if _, err := r.touchFavorite(userID, asset.GetID(), now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// touchFavorite sets the favorite's updated_at and reports whether it exists
+func (r *Repository) touchFavorite(userID, assetID string, now time.Time) (bool, error) {
+	return r.writeQuery(
+		`UPDATE favorites_by_user SET updated_at = ? WHERE user_id = ? AND asset_id = ? IF EXISTS`,
+		now, userID, assetID,
+	).MapScanCAS(map[string]interface{}{})
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	applied, err := r.writeQuery(`DELETE FROM assets WHERE id = ? IF EXISTS`, assetID).
+		MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrAssetNotFound
+	}
+
+	// Remove from all user favorites
+	userIDs, err := r.usersByAsset(assetID)
+	if err != nil {
+		return err
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.SetConsistency(r.write)
+	for _, userID := range userIDs {
+		batch.Query(`DELETE FROM favorites_by_user WHERE user_id = ? AND asset_id = ?`, userID, assetID)
+		batch.Query(`DELETE FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID)
+	}
+	batch.Query(`DELETE FROM users_by_asset WHERE asset_id = ?`, assetID)
+	return r.session.ExecuteBatch(batch)
+}
+
+// ListAssets pages through assets in token order, which is stable but not
+// meaningful; Cassandra cannot sort a full table scan
+func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
+	iter := r.readQuery(`SELECT data FROM assets`).Iter()
+
+	var assets []domain.Asset
+	var data []byte
+	skipped := 0
+	for len(assets) < limit && iter.Scan(&data) {
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		asset, err := repository.DecodeAsset(data)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, iter.Close()
+}
+
+// User operations
+func (r *Repository) CreateUser(user *domain.User) error {
+	return r.writeQuery(
+		`INSERT INTO users (id, email, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		user.ID, user.Email, user.Name, user.CreatedAt, user.UpdatedAt,
+	).Exec()
+}
+
+func (r *Repository) GetUser(userID string) (*domain.User, error) {
+	var user domain.User
+	err := r.readQuery(
+		`SELECT id, email, name, created_at, updated_at FROM users WHERE id = ?`, userID,
+	).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	if err == gocql.ErrNotFound {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Favorites operations
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	return r.addFavorite(userID, asset, r.clock.Now())
+}
+
+// ImportFavorite adds a favorite as if it had been added at addedAt
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	return r.addFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) addFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+	if _, err := r.GetAsset(asset.GetID()); err != nil {
+		return err
+	}
+
+	favorite := domain.NewUserFavoriteAt(userID, asset, addedAt)
+	applied, err := r.writeQuery(
+		`INSERT INTO favorites_by_user (user_id, asset_id, added_at, updated_at) VALUES (?, ?, ?, ?) IF NOT EXISTS`,
+		favorite.UserID, favorite.AssetID, favorite.AddedAt, favorite.UpdatedAt,
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrFavoriteAlreadyExists
+	}
+
+	return r.writeQuery(`INSERT INTO users_by_asset (asset_id, user_id) VALUES (?, ?)`, asset.GetID(), userID).Exec()
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+
+	applied, err := r.writeQuery(
+		`DELETE FROM favorites_by_user WHERE user_id = ? AND asset_id = ? IF EXISTS`, userID, assetID,
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrFavoriteNotFound
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.SetConsistency(r.write)
+	batch.Query(`DELETE FROM users_by_asset WHERE asset_id = ? AND user_id = ?`, assetID, userID)
+	batch.Query(`DELETE FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID)
+	return r.session.ExecuteBatch(batch)
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return nil, err
+	}
+
+	iter := r.readQuery(
+		`SELECT asset_id, added_at, updated_at FROM favorites_by_user WHERE user_id = ?`, userID,
+	).Iter()
+
+	var favorites []*domain.UserFavorite
+	var ids []string
+	var assetID string
+	var addedAt, updatedAt time.Time
+	for iter.Scan(&assetID, &addedAt, &updatedAt) {
+		favorites = append(favorites, &domain.UserFavorite{
+			UserID:    userID,
+			AssetID:   assetID,
+			AddedAt:   addedAt,
+			UpdatedAt: updatedAt,
+		})
+		ids = append(ids, assetID)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	assets, err := r.loadAssets(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := r.userNotes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]*domain.UserFavorite, 0, len(favorites))
+	for _, favorite := range favorites {
+		asset, exists := assets[favorite.AssetID]
+		if !exists {
+			// The asset was deleted concurrently; skip the dangling favorite
+			continue
+		}
+		favorite.Asset = asset
+		favorite.Note = notes[favorite.AssetID]
+		resolved = append(resolved, favorite)
+	}
+
+	return repository.ApplyFavoritesQuery(resolved, query), nil
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	var count int
+	err := r.readQuery(
+		`SELECT COUNT(*) FROM favorites_by_user WHERE user_id = ? AND asset_id = ?`, userID, assetID,
+	).Scan(&count)
+	return count > 0, err
+}
+
+func (r *Repository) GetFavoriteCount(userID string) (int, error) {
+	var count int
+	err := r.readQuery(`SELECT COUNT(*) FROM favorites_by_user WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
+
+// UpdateFavoriteAsset stores the asset and touches the favorite. Favorites
+// reference the shared asset row, so the change is visible to every user.
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+
+	isFavorite, err := r.IsFavorite(userID, assetID)
+	if err != nil {
+		return err
+	}
+	if !isFavorite {
+		return domain.ErrFavoriteNotFound
+	}
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	// Both rows are written only if they still exist, so a favorite removed
+	// or an asset deleted since the check is not upserted back. Conditions
+	// cannot span the two partitions, hence two lightweight transactions.
+	now := r.clock.Now()
+	applied, err := r.touchFavorite(userID, assetID, now)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrFavoriteNotFound
+	}
+
+	applied, err = r.writeQuery(
+		`UPDATE assets SET type = ?, data = ?, updated_at = ? WHERE id = ? IF EXISTS`,
+		string(asset.GetType()), data, now, assetID,
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return domain.ErrAssetNotFound
+	}
+	return nil
+}
+
+// UpdateFavoriteNote sets the user's note on a favorite and returns the
+// note it replaced. The read and the write are separate queries, so
+// concurrent edits of the same note resolve last write wins.
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return "", err
+	}
+
+	isFavorite, err := r.IsFavorite(userID, assetID)
+	if err != nil {
+		return "", err
+	}
+	if !isFavorite {
+		return "", domain.ErrFavoriteNotFound
+	}
+
+	var previous string
+	err = r.readQuery(
+		`SELECT note FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID,
+	).Scan(&previous)
+	if err != nil && err != gocql.ErrNotFound {
+		return "", err
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.SetConsistency(r.write)
+	if note == "" {
+		batch.Query(`DELETE FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID)
+	} else {
+		batch.Query(`INSERT INTO favorite_notes (user_id, asset_id, note) VALUES (?, ?, ?)`, userID, assetID, note)
+	}
+	batch.Query(`UPDATE favorites_by_user SET updated_at = ? WHERE user_id = ? AND asset_id = ?`, r.clock.Now(), userID, assetID)
+	return previous, r.session.ExecuteBatch(batch)
+}
+
+// CountAssetReferences returns how many users have favorited the asset
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	var count int
+	err := r.readQuery(`SELECT COUNT(*) FROM users_by_asset WHERE asset_id = ?`, assetID).Scan(&count)
+	return count, err
+}
+
+// ListAssetFavoriters returns the users who favorited the asset; user_id
+// is the clustering key, so they come back in ascending order
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	userIDs, err := r.usersByAsset(assetID)
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+	return userIDs, err
+}
+
+// ensureUser returns ErrUserNotFound if the user does not exist
+func (r *Repository) ensureUser(userID string) error {
+	var id string
+	err := r.readQuery(`SELECT id FROM users WHERE id = ?`, userID).Scan(&id)
+	if err == gocql.ErrNotFound {
+		return domain.ErrUserNotFound
+	}
+	return err
+}
+
+// usersByAsset lists the users that favorited an asset
+func (r *Repository) usersByAsset(assetID string) ([]string, error) {
+	iter := r.readQuery(`SELECT user_id FROM users_by_asset WHERE asset_id = ?`, assetID).Iter()
+
+	var userIDs []string
+	var userID string
+	for iter.Scan(&userID) {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, iter.Close()
+}
+
+// userNotes reads a user's notes, keyed by asset ID
+func (r *Repository) userNotes(userID string) (map[string]string, error) {
+	iter := r.readQuery(`SELECT asset_id, note FROM favorite_notes WHERE user_id = ?`, userID).Iter()
+
+	notes := make(map[string]string)
+	var assetID, note string
+	for iter.Scan(&assetID, &note) {
+		notes[assetID] = note
+	}
+	return notes, iter.Close()
+}
+
+// loadAssets fetches several assets in one query, omitting missing ones
+func (r *Repository) loadAssets(ids []string) (map[string]domain.Asset, error) {
+	assets := make(map[string]domain.Asset, len(ids))
+	if len(ids) == 0 {
+		return assets, nil
+	}
+
+	iter := r.readQuery(`SELECT id, data FROM assets WHERE id IN ?`, ids).Iter()
+
+	var id string
+	var data []byte
+	for iter.Scan(&id, &data) {
+		asset, err := repository.DecodeAsset(data)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		assets[id] = asset
+	}
+
+	return assets, iter.Close()
+}
+
+// Ensure Repository implements the interface
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.Pinger              = (*Repository)(nil)
+)
@@ -0,0 +1,71 @@
+package cassandra
+
+import (
+	"fmt"
+
+	"github.com/gocql/gocql"
+)
+
+// tables are applied on startup; every statement is idempotent
+var tables = []string{
+	`CREATE TABLE IF NOT EXISTS %s.users (
+	id         text PRIMARY KEY,
+	email      text,
+	name       text,
+	created_at timestamp,
+	updated_at timestamp
+)`,
+
+	`CREATE TABLE IF NOT EXISTS %s.assets (
+	id         text PRIMARY KEY,
+	type       text,
+	data       blob,
+	created_at timestamp,
+	updated_at timestamp
+)`,
+
+	`CREATE TABLE IF NOT EXISTS %s.favorites_by_user (
+	user_id    text,
+	asset_id   text,
+	added_at   timestamp,
+	updated_at timestamp,
+	PRIMARY KEY ((user_id), asset_id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS %s.users_by_asset (
+	asset_id text,
+	user_id  text,
+	PRIMARY KEY ((asset_id), user_id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS %s.favorite_notes (
+	user_id  text,
+	asset_id text,
+	note     text,
+	PRIMARY KEY ((user_id), asset_id)
+)`,
+}
+
+// ensureSchema creates the keyspace and tables if they do not exist
+func ensureSchema(session *gocql.Session, keyspace string, replicationFactor int) error {
+	if replicationFactor <= 0 {
+		replicationFactor = 1
+	}
+
+	// Identifiers cannot be bound, so the keyspace is quoted into the statements
+	quoted := `"` + keyspace + `"`
+	statements := []string{fmt.Sprintf(
+		`CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': %d}`,
+		quoted, replicationFactor,
+	)}
+	for _, table := range tables {
+		statements = append(statements, fmt.Sprintf(table, quoted))
+	}
+
+	for _, statement := range statements {
+		if err := session.Query(statement).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/canonicaljson"
+)
+
+// AssetSchemaVersion is the version written by EncodeAsset. Bump it and
+// append an upgrade to assetUpgrades whenever a stored field is added or
+// renamed, so data persisted by older builds keeps decoding.
+const AssetSchemaVersion = 1
+
+// schemaVersionField is stored alongside the asset fields
+const schemaVersionField = "schema_version"
+
+// assetUpgrade migrates a decoded document from version i to i+1
+type assetUpgrade func(doc map[string]interface{}) error
+
+// assetUpgrades[i] upgrades a document from version i to i+1
+var assetUpgrades = []assetUpgrade{
+	upgradeAssetV0,
+}
+
+// EncodeAsset serializes an asset for storage, tagged with AssetSchemaVersion.
+// The encoding is canonical, so equal assets are stored byte for byte alike.
+func EncodeAsset(asset domain.Asset) ([]byte, error) {
+	data, err := json.Marshal(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc[schemaVersionField] = AssetSchemaVersion
+
+	return canonicaljson.Marshal(doc)
+}
+
+// DecodeAsset deserializes a stored asset, upgrading documents written by
+// older builds to the current schema first. Unversioned documents are
+// treated as version 0.
+func DecodeAsset(data []byte) (domain.Asset, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if raw, ok := doc[schemaVersionField].(float64); ok {
+		version = int(raw)
+	}
+	if version > AssetSchemaVersion {
+		return nil, fmt.Errorf("asset schema version %d is newer than supported version %d", version, AssetSchemaVersion)
+	}
+
+	// Fast path: current documents need no rewriting
+	if version == AssetSchemaVersion {
+		return domain.AssetFromJSON(data)
+	}
+
+	for ; version < AssetSchemaVersion; version++ {
+		if err := assetUpgrades[version](doc); err != nil {
+			return nil, fmt.Errorf("upgrading asset from schema version %d: %w", version, err)
+		}
+	}
+	doc[schemaVersionField] = AssetSchemaVersion
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return domain.AssetFromJSON(upgraded)
+}
+
+// upgradeAssetV0 fills timestamps missing from assets written before they
+// were tracked on every update
+func upgradeAssetV0(doc map[string]interface{}) error {
+	if _, ok := doc["updated_at"]; !ok {
+		if createdAt, ok := doc["created_at"]; ok {
+			doc["updated_at"] = createdAt
+		}
+	}
+	if _, ok := doc["description"]; !ok {
+		doc["description"] = ""
+	}
+	return nil
+}
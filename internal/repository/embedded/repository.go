@@ -0,0 +1,494 @@
+package embedded
+
+import (
+	"encoding/json"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Repository implements FavoritesRepository on top of a local bbolt file.
+//
+// Buckets:
+//
+//	assets                     assetID -> asset JSON
+//	users                      userID -> user JSON
+//	favorites/{userID}         assetID -> favorite metadata JSON
+//	favorited_by/{assetID}     userID -> empty
+//
+// Every operation runs in a single bbolt transaction, so multi-key updates
+// are atomic and survive crashes.
+type Repository struct {
+	db    *bolt.DB
+	clock clock.Clock
+}
+
+// Option configures optional Repository behaviour
+type Option func(*Repository)
+
+// WithClock stamps favorites and asset updates with c instead of the wall clock
+func WithClock(c clock.Clock) Option {
+	return func(r *Repository) { r.clock = c }
+}
+
+var (
+	assetsBucket      = []byte("assets")
+	usersBucket       = []byte("users")
+	favoritesBucket   = []byte("favorites")
+	favoritedByBucket = []byte("favorited_by")
+)
+
+// favoriteMeta is the per-favorite data stored under the user's bucket
+type favoriteMeta struct {
+	Note      string    `json:"note,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Open opens (creating if needed) the database file at path
+func Open(path string, opts ...Option) (*Repository, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{assetsBucket, usersBucket, favoritesBucket, favoritedByBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	r := &Repository{db: db, clock: clock.System}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Close releases the database file
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// Asset operations
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		assets := tx.Bucket(assetsBucket)
+		if assets.Get([]byte(asset.GetID())) != nil {
+			return domain.ErrAssetAlreadyExists
+		}
+		return assets.Put([]byte(asset.GetID()), data)
+	})
+}
+
+func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
+	var asset domain.Asset
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		asset, err = getAsset(tx, assetID)
+		return err
+	})
+	return asset, err
+}
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	now := r.clock.Now()
+	asset.SetUpdatedAt(now)
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		assets := tx.Bucket(assetsBucket)
+		if assets.Get([]byte(asset.GetID())) == nil {
+			return domain.ErrAssetNotFound
+		}
+		if err := assets.Put([]byte(asset.GetID()), data); err != nil {
+			return err
+		}
+
+		// Update in all user favorites
+		favoritedBy := tx.Bucket(favoritedByBucket).Bucket([]byte(asset.GetID()))
+		if favoritedBy == nil {
+			return nil
+		}
+		return favoritedBy.ForEach(func(userID, _ []byte) error {
+			return touchFavorite(tx, string(userID), asset.GetID(), now)
+		})
+	})
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		assets := tx.Bucket(assetsBucket)
+		if assets.Get([]byte(assetID)) == nil {
+			return domain.ErrAssetNotFound
+		}
+		if err := assets.Delete([]byte(assetID)); err != nil {
+			return err
+		}
+
+		// Remove from all user favorites
+		favoritedByRoot := tx.Bucket(favoritedByBucket)
+		favoritedBy := favoritedByRoot.Bucket([]byte(assetID))
+		if favoritedBy == nil {
+			return nil
+		}
+		err := favoritedBy.ForEach(func(userID, _ []byte) error {
+			if favorites := tx.Bucket(favoritesBucket).Bucket(userID); favorites != nil {
+				return favorites.Delete([]byte(assetID))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return favoritedByRoot.DeleteBucket([]byte(assetID))
+	})
+}
+
+// ListAssets pages through assets in ID order
+func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
+	var list []domain.Asset
+	err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(assetsBucket).Cursor()
+		skipped := 0
+		for key, data := cursor.First(); key != nil && len(list) < limit; key, data = cursor.Next() {
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			asset, err := repository.DecodeAsset(data)
+			if err != nil {
+				return err
+			}
+			list = append(list, asset)
+		}
+		return nil
+	})
+	return list, err
+}
+
+// User operations
+func (r *Repository) CreateUser(user *domain.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).Put([]byte(user.ID), data)
+	})
+}
+
+func (r *Repository) GetUser(userID string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(usersBucket).Get([]byte(userID))
+		if data == nil {
+			return domain.ErrUserNotFound
+		}
+		return json.Unmarshal(data, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUserIDs returns up to limit user IDs after the given ID, in order
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(usersBucket).Cursor()
+		key, _ := cursor.Seek([]byte(after))
+		if key != nil && string(key) == after {
+			key, _ = cursor.Next()
+		}
+		for ; key != nil && len(ids) < limit; key, _ = cursor.Next() {
+			ids = append(ids, string(key))
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// Favorites operations
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	return r.addFavorite(userID, asset, r.clock.Now())
+}
+
+// ImportFavorite adds a favorite as if it had been added at addedAt
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	return r.addFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) addFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	favorite := domain.NewUserFavoriteAt(userID, asset, addedAt)
+	meta, err := json.Marshal(favoriteMeta{AddedAt: favorite.AddedAt, UpdatedAt: favorite.UpdatedAt})
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		// Ensure user exists
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return domain.ErrUserNotFound
+		}
+
+		// Ensure asset exists
+		if tx.Bucket(assetsBucket).Get([]byte(asset.GetID())) == nil {
+			return domain.ErrAssetNotFound
+		}
+
+		favorites, err := tx.Bucket(favoritesBucket).CreateBucketIfNotExists([]byte(userID))
+		if err != nil {
+			return err
+		}
+		if favorites.Get([]byte(asset.GetID())) != nil {
+			return domain.ErrFavoriteAlreadyExists
+		}
+		if err := favorites.Put([]byte(asset.GetID()), meta); err != nil {
+			return err
+		}
+
+		favoritedBy, err := tx.Bucket(favoritedByBucket).CreateBucketIfNotExists([]byte(asset.GetID()))
+		if err != nil {
+			return err
+		}
+		return favoritedBy.Put([]byte(userID), []byte{})
+	})
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		// Check if user exists
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return domain.ErrUserNotFound
+		}
+
+		favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+		if favorites == nil || favorites.Get([]byte(assetID)) == nil {
+			return domain.ErrFavoriteNotFound
+		}
+		if err := favorites.Delete([]byte(assetID)); err != nil {
+			return err
+		}
+
+		if favoritedBy := tx.Bucket(favoritedByBucket).Bucket([]byte(assetID)); favoritedBy != nil {
+			return favoritedBy.Delete([]byte(userID))
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	favorites := []*domain.UserFavorite{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		// Check if user exists
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return domain.ErrUserNotFound
+		}
+
+		bucket := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(assetID, raw []byte) error {
+			var meta favoriteMeta
+			if err := json.Unmarshal(raw, &meta); err != nil {
+				return err
+			}
+
+			asset, err := getAsset(tx, string(assetID))
+			if err != nil {
+				return err
+			}
+
+			favorites = append(favorites, &domain.UserFavorite{
+				UserID:    userID,
+				AssetID:   string(assetID),
+				Asset:     asset,
+				Note:      meta.Note,
+				AddedAt:   meta.AddedAt,
+				UpdatedAt: meta.UpdatedAt,
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.ApplyFavoritesQuery(favorites, query), nil
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	var isFavorite bool
+	err := r.db.View(func(tx *bolt.Tx) error {
+		favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+		isFavorite = favorites != nil && favorites.Get([]byte(assetID)) != nil
+		return nil
+	})
+	return isFavorite, err
+}
+
+func (r *Repository) GetFavoriteCount(userID string) (int, error) {
+	var count int
+	err := r.db.View(func(tx *bolt.Tx) error {
+		if favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID)); favorites != nil {
+			count = favorites.Stats().KeyN
+		}
+		return nil
+	})
+	return count, err
+}
+
+// UpdateFavoriteAsset stores the asset and touches the favorite. Favorites
+// reference the shared asset, so the change is visible to every user.
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		// Check if user exists
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return domain.ErrUserNotFound
+		}
+
+		favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+		if favorites == nil || favorites.Get([]byte(assetID)) == nil {
+			return domain.ErrFavoriteNotFound
+		}
+
+		if err := tx.Bucket(assetsBucket).Put([]byte(assetID), data); err != nil {
+			return err
+		}
+		return touchFavorite(tx, userID, assetID, r.clock.Now())
+	})
+}
+
+// UpdateFavoriteNote sets the user's note on a favorite and returns the
+// note it replaced
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	var previous string
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		// Check if user exists
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return domain.ErrUserNotFound
+		}
+
+		favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+		if favorites == nil {
+			return domain.ErrFavoriteNotFound
+		}
+		raw := favorites.Get([]byte(assetID))
+		if raw == nil {
+			return domain.ErrFavoriteNotFound
+		}
+
+		var meta favoriteMeta
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+		previous = meta.Note
+		meta.Note = note
+		meta.UpdatedAt = r.clock.Now()
+
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		return favorites.Put([]byte(assetID), data)
+	})
+	return previous, err
+}
+
+// CountAssetReferences returns how many users have favorited the asset
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	var count int
+	err := r.db.View(func(tx *bolt.Tx) error {
+		if favoritedBy := tx.Bucket(favoritedByBucket).Bucket([]byte(assetID)); favoritedBy != nil {
+			count = favoritedBy.Stats().KeyN
+		}
+		return nil
+	})
+	return count, err
+}
+
+// ListAssetFavoriters returns the users who favorited the asset, in key order
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	userIDs := []string{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		favoritedBy := tx.Bucket(favoritedByBucket).Bucket([]byte(assetID))
+		if favoritedBy == nil {
+			return nil
+		}
+		return favoritedBy.ForEach(func(userID, _ []byte) error {
+			userIDs = append(userIDs, string(userID))
+			return nil
+		})
+	})
+	return userIDs, err
+}
+
+// getAsset decodes an asset within a transaction
+func getAsset(tx *bolt.Tx, assetID string) (domain.Asset, error) {
+	data := tx.Bucket(assetsBucket).Get([]byte(assetID))
+	if data == nil {
+		return nil, domain.ErrAssetNotFound
+	}
+	return repository.DecodeAsset(data)
+}
+
+// touchFavorite sets the UpdatedAt of a favorite if it still exists
+func touchFavorite(tx *bolt.Tx, userID, assetID string, now time.Time) error {
+	favorites := tx.Bucket(favoritesBucket).Bucket([]byte(userID))
+	if favorites == nil {
+		return nil
+	}
+	raw := favorites.Get([]byte(assetID))
+	if raw == nil {
+		return nil
+	}
+
+	var meta favoriteMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return err
+	}
+	meta.UpdatedAt = now
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return favorites.Put([]byte(assetID), data)
+}
+
+// Ensure Repository implements the interface
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+)
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// week is the span of each weekly count
+const week = 7 * 24 * time.Hour
+
+// NewFavoriteStats returns stats with a zero count for every asset type and
+// for each of the weeks starting at weeks
+func NewFavoriteStats(weeks []time.Time) FavoriteStats {
+	stats := FavoriteStats{
+		ByType: make(map[domain.AssetType]int),
+		Weekly: make([]WeeklyFavorites, len(weeks)),
+	}
+	for _, assetType := range domain.AssetTypes() {
+		stats.ByType[assetType] = 0
+	}
+	for i, start := range weeks {
+		stats.Weekly[i].Start = start
+	}
+	return stats
+}
+
+// Add counts a favorite. It is shared by backends that summarize
+// favorites in memory.
+func (s *FavoriteStats) Add(favorite *domain.UserFavorite) {
+	s.Total++
+	if favorite.Asset != nil {
+		s.ByType[favorite.Asset.GetType()]++
+	}
+
+	// The last week starting at or before the favorite was added
+	i := sort.Search(len(s.Weekly), func(i int) bool {
+		return s.Weekly[i].Start.After(favorite.AddedAt)
+	}) - 1
+	if i >= 0 && favorite.AddedAt.Before(s.Weekly[i].Start.Add(week)) {
+		s.Weekly[i].Added++
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+// MaxIntegrityFindings bounds the favorites and assets an integrity report
+// lists of each kind
+const MaxIntegrityFindings = 100
+
+// NewIntegrityReport returns a report with no findings
+func NewIntegrityReport() IntegrityReport {
+	return IntegrityReport{
+		MissingAssets: []FavoriteRef{},
+		InvalidAssets: []InvalidAsset{},
+		CounterDrift:  []CounterDrift{},
+	}
+}
+
+// AddMissingAsset records a favorite pointing at a missing asset
+func (r *IntegrityReport) AddMissingAsset(userID, assetID string) {
+	r.MissingAssetCount++
+	if len(r.MissingAssets) < MaxIntegrityFindings {
+		r.MissingAssets = append(r.MissingAssets, FavoriteRef{UserID: userID, AssetID: assetID})
+	}
+}
+
+// AddInvalidAsset records an asset that cannot be served
+func (r *IntegrityReport) AddInvalidAsset(assetID, reason string) {
+	r.InvalidAssetCount++
+	if len(r.InvalidAssets) < MaxIntegrityFindings {
+		r.InvalidAssets = append(r.InvalidAssets, InvalidAsset{AssetID: assetID, Reason: reason})
+	}
+}
+
+// AddCounterDrift records a counter that disagrees with what it counts
+func (r *IntegrityReport) AddCounterDrift(counter string, stored, actual int) {
+	r.CounterDrift = append(r.CounterDrift, CounterDrift{Counter: counter, Stored: stored, Actual: actual})
+}
+
+// Issues counts the inconsistencies found
+func (r *IntegrityReport) Issues() int {
+	return r.MissingAssetCount + r.InvalidAssetCount + len(r.CounterDrift)
+}
+
+// Merge adds the findings of other, such as another shard's, to the report.
+// Its counters are named with prefix.
+func (r *IntegrityReport) Merge(other IntegrityReport, prefix string) {
+	r.AssetsChecked += other.AssetsChecked
+	r.FavoritesChecked += other.FavoritesChecked
+	r.Repaired += other.Repaired
+	for _, ref := range other.MissingAssets {
+		r.AddMissingAsset(ref.UserID, ref.AssetID)
+	}
+	r.MissingAssetCount += other.MissingAssetCount - len(other.MissingAssets)
+	for _, asset := range other.InvalidAssets {
+		r.AddInvalidAsset(asset.AssetID, asset.Reason)
+	}
+	r.InvalidAssetCount += other.InvalidAssetCount - len(other.InvalidAssets)
+	for _, drift := range other.CounterDrift {
+		r.AddCounterDrift(prefix+drift.Counter, drift.Stored, drift.Actual)
+	}
+}
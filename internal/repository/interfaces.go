@@ -1,6 +1,11 @@
 package repository
 
-import "gwi-favorites-service/internal/domain"
+import (
+	"context"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
 
 // FavoritesRepository defines the interface for favorites storage operations
 type FavoritesRepository interface {
@@ -18,8 +23,210 @@ type FavoritesRepository interface {
 	// Favorites operations
 	AddFavorite(userID string, asset domain.Asset) error
 	RemoveFavorite(userID, assetID string) error
-	GetUserFavorites(userID string, limit, offset int) ([]*domain.UserFavorite, error)
+	GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error)
 	IsFavorite(userID, assetID string) (bool, error)
 	GetFavoriteCount(userID string) (int, error)
 	UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error
+	// UpdateFavoriteNote sets the user's note on a favorite and returns the
+	// note it replaced
+	UpdateFavoriteNote(userID, assetID, note string) (string, error)
+}
+
+// StorageStats describes the contents and health of a storage backend
+type StorageStats struct {
+	Backend        string                 `json:"backend"`
+	Users          int                    `json:"users"`
+	Assets         int                    `json:"assets"`
+	Favorites      int                    `json:"favorites"`
+	EstimatedBytes int64                  `json:"estimated_bytes"`
+	Details        map[string]interface{} `json:"details,omitempty"`
+}
+
+// CompactionResult describes the outcome of a storage compaction
+type CompactionResult struct {
+	BytesBefore int64         `json:"estimated_bytes_before"`
+	BytesAfter  int64         `json:"estimated_bytes_after"`
+	Removed     int           `json:"removed_entries"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// StorageInspector is implemented by backends that can report on and compact their own storage
+type StorageInspector interface {
+	StorageStats() (StorageStats, error)
+	Compact() (CompactionResult, error)
+}
+
+// FavoritesCounter is implemented by backends that can count the favorites
+// matching a query's filters without loading them. Sort and paging are ignored.
+type FavoritesCounter interface {
+	CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error)
+}
+
+// FavoriteGetter is implemented by backends that can look up one of a
+// user's favorites without loading the others. It fails with
+// domain.ErrUserNotFound or domain.ErrFavoriteNotFound.
+type FavoriteGetter interface {
+	GetFavorite(userID, assetID string) (*domain.UserFavorite, error)
+}
+
+// AssetSearcher is implemented by backends that can filter the catalog by
+// type and search terms themselves. Matching assets are returned in
+// ListAssets order, paged by the query's limit and offset; availability is
+// left to the caller.
+type AssetSearcher interface {
+	SearchAssets(query domain.AssetQuery) ([]domain.Asset, error)
+}
+
+// CollectionStore is implemented by backends that can keep favorites
+// collections. Only favorites can be collected, and removing a favorite
+// removes it from every collection. Names are unique per user.
+type CollectionStore interface {
+	CreateCollection(collection *domain.Collection) error
+	GetCollection(userID, collectionID string) (*domain.Collection, error)
+	ListCollections(userID string) ([]*domain.Collection, error)
+	DeleteCollection(userID, collectionID string) error
+	// AddToCollection succeeds without change when the favorite is already collected
+	AddToCollection(userID, collectionID, assetID string) error
+	RemoveFromCollection(userID, collectionID, assetID string) error
+	// GetCollectionFavorites filters, orders and pages like GetUserFavorites
+	GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error)
+}
+
+// FavoriteOrderStore is implemented by backends that can keep a user's
+// manual order of favorites and pin favorites to the top. Both fail with
+// ErrFavoriteNotFound, changing nothing, if an asset is not a favorite.
+type FavoriteOrderStore interface {
+	// ReorderFavorites places the listed favorites at positions 1 to n and
+	// clears the position of every other favorite of the user
+	ReorderFavorites(userID string, assetIDs []string) error
+	SetFavoritePinned(userID, assetID string, pinned bool) error
+}
+
+// FavoriteImporter is implemented by backends that can add a favorite
+// with the time it was originally added, so restores and migrations keep
+// it. It fails like AddFavorite.
+type FavoriteImporter interface {
+	ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error
+}
+
+// TransferResult lists, in ascending order, the favorites a transfer gave
+// the target user and those skipped because the target already had them
+type TransferResult struct {
+	Transferred []string `json:"transferred"`
+	Skipped     []string `json:"skipped"`
+}
+
+// FavoritesTransferer is implemented by backends that can copy or move a
+// user's favorites to another user atomically, as when merging accounts.
+// Favorites keep their notes and the time they were added; pins, positions
+// and collections stay behind. Nil assetIDs transfers every favorite; if
+// any listed asset is not a favorite of fromUserID it fails with
+// ErrFavoriteNotFound, changing nothing. Moving also removes the skipped
+// favorites from fromUserID.
+type FavoritesTransferer interface {
+	TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (TransferResult, error)
+}
+
+// FavoriteStats summarizes a user's favorites
+type FavoriteStats struct {
+	Total  int                      `json:"total"`
+	ByType map[domain.AssetType]int `json:"by_type"`
+	Weekly []WeeklyFavorites        `json:"weekly"`
+}
+
+// WeeklyFavorites counts the favorites added in the week from Start
+type WeeklyFavorites struct {
+	Start time.Time `json:"week_start"`
+	Added int       `json:"added"`
+}
+
+// FavoritesSummarizer is implemented by backends that can summarize a
+// user's favorites without loading them. Weekly counts the favorites added
+// in the week from each of weeks, which are ascending and a week apart.
+type FavoritesSummarizer interface {
+	FavoriteStats(userID string, weeks []time.Time) (FavoriteStats, error)
+}
+
+// IntegrityReport lists the inconsistencies found by an integrity check.
+// At most MaxIntegrityFindings favorites and assets are listed; the counts
+// cover them all.
+type IntegrityReport struct {
+	AssetsChecked     int            `json:"assets_checked"`
+	FavoritesChecked  int            `json:"favorites_checked"`
+	MissingAssetCount int            `json:"missing_asset_count"`
+	MissingAssets     []FavoriteRef  `json:"missing_assets"`
+	InvalidAssetCount int            `json:"invalid_asset_count"`
+	InvalidAssets     []InvalidAsset `json:"invalid_assets"`
+	CounterDrift      []CounterDrift `json:"counter_drift"`
+	// Repaired counts the favorites removed and counters corrected
+	Repaired int `json:"repaired"`
+}
+
+// FavoriteRef identifies a favorite
+type FavoriteRef struct {
+	UserID  string `json:"user_id"`
+	AssetID string `json:"asset_id"`
+}
+
+// InvalidAsset is a stored asset that cannot be served, and why
+type InvalidAsset struct {
+	AssetID string `json:"asset_id"`
+	Reason  string `json:"reason"`
+}
+
+// CounterDrift is a stored counter that disagrees with what it counts
+type CounterDrift struct {
+	Counter string `json:"counter"`
+	Stored  int    `json:"stored"`
+	Actual  int    `json:"actual"`
+}
+
+// IntegrityChecker is implemented by backends that can scan their storage
+// for favorites pointing at missing assets, assets of unknown types and
+// counters that drifted from what they count. Repairing removes those
+// favorites and corrects the counters. Invalid assets are only reported,
+// since they may have been written by a newer build.
+type IntegrityChecker interface {
+	CheckIntegrity(repair bool) (IntegrityReport, error)
+}
+
+// AssetReferences is implemented by backends that can count and list the
+// users who favorited an asset without scanning every user
+type AssetReferences interface {
+	CountAssetReferences(assetID string) (int, error)
+	// ListAssetFavoriters returns the IDs of those users in ascending order
+	ListAssetFavoriters(assetID string) ([]string, error)
+}
+
+// UserEraser is implemented by backends that can delete a user together
+// with all of their favorites, as required to honour erasure requests
+type UserEraser interface {
+	DeleteUser(userID string) error
+}
+
+// UserLister is implemented by backends that can enumerate their users, as
+// needed to copy a whole store. IDs are returned in ascending order, starting
+// after the given ID, so listing can resume from the last ID seen.
+type UserLister interface {
+	ListUserIDs(after string, limit int) ([]string, error)
+}
+
+// AssetBlobStore holds asset content too large to keep inline, such as the
+// data of big charts. Keys are chosen by the caller.
+type AssetBlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// ChartDataLoader is implemented by backends that move large chart data to
+// a blob store, to load the data a chart's DataRef points at
+type ChartDataLoader interface {
+	LoadChartData(ref domain.BlobRef) ([]domain.ChartDataPoint, error)
+}
+
+// Pinger is implemented by backends that depend on a remote service, to
+// check the service is reachable and measure its round trip
+type Pinger interface {
+	Ping(ctx context.Context) error
 }
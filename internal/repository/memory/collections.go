@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// collection holds a collection and the asset IDs collected in it, with
+// the time each was added
+type collection struct {
+	domain.Collection
+	items map[string]time.Time
+}
+
+// view returns a copy of the collection with its favorite count filled in
+func (c *collection) view() *domain.Collection {
+	view := c.Collection
+	view.FavoriteCount = len(c.items)
+	return &view
+}
+
+var _ repository.CollectionStore = (*Repository)(nil)
+
+func (r *Repository) CreateCollection(c *domain.Collection) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[c.UserID]; !exists {
+		return domain.ErrUserNotFound
+	}
+	for _, existing := range r.collections[c.UserID] {
+		if existing.Name == c.Name {
+			return domain.ErrCollectionAlreadyExists
+		}
+	}
+
+	if r.collections[c.UserID] == nil {
+		r.collections[c.UserID] = make(map[string]*collection)
+	}
+	r.collections[c.UserID][c.ID] = &collection{Collection: *c, items: make(map[string]time.Time)}
+	return nil
+}
+
+func (r *Repository) GetCollection(userID, collectionID string) (*domain.Collection, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	c, err := r.collectionLocked(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	return c.view(), nil
+}
+
+// ListCollections returns the user's collections, oldest first
+func (r *Repository) ListCollections(userID string) ([]*domain.Collection, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return nil, domain.ErrUserNotFound
+	}
+
+	collections := make([]*domain.Collection, 0, len(r.collections[userID]))
+	for _, c := range r.collections[userID] {
+		collections = append(collections, c.view())
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		if !collections[i].CreatedAt.Equal(collections[j].CreatedAt) {
+			return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+		}
+		return collections[i].ID < collections[j].ID
+	})
+	return collections, nil
+}
+
+func (r *Repository) DeleteCollection(userID, collectionID string) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.collectionLocked(userID, collectionID); err != nil {
+		return err
+	}
+	delete(r.collections[userID], collectionID)
+	return nil
+}
+
+func (r *Repository) AddToCollection(userID, collectionID, assetID string) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	c, err := r.collectionLocked(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if _, exists := r.favorites[userID][assetID]; !exists {
+		return domain.ErrFavoriteNotFound
+	}
+	if _, exists := c.items[assetID]; exists {
+		return nil
+	}
+
+	now := r.clock.Now()
+	c.items[assetID] = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (r *Repository) RemoveFromCollection(userID, collectionID, assetID string) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	c, err := r.collectionLocked(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if _, exists := c.items[assetID]; !exists {
+		return domain.ErrNotInCollection
+	}
+
+	delete(c.items, assetID)
+	c.UpdatedAt = r.clock.Now()
+	return nil
+}
+
+func (r *Repository) GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	c, err := r.collectionLocked(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	collected := make([]*domain.UserFavorite, 0, len(c.items))
+	for assetID := range c.items {
+		if favorite, exists := r.favorites[userID][assetID]; exists {
+			collected = append(collected, favorite)
+		}
+	}
+
+	page := repository.ApplyFavoritesQuery(collected, query)
+	for _, favorite := range page {
+		r.touch(favorite.AssetID)
+	}
+	return page, nil
+}
+
+// collectionLocked finds a user's collection. The caller must hold the lock.
+func (r *Repository) collectionLocked(userID, collectionID string) (*collection, error) {
+	if _, exists := r.users[userID]; !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	c, exists := r.collections[userID][collectionID]
+	if !exists {
+		return nil, domain.ErrCollectionNotFound
+	}
+	return c, nil
+}
+
+// uncollectLocked removes a favorite that is no longer held from the
+// user's collections. The caller must hold the write lock.
+func (r *Repository) uncollectLocked(userID, assetID string) {
+	for _, c := range r.collections[userID] {
+		delete(c.items, assetID)
+	}
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoritesSummarizer = (*Repository)(nil)
+
+func (r *Repository) FavoriteStats(userID string, weeks []time.Time) (repository.FavoriteStats, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return repository.FavoriteStats{}, domain.ErrUserNotFound
+	}
+
+	stats := repository.NewFavoriteStats(weeks)
+	for _, favorite := range r.favorites[userID] {
+		stats.Add(favorite)
+	}
+	return stats, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.IntegrityChecker = (*Repository)(nil)
+
+// CheckIntegrity scans the catalog and every user's favorites, in ID order
+func (r *Repository) CheckIntegrity(repair bool) (repository.IntegrityReport, error) {
+	if repair {
+		r.lock()
+		defer r.mu.Unlock()
+	} else {
+		r.rlock()
+		defer r.mu.RUnlock()
+	}
+
+	report := repository.NewIntegrityReport()
+	for _, assetID := range sortedKeys(r.assets) {
+		asset := r.assets[assetID]
+		report.AssetsChecked++
+		switch {
+		case asset.GetID() != assetID:
+			report.AddInvalidAsset(assetID, fmt.Sprintf("stored under another ID, %q", asset.GetID()))
+		case !asset.GetType().IsValid():
+			report.AddInvalidAsset(assetID, fmt.Sprintf("unknown asset type %q", asset.GetType()))
+		}
+	}
+
+	// favorites counts the entries left after any repair
+	favorites := 0
+	for _, userID := range sortedKeys(r.favorites) {
+		for _, assetID := range sortedKeys(r.favorites[userID]) {
+			favorites++
+			report.FavoritesChecked++
+			if _, exists := r.assets[assetID]; exists {
+				continue
+			}
+			report.AddMissingAsset(userID, assetID)
+			if repair {
+				delete(r.favorites[userID], assetID)
+				r.uncollectLocked(userID, assetID)
+				r.countFavoriteLocked(assetID, -1)
+				favorites--
+				report.Repaired++
+			}
+		}
+	}
+
+	if r.favoriteCount != favorites {
+		report.AddCounterDrift("favorites", r.favoriteCount, favorites)
+		if repair {
+			r.favoriteCount = favorites
+			report.Repaired++
+		}
+	}
+	return report, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoriteOrderStore = (*Repository)(nil)
+
+func (r *Repository) ReorderFavorites(userID string, assetIDs []string) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return domain.ErrUserNotFound
+	}
+	favorites := r.favorites[userID]
+	for _, assetID := range assetIDs {
+		if _, exists := favorites[assetID]; !exists {
+			return domain.ErrFavoriteNotFound
+		}
+	}
+
+	for _, favorite := range favorites {
+		favorite.Position = 0
+	}
+	for i, assetID := range assetIDs {
+		favorites[assetID].Position = i + 1
+	}
+	return nil
+}
+
+func (r *Repository) SetFavoritePinned(userID, assetID string, pinned bool) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return domain.ErrUserNotFound
+	}
+	favorite, exists := r.favorites[userID][assetID]
+	if !exists {
+		return domain.ErrFavoriteNotFound
+	}
+
+	favorite.Pinned = pinned
+	return nil
+}
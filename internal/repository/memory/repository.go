@@ -1,45 +1,116 @@
 package memory
 
 import (
+	"container/list"
+	"sort"
 	"sync"
 	"time"
 
 	"gwi-favorites-service/internal/domain"
 	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
 )
 
+// Options configures the in-memory repository
+type Options struct {
+	// MaxAssets caps the number of stored assets; 0 means unlimited
+	MaxAssets int
+	// MaxFavorites caps the number of favorites across all users; 0 means unlimited
+	MaxFavorites int
+	// Clock stamps favorites and asset updates; nil means the wall clock
+	Clock clock.Clock
+}
+
+// Usage reports how much of its budget the repository is using
+type Usage struct {
+	Assets       int    `json:"assets"`
+	MaxAssets    int    `json:"max_assets"`
+	Favorites    int    `json:"favorites"`
+	MaxFavorites int    `json:"max_favorites"`
+	Evictions    uint64 `json:"evictions"`
+}
+
 // Repository implements FavoritesRepository using in-memory storage
 type Repository struct {
-	mu        sync.RWMutex
-	assets    map[string]domain.Asset
-	users     map[string]*domain.User
-	favorites map[string]map[string]*domain.UserFavorite // userID -> assetID -> UserFavorite
+	mu            sync.RWMutex
+	opts          Options
+	clock         clock.Clock
+	assets        map[string]domain.Asset
+	users         map[string]*domain.User
+	favorites     map[string]map[string]*domain.UserFavorite // userID -> assetID -> UserFavorite
+	collections   map[string]map[string]*collection          // userID -> collectionID -> collection
+	favoriteCount int
+	favoriters    map[string]int // assetID -> users who favorited it
+	evictions     uint64
+
+	// Lock acquisition counters, updated atomically
+	lockStats      lockStats
+	lastCompaction time.Time
+
+	// Asset recency is tracked under its own lock so reads holding only
+	// the read lock can still record access
+	lruMu    sync.Mutex
+	lru      *list.List               // front is most recently used asset ID
+	lruIndex map[string]*list.Element // assetID -> element in lru
 }
 
-// NewRepository creates a new in-memory repository
+// NewRepository creates a new in-memory repository without storage limits
 func NewRepository() *Repository {
+	return NewRepositoryWithOptions(Options{})
+}
+
+// NewRepositoryWithOptions creates a new in-memory repository with the given limits
+func NewRepositoryWithOptions(opts Options) *Repository {
 	return &Repository{
-		assets:    make(map[string]domain.Asset),
-		users:     make(map[string]*domain.User),
-		favorites: make(map[string]map[string]*domain.UserFavorite),
+		opts:        opts,
+		clock:       clock.OrSystem(opts.Clock),
+		assets:      make(map[string]domain.Asset),
+		users:       make(map[string]*domain.User),
+		favorites:   make(map[string]map[string]*domain.UserFavorite),
+		collections: make(map[string]map[string]*collection),
+		favoriters:  make(map[string]int),
+		lru:         list.New(),
+		lruIndex:    make(map[string]*list.Element),
+	}
+}
+
+// Usage returns current entry counts against the configured limits
+func (r *Repository) Usage() Usage {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	return Usage{
+		Assets:       len(r.assets),
+		MaxAssets:    r.opts.MaxAssets,
+		Favorites:    r.favoriteCount,
+		MaxFavorites: r.opts.MaxFavorites,
+		Evictions:    r.evictions,
 	}
 }
 
 // Asset operations
 func (r *Repository) CreateAsset(asset domain.Asset) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	if _, exists := r.assets[asset.GetID()]; exists {
 		return domain.ErrAssetAlreadyExists
 	}
 
+	// Make room by evicting the least recently used asset
+	if r.opts.MaxAssets > 0 && len(r.assets) >= r.opts.MaxAssets {
+		if !r.evictLocked(asset.GetID(), false) {
+			return domain.ErrStorageLimitReached
+		}
+	}
+
 	r.assets[asset.GetID()] = asset
+	r.touch(asset.GetID())
 	return nil
 }
 
 func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
-	r.mu.RLock()
+	r.rlock()
 	defer r.mu.RUnlock()
 
 	asset, exists := r.assets[assetID]
@@ -47,25 +118,28 @@ func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
 		return nil, domain.ErrAssetNotFound
 	}
 
+	r.touch(assetID)
 	return asset, nil
 }
 
 func (r *Repository) UpdateAsset(asset domain.Asset) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	if _, exists := r.assets[asset.GetID()]; !exists {
 		return domain.ErrAssetNotFound
 	}
 
-	asset.SetUpdatedAt(time.Now())
+	now := r.clock.Now()
+	asset.SetUpdatedAt(now)
 	r.assets[asset.GetID()] = asset
+	r.touch(asset.GetID())
 
 	// Update in all user favorites
 	for userID := range r.favorites {
 		if favorite, exists := r.favorites[userID][asset.GetID()]; exists {
 			favorite.Asset = asset
-			favorite.UpdatedAt = time.Now()
+			favorite.UpdatedAt = now
 		}
 	}
 
@@ -73,40 +147,53 @@ func (r *Repository) UpdateAsset(asset domain.Asset) error {
 }
 
 func (r *Repository) DeleteAsset(assetID string) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	if _, exists := r.assets[assetID]; !exists {
 		return domain.ErrAssetNotFound
 	}
 
-	delete(r.assets, assetID)
-
-	// Remove from all user favorites
-	for userID := range r.favorites {
-		delete(r.favorites[userID], assetID)
-	}
-
+	r.removeAssetLocked(assetID)
 	return nil
 }
 
+// ListAssets pages through assets in ID order, so pages are stable across calls
 func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
-	r.mu.RLock()
+	r.rlock()
 	defer r.mu.RUnlock()
 
+	ids := make([]string, 0, len(r.assets))
+	for id := range r.assets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
 	var assets []domain.Asset
-	count := 0
+	for i := offset; i < len(ids) && len(assets) < limit; i++ {
+		assets = append(assets, r.assets[ids[i]])
+	}
 
-	for _, asset := range r.assets {
-		if count < offset {
-			count++
-			continue
-		}
-		if len(assets) >= limit {
-			break
+	return assets, nil
+}
+
+// SearchAssets pages through the assets matching the query's type and
+// search terms, in ID order
+func (r *Repository) SearchAssets(query domain.AssetQuery) ([]domain.Asset, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	var matching []string
+	for id, asset := range r.assets {
+		if query.Matches(asset) {
+			matching = append(matching, id)
 		}
-		assets = append(assets, asset)
-		count++
+	}
+	sort.Strings(matching)
+
+	var assets []domain.Asset
+	for i := query.Offset; i < len(matching) && len(assets) < query.Limit; i++ {
+		assets = append(assets, r.assets[matching[i]])
 	}
 
 	return assets, nil
@@ -114,7 +201,7 @@ func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
 
 // User operations
 func (r *Repository) CreateUser(user *domain.User) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	r.users[user.ID] = user
@@ -125,7 +212,7 @@ func (r *Repository) CreateUser(user *domain.User) error {
 }
 
 func (r *Repository) GetUser(userID string) (*domain.User, error) {
-	r.mu.RLock()
+	r.rlock()
 	defer r.mu.RUnlock()
 
 	user, exists := r.users[userID]
@@ -136,9 +223,55 @@ func (r *Repository) GetUser(userID string) (*domain.User, error) {
 	return user, nil
 }
 
+// ListUserIDs returns up to limit user IDs after the given ID, in order
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.users))
+	for id := range r.users {
+		if id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// DeleteUser removes the user and all of their favorites
+func (r *Repository) DeleteUser(userID string) error {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return domain.ErrUserNotFound
+	}
+
+	for assetID := range r.favorites[userID] {
+		r.countFavoriteLocked(assetID, -1)
+	}
+	delete(r.favorites, userID)
+	delete(r.collections, userID)
+	delete(r.users, userID)
+	return nil
+}
+
 // Favorites operations
 func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
-	r.mu.Lock()
+	return r.addFavorite(userID, asset, r.clock.Now())
+}
+
+// ImportFavorite adds a favorite as if it had been added at addedAt
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	return r.addFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) addFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	r.lock()
 	defer r.mu.Unlock()
 
 	// Ensure user exists
@@ -161,15 +294,26 @@ func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
 		return domain.ErrFavoriteAlreadyExists
 	}
 
+	// Make room by evicting least recently used assets along with their favorites
+	for r.opts.MaxFavorites > 0 && r.favoriteCount >= r.opts.MaxFavorites {
+		// Only assets holding favorites free room; evicting the others
+		// would empty the catalog without making any
+		if !r.evictLocked(asset.GetID(), true) {
+			return domain.ErrMaxFavoritesReached
+		}
+	}
+
 	// Add to favorites
-	favorite := domain.NewUserFavorite(userID, asset)
+	favorite := domain.NewUserFavoriteAt(userID, asset, addedAt)
 	r.favorites[userID][asset.GetID()] = favorite
+	r.countFavoriteLocked(asset.GetID(), 1)
+	r.touch(asset.GetID())
 
 	return nil
 }
 
 func (r *Repository) RemoveFavorite(userID, assetID string) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	// Check if user exists
@@ -183,11 +327,13 @@ func (r *Repository) RemoveFavorite(userID, assetID string) error {
 	}
 
 	delete(r.favorites[userID], assetID)
+	r.uncollectLocked(userID, assetID)
+	r.countFavoriteLocked(assetID, -1)
 	return nil
 }
 
-func (r *Repository) GetUserFavorites(userID string, limit, offset int) ([]*domain.UserFavorite, error) {
-	r.mu.RLock()
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	r.rlock()
 	defer r.mu.RUnlock()
 
 	// Check if user exists
@@ -200,26 +346,56 @@ func (r *Repository) GetUserFavorites(userID string, limit, offset int) ([]*doma
 		return []*domain.UserFavorite{}, nil
 	}
 
-	var favorites []*domain.UserFavorite
-	count := 0
-
+	all := make([]*domain.UserFavorite, 0, len(userFavorites))
 	for _, favorite := range userFavorites {
-		if count < offset {
+		all = append(all, favorite)
+	}
+
+	page := repository.ApplyFavoritesQuery(all, query)
+	for _, favorite := range page {
+		r.touch(favorite.AssetID)
+	}
+
+	return page, nil
+}
+
+// GetFavorite returns one of the user's favorites
+func (r *Repository) GetFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	favorite, exists := r.favorites[userID][assetID]
+	if !exists {
+		return nil, domain.ErrFavoriteNotFound
+	}
+
+	r.touch(assetID)
+	return favorite, nil
+}
+
+// CountUserFavorites counts the user's favorites matching the query's filters
+func (r *Repository) CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return 0, domain.ErrUserNotFound
+	}
+
+	count := 0
+	for _, favorite := range r.favorites[userID] {
+		if query.Matches(favorite) {
 			count++
-			continue
 		}
-		if len(favorites) >= limit {
-			break
-		}
-		favorites = append(favorites, favorite)
-		count++
 	}
-
-	return favorites, nil
+	return count, nil
 }
 
 func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
-	r.mu.RLock()
+	r.rlock()
 	defer r.mu.RUnlock()
 
 	if userFavorites := r.favorites[userID]; userFavorites != nil {
@@ -231,7 +407,7 @@ func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
 }
 
 func (r *Repository) GetFavoriteCount(userID string) (int, error) {
-	r.mu.RLock()
+	r.rlock()
 	defer r.mu.RUnlock()
 
 	if userFavorites := r.favorites[userID]; userFavorites != nil {
@@ -242,7 +418,7 @@ func (r *Repository) GetFavoriteCount(userID string) (int, error) {
 }
 
 func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
-	r.mu.Lock()
+	r.lock()
 	defer r.mu.Unlock()
 
 	// Check if user exists
@@ -258,10 +434,133 @@ func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.As
 
 	// Update the asset in the favorite
 	favorite.Asset = asset
-	favorite.UpdatedAt = time.Now()
+	favorite.UpdatedAt = r.clock.Now()
 
 	return nil
 }
 
+// UpdateFavoriteNote sets the user's note on a favorite and returns the
+// note it replaced
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return "", domain.ErrUserNotFound
+	}
+
+	favorite, exists := r.favorites[userID][assetID]
+	if !exists {
+		return "", domain.ErrFavoriteNotFound
+	}
+
+	previous := favorite.Note
+	favorite.Note = note
+	favorite.UpdatedAt = r.clock.Now()
+
+	return previous, nil
+}
+
+// removeAssetLocked deletes an asset and every favorite pointing at it.
+// The caller must hold the write lock.
+func (r *Repository) removeAssetLocked(assetID string) {
+	delete(r.assets, assetID)
+
+	// Remove from all user favorites
+	for userID := range r.favorites {
+		if _, exists := r.favorites[userID][assetID]; exists {
+			delete(r.favorites[userID], assetID)
+			r.uncollectLocked(userID, assetID)
+			r.countFavoriteLocked(assetID, -1)
+		}
+	}
+
+	r.lruMu.Lock()
+	if element, exists := r.lruIndex[assetID]; exists {
+		r.lru.Remove(element)
+		delete(r.lruIndex, assetID)
+	}
+	r.lruMu.Unlock()
+}
+
+// evictLocked removes the least recently used asset other than keep, or
+// with favoritedOnly the least recently used one that someone favorited.
+// It reports whether anything was evicted. The caller must hold the write lock.
+func (r *Repository) evictLocked(keep string, favoritedOnly bool) bool {
+	r.lruMu.Lock()
+	var victim string
+	for element := r.lru.Back(); element != nil; element = element.Prev() {
+		assetID := element.Value.(string)
+		if assetID != keep && (!favoritedOnly || r.favoriters[assetID] > 0) {
+			victim = assetID
+			break
+		}
+	}
+	r.lruMu.Unlock()
+
+	if victim == "" {
+		return false
+	}
+
+	r.removeAssetLocked(victim)
+	r.evictions++
+	return true
+}
+
+// countFavoriteLocked records delta favorites of the asset being added or
+// removed, keeping the totals eviction and reference counts rely on. The
+// caller must hold the write lock.
+func (r *Repository) countFavoriteLocked(assetID string, delta int) {
+	r.favoriteCount += delta
+	if count := r.favoriters[assetID] + delta; count > 0 {
+		r.favoriters[assetID] = count
+	} else {
+		delete(r.favoriters, assetID)
+	}
+}
+
+// touch marks an asset as most recently used
+func (r *Repository) touch(assetID string) {
+	r.lruMu.Lock()
+	defer r.lruMu.Unlock()
+
+	if element, exists := r.lruIndex[assetID]; exists {
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.lruIndex[assetID] = r.lru.PushFront(assetID)
+}
+
+// CountAssetReferences returns how many users have favorited the asset
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	return r.favoriters[assetID], nil
+}
+
+// ListAssetFavoriters returns the users who favorited the asset
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	userIDs := []string{}
+	for userID, favorites := range r.favorites {
+		if _, exists := favorites[assetID]; exists {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
 // Ensure Repository implements the interface
-var _ repository.FavoritesRepository = (*Repository)(nil)
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.UserEraser          = (*Repository)(nil)
+	_ repository.FavoritesCounter    = (*Repository)(nil)
+	_ repository.FavoriteGetter      = (*Repository)(nil)
+	_ repository.AssetSearcher       = (*Repository)(nil)
+)
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// snapshotVersion identifies the snapshot file layout
+const snapshotVersion = 1
+
+// snapshot is the on-disk representation of the repository contents.
+// Assets use the versioned storage encoding so older snapshots keep loading.
+type snapshot struct {
+	Version   int               `json:"version"`
+	SavedAt   time.Time         `json:"saved_at"`
+	Users     []*domain.User    `json:"users"`
+	Assets    []json.RawMessage `json:"assets"`
+	Favorites []favoriteRecord  `json:"favorites"`
+	// Collections were added after version 1 shipped and may be missing
+	Collections []collectionRecord `json:"collections,omitempty"`
+}
+
+type collectionRecord struct {
+	domain.Collection
+	Items map[string]time.Time `json:"items"`
+}
+
+type favoriteRecord struct {
+	UserID    string    `json:"user_id"`
+	AssetID   string    `json:"asset_id"`
+	Note      string    `json:"note,omitempty"`
+	Pinned    bool      `json:"pinned,omitempty"`
+	Position  int       `json:"position,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveSnapshot writes the repository contents to path. The file is written
+// next to path and renamed into place, so a crash never leaves a partial snapshot.
+func (r *Repository) SaveSnapshot(path string) error {
+	data, err := r.encodeSnapshot()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (r *Repository) encodeSnapshot() ([]byte, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	snap := snapshot{
+		Version:   snapshotVersion,
+		SavedAt:   r.clock.Now(),
+		Users:     make([]*domain.User, 0, len(r.users)),
+		Assets:    make([]json.RawMessage, 0, len(r.assets)),
+		Favorites: make([]favoriteRecord, 0, r.favoriteCount),
+	}
+
+	for _, user := range r.users {
+		snap.Users = append(snap.Users, user)
+	}
+	for _, asset := range r.assets {
+		data, err := repository.EncodeAsset(asset)
+		if err != nil {
+			return nil, err
+		}
+		snap.Assets = append(snap.Assets, data)
+	}
+	for userID, favorites := range r.favorites {
+		for assetID, favorite := range favorites {
+			snap.Favorites = append(snap.Favorites, favoriteRecord{
+				UserID:    userID,
+				AssetID:   assetID,
+				Note:      favorite.Note,
+				Pinned:    favorite.Pinned,
+				Position:  favorite.Position,
+				AddedAt:   favorite.AddedAt,
+				UpdatedAt: favorite.UpdatedAt,
+			})
+		}
+	}
+
+	for _, collections := range r.collections {
+		for _, c := range collections {
+			snap.Collections = append(snap.Collections, collectionRecord{Collection: c.Collection, Items: c.items})
+		}
+	}
+
+	return json.Marshal(snap)
+}
+
+// LoadSnapshot replaces the repository contents with the snapshot at path.
+// It reports false without error when no snapshot exists yet.
+func (r *Repository) LoadSnapshot(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return false, fmt.Errorf("reading snapshot %s: %w", path, err)
+	}
+	if snap.Version > snapshotVersion {
+		return false, fmt.Errorf("snapshot %s has version %d, newer than supported version %d", path, snap.Version, snapshotVersion)
+	}
+
+	assets := make(map[string]domain.Asset, len(snap.Assets))
+	for _, raw := range snap.Assets {
+		asset, err := repository.DecodeAsset(raw)
+		if err != nil {
+			return false, fmt.Errorf("reading snapshot %s: %w", path, err)
+		}
+		assets[asset.GetID()] = asset
+	}
+
+	r.lock()
+	defer r.mu.Unlock()
+
+	r.assets = assets
+	r.users = make(map[string]*domain.User, len(snap.Users))
+	r.favorites = make(map[string]map[string]*domain.UserFavorite, len(snap.Users))
+	r.favoriteCount = 0
+	r.favoriters = make(map[string]int)
+	for _, user := range snap.Users {
+		r.users[user.ID] = user
+		r.favorites[user.ID] = make(map[string]*domain.UserFavorite)
+	}
+
+	for _, record := range snap.Favorites {
+		asset, exists := assets[record.AssetID]
+		if !exists || r.favorites[record.UserID] == nil {
+			continue
+		}
+		r.favorites[record.UserID][record.AssetID] = &domain.UserFavorite{
+			UserID:    record.UserID,
+			AssetID:   record.AssetID,
+			Asset:     asset,
+			Note:      record.Note,
+			Pinned:    record.Pinned,
+			Position:  record.Position,
+			AddedAt:   record.AddedAt,
+			UpdatedAt: record.UpdatedAt,
+		}
+		r.countFavoriteLocked(record.AssetID, 1)
+	}
+
+	r.collections = make(map[string]map[string]*collection)
+	for _, record := range snap.Collections {
+		if r.favorites[record.UserID] == nil {
+			continue
+		}
+		c := &collection{Collection: record.Collection, items: make(map[string]time.Time, len(record.Items))}
+		for assetID, addedAt := range record.Items {
+			if _, exists := r.favorites[record.UserID][assetID]; exists {
+				c.items[assetID] = addedAt
+			}
+		}
+		if r.collections[record.UserID] == nil {
+			r.collections[record.UserID] = make(map[string]*collection)
+		}
+		r.collections[record.UserID][c.ID] = c
+	}
+
+	r.lruMu.Lock()
+	r.lru.Init()
+	r.lruIndex = make(map[string]*list.Element, len(assets))
+	r.lruMu.Unlock()
+	for assetID := range assets {
+		r.touch(assetID)
+	}
+
+	return true, nil
+}
+
+// StartSnapshots saves a snapshot to path every interval until the returned
+// stop function is called. Stop takes a final snapshot, so it belongs in the
+// graceful shutdown path. Errors from periodic saves are passed to onError.
+func (r *Repository) StartSnapshots(path string, interval time.Duration, onError func(error)) (stop func() error) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.SaveSnapshot(path); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			err = r.SaveSnapshot(path)
+		})
+		return err
+	}
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// Rough per-entry overheads used when estimating memory usage
+const (
+	mapEntryOverhead = 48
+	favoriteBytes    = 128
+)
+
+// lockStats counts lock acquisitions and how many of them had to wait
+type lockStats struct {
+	reads          uint64
+	writes         uint64
+	readContended  uint64
+	writeContended uint64
+}
+
+// lock acquires the write lock, recording whether it was contended
+func (r *Repository) lock() {
+	atomic.AddUint64(&r.lockStats.writes, 1)
+	if !r.mu.TryLock() {
+		atomic.AddUint64(&r.lockStats.writeContended, 1)
+		r.mu.Lock()
+	}
+}
+
+// rlock acquires the read lock, recording whether it was contended
+func (r *Repository) rlock() {
+	atomic.AddUint64(&r.lockStats.reads, 1)
+	if !r.mu.TryRLock() {
+		atomic.AddUint64(&r.lockStats.readContended, 1)
+		r.mu.RLock()
+	}
+}
+
+// StorageStats reports entry counts, an estimate of the memory held and lock contention
+func (r *Repository) StorageStats() (repository.StorageStats, error) {
+	r.rlock()
+	defer r.mu.RUnlock()
+
+	return repository.StorageStats{
+		Backend:        "memory",
+		Users:          len(r.users),
+		Assets:         len(r.assets),
+		Favorites:      r.favoriteCount,
+		EstimatedBytes: r.estimateBytesLocked(),
+		Details: map[string]interface{}{
+			"max_assets":    r.opts.MaxAssets,
+			"max_favorites": r.opts.MaxFavorites,
+			"evictions":     r.evictions,
+			"lock": map[string]uint64{
+				"read_acquisitions":  atomic.LoadUint64(&r.lockStats.reads),
+				"read_contended":     atomic.LoadUint64(&r.lockStats.readContended),
+				"write_acquisitions": atomic.LoadUint64(&r.lockStats.writes),
+				"write_contended":    atomic.LoadUint64(&r.lockStats.writeContended),
+			},
+			"last_compaction": r.lastCompaction,
+		},
+	}, nil
+}
+
+// Compact rebuilds the internal maps, which never shrink after deletes in Go,
+// and drops per-user favorite maps and recency entries that are no longer needed
+func (r *Repository) Compact() (repository.CompactionResult, error) {
+	start := time.Now()
+
+	r.lock()
+	defer r.mu.Unlock()
+
+	result := repository.CompactionResult{BytesBefore: r.estimateBytesLocked()}
+
+	assets := make(map[string]domain.Asset, len(r.assets))
+	for id, asset := range r.assets {
+		assets[id] = asset
+	}
+	r.assets = assets
+
+	users := make(map[string]*domain.User, len(r.users))
+	for id, user := range r.users {
+		users[id] = user
+	}
+	r.users = users
+
+	favorites := make(map[string]map[string]*domain.UserFavorite, len(r.favorites))
+	for userID, userFavorites := range r.favorites {
+		// Empty maps are recreated lazily on the next AddFavorite
+		if len(userFavorites) == 0 {
+			result.Removed++
+			continue
+		}
+		compacted := make(map[string]*domain.UserFavorite, len(userFavorites))
+		for assetID, favorite := range userFavorites {
+			compacted[assetID] = favorite
+		}
+		favorites[userID] = compacted
+	}
+	r.favorites = favorites
+
+	r.lruMu.Lock()
+	for element := r.lru.Front(); element != nil; {
+		next := element.Next()
+		if assetID := element.Value.(string); r.assets[assetID] == nil {
+			r.lru.Remove(element)
+			delete(r.lruIndex, assetID)
+			result.Removed++
+		}
+		element = next
+	}
+	r.lruMu.Unlock()
+
+	r.lastCompaction = r.clock.Now()
+	result.BytesAfter = r.estimateBytesLocked()
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// estimateBytesLocked approximates the memory held by stored entries using
+// their JSON size plus a fixed per-entry overhead. The caller must hold a lock.
+func (r *Repository) estimateBytesLocked() int64 {
+	var total int64
+
+	for id, asset := range r.assets {
+		total += int64(len(id) + mapEntryOverhead + jsonSize(asset))
+	}
+	for id, user := range r.users {
+		total += int64(len(id) + mapEntryOverhead + jsonSize(user))
+	}
+	for userID, userFavorites := range r.favorites {
+		total += int64(len(userID) + mapEntryOverhead)
+		for assetID := range userFavorites {
+			total += int64(len(assetID) + mapEntryOverhead + favoriteBytes)
+		}
+	}
+
+	return total
+}
+
+func jsonSize(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// Ensure Repository can report on its storage
+var _ repository.StorageInspector = (*Repository)(nil)
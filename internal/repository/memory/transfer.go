@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"sort"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoritesTransferer = (*Repository)(nil)
+
+func (r *Repository) TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	r.lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[fromUserID]; !exists {
+		return repository.TransferResult{}, domain.ErrUserNotFound
+	}
+	if _, exists := r.users[toUserID]; !exists {
+		return repository.TransferResult{}, domain.ErrUserNotFound
+	}
+	source := r.favorites[fromUserID]
+	if assetIDs == nil {
+		for assetID := range source {
+			assetIDs = append(assetIDs, assetID)
+		}
+	} else {
+		assetIDs = append([]string(nil), assetIDs...)
+	}
+	for _, assetID := range assetIDs {
+		if _, exists := source[assetID]; !exists {
+			return repository.TransferResult{}, domain.ErrFavoriteNotFound
+		}
+	}
+	sort.Strings(assetIDs)
+
+	result := repository.TransferResult{Transferred: []string{}, Skipped: []string{}}
+	for _, assetID := range assetIDs {
+		if _, exists := r.favorites[toUserID][assetID]; exists {
+			result.Skipped = append(result.Skipped, assetID)
+		} else {
+			result.Transferred = append(result.Transferred, assetID)
+		}
+	}
+	// Copies are not made room for by eviction, which could drop the very
+	// assets being copied
+	if !move && r.opts.MaxFavorites > 0 && r.favoriteCount+len(result.Transferred) > r.opts.MaxFavorites {
+		return repository.TransferResult{}, domain.ErrMaxFavoritesReached
+	}
+
+	if r.favorites[toUserID] == nil {
+		r.favorites[toUserID] = make(map[string]*domain.UserFavorite)
+	}
+	for _, assetID := range result.Transferred {
+		favorite := *source[assetID]
+		favorite.UserID = toUserID
+		favorite.Pinned = false
+		favorite.Position = 0
+		r.favorites[toUserID][assetID] = &favorite
+		r.countFavoriteLocked(assetID, 1)
+	}
+	if move {
+		for _, assetID := range assetIDs {
+			delete(source, assetID)
+			r.uncollectLocked(fromUserID, assetID)
+			r.countFavoriteLocked(assetID, -1)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gwi-favorites-service/internal/repository/sqlstore"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// duplicateEntry is the MySQL error number for duplicate keys
+const duplicateEntry = 1062
+
+// Dialect adapts the shared SQL repository to MySQL
+type Dialect struct{}
+
+func (Dialect) Name() string             { return "mysql" }
+func (Dialect) Schema() []string         { return schema }
+func (Dialect) Placeholder(n int) string { return sqlstore.QuestionPlaceholder(n) }
+func (Dialect) ShareLock() string        { return " LOCK IN SHARE MODE" }
+
+func (Dialect) UpsertUser() string {
+	return `INSERT INTO users (id, email, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE email = VALUES(email), name = VALUES(name), updated_at = VALUES(updated_at)`
+}
+
+// searchText is the searchable text of an asset row; CONCAT_WS skips
+// fields the asset type does not have
+const searchText = `CONCAT_WS(' ', a.data->>'$.title', a.data->>'$.description', a.data->>'$.content', a.data->>'$.tags')`
+
+func (Dialect) SearchCondition(terms []string) (string, []interface{}) {
+	return sqlstore.LikeSearch(searchText, terms)
+}
+
+func (Dialect) SortTitle() string {
+	return `LOWER(COALESCE(a.data->>'$.title', a.data->>'$.content', a.data->>'$.description', ''))`
+}
+
+func (Dialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *driver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntry
+}
+
+// NewRepository creates a MySQL repository using an existing connection pool.
+// The pool must be opened with parseTime=true.
+func NewRepository(db *sql.DB, opts ...sqlstore.Option) *sqlstore.Repository {
+	return sqlstore.New(db, Dialect{}, opts...)
+}
+
+// Open connects to MySQL using dsn, verifies the connection and ensures the schema exists.
+// Timestamps are always parsed and stored in UTC, whatever the DSN says.
+func Open(dsn string, opts ...sqlstore.Option) (*sqlstore.Repository, error) {
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+
+	return sqlstore.Open("mysql", cfg.FormatDSN(), Dialect{}, opts...)
+}
@@ -0,0 +1,72 @@
+package mysql
+
+// schema is applied on startup; every statement is idempotent. MySQL has no
+// CREATE INDEX IF NOT EXISTS, so indexes are declared with their tables.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+	id         VARCHAR(255) NOT NULL PRIMARY KEY,
+	email      VARCHAR(255) NOT NULL DEFAULT '',
+	name       VARCHAR(255) NOT NULL DEFAULT '',
+	created_at DATETIME(6)  NOT NULL,
+	updated_at DATETIME(6)  NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS assets (
+	id         VARCHAR(255) NOT NULL PRIMARY KEY,
+	type       VARCHAR(32)  NOT NULL,
+	data       JSON         NOT NULL,
+	created_at DATETIME(6)  NOT NULL,
+	updated_at DATETIME(6)  NOT NULL,
+	INDEX assets_type_idx (type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS favorites (
+	user_id    VARCHAR(255) NOT NULL,
+	asset_id   VARCHAR(255) NOT NULL,
+	added_at   DATETIME(6)  NOT NULL,
+	updated_at DATETIME(6)  NOT NULL,
+	PRIMARY KEY (user_id, asset_id),
+	INDEX favorites_user_added_idx (user_id, added_at, asset_id),
+	INDEX favorites_asset_idx (asset_id),
+	CONSTRAINT favorites_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+	CONSTRAINT favorites_asset_fk FOREIGN KEY (asset_id) REFERENCES assets (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS collections (
+	id         VARCHAR(64)  NOT NULL PRIMARY KEY,
+	user_id    VARCHAR(255) NOT NULL,
+	name       VARCHAR(255) NOT NULL,
+	created_at DATETIME(6)  NOT NULL,
+	updated_at DATETIME(6)  NOT NULL,
+	UNIQUE INDEX collections_user_name_idx (user_id, name),
+	CONSTRAINT collections_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS collection_items (
+	collection_id VARCHAR(64)  NOT NULL,
+	user_id       VARCHAR(255) NOT NULL,
+	asset_id      VARCHAR(255) NOT NULL,
+	added_at      DATETIME(6)  NOT NULL,
+	PRIMARY KEY (collection_id, asset_id),
+	INDEX collection_items_favorite_idx (user_id, asset_id),
+	CONSTRAINT collection_items_collection_fk FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE,
+	CONSTRAINT collection_items_favorite_fk FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS favorite_notes (
+	user_id  VARCHAR(255) NOT NULL,
+	asset_id VARCHAR(255) NOT NULL,
+	note     TEXT         NOT NULL,
+	PRIMARY KEY (user_id, asset_id),
+	CONSTRAINT favorite_notes_favorite_fk FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+	`CREATE TABLE IF NOT EXISTS favorite_order (
+	user_id  VARCHAR(255) NOT NULL,
+	asset_id VARCHAR(255) NOT NULL,
+	position INT          NOT NULL DEFAULT 0,
+	pinned   TINYINT      NOT NULL DEFAULT 0,
+	PRIMARY KEY (user_id, asset_id),
+	CONSTRAINT favorite_order_favorite_fk FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+}
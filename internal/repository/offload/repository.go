@@ -0,0 +1,427 @@
+// Package offload keeps large chart data out of the primary store
+package offload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// DefaultThreshold is the encoded size above which chart data is offloaded
+const DefaultThreshold = 64 * 1024
+
+// Options configures offloading
+type Options struct {
+	// Threshold is the encoded size of a chart's data, in bytes, above which
+	// it is moved to the blob store; zero uses DefaultThreshold
+	Threshold int
+	// OnError is called when a blob that is no longer referenced cannot be
+	// deleted. The write that replaced it has succeeded, so the error is
+	// reported rather than returned.
+	OnError func(err error)
+}
+
+// Repository is a FavoritesRepository that moves the data of large charts
+// to an AssetBlobStore and stores the chart with a DataRef in its place. The
+// catalog, favorites listings and any cache in front of the backend stay
+// small; callers that need the points load them with LoadChartData.
+//
+// Blobs are keyed by asset ID and content hash, so rewriting unchanged data
+// does not upload it again, and a blob is deleted once its asset is deleted
+// or updated with other data.
+type Repository struct {
+	repository.FavoritesRepository
+	blobs     repository.AssetBlobStore
+	threshold int
+	onError   func(err error)
+}
+
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.ChartDataLoader     = (*Repository)(nil)
+	_ repository.StorageInspector    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.UserEraser          = (*Repository)(nil)
+	_ repository.UserLister          = (*Repository)(nil)
+	_ repository.Pinger              = (*Repository)(nil)
+	_ repository.FavoritesCounter    = (*Repository)(nil)
+	_ repository.FavoriteGetter      = (*Repository)(nil)
+	_ repository.FavoritesSummarizer = (*Repository)(nil)
+	_ repository.AssetSearcher       = (*Repository)(nil)
+	_ repository.CollectionStore     = (*Repository)(nil)
+	_ repository.FavoriteOrderStore  = (*Repository)(nil)
+	_ repository.FavoritesTransferer = (*Repository)(nil)
+	_ repository.IntegrityChecker    = (*Repository)(nil)
+)
+
+// New wraps backend, offloading chart data to blobs
+func New(backend repository.FavoritesRepository, blobs repository.AssetBlobStore, opts Options) *Repository {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(error) {}
+	}
+	return &Repository{
+		FavoritesRepository: backend,
+		blobs:               blobs,
+		threshold:           opts.Threshold,
+		onError:             opts.OnError,
+	}
+}
+
+// blobPrefix is the common prefix of an asset's blob keys
+func blobPrefix(assetID string) string {
+	return "charts/" + url.PathEscape(assetID) + "/"
+}
+
+// blobKey names the blob holding an asset's data with the given hash
+func blobKey(assetID, sum string) string {
+	return blobPrefix(assetID) + sum + ".json"
+}
+
+// offload returns the asset to store: charts with large data are replaced
+// by a copy referencing the data in the blob store. The caller's asset is
+// never modified.
+func (r *Repository) offload(asset domain.Asset) (domain.Asset, error) {
+	chart, ok := asset.(*domain.Chart)
+	if !ok {
+		return asset, nil
+	}
+
+	if len(chart.Data) == 0 {
+		// A reference can only be kept for this asset's own blobs, so a
+		// client cannot point a chart at another asset's data
+		if chart.DataRef != nil && !r.ownsRef(chart.ID, *chart.DataRef) {
+			stored := *chart
+			stored.DataRef = nil
+			return &stored, nil
+		}
+		return asset, nil
+	}
+
+	data, err := json.Marshal(chart.Data)
+	if err != nil {
+		return nil, err
+	}
+	stored := *chart
+	if len(data) <= r.threshold {
+		stored.DataRef = nil
+		return &stored, nil
+	}
+
+	sum := sha256.Sum256(data)
+	ref := domain.BlobRef{
+		Key:    blobKey(chart.ID, hex.EncodeToString(sum[:])),
+		Size:   len(data),
+		Points: len(chart.Data),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+	// Data loaded from an existing blob is written back without uploading
+	if chart.DataRef == nil || *chart.DataRef != ref {
+		if err := r.blobs.Put(ref.Key, data); err != nil {
+			return nil, fmt.Errorf("storing chart data: %w", err)
+		}
+	}
+
+	stored.Data = nil
+	stored.DataRef = &ref
+	return &stored, nil
+}
+
+// ownsRef reports whether ref names one of the asset's own blobs: the one
+// it references now, or the key offload would give data with ref's hash.
+// Keys are compared whole, so "charts/a/../b/..." cannot pass for a's.
+func (r *Repository) ownsRef(assetID string, ref domain.BlobRef) bool {
+	if current := r.currentRef(assetID); current != nil && *current == ref {
+		return true
+	}
+	sum, err := hex.DecodeString(ref.SHA256)
+	return err == nil && len(sum) == sha256.Size && ref.Key == blobKey(assetID, ref.SHA256)
+}
+
+// currentRef returns the blob reference of the stored asset, if any
+func (r *Repository) currentRef(assetID string) *domain.BlobRef {
+	existing, err := r.FavoritesRepository.GetAsset(assetID)
+	if err != nil {
+		return nil
+	}
+	if chart, ok := existing.(*domain.Chart); ok {
+		return chart.DataRef
+	}
+	return nil
+}
+
+// release deletes a blob that is no longer referenced
+func (r *Repository) release(previous *domain.BlobRef, current domain.Asset) {
+	if previous == nil {
+		return
+	}
+	if chart, ok := current.(*domain.Chart); ok && chart.DataRef != nil && chart.DataRef.Key == previous.Key {
+		return
+	}
+	if err := r.blobs.Delete(previous.Key); err != nil {
+		r.onError(fmt.Errorf("deleting chart data %s: %w", previous.Key, err))
+	}
+}
+
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	stored, err := r.offload(asset)
+	if err != nil {
+		return err
+	}
+	return r.FavoritesRepository.CreateAsset(stored)
+}
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	previous := r.currentRef(asset.GetID())
+	stored, err := r.offload(asset)
+	if err != nil {
+		return err
+	}
+	if err := r.FavoritesRepository.UpdateAsset(stored); err != nil {
+		return err
+	}
+	r.release(previous, stored)
+	return nil
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	previous := r.currentRef(assetID)
+	if err := r.FavoritesRepository.DeleteAsset(assetID); err != nil {
+		return err
+	}
+	r.release(previous, nil)
+	return nil
+}
+
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	stored, err := r.offload(asset)
+	if err != nil {
+		return err
+	}
+	return r.FavoritesRepository.AddFavorite(userID, stored)
+}
+
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	importer, ok := r.FavoritesRepository.(repository.FavoriteImporter)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	stored, err := r.offload(asset)
+	if err != nil {
+		return err
+	}
+	return importer.ImportFavorite(userID, stored, addedAt)
+}
+
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	stored, err := r.offload(asset)
+	if err != nil {
+		return err
+	}
+	return r.FavoritesRepository.UpdateFavoriteAsset(userID, assetID, stored)
+}
+
+// LoadChartData reads offloaded data and checks it against the reference
+func (r *Repository) LoadChartData(ref domain.BlobRef) ([]domain.ChartDataPoint, error) {
+	data, err := r.blobs.Get(ref.Key)
+	if err != nil {
+		return nil, fmt.Errorf("loading chart data %s: %w", ref.Key, err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != ref.SHA256 {
+		return nil, fmt.Errorf("chart data %s does not match its checksum", ref.Key)
+	}
+
+	var points []domain.ChartDataPoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("decoding chart data %s: %w", ref.Key, err)
+	}
+	return points, nil
+}
+
+// Optional capabilities are forwarded to the backend
+
+func (r *Repository) StorageStats() (repository.StorageStats, error) {
+	inspector, ok := r.FavoritesRepository.(repository.StorageInspector)
+	if !ok {
+		return repository.StorageStats{}, domain.ErrNotSupported
+	}
+	return inspector.StorageStats()
+}
+
+func (r *Repository) Compact() (repository.CompactionResult, error) {
+	inspector, ok := r.FavoritesRepository.(repository.StorageInspector)
+	if !ok {
+		return repository.CompactionResult{}, domain.ErrNotSupported
+	}
+	return inspector.Compact()
+}
+
+func (r *Repository) CheckIntegrity(repair bool) (repository.IntegrityReport, error) {
+	checker, ok := r.FavoritesRepository.(repository.IntegrityChecker)
+	if !ok {
+		return repository.IntegrityReport{}, domain.ErrNotSupported
+	}
+	return checker.CheckIntegrity(repair)
+}
+
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	references, ok := r.FavoritesRepository.(repository.AssetReferences)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return references.CountAssetReferences(assetID)
+}
+
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	references, ok := r.FavoritesRepository.(repository.AssetReferences)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return references.ListAssetFavoriters(assetID)
+}
+
+func (r *Repository) DeleteUser(userID string) error {
+	eraser, ok := r.FavoritesRepository.(repository.UserEraser)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return eraser.DeleteUser(userID)
+}
+
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	lister, ok := r.FavoritesRepository.(repository.UserLister)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return lister.ListUserIDs(after, limit)
+}
+
+func (r *Repository) Ping(ctx context.Context) error {
+	pinger, ok := r.FavoritesRepository.(repository.Pinger)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return pinger.Ping(ctx)
+}
+
+func (r *Repository) CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error) {
+	counter, ok := r.FavoritesRepository.(repository.FavoritesCounter)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return counter.CountUserFavorites(userID, query)
+}
+
+func (r *Repository) GetFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	getter, ok := r.FavoritesRepository.(repository.FavoriteGetter)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return getter.GetFavorite(userID, assetID)
+}
+
+func (r *Repository) FavoriteStats(userID string, weeks []time.Time) (repository.FavoriteStats, error) {
+	reporter, ok := r.FavoritesRepository.(repository.FavoritesSummarizer)
+	if !ok {
+		return repository.FavoriteStats{}, domain.ErrNotSupported
+	}
+	return reporter.FavoriteStats(userID, weeks)
+}
+
+func (r *Repository) SearchAssets(query domain.AssetQuery) ([]domain.Asset, error) {
+	searcher, ok := r.FavoritesRepository.(repository.AssetSearcher)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return searcher.SearchAssets(query)
+}
+
+func (r *Repository) CreateCollection(c *domain.Collection) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.CreateCollection(c)
+}
+
+func (r *Repository) GetCollection(userID, collectionID string) (*domain.Collection, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollection(userID, collectionID)
+}
+
+func (r *Repository) ListCollections(userID string) ([]*domain.Collection, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.ListCollections(userID)
+}
+
+func (r *Repository) DeleteCollection(userID, collectionID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.DeleteCollection(userID, collectionID)
+}
+
+func (r *Repository) AddToCollection(userID, collectionID, assetID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.AddToCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) RemoveFromCollection(userID, collectionID, assetID string) error {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.RemoveFromCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	store, ok := r.FavoritesRepository.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollectionFavorites(userID, collectionID, query)
+}
+
+func (r *Repository) ReorderFavorites(userID string, assetIDs []string) error {
+	store, ok := r.FavoritesRepository.(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.ReorderFavorites(userID, assetIDs)
+}
+
+func (r *Repository) SetFavoritePinned(userID, assetID string, pinned bool) error {
+	store, ok := r.FavoritesRepository.(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.SetFavoritePinned(userID, assetID, pinned)
+}
+
+func (r *Repository) TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	transferer, ok := r.FavoritesRepository.(repository.FavoritesTransferer)
+	if !ok {
+		return repository.TransferResult{}, domain.ErrNotSupported
+	}
+	return transferer.TransferFavorites(fromUserID, toUserID, assetIDs, move)
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"gwi-favorites-service/internal/repository/sqlstore"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code for duplicate keys
+const uniqueViolation = "23505"
+
+// Dialect adapts the shared SQL repository to PostgreSQL
+type Dialect struct{}
+
+func (Dialect) Name() string             { return "postgres" }
+func (Dialect) Schema() []string         { return schema }
+func (Dialect) Placeholder(n int) string { return sqlstore.DollarPlaceholder(n) }
+func (Dialect) ShareLock() string        { return " FOR SHARE" }
+
+func (Dialect) UpsertUser() string {
+	return `INSERT INTO users (id, email, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, name = EXCLUDED.name, updated_at = EXCLUDED.updated_at`
+}
+
+// SearchCondition uses full-text search backed by assets_search_idx. Terms
+// match whole words rather than substrings.
+func (Dialect) SearchCondition(terms []string) (string, []interface{}) {
+	return searchDocument("a.data") + ` @@ plainto_tsquery('simple', ?)`, []interface{}{strings.Join(terms, " ")}
+}
+
+func (Dialect) SortTitle() string {
+	return `lower(coalesce(a.data->>'title', a.data->>'content', a.data->>'description', ''))`
+}
+
+func (Dialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// NewRepository creates a PostgreSQL repository using an existing connection pool
+func NewRepository(db *sql.DB, opts ...sqlstore.Option) *sqlstore.Repository {
+	return sqlstore.New(db, Dialect{}, opts...)
+}
+
+// Open connects to PostgreSQL using dsn, verifies the connection and ensures the schema exists
+func Open(dsn string, opts ...sqlstore.Option) (*sqlstore.Repository, error) {
+	return sqlstore.Open("postgres", dsn, Dialect{}, opts...)
+}
@@ -0,0 +1,78 @@
+package postgres
+
+// schema is applied on startup; every statement is idempotent
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+	id         TEXT PRIMARY KEY,
+	email      TEXT NOT NULL DEFAULT '',
+	name       TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+)`,
+
+	`CREATE TABLE IF NOT EXISTS assets (
+	id         TEXT PRIMARY KEY,
+	type       TEXT NOT NULL,
+	data       JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+)`,
+
+	`CREATE TABLE IF NOT EXISTS favorites (
+	user_id    TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	asset_id   TEXT NOT NULL REFERENCES assets (id) ON DELETE CASCADE,
+	added_at   TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, asset_id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS collections (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name       TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (user_id, name)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS collection_items (
+	collection_id TEXT NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+	user_id       TEXT NOT NULL,
+	asset_id      TEXT NOT NULL,
+	added_at      TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (collection_id, asset_id),
+	FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+)`,
+
+	`CREATE TABLE IF NOT EXISTS favorite_notes (
+	user_id  TEXT NOT NULL,
+	asset_id TEXT NOT NULL,
+	note     TEXT NOT NULL,
+	PRIMARY KEY (user_id, asset_id),
+	FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+)`,
+
+	`CREATE TABLE IF NOT EXISTS favorite_order (
+	user_id  TEXT    NOT NULL,
+	asset_id TEXT    NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	pinned   INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (user_id, asset_id),
+	FOREIGN KEY (user_id, asset_id) REFERENCES favorites (user_id, asset_id) ON DELETE CASCADE
+)`,
+
+	`CREATE INDEX IF NOT EXISTS favorites_user_added_idx ON favorites (user_id, added_at, asset_id)`,
+	`CREATE INDEX IF NOT EXISTS favorites_asset_idx ON favorites (asset_id)`,
+	`CREATE INDEX IF NOT EXISTS assets_type_idx ON assets (type)`,
+	`CREATE INDEX IF NOT EXISTS collection_items_favorite_idx ON collection_items (user_id, asset_id)`,
+	`CREATE INDEX IF NOT EXISTS assets_search_idx ON assets USING GIN (` + searchDocument("data") + `)`,
+}
+
+// searchDocument is the full-text document of an asset. Queries must use
+// the same expression as assets_search_idx for the index to apply, and it
+// must be immutable, which rules out concat_ws.
+func searchDocument(column string) string {
+	field := func(name string) string { return "coalesce(" + column + "->>'" + name + "', '')" }
+	return "to_tsvector('simple', " + field("title") + " || ' ' || " + field("description") + " || ' ' || " +
+		field("content") + " || ' ' || " + field("tags") + ")"
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// ApplyFavoritesQuery filters, orders and paginates favorites in memory.
+// It is shared by backends that cannot evaluate queries natively. Favorites
+// are ordered by the query's sort field, then AddedAt, then AssetID, so
+// offsets are stable between calls.
+func ApplyFavoritesQuery(favorites []*domain.UserFavorite, query domain.FavoritesQuery) []*domain.UserFavorite {
+	// Filter before paginating so each asset type pages independently
+	matched := make([]*domain.UserFavorite, 0, len(favorites))
+	for _, favorite := range favorites {
+		if query.Matches(favorite) {
+			matched = append(matched, favorite)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		// Pinned favorites lead in either direction
+		if matched[i].Pinned != matched[j].Pinned {
+			return matched[i].Pinned
+		}
+		c := compareFavorites(matched[i], matched[j], query.Sort)
+		if query.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if query.Offset >= len(matched) {
+		return []*domain.UserFavorite{}
+	}
+
+	end := len(matched)
+	if query.Limit > 0 && query.Offset+query.Limit < end {
+		end = query.Offset + query.Limit
+	}
+
+	return matched[query.Offset:end]
+}
+
+// compareFavorites orders two favorites by field, breaking ties by AddedAt
+// and then AssetID
+func compareFavorites(a, b *domain.UserFavorite, field domain.SortField) int {
+	switch field {
+	case domain.SortUpdatedAt:
+		if c := compareTimes(a.UpdatedAt, b.UpdatedAt); c != 0 {
+			return c
+		}
+	case domain.SortType:
+		if c := strings.Compare(string(assetType(a)), string(assetType(b))); c != 0 {
+			return c
+		}
+	case domain.SortTitle:
+		if c := strings.Compare(sortTitle(a), sortTitle(b)); c != 0 {
+			return c
+		}
+	case domain.SortPosition:
+		if c := positionKey(a) - positionKey(b); c != 0 {
+			return c
+		}
+	}
+	if c := compareTimes(a.AddedAt, b.AddedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.AssetID, b.AssetID)
+}
+
+// positionKey places favorites without a position after all others
+func positionKey(favorite *domain.UserFavorite) int {
+	if favorite.Position <= 0 {
+		return math.MaxInt32
+	}
+	return favorite.Position
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func assetType(favorite *domain.UserFavorite) domain.AssetType {
+	if favorite.Asset == nil {
+		return ""
+	}
+	return favorite.Asset.GetType()
+}
+
+func sortTitle(favorite *domain.UserFavorite) string {
+	if favorite.Asset == nil {
+		return ""
+	}
+	return domain.AssetSortTitle(favorite.Asset)
+}
@@ -0,0 +1,542 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Options configures the Redis connection
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+	// KeyPrefix namespaces all keys so several deployments can share a server
+	KeyPrefix string
+	// Clock stamps favorites and asset updates; nil means the wall clock
+	Clock clock.Clock
+}
+
+// Repository implements FavoritesRepository on top of Redis.
+//
+// Keys (all prefixed with Options.KeyPrefix):
+//
+//	asset:{assetID}          string, asset JSON
+//	assets                   sorted set of asset IDs scored by creation time
+//	user:{userID}            string, user JSON
+//	users                    set of user IDs
+//	favorites:{userID}       hash, assetID -> favorite metadata JSON
+//	favorited_by:{assetID}   set of user IDs that favorited the asset
+type Repository struct {
+	client *goredis.Client
+	prefix string
+	clock  clock.Clock
+}
+
+// favoriteMeta is the per-favorite data stored in the user's favorites hash
+type favoriteMeta struct {
+	Note      string    `json:"note,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewRepository creates a repository using an existing client
+func NewRepository(client *goredis.Client, keyPrefix string) *Repository {
+	return &Repository{client: client, prefix: keyPrefix, clock: clock.System}
+}
+
+// Open connects to Redis and verifies the connection
+func Open(opts Options) (*Repository, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	repo := NewRepository(client, opts.KeyPrefix)
+	repo.clock = clock.OrSystem(opts.Clock)
+	return repo, nil
+}
+
+// Close closes the client
+func (r *Repository) Close() error {
+	return r.client.Close()
+}
+
+// Ping checks the Redis server is reachable
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *Repository) assetKey(assetID string) string    { return r.prefix + "asset:" + assetID }
+func (r *Repository) assetsKey() string                 { return r.prefix + "assets" }
+func (r *Repository) userKey(userID string) string      { return r.prefix + "user:" + userID }
+func (r *Repository) usersKey() string                  { return r.prefix + "users" }
+func (r *Repository) favoritesKey(userID string) string { return r.prefix + "favorites:" + userID }
+func (r *Repository) favoritedByKey(assetID string) string {
+	return r.prefix + "favorited_by:" + assetID
+}
+
+// Asset operations
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	ctx := context.Background()
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	created, err := r.client.SetNX(ctx, r.assetKey(asset.GetID()), data, 0).Result()
+	if err != nil {
+		return err
+	}
+	if !created {
+		return domain.ErrAssetAlreadyExists
+	}
+
+	score := float64(asset.GetCreatedAt().UnixNano())
+	return r.client.ZAdd(ctx, r.assetsKey(), goredis.Z{Score: score, Member: asset.GetID()}).Err()
+}
+
+func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
+	data, err := r.client.Get(context.Background(), r.assetKey(assetID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, domain.ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.DecodeAsset(data)
+}
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	ctx := context.Background()
+
+	now := r.clock.Now()
+	asset.SetUpdatedAt(now)
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	// XX only replaces an existing asset
+	updated, err := r.client.SetXX(ctx, r.assetKey(asset.GetID()), data, 0).Result()
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return domain.ErrAssetNotFound
+	}
+
+	// Update in all user favorites
+	userIDs, err := r.client.SMembers(ctx, r.favoritedByKey(asset.GetID())).Result()
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := r.touchFavorite(ctx, userID, asset.GetID(), now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	ctx := context.Background()
+
+	deleted, err := r.client.Del(ctx, r.assetKey(assetID)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return domain.ErrAssetNotFound
+	}
+
+	// Remove from all user favorites
+	userIDs, err := r.client.SMembers(ctx, r.favoritedByKey(assetID)).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZRem(ctx, r.assetsKey(), assetID)
+		for _, userID := range userIDs {
+			pipe.HDel(ctx, r.favoritesKey(userID), assetID)
+		}
+		pipe.Del(ctx, r.favoritedByKey(assetID))
+		return nil
+	})
+	return err
+}
+
+func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
+	ctx := context.Background()
+
+	ids, err := r.client.ZRange(ctx, r.assetsKey(), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := r.loadAssets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]domain.Asset, 0, len(ids))
+	for _, id := range ids {
+		if asset, exists := assets[id]; exists {
+			list = append(list, asset)
+		}
+	}
+	return list, nil
+}
+
+// User operations
+func (r *Repository) CreateUser(user *domain.User) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, r.userKey(user.ID), data, 0)
+		pipe.SAdd(ctx, r.usersKey(), user.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *Repository) GetUser(userID string) (*domain.User, error) {
+	data, err := r.client.Get(context.Background(), r.userKey(userID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user domain.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUserIDs returns up to limit user IDs after the given ID, in order.
+// The users set is unordered, so each call reads and sorts all of it.
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	members, err := r.client.SMembers(context.Background(), r.usersKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(members)
+
+	start := sort.SearchStrings(members, after)
+	if start < len(members) && members[start] == after {
+		start++
+	}
+	end := start + limit
+	if end > len(members) {
+		end = len(members)
+	}
+	return members[start:end], nil
+}
+
+// Favorites operations
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	return r.addFavorite(userID, asset, r.clock.Now())
+}
+
+// ImportFavorite adds a favorite as if it had been added at addedAt
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	return r.addFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) addFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	ctx := context.Background()
+
+	// Ensure user exists
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// Ensure asset exists
+	exists, err := r.client.Exists(ctx, r.assetKey(asset.GetID())).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return domain.ErrAssetNotFound
+	}
+
+	favorite := domain.NewUserFavoriteAt(userID, asset, addedAt)
+	meta, err := json.Marshal(favoriteMeta{AddedAt: favorite.AddedAt, UpdatedAt: favorite.UpdatedAt})
+	if err != nil {
+		return err
+	}
+
+	// HSETNX makes the duplicate check and the insert a single atomic step
+	added, err := r.client.HSetNX(ctx, r.favoritesKey(userID), asset.GetID(), meta).Result()
+	if err != nil {
+		return err
+	}
+	if !added {
+		return domain.ErrFavoriteAlreadyExists
+	}
+
+	return r.client.SAdd(ctx, r.favoritedByKey(asset.GetID()), userID).Err()
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	ctx := context.Background()
+
+	// Check if user exists
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	removed, err := r.client.HDel(ctx, r.favoritesKey(userID), assetID).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return domain.ErrFavoriteNotFound
+	}
+
+	return r.client.SRem(ctx, r.favoritedByKey(assetID), userID).Err()
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	ctx := context.Background()
+
+	// Check if user exists
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.client.HGetAll(ctx, r.favoritesKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []*domain.UserFavorite{}, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	for assetID := range entries {
+		ids = append(ids, assetID)
+	}
+
+	assets, err := r.loadAssets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	favorites := make([]*domain.UserFavorite, 0, len(entries))
+	for assetID, raw := range entries {
+		asset, exists := assets[assetID]
+		if !exists {
+			// The asset was deleted concurrently; skip the dangling favorite
+			continue
+		}
+
+		var meta favoriteMeta
+		if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+			return nil, err
+		}
+
+		favorites = append(favorites, &domain.UserFavorite{
+			UserID:    userID,
+			AssetID:   assetID,
+			Asset:     asset,
+			Note:      meta.Note,
+			AddedAt:   meta.AddedAt,
+			UpdatedAt: meta.UpdatedAt,
+		})
+	}
+
+	return repository.ApplyFavoritesQuery(favorites, query), nil
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	return r.client.HExists(context.Background(), r.favoritesKey(userID), assetID).Result()
+}
+
+func (r *Repository) GetFavoriteCount(userID string) (int, error) {
+	count, err := r.client.HLen(context.Background(), r.favoritesKey(userID)).Result()
+	return int(count), err
+}
+
+// UpdateFavoriteAsset stores the asset and touches the favorite. Favorites
+// reference the shared asset, so the change is visible to every user.
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	ctx := context.Background()
+
+	// Check if user exists
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	exists, err := r.client.HExists(ctx, r.favoritesKey(userID), assetID).Result()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrFavoriteNotFound
+	}
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+	if err := r.client.Set(ctx, r.assetKey(assetID), data, 0).Err(); err != nil {
+		return err
+	}
+
+	return r.touchFavorite(ctx, userID, assetID, r.clock.Now())
+}
+
+// UpdateFavoriteNote sets the user's note on a favorite and returns the
+// note it replaced
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	ctx := context.Background()
+
+	// Check if user exists
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return "", err
+	}
+
+	raw, err := r.client.HGet(ctx, r.favoritesKey(userID), assetID).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", domain.ErrFavoriteNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var meta favoriteMeta
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return "", err
+	}
+	previous := meta.Note
+	meta.Note = note
+	meta.UpdatedAt = r.clock.Now()
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	if err := r.client.HSet(ctx, r.favoritesKey(userID), assetID, data).Err(); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+// CountAssetReferences returns how many users have favorited the asset
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	count, err := r.client.SCard(context.Background(), r.favoritedByKey(assetID)).Result()
+	return int(count), err
+}
+
+// ListAssetFavoriters returns the users who favorited the asset
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	userIDs, err := r.client.SMembers(context.Background(), r.favoritedByKey(assetID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// ensureUser returns ErrUserNotFound if the user does not exist
+func (r *Repository) ensureUser(ctx context.Context, userID string) error {
+	exists, err := r.client.Exists(ctx, r.userKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// touchFavorite sets the UpdatedAt of a favorite if it still exists
+func (r *Repository) touchFavorite(ctx context.Context, userID, assetID string, now time.Time) error {
+	raw, err := r.client.HGet(ctx, r.favoritesKey(userID), assetID).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var meta favoriteMeta
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return err
+	}
+	meta.UpdatedAt = now
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, r.favoritesKey(userID), assetID, data).Err()
+}
+
+// loadAssets fetches several assets in one round trip, omitting missing ones
+func (r *Repository) loadAssets(ctx context.Context, ids []string) (map[string]domain.Asset, error) {
+	assets := make(map[string]domain.Asset, len(ids))
+	if len(ids) == 0 {
+		return assets, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.assetKey(id)
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		asset, err := repository.DecodeAsset([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		assets[ids[i]] = asset
+	}
+
+	return assets, nil
+}
+
+// Ensure Repository implements the interface
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.Pinger              = (*Repository)(nil)
+)
@@ -0,0 +1,475 @@
+package sharded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// virtualNodes is the number of ring positions per shard. More positions
+// spread users more evenly at the cost of a larger ring.
+const virtualNodes = 128
+
+// Shard is one backend holding a subset of users
+type Shard struct {
+	// Name places the shard on the ring; it must stay the same across
+	// restarts, or users move to other shards
+	Name string
+	Repo repository.FavoritesRepository
+}
+
+type ringNode struct {
+	hash  uint64
+	shard int
+}
+
+// Repository is a FavoritesRepository that spreads users over several
+// backends by consistent hashing of the user ID. A user's favorites live on
+// one shard. The asset catalog is small and favorites need it locally, so
+// every shard holds a full copy: asset writes go to all shards and asset
+// reads are served by the first.
+//
+// Adding a shard moves about 1/N of the users to it. Their data is not
+// moved automatically.
+type Repository struct {
+	shards []Shard
+	ring   []ringNode
+}
+
+// New creates a sharded repository over shards, which must have unique names
+func New(shards []Shard) (*Repository, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("sharded repository needs at least one shard")
+	}
+
+	r := &Repository{shards: shards}
+	seen := make(map[string]bool, len(shards))
+	for i, shard := range shards {
+		if seen[shard.Name] {
+			return nil, fmt.Errorf("duplicate shard name %q", shard.Name)
+		}
+		seen[shard.Name] = true
+		for v := 0; v < virtualNodes; v++ {
+			r.ring = append(r.ring, ringNode{hash: hash(shard.Name + "#" + strconv.Itoa(v)), shard: i})
+		}
+	}
+	sort.Slice(r.ring, func(i, j int) bool { return r.ring[i].hash < r.ring[j].hash })
+
+	return r, nil
+}
+
+// ShardFor returns the name of the shard holding userID
+func (r *Repository) ShardFor(userID string) string {
+	return r.shards[r.locate(userID)].Name
+}
+
+func (r *Repository) locate(userID string) int {
+	h := hash(userID)
+	i := sort.Search(len(r.ring), func(i int) bool { return r.ring[i].hash >= h })
+	if i == len(r.ring) {
+		i = 0
+	}
+	return r.ring[i].shard
+}
+
+func (r *Repository) shard(userID string) repository.FavoritesRepository {
+	return r.shards[r.locate(userID)].Repo
+}
+
+// hash is FNV-1a followed by a 64-bit finalizer. FNV alone leaves the high
+// bits of similar keys such as user1, user2 close together, which would
+// cluster them on the ring.
+func hash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Asset operations
+
+// CreateAsset creates the asset on every shard. Only the first shard
+// decides whether it already exists, so a retry completes a partial write.
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	if err := r.shards[0].Repo.CreateAsset(asset); err != nil {
+		return err
+	}
+	for _, shard := range r.shards[1:] {
+		if err := shard.Repo.CreateAsset(asset); err != nil && !errors.Is(err, domain.ErrAssetAlreadyExists) {
+			return fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
+	return r.shards[0].Repo.GetAsset(assetID)
+}
+
+// UpdateAsset updates the asset on every shard, creating it on shards
+// that missed an earlier write
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	if err := r.shards[0].Repo.UpdateAsset(asset); err != nil {
+		return err
+	}
+	for _, shard := range r.shards[1:] {
+		err := shard.Repo.UpdateAsset(asset)
+		if errors.Is(err, domain.ErrAssetNotFound) {
+			err = shard.Repo.CreateAsset(asset)
+		}
+		if err != nil {
+			return fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteAsset deletes the asset, and the favorites holding it, on every shard
+func (r *Repository) DeleteAsset(assetID string) error {
+	if err := r.shards[0].Repo.DeleteAsset(assetID); err != nil {
+		return err
+	}
+	for _, shard := range r.shards[1:] {
+		if err := shard.Repo.DeleteAsset(assetID); err != nil && !errors.Is(err, domain.ErrAssetNotFound) {
+			return fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
+	return r.shards[0].Repo.ListAssets(limit, offset)
+}
+
+func (r *Repository) SearchAssets(query domain.AssetQuery) ([]domain.Asset, error) {
+	searcher, ok := r.shards[0].Repo.(repository.AssetSearcher)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return searcher.SearchAssets(query)
+}
+
+// User operations are served by the user's shard
+
+func (r *Repository) CreateUser(user *domain.User) error {
+	return r.shard(user.ID).CreateUser(user)
+}
+
+func (r *Repository) GetUser(userID string) (*domain.User, error) {
+	return r.shard(userID).GetUser(userID)
+}
+
+// Favorites operations
+
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	return r.shard(userID).AddFavorite(userID, asset)
+}
+
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	importer, ok := r.shard(userID).(repository.FavoriteImporter)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return importer.ImportFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	return r.shard(userID).RemoveFavorite(userID, assetID)
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	return r.shard(userID).GetUserFavorites(userID, query)
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	return r.shard(userID).IsFavorite(userID, assetID)
+}
+
+func (r *Repository) GetFavoriteCount(userID string) (int, error) {
+	return r.shard(userID).GetFavoriteCount(userID)
+}
+
+func (r *Repository) CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error) {
+	counter, ok := r.shard(userID).(repository.FavoritesCounter)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return counter.CountUserFavorites(userID, query)
+}
+
+func (r *Repository) GetFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	getter, ok := r.shard(userID).(repository.FavoriteGetter)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return getter.GetFavorite(userID, assetID)
+}
+
+func (r *Repository) FavoriteStats(userID string, weeks []time.Time) (repository.FavoriteStats, error) {
+	reporter, ok := r.shard(userID).(repository.FavoritesSummarizer)
+	if !ok {
+		return repository.FavoriteStats{}, domain.ErrNotSupported
+	}
+	return reporter.FavoriteStats(userID, weeks)
+}
+
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	return r.shard(userID).UpdateFavoriteAsset(userID, assetID, asset)
+}
+
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	return r.shard(userID).UpdateFavoriteNote(userID, assetID, note)
+}
+
+// Optional capabilities are available when every shard supports them
+
+func (r *Repository) DeleteUser(userID string) error {
+	eraser, ok := r.shard(userID).(repository.UserEraser)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return eraser.DeleteUser(userID)
+}
+
+// ListUserIDs merges the ordered user IDs of every shard
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	var ids []string
+	for _, shard := range r.shards {
+		lister, ok := shard.Repo.(repository.UserLister)
+		if !ok {
+			return nil, domain.ErrNotSupported
+		}
+		shardIDs, err := lister.ListUserIDs(after, limit)
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+		ids = append(ids, shardIDs...)
+	}
+
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// CountAssetReferences sums the references held on every shard
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	total := 0
+	for _, shard := range r.shards {
+		references, ok := shard.Repo.(repository.AssetReferences)
+		if !ok {
+			return 0, domain.ErrNotSupported
+		}
+		count, err := references.CountAssetReferences(assetID)
+		if err != nil {
+			return 0, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// ListAssetFavoriters merges the users found on every shard
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	userIDs := []string{}
+	for _, shard := range r.shards {
+		references, ok := shard.Repo.(repository.AssetReferences)
+		if !ok {
+			return nil, domain.ErrNotSupported
+		}
+		found, err := references.ListAssetFavoriters(assetID)
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+		userIDs = append(userIDs, found...)
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// StorageStats sums users and favorites over the shards. Assets are counted
+// once since every shard holds the catalog.
+func (r *Repository) StorageStats() (repository.StorageStats, error) {
+	total := repository.StorageStats{Backend: "sharded"}
+	perShard := make(map[string]interface{}, len(r.shards))
+
+	for i, shard := range r.shards {
+		inspector, ok := shard.Repo.(repository.StorageInspector)
+		if !ok {
+			return repository.StorageStats{}, domain.ErrNotSupported
+		}
+		stats, err := inspector.StorageStats()
+		if err != nil {
+			return repository.StorageStats{}, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+
+		total.Users += stats.Users
+		total.Favorites += stats.Favorites
+		total.EstimatedBytes += stats.EstimatedBytes
+		if i == 0 {
+			total.Assets = stats.Assets
+		}
+		perShard[shard.Name] = stats
+	}
+
+	total.Details = map[string]interface{}{"shards": perShard}
+	return total, nil
+}
+
+// Compact compacts every shard in turn
+func (r *Repository) Compact() (repository.CompactionResult, error) {
+	var total repository.CompactionResult
+	start := time.Now()
+
+	for _, shard := range r.shards {
+		inspector, ok := shard.Repo.(repository.StorageInspector)
+		if !ok {
+			return repository.CompactionResult{}, domain.ErrNotSupported
+		}
+		result, err := inspector.Compact()
+		if err != nil {
+			return repository.CompactionResult{}, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+		total.BytesBefore += result.BytesBefore
+		total.BytesAfter += result.BytesAfter
+		total.Removed += result.Removed
+	}
+
+	total.Duration = time.Since(start)
+	return total, nil
+}
+
+// CheckIntegrity checks every shard in turn. Each shard holds its own copy
+// of the catalog, so its favorites are checked against that copy.
+// Counters are named after the shard they drifted on.
+func (r *Repository) CheckIntegrity(repair bool) (repository.IntegrityReport, error) {
+	total := repository.NewIntegrityReport()
+	for _, shard := range r.shards {
+		checker, ok := shard.Repo.(repository.IntegrityChecker)
+		if !ok {
+			return repository.IntegrityReport{}, domain.ErrNotSupported
+		}
+		report, err := checker.CheckIntegrity(repair)
+		if err != nil {
+			return repository.IntegrityReport{}, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+		total.Merge(report, shard.Name+":")
+	}
+	return total, nil
+}
+
+// Ping pings every shard in turn, failing on the first unreachable one
+func (r *Repository) Ping(ctx context.Context) error {
+	for _, shard := range r.shards {
+		pinger, ok := shard.Repo.(repository.Pinger)
+		if !ok {
+			return domain.ErrNotSupported
+		}
+		if err := pinger.Ping(ctx); err != nil {
+			return fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+	}
+	return nil
+}
+
+// Collections belong to their user and live on the user's shard
+
+func (r *Repository) CreateCollection(c *domain.Collection) error {
+	store, ok := r.shard(c.UserID).(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.CreateCollection(c)
+}
+
+func (r *Repository) GetCollection(userID, collectionID string) (*domain.Collection, error) {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollection(userID, collectionID)
+}
+
+func (r *Repository) ListCollections(userID string) ([]*domain.Collection, error) {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.ListCollections(userID)
+}
+
+func (r *Repository) DeleteCollection(userID, collectionID string) error {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.DeleteCollection(userID, collectionID)
+}
+
+func (r *Repository) AddToCollection(userID, collectionID, assetID string) error {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.AddToCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) RemoveFromCollection(userID, collectionID, assetID string) error {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.RemoveFromCollection(userID, collectionID, assetID)
+}
+
+func (r *Repository) GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	store, ok := r.shard(userID).(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store.GetCollectionFavorites(userID, collectionID, query)
+}
+
+// Favorite order and pins live on the user's shard
+
+func (r *Repository) ReorderFavorites(userID string, assetIDs []string) error {
+	store, ok := r.shard(userID).(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.ReorderFavorites(userID, assetIDs)
+}
+
+func (r *Repository) SetFavoritePinned(userID, assetID string, pinned bool) error {
+	store, ok := r.shard(userID).(repository.FavoriteOrderStore)
+	if !ok {
+		return domain.ErrNotSupported
+	}
+	return store.SetFavoritePinned(userID, assetID, pinned)
+}
+
+// TransferFavorites is only atomic between users on the same shard;
+// transfers across shards are not supported
+func (r *Repository) TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	if r.locate(fromUserID) != r.locate(toUserID) {
+		return repository.TransferResult{}, domain.ErrNotSupported
+	}
+	transferer, ok := r.shard(fromUserID).(repository.FavoritesTransferer)
+	if !ok {
+		return repository.TransferResult{}, domain.ErrNotSupported
+	}
+	return transferer.TransferFavorites(fromUserID, toUserID, assetIDs, move)
+}
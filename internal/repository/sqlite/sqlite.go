@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"errors"
+	"net/url"
+
+	"gwi-favorites-service/internal/repository/sqlstore"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect adapts the shared SQL repository to SQLite
+type Dialect struct{}
+
+func (Dialect) Name() string             { return "sqlite" }
+func (Dialect) Schema() []string         { return schema }
+func (Dialect) Placeholder(n int) string { return sqlstore.QuestionPlaceholder(n) }
+
+// ShareLock is empty: SQLite serialises writers at the database level
+func (Dialect) ShareLock() string { return "" }
+
+func (Dialect) UpsertUser() string {
+	return `INSERT INTO users (id, email, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET email = excluded.email, name = excluded.name, updated_at = excluded.updated_at`
+}
+
+// searchText is the searchable text of an asset row. Assets are stored as
+// BLOBs, which SQLite would read as binary JSONB, so they are cast first.
+const searchText = `COALESCE(json_extract(CAST(a.data AS TEXT), '$.title'), '') || ' ' ||
+	COALESCE(json_extract(CAST(a.data AS TEXT), '$.description'), '') || ' ' ||
+	COALESCE(json_extract(CAST(a.data AS TEXT), '$.content'), '') || ' ' ||
+	COALESCE(json_extract(CAST(a.data AS TEXT), '$.tags'), '')`
+
+func (Dialect) SearchCondition(terms []string) (string, []interface{}) {
+	return sqlstore.LikeSearch(searchText, terms)
+}
+
+func (Dialect) SortTitle() string {
+	return `LOWER(COALESCE(json_extract(CAST(a.data AS TEXT), '$.title'),
+		json_extract(CAST(a.data AS TEXT), '$.content'),
+		json_extract(CAST(a.data AS TEXT), '$.description'), ''))`
+}
+
+func (Dialect) IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// Open opens (creating if needed) the database file at path and ensures the schema exists
+func Open(path string, opts ...sqlstore.Option) (*sqlstore.Repository, error) {
+	// Foreign keys are off by default in SQLite and are needed for cascading deletes
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	}.Encode()
+
+	repo, err := sqlstore.Open("sqlite", dsn, Dialect{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single connection avoids "database is locked" errors between concurrent writers
+	repo.DB().SetMaxOpenConns(1)
+	return repo, nil
+}
@@ -0,0 +1,192 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// Collected favorites live in collection_items, whose foreign key on
+// favorites removes them together with the favorite
+
+var _ repository.CollectionStore = (*Repository)(nil)
+
+// collectionColumns selects a collection with its favorite count; queries
+// using it group by the collection columns
+const collectionColumns = `SELECT c.id, c.user_id, c.name, c.created_at, c.updated_at, COUNT(i.asset_id)
+	FROM collections c LEFT JOIN collection_items i ON i.collection_id = c.id`
+
+const collectionGroupBy = ` GROUP BY c.id, c.user_id, c.name, c.created_at, c.updated_at`
+
+func (r *Repository) CreateCollection(c *domain.Collection) error {
+	if err := r.ensureUser(c.UserID); err != nil {
+		return err
+	}
+
+	_, err := r.exec(r.db,
+		`INSERT INTO collections (id, user_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		c.ID, c.UserID, c.Name, c.CreatedAt, c.UpdatedAt,
+	)
+	if r.dialect.IsUniqueViolation(err) {
+		return domain.ErrCollectionAlreadyExists
+	}
+	return err
+}
+
+func (r *Repository) GetCollection(userID, collectionID string) (*domain.Collection, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return nil, err
+	}
+
+	var c domain.Collection
+	err := r.queryRow(r.db, collectionColumns+` WHERE c.id = ? AND c.user_id = ?`+collectionGroupBy, collectionID, userID).
+		Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt, &c.UpdatedAt, &c.FavoriteCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListCollections returns the user's collections, oldest first
+func (r *Repository) ListCollections(userID string) ([]*domain.Collection, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.query(r.db, collectionColumns+` WHERE c.user_id = ?`+collectionGroupBy+` ORDER BY c.created_at, c.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*domain.Collection{}
+	for rows.Next() {
+		var c domain.Collection
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt, &c.UpdatedAt, &c.FavoriteCount); err != nil {
+			return nil, err
+		}
+		collections = append(collections, &c)
+	}
+	return collections, rows.Err()
+}
+
+func (r *Repository) DeleteCollection(userID, collectionID string) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+
+	result, err := r.exec(r.db, `DELETE FROM collections WHERE id = ? AND user_id = ?`, collectionID, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrCollectionNotFound)
+}
+
+func (r *Repository) AddToCollection(userID, collectionID, assetID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockCollection(tx, userID, collectionID); err != nil {
+		return err
+	}
+	var one int
+	err = r.queryRow(tx, `SELECT 1 FROM favorites WHERE user_id = ? AND asset_id = ?`+r.dialect.ShareLock(), userID, assetID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrFavoriteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	now := r.clock.Now()
+	_, err = r.exec(tx,
+		`INSERT INTO collection_items (collection_id, user_id, asset_id, added_at) VALUES (?, ?, ?, ?)`,
+		collectionID, userID, assetID, now,
+	)
+	if r.dialect.IsUniqueViolation(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.exec(tx, `UPDATE collections SET updated_at = ? WHERE id = ?`, now, collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) RemoveFromCollection(userID, collectionID, assetID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockCollection(tx, userID, collectionID); err != nil {
+		return err
+	}
+
+	result, err := r.exec(tx, `DELETE FROM collection_items WHERE collection_id = ? AND asset_id = ?`, collectionID, assetID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, domain.ErrNotInCollection); err != nil {
+		return err
+	}
+	if _, err := r.exec(tx, `UPDATE collections SET updated_at = ? WHERE id = ?`, r.clock.Now(), collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetCollectionFavorites(userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	if _, err := r.GetCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	where, filterArgs := r.favoritesFilter(userID, query)
+	statement := favoritesSelect + `
+		JOIN collection_items i ON i.user_id = f.user_id AND i.asset_id = f.asset_id AND i.collection_id = ?
+		WHERE ` + where
+	args := append([]interface{}{collectionID}, filterArgs...)
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	statement += ` ORDER BY ` + r.orderBy(query) + ` LIMIT ? OFFSET ?`
+	args = append(args, limit, query.Offset)
+
+	rows, err := r.query(r.db, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFavorites(rows)
+}
+
+// lockCollection checks the user's collection exists, locking it against
+// concurrent deletes
+func (r *Repository) lockCollection(tx *sql.Tx, userID, collectionID string) error {
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, userID, domain.ErrUserNotFound); err != nil {
+		return err
+	}
+
+	var one int
+	err := r.queryRow(tx, `SELECT 1 FROM collections WHERE id = ? AND user_id = ?`+r.dialect.ShareLock(), collectionID, userID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrCollectionNotFound
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package sqlstore
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect captures the differences between the SQL databases backing Repository.
+// Queries in this package are written with ? placeholders and rebound per dialect.
+type Dialect interface {
+	// Name identifies the database, e.g. "postgres"
+	Name() string
+	// Schema returns idempotent DDL statements, executed one at a time on startup
+	Schema() []string
+	// Placeholder returns the n-th (1-based) bind parameter marker
+	Placeholder(n int) string
+	// UpsertUser returns an INSERT for (id, email, name, created_at, updated_at)
+	// that overwrites email, name and updated_at when the user exists
+	UpsertUser() string
+	// ShareLock is appended to SELECTs that must block concurrent deletes, or is empty
+	ShareLock() string
+	// IsUniqueViolation reports whether err is a primary key or unique constraint violation
+	IsUniqueViolation(err error) bool
+	// SearchCondition returns a condition on the asset row aliased a that
+	// holds when its searchable text contains every term, with its bind
+	// arguments. Terms are lower-cased and non-empty.
+	SearchCondition(terms []string) (string, []interface{})
+	// SortTitle returns an expression on the asset row aliased a giving the
+	// lower-cased chart title, insight content or audience description
+	SortTitle() string
+}
+
+// likeEscape is the LIKE escape character used by LikeSearch. Backslash is
+// avoided since MySQL also treats it as a string literal escape.
+const likeEscape = "!"
+
+// LikeSearch builds a SearchCondition that matches each term as a substring
+// of the lower-cased SQL expression text. It cannot use an index, but runs
+// after the user's favorites have been selected by the primary key.
+func LikeSearch(text string, terms []string) (string, []interface{}) {
+	escaper := strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+	conditions := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		conditions[i] = "LOWER(" + text + ") LIKE ? ESCAPE '" + likeEscape + "'"
+		args[i] = "%" + escaper.Replace(term) + "%"
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// QuestionPlaceholder implements ? style bind parameters
+func QuestionPlaceholder(int) string { return "?" }
+
+// DollarPlaceholder implements $n style bind parameters
+func DollarPlaceholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// rebind replaces ? placeholders with the dialect's bind parameter markers
+func rebind(d Dialect, query string) string {
+	if d.Placeholder(1) == "?" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoritesSummarizer = (*Repository)(nil)
+
+func (r *Repository) FavoriteStats(userID string, weeks []time.Time) (repository.FavoriteStats, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return repository.FavoriteStats{}, err
+	}
+	stats := repository.NewFavoriteStats(weeks)
+
+	rows, err := r.query(r.db, `SELECT a.type, COUNT(*) FROM favorites f JOIN assets a ON a.id = f.asset_id
+		WHERE f.user_id = ? GROUP BY a.type`, userID)
+	if err != nil {
+		return repository.FavoriteStats{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var assetType string
+		var count int
+		if err := rows.Scan(&assetType, &count); err != nil {
+			return repository.FavoriteStats{}, err
+		}
+		stats.ByType[domain.AssetType(assetType)] = count
+		stats.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return repository.FavoriteStats{}, err
+	}
+	if len(weeks) == 0 {
+		return stats, nil
+	}
+
+	// One count per week, in a single pass over the user's recent favorites
+	columns := make([]string, len(weeks))
+	args := make([]interface{}, 0, 2*len(weeks)+2)
+	counts := make([]interface{}, len(weeks))
+	for i := range stats.Weekly {
+		columns[i] = `COUNT(CASE WHEN f.added_at >= ? AND f.added_at < ? THEN 1 END)`
+		start := stats.Weekly[i].Start
+		args = append(args, start, start.Add(7*24*time.Hour))
+		counts[i] = &stats.Weekly[i].Added
+	}
+	args = append(args, userID, weeks[0])
+	err = r.queryRow(r.db, `SELECT `+strings.Join(columns, ", ")+
+		` FROM favorites f WHERE f.user_id = ? AND f.added_at >= ?`, args...).Scan(counts...)
+	if err != nil {
+		return repository.FavoriteStats{}, err
+	}
+	return stats, nil
+}
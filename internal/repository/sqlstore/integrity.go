@@ -0,0 +1,112 @@
+package sqlstore
+
+import (
+	"fmt"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.IntegrityChecker = (*Repository)(nil)
+
+// missingAssetFavorites are the tables keyed by favorite, in the order
+// repairs delete from them. Foreign keys would cascade from favorites, but
+// favorites of missing assets mean they were not enforced.
+var missingAssetFavorites = []string{"favorite_notes", "favorite_order", "collection_items", "favorites"}
+
+// CheckIntegrity decodes every asset and looks for favorites whose asset
+// row is gone. Counts are computed by the database, so there is no counter
+// to drift.
+func (r *Repository) CheckIntegrity(repair bool) (repository.IntegrityReport, error) {
+	report := repository.NewIntegrityReport()
+	if err := r.checkAssets(&report); err != nil {
+		return report, err
+	}
+
+	if err := r.queryRow(r.db, `SELECT COUNT(*) FROM favorites`).Scan(&report.FavoritesChecked); err != nil {
+		return report, err
+	}
+	rows, err := r.query(r.db, `SELECT f.user_id, f.asset_id FROM favorites f
+		LEFT JOIN assets a ON a.id = f.asset_id WHERE a.id IS NULL ORDER BY f.user_id, f.asset_id`)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID, assetID string
+		if err := rows.Scan(&userID, &assetID); err != nil {
+			return report, err
+		}
+		report.AddMissingAsset(userID, assetID)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	if repair && report.MissingAssetCount > 0 {
+		removed, err := r.removeMissingAssetFavorites()
+		if err != nil {
+			return report, err
+		}
+		report.Repaired += removed
+	}
+	return report, nil
+}
+
+// checkAssets reports the assets that cannot be decoded or disagree with
+// the columns they are stored under
+func (r *Repository) checkAssets(report *repository.IntegrityReport) error {
+	rows, err := r.query(r.db, `SELECT id, type, data FROM assets ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, assetType string
+		var data []byte
+		if err := rows.Scan(&id, &assetType, &data); err != nil {
+			return err
+		}
+		report.AssetsChecked++
+
+		if !domain.AssetType(assetType).IsValid() {
+			report.AddInvalidAsset(id, fmt.Sprintf("unknown asset type %q", assetType))
+			continue
+		}
+		asset, err := repository.DecodeAsset(data)
+		switch {
+		case err != nil:
+			report.AddInvalidAsset(id, "undecodable: "+err.Error())
+		case asset.GetID() != id:
+			report.AddInvalidAsset(id, fmt.Sprintf("stored under another ID, %q", asset.GetID()))
+		case string(asset.GetType()) != assetType:
+			report.AddInvalidAsset(id, fmt.Sprintf("stored as type %q but is %q", assetType, asset.GetType()))
+		}
+	}
+	return rows.Err()
+}
+
+// removeMissingAssetFavorites deletes the favorites whose asset is gone and
+// returns how many there were
+func (r *Repository) removeMissingAssetFavorites() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var removed int64
+	for _, table := range missingAssetFavorites {
+		result, err := r.exec(tx, `DELETE FROM `+table+` WHERE NOT EXISTS (SELECT 1 FROM assets a WHERE a.id = `+table+`.asset_id)`)
+		if err != nil {
+			return 0, err
+		}
+		if table == "favorites" {
+			if removed, err = result.RowsAffected(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return int(removed), tx.Commit()
+}
@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoriteOrderStore = (*Repository)(nil)
+
+// Order rows exist only for favorites that are placed or pinned; the
+// listing queries treat a missing row as neither.
+
+func (r *Repository) ReorderFavorites(userID string, assetIDs []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, userID, domain.ErrUserNotFound); err != nil {
+		return err
+	}
+	for _, assetID := range assetIDs {
+		if err := r.lockFavorite(tx, userID, assetID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.exec(tx, `UPDATE favorite_order SET position = 0 WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	for i, assetID := range assetIDs {
+		if err := r.setOrder(tx, userID, assetID, "position", i+1); err != nil {
+			return err
+		}
+	}
+	if _, err := r.exec(tx, `DELETE FROM favorite_order WHERE user_id = ? AND position = 0 AND pinned = 0`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) SetFavoritePinned(userID, assetID string, pinned bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, userID, domain.ErrUserNotFound); err != nil {
+		return err
+	}
+	if err := r.lockFavorite(tx, userID, assetID); err != nil {
+		return err
+	}
+
+	value := 0
+	if pinned {
+		value = 1
+	}
+	if err := r.setOrder(tx, userID, assetID, "pinned", value); err != nil {
+		return err
+	}
+	if _, err := r.exec(tx,
+		`DELETE FROM favorite_order WHERE user_id = ? AND asset_id = ? AND position = 0 AND pinned = 0`,
+		userID, assetID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockFavorite checks the favorite exists, locking it against concurrent removal
+func (r *Repository) lockFavorite(tx *sql.Tx, userID, assetID string) error {
+	var one int
+	err := r.queryRow(tx,
+		`SELECT 1 FROM favorites WHERE user_id = ? AND asset_id = ?`+r.dialect.ShareLock(),
+		userID, assetID,
+	).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrFavoriteNotFound
+	}
+	return err
+}
+
+// setOrder sets one column of a favorite's order row, creating the row if
+// needed. column is position or pinned. The row is looked up rather than
+// inserted optimistically, since a failed insert aborts a Postgres transaction.
+func (r *Repository) setOrder(tx *sql.Tx, userID, assetID, column string, value int) error {
+	var one int
+	err := r.queryRow(tx, `SELECT 1 FROM favorite_order WHERE user_id = ? AND asset_id = ?`, userID, assetID).Scan(&one)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = r.exec(tx,
+			`INSERT INTO favorite_order (user_id, asset_id, `+column+`) VALUES (?, ?, ?)`,
+			userID, assetID, value,
+		)
+	case err == nil:
+		_, err = r.exec(tx,
+			`UPDATE favorite_order SET `+column+` = ? WHERE user_id = ? AND asset_id = ?`,
+			value, userID, assetID,
+		)
+	}
+	return err
+}
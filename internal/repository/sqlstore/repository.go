@@ -0,0 +1,728 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
+)
+
+// Repository implements FavoritesRepository on top of a SQL database.
+// Assets are stored as JSON documents next to their ID and type; favorites
+// reference users and assets with cascading foreign keys.
+type Repository struct {
+	db      *sql.DB
+	dialect Dialect
+	clock   clock.Clock
+
+	// statements caches prepared statements by query text
+	stmtMu     sync.Mutex
+	statements map[string]*sql.Stmt
+}
+
+// Option configures optional Repository behaviour
+type Option func(*Repository)
+
+// WithClock stamps favorites and asset updates with c instead of the wall clock
+func WithClock(c clock.Clock) Option {
+	return func(r *Repository) { r.clock = c }
+}
+
+// New creates a repository using an existing connection pool
+func New(db *sql.DB, dialect Dialect, opts ...Option) *Repository {
+	r := &Repository{db: db, dialect: dialect, clock: clock.System, statements: make(map[string]*sql.Stmt)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Open connects using driverName and dsn, verifies the connection and ensures the schema exists
+func Open(driverName, dsn string, dialect Dialect, opts ...Option) (*Repository, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repo := New(db, dialect, opts...)
+	if err := repo.EnsureSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// DB exposes the connection pool, e.g. for tuning pool limits
+func (r *Repository) DB() *sql.DB {
+	return r.db
+}
+
+// Ping checks the database is reachable
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close releases the prepared statements and the connection pool
+func (r *Repository) Close() error {
+	r.stmtMu.Lock()
+	for query, stmt := range r.statements {
+		stmt.Close()
+		delete(r.statements, query)
+	}
+	r.stmtMu.Unlock()
+
+	return r.db.Close()
+}
+
+// EnsureSchema creates the tables and indexes if they do not exist
+func (r *Repository) EnsureSchema() error {
+	for _, statement := range r.dialect.Schema() {
+		if _, err := r.db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Asset operations
+func (r *Repository) CreateAsset(asset domain.Asset) error {
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.exec(r.db,
+		`INSERT INTO assets (id, type, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		asset.GetID(), string(asset.GetType()), data, asset.GetCreatedAt(), asset.GetUpdatedAt(),
+	)
+	if r.dialect.IsUniqueViolation(err) {
+		return domain.ErrAssetAlreadyExists
+	}
+	return err
+}
+
+func (r *Repository) GetAsset(assetID string) (domain.Asset, error) {
+	var data []byte
+	err := r.queryRow(r.db, `SELECT data FROM assets WHERE id = ?`, assetID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.DecodeAsset(data)
+}
+
+func (r *Repository) UpdateAsset(asset domain.Asset) error {
+	now := r.clock.Now()
+	asset.SetUpdatedAt(now)
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := r.exec(tx,
+		`UPDATE assets SET type = ?, data = ?, updated_at = ? WHERE id = ?`,
+		string(asset.GetType()), data, now, asset.GetID(),
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, domain.ErrAssetNotFound); err != nil {
+		return err
+	}
+
+	// Update in all user favorites
+	if _, err := r.exec(tx, `UPDATE favorites SET updated_at = ? WHERE asset_id = ?`, now, asset.GetID()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) DeleteAsset(assetID string) error {
+	// Favorites are removed by the ON DELETE CASCADE foreign key
+	result, err := r.exec(r.db, `DELETE FROM assets WHERE id = ?`, assetID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrAssetNotFound)
+}
+
+func (r *Repository) ListAssets(limit, offset int) ([]domain.Asset, error) {
+	rows, err := r.query(r.db,
+		`SELECT data FROM assets ORDER BY created_at, id LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAssets(rows)
+}
+
+// SearchAssets pages through the assets matching the query's type and
+// search terms, in ListAssets order
+func (r *Repository) SearchAssets(query domain.AssetQuery) ([]domain.Asset, error) {
+	where := `1 = 1`
+	var args []interface{}
+	if query.Type != "" {
+		where += ` AND a.type = ?`
+		args = append(args, string(query.Type))
+	}
+	if terms := query.SearchTerms(); len(terms) > 0 {
+		condition, searchArgs := r.dialect.SearchCondition(terms)
+		where += ` AND ` + condition
+		args = append(args, searchArgs...)
+	}
+
+	rows, err := r.query(r.db,
+		`SELECT a.data FROM assets a WHERE `+where+` ORDER BY a.created_at, a.id LIMIT ? OFFSET ?`,
+		append(args, query.Limit, query.Offset)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAssets(rows)
+}
+
+// scanAssets decodes and closes rows of asset data
+func scanAssets(rows *sql.Rows) ([]domain.Asset, error) {
+	defer rows.Close()
+
+	var assets []domain.Asset
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		asset, err := repository.DecodeAsset(data)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, rows.Err()
+}
+
+// User operations
+func (r *Repository) CreateUser(user *domain.User) error {
+	_, err := r.exec(r.db, r.dialect.UpsertUser(),
+		user.ID, user.Email, user.Name, user.CreatedAt, user.UpdatedAt,
+	)
+	return err
+}
+
+func (r *Repository) GetUser(userID string) (*domain.User, error) {
+	var user domain.User
+	err := r.queryRow(r.db,
+		`SELECT id, email, name, created_at, updated_at FROM users WHERE id = ?`, userID,
+	).Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ListUserIDs returns up to limit user IDs after the given ID, in order
+func (r *Repository) ListUserIDs(after string, limit int) ([]string, error) {
+	rows, err := r.query(r.db, `SELECT id FROM users WHERE id > ? ORDER BY id LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteUser removes the user; their favorites are removed by the
+// ON DELETE CASCADE foreign key
+func (r *Repository) DeleteUser(userID string) error {
+	result, err := r.exec(r.db, `DELETE FROM users WHERE id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrUserNotFound)
+}
+
+// Favorites operations
+func (r *Repository) AddFavorite(userID string, asset domain.Asset) error {
+	return r.addFavorite(userID, asset, r.clock.Now())
+}
+
+// ImportFavorite adds a favorite as if it had been added at addedAt
+func (r *Repository) ImportFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	return r.addFavorite(userID, asset, addedAt)
+}
+
+func (r *Repository) addFavorite(userID string, asset domain.Asset, addedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ensure user and asset exist, locking them against concurrent deletes
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, userID, domain.ErrUserNotFound); err != nil {
+		return err
+	}
+	if err := r.lockRow(tx, `SELECT 1 FROM assets WHERE id = ?`, asset.GetID(), domain.ErrAssetNotFound); err != nil {
+		return err
+	}
+
+	favorite := domain.NewUserFavoriteAt(userID, asset, addedAt)
+	_, err = r.exec(tx,
+		`INSERT INTO favorites (user_id, asset_id, added_at, updated_at) VALUES (?, ?, ?, ?)`,
+		favorite.UserID, favorite.AssetID, favorite.AddedAt, favorite.UpdatedAt,
+	)
+	if r.dialect.IsUniqueViolation(err) {
+		return domain.ErrFavoriteAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) RemoveFavorite(userID, assetID string) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+
+	result, err := r.exec(r.db, `DELETE FROM favorites WHERE user_id = ? AND asset_id = ?`, userID, assetID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrFavoriteNotFound)
+}
+
+func (r *Repository) GetUserFavorites(userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return nil, err
+	}
+
+	where, args := r.favoritesFilter(userID, query)
+	statement := favoritesSelect + ` WHERE ` + where
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	statement += ` ORDER BY ` + r.orderBy(query) + ` LIMIT ? OFFSET ?`
+	args = append(args, limit, query.Offset)
+
+	rows, err := r.query(r.db, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFavorites(rows)
+}
+
+// GetFavorite returns one of the user's favorites
+func (r *Repository) GetFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.query(r.db, favoritesSelect+` WHERE f.user_id = ? AND f.asset_id = ?`, userID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	favorites, err := scanFavorites(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(favorites) == 0 {
+		return nil, domain.ErrFavoriteNotFound
+	}
+	return favorites[0], nil
+}
+
+// favoritesSelect selects favorites, aliased f, with their note, order and
+// asset data, as read by scanFavorites
+const favoritesSelect = `SELECT f.user_id, f.asset_id, COALESCE(n.note, ''), COALESCE(o.position, 0), COALESCE(o.pinned, 0),
+		f.added_at, f.updated_at, a.data
+	FROM favorites f JOIN assets a ON a.id = f.asset_id
+	LEFT JOIN favorite_notes n ON n.user_id = f.user_id AND n.asset_id = f.asset_id
+	LEFT JOIN favorite_order o ON o.user_id = f.user_id AND o.asset_id = f.asset_id`
+
+// scanFavorites reads favorites selected with favoritesSelect
+func scanFavorites(rows *sql.Rows) ([]*domain.UserFavorite, error) {
+	favorites := []*domain.UserFavorite{}
+	for rows.Next() {
+		var favorite domain.UserFavorite
+		var pinned int
+		var data []byte
+		if err := rows.Scan(&favorite.UserID, &favorite.AssetID, &favorite.Note, &favorite.Position, &pinned,
+			&favorite.AddedAt, &favorite.UpdatedAt, &data); err != nil {
+			return nil, err
+		}
+		favorite.Pinned = pinned != 0
+		asset, err := repository.DecodeAsset(data)
+		if err != nil {
+			return nil, err
+		}
+		favorite.Asset = asset
+		favorites = append(favorites, &favorite)
+	}
+
+	return favorites, rows.Err()
+}
+
+// CountUserFavorites counts the favorites GetUserFavorites would return
+// without a limit or offset
+func (r *Repository) CountUserFavorites(userID string, query domain.FavoritesQuery) (int, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return 0, err
+	}
+
+	where, args := r.favoritesFilter(userID, query)
+	var count int
+	err := r.queryRow(r.db, `SELECT COUNT(*) FROM favorites f JOIN assets a ON a.id = f.asset_id WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// favoritesFilter is the WHERE clause selecting a user's favorites that
+// match the query's type, time range and search filters
+func (r *Repository) favoritesFilter(userID string, query domain.FavoritesQuery) (string, []interface{}) {
+	where := `f.user_id = ?`
+	args := []interface{}{userID}
+
+	if query.Type != "" {
+		where += ` AND a.type = ?`
+		args = append(args, string(query.Type))
+	}
+	if !query.AddedSince.IsZero() {
+		where += ` AND f.added_at >= ?`
+		args = append(args, query.AddedSince)
+	}
+	if !query.AddedUntil.IsZero() {
+		where += ` AND f.added_at < ?`
+		args = append(args, query.AddedUntil)
+	}
+	if terms := query.SearchTerms(); len(terms) > 0 {
+		condition, searchArgs := r.dialect.SearchCondition(terms)
+		where += ` AND ` + condition
+		args = append(args, searchArgs...)
+	}
+	return where, args
+}
+
+// orderBy mirrors repository.ApplyFavoritesQuery: pinned favorites first,
+// then the sort field, added_at and asset_id, all in the query's direction.
+// It needs the favorite_order join of favoritesSelect.
+func (r *Repository) orderBy(query domain.FavoritesQuery) string {
+	direction := " ASC"
+	if query.Descending {
+		direction = " DESC"
+	}
+
+	var columns []string
+	switch query.Sort {
+	case domain.SortUpdatedAt:
+		columns = append(columns, "f.updated_at")
+	case domain.SortType:
+		columns = append(columns, "a.type")
+	case domain.SortTitle:
+		columns = append(columns, r.dialect.SortTitle())
+	case domain.SortPosition:
+		columns = append(columns, "COALESCE(NULLIF(o.position, 0), 2147483647)")
+	}
+	columns = append(columns, "f.added_at", "f.asset_id")
+
+	for i := range columns {
+		columns[i] += direction
+	}
+	return "COALESCE(o.pinned, 0) DESC, " + strings.Join(columns, ", ")
+}
+
+func (r *Repository) IsFavorite(userID, assetID string) (bool, error) {
+	var count int
+	err := r.queryRow(r.db,
+		`SELECT COUNT(*) FROM favorites WHERE user_id = ? AND asset_id = ?`,
+		userID, assetID,
+	).Scan(&count)
+	return count > 0, err
+}
+
+func (r *Repository) GetFavoriteCount(userID string) (int, error) {
+	var count int
+	err := r.queryRow(r.db, `SELECT COUNT(*) FROM favorites WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
+
+// UpdateFavoriteAsset stores the asset and touches the favorite. Favorites
+// reference the shared asset row, so the change is visible to every user.
+func (r *Repository) UpdateFavoriteAsset(userID, assetID string, asset domain.Asset) error {
+	if err := r.ensureUser(userID); err != nil {
+		return err
+	}
+
+	data, err := repository.EncodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.clock.Now()
+	result, err := r.exec(tx,
+		`UPDATE favorites SET updated_at = ? WHERE user_id = ? AND asset_id = ?`,
+		now, userID, assetID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, domain.ErrFavoriteNotFound); err != nil {
+		return err
+	}
+
+	if _, err := r.exec(tx,
+		`UPDATE assets SET type = ?, data = ?, updated_at = ? WHERE id = ?`,
+		string(asset.GetType()), data, now, assetID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateFavoriteNote sets the user's note on a favorite and returns the
+// note it replaced. Notes live in their own table, keyed by the favorite,
+// and an empty note deletes the row.
+func (r *Repository) UpdateFavoriteNote(userID, assetID, note string) (string, error) {
+	if err := r.ensureUser(userID); err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Touching the favorite first also locks it against concurrent edits
+	result, err := r.exec(tx,
+		`UPDATE favorites SET updated_at = ? WHERE user_id = ? AND asset_id = ?`,
+		r.clock.Now(), userID, assetID,
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := requireAffected(result, domain.ErrFavoriteNotFound); err != nil {
+		return "", err
+	}
+
+	var previous string
+	err = r.queryRow(tx, `SELECT note FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	if _, err := r.exec(tx, `DELETE FROM favorite_notes WHERE user_id = ? AND asset_id = ?`, userID, assetID); err != nil {
+		return "", err
+	}
+	if note != "" {
+		if _, err := r.exec(tx,
+			`INSERT INTO favorite_notes (user_id, asset_id, note) VALUES (?, ?, ?)`,
+			userID, assetID, note,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	return previous, tx.Commit()
+}
+
+// CountAssetReferences returns how many users have favorited the asset
+func (r *Repository) CountAssetReferences(assetID string) (int, error) {
+	var count int
+	err := r.queryRow(r.db, `SELECT COUNT(*) FROM favorites WHERE asset_id = ?`, assetID).Scan(&count)
+	return count, err
+}
+
+// ListAssetFavoriters returns the users who favorited the asset
+func (r *Repository) ListAssetFavoriters(assetID string) ([]string, error) {
+	rows, err := r.query(r.db, `SELECT user_id FROM favorites WHERE asset_id = ? ORDER BY user_id`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// row defers a statement preparation error to Scan, like *sql.Row does for query errors
+type row struct {
+	row *sql.Row
+	err error
+}
+
+func (r row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Scan(dest...)
+}
+
+// prepared returns a prepared statement for query. Statements are prepared
+// once on the pool and cached; inside a transaction a cached statement is
+// rebound to the transaction, otherwise it is prepared on the transaction's
+// own connection so a single-connection pool cannot deadlock.
+func (r *Repository) prepared(e execer, query string) (*sql.Stmt, error) {
+	query = rebind(r.dialect, query)
+
+	r.stmtMu.Lock()
+	stmt, ok := r.statements[query]
+	r.stmtMu.Unlock()
+
+	if tx, isTx := e.(*sql.Tx); isTx {
+		if ok {
+			return tx.Stmt(stmt), nil
+		}
+		return tx.Prepare(query)
+	}
+	if ok {
+		return stmt, nil
+	}
+
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	r.stmtMu.Lock()
+	defer r.stmtMu.Unlock()
+	if existing, ok := r.statements[query]; ok {
+		// Another goroutine prepared it first
+		stmt.Close()
+		return existing, nil
+	}
+	r.statements[query] = stmt
+	return stmt, nil
+}
+
+func (r *Repository) exec(e execer, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := r.prepared(e, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+func (r *Repository) query(e execer, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := r.prepared(e, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+func (r *Repository) queryRow(e execer, query string, args ...interface{}) row {
+	stmt, err := r.prepared(e, query)
+	if err != nil {
+		return row{err: err}
+	}
+	return row{row: stmt.QueryRow(args...)}
+}
+
+// ensureUser returns ErrUserNotFound if the user does not exist
+func (r *Repository) ensureUser(userID string) error {
+	var count int
+	if err := r.queryRow(r.db, `SELECT COUNT(*) FROM users WHERE id = ?`, userID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// lockRow runs a single-row query with the dialect's share lock, returning notFound when there is no row
+func (r *Repository) lockRow(tx *sql.Tx, query, id string, notFound error) error {
+	var one int
+	err := r.queryRow(tx, query+r.dialect.ShareLock(), id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	return err
+}
+
+// requireAffected returns notFound when a statement changed no rows
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// Ensure Repository implements the interface
+var (
+	_ repository.FavoritesRepository = (*Repository)(nil)
+	_ repository.FavoriteImporter    = (*Repository)(nil)
+	_ repository.AssetReferences     = (*Repository)(nil)
+	_ repository.UserEraser          = (*Repository)(nil)
+	_ repository.Pinger              = (*Repository)(nil)
+	_ repository.FavoritesCounter    = (*Repository)(nil)
+	_ repository.FavoriteGetter      = (*Repository)(nil)
+	_ repository.AssetSearcher       = (*Repository)(nil)
+)
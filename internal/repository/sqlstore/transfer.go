@@ -0,0 +1,155 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+var _ repository.FavoritesTransferer = (*Repository)(nil)
+
+// transferredFavorite is a favorite row with its note, as copied by a transfer
+type transferredFavorite struct {
+	addedAt   time.Time
+	updatedAt time.Time
+	note      string
+}
+
+func (r *Repository) TransferFavorites(fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return repository.TransferResult{}, err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, fromUserID, domain.ErrUserNotFound); err != nil {
+		return repository.TransferResult{}, err
+	}
+	if err := r.lockRow(tx, `SELECT 1 FROM users WHERE id = ?`, toUserID, domain.ErrUserNotFound); err != nil {
+		return repository.TransferResult{}, err
+	}
+
+	source, err := r.transferSource(tx, fromUserID)
+	if err != nil {
+		return repository.TransferResult{}, err
+	}
+	if assetIDs == nil {
+		for assetID := range source {
+			assetIDs = append(assetIDs, assetID)
+		}
+	} else {
+		assetIDs = append([]string(nil), assetIDs...)
+	}
+	for _, assetID := range assetIDs {
+		if _, exists := source[assetID]; !exists {
+			return repository.TransferResult{}, domain.ErrFavoriteNotFound
+		}
+	}
+	sort.Strings(assetIDs)
+
+	existing, err := r.favoriteIDs(tx, toUserID)
+	if err != nil {
+		return repository.TransferResult{}, err
+	}
+
+	result := repository.TransferResult{Transferred: []string{}, Skipped: []string{}}
+	for _, assetID := range assetIDs {
+		if existing[assetID] {
+			result.Skipped = append(result.Skipped, assetID)
+			continue
+		}
+		favorite := source[assetID]
+		if _, err := r.exec(tx,
+			`INSERT INTO favorites (user_id, asset_id, added_at, updated_at) VALUES (?, ?, ?, ?)`,
+			toUserID, assetID, favorite.addedAt, favorite.updatedAt,
+		); err != nil {
+			return repository.TransferResult{}, err
+		}
+		if favorite.note != "" {
+			if _, err := r.exec(tx,
+				`INSERT INTO favorite_notes (user_id, asset_id, note) VALUES (?, ?, ?)`,
+				toUserID, assetID, favorite.note,
+			); err != nil {
+				return repository.TransferResult{}, err
+			}
+		}
+		result.Transferred = append(result.Transferred, assetID)
+	}
+
+	// Notes, order and collection entries go with the favorite through
+	// their cascading foreign keys
+	if move {
+		for _, assetID := range assetIDs {
+			if _, err := r.exec(tx, `DELETE FROM favorites WHERE user_id = ? AND asset_id = ?`, fromUserID, assetID); err != nil {
+				return repository.TransferResult{}, err
+			}
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+// transferSource reads a user's favorites and notes by asset ID, locking
+// the favorites against concurrent removal
+func (r *Repository) transferSource(tx *sql.Tx, userID string) (map[string]*transferredFavorite, error) {
+	rows, err := r.query(tx,
+		`SELECT asset_id, added_at, updated_at FROM favorites WHERE user_id = ?`+r.dialect.ShareLock(),
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := make(map[string]*transferredFavorite)
+	for rows.Next() {
+		var assetID string
+		var favorite transferredFavorite
+		if err := rows.Scan(&assetID, &favorite.addedAt, &favorite.updatedAt); err != nil {
+			return nil, err
+		}
+		favorites[assetID] = &favorite
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	notes, err := r.query(tx, `SELECT asset_id, note FROM favorite_notes WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer notes.Close()
+	for notes.Next() {
+		var assetID, note string
+		if err := notes.Scan(&assetID, &note); err != nil {
+			return nil, err
+		}
+		if favorite, ok := favorites[assetID]; ok {
+			favorite.note = note
+		}
+	}
+	return favorites, notes.Err()
+}
+
+// favoriteIDs returns the set of assets the user has favorited
+func (r *Repository) favoriteIDs(tx *sql.Tx, userID string) (map[string]bool, error) {
+	rows, err := r.query(tx, `SELECT asset_id FROM favorites WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var assetID string
+		if err := rows.Scan(&assetID); err != nil {
+			return nil, err
+		}
+		ids[assetID] = true
+	}
+	return ids, rows.Err()
+}
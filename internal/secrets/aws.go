@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/pkg/awsv4"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager. Names are secret
+// IDs or ARNs. Secrets stored as JSON objects expose their fields as keys;
+// other secrets are returned as a single plain value.
+type AWSSecretsManager struct {
+	client      *httpclient.Client
+	endpoint    string
+	region      string
+	credentials awsv4.Credentials
+	now         func() time.Time
+}
+
+// NewAWSSecretsManager creates a provider for region. endpoint overrides the
+// regional endpoint, e.g. for a VPC endpoint or a local emulator.
+func NewAWSSecretsManager(client *httpclient.Client, region, endpoint string, credentials awsv4.Credentials) *AWSSecretsManager {
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+	return &AWSSecretsManager{
+		client:      client,
+		endpoint:    strings.TrimRight(endpoint, "/"),
+		region:      region,
+		credentials: credentials,
+		now:         time.Now,
+	}
+}
+
+func (a *AWSSecretsManager) Fetch(ctx context.Context, name string) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	awsv4.Sign(req, payload, a.credentials, a.region, "secretsmanager", a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+		if failure.Type != "" {
+			return nil, fmt.Errorf("secrets manager returned %s: %s %s", resp.Status, failure.Type, failure.Message)
+		}
+		return nil, fmt.Errorf("secrets manager returned %s", resp.Status)
+	}
+
+	var body struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding secrets manager response: %w", err)
+	}
+	if body.SecretString == nil {
+		return nil, fmt.Errorf("secret has no string value")
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*body.SecretString), &fields); err != nil {
+		return map[string]string{"": *body.SecretString}, nil
+	}
+	values := make(map[string]string, len(fields))
+	for key, field := range fields {
+		if s, ok := field.(string); ok {
+			values[key] = s
+			continue
+		}
+		raw, _ := json.Marshal(field)
+		values[key] = string(raw)
+	}
+	return values, nil
+}
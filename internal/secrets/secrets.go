@@ -0,0 +1,113 @@
+// Package secrets resolves configuration values that reference a secret
+// store instead of holding the secret itself
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider fetches a named secret as key/value pairs. Secrets holding a
+// single plain value are returned under the empty key.
+type Provider interface {
+	Fetch(ctx context.Context, name string) (map[string]string, error)
+}
+
+// Schemes prefixing secret references
+const (
+	SchemeVault = "vault"
+	SchemeAWS   = "aws-sm"
+)
+
+// IsReference reports whether value refers to a secret, e.g.
+// vault:secret/data/favorites#jwt_secret or aws-sm:favorites/prod#db_password
+func IsReference(value string) bool {
+	scheme, _, found := strings.Cut(value, ":")
+	return found && (scheme == SchemeVault || scheme == SchemeAWS)
+}
+
+type cached struct {
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// Resolver replaces secret references with the secrets they name. Fetched
+// secrets are cached, so settings referencing keys of the same secret cost
+// one request.
+type Resolver struct {
+	providers map[string]Provider
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewResolver creates a resolver. Providers are registered by scheme;
+// references to schemes without one fail to resolve.
+func NewResolver(providers map[string]Provider, ttl time.Duration) *Resolver {
+	return &Resolver{
+		providers: providers,
+		ttl:       ttl,
+		now:       time.Now,
+		cache:     make(map[string]cached),
+	}
+}
+
+// Resolve returns value unchanged unless it is a reference, in which case
+// it returns the referenced secret. A reference is scheme:name#key; without
+// #key the secret must hold a single plain value.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	scheme, rest, _ := strings.Cut(value, ":")
+	name, key, _ := strings.Cut(rest, "#")
+	if name == "" {
+		return "", fmt.Errorf("secret reference %q: missing secret name", value)
+	}
+
+	provider, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("secret reference %q: %s is not configured", value, scheme)
+	}
+
+	values, err := r.fetch(ctx, scheme, name, provider)
+	if err != nil {
+		return "", fmt.Errorf("fetching secret %s:%s: %w", scheme, name, err)
+	}
+
+	secret, ok := values[key]
+	if !ok {
+		if key == "" {
+			return "", fmt.Errorf("secret %s:%s holds several keys; name one with #key", scheme, name)
+		}
+		return "", fmt.Errorf("secret %s:%s has no key %q", scheme, name, key)
+	}
+	return secret, nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, scheme, name string, provider Provider) (map[string]string, error) {
+	cacheKey := scheme + ":" + name
+
+	r.mu.Lock()
+	entry, ok := r.cache[cacheKey]
+	r.mu.Unlock()
+	if ok && r.now().Sub(entry.fetchedAt) < r.ttl {
+		return entry.values, nil
+	}
+
+	values, err := provider.Fetch(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[cacheKey] = cached{values: values, fetchedAt: r.now()}
+	r.mu.Unlock()
+	return values, nil
+}
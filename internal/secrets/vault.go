@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// Vault reads secrets from HashiCorp Vault's key/value engine. Names are API
+// paths below /v1/, e.g. secret/data/favorites for KV version 2.
+type Vault struct {
+	client *httpclient.Client
+	addr   string
+	token  string
+}
+
+// NewVault creates a Vault provider for the server at addr
+func NewVault(client *httpclient.Client, addr, token string) *Vault {
+	return &Vault{client: client, addr: strings.TrimRight(addr, "/"), token: token}
+}
+
+func (v *Vault) Fetch(ctx context.Context, name string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimLeft(name, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding vault response: %w", err)
+	}
+
+	// KV version 2 nests the secret under data.data, next to its metadata
+	data := body.Data
+	if nested, ok := data["data"]; ok {
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return nil, fmt.Errorf("decoding vault secret: %w", err)
+		}
+	}
+
+	values := make(map[string]string, len(data))
+	for key, raw := range data {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Non-string values are kept in their JSON form
+			value = string(raw)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
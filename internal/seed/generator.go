@@ -0,0 +1,201 @@
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Options controls the volume of generated data
+type Options struct {
+	Users            int   `json:"users"`
+	Assets           int   `json:"assets"`
+	FavoritesPerUser int   `json:"favorites_per_user"`
+	Seed             int64 `json:"seed"`
+}
+
+// Result reports how much data was written
+type Result struct {
+	Users     int           `json:"users"`
+	Assets    int           `json:"assets"`
+	Favorites int           `json:"favorites"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Generator writes fake but realistic users, assets and favorites.
+// The same options and seed always produce the same data, and IDs follow
+// the user1, chart1, insight1, audience1 pattern used in the documentation.
+type Generator struct {
+	repo   repository.FavoritesRepository
+	logger *logrus.Logger
+}
+
+// NewGenerator creates a generator writing to repo
+func NewGenerator(repo repository.FavoritesRepository, logger *logrus.Logger) *Generator {
+	return &Generator{repo: repo, logger: logger}
+}
+
+// Generate writes the requested volume of data. Entries that already exist
+// are left untouched and are not counted; favorites of existing assets use
+// the stored asset.
+func (g *Generator) Generate(opts Options) (Result, error) {
+	start := time.Now()
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var result Result
+
+	g.logger.WithFields(logrus.Fields{
+		"users":              opts.Users,
+		"assets":             opts.Assets,
+		"favorites_per_user": opts.FavoritesPerUser,
+		"seed":               opts.Seed,
+	}).Info("Generating seed data")
+
+	for i := 1; i <= opts.Users; i++ {
+		first := firstNames[rng.Intn(len(firstNames))]
+		last := lastNames[rng.Intn(len(lastNames))]
+		email := fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i)
+
+		userID := fmt.Sprintf("user%d", i)
+		// Some backends replace existing users on create, so existing
+		// users are looked up first
+		if _, err := g.repo.GetUser(userID); err == nil {
+			continue
+		}
+		err := g.repo.CreateUser(domain.NewUser(userID, email, first+" "+last))
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Users++
+	}
+
+	assets := make([]domain.Asset, 0, opts.Assets)
+	counters := map[domain.AssetType]int{}
+	for i := 0; i < opts.Assets; i++ {
+		assetType := assetTypes[i%len(assetTypes)]
+		counters[assetType]++
+		asset := randomAsset(rng, assetType, counters[assetType])
+		asset.Normalize()
+
+		err := g.repo.CreateAsset(asset)
+		switch {
+		case err == nil:
+			result.Assets++
+		case errors.Is(err, domain.ErrAssetAlreadyExists):
+			// Favorites point at the stored asset, not the generated one
+			if asset, err = g.repo.GetAsset(asset.GetID()); err != nil {
+				return result, err
+			}
+		default:
+			return result, err
+		}
+		assets = append(assets, asset)
+	}
+
+	if len(assets) == 0 {
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	// Each user's favorites are a partial shuffle of one shared index slice,
+	// so the work grows with the favorites generated rather than users x assets.
+	indices := make([]int, len(assets))
+	for i := range indices {
+		indices[i] = i
+	}
+	perUser := min(opts.FavoritesPerUser, len(assets))
+
+	for i := 1; i <= opts.Users; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		for j := 0; j < perUser; j++ {
+			k := j + rng.Intn(len(indices)-j)
+			indices[j], indices[k] = indices[k], indices[j]
+		}
+		for _, index := range indices[:perUser] {
+			err := g.repo.AddFavorite(userID, assets[index])
+			if errors.Is(err, domain.ErrFavoriteAlreadyExists) {
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.Favorites++
+		}
+	}
+
+	result.Duration = time.Since(start)
+	g.logger.WithFields(logrus.Fields{
+		"users":     result.Users,
+		"assets":    result.Assets,
+		"favorites": result.Favorites,
+		"duration":  result.Duration,
+	}).Info("Seed data generated")
+
+	return result, nil
+}
+
+var assetTypes = []domain.AssetType{domain.AssetTypeChart, domain.AssetTypeInsight, domain.AssetTypeAudience}
+
+func randomAsset(rng *rand.Rand, assetType domain.AssetType, n int) domain.Asset {
+	switch assetType {
+	case domain.AssetTypeChart:
+		metric := chartMetrics[rng.Intn(len(chartMetrics))]
+		data := make([]domain.ChartDataPoint, 0, len(months))
+		value := 50 + rng.Intn(200)
+		for _, month := range months[:3+rng.Intn(len(months)-2)] {
+			value += rng.Intn(60) - 20
+			data = append(data, domain.ChartDataPoint{X: month, Y: value})
+		}
+		return domain.NewChart(fmt.Sprintf("chart%d", n), "Monthly "+metric, "Month", metric,
+			fmt.Sprintf("%s trend by month", metric), data)
+
+	case domain.AssetTypeInsight:
+		topic := insightTopics[rng.Intn(len(insightTopics))]
+		group := ageGroups[rng.Intn(len(ageGroups))]
+		content := fmt.Sprintf("%d%% of people aged %s %s", 10+rng.Intn(80), group, topic.text)
+		return domain.NewInsight(fmt.Sprintf("insight%d", n), content,
+			strings.ToUpper(topic.category[:1])+topic.category[1:]+" insight", []string{topic.category, group}, topic.category)
+
+	default:
+		group := ageGroups[rng.Intn(len(ageGroups))]
+		interest := interests[rng.Intn(len(interests))]
+		audience := domain.NewAudience(fmt.Sprintf("audience%d", n), fmt.Sprintf("%s enthusiasts aged %s", interest, group))
+		audience.Gender = genders[:1+rng.Intn(len(genders))]
+		audience.AgeGroups = []domain.AgeGroup{domain.AgeGroup(group)}
+		audience.BirthCountries = countries[:1+rng.Intn(len(countries))]
+		audience.SocialMediaHours = socialMediaHours[rng.Intn(len(socialMediaHours))]
+		audience.PurchasesLastMonth = rng.Intn(12)
+		return audience
+	}
+}
+
+var (
+	firstNames = []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Jamie", "Riley", "Avery", "Quinn"}
+	lastNames  = []string{"Smith", "Johnson", "Garcia", "Brown", "Miller", "Davis", "Lopez", "Wilson", "Moore", "Clark"}
+
+	chartMetrics = []string{"Sales ($)", "Active Users", "Sign-ups", "Churn Rate (%)", "Ad Spend ($)", "Page Views"}
+	months       = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+	insightTopics = []struct{ text, category string }{
+		{"spend more than 3 hours on social media daily", "social"},
+		{"bought something after seeing it on a video platform", "ecommerce"},
+		{"stream music every day", "entertainment"},
+		{"play mobile games at least weekly", "gaming"},
+		{"prefer brands that are environmentally friendly", "sustainability"},
+	}
+
+	ageGroups        = []string{"16-24", "25-34", "35-44", "45-54", "55-64"}
+	interests        = []string{"Gaming", "Fitness", "Travel", "Cooking", "Technology", "Fashion"}
+	genders          = []string{"Female", "Male", "Non-binary"}
+	countries        = []string{"US", "UK", "DE", "FR", "BR", "JP"}
+	socialMediaHours = []domain.SocialMediaHours{"0-1", "1-3", "3+"}
+)
@@ -0,0 +1,124 @@
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed profiles/*.json
+var builtinFiles embed.FS
+
+// Profile is a named seed volume defined in a fixture file. The file name
+// without its .json extension is the profile name.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Options
+}
+
+// Scale returns the profile's options with users and assets multiplied by
+// factor. Non-empty volumes never scale below one; favorites per user and
+// the random seed are kept as defined.
+func (p Profile) Scale(factor float64) Options {
+	opts := p.Options
+	opts.Users = scaleVolume(opts.Users, factor)
+	opts.Assets = scaleVolume(opts.Assets, factor)
+	return opts
+}
+
+func scaleVolume(n int, factor float64) int {
+	if n == 0 {
+		return 0
+	}
+	return max(1, int(math.Round(float64(n)*factor)))
+}
+
+// Profiles holds the available seed profiles by name
+type Profiles map[string]Profile
+
+// BuiltinProfiles returns the demo, load-test and empty profiles shipped with the service
+func BuiltinProfiles() (Profiles, error) {
+	profiles := Profiles{}
+	if err := profiles.load(builtinFiles, "profiles"); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// LoadProfiles returns the built-in profiles plus every *.json fixture in
+// dir. A fixture named like a built-in profile replaces it. An empty dir
+// returns only the built-in profiles.
+func LoadProfiles(dir string) (Profiles, error) {
+	profiles, err := BuiltinProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return profiles, nil
+	}
+	if err := profiles.load(os.DirFS(dir), "."); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (p Profiles) load(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range paths {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var profile Profile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return fmt.Errorf("seed profile %s: %w", file, err)
+		}
+		if profile.Users < 0 || profile.Assets < 0 || profile.FavoritesPerUser < 0 {
+			return fmt.Errorf("seed profile %s: volumes must not be negative", file)
+		}
+
+		profile.Name = strings.TrimSuffix(path.Base(file), ".json")
+		p[profile.Name] = profile
+	}
+	return nil
+}
+
+// Get returns the named profile
+func (p Profiles) Get(name string) (Profile, error) {
+	profile, ok := p[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown seed profile %q (available: %s)", name, strings.Join(p.Names(), ", "))
+	}
+	return profile, nil
+}
+
+// Names returns the profile names in alphabetical order
+func (p Profiles) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List returns the profiles in name order
+func (p Profiles) List() []Profile {
+	list := make([]Profile, 0, len(p))
+	for _, name := range p.Names() {
+		list = append(list, p[name])
+	}
+	return list
+}
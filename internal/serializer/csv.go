@@ -0,0 +1,153 @@
+package serializer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// CSVHeader names the columns of favorites exported as CSV. Every asset type
+// shares the row layout, leaving the columns of other types empty.
+var CSVHeader = []string{
+	"asset_id", "type", "added_at", "updated_at", "pinned", "position", "note", "unavailable",
+	"description",
+	"title", "x_axis_title", "y_axis_title", "data",
+	"content", "tags", "category",
+	"gender", "birth_countries", "age_groups", "social_media_hours", "purchases_last_month",
+}
+
+// csvListSeparator joins list fields such as tags within one cell
+const csvListSeparator = "; "
+
+// FavoriteCSVRecord flattens a favorite and its asset into a row matching
+// CSVHeader. Chart data points are kept as a JSON array in one cell.
+func FavoriteCSVRecord(favorite *domain.UserFavorite) ([]string, error) {
+	record := make(map[string]string, len(CSVHeader))
+	record["asset_id"] = favorite.AssetID
+	record["added_at"] = favorite.AddedAt.UTC().Format(time.RFC3339)
+	record["updated_at"] = favorite.UpdatedAt.UTC().Format(time.RFC3339)
+	record["pinned"] = strconv.FormatBool(favorite.Pinned)
+	record["position"] = strconv.Itoa(favorite.Position)
+	record["note"] = favorite.Note
+
+	if favorite.Asset != nil {
+		record["type"] = string(favorite.Asset.GetType())
+		record["unavailable"] = strconv.FormatBool(favorite.Asset.GetDeletedAt() != nil)
+	}
+
+	switch asset := favorite.Asset.(type) {
+	case *domain.Chart:
+		record["description"] = asset.Description
+		record["title"] = asset.Title
+		record["x_axis_title"] = asset.XAxisTitle
+		record["y_axis_title"] = asset.YAxisTitle
+		if len(asset.Data) > 0 {
+			data, err := json.Marshal(asset.Data)
+			if err != nil {
+				return nil, err
+			}
+			record["data"] = string(data)
+		}
+	case *domain.Insight:
+		record["description"] = asset.Description
+		record["content"] = asset.Content
+		record["tags"] = strings.Join(asset.Tags, csvListSeparator)
+		record["category"] = asset.Category
+	case *domain.Audience:
+		record["description"] = asset.Description
+		record["gender"] = strings.Join(asset.Gender, csvListSeparator)
+		record["birth_countries"] = strings.Join(asset.BirthCountries, csvListSeparator)
+		groups := make([]string, len(asset.AgeGroups))
+		for i, group := range asset.AgeGroups {
+			groups[i] = string(group)
+		}
+		record["age_groups"] = strings.Join(groups, csvListSeparator)
+		record["social_media_hours"] = string(asset.SocialMediaHours)
+		record["purchases_last_month"] = strconv.Itoa(asset.PurchasesLastMonth)
+	}
+
+	row := make([]string, len(CSVHeader))
+	for i, column := range CSVHeader {
+		row[i] = escapeCSVFormula(record[column])
+	}
+	return row, nil
+}
+
+// escapeCSVFormula stops spreadsheets from evaluating user-supplied text as
+// a formula by prefixing cells that would start one with a quote
+func escapeCSVFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cell
+	}
+	return cell
+}
+
+// ParseFavoriteCSVRecord reads back a row exported with FavoriteCSVRecord,
+// given as cells by column name. Rows without a type name a catalog asset
+// by asset_id alone, so asset is nil. Columns that do not apply to the
+// row's type are ignored.
+func ParseFavoriteCSVRecord(cells map[string]string) (asset domain.Asset, note string, err error) {
+	cell := func(column string) string {
+		return unescapeCSVFormula(strings.TrimSpace(cells[column]))
+	}
+	list := func(column string) []string {
+		var values []string
+		for _, value := range strings.Split(cell(column), strings.TrimSpace(csvListSeparator)) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	id := cell("asset_id")
+	switch domain.AssetType(cell("type")) {
+	case "":
+		return nil, cell("note"), nil
+	case domain.AssetTypeChart:
+		var data []domain.ChartDataPoint
+		if raw := cell("data"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &data); err != nil {
+				return nil, "", domain.WithContext(domain.ErrInvalidInput, "field", "data")
+			}
+		}
+		asset = domain.NewChart(id, cell("title"), cell("x_axis_title"), cell("y_axis_title"), cell("description"), data)
+	case domain.AssetTypeInsight:
+		asset = domain.NewInsight(id, cell("content"), cell("description"), list("tags"), cell("category"))
+	case domain.AssetTypeAudience:
+		audience := domain.NewAudience(id, cell("description"))
+		audience.Gender = list("gender")
+		audience.BirthCountries = list("birth_countries")
+		for _, group := range list("age_groups") {
+			audience.AgeGroups = append(audience.AgeGroups, domain.AgeGroup(group))
+		}
+		audience.SocialMediaHours = domain.SocialMediaHours(cell("social_media_hours"))
+		if raw := cell("purchases_last_month"); raw != "" {
+			if audience.PurchasesLastMonth, err = strconv.Atoi(raw); err != nil {
+				return nil, "", domain.WithContext(domain.ErrInvalidInput, "field", "purchases_last_month")
+			}
+		}
+		asset = audience
+	default:
+		return nil, "", domain.WithContext(domain.ErrInvalidAssetType, "type", cell("type"))
+	}
+	return asset, cell("note"), nil
+}
+
+// unescapeCSVFormula removes the quote escapeCSVFormula adds
+func unescapeCSVFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' {
+		switch cell[1] {
+		case '=', '+', '-', '@', '\t', '\r':
+			return cell[1:]
+		}
+	}
+	return cell
+}
@@ -0,0 +1,155 @@
+package serializer
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// JSONAPIMediaType is the media type defined by the JSON:API specification
+const JSONAPIMediaType = "application/vnd.api+json"
+
+// JSON:API resource types
+const (
+	jsonAPITypeUsers     = "users"
+	jsonAPITypeFavorites = "favorites"
+)
+
+// JSONAPIDocument is a top-level JSON:API document
+type JSONAPIDocument struct {
+	Data     interface{}            `json:"data,omitempty"`
+	Included []JSONAPIResource      `json:"included,omitempty"`
+	Errors   []JSONAPIError         `json:"errors,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+}
+
+// JSONAPIResource is a resource object
+type JSONAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]interface{}         `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+}
+
+// JSONAPIRelationship is a to-one relationship object
+type JSONAPIRelationship struct {
+	Data JSONAPIIdentifier `json:"data"`
+}
+
+// JSONAPIIdentifier is a resource identifier object
+type JSONAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIError is an error object
+type JSONAPIError struct {
+	Status string            `json:"status"`
+	Code   string            `json:"code,omitempty"`
+	Title  string            `json:"title"`
+	Meta   map[string]string `json:"meta,omitempty"`
+}
+
+// FavoritesJSONAPIDocument renders favorites as JSON:API resources with
+// relationships to their user and asset, and the assets as included resources
+func (r *Registry) FavoritesJSONAPIDocument(version Version, favorites []*domain.UserFavorite) (JSONAPIDocument, error) {
+	data := make([]JSONAPIResource, 0, len(favorites))
+	included := make([]JSONAPIResource, 0, len(favorites))
+	seen := make(map[JSONAPIIdentifier]bool)
+
+	for _, favorite := range favorites {
+		assetRef := JSONAPIIdentifier{Type: assetResourceType(favorite.Asset), ID: favorite.AssetID}
+
+		attributes := map[string]interface{}{
+			"added_at":   favorite.AddedAt,
+			"updated_at": favorite.UpdatedAt,
+		}
+		if favorite.Note != "" {
+			attributes["note"] = favorite.Note
+		}
+		if favorite.Pinned {
+			attributes["pinned"] = true
+		}
+		if favorite.Position > 0 {
+			attributes["position"] = favorite.Position
+		}
+
+		data = append(data, JSONAPIResource{
+			Type:       jsonAPITypeFavorites,
+			ID:         favorite.UserID + ":" + favorite.AssetID,
+			Attributes: attributes,
+			Relationships: map[string]JSONAPIRelationship{
+				"user":  {Data: JSONAPIIdentifier{Type: jsonAPITypeUsers, ID: favorite.UserID}},
+				"asset": {Data: assetRef},
+			},
+		})
+
+		if favorite.Asset == nil || seen[assetRef] {
+			continue
+		}
+		seen[assetRef] = true
+
+		attributes, err := resourceAttributes(r.SerializeAsset(version, favorite.Asset))
+		if err != nil {
+			return JSONAPIDocument{}, err
+		}
+		included = append(included, JSONAPIResource{
+			Type:       assetRef.Type,
+			ID:         assetRef.ID,
+			Attributes: attributes,
+		})
+	}
+
+	return JSONAPIDocument{
+		Data:     data,
+		Included: included,
+		Meta:     map[string]interface{}{"count": len(data)},
+	}, nil
+}
+
+// FavoriteJSONAPIDocument renders a single favorite as the primary data of
+// a JSON:API document, with its asset included
+func (r *Registry) FavoriteJSONAPIDocument(version Version, favorite *domain.UserFavorite) (JSONAPIDocument, error) {
+	document, err := r.FavoritesJSONAPIDocument(version, []*domain.UserFavorite{favorite})
+	if err != nil {
+		return JSONAPIDocument{}, err
+	}
+	document.Data = document.Data.([]JSONAPIResource)[0]
+	document.Meta = nil
+	return document, nil
+}
+
+// JSONAPIErrorDocument renders a single error as a JSON:API document. The
+// error context, such as the IDs involved, is carried in meta.
+func JSONAPIErrorDocument(statusCode int, code, message string, context map[string]string) JSONAPIDocument {
+	return JSONAPIDocument{
+		Errors: []JSONAPIError{{Status: strconv.Itoa(statusCode), Code: code, Title: message, Meta: context}},
+	}
+}
+
+// assetResourceType maps asset types onto plural JSON:API resource types
+func assetResourceType(asset domain.Asset) string {
+	if asset == nil {
+		return "assets"
+	}
+	return string(asset.GetType()) + "s"
+}
+
+// resourceAttributes converts a serialized value into JSON:API attributes,
+// dropping the members that live at the resource object level
+func resourceAttributes(value interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(raw, &attributes); err != nil {
+		return nil, err
+	}
+
+	delete(attributes, "id")
+	delete(attributes, "type")
+	return attributes, nil
+}
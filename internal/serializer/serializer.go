@@ -0,0 +1,167 @@
+package serializer
+
+import (
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Version identifies a revision of the API wire format
+type Version string
+
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+
+	// DefaultVersion is used when the client does not ask for a specific version
+	DefaultVersion = V2
+)
+
+// AssetSerializer renders an asset into its wire representation
+type AssetSerializer func(asset domain.Asset) interface{}
+
+// Decorator adds request-specific members, such as localized names, to a
+// serialized asset
+type Decorator func(view interface{}) interface{}
+
+// FavoriteView is the wire representation of a user favorite
+type FavoriteView struct {
+	UserID    string      `json:"user_id"`
+	AssetID   string      `json:"asset_id"`
+	Asset     interface{} `json:"asset"`
+	Note      string      `json:"note,omitempty"`
+	Pinned    bool        `json:"pinned,omitempty"`
+	Position  int         `json:"position,omitempty"`
+	AddedAt   time.Time   `json:"added_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	// Unavailable marks favorites of assets deleted from the catalog
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// Registry maps API versions and asset types to serializers, decoupling
+// the wire format from the domain structs
+type Registry struct {
+	serializers map[Version]map[domain.AssetType]AssetSerializer
+	decorate    Decorator
+}
+
+// NewRegistry creates an empty serializer registry
+func NewRegistry() *Registry {
+	return &Registry{
+		serializers: make(map[Version]map[domain.AssetType]AssetSerializer),
+	}
+}
+
+// Register sets the serializer used for an asset type in a given version
+func (r *Registry) Register(version Version, assetType domain.AssetType, serializer AssetSerializer) {
+	if r.serializers[version] == nil {
+		r.serializers[version] = make(map[domain.AssetType]AssetSerializer)
+	}
+	r.serializers[version][assetType] = serializer
+}
+
+// HasVersion reports whether any serializers are registered for the version
+func (r *Registry) HasVersion(version Version) bool {
+	_, exists := r.serializers[version]
+	return exists
+}
+
+// Versions returns the registered versions in ascending order
+func (r *Registry) Versions() []Version {
+	versions := make([]Version, 0, len(r.serializers))
+	for version := range r.serializers {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// WithDecorator returns a registry sharing r's serializers that passes every
+// serialized asset through decorate. It is cheap enough to call per request.
+func (r *Registry) WithDecorator(decorate Decorator) *Registry {
+	return &Registry{serializers: r.serializers, decorate: decorate}
+}
+
+// SerializeAsset renders an asset for the given version. Asset types without
+// a registered serializer are rendered as the domain struct itself.
+func (r *Registry) SerializeAsset(version Version, asset domain.Asset) interface{} {
+	if asset == nil {
+		return nil
+	}
+	var view interface{} = asset
+	if serializer, exists := r.serializers[version][asset.GetType()]; exists {
+		view = serializer(asset)
+	}
+	if r.decorate != nil {
+		view = r.decorate(view)
+	}
+	return view
+}
+
+// SerializeFavorite renders a single favorite for the given version
+func (r *Registry) SerializeFavorite(version Version, favorite *domain.UserFavorite) FavoriteView {
+	return FavoriteView{
+		UserID:      favorite.UserID,
+		AssetID:     favorite.AssetID,
+		Asset:       r.SerializeAsset(version, favorite.Asset),
+		Note:        favorite.Note,
+		Pinned:      favorite.Pinned,
+		Position:    favorite.Position,
+		AddedAt:     favorite.AddedAt,
+		UpdatedAt:   favorite.UpdatedAt,
+		Unavailable: favorite.Asset != nil && favorite.Asset.GetDeletedAt() != nil,
+	}
+}
+
+// SerializeFavorites renders a list of favorites for the given version
+func (r *Registry) SerializeFavorites(version Version, favorites []*domain.UserFavorite) []FavoriteView {
+	views := make([]FavoriteView, 0, len(favorites))
+	for _, favorite := range favorites {
+		views = append(views, r.SerializeFavorite(version, favorite))
+	}
+	return views
+}
+
+// DefaultRegistry returns a registry with the built-in formats: v1 renders
+// charts without their data points, v2 renders every asset in full
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(V1, domain.AssetTypeChart, slimChart)
+	r.Register(V1, domain.AssetTypeInsight, fullAsset)
+	r.Register(V1, domain.AssetTypeAudience, fullAsset)
+
+	r.Register(V2, domain.AssetTypeChart, fullAsset)
+	r.Register(V2, domain.AssetTypeInsight, fullAsset)
+	r.Register(V2, domain.AssetTypeAudience, fullAsset)
+
+	return r
+}
+
+// chartSummary is the v1 chart representation without data points
+type chartSummary struct {
+	domain.BaseAsset
+	Title          string `json:"title"`
+	XAxisTitle     string `json:"x_axis_title"`
+	YAxisTitle     string `json:"y_axis_title"`
+	DataPointCount int    `json:"data_point_count"`
+}
+
+func slimChart(asset domain.Asset) interface{} {
+	chart, ok := asset.(*domain.Chart)
+	if !ok {
+		return asset
+	}
+	return chartSummary{
+		BaseAsset:      chart.BaseAsset,
+		Title:          chart.Title,
+		XAxisTitle:     chart.XAxisTitle,
+		YAxisTitle:     chart.YAxisTitle,
+		DataPointCount: len(chart.Data),
+	}
+}
+
+func fullAsset(asset domain.Asset) interface{} {
+	return asset
+}
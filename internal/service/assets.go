@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/catalog"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/moderation"
+	"gwi-favorites-service/internal/notification"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/validation"
+	"gwi-favorites-service/pkg/clock"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeletePolicy decides what happens to favorites when their asset is deleted
+type DeletePolicy string
+
+const (
+	// DeleteCascade removes the asset and every favorite pointing at it
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteOrphan keeps the asset for existing favorites but marks it
+	// deleted, so it can no longer be favorited
+	DeleteOrphan DeletePolicy = "orphan"
+	// DeleteBlock refuses to delete assets that are still favorited
+	DeleteBlock DeletePolicy = "block"
+	// DeleteGrace marks the asset deleted like DeleteOrphan, notifies the
+	// users who favorited it, and removes it with their favorites once the
+	// grace period has passed
+	DeleteGrace DeletePolicy = "grace"
+)
+
+// ParseDeletePolicy validates a configured delete policy
+func ParseDeletePolicy(value string) (DeletePolicy, error) {
+	switch policy := DeletePolicy(value); policy {
+	case DeleteCascade, DeleteOrphan, DeleteBlock, DeleteGrace:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown asset delete policy %q", value)
+}
+
+// AssetService handles catalog-level asset operations
+type AssetService struct {
+	repo         repository.FavoritesRepository
+	deletePolicy DeletePolicy
+	limits       validation.LengthLimits
+	coerceCharts bool
+	auditLog     audit.Log
+	moderation   *moderation.Store
+	gracePeriod  time.Duration
+	notices      *notification.Store
+	source       catalog.Source
+	clock        clock.Clock
+	logger       *logrus.Logger
+
+	featuredMu sync.RWMutex
+	featured   map[string]time.Time
+
+	// removals holds the time each asset in its grace period is removed
+	removalsMu sync.Mutex
+	removals   map[string]time.Time
+}
+
+// AssetOption configures optional AssetService behaviour
+type AssetOption func(*AssetService)
+
+// WithAssetLengthLimits caps description and content lengths of catalog assets
+func WithAssetLengthLimits(limits validation.LengthLimits) AssetOption {
+	return func(s *AssetService) { s.limits = limits }
+}
+
+// WithAssetChartCoercion normalizes the data of catalog charts, as
+// validation.CoerceChartData does
+func WithAssetChartCoercion(enabled bool) AssetOption {
+	return func(s *AssetService) { s.coerceCharts = enabled }
+}
+
+// WithAssetAuditLog records catalog updates and deletions, which change the
+// favorites pointing at the asset
+func WithAssetAuditLog(log audit.Log) AssetOption {
+	return func(s *AssetService) { s.auditLog = log }
+}
+
+// WithAssetClock stamps orphaned assets with c instead of the wall clock
+func WithAssetClock(c clock.Clock) AssetOption {
+	return func(s *AssetService) { s.clock = c }
+}
+
+// WithAssetModeration leaves assets hidden by moderation out of the featured assets
+func WithAssetModeration(store *moderation.Store) AssetOption {
+	return func(s *AssetService) { s.moderation = store }
+}
+
+// WithAssetGracePeriod sets how long assets deleted under DeleteGrace stay
+// visible as unavailable before they are removed
+func WithAssetGracePeriod(d time.Duration) AssetOption {
+	return func(s *AssetService) { s.gracePeriod = d }
+}
+
+// WithAssetNotifications notifies the users who favorited an asset deleted
+// under DeleteGrace
+func WithAssetNotifications(store *notification.Store) AssetOption {
+	return func(s *AssetService) { s.notices = store }
+}
+
+// NewAssetService creates a new asset service
+func NewAssetService(repo repository.FavoritesRepository, deletePolicy DeletePolicy, logger *logrus.Logger, opts ...AssetOption) *AssetService {
+	s := &AssetService{
+		repo:         repo,
+		deletePolicy: deletePolicy,
+		clock:        clock.System,
+		logger:       logger,
+		featured:     make(map[string]time.Time),
+		removals:     make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetAsset returns a catalog asset, including orphaned ones, with any
+// offloaded chart data loaded
+func (s *AssetService) GetAsset(ctx context.Context, assetID string) (domain.Asset, error) {
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	if chart, ok := asset.(*domain.Chart); ok {
+		if asset, err = loadChartData(s.repo, chart); err != nil {
+			return nil, domain.WithContext(err, "asset_id", assetID)
+		}
+	}
+	return asset, nil
+}
+
+// CreateAsset adds an asset to the catalog
+func (s *AssetService) CreateAsset(ctx context.Context, asset domain.Asset) error {
+	s.logger.WithFields(logrus.Fields{
+		"asset_id":   asset.GetID(),
+		"asset_type": asset.GetType(),
+	}).Info("Creating asset")
+
+	if err := s.checkAsset(ctx, asset); err != nil {
+		return domain.WithContext(err, "asset_id", asset.GetID())
+	}
+
+	if err := s.repo.CreateAsset(asset); err != nil {
+		s.logger.WithError(err).WithField("asset_id", asset.GetID()).Error("Failed to create asset")
+		return domain.WithContext(err, "asset_id", asset.GetID())
+	}
+
+	s.logger.WithField("asset_id", asset.GetID()).Info("Successfully created asset")
+	return nil
+}
+
+// UpdateAsset replaces a catalog asset; favorites pointing at it see the
+// change. The asset type cannot change, and orphaned assets cannot be updated.
+func (s *AssetService) UpdateAsset(ctx context.Context, assetID string, asset domain.Asset) error {
+	s.logger.WithField("asset_id", assetID).Info("Updating asset")
+
+	if asset.GetID() != assetID {
+		return domain.WithContext(domain.ErrInvalidInput, "asset_id", assetID, "field", "id")
+	}
+	if err := s.checkAsset(ctx, asset); err != nil {
+		return domain.WithContext(err, "asset_id", assetID)
+	}
+
+	existing, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return domain.WithContext(err, "asset_id", assetID)
+	}
+	if existing.GetDeletedAt() != nil {
+		return domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+	}
+	if existing.GetType() != asset.GetType() {
+		return domain.WithContext(domain.ErrInvalidAssetType, "asset_id", assetID, "type", string(existing.GetType()))
+	}
+
+	if err := s.repo.UpdateAsset(asset); err != nil {
+		s.logger.WithError(err).WithField("asset_id", assetID).Error("Failed to update asset")
+		return domain.WithContext(err, "asset_id", assetID)
+	}
+
+	recordAudit(ctx, s.auditLog, s.logger, audit.AssetUpdated, "", assetID, existing, asset)
+
+	s.logger.WithField("asset_id", assetID).Info("Successfully updated asset")
+	return nil
+}
+
+// checkAsset validates an asset written through the catalog. Deletion goes
+// through DeleteAsset so the delete policy applies.
+func (s *AssetService) checkAsset(ctx context.Context, asset domain.Asset) error {
+	if err := asset.Validate(); err != nil {
+		return err
+	}
+	if asset.GetDeletedAt() != nil {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "deleted_at")
+	}
+	asset.Normalize()
+	if s.coerceCharts {
+		validation.CoerceChartData(ctx, asset)
+	}
+	return s.limits.ApplyToAsset(ctx, asset)
+}
+
+// DeleteAsset removes an asset according to the configured delete policy
+func (s *AssetService) DeleteAsset(ctx context.Context, assetID string) error {
+	s.logger.WithFields(logrus.Fields{
+		"asset_id": assetID,
+		"policy":   s.deletePolicy,
+	}).Info("Deleting asset")
+
+	if assetID == "" {
+		return domain.ErrInvalidInput
+	}
+
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return domain.WithContext(err, "asset_id", assetID)
+	}
+	if deletedAt := asset.GetDeletedAt(); deletedAt != nil {
+		if s.deletePolicy != DeleteGrace {
+			return domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+		}
+		// Deleting again reschedules the removal, which is needed after a
+		// restart as scheduled removals are held in memory
+		s.scheduleRemoval(assetID, deletedAt.Add(s.gracePeriod))
+		return nil
+	}
+
+	// Orphaning changes the asset in place, so it is recorded beforehand
+	var before json.RawMessage
+	if s.auditLog != nil {
+		before, _ = json.Marshal(asset)
+	}
+
+	switch s.deletePolicy {
+	case DeleteBlock:
+		references, err := s.countReferences(assetID)
+		if err != nil {
+			return domain.WithContext(err, "asset_id", assetID)
+		}
+		if references > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"asset_id":   assetID,
+				"references": references,
+			}).Warn("Refusing to delete favorited asset")
+			return domain.WithContext(domain.ErrAssetInUse, "asset_id", assetID, "references", strconv.Itoa(references))
+		}
+		err = s.repo.DeleteAsset(assetID)
+	case DeleteOrphan, DeleteGrace:
+		asset.MarkDeleted(s.clock.Now())
+		err = s.repo.UpdateAsset(asset)
+	default:
+		err = s.repo.DeleteAsset(assetID)
+	}
+
+	if err != nil {
+		s.logger.WithError(err).WithField("asset_id", assetID).Error("Failed to delete asset")
+		return domain.WithContext(err, "asset_id", assetID)
+	}
+
+	s.dropFeatured(assetID)
+	if s.deletePolicy == DeleteGrace {
+		removesAt := asset.GetDeletedAt().Add(s.gracePeriod)
+		s.scheduleRemoval(assetID, removesAt)
+		s.notifyFavoriters(assetID, removesAt)
+	}
+	recordAudit(ctx, s.auditLog, s.logger, audit.AssetDeleted, "", assetID, before, map[string]DeletePolicy{"policy": s.deletePolicy})
+
+	s.logger.WithField("asset_id", assetID).Info("Successfully deleted asset")
+	return nil
+}
+
+// countReferences counts the favorites pointing at an asset
+func (s *AssetService) countReferences(assetID string) (int, error) {
+	counter, ok := s.repo.(repository.AssetReferences)
+	if !ok {
+		return 0, domain.ErrNotSupported
+	}
+	return counter.CountAssetReferences(assetID)
+}
+
+// ListAssets pages through the catalog in the repository's order. Assets
+// the query filters out are skipped, so a page may be read from more than
+// limit catalog entries; NextOffset accounts for them.
+func (s *AssetService) ListAssets(ctx context.Context, query domain.AssetQuery) (domain.AssetPage, error) {
+	if query.Type != "" && !query.Type.IsValid() {
+		return domain.AssetPage{}, domain.WithContext(domain.ErrInvalidAssetType, "field", "type")
+	}
+	if query.Limit <= 0 || query.Offset < 0 {
+		return domain.AssetPage{}, domain.WithContext(domain.ErrInvalidInput, "field", "limit")
+	}
+
+	page := domain.AssetPage{Assets: []domain.Asset{}, Limit: query.Limit, Offset: query.Offset}
+	position := query.Offset
+	for len(page.Assets) < query.Limit {
+		batch, err := s.scanAssets(query, query.Limit, position)
+		if err != nil {
+			return domain.AssetPage{}, err
+		}
+		for _, asset := range batch {
+			position++
+			if s.listable(asset, query) {
+				page.Assets = append(page.Assets, asset)
+				if len(page.Assets) == query.Limit {
+					break
+				}
+			}
+		}
+		if len(batch) < query.Limit {
+			return page, nil
+		}
+	}
+
+	page.NextOffset = &position
+	page.HasMore = true
+	return page, nil
+}
+
+// scanAssets returns limit assets from offset of the sequence a listing
+// scans: the assets matching the query's type and search on backends that
+// search the catalog themselves, otherwise the whole catalog
+func (s *AssetService) scanAssets(query domain.AssetQuery, limit, offset int) ([]domain.Asset, error) {
+	if searcher, ok := s.repo.(repository.AssetSearcher); ok {
+		query.Limit, query.Offset = limit, offset
+		assets, err := searcher.SearchAssets(query)
+		if !errors.Is(err, domain.ErrNotSupported) {
+			return assets, err
+		}
+	}
+	return s.repo.ListAssets(limit, offset)
+}
+
+// listable reports whether an asset passes the query filters
+func (s *AssetService) listable(asset domain.Asset, query domain.AssetQuery) bool {
+	if !query.Matches(asset) {
+		return false
+	}
+	if query.IncludeUnavailable {
+		return true
+	}
+	return asset.GetDeletedAt() == nil && (s.moderation == nil || !s.moderation.IsHidden(asset.GetID()))
+}
+
+// GetAvailableAsset returns a catalog asset that users may see and
+// favorite: deleted assets and those hidden by moderation are refused
+func (s *AssetService) GetAvailableAsset(ctx context.Context, assetID string) (domain.Asset, error) {
+	asset, err := s.GetAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if asset.GetDeletedAt() != nil {
+		return nil, domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+	}
+	if s.moderation != nil && s.moderation.IsHidden(assetID) {
+		return nil, domain.WithContext(domain.ErrAssetHidden, "asset_id", assetID)
+	}
+	return asset, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/audit"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordAudit appends an audit entry for a change that has been made. A
+// failed write cannot undo the change, so it is logged as an error.
+func recordAudit(ctx context.Context, log audit.Log, logger *logrus.Logger, action audit.Action, userID, assetID string, old, new interface{}) {
+	if log == nil {
+		return
+	}
+
+	entry, err := audit.NewEntry(ctx, action, userID, assetID, old, new)
+	if err == nil {
+		err = log.Append(entry)
+	}
+	if err != nil {
+		logger.WithError(err).WithFields(logrus.Fields{
+			"action":   action,
+			"user_id":  userID,
+			"asset_id": assetID,
+		}).Error("Failed to write audit entry")
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AuthService logs users in with a password and issues the tokens the API
+// accepts in hs256 mode
+type AuthService struct {
+	repo        repository.FavoritesRepository
+	credentials *auth.Credentials
+	issuer      *auth.Issuer
+	logger      *logrus.Logger
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(repo repository.FavoritesRepository, credentials *auth.Credentials, issuer *auth.Issuer, logger *logrus.Logger) *AuthService {
+	return &AuthService{
+		repo:        repo,
+		credentials: credentials,
+		issuer:      issuer,
+		logger:      logger,
+	}
+}
+
+// Login checks userID's password and issues a token pair. Users logging in
+// with the default password must exist in storage.
+func (s *AuthService) Login(ctx context.Context, userID, password string) (*auth.TokenPair, error) {
+	ok, listed := s.credentials.Check(userID, password)
+	if ok && !listed {
+		if _, err := s.repo.GetUser(userID); err != nil {
+			if !errors.Is(err, domain.ErrUserNotFound) {
+				return nil, domain.WithContext(err, "user_id", userID)
+			}
+			ok = false
+		}
+	}
+	if !ok {
+		s.logger.WithField("user_id", userID).Warn("Failed login")
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	s.logger.WithField("user_id", userID).Info("User logged in")
+	return s.issuer.Issue(userID, s.credentials.Role(userID))
+}
+
+// Refresh exchanges a refresh token for a new token pair. The role is
+// looked up again, and users erased since login are refused.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	claims, err := s.issuer.Redeem(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.credentials.Listed(claims.Subject) {
+		if _, err := s.repo.GetUser(claims.Subject); err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return nil, domain.ErrUnauthorized
+			}
+			return nil, domain.WithContext(err, "user_id", claims.Subject)
+		}
+	}
+
+	return s.issuer.Issue(claims.Subject, s.credentials.Role(claims.Subject))
+}
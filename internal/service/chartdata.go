@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetFavoriteChartData returns the data points of a favorited chart,
+// loading them from the blob store when they were offloaded
+func (s *FavoritesService) GetFavoriteChartData(ctx context.Context, userID, assetID string) ([]domain.ChartDataPoint, error) {
+	if userID == "" || assetID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	isFavorite, err := s.repo.IsFavorite(userID, assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+	if !isFavorite {
+		return nil, domain.WithContext(domain.ErrFavoriteNotFound, "user_id", userID, "asset_id", assetID)
+	}
+
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+	chart, ok := asset.(*domain.Chart)
+	if !ok {
+		return nil, domain.WithContext(domain.ErrInvalidAssetType, "asset_id", assetID, "type", string(asset.GetType()))
+	}
+
+	chart, err = loadChartData(s.repo, chart)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"user_id":  userID,
+			"asset_id": assetID,
+		}).Error("Failed to load chart data")
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	if chart.Data == nil {
+		return []domain.ChartDataPoint{}, nil
+	}
+	return chart.Data, nil
+}
+
+// loadChartData returns chart with its offloaded data filled in. Charts
+// stored inline are returned as they are.
+func loadChartData(repo repository.FavoritesRepository, chart *domain.Chart) (*domain.Chart, error) {
+	if chart.DataRef == nil || len(chart.Data) > 0 {
+		return chart, nil
+	}
+	loader, ok := repo.(repository.ChartDataLoader)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+
+	data, err := loader.LoadChartData(*chart.DataRef)
+	if err != nil {
+		return nil, err
+	}
+	loaded := *chart
+	loaded.Data = data
+	return &loaded, nil
+}
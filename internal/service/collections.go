@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// collections returns the repository's collection capability
+func (s *FavoritesService) collections() (repository.CollectionStore, error) {
+	store, ok := s.repo.(repository.CollectionStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store, nil
+}
+
+// newCollectionID returns a random 16 character hex ID
+func newCollectionID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// CreateCollection creates an empty, named collection for a user
+func (s *FavoritesService) CreateCollection(ctx context.Context, userID, name string) (*domain.Collection, error) {
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"name":    name,
+	}).Info("Creating collection")
+
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.WithContext(domain.ErrMissingRequiredField, "field", "name")
+	}
+	if len(name) > domain.MaxCollectionNameLength {
+		return nil, domain.WithContext(domain.ErrFieldTooLong, "field", "name", "max", strconv.Itoa(domain.MaxCollectionNameLength))
+	}
+
+	store, err := s.collections()
+	if err != nil {
+		return nil, err
+	}
+	id, err := newCollectionID()
+	if err != nil {
+		return nil, err
+	}
+
+	collection := domain.NewCollectionAt(id, userID, name, s.clock.Now())
+	if err := store.CreateCollection(collection); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to create collection")
+		return nil, domain.WithContext(err, "user_id", userID, "name", name)
+	}
+	return collection, nil
+}
+
+// ListCollections returns a user's collections, oldest first
+func (s *FavoritesService) ListCollections(ctx context.Context, userID string) ([]*domain.Collection, error) {
+	store, err := s.collections()
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := store.ListCollections(userID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID)
+	}
+	return collections, nil
+}
+
+// DeleteCollection deletes a collection. The favorites in it are kept.
+func (s *FavoritesService) DeleteCollection(ctx context.Context, userID, collectionID string) error {
+	s.logger.WithFields(logrus.Fields{
+		"user_id":       userID,
+		"collection_id": collectionID,
+	}).Info("Deleting collection")
+
+	store, err := s.collections()
+	if err != nil {
+		return err
+	}
+	if err := store.DeleteCollection(userID, collectionID); err != nil {
+		return domain.WithContext(err, "user_id", userID, "collection_id", collectionID)
+	}
+	return nil
+}
+
+// AddToCollection puts one of the user's favorites into a collection
+func (s *FavoritesService) AddToCollection(ctx context.Context, userID, collectionID, assetID string) error {
+	if assetID == "" {
+		return domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_id")
+	}
+
+	store, err := s.collections()
+	if err != nil {
+		return err
+	}
+	if err := store.AddToCollection(userID, collectionID, assetID); err != nil {
+		return domain.WithContext(err, "user_id", userID, "collection_id", collectionID, "asset_id", assetID)
+	}
+	return nil
+}
+
+// RemoveFromCollection takes a favorite out of a collection; it stays in favorites
+func (s *FavoritesService) RemoveFromCollection(ctx context.Context, userID, collectionID, assetID string) error {
+	store, err := s.collections()
+	if err != nil {
+		return err
+	}
+	if err := store.RemoveFromCollection(userID, collectionID, assetID); err != nil {
+		return domain.WithContext(err, "user_id", userID, "collection_id", collectionID, "asset_id", assetID)
+	}
+	return nil
+}
+
+// ListCollectionFavorites returns a page of the favorites in a collection,
+// filtered and ordered like ListUserFavorites, with its pagination
+func (s *FavoritesService) ListCollectionFavorites(ctx context.Context, userID, collectionID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, domain.Pagination, error) {
+	if query.Type != "" && !query.Type.IsValid() {
+		return nil, domain.Pagination{}, domain.ErrInvalidAssetType
+	}
+	if query.Sort != "" && !query.Sort.IsValid() {
+		return nil, domain.Pagination{}, domain.WithContext(domain.ErrInvalidInput, "field", "sort")
+	}
+
+	store, err := s.collections()
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	favorites, err := store.GetCollectionFavorites(userID, collectionID, query)
+	if err != nil {
+		return nil, domain.Pagination{}, domain.WithContext(err, "user_id", userID, "collection_id", collectionID)
+	}
+
+	// Collections are small, so a full page is counted by loading every match
+	total := query.Offset + len(favorites)
+	if (len(favorites) == 0 && query.Offset > 0) || (query.Limit > 0 && len(favorites) == query.Limit) {
+		all := query
+		all.Limit, all.Offset = 0, 0
+		matching, err := store.GetCollectionFavorites(userID, collectionID, all)
+		if err != nil {
+			return nil, domain.Pagination{}, domain.WithContext(err, "user_id", userID, "collection_id", collectionID)
+		}
+		total = len(matching)
+	}
+	return favorites, domain.NewPagination(total, query.Limit, query.Offset), nil
+}
+
+// SupportsCollections reports whether the repository can keep collections
+func (s *FavoritesService) SupportsCollections() bool {
+	_, err := s.collections()
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ConflictPolicy decides how concurrent edits of a favorite's note are
+// handled
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite lets the last write win silently
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictReport lets the last write win, but tells its client which
+	// concurrent edit it replaced
+	ConflictReport ConflictPolicy = "report"
+)
+
+// ParseConflictPolicy validates a configured conflict policy
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case ConflictOverwrite, ConflictReport:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown note conflict policy %q", value)
+}
+
+// WithConflictPolicy sets how concurrent note edits are handled
+func WithConflictPolicy(policy ConflictPolicy) FavoritesOption {
+	return func(s *FavoritesService) { s.conflicts = policy }
+}
+
+// NoteUpdate is the outcome of a note edit
+type NoteUpdate struct {
+	Note     string `json:"note"`
+	Conflict bool   `json:"conflict"`
+	// ConflictingNote is the concurrent edit that was replaced
+	ConflictingNote *string `json:"conflicting_note,omitempty"`
+}
+
+// detectConflict compares the note the write replaced with the one the
+// client edited. The write happens first, so the edit always wins; this is
+// advisory, not locking.
+func (s *FavoritesService) detectConflict(userID, assetID, note, replaced string, base *string) NoteUpdate {
+	result := NoteUpdate{Note: note}
+	if s.conflicts != ConflictReport || base == nil || *base == replaced || replaced == note {
+		return result
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"asset_id": assetID,
+	}).Warn("Concurrent note edit overwritten")
+
+	result.Conflict = true
+	result.ConflictingNote = &replaced
+	return result
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// exportPageSize is how many favorites an export reads from the repository
+// at a time
+const exportPageSize = 500
+
+// ExportFavorites passes all of a user's favorites to fn, a page at a time,
+// with the data of offloaded charts loaded. An error from fn stops the
+// export and is returned.
+func (s *FavoritesService) ExportFavorites(ctx context.Context, userID string, fn func([]*domain.UserFavorite) error) error {
+	if userID == "" {
+		return domain.ErrInvalidUserID
+	}
+
+	exported := 0
+	for offset := 0; ; offset += exportPageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := s.repo.GetUserFavorites(userID, domain.FavoritesQuery{Limit: exportPageSize, Offset: offset})
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to export user favorites")
+			return domain.WithContext(err, "user_id", userID)
+		}
+
+		for i, favorite := range page {
+			chart, ok := favorite.Asset.(*domain.Chart)
+			if !ok {
+				continue
+			}
+			loaded, err := loadChartData(s.repo, chart)
+			if err != nil {
+				return domain.WithContext(err, "user_id", userID, "asset_id", favorite.AssetID)
+			}
+			if loaded == chart {
+				continue
+			}
+			// Favorites may be shared with the repository, so fill in a copy
+			withData := *favorite
+			withData.Asset = loaded
+			page[i] = &withData
+		}
+
+		if len(page) > 0 {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		exported += len(page)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"count":   exported,
+	}).Info("Exported user favorites")
+
+	return nil
+}
@@ -2,43 +2,136 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"gwi-favorites-service/internal/anomaly"
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/directory"
 	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/engagement"
+	"gwi-favorites-service/internal/kpi"
+	"gwi-favorites-service/internal/moderation"
 	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/trash"
+	"gwi-favorites-service/internal/validation"
+	"gwi-favorites-service/pkg/clock"
 
 	"github.com/sirupsen/logrus"
 )
 
 // FavoritesService handles business logic for favorites
 type FavoritesService struct {
-	repo   repository.FavoritesRepository
-	logger *logrus.Logger
+	repo         repository.FavoritesRepository
+	detector     *anomaly.Detector
+	moderation   *moderation.Store
+	limits       validation.LengthLimits
+	coerceCharts bool
+	directory    directory.UserDirectory
+	auditLog     audit.Log
+	conflicts    ConflictPolicy
+	typeRules    AssetTypeRules
+	kpis         *kpi.Tracker
+	engagement   *engagement.Notifier
+	trash        *trash.Store
+	clock        clock.Clock
+	logger       *logrus.Logger
+}
+
+// FavoritesOption configures optional FavoritesService behaviour
+type FavoritesOption func(*FavoritesService)
+
+// WithAnomalyDetector throttles users whose favorites activity looks abusive
+func WithAnomalyDetector(detector *anomaly.Detector) FavoritesOption {
+	return func(s *FavoritesService) { s.detector = detector }
+}
+
+// WithKPITracker feeds favorites changes into the business KPIs
+func WithKPITracker(tracker *kpi.Tracker) FavoritesOption {
+	return func(s *FavoritesService) { s.kpis = tracker }
+}
+
+// WithEngagement tells asset owners subscribed to webhooks when their
+// assets are favorited or unfavorited
+func WithEngagement(notifier *engagement.Notifier) FavoritesOption {
+	return func(s *FavoritesService) { s.engagement = notifier }
+}
+
+// WithModeration prevents assets hidden by moderation from being favorited
+func WithModeration(store *moderation.Store) FavoritesOption {
+	return func(s *FavoritesService) { s.moderation = store }
+}
+
+// WithLengthLimits caps description and content lengths on favorited assets
+func WithLengthLimits(limits validation.LengthLimits) FavoritesOption {
+	return func(s *FavoritesService) { s.limits = limits }
+}
+
+// WithChartCoercion normalizes the data of submitted charts, as
+// validation.CoerceChartData does
+func WithChartCoercion(enabled bool) FavoritesOption {
+	return func(s *FavoritesService) { s.coerceCharts = enabled }
+}
+
+// WithUserDirectory only lets favorites be added for users the directory
+// knows. Users are created in storage on their first favorite.
+func WithUserDirectory(users directory.UserDirectory) FavoritesOption {
+	return func(s *FavoritesService) { s.directory = users }
+}
+
+// WithAuditLog records every favorite added, removed or updated
+func WithAuditLog(log audit.Log) FavoritesOption {
+	return func(s *FavoritesService) { s.auditLog = log }
+}
+
+// WithClock stamps description updates and measures recent favorites with
+// c instead of the wall clock
+func WithClock(c clock.Clock) FavoritesOption {
+	return func(s *FavoritesService) { s.clock = c }
 }
 
 // NewFavoritesService creates a new favorites service
-func NewFavoritesService(repo repository.FavoritesRepository, logger *logrus.Logger) *FavoritesService {
-	return &FavoritesService{
-		repo:   repo,
-		logger: logger,
+func NewFavoritesService(repo repository.FavoritesRepository, logger *logrus.Logger, opts ...FavoritesOption) *FavoritesService {
+	s := &FavoritesService{
+		repo:      repo,
+		conflicts: ConflictOverwrite,
+		clock:     clock.System,
+		logger:    logger,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // GetUserFavorites retrieves all favorites for a user
 func (s *FavoritesService) GetUserFavorites(ctx context.Context, userID string, limit, offset int) ([]*domain.UserFavorite, error) {
+	return s.ListUserFavorites(ctx, userID, domain.FavoritesQuery{Limit: limit, Offset: offset})
+}
+
+// ListUserFavorites retrieves a user's favorites matching the query
+func (s *FavoritesService) ListUserFavorites(ctx context.Context, userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, error) {
 	s.logger.WithFields(logrus.Fields{
-		"user_id": userID,
-		"limit":   limit,
-		"offset":  offset,
+		"user_id":    userID,
+		"asset_type": query.Type,
+		"limit":      query.Limit,
+		"offset":     query.Offset,
 	}).Info("Getting user favorites")
 
 	if userID == "" {
 		return nil, domain.ErrInvalidUserID
 	}
 
-	favorites, err := s.repo.GetUserFavorites(userID, limit, offset)
+	if query.Type != "" && !query.Type.IsValid() {
+		return nil, domain.ErrInvalidAssetType
+	}
+	if query.Sort != "" && !query.Sort.IsValid() {
+		return nil, domain.WithContext(domain.ErrInvalidInput, "field", "sort")
+	}
+
+	favorites, err := s.repo.GetUserFavorites(userID, query)
 	if err != nil {
 		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user favorites")
-		return nil, err
+		return nil, domain.WithContext(err, "user_id", userID)
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -49,6 +142,81 @@ func (s *FavoritesService) GetUserFavorites(ctx context.Context, userID string,
 	return favorites, nil
 }
 
+// GetFavorite retrieves one of user's favorites, with its asset and note
+func (s *FavoritesService) GetFavorite(ctx context.Context, userID, assetID string) (*domain.UserFavorite, error) {
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+	if assetID == "" {
+		return nil, domain.ErrInvalidAssetID
+	}
+
+	favorite, err := s.findFavorite(userID, assetID)
+	return favorite, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+}
+
+// findFavorite looks up one favorite, scanning the user's favorites when
+// the backend cannot look it up directly
+func (s *FavoritesService) findFavorite(userID, assetID string) (*domain.UserFavorite, error) {
+	if getter, ok := s.repo.(repository.FavoriteGetter); ok {
+		favorite, err := getter.GetFavorite(userID, assetID)
+		if !errors.Is(err, domain.ErrNotSupported) {
+			return favorite, err
+		}
+	}
+
+	favorites, err := s.repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return nil, err
+	}
+	for _, favorite := range favorites {
+		if favorite.AssetID == assetID {
+			return favorite, nil
+		}
+	}
+	return nil, domain.ErrFavoriteNotFound
+}
+
+// ListUserFavoritesPage returns a page of user's favorites along with where
+// the page sits among all favorites matching the query
+func (s *FavoritesService) ListUserFavoritesPage(ctx context.Context, userID string, query domain.FavoritesQuery) ([]*domain.UserFavorite, domain.Pagination, error) {
+	favorites, err := s.ListUserFavorites(ctx, userID, query)
+	if err != nil {
+		return nil, domain.Pagination{}, err
+	}
+
+	total, err := s.countMatching(userID, query, len(favorites))
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to count user favorites")
+		return nil, domain.Pagination{}, domain.WithContext(err, "user_id", userID)
+	}
+	return favorites, domain.NewPagination(total, query.Limit, query.Offset), nil
+}
+
+// countMatching counts the favorites matching the query's filters. A short
+// page that is not past the end already tells the total, so the repository
+// is only asked for full pages and pages past the end. Backends that cannot
+// count natively have the matching favorites loaded and counted.
+func (s *FavoritesService) countMatching(userID string, query domain.FavoritesQuery, pageSize int) (int, error) {
+	if (pageSize > 0 || query.Offset == 0) && (query.Limit <= 0 || pageSize < query.Limit) {
+		return query.Offset + pageSize, nil
+	}
+
+	if counter, ok := s.repo.(repository.FavoritesCounter); ok {
+		count, err := counter.CountUserFavorites(userID, query)
+		if !errors.Is(err, domain.ErrNotSupported) {
+			return count, err
+		}
+	}
+
+	query.Limit, query.Offset = 0, 0
+	all, err := s.repo.GetUserFavorites(userID, query)
+	if err != nil {
+		return 0, err
+	}
+	return len(all), nil
+}
+
 // AddFavorite adds an asset to user's favorites
 func (s *FavoritesService) AddFavorite(ctx context.Context, userID string, asset domain.Asset) error {
 	s.logger.WithFields(logrus.Fields{
@@ -60,17 +228,109 @@ func (s *FavoritesService) AddFavorite(ctx context.Context, userID string, asset
 	if userID == "" {
 		return domain.ErrInvalidUserID
 	}
-
-	if err := asset.Validate(); err != nil {
-		s.logger.WithError(err).WithField("asset_id", asset.GetID()).Error("Asset validation failed")
+	if err := s.observeActivity(userID, asset.GetID()); err != nil {
 		return err
 	}
 
-	// Check if asset exists, if not create it
-	if _, err := s.repo.GetAsset(asset.GetID()); err == domain.ErrAssetNotFound {
-		if err := s.repo.CreateAsset(asset); err != nil {
-			s.logger.WithError(err).WithField("asset_id", asset.GetID()).Error("Failed to create asset")
-			return err
+	return s.addFavorite(ctx, userID, asset, true)
+}
+
+// AddFavoriteByID favorites an asset already in the catalog, as stored,
+// and returns it. Unlike AddFavorite it never creates or changes the asset.
+func (s *FavoritesService) AddFavoriteByID(ctx context.Context, userID, assetID string) (domain.Asset, error) {
+	s.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"asset_id": assetID,
+	}).Info("Adding catalog asset to favorites")
+
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+	if assetID == "" {
+		return nil, domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_id")
+	}
+	if err := s.observeActivity(userID, assetID); err != nil {
+		return nil, err
+	}
+
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+	if asset.GetDeletedAt() != nil {
+		return nil, domain.WithContext(domain.ErrAssetDeleted, "user_id", userID, "asset_id", assetID)
+	}
+
+	if err := s.addFavorite(ctx, userID, asset, false); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// observeActivity counts a change to userID's favorites towards anomaly
+// detection, failing when the user is throttled
+func (s *FavoritesService) observeActivity(userID, assetID string) error {
+	if err := s.detector.Observe(userID); err != nil {
+		s.logger.WithField("user_id", userID).Warn("Favorites activity throttled")
+		return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+	return nil
+}
+
+// addFavorite favorites asset for userID. A submitted asset is validated
+// and created when new; a catalog asset is used as stored.
+func (s *FavoritesService) addFavorite(ctx context.Context, userID string, asset domain.Asset, submitted bool) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
+	}
+
+	if submitted {
+		if err := asset.Validate(); err != nil {
+			s.logger.WithError(err).WithField("asset_id", asset.GetID()).Error("Asset validation failed")
+			return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
+		}
+	}
+
+	if err := s.checkAssetType(ctx, asset.GetType()); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"asset_type": asset.GetType(),
+		}).Warn("Asset type blocked for role")
+		return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
+	}
+
+	if submitted {
+		asset.Normalize()
+		if s.coerceCharts {
+			validation.CoerceChartData(ctx, asset)
+		}
+		if err := s.limits.ApplyToAsset(ctx, asset); err != nil {
+			return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
+		}
+	}
+
+	if s.moderation != nil && s.moderation.IsHidden(asset.GetID()) {
+		return domain.WithContext(domain.ErrAssetHidden, "user_id", userID, "asset_id", asset.GetID())
+	}
+
+	owner := asset.GetOwnerID()
+	if submitted {
+		// Check if asset exists, if not create it. Ownership is assigned
+		// by the catalog, never claimed by a favoriting user.
+		existing, err := s.repo.GetAsset(asset.GetID())
+		owner = ""
+		if err == nil {
+			owner = existing.GetOwnerID()
+		}
+		if errors.Is(err, domain.ErrAssetNotFound) {
+			asset.SetOwnerID("")
+			if err := s.repo.CreateAsset(asset); err != nil {
+				s.logger.WithError(err).WithField("asset_id", asset.GetID()).Error("Failed to create asset")
+				return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
+			}
+		} else if err == nil && existing.GetDeletedAt() != nil {
+			// Orphaned assets stay visible to existing favorites only
+			return domain.WithContext(domain.ErrAssetDeleted, "user_id", userID, "asset_id", asset.GetID())
 		}
 	}
 
@@ -79,9 +339,13 @@ func (s *FavoritesService) AddFavorite(ctx context.Context, userID string, asset
 			"user_id":  userID,
 			"asset_id": asset.GetID(),
 		}).Error("Failed to add favorite")
-		return err
+		return domain.WithContext(err, "user_id", userID, "asset_id", asset.GetID())
 	}
 
+	recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteAdded, userID, asset.GetID(), nil, asset)
+	s.kpis.FavoriteAdded(userID)
+	s.engagement.Record(owner, userID, asset.GetID(), engagement.Favorited)
+
 	s.logger.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"asset_id": asset.GetID(),
@@ -105,12 +369,31 @@ func (s *FavoritesService) RemoveFavorite(ctx context.Context, userID, assetID s
 		return domain.ErrInvalidInput
 	}
 
+	// The removed asset is kept in the audit trail, and its owner told
+	var removed domain.Asset
+	if s.auditLog != nil || s.engagement != nil {
+		removed, _ = s.repo.GetAsset(assetID)
+	}
+	var trashed *domain.UserFavorite
+	if s.trash != nil {
+		trashed = s.trashedFavorite(userID, assetID)
+	}
+
 	if err := s.repo.RemoveFavorite(userID, assetID); err != nil {
 		s.logger.WithError(err).WithFields(logrus.Fields{
 			"user_id":  userID,
 			"asset_id": assetID,
 		}).Error("Failed to remove favorite")
-		return err
+		return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+
+	if trashed != nil {
+		s.trash.Put(trash.Item{Favorite: *trashed, RemovedAt: s.clock.Now()})
+	}
+	recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteRemoved, userID, assetID, removed, nil)
+	s.kpis.FavoriteRemoved(userID)
+	if removed != nil {
+		s.engagement.Record(removed.GetOwnerID(), userID, assetID, engagement.Unfavorited)
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -121,54 +404,60 @@ func (s *FavoritesService) RemoveFavorite(ctx context.Context, userID, assetID s
 	return nil
 }
 
-// UpdateFavoriteDescription updates the description of a favorite asset
-func (s *FavoritesService) UpdateFavoriteDescription(ctx context.Context, userID, assetID, description string) error {
+// UpdateFavoriteNote sets the user's personal note on a favorite
+func (s *FavoritesService) UpdateFavoriteNote(ctx context.Context, userID, assetID, note string) error {
+	_, err := s.EditFavoriteNote(ctx, userID, assetID, note, nil)
+	return err
+}
+
+// EditFavoriteNote sets the user's personal note on a favorite. The note
+// belongs to the favorite, so the shared asset other users see is left
+// alone. base is the note the client started editing from, if it sent
+// one. With the report conflict policy, an edit whose base no longer
+// matches still wins, and the result carries the note it replaced.
+func (s *FavoritesService) EditFavoriteNote(ctx context.Context, userID, assetID, note string, base *string) (NoteUpdate, error) {
 	s.logger.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"asset_id": assetID,
-	}).Info("Updating favorite asset description")
+	}).Info("Updating favorite note")
 
 	if userID == "" {
-		return domain.ErrInvalidUserID
+		return NoteUpdate{}, domain.ErrInvalidUserID
 	}
 
 	if assetID == "" {
-		return domain.ErrInvalidInput
+		return NoteUpdate{}, domain.ErrInvalidInput
 	}
 
-	// Check if it's a favorite
-	isFavorite, err := s.repo.IsFavorite(userID, assetID)
+	note, err := s.limits.ApplyToDescription(ctx, note)
 	if err != nil {
-		return err
-	}
-	if !isFavorite {
-		return domain.ErrFavoriteNotFound
+		return NoteUpdate{}, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
 	}
 
-	// Get the asset
-	asset, err := s.repo.GetAsset(assetID)
+	previous, err := s.repo.UpdateFavoriteNote(userID, assetID, note)
 	if err != nil {
-		return err
+		if !errors.Is(err, domain.ErrFavoriteNotFound) && !errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"user_id":  userID,
+				"asset_id": assetID,
+			}).Error("Failed to update favorite note")
+		}
+		return NoteUpdate{}, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
 	}
+	result := s.detectConflict(userID, assetID, note, previous, base)
 
-	// Update description
-	asset.SetDescription(description)
-
-	// Update in repository
-	if err := s.repo.UpdateAsset(asset); err != nil {
-		s.logger.WithError(err).WithFields(logrus.Fields{
-			"user_id":  userID,
-			"asset_id": assetID,
-		}).Error("Failed to update asset description")
-		return err
-	}
+	recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteUpdated, userID, assetID,
+		map[string]string{"note": previous},
+		map[string]string{"note": note},
+	)
+	s.kpis.FavoriteEdited(userID)
 
 	s.logger.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"asset_id": assetID,
-	}).Info("Successfully updated favorite asset description")
+	}).Info("Successfully updated favorite note")
 
-	return nil
+	return result, nil
 }
 
 // GetFavoriteCount returns the count of user's favorites
@@ -180,7 +469,7 @@ func (s *FavoritesService) GetFavoriteCount(ctx context.Context, userID string)
 	count, err := s.repo.GetFavoriteCount(userID)
 	if err != nil {
 		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get favorite count")
-		return 0, err
+		return 0, domain.WithContext(err, "user_id", userID)
 	}
 
 	return count, nil
@@ -192,5 +481,56 @@ func (s *FavoritesService) IsFavorite(ctx context.Context, userID, assetID strin
 		return false, domain.ErrInvalidInput
 	}
 
-	return s.repo.IsFavorite(userID, assetID)
+	isFavorite, err := s.repo.IsFavorite(userID, assetID)
+	return isFavorite, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+}
+
+// CheckFavorites reports for each of assetIDs whether it is in the user's
+// favorites. Repeated IDs are checked once.
+func (s *FavoritesService) CheckFavorites(ctx context.Context, userID string, assetIDs []string) (map[string]bool, error) {
+	if userID == "" || len(assetIDs) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	results := make(map[string]bool, len(assetIDs))
+	for _, assetID := range assetIDs {
+		if assetID == "" {
+			return nil, domain.WithContext(domain.ErrInvalidInput, "field", "asset_ids")
+		}
+		if _, done := results[assetID]; done {
+			continue
+		}
+		isFavorite, err := s.repo.IsFavorite(userID, assetID)
+		if err != nil {
+			return nil, domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+		}
+		results[assetID] = isFavorite
+	}
+	return results, nil
+}
+
+// ensureUser confirms the user with the directory and creates users it
+// knows but storage does not
+func (s *FavoritesService) ensureUser(ctx context.Context, userID string) error {
+	if s.directory == nil {
+		return nil
+	}
+
+	user, err := s.directory.LookupUser(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.WithError(err).WithField("user_id", userID).Error("User directory lookup failed")
+		}
+		return err
+	}
+
+	if _, err := s.repo.GetUser(userID); !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if err := s.repo.CreateUser(user); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to create user from directory")
+		return err
+	}
+	s.logger.WithField("user_id", userID).Info("Created user from directory")
+	return nil
 }
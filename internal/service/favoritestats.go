@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+)
+
+// GetFavoriteStats summarizes the user's favorites: their total, counts by
+// asset type and the number added in each of the last weeks weeks,
+// including the current one. Weeks start on Monday, in UTC. Backends that
+// cannot summarize favorites themselves have them loaded instead.
+func (s *FavoritesService) GetFavoriteStats(ctx context.Context, userID string, weeks int) (repository.FavoriteStats, error) {
+	if userID == "" {
+		return repository.FavoriteStats{}, domain.ErrInvalidUserID
+	}
+	if weeks <= 0 {
+		return repository.FavoriteStats{}, domain.WithContext(domain.ErrInvalidInput, "field", "weeks")
+	}
+
+	now := s.clock.Now().UTC()
+	monday := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	starts := make([]time.Time, weeks)
+	for i := range starts {
+		starts[i] = monday.AddDate(0, 0, -7*(weeks-1-i))
+	}
+
+	if summarizer, ok := s.repo.(repository.FavoritesSummarizer); ok {
+		stats, err := summarizer.FavoriteStats(userID, starts)
+		if err == nil {
+			return stats, nil
+		}
+		if !errors.Is(err, domain.ErrNotSupported) {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to summarize favorites")
+			return repository.FavoriteStats{}, domain.WithContext(err, "user_id", userID)
+		}
+	}
+
+	favorites, err := s.repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to summarize favorites")
+		return repository.FavoriteStats{}, domain.WithContext(err, "user_id", userID)
+	}
+	stats := repository.NewFavoriteStats(starts)
+	for _, favorite := range favorites {
+		stats.Add(favorite)
+	}
+	return stats, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// FeaturedAsset is a catalog asset an admin has promoted to every user.
+// Featuring is catalog-wide and independent of any user's favorites.
+type FeaturedAsset struct {
+	Asset      domain.Asset `json:"asset"`
+	FeaturedAt time.Time    `json:"featured_at"`
+}
+
+// FeatureAsset marks a catalog asset as featured. Featuring an asset that
+// is already featured keeps its original featured time.
+func (s *AssetService) FeatureAsset(ctx context.Context, assetID string) (FeaturedAsset, error) {
+	if assetID == "" {
+		return FeaturedAsset{}, domain.ErrInvalidInput
+	}
+
+	asset, err := s.GetAsset(ctx, assetID)
+	if err != nil {
+		return FeaturedAsset{}, err
+	}
+	if asset.GetDeletedAt() != nil {
+		return FeaturedAsset{}, domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+	}
+	if s.moderation != nil && s.moderation.IsHidden(assetID) {
+		return FeaturedAsset{}, domain.WithContext(domain.ErrAssetHidden, "asset_id", assetID)
+	}
+
+	s.featuredMu.Lock()
+	featuredAt, ok := s.featured[assetID]
+	if !ok {
+		featuredAt = s.clock.Now()
+		s.featured[assetID] = featuredAt
+	}
+	s.featuredMu.Unlock()
+
+	if !ok {
+		s.logger.WithField("asset_id", assetID).Info("Asset featured")
+	}
+	return FeaturedAsset{Asset: asset, FeaturedAt: featuredAt}, nil
+}
+
+// UnfeatureAsset removes an asset from the featured assets
+func (s *AssetService) UnfeatureAsset(ctx context.Context, assetID string) error {
+	s.featuredMu.Lock()
+	_, ok := s.featured[assetID]
+	delete(s.featured, assetID)
+	s.featuredMu.Unlock()
+
+	if !ok {
+		return domain.WithContext(domain.ErrAssetNotFeatured, "asset_id", assetID)
+	}
+	s.logger.WithField("asset_id", assetID).Info("Asset no longer featured")
+	return nil
+}
+
+// FeaturedAssets returns the featured assets, most recently featured first.
+// Assets deleted since they were featured are dropped from the list, and
+// assets hidden by moderation are left out until they are restored.
+func (s *AssetService) FeaturedAssets(ctx context.Context) ([]FeaturedAsset, error) {
+	s.featuredMu.RLock()
+	ids := make([]string, 0, len(s.featured))
+	times := make(map[string]time.Time, len(s.featured))
+	for id, featuredAt := range s.featured {
+		ids = append(ids, id)
+		times[id] = featuredAt
+	}
+	s.featuredMu.RUnlock()
+
+	sort.Slice(ids, func(i, j int) bool {
+		if !times[ids[i]].Equal(times[ids[j]]) {
+			return times[ids[i]].After(times[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+
+	featured := make([]FeaturedAsset, 0, len(ids))
+	for _, id := range ids {
+		if s.moderation != nil && s.moderation.IsHidden(id) {
+			continue
+		}
+
+		asset, err := s.GetAsset(ctx, id)
+		if errors.Is(err, domain.ErrAssetNotFound) {
+			s.dropFeatured(id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if asset.GetDeletedAt() != nil {
+			s.dropFeatured(id)
+			continue
+		}
+		featured = append(featured, FeaturedAsset{Asset: asset, FeaturedAt: times[id]})
+	}
+	return featured, nil
+}
+
+// IsFeatured reports whether an asset is featured
+func (s *AssetService) IsFeatured(assetID string) bool {
+	s.featuredMu.RLock()
+	defer s.featuredMu.RUnlock()
+	_, ok := s.featured[assetID]
+	return ok
+}
+
+// dropFeatured forgets an asset that left the catalog
+func (s *AssetService) dropFeatured(assetID string) {
+	s.featuredMu.Lock()
+	_, ok := s.featured[assetID]
+	delete(s.featured, assetID)
+	s.featuredMu.Unlock()
+
+	if ok {
+		s.logger.WithField("asset_id", assetID).Info("Deleted asset no longer featured")
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/catalog"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/validation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithAssetSource hydrates assets from the upstream catalog, through
+// SyncAsset and the freshness checks of reads
+func WithAssetSource(source catalog.Source) AssetOption {
+	return func(s *AssetService) { s.source = source }
+}
+
+// HasSource reports whether assets can be hydrated from an upstream catalog
+func (s *AssetService) HasSource() bool {
+	return s.source != nil
+}
+
+// SyncAsset fetches an asset from the upstream catalog and stores it,
+// creating it when new, stamped with the sync time and upstream version. It
+// returns the asset as stored.
+func (s *AssetService) SyncAsset(ctx context.Context, assetID string) (domain.Asset, error) {
+	s.logger.WithField("asset_id", assetID).Info("Syncing asset from catalog")
+
+	if s.source == nil {
+		return nil, domain.ErrNotSupported
+	}
+
+	asset, version, err := s.source.Fetch(ctx, assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	if err := s.checkAsset(ctx, asset); err != nil {
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	now := s.clock.Now()
+	asset.MarkSynced(version, now)
+	if asset.GetUpdatedAt().IsZero() {
+		asset.SetUpdatedAt(now)
+	}
+
+	existing, err := s.repo.GetAsset(assetID)
+	switch {
+	case errors.Is(err, domain.ErrAssetNotFound):
+		err = s.repo.CreateAsset(asset)
+	case err != nil:
+	case existing.GetDeletedAt() != nil:
+		return nil, domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+	case existing.GetType() != asset.GetType():
+		return nil, domain.WithContext(domain.ErrInvalidAssetType, "asset_id", assetID, "type", string(existing.GetType()))
+	default:
+		if err = s.repo.UpdateAsset(asset); err == nil && !sameSourceVersion(existing, version) {
+			recordAudit(ctx, s.auditLog, s.logger, audit.AssetUpdated, "", assetID, existing, asset)
+		}
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("asset_id", assetID).Error("Failed to store synced asset")
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"asset_id":       assetID,
+		"source_version": version,
+	}).Info("Successfully synced asset")
+
+	// Read back so callers see the asset as listings do, e.g. with
+	// offloaded chart data moved out
+	stored, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return asset, nil
+	}
+	return stored, nil
+}
+
+// sameSourceVersion reports whether a re-sync fetched the version already
+// stored, in which case only the sync time changed
+func sameSourceVersion(existing domain.Asset, version string) bool {
+	return existing.GetLastSyncedAt() != nil && version != "" && existing.GetSourceVersion() == version
+}
+
+// RefreshIfStale re-syncs an asset hydrated from the upstream catalog that
+// was last synced more than maxAge ago, and returns the fresh asset.
+// Assets that never came from the catalog, and orphaned ones, are returned
+// as they are. Refreshing is best effort: when it fails the stale asset is
+// returned, with a warning recorded in ctx.
+func (s *AssetService) RefreshIfStale(ctx context.Context, asset domain.Asset, maxAge time.Duration) domain.Asset {
+	if s.source == nil || asset == nil || asset.GetDeletedAt() != nil {
+		return asset
+	}
+	synced := asset.GetLastSyncedAt()
+	if synced == nil || s.clock.Now().Sub(*synced) <= maxAge {
+		return asset
+	}
+
+	fresh, err := s.SyncAsset(ctx, asset.GetID())
+	if err != nil {
+		s.logger.WithError(err).WithField("asset_id", asset.GetID()).Warn("Failed to refresh stale asset")
+		validation.Warn(ctx, fmt.Sprintf("asset %s could not be refreshed and was last synced at %s",
+			asset.GetID(), synced.UTC().Format(time.RFC3339)))
+		return asset
+	}
+	return fresh
+}
+
+// RefreshFavorites refreshes the stale assets of favorites in place, as
+// RefreshIfStale does. Assets are refreshed one at a time, and once each
+// however many favorites share them.
+func (s *AssetService) RefreshFavorites(ctx context.Context, favorites []*domain.UserFavorite, maxAge time.Duration) {
+	if s.source == nil {
+		return
+	}
+	refreshed := make(map[string]domain.Asset)
+	for _, favorite := range favorites {
+		if asset, ok := refreshed[favorite.AssetID]; ok {
+			favorite.Asset = asset
+			continue
+		}
+		favorite.Asset = s.RefreshIfStale(ctx, favorite.Asset, maxAge)
+		refreshed[favorite.AssetID] = favorite.Asset
+	}
+}
+
+// GetFreshAsset is GetAsset, refreshing the asset first when it was last
+// synced more than maxAge ago
+func (s *AssetService) GetFreshAsset(ctx context.Context, assetID string, maxAge time.Duration) (domain.Asset, error) {
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	asset = s.RefreshIfStale(ctx, asset, maxAge)
+	if chart, ok := asset.(*domain.Chart); ok {
+		if asset, err = loadChartData(s.repo, chart); err != nil {
+			return nil, domain.WithContext(err, "asset_id", assetID)
+		}
+	}
+	return asset, nil
+}
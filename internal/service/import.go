@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ImportRow is one favorite to import: either a submitted asset, created
+// when new, or the ID of an asset already in the catalog. Err marks a row
+// that could not be read from the imported file.
+type ImportRow struct {
+	Asset   domain.Asset
+	AssetID string
+	Note    string
+	Err     error
+}
+
+// Import row outcomes
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+// ImportResult is the outcome of one row that was not imported. Rows are
+// numbered from 1 in the order of the file.
+type ImportResult struct {
+	Row     int    `json:"row"`
+	AssetID string `json:"asset_id,omitempty"`
+	Status  string `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes an import. Results lists the skipped and failed
+// rows; created rows are only counted.
+type ImportReport struct {
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+	Results []ImportResult `json:"results"`
+}
+
+// ImportFavorites adds each row to userID's favorites, as AddFavorite or
+// AddFavoriteByID would. Rows already among the favorites are skipped, and
+// a failed row does not stop the others. The whole import counts as one
+// change towards anomaly detection.
+func (s *FavoritesService) ImportFavorites(ctx context.Context, userID string, rows []ImportRow) (ImportReport, error) {
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"rows":    len(rows),
+	}).Info("Importing favorites")
+
+	if userID == "" {
+		return ImportReport{}, domain.ErrInvalidUserID
+	}
+	if err := s.observeActivity(userID, ""); err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{Results: []ImportResult{}}
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return ImportReport{}, err
+		}
+
+		assetID := row.AssetID
+		if row.Asset != nil {
+			assetID = row.Asset.GetID()
+		}
+		err := row.Err
+		if err == nil {
+			err = s.importRow(ctx, userID, row)
+		}
+
+		switch {
+		case err == nil:
+			report.Created++
+		case errors.Is(err, domain.ErrFavoriteAlreadyExists):
+			report.Skipped++
+			report.Results = append(report.Results, ImportResult{Row: i + 1, AssetID: assetID, Status: ImportSkipped, Code: domain.CodeOf(err)})
+		default:
+			result := ImportResult{Row: i + 1, AssetID: assetID, Status: ImportFailed, Code: "internal_error", Error: "internal error"}
+			var domainErr *domain.Error
+			if errors.As(err, &domainErr) {
+				result.Code, result.Error = domainErr.Code, domainErr.Error()
+			} else {
+				s.logger.WithError(err).WithFields(logrus.Fields{
+					"user_id":  userID,
+					"asset_id": assetID,
+				}).Error("Failed to import favorite")
+			}
+			report.Failed++
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"created": report.Created,
+		"skipped": report.Skipped,
+		"failed":  report.Failed,
+	}).Info("Imported favorites")
+
+	return report, nil
+}
+
+func (s *FavoritesService) importRow(ctx context.Context, userID string, row ImportRow) error {
+	assetID := row.AssetID
+	if row.Asset != nil {
+		assetID = row.Asset.GetID()
+		if err := s.addFavorite(ctx, userID, row.Asset, true); err != nil {
+			return err
+		}
+	} else {
+		if assetID == "" {
+			return domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_id")
+		}
+		asset, err := s.repo.GetAsset(assetID)
+		if err != nil {
+			return domain.WithContext(err, "asset_id", assetID)
+		}
+		if asset.GetDeletedAt() != nil {
+			return domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+		}
+		if err := s.addFavorite(ctx, userID, asset, false); err != nil {
+			return err
+		}
+	}
+
+	if row.Note == "" {
+		return nil
+	}
+	return s.UpdateFavoriteNote(ctx, userID, assetID, row.Note)
+}
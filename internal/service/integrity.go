@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CheckIntegrity scans storage for favorites pointing at missing assets,
+// assets of unknown types and drifted counters. With repair, the backend
+// removes those favorites and corrects the counters.
+func (s *StorageService) CheckIntegrity(ctx context.Context, repair bool) (repository.IntegrityReport, error) {
+	checker, ok := s.repo.(repository.IntegrityChecker)
+	if !ok {
+		return repository.IntegrityReport{}, domain.ErrNotSupported
+	}
+
+	start := time.Now()
+	report, err := checker.CheckIntegrity(repair)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to check storage integrity")
+		return repository.IntegrityReport{}, err
+	}
+
+	entry := s.logger.WithFields(logrus.Fields{
+		"repair":         repair,
+		"assets":         report.AssetsChecked,
+		"favorites":      report.FavoritesChecked,
+		"missing_assets": report.MissingAssetCount,
+		"invalid_assets": report.InvalidAssetCount,
+		"counter_drift":  len(report.CounterDrift),
+		"repaired":       report.Repaired,
+		"duration":       time.Since(start),
+	})
+	if report.Issues() > 0 {
+		entry.Warn("Storage integrity check found inconsistencies")
+	} else {
+		entry.Info("Storage integrity check passed")
+	}
+	return report, nil
+}
+
+// StartIntegrityChecks checks storage integrity every interval until the
+// returned function is called, passing each outcome to sink
+func (s *StorageService) StartIntegrityChecks(interval time.Duration, repair bool, sink func(repository.IntegrityReport, error)) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				sink(s.CheckIntegrity(context.Background(), repair))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/moderation"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/pkg/clock"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModerationService handles abuse reports against assets
+type ModerationService struct {
+	repo   repository.FavoritesRepository
+	store  *moderation.Store
+	clock  clock.Clock
+	logger *logrus.Logger
+}
+
+// ModerationOption configures optional ModerationService behaviour
+type ModerationOption func(*ModerationService)
+
+// WithModerationClock stamps reports with c instead of the wall clock
+func WithModerationClock(c clock.Clock) ModerationOption {
+	return func(s *ModerationService) { s.clock = c }
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(repo repository.FavoritesRepository, store *moderation.Store, logger *logrus.Logger, opts ...ModerationOption) *ModerationService {
+	s := &ModerationService{
+		repo:   repo,
+		store:  store,
+		clock:  clock.System,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ReportAsset files a report against an existing asset. It returns whether
+// the asset is hidden once the report is counted.
+func (s *ModerationService) ReportAsset(ctx context.Context, assetID, reporterID string, reason moderation.Reason, details string) (bool, error) {
+	s.logger.WithFields(logrus.Fields{
+		"asset_id":    assetID,
+		"reporter_id": reporterID,
+		"reason":      reason,
+	}).Info("Reporting asset")
+
+	if assetID == "" || !reason.IsValid() {
+		return false, domain.ErrInvalidInput
+	}
+	if reporterID == "" {
+		return false, domain.WithContext(domain.ErrInvalidInput, "field", "reporter_id")
+	}
+
+	if _, err := s.repo.GetAsset(assetID); err != nil {
+		return false, domain.WithContext(err, "asset_id", assetID)
+	}
+
+	hidden, err := s.store.Add(moderation.Report{
+		AssetID:    assetID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		ReportedAt: s.clock.Now(),
+	})
+	if err != nil {
+		return hidden, domain.WithContext(err, "asset_id", assetID, "reporter_id", reporterID)
+	}
+
+	if hidden {
+		s.logger.WithField("asset_id", assetID).Warn("Asset hidden after reaching report threshold")
+	}
+
+	return hidden, nil
+}
+
+// Queue returns assets awaiting moderation
+func (s *ModerationService) Queue(ctx context.Context) []moderation.QueueEntry {
+	return s.store.Queue()
+}
+
+// Resolve closes an asset's reports, keeping it hidden or restoring it
+func (s *ModerationService) Resolve(ctx context.Context, assetID string, hidden bool) error {
+	s.logger.WithFields(logrus.Fields{
+		"asset_id": assetID,
+		"hidden":   hidden,
+	}).Info("Resolving asset reports")
+
+	return domain.WithContext(s.store.Resolve(assetID, hidden), "asset_id", assetID)
+}
+
+// IsHidden reports whether an asset has been hidden by moderation
+func (s *ModerationService) IsHidden(assetID string) bool {
+	return s.store.IsHidden(assetID)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ordering returns the repository's favorite order capability
+func (s *FavoritesService) ordering() (repository.FavoriteOrderStore, error) {
+	store, ok := s.repo.(repository.FavoriteOrderStore)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return store, nil
+}
+
+// ReorderFavorites sets the user's manual order: the listed favorites take
+// positions 1 to n, and any others lose their position. Listing with
+// sort=position follows this order.
+func (s *FavoritesService) ReorderFavorites(ctx context.Context, userID string, assetIDs []string) error {
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"count":   len(assetIDs),
+	}).Info("Reordering favorites")
+
+	if userID == "" {
+		return domain.ErrInvalidUserID
+	}
+	seen := make(map[string]bool, len(assetIDs))
+	for _, assetID := range assetIDs {
+		if assetID == "" {
+			return domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_ids")
+		}
+		if seen[assetID] {
+			return domain.WithContext(domain.ErrInvalidInput, "field", "asset_ids", "asset_id", assetID)
+		}
+		seen[assetID] = true
+	}
+
+	store, err := s.ordering()
+	if err != nil {
+		return err
+	}
+	var previous []string
+	if s.auditLog != nil {
+		previous = s.manualOrder(userID)
+	}
+	if err := store.ReorderFavorites(userID, assetIDs); err != nil {
+		return domain.WithContext(err, "user_id", userID)
+	}
+
+	// A reorder changes the user's favorites as a whole, so it is one entry
+	// without an asset ID
+	recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteUpdated, userID, "",
+		map[string][]string{"order": previous},
+		map[string][]string{"order": assetIDs},
+	)
+	return nil
+}
+
+// manualOrder lists the asset IDs of the user's placed favorites by
+// position. It is best effort, for the audit trail.
+func (s *FavoritesService) manualOrder(userID string) []string {
+	favorites, err := s.repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return nil
+	}
+	sort.SliceStable(favorites, func(i, j int) bool { return favorites[i].Position < favorites[j].Position })
+
+	order := []string{}
+	for _, favorite := range favorites {
+		if favorite.Position > 0 {
+			order = append(order, favorite.AssetID)
+		}
+	}
+	return order
+}
+
+// PinFavorite pins a favorite to the top of the user's listings, or unpins it
+func (s *FavoritesService) PinFavorite(ctx context.Context, userID, assetID string, pinned bool) error {
+	s.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"asset_id": assetID,
+		"pinned":   pinned,
+	}).Info("Pinning favorite")
+
+	if userID == "" {
+		return domain.ErrInvalidUserID
+	}
+
+	store, err := s.ordering()
+	if err != nil {
+		return err
+	}
+	var previous interface{}
+	if s.auditLog != nil {
+		if favorite, err := s.findFavorite(userID, assetID); err == nil {
+			previous = map[string]bool{"pinned": favorite.Pinned}
+		}
+	}
+	if err := store.SetFavoritePinned(userID, assetID, pinned); err != nil {
+		return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+
+	recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteUpdated, userID, assetID,
+		previous,
+		map[string]bool{"pinned": pinned},
+	)
+	return nil
+}
+
+// SupportsOrdering reports whether the repository can keep a manual order
+// and pins
+func (s *FavoritesService) SupportsOrdering() bool {
+	_, err := s.ordering()
+	return err == nil
+}
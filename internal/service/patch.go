@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/mergepatch"
+
+	"github.com/sirupsen/logrus"
+)
+
+// managedAssetFields are set by the service, the repository or catalog
+// sync, so patches cannot change them
+var managedAssetFields = []string{"id", "type", "created_at", "updated_at", "deleted_at", "last_synced_at", "source_version", "data_ref"}
+
+// PatchAsset applies a JSON merge patch to a catalog asset and stores the
+// result as UpdateAsset would, validating it for its type
+func (s *AssetService) PatchAsset(ctx context.Context, assetID string, patch []byte) (domain.Asset, error) {
+	s.logger.WithField("asset_id", assetID).Info("Patching asset")
+
+	members, err := mergepatch.Members(patch)
+	if err != nil {
+		return nil, domain.WithContext(domain.ErrInvalidInput, "asset_id", assetID)
+	}
+	for _, field := range managedAssetFields {
+		if _, ok := members[field]; ok {
+			return nil, domain.WithContext(domain.ErrInvalidInput, "asset_id", assetID, "field", field)
+		}
+	}
+
+	existing, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return nil, domain.WithContext(err, "asset_id", assetID)
+	}
+	if existing.GetDeletedAt() != nil {
+		return nil, domain.WithContext(domain.ErrAssetDeleted, "asset_id", assetID)
+	}
+
+	document, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, domain.WithContext(domain.ErrInvalidInput, "asset_id", assetID)
+	}
+	asset, err := domain.AssetFromJSON(merged)
+	if err != nil {
+		// Members of the wrong JSON type, such as a numeric title
+		return nil, domain.WithContext(domain.ErrInvalidInput, "asset_id", assetID)
+	}
+
+	if err := s.UpdateAsset(ctx, assetID, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// FavoritePatch is the part of a favorite its user can change: the note and
+// the pin. Nil members are left as they are.
+type FavoritePatch struct {
+	Note   *string
+	Pinned *bool
+}
+
+// ParseFavoritePatch reads a JSON merge patch of a favorite. A null note
+// clears it and a null pin unpins; other members are refused, as the asset
+// itself is shared with every user who favorited it.
+func ParseFavoritePatch(patch []byte) (FavoritePatch, error) {
+	members, err := mergepatch.Members(patch)
+	if err != nil {
+		return FavoritePatch{}, domain.ErrInvalidInput
+	}
+
+	var parsed FavoritePatch
+	for name, value := range members {
+		switch name {
+		case "note":
+			note := ""
+			if err := json.Unmarshal(value, &note); err != nil {
+				return FavoritePatch{}, domain.WithContext(domain.ErrInvalidInput, "field", name)
+			}
+			parsed.Note = &note
+		case "pinned":
+			pinned := false
+			if err := json.Unmarshal(value, &pinned); err != nil {
+				return FavoritePatch{}, domain.WithContext(domain.ErrInvalidInput, "field", name)
+			}
+			parsed.Pinned = &pinned
+		default:
+			return FavoritePatch{}, domain.WithContext(domain.ErrInvalidInput, "field", name)
+		}
+	}
+	return parsed, nil
+}
+
+// PatchFavorite updates the user's note and pin on a favorite and returns
+// the updated favorite. Every change is checked before the first is made.
+func (s *FavoritesService) PatchFavorite(ctx context.Context, userID, assetID string, patch FavoritePatch) (*domain.UserFavorite, error) {
+	s.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"asset_id": assetID,
+	}).Info("Patching favorite")
+
+	if _, err := s.GetFavorite(ctx, userID, assetID); err != nil {
+		return nil, err
+	}
+	if patch.Pinned != nil && !s.SupportsOrdering() {
+		return nil, domain.WithContext(domain.ErrNotSupported, "field", "pinned")
+	}
+
+	if patch.Note != nil {
+		if err := s.UpdateFavoriteNote(ctx, userID, assetID, *patch.Note); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Pinned != nil {
+		if err := s.PinFavorite(ctx, userID, assetID, *patch.Pinned); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetFavorite(ctx, userID, assetID)
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// ListRecentFavorites returns a page of the favorites the user added within
+// window of now, newest first. The query's type and search filters and
+// paging apply; its sort and time range are replaced.
+func (s *FavoritesService) ListRecentFavorites(ctx context.Context, userID string, window time.Duration, query domain.FavoritesQuery) ([]*domain.UserFavorite, domain.Pagination, error) {
+	if window <= 0 {
+		return nil, domain.Pagination{}, domain.WithContext(domain.ErrInvalidInput, "field", "days")
+	}
+
+	query.AddedSince = s.clock.Now().Add(-window)
+	query.AddedUntil = time.Time{}
+	query.Sort = domain.SortAddedAt
+	query.Descending = true
+	return s.ListUserFavoritesPage(ctx, userID, query)
+}
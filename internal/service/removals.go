@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/notification"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PendingRemoval is an asset deleted under DeleteGrace that is still in its
+// grace period
+type PendingRemoval struct {
+	AssetID   string    `json:"asset_id"`
+	RemovesAt time.Time `json:"removes_at"`
+}
+
+// scheduleRemoval records when a deleted asset is to be removed
+func (s *AssetService) scheduleRemoval(assetID string, at time.Time) {
+	s.removalsMu.Lock()
+	defer s.removalsMu.Unlock()
+	s.removals[assetID] = at
+}
+
+// PendingRemovals returns the assets in their grace period, soonest removal first
+func (s *AssetService) PendingRemovals() []PendingRemoval {
+	s.removalsMu.Lock()
+	defer s.removalsMu.Unlock()
+
+	pending := make([]PendingRemoval, 0, len(s.removals))
+	for assetID, at := range s.removals {
+		pending = append(pending, PendingRemoval{AssetID: assetID, RemovesAt: at})
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].RemovesAt.Equal(pending[j].RemovesAt) {
+			return pending[i].RemovesAt.Before(pending[j].RemovesAt)
+		}
+		return pending[i].AssetID < pending[j].AssetID
+	})
+	return pending
+}
+
+// notifyFavoriters tells every user who favorited a deleted asset that it
+// is unavailable and when it leaves their favorites. Failing to find the
+// users is logged; the deletion itself has already succeeded.
+func (s *AssetService) notifyFavoriters(assetID string, removesAt time.Time) {
+	if s.notices == nil {
+		return
+	}
+
+	references, ok := s.repo.(repository.AssetReferences)
+	if !ok {
+		s.logger.WithField("asset_id", assetID).Warn("Storage backend cannot list favoriters; deleted asset not notified")
+		return
+	}
+	userIDs, err := references.ListAssetFavoriters(assetID)
+	if err != nil {
+		s.logger.WithError(err).WithField("asset_id", assetID).Error("Failed to list favoriters of deleted asset")
+		return
+	}
+
+	now := s.clock.Now()
+	for _, userID := range userIDs {
+		s.notices.Add(userID, notification.Notification{
+			Kind:      notification.KindAssetUnavailable,
+			AssetID:   assetID,
+			Message:   fmt.Sprintf("Asset %s is no longer available and will be removed from your favorites on %s", assetID, removesAt.UTC().Format(time.RFC1123)),
+			RemovesAt: &removesAt,
+			CreatedAt: now,
+		})
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"asset_id": assetID,
+		"notified": len(userIDs),
+	}).Info("Notified favoriters of deleted asset")
+}
+
+// RemoveExpired removes the assets whose grace period has passed, together
+// with every favorite of them, and returns how many were removed. Assets
+// that fail to be removed are retried on the next call.
+func (s *AssetService) RemoveExpired(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+
+	s.removalsMu.Lock()
+	var due []string
+	for assetID, at := range s.removals {
+		if !at.After(now) {
+			due = append(due, assetID)
+		}
+	}
+	s.removalsMu.Unlock()
+	sort.Strings(due)
+
+	removed := 0
+	var errs []error
+	for _, assetID := range due {
+		if err := s.repo.DeleteAsset(assetID); err != nil && !errors.Is(err, domain.ErrAssetNotFound) {
+			s.logger.WithError(err).WithField("asset_id", assetID).Error("Failed to remove asset after grace period")
+			errs = append(errs, fmt.Errorf("asset %s: %w", assetID, err))
+			continue
+		}
+
+		s.removalsMu.Lock()
+		delete(s.removals, assetID)
+		s.removalsMu.Unlock()
+		removed++
+		s.logger.WithField("asset_id", assetID).Info("Removed asset after grace period")
+	}
+	return removed, errors.Join(errs...)
+}
+
+// StartRemovals calls RemoveExpired every interval until the returned stop
+// function is called
+func (s *AssetService) StartRemovals(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.RemoveExpired(context.Background())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
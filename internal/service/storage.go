@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StorageService exposes operational insight into the storage backend
+type StorageService struct {
+	repo   repository.FavoritesRepository
+	logger *logrus.Logger
+}
+
+// NewStorageService creates a new storage service
+func NewStorageService(repo repository.FavoritesRepository, logger *logrus.Logger) *StorageService {
+	return &StorageService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Stats returns statistics reported by the storage backend
+func (s *StorageService) Stats(ctx context.Context) (repository.StorageStats, error) {
+	inspector, ok := s.repo.(repository.StorageInspector)
+	if !ok {
+		return repository.StorageStats{}, domain.ErrNotSupported
+	}
+
+	return inspector.StorageStats()
+}
+
+// Compact asks the storage backend to reclaim unused space
+func (s *StorageService) Compact(ctx context.Context) (repository.CompactionResult, error) {
+	inspector, ok := s.repo.(repository.StorageInspector)
+	if !ok {
+		return repository.CompactionResult{}, domain.ErrNotSupported
+	}
+
+	s.logger.Info("Compacting storage")
+
+	result, err := inspector.Compact()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to compact storage")
+		return repository.CompactionResult{}, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bytes_before": result.BytesBefore,
+		"bytes_after":  result.BytesAfter,
+		"removed":      result.Removed,
+		"duration":     result.Duration,
+	}).Info("Successfully compacted storage")
+
+	return result, nil
+}
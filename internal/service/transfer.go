@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SupportsTransfers reports whether the storage backend can transfer
+// favorites between users
+func (s *FavoritesService) SupportsTransfers() bool {
+	_, ok := s.repo.(repository.FavoritesTransferer)
+	return ok
+}
+
+// TransferFavorites copies a user's favorites to another user, or moves
+// them when move is set, as when merging duplicate accounts. Nil assetIDs
+// transfers every favorite. The transfer is all or nothing: favorites the
+// target already has are skipped, keeping the target's note, but any
+// listed asset that is not a favorite of fromUserID fails it. Asset owners
+// are not told, as nobody's interest changed.
+func (s *FavoritesService) TransferFavorites(ctx context.Context, fromUserID, toUserID string, assetIDs []string, move bool) (repository.TransferResult, error) {
+	s.logger.WithFields(logrus.Fields{
+		"from_user_id": fromUserID,
+		"to_user_id":   toUserID,
+		"count":        len(assetIDs),
+		"move":         move,
+	}).Info("Transferring favorites")
+
+	if fromUserID == "" || toUserID == "" {
+		return repository.TransferResult{}, domain.ErrInvalidUserID
+	}
+	if fromUserID == toUserID {
+		return repository.TransferResult{}, domain.WithContext(domain.ErrInvalidInput, "field", "to_user_id")
+	}
+	seen := make(map[string]bool, len(assetIDs))
+	for _, assetID := range assetIDs {
+		if assetID == "" {
+			return repository.TransferResult{}, domain.WithContext(domain.ErrMissingRequiredField, "field", "asset_ids")
+		}
+		if seen[assetID] {
+			return repository.TransferResult{}, domain.WithContext(domain.ErrInvalidInput, "field", "asset_ids", "asset_id", assetID)
+		}
+		seen[assetID] = true
+	}
+
+	transferer, ok := s.repo.(repository.FavoritesTransferer)
+	if !ok {
+		return repository.TransferResult{}, domain.ErrNotSupported
+	}
+	if err := s.ensureUser(ctx, toUserID); err != nil {
+		return repository.TransferResult{}, domain.WithContext(err, "user_id", toUserID)
+	}
+
+	// The favorites are read before they move, for the audit trail
+	var transferred auditedFavorites
+	if s.auditLog != nil {
+		transferred = s.auditedFavorites(fromUserID)
+	}
+
+	result, err := transferer.TransferFavorites(fromUserID, toUserID, assetIDs, move)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"from_user_id": fromUserID,
+			"to_user_id":   toUserID,
+		}).Error("Failed to transfer favorites")
+		return repository.TransferResult{}, domain.WithContext(err, "from_user_id", fromUserID, "to_user_id", toUserID)
+	}
+
+	for _, assetID := range result.Transferred {
+		recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteAdded, toUserID, assetID, nil, transferred.get(assetID))
+		s.kpis.FavoriteAdded(toUserID)
+	}
+	if move {
+		for _, assetIDs := range [][]string{result.Transferred, result.Skipped} {
+			for _, assetID := range assetIDs {
+				recordAudit(ctx, s.auditLog, s.logger, audit.FavoriteRemoved, fromUserID, assetID, transferred.get(assetID), nil)
+				s.kpis.FavoriteRemoved(fromUserID)
+			}
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"from_user_id": fromUserID,
+		"to_user_id":   toUserID,
+		"transferred":  len(result.Transferred),
+		"skipped":      len(result.Skipped),
+	}).Info("Successfully transferred favorites")
+
+	return result, nil
+}
+
+// auditedFavorite is a transferred favorite as the audit trail records it:
+// the asset and the note that travels with it
+type auditedFavorite struct {
+	Asset domain.Asset `json:"asset"`
+	Note  string       `json:"note,omitempty"`
+}
+
+// auditedFavorites reads the user's favorites by asset ID. It is best
+// effort: favorites it cannot read are audited without values.
+func (s *FavoritesService) auditedFavorites(userID string) auditedFavorites {
+	favorites, err := s.repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	if err != nil {
+		return nil
+	}
+	audited := make(auditedFavorites, len(favorites))
+	for _, favorite := range favorites {
+		audited[favorite.AssetID] = auditedFavorite{Asset: favorite.Asset, Note: favorite.Note}
+	}
+	return audited
+}
+
+type auditedFavorites map[string]auditedFavorite
+
+// get returns the favorite's audit value, or nil when it was not read
+func (a auditedFavorites) get(assetID string) interface{} {
+	if favorite, ok := a[assetID]; ok {
+		return favorite
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+)
+
+// AssetTypeRules lists, per role, the asset types that role may not add to
+// favorites. Blocking is soft: favorites added before a rule applied are
+// kept, and other roles are unaffected.
+type AssetTypeRules map[string]map[domain.AssetType]bool
+
+// ParseAssetTypeRules parses rules given as role to blocked types, with the
+// types separated by "|", e.g. {"contractor": "audience|insight"}
+func ParseAssetTypeRules(rules map[string]string) (AssetTypeRules, error) {
+	parsed := make(AssetTypeRules, len(rules))
+	for role, types := range rules {
+		blocked := make(map[domain.AssetType]bool)
+		for _, name := range strings.Split(types, "|") {
+			assetType := domain.AssetType(strings.TrimSpace(name))
+			if !assetType.IsValid() {
+				return nil, fmt.Errorf("role %q: unknown asset type %q", role, name)
+			}
+			blocked[assetType] = true
+		}
+		parsed[role] = blocked
+	}
+	return parsed, nil
+}
+
+// Allows reports whether role may favorite assets of the given type
+func (r AssetTypeRules) Allows(role string, assetType domain.AssetType) bool {
+	return !r[role][assetType]
+}
+
+// WithAssetTypeRules blocks roles from favoriting some asset types. The role
+// is taken from the caller's token, so anonymous callers are never blocked.
+func WithAssetTypeRules(rules AssetTypeRules) FavoritesOption {
+	return func(s *FavoritesService) { s.typeRules = rules }
+}
+
+// checkAssetType applies the asset type rules to the caller's role
+func (s *FavoritesService) checkAssetType(ctx context.Context, assetType domain.AssetType) error {
+	claims := auth.ClaimsFromContext(ctx)
+	if claims == nil || s.typeRules.Allows(claims.Role, assetType) {
+		return nil
+	}
+	return domain.WithContext(domain.ErrAssetTypeBlocked, "role", claims.Role, "asset_type", string(assetType))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/trash"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithTrash keeps favorites removed by their users in store, so that
+// UndoRemoval can restore them
+func WithTrash(store *trash.Store) FavoritesOption {
+	return func(s *FavoritesService) { s.trash = store }
+}
+
+// SupportsUndo reports whether removals can be undone
+func (s *FavoritesService) SupportsUndo() bool {
+	return s.trash != nil
+}
+
+// trashedFavorite looks up the favorite about to be removed, for the trash.
+// It is best effort: a favorite it cannot find is removed all the same.
+func (s *FavoritesService) trashedFavorite(userID, assetID string) *domain.UserFavorite {
+	favorite, err := s.findFavorite(userID, assetID)
+	if err != nil {
+		return nil
+	}
+	return favorite
+}
+
+// UndoRemoval restores the user's most recently removed favorite, with its
+// note and pin, and returns its asset ID. The asset is favorited again as
+// it is now, so the favorite counts as newly added.
+func (s *FavoritesService) UndoRemoval(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", domain.ErrInvalidUserID
+	}
+	if s.trash == nil {
+		return "", domain.ErrNotSupported
+	}
+
+	item, err := s.trash.Pop(userID)
+	if err != nil {
+		return "", err
+	}
+	favorite := item.Favorite
+	s.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"asset_id": favorite.AssetID,
+	}).Info("Undoing favorite removal")
+
+	if err := s.restoreFavorite(ctx, favorite); err != nil {
+		// The user may make room and try again
+		if errors.Is(err, domain.ErrMaxFavoritesReached) {
+			s.trash.Put(item)
+		}
+		return "", err
+	}
+	return favorite.AssetID, nil
+}
+
+func (s *FavoritesService) restoreFavorite(ctx context.Context, favorite domain.UserFavorite) error {
+	userID, assetID := favorite.UserID, favorite.AssetID
+	asset, err := s.repo.GetAsset(assetID)
+	if err != nil {
+		return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+	}
+	if asset.GetDeletedAt() != nil {
+		return domain.WithContext(domain.ErrAssetDeleted, "user_id", userID, "asset_id", assetID)
+	}
+	if err := s.addFavorite(ctx, userID, asset, false); err != nil {
+		return err
+	}
+
+	if favorite.Note != "" {
+		if _, err := s.repo.UpdateFavoriteNote(userID, assetID, favorite.Note); err != nil {
+			return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+		}
+	}
+	if favorite.Pinned {
+		if store, err := s.ordering(); err == nil {
+			if err := store.SetFavoritePinned(userID, assetID, true); err != nil {
+				return domain.WithContext(err, "user_id", userID, "asset_id", assetID)
+			}
+		}
+	}
+	return nil
+}
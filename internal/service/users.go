@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/trash"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UserService manages users, on behalf of the identity platform through
+// SCIM or through the user API
+type UserService struct {
+	repo   repository.FavoritesRepository
+	trash  *trash.Store
+	logger *logrus.Logger
+}
+
+// UserOption configures optional UserService behaviour
+type UserOption func(*UserService)
+
+// WithUserTrash empties the user's trash in store when they are erased
+func WithUserTrash(store *trash.Store) UserOption {
+	return func(s *UserService) { s.trash = store }
+}
+
+// NewUserService creates a new user service
+func NewUserService(repo repository.FavoritesRepository, logger *logrus.Logger, opts ...UserOption) *UserService {
+	s := &UserService{
+		repo:   repo,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateUser provisions a user
+func (s *UserService) CreateUser(ctx context.Context, user *domain.User) error {
+	s.logger.WithField("user_id", user.ID).Info("Provisioning user")
+
+	if user.ID == "" {
+		return domain.ErrInvalidUserID
+	}
+	if err := validateEmail(user.Email); err != nil {
+		return domain.WithContext(err, "user_id", user.ID)
+	}
+
+	// Backends upsert users, so existence is checked here
+	_, err := s.repo.GetUser(user.ID)
+	if err == nil {
+		return domain.WithContext(domain.ErrUserAlreadyExists, "user_id", user.ID)
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return domain.WithContext(err, "user_id", user.ID)
+	}
+
+	if err := s.repo.CreateUser(user); err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to provision user")
+		return domain.WithContext(err, "user_id", user.ID)
+	}
+
+	s.logger.WithField("user_id", user.ID).Info("Successfully provisioned user")
+	return nil
+}
+
+// GetUser returns a provisioned user
+func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.repo.GetUser(userID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID)
+	}
+	return user, nil
+}
+
+// UpdateUser replaces an existing user's email and name
+func (s *UserService) UpdateUser(ctx context.Context, userID, email, name string) (*domain.User, error) {
+	s.logger.WithField("user_id", userID).Info("Updating user")
+
+	if err := validateEmail(email); err != nil {
+		return nil, domain.WithContext(err, "user_id", userID)
+	}
+	user, err := s.repo.GetUser(userID)
+	if err != nil {
+		return nil, domain.WithContext(err, "user_id", userID)
+	}
+
+	updated := *user
+	updated.Email = email
+	updated.Name = name
+	updated.UpdatedAt = time.Now()
+	// Backends upsert users, keeping the user's favorites
+	if err := s.repo.CreateUser(&updated); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to update user")
+		return nil, domain.WithContext(err, "user_id", userID)
+	}
+
+	s.logger.WithField("user_id", userID).Info("Successfully updated user")
+	return &updated, nil
+}
+
+// validateEmail accepts an empty email or a single bare address
+func validateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "email")
+	}
+	return nil
+}
+
+// EraseUser deprovisions a user and runs the GDPR cleanup: the user record,
+// every favorite of theirs and the favorites in their trash are deleted
+func (s *UserService) EraseUser(ctx context.Context, userID string) error {
+	s.logger.WithField("user_id", userID).Info("Erasing user data")
+
+	eraser, ok := s.repo.(repository.UserEraser)
+	if !ok {
+		return domain.WithContext(domain.ErrNotSupported, "user_id", userID)
+	}
+
+	if err := eraser.DeleteUser(userID); err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to erase user data")
+		}
+		return domain.WithContext(err, "user_id", userID)
+	}
+	if s.trash != nil {
+		s.trash.Clear(userID)
+	}
+
+	s.logger.WithField("user_id", userID).Info("Successfully erased user data")
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package sharing lets users publish a read-only view of their favorites
+// through an unguessable link token
+package sharing
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/clock"
+)
+
+// tokenBytes is the entropy of a share token
+const tokenBytes = 32
+
+// Share is a user's share link. The token is only known when the share is
+// created; the store keeps its hash.
+type Share struct {
+	Token     string     `json:"token,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type entry struct {
+	userID string
+	share  Share
+}
+
+// Store keeps share links in memory. Each user has at most one link, and
+// creating a new one revokes the previous.
+type Store struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	shares map[string]*entry // by token hash
+	users  map[string]string // user ID to token hash
+}
+
+// NewStore creates an empty share store; a nil clock uses the system clock
+func NewStore(c clock.Clock) *Store {
+	return &Store{
+		clock:  clock.OrSystem(c),
+		shares: make(map[string]*entry),
+		users:  make(map[string]string),
+	}
+}
+
+// Create gives userID a new share link, valid until expiresAt when set
+func (s *Store) Create(userID string, expiresAt *time.Time) (Share, error) {
+	if userID == "" {
+		return Share{}, domain.ErrInvalidUserID
+	}
+	now := s.clock.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return Share{}, domain.WithContext(domain.ErrInvalidInput, "field", "expires_at")
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return Share{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	share := Share{CreatedAt: now, ExpiresAt: expiresAt}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.shares, s.users[userID])
+	hash := hashToken(token)
+	s.shares[hash] = &entry{userID: userID, share: share}
+	s.users[userID] = hash
+
+	share.Token = token
+	return share, nil
+}
+
+// Get returns userID's share link, without its token
+func (s *Store) Get(userID string) (Share, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.liveLocked(s.users[userID])
+	if !ok {
+		return Share{}, domain.WithContext(domain.ErrShareNotFound, "user_id", userID)
+	}
+	return e.share, nil
+}
+
+// Revoke deletes userID's share link
+func (s *Store) Revoke(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, ok := s.users[userID]
+	if _, live := s.liveLocked(hash); !ok || !live {
+		return domain.WithContext(domain.ErrShareNotFound, "user_id", userID)
+	}
+	delete(s.shares, hash)
+	delete(s.users, userID)
+	return nil
+}
+
+// Resolve returns the user whose favorites token shares
+func (s *Store) Resolve(token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.liveLocked(hashToken(token))
+	if !ok {
+		return "", domain.ErrShareNotFound
+	}
+	return e.userID, nil
+}
+
+// liveLocked returns the share with the given hash, dropping it once expired
+func (s *Store) liveLocked(hash string) (*entry, bool) {
+	e, ok := s.shares[hash]
+	if !ok {
+		return nil, false
+	}
+	if e.share.ExpiresAt != nil && !s.clock.Now().Before(*e.share.ExpiresAt) {
+		delete(s.shares, hash)
+		delete(s.users, e.userID)
+		return nil, false
+	}
+	return e, true
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,236 @@
+// Package taxonomy holds localized display names for the canonical asset
+// category and tag keys
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Kind is the part of the taxonomy a key belongs to
+type Kind string
+
+const (
+	KindCategory Kind = "categories"
+	KindTag      Kind = "tags"
+)
+
+// IsValid reports whether the kind is one of the supported kinds
+func (k Kind) IsValid() bool {
+	return k == KindCategory || k == KindTag
+}
+
+// Entry holds the display names of one key by language tag
+type Entry struct {
+	Kind  Kind              `json:"kind"`
+	Key   string            `json:"key"`
+	Names map[string]string `json:"names"`
+}
+
+// Catalog keeps display names in memory. Keys are never rewritten: assets
+// and filters keep using them, and names are only added to responses.
+type Catalog struct {
+	mu    sync.RWMutex
+	names map[Kind]map[string]map[string]string
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{names: map[Kind]map[string]map[string]string{
+		KindCategory: {},
+		KindTag:      {},
+	}}
+}
+
+// LoadFile adds the entries of a JSON file holding a list of entries
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if _, err := c.Set(entry.Kind, entry.Key, entry.Names); err != nil {
+			return fmt.Errorf("%s %s/%s: %w", path, entry.Kind, entry.Key, err)
+		}
+	}
+	return nil
+}
+
+// Set replaces the display names of a key. Language tags are matched
+// case-insensitively.
+func (c *Catalog) Set(kind Kind, key string, names map[string]string) (Entry, error) {
+	if !kind.IsValid() {
+		return Entry{}, domain.WithContext(domain.ErrInvalidInput, "field", "kind")
+	}
+	if key == "" || len(names) == 0 {
+		return Entry{}, domain.ErrMissingRequiredField
+	}
+
+	normalized := make(map[string]string, len(names))
+	for lang, name := range names {
+		lang = normalizeLanguage(lang)
+		if lang == "" || lang == "*" || name == "" {
+			return Entry{}, domain.WithContext(domain.ErrInvalidInput, "field", "names")
+		}
+		normalized[lang] = name
+	}
+
+	c.mu.Lock()
+	c.names[kind][key] = normalized
+	c.mu.Unlock()
+	return Entry{Kind: kind, Key: key, Names: copyNames(normalized)}, nil
+}
+
+// Delete removes the display names of a key
+func (c *Catalog) Delete(kind Kind, key string) error {
+	if !kind.IsValid() {
+		return domain.WithContext(domain.ErrInvalidInput, "field", "kind")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.names[kind][key]; !ok {
+		return domain.WithContext(domain.ErrTaxonomyKeyNotFound, "kind", string(kind), "key", key)
+	}
+	delete(c.names[kind], key)
+	return nil
+}
+
+// List returns every entry ordered by kind and key
+func (c *Catalog) List() []Entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]Entry, 0)
+	for _, kind := range []Kind{KindCategory, KindTag} {
+		for key, names := range c.names[kind] {
+			entries = append(entries, Entry{Kind: kind, Key: key, Names: copyNames(names)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Name returns the display name of a key in the first of languages it is
+// known in. A language also matches names in its base language, so de-AT
+// finds de.
+func (c *Catalog) Name(kind Kind, key string, languages []string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := c.names[kind][key]
+	if len(names) == 0 {
+		return "", false
+	}
+	for _, lang := range languages {
+		if name, ok := names[lang]; ok {
+			return name, true
+		}
+		if base, _, found := strings.Cut(lang, "-"); found {
+			if name, ok := names[base]; ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LocalizedInsight is an insight with the display names of its category
+// and tags. Keys without a name in the requested languages are left out,
+// and clients show the key instead.
+type LocalizedInsight struct {
+	*domain.Insight
+	CategoryName string            `json:"category_name,omitempty"`
+	TagNames     map[string]string `json:"tag_names,omitempty"`
+}
+
+// Localize adds display names to a serialized insight. Other assets, and
+// insights rendered into a different form, are returned unchanged.
+func (c *Catalog) Localize(languages []string, view interface{}) interface{} {
+	insight, ok := view.(*domain.Insight)
+	if !ok || len(languages) == 0 {
+		return view
+	}
+
+	localized := LocalizedInsight{Insight: insight}
+	if insight.Category != "" {
+		localized.CategoryName, _ = c.Name(KindCategory, insight.Category, languages)
+	}
+	for _, tag := range insight.Tags {
+		if name, ok := c.Name(KindTag, tag, languages); ok {
+			if localized.TagNames == nil {
+				localized.TagNames = make(map[string]string, len(insight.Tags))
+			}
+			localized.TagNames[tag] = name
+		}
+	}
+	if localized.CategoryName == "" && localized.TagNames == nil {
+		return view
+	}
+	return localized
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, most preferred first. Tags with q=0 and the wildcard are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		lang = normalizeLanguage(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{lang: lang, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	languages := make([]string, len(ranges))
+	for i, r := range ranges {
+		languages[i] = r.lang
+	}
+	return languages
+}
+
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+func copyNames(names map[string]string) map[string]string {
+	copied := make(map[string]string, len(names))
+	for lang, name := range names {
+		copied[lang] = name
+	}
+	return copied
+}
@@ -0,0 +1,123 @@
+// Package trash keeps users' recently removed favorites for a while, so
+// that removals can be undone
+package trash
+
+import (
+	"sync"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/pkg/clock"
+)
+
+// Item is a removed favorite as it was before removal
+type Item struct {
+	Favorite  domain.UserFavorite
+	RemovedAt time.Time
+}
+
+// Store keeps removed favorites in memory for ttl, dropping a user's oldest
+// once they have more than maxPerUser
+type Store struct {
+	ttl        time.Duration
+	maxPerUser int
+	clock      clock.Clock
+
+	mu        sync.Mutex
+	users     map[string][]Item
+	lastSweep time.Time
+}
+
+// NewStore creates a store keeping removed favorites for ttl, up to
+// maxPerUser per user; 0 keeps them all. A nil clock uses the system clock.
+func NewStore(ttl time.Duration, maxPerUser int, c clock.Clock) *Store {
+	c = clock.OrSystem(c)
+	return &Store{
+		ttl:        ttl,
+		maxPerUser: maxPerUser,
+		clock:      c,
+		users:      make(map[string][]Item),
+		lastSweep:  c.Now(),
+	}
+}
+
+// TTL is how long removed favorites are kept
+func (s *Store) TTL() time.Duration {
+	return s.ttl
+}
+
+// Put keeps a removed favorite, replacing any earlier removal of the same
+// asset by the same user
+func (s *Store) Put(item Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if now.Sub(s.lastSweep) >= s.ttl {
+		for userID := range s.users {
+			s.liveLocked(userID, now)
+		}
+		s.lastSweep = now
+	}
+
+	userID := item.Favorite.UserID
+	items := s.liveLocked(userID, now)
+	kept := make([]Item, 0, len(items)+1)
+	for _, existing := range items {
+		if existing.Favorite.AssetID != item.Favorite.AssetID {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, item)
+	if s.maxPerUser > 0 && len(kept) > s.maxPerUser {
+		kept = kept[len(kept)-s.maxPerUser:]
+	}
+	s.users[userID] = kept
+}
+
+// Pop takes the user's most recently removed favorite out of the trash
+func (s *Store) Pop(userID string) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := s.liveLocked(userID, s.clock.Now())
+	if len(items) == 0 {
+		return Item{}, domain.WithContext(domain.ErrTrashEmpty, "user_id", userID)
+	}
+	item := items[len(items)-1]
+	if len(items) == 1 {
+		delete(s.users, userID)
+	} else {
+		s.users[userID] = items[:len(items)-1]
+	}
+	return item, nil
+}
+
+// Clear drops everything the user removed, so erased users leave nothing
+// behind
+func (s *Store) Clear(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, userID)
+}
+
+// liveLocked returns the user's items still within the TTL, oldest first,
+// dropping the rest
+func (s *Store) liveLocked(userID string, now time.Time) []Item {
+	items := s.users[userID]
+	expired := 0
+	for expired < len(items) && now.Sub(items[expired].RemovedAt) >= s.ttl {
+		expired++
+	}
+	if expired == 0 {
+		return items
+	}
+	if expired == len(items) {
+		delete(s.users, userID)
+		return nil
+	}
+	items = append([]Item(nil), items[expired:]...)
+	s.users[userID] = items
+	return items
+}
@@ -0,0 +1,118 @@
+// Package usage counts how clients use optional API features, such as
+// response formats and query parameters, to show which ones matter
+package usage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gwi-favorites-service/pkg/metrics"
+)
+
+// OtherValue collects the values of a feature beyond its first MaxValues
+const OtherValue = "other"
+
+// DefaultMaxValues bounds the distinct values tracked per feature
+const DefaultMaxValues = 20
+
+// Feature summarises the use of one feature
+type Feature struct {
+	Name     string            `json:"name"`
+	Total    uint64            `json:"total"`
+	Values   map[string]uint64 `json:"values"`
+	LastUsed time.Time         `json:"last_used"`
+}
+
+type featureState struct {
+	values   map[string]*metrics.Counter
+	lastUsed time.Time
+}
+
+// Tracker counts feature use since startup. Counts are also exported as
+// feature_usage_total{feature,value}. Values are bounded per feature, so
+// callers may pass client input after mapping it to a small set.
+type Tracker struct {
+	maxValues int
+	registry  *metrics.Registry
+	now       func() time.Time
+
+	mu       sync.Mutex
+	features map[string]*featureState
+	started  time.Time
+}
+
+// NewTracker creates a tracker exporting to registry
+func NewTracker(registry *metrics.Registry, maxValues int) *Tracker {
+	if maxValues <= 0 {
+		maxValues = DefaultMaxValues
+	}
+	return &Tracker{
+		maxValues: maxValues,
+		registry:  registry,
+		now:       time.Now,
+		features:  make(map[string]*featureState),
+		started:   time.Now(),
+	}
+}
+
+// Record counts one use of feature with the given value. A nil tracker
+// records nothing, so callers need not check whether tracking is enabled.
+func (t *Tracker) Record(feature, value string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.features[feature]
+	if !ok {
+		state = &featureState{values: make(map[string]*metrics.Counter)}
+		t.features[feature] = state
+	}
+	state.lastUsed = t.now()
+
+	counter, ok := state.values[value]
+	if !ok {
+		if len(state.values) >= t.maxValues {
+			value = OtherValue
+			counter = state.values[value]
+		}
+		if counter == nil {
+			counter = t.registry.Counter("feature_usage_total", "Uses of optional API features by value", metrics.Labels{"feature": feature, "value": value})
+			state.values[value] = counter
+		}
+	}
+	counter.Inc()
+}
+
+// Summary returns every feature used since startup, most used first
+func (t *Tracker) Summary() []Feature {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	features := make([]Feature, 0, len(t.features))
+	for name, state := range t.features {
+		feature := Feature{Name: name, Values: make(map[string]uint64, len(state.values)), LastUsed: state.lastUsed}
+		for value, counter := range state.values {
+			count := counter.Value()
+			feature.Values[value] = count
+			feature.Total += count
+		}
+		features = append(features, feature)
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		if features[i].Total != features[j].Total {
+			return features[i].Total > features[j].Total
+		}
+		return features[i].Name < features[j].Name
+	})
+	return features
+}
+
+// Since returns when counting started
+func (t *Tracker) Since() time.Time {
+	return t.started
+}
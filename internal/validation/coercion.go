@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+var (
+	// decimalPattern matches plain decimal numbers, leaving hex, Inf and
+	// NaN, which strconv also parses, as text
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	// thousandsPattern matches numbers grouped with commas, e.g. 1,234.5
+	thousandsPattern = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+)
+
+// dateLayouts are the date formats recognised in chart data. Day and month
+// order is ambiguous in numeric forms such as 01/02/2006, so only
+// year-first numeric dates are recognised. Layouts without a zone are UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"02 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// CoerceChartData normalizes the data points of a chart submitted from
+// loosely typed sources such as CSV imports: numeric strings become numbers
+// and dates become RFC 3339 timestamps. Each kind of coercion applied is
+// reported as a warning with its count. Values of other types, and strings
+// that are neither, are left alone, as are other assets.
+func CoerceChartData(ctx context.Context, asset domain.Asset) {
+	chart, ok := asset.(*domain.Chart)
+	if !ok {
+		return
+	}
+
+	var numbers, dates [2]int
+	for i := range chart.Data {
+		for axis, value := range []*interface{}{&chart.Data[i].X, &chart.Data[i].Y} {
+			text, ok := (*value).(string)
+			if !ok {
+				continue
+			}
+			if number, ok := coerceNumber(text); ok {
+				*value = number
+				numbers[axis]++
+			} else if date, ok := coerceDate(text); ok && date != text {
+				*value = date
+				dates[axis]++
+			}
+		}
+	}
+
+	for axis, name := range []string{"x", "y"} {
+		if numbers[axis] > 0 {
+			Warn(ctx, fmt.Sprintf("chart data: %d %s values converted from text to numbers", numbers[axis], name))
+		}
+		if dates[axis] > 0 {
+			Warn(ctx, fmt.Sprintf("chart data: %d %s values converted to RFC 3339 dates", dates[axis], name))
+		}
+	}
+}
+
+// coerceNumber parses a decimal number, optionally grouped in thousands
+func coerceNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if thousandsPattern.MatchString(text) {
+		text = strings.ReplaceAll(text, ",", "")
+	}
+	if !decimalPattern.MatchString(text) {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	return number, err == nil
+}
+
+// coerceDate formats a date in one of dateLayouts as RFC 3339
+func coerceDate(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.Format(time.RFC3339Nano), true
+		}
+	}
+	return "", false
+}
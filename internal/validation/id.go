@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// uuidPattern matches canonical, hyphenated UUIDs of any version
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IDRule constrains one kind of identifier
+type IDRule struct {
+	// MaxLength caps the length in characters; 0 means unlimited
+	MaxLength int
+	// Pattern must match the whole ID when set
+	Pattern *regexp.Regexp
+}
+
+// ParseIDRule builds a rule from configuration. pattern is a regular
+// expression, the keyword "uuid", or empty to accept any format.
+func ParseIDRule(pattern string, maxLength int) (IDRule, error) {
+	rule := IDRule{MaxLength: maxLength}
+
+	switch pattern {
+	case "":
+	case "uuid":
+		rule.Pattern = uuidPattern
+	default:
+		// Anchored so that the pattern matches the whole ID, not part of it
+		compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return IDRule{}, fmt.Errorf("invalid ID pattern %q: %w", pattern, err)
+		}
+		rule.Pattern = compiled
+	}
+
+	return rule, nil
+}
+
+// Valid reports whether id satisfies the rule. Empty IDs, invalid UTF-8 and
+// control characters are always rejected.
+func (r IDRule) Valid(id string) bool {
+	if id == "" || !utf8.ValidString(id) {
+		return false
+	}
+	if r.MaxLength > 0 && utf8.RuneCountInString(id) > r.MaxLength {
+		return false
+	}
+	for _, c := range id {
+		if unicode.IsControl(c) {
+			return false
+		}
+	}
+	return r.Pattern == nil || r.Pattern.MatchString(id)
+}
+
+// IDValidator checks user and asset IDs before they reach the repository
+type IDValidator struct {
+	User  IDRule
+	Asset IDRule
+}
+
+// ValidateUserID returns domain.ErrInvalidUserID for malformed user IDs
+func (v *IDValidator) ValidateUserID(id string) error {
+	if !v.User.Valid(id) {
+		return domain.ErrInvalidUserID
+	}
+	return nil
+}
+
+// ValidateAssetID returns domain.ErrInvalidAssetID for malformed asset IDs
+func (v *IDValidator) ValidateAssetID(id string) error {
+	if !v.Asset.Valid(id) {
+		return domain.ErrInvalidAssetID
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// LengthPolicy decides what happens to text over its limit
+type LengthPolicy string
+
+const (
+	// LengthReject fails the request with domain.ErrFieldTooLong
+	LengthReject LengthPolicy = "reject"
+	// LengthTruncate shortens the text and reports a warning
+	LengthTruncate LengthPolicy = "truncate"
+)
+
+// ParseLengthPolicy validates a configured policy name
+func ParseLengthPolicy(policy string) (LengthPolicy, error) {
+	switch LengthPolicy(policy) {
+	case LengthReject, LengthTruncate:
+		return LengthPolicy(policy), nil
+	}
+	return "", fmt.Errorf("unknown length policy %q (want reject or truncate)", policy)
+}
+
+// LengthLimits caps free-text asset fields, in characters; 0 means unlimited
+type LengthLimits struct {
+	Description int
+	Content     int
+	Policy      LengthPolicy
+}
+
+// ApplyToAsset enforces the limits on an asset's free-text fields,
+// truncating in place or rejecting according to the policy
+func (l LengthLimits) ApplyToAsset(ctx context.Context, asset domain.Asset) error {
+	description, err := l.apply(ctx, "description", asset.GetDescription(), l.Description)
+	if err != nil {
+		return err
+	}
+	if description != asset.GetDescription() {
+		asset.SetDescription(description)
+	}
+
+	if insight, ok := asset.(*domain.Insight); ok {
+		if insight.Content, err = l.apply(ctx, "content", insight.Content, l.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyToDescription enforces the description limit on a new description
+func (l LengthLimits) ApplyToDescription(ctx context.Context, description string) (string, error) {
+	return l.apply(ctx, "description", description, l.Description)
+}
+
+func (l LengthLimits) apply(ctx context.Context, field, value string, limit int) (string, error) {
+	if limit <= 0 || utf8.RuneCountInString(value) <= limit {
+		return value, nil
+	}
+	if l.Policy != LengthTruncate {
+		return "", domain.ErrFieldTooLong
+	}
+
+	Warn(ctx, fmt.Sprintf("%s truncated to %d characters", field, limit))
+	return truncateRunes(value, limit), nil
+}
+
+// truncateRunes keeps the first limit characters without splitting a rune
+func truncateRunes(s string, limit int) string {
+	count := 0
+	for i := range s {
+		if count == limit {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
+// Warnings collects non-fatal problems found while serving a request
+type Warnings struct {
+	mu   sync.Mutex
+	list []string
+}
+
+// List returns the collected warnings
+func (w *Warnings) List() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.list...)
+}
+
+type warningsKey struct{}
+
+// WithWarnings attaches a warning collector to ctx
+func WithWarnings(ctx context.Context) (context.Context, *Warnings) {
+	warnings := &Warnings{}
+	return context.WithValue(ctx, warningsKey{}, warnings), warnings
+}
+
+// Warn records a warning when ctx carries a collector
+func Warn(ctx context.Context, warning string) {
+	if warnings, ok := ctx.Value(warningsKey{}).(*Warnings); ok {
+		warnings.mu.Lock()
+		warnings.list = append(warnings.list, warning)
+		warnings.mu.Unlock()
+	}
+}
@@ -0,0 +1,176 @@
+// Package handoff restarts a server without refusing connections. The
+// running process starts its successor with its listening sockets, so
+// connections queue in the kernel while the two swap over. The successor
+// reports when it has started; the predecessor then drains its in-flight
+// requests, saves whatever state the successor needs, and releases it.
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+)
+
+// envListeners tells a successor how many listening sockets it inherited.
+// They are passed as file descriptors 3 and up, followed by the ready pipe
+// the successor writes to and the release pipe it reads from.
+const envListeners = "HANDOFF_LISTENERS"
+
+// firstFD is the first descriptor after stdin, stdout and stderr
+const firstFD = 3
+
+// signalByte is written on a pipe to report ready or to release
+var signalByte = []byte{1}
+
+// ErrAborted is returned to a successor whose predecessor gave up the handoff
+var ErrAborted = errors.New("handoff aborted by the previous process")
+
+// Listeners hands out the listening sockets of a server: inherited from the
+// previous process after a handoff, otherwise newly opened. Listeners must
+// be requested in the same order in every process.
+type Listeners struct {
+	// Argv is the command Restart starts; it defaults to this executable
+	// with the arguments it was started with
+	Argv []string
+
+	inherited []net.Listener
+	opened    []net.Listener
+	ready     *os.File
+	release   *os.File
+}
+
+// New returns the listeners of this process, taking over those inherited
+// from a predecessor
+func New() (*Listeners, error) {
+	l := &Listeners{}
+	if executable, err := os.Executable(); err == nil {
+		l.Argv = append([]string{executable}, os.Args[1:]...)
+	}
+
+	value, ok := os.LookupEnv(envListeners)
+	if !ok {
+		return l, nil
+	}
+	// A later restart passes its own sockets
+	os.Unsetenv(envListeners)
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid %s %q", envListeners, value)
+	}
+	for i := 0; i < count; i++ {
+		file := os.NewFile(uintptr(firstFD+i), "listener"+strconv.Itoa(i))
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inheriting listener %d: %w", i, err)
+		}
+		l.inherited = append(l.inherited, listener)
+	}
+	l.ready = os.NewFile(uintptr(firstFD+count), "handoff-ready")
+	l.release = os.NewFile(uintptr(firstFD+count+1), "handoff-release")
+	return l, nil
+}
+
+// Inherited reports whether this process took over from a predecessor
+func (l *Listeners) Inherited() bool {
+	return l.ready != nil
+}
+
+// Listen returns the next inherited listener, or a new TCP listener on
+// addr once none are left
+func (l *Listeners) Listen(addr string) (net.Listener, error) {
+	var listener net.Listener
+	if len(l.opened) < len(l.inherited) {
+		listener = l.inherited[len(l.opened)]
+	} else {
+		var err error
+		if listener, err = net.Listen("tcp", addr); err != nil {
+			return nil, err
+		}
+	}
+	l.opened = append(l.opened, listener)
+	return listener, nil
+}
+
+// Ready tells the predecessor that this process has started and it may
+// begin draining. It does nothing without a predecessor.
+func (l *Listeners) Ready() error {
+	if l.ready == nil {
+		return nil
+	}
+	_, err := l.ready.Write(signalByte)
+	l.ready.Close()
+	l.ready = nil
+	return err
+}
+
+// WaitRelease blocks until the predecessor has drained and saved its state.
+// A predecessor that exits without releasing counts as releasing, since it
+// can no longer change its state; one that aborts the handoff gives
+// ErrAborted. It returns at once without a predecessor.
+func (l *Listeners) WaitRelease(ctx context.Context) error {
+	if l.release == nil {
+		return nil
+	}
+	defer func() {
+		l.release.Close()
+		l.release = nil
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		n, err := l.release.Read(buf)
+		switch {
+		case n == 1 && buf[0] == signalByte[0]:
+			done <- nil
+		case n == 1:
+			done <- ErrAborted
+		case errors.Is(err, io.EOF):
+			done <- nil
+		default:
+			done <- err
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Successor is a process started by Restart that waits to be released
+type Successor struct {
+	process *os.Process
+	release *os.File
+}
+
+// PID is the process ID of the successor
+func (s *Successor) PID() int {
+	return s.process.Pid
+}
+
+// Release lets the successor take over. The caller should have stopped
+// serving and saved its state, and exit afterwards.
+func (s *Successor) Release() error {
+	_, err := s.release.Write(signalByte)
+	if closeErr := s.release.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Abort cancels the handoff and stops the successor
+func (s *Successor) Abort() {
+	s.release.Write([]byte{0})
+	s.release.Close()
+	s.process.Kill()
+	s.process.Wait()
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package handoff
+
+import (
+	"context"
+
+	"gwi-favorites-service/internal/domain"
+)
+
+// Supported reports whether Restart can start a successor on this platform
+const Supported = false
+
+// Restart is not supported without fork and inheritable sockets
+func (l *Listeners) Restart(ctx context.Context) (*Successor, error) {
+	return nil, domain.ErrNotSupported
+}
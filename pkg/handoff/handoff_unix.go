@@ -0,0 +1,109 @@
+//go:build unix
+
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Supported reports whether Restart can start a successor on this platform
+const Supported = true
+
+// Restart starts a successor with every listener opened so far, and waits
+// until it reports ready. The successor is stopped when it does not report
+// ready before ctx is done.
+func (l *Listeners) Restart(ctx context.Context) (*Successor, error) {
+	if len(l.Argv) == 0 {
+		return nil, errors.New("no command to restart with")
+	}
+
+	var files []*os.File
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for i, listener := range l.opened {
+		filer, ok := listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, fmt.Errorf("listener %d cannot be handed off", i)
+		}
+		file, err := filer.File()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer readyR.Close()
+	releaseR, releaseW, err := os.Pipe()
+	if err != nil {
+		readyW.Close()
+		return nil, err
+	}
+	files = append(files, readyW, releaseR)
+
+	// exec.Cmd would switch the files to blocking mode, which the sockets
+	// share with the listeners still accepting here, so fork directly
+	fds := []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()}
+	for _, file := range files {
+		raw, err := file.SyscallConn()
+		if err != nil {
+			releaseW.Close()
+			return nil, err
+		}
+		raw.Control(func(fd uintptr) { fds = append(fds, fd) })
+	}
+	pid, err := syscall.ForkExec(l.Argv[0], l.Argv, &syscall.ProcAttr{
+		Env:   append(os.Environ(), envListeners+"="+strconv.Itoa(len(l.opened))),
+		Files: fds,
+	})
+	if err != nil {
+		releaseW.Close()
+		return nil, err
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		releaseW.Close()
+		return nil, err
+	}
+	successor := &Successor{process: process, release: releaseW}
+
+	// The successor holds its own copies of the files; closing ours lets
+	// the ready read fail if it exits
+	for _, file := range files {
+		file.Close()
+	}
+	files = nil
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := io.ReadFull(readyR, buf); err != nil {
+			ready <- fmt.Errorf("successor exited before it was ready: %w", err)
+			return
+		}
+		ready <- nil
+	}()
+
+	select {
+	case err = <-ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		successor.Abort()
+		return nil, err
+	}
+	return successor, nil
+}
@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"gwi-favorites-service/pkg/tracing"
+)
+
+// Config controls timeouts, connection pooling and retries for downstream calls
+type Config struct {
+	// Timeout bounds a single attempt, from dialing until the response body
+	// has been read, so callers streaming large bodies need a longer one
+	Timeout time.Duration
+	// MaxRetries is the number of additional attempts for retryable failures
+	MaxRetries int
+	// InitialBackoff and MaxBackoff bound the jittered exponential backoff between attempts
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxIdleConnsPerHost sizes the keep-alive pool per downstream host
+	MaxIdleConnsPerHost int
+	// UserAgent is sent on every request that does not set its own
+	UserAgent string
+	// DialControl, when set, vets every connection after its address is
+	// resolved and before it is made. Such clients get their own transport
+	// and connect directly, bypassing any proxy, so the check sees the
+	// real destination.
+	DialControl func(network, address string, conn syscall.RawConn) error
+}
+
+// DefaultConfig returns conservative defaults for service-to-service calls
+func DefaultConfig() Config {
+	return Config{
+		Timeout:             10 * time.Second,
+		MaxRetries:          2,
+		InitialBackoff:      100 * time.Millisecond,
+		MaxBackoff:          2 * time.Second,
+		MaxIdleConnsPerHost: 16,
+		UserAgent:           "gwi-favorites-service",
+	}
+}
+
+// Transports are shared by all clients with the same pool settings, so
+// connections are pooled process-wide
+var (
+	transportsMu sync.Mutex
+	transports   = make(map[int]*http.Transport)
+)
+
+// sharedTransport returns the transport for cfg's pool settings
+func sharedTransport(cfg Config) *http.Transport {
+	if cfg.DialControl != nil {
+		return newTransport(cfg)
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+
+	transport, ok := transports[cfg.MaxIdleConnsPerHost]
+	if !ok {
+		transport = newTransport(cfg)
+		transports[cfg.MaxIdleConnsPerHost] = transport
+	}
+	return transport
+}
+
+func newTransport(cfg Config) *http.Transport {
+	proxy := http.ProxyFromEnvironment
+	if cfg.DialControl != nil {
+		proxy = nil
+	}
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   cfg.DialControl,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// Client performs downstream HTTP calls with retries and trace propagation
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New creates a client using the connection pool shared by clients with
+// the same MaxIdleConnsPerHost, or its own pool when DialControl is set
+func New(cfg Config) *Client {
+	return &Client{
+		cfg: cfg,
+		http: &http.Client{
+			Transport: sharedTransport(cfg),
+			Timeout:   cfg.Timeout,
+		},
+	}
+}
+
+// NewDefault creates a client with DefaultConfig
+func NewDefault() *Client {
+	return New(DefaultConfig())
+}
+
+// HTTPClient exposes the underlying client for libraries that need a *http.Client
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Get issues a GET request
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends the request, retrying idempotent requests on network errors and
+// on 429/502/503/504 responses. Requests with a body are only retried when
+// the body can be replayed (see http.Request.GetBody).
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" && c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	tracing.Inject(req.Context(), req.Header)
+
+	retryable := isIdempotent(req) && (req.Body == nil || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.http.Do(req)
+		if !retryable || attempt >= c.cfg.MaxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt, honoring Retry-After
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if wait := time.Duration(seconds) * time.Second; wait <= c.cfg.MaxBackoff {
+				return wait
+			}
+			return c.cfg.MaxBackoff
+		}
+	}
+
+	ceiling := c.cfg.InitialBackoff << attempt
+	if ceiling <= 0 || ceiling > c.cfg.MaxBackoff {
+		ceiling = c.cfg.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	// Full jitter spreads retries from many callers
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
@@ -0,0 +1,69 @@
+// Package mergepatch applies JSON merge patches (RFC 7396). A patch is a
+// JSON document shaped like its target: object members replace the
+// target's, null members remove them, and nested objects are merged
+// recursively. Any other patch value, including an array, replaces the
+// target as a whole.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotObject is returned by Members for patches that are not objects
+var ErrNotObject = errors.New("mergepatch: patch is not a JSON object")
+
+// Apply merges patch into document and returns the result. Numbers are
+// carried through as written, so large integers keep their precision.
+func Apply(document, patch []byte) ([]byte, error) {
+	target, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+// Members lists the top-level members a patch sets or removes. It fails
+// when the patch is not a JSON object.
+func Members(patch []byte) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return nil, ErrNotObject
+	}
+	return members, nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = merge(object[name], value)
+	}
+	return object
+}
+
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
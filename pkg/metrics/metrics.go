@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Labels are the dimensions of a single metric series
+type Labels map[string]string
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	value uint64
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() { atomic.AddUint64(&c.value, 1) }
+
+// Add increments the counter by n
+func (c *Counter) Add(n uint64) { atomic.AddUint64(&c.value, n) }
+
+// Value returns the current counter value
+func (c *Counter) Value() uint64 { return atomic.LoadUint64(&c.value) }
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	bits uint64
+}
+
+// Set replaces the gauge value
+func (g *Gauge) Set(v float64) { atomic.StoreUint64(&g.bits, math.Float64bits(v)) }
+
+// Add adds delta (which may be negative) to the gauge value
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&g.bits, old, next) {
+			return
+		}
+	}
+}
+
+// Value returns the current gauge value
+func (g *Gauge) Value() float64 { return math.Float64frombits(atomic.LoadUint64(&g.bits)) }
+
+type metricKind string
+
+const (
+	kindCounter metricKind = "counter"
+	kindGauge   metricKind = "gauge"
+)
+
+type family struct {
+	name   string
+	help   string
+	kind   metricKind
+	series map[string]*series
+}
+
+type series struct {
+	labels  Labels
+	counter *Counter
+	gauge   *Gauge
+	fn      func() float64
+}
+
+func (s *series) value() float64 {
+	switch {
+	case s.fn != nil:
+		return s.fn()
+	case s.counter != nil:
+		return float64(s.counter.Value())
+	default:
+		return s.gauge.Value()
+	}
+}
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// DefaultRegistry is the process-wide registry exposed on /metrics
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter returns the counter series for name and labels, creating it if needed
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	s := r.series(name, help, kindCounter, labels, func() *series {
+		return &series{labels: labels, counter: &Counter{}}
+	})
+	return s.counter
+}
+
+// Gauge returns the gauge series for name and labels, creating it if needed
+func (r *Registry) Gauge(name, help string, labels Labels) *Gauge {
+	s := r.series(name, help, kindGauge, labels, func() *series {
+		return &series{labels: labels, gauge: &Gauge{}}
+	})
+	return s.gauge
+}
+
+// GaugeFunc registers a gauge whose value is computed by fn at scrape time,
+// replacing any function previously registered for the same series
+func (r *Registry) GaugeFunc(name, help string, labels Labels, fn func() float64) {
+	s := r.series(name, help, kindGauge, labels, func() *series {
+		return &series{labels: labels}
+	})
+
+	r.mu.Lock()
+	s.fn = fn
+	r.mu.Unlock()
+}
+
+// CounterFunc registers a counter whose value is read from fn at scrape time,
+// for monotonic totals already tracked elsewhere
+func (r *Registry) CounterFunc(name, help string, labels Labels, fn func() float64) {
+	s := r.series(name, help, kindCounter, labels, func() *series {
+		return &series{labels: labels}
+	})
+
+	r.mu.Lock()
+	s.fn = fn
+	r.mu.Unlock()
+}
+
+func (r *Registry) series(name, help string, kind metricKind, labels Labels, create func() *series) *series {
+	key := labelKey(labels)
+
+	r.mu.RLock()
+	if f, exists := r.families[name]; exists {
+		if s, exists := f.series[key]; exists {
+			r.mu.RUnlock()
+			return s
+		}
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, exists := r.families[name]
+	if !exists {
+		f = &family{name: name, help: help, kind: kind, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	if s, exists := f.series[key]; exists {
+		return s
+	}
+
+	s := create()
+	f.series[key] = s
+	return s
+}
+
+// WriteTo renders all metrics in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s%s %v\n", f.name, key, f.series[key].value())
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the registry in the Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.WriteTo(w)
+	})
+}
+
+// labelKey renders labels in exposition syntax, sorted so equal label sets share a series
+func labelKey(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[name])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Value returns the current value of the series for name and labels and
+// whether the series exists
+func (r *Registry) Value(name string, labels Labels) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, exists := r.families[name]
+	if !exists {
+		return 0, false
+	}
+	s, exists := f.series[labelKey(labels)]
+	if !exists {
+		return 0, false
+	}
+	return s.value(), true
+}
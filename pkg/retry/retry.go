@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Policy bounds how long an operation is retried
+type Policy struct {
+	// Attempts is the total number of tries; values below 1 mean one try
+	Attempts int
+	// InitialBackoff and MaxBackoff bound the jittered exponential backoff between attempts
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Do returns it without further attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error, the attempts
+// run out or ctx is done. onRetry, when set, is told about each failure
+// that will be retried and the wait before the next attempt. The last
+// error is returned, unwrapped from Permanent.
+func Do(ctx context.Context, policy Policy, fn func() error, onRetry func(attempt int, err error, wait time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= policy.Attempts {
+			return err
+		}
+
+		wait := policy.backoff(attempt)
+		if onRetry != nil {
+			onRetry(attempt, err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns the delay after the given attempt
+func (p Policy) backoff(attempt int) time.Duration {
+	ceiling := p.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.MaxBackoff {
+		ceiling = p.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	// Equal jitter keeps waits growing while spreading out restarting replicas
+	half := ceiling / 2
+	return half + time.Duration(rand.Int63n(int64(ceiling-half)+1))
+}
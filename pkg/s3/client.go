@@ -0,0 +1,184 @@
+// Package s3 is a minimal client for S3-compatible object storage
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gwi-favorites-service/pkg/awsv4"
+	"gwi-favorites-service/pkg/httpclient"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("s3 object not found")
+
+// Config locates a bucket
+type Config struct {
+	Bucket string
+	Region string
+	// Endpoint overrides the AWS endpoint, e.g. for MinIO. Buckets are
+	// addressed by path, which every S3-compatible store supports.
+	Endpoint    string
+	Credentials awsv4.Credentials
+}
+
+// Client reads and writes objects in one bucket
+type Client struct {
+	http     *httpclient.Client
+	cfg      Config
+	endpoint string
+	now      func() time.Time
+}
+
+// New creates a client for cfg.Bucket
+func New(client *httpclient.Client, cfg Config) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	return &Client{
+		http:     client,
+		cfg:      cfg,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		now:      time.Now,
+	}, nil
+}
+
+// Put stores data under key. headers are sent with the request, e.g.
+// x-amz-server-side-encryption.
+func (c *Client) Put(ctx context.Context, key string, data []byte, headers map[string]string) error {
+	resp, err := c.do(ctx, http.MethodPut, key, nil, data, headers)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get returns the object stored under key
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes the object under key. Deleting a missing object succeeds.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Object describes a stored object
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// List returns every object whose key starts with prefix, in key order
+func (c *Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding s3 listing: %w", err)
+		}
+
+		objects = append(objects, page.Contents...)
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	target := c.endpoint + "/" + url.PathEscape(c.cfg.Bucket)
+	if key != "" {
+		target += "/" + escapeKey(key)
+	}
+	if len(query) > 0 {
+		target += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	awsv4.Sign(req, body, c.cfg.Credentials, c.cfg.Region, "s3", c.now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+		resp.Body.Close()
+		if failure.Code != "" {
+			return nil, fmt.Errorf("s3 %s %s returned %s: %s %s", method, key, resp.Status, failure.Code, failure.Message)
+		}
+		return nil, fmt.Errorf("s3 %s %s returned %s", method, key, resp.Status)
+	}
+	return resp, nil
+}
+
+// escapeKey escapes each segment of key, keeping the slashes
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
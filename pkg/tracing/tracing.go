@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context headers
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// SpanContext identifies the current position in a distributed trace
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+	State   string
+}
+
+// TraceParent formats the span context as a W3C traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceParent parses a W3C traceparent header value
+func ParseTraceParent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if !isHex(parts[1]) || !isHex(parts[2]) || !isHex(parts[3]) || parts[1] == strings.Repeat("0", 32) {
+		return SpanContext{}, false
+	}
+
+	return SpanContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Sampled: parts[3] == "01",
+	}, true
+}
+
+// NewSpanContext starts a new trace
+func NewSpanContext(sampled bool) SpanContext {
+	return SpanContext{TraceID: randomHex(16), SpanID: randomHex(8), Sampled: sampled}
+}
+
+// ChildOf returns a new span within the same trace
+func ChildOf(parent SpanContext) SpanContext {
+	return SpanContext{TraceID: parent.TraceID, SpanID: randomHex(8), Sampled: parent.Sampled, State: parent.State}
+}
+
+type contextKey struct{}
+
+// ContextWithSpan attaches a span context to ctx
+func ContextWithSpan(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// SpanFromContext returns the span context attached to ctx, if any
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// Inject writes the span context from ctx into outgoing request headers as a child span
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	child := ChildOf(sc)
+	header.Set(TraceParentHeader, child.TraceParent())
+	if child.State != "" {
+		header.Set(TraceStateHeader, child.State)
+	}
+}
+
+// Sampler decides whether a request's span is sampled. parent is the
+// caller's span context, or nil when the request starts a new trace.
+type Sampler func(r *http.Request, parent *SpanContext) bool
+
+// ParentBased keeps the caller's sampling decision and does not sample the
+// traces it starts
+func ParentBased(r *http.Request, parent *SpanContext) bool {
+	return parent != nil && parent.Sampled
+}
+
+// Middleware continues the caller's trace (or starts a new one) for every
+// incoming request and echoes the traceparent on the response. Sampling is
+// ParentBased.
+func Middleware(next http.Handler) http.Handler {
+	return MiddlewareWithSampler(ParentBased)(next)
+}
+
+// MiddlewareWithSampler is Middleware with the sampling decision of each
+// request's span left to sample
+func MiddlewareWithSampler(sample Sampler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var sc SpanContext
+			if parent, ok := ParseTraceParent(r.Header.Get(TraceParentHeader)); ok {
+				parent.State = r.Header.Get(TraceStateHeader)
+				sc = ChildOf(parent)
+				sc.Sampled = sample(r, &parent)
+			} else {
+				sc = NewSpanContext(sample(r, nil))
+			}
+
+			w.Header().Set(TraceParentHeader, sc.TraceParent())
+			next.ServeHTTP(w, r.WithContext(ContextWithSpan(r.Context(), sc)))
+		})
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,119 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// setup creates a service with one user holding the given number of favorites
+func setup(b *testing.B, favorites int) (*memory.Repository, *service.FavoritesService, http.Handler) {
+	b.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	repo := memory.NewRepository()
+	if err := repo.CreateUser(domain.NewUser("user1", "", "")); err != nil {
+		b.Fatal(err)
+	}
+
+	svc := service.NewFavoritesService(repo, log)
+	for i := 0; i < favorites; i++ {
+		chart := domain.NewChart(fmt.Sprintf("chart%d", i), "Chart", "X", "Y", "", nil)
+		if err := svc.AddFavorite(context.Background(), "user1", chart); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return repo, svc, handler.NewHandler(svc, log).SetupRoutes()
+}
+
+// discardWriter is a ResponseWriter that drops everything, so benchmarks
+// measure the handler rather than the recorder
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func BenchmarkRepository_IsFavorite(b *testing.B) {
+	repo, _, _ := setup(b, 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		repo.IsFavorite("user1", "chart50")
+	}
+}
+
+func BenchmarkRepository_GetFavoriteCount(b *testing.B) {
+	repo, _, _ := setup(b, 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		repo.GetFavoriteCount("user1")
+	}
+}
+
+func BenchmarkService_IsFavorite(b *testing.B) {
+	_, svc, _ := setup(b, 100)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		svc.IsFavorite(ctx, "user1", "chart50")
+	}
+}
+
+func BenchmarkHandler_CheckIsFavorite(b *testing.B) {
+	_, _, h := setup(b, 100)
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites/chart50/check", nil)
+	w := &discardWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkHandler_GetFavoriteCount(b *testing.B) {
+	_, _, h := setup(b, 100)
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites/count", nil)
+	w := &discardWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkHandler_CheckIsFavoriteParallel(b *testing.B) {
+	_, _, h := setup(b, 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites/chart50/check", nil)
+		w := &discardWriter{header: make(http.Header)}
+		for pb.Next() {
+			h.ServeHTTP(w, req)
+		}
+	})
+}
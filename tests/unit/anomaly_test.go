@@ -0,0 +1,42 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/anomaly"
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetector_ThrottlesBursts(t *testing.T) {
+	var events []anomaly.Event
+	detector := anomaly.NewDetector(anomaly.Options{
+		Window:    time.Minute,
+		Threshold: 3,
+		Cooldown:  time.Hour,
+	}, func(event anomaly.Event) { events = append(events, event) })
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, detector.Observe("user1"))
+	}
+
+	// Crossing the threshold raises one event and throttles the key
+	assert.Equal(t, domain.ErrRateLimited, detector.Observe("user1"))
+	assert.Equal(t, domain.ErrRateLimited, detector.Observe("user1"))
+	assert.True(t, detector.Throttled("user1"))
+	assert.Len(t, events, 1)
+	assert.Equal(t, "user1", events[0].Key)
+
+	// Other keys are unaffected
+	assert.NoError(t, detector.Observe("user2"))
+	assert.False(t, detector.Throttled("user2"))
+}
+
+func TestDetector_Disabled(t *testing.T) {
+	detector := anomaly.NewDetector(anomaly.Options{Window: time.Minute}, nil)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, detector.Observe("user1"))
+	}
+}
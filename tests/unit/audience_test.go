@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAgeGroup(t *testing.T) {
+	for text, want := range map[string]domain.AgeGroup{
+		"25-34":          "25-34",
+		"25 to 34 years": "25-34",
+		"25 – 34":        "25-34",
+		"65+":            "65+",
+		"Over 65":        "65+",
+		"65 and over":    "65+",
+		"under 18":       "0-18",
+		"40":             "40-40",
+	} {
+		group, err := domain.ParseAgeGroup(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, group, text)
+	}
+
+	for _, text := range []string{"", "young", "34-25", "18-200", "teens 13-19"} {
+		_, err := domain.ParseAgeGroup(text)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, text)
+	}
+
+	group := domain.AgeGroup("65+")
+	min, max, ok := group.Bounds()
+	assert.True(t, ok)
+	assert.Equal(t, 65, min)
+	assert.Equal(t, -1, max, "open bands have no upper bound")
+	assert.True(t, group.Contains(90))
+	assert.False(t, group.Contains(64))
+	assert.True(t, domain.AgeGroup("25-34").Contains(34))
+	assert.False(t, domain.AgeGroup("young").Contains(20))
+}
+
+func TestParseSocialMediaHours(t *testing.T) {
+	for text, want := range map[string]domain.SocialMediaHours{
+		"1-3":              "1-3",
+		"1-3 hours":        "1-3",
+		"1 to 3 hrs a day": "1-3",
+		"less than 1":      "0-1",
+		"3+":               "3+",
+		"more than 3h":     "3+",
+	} {
+		hours, err := domain.ParseSocialMediaHours(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, hours, text)
+	}
+
+	_, err := domain.ParseSocialMediaHours("30+")
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "there are only 24 hours in a day")
+	assert.True(t, domain.SocialMediaHours("").IsValid(), "unknown hours are allowed")
+}
+
+func TestAudienceAttributes_Decoding(t *testing.T) {
+	asset, err := domain.AssetFromJSON([]byte(`{"id": "audience1", "type": "audience",
+		"age_groups": ["25 to 34", "18-24"], "social_media_hours": "1-3 hours"}`))
+	require.NoError(t, err)
+	audience := asset.(*domain.Audience)
+	assert.Equal(t, []domain.AgeGroup{"25-34", "18-24"}, audience.AgeGroups, "legacy forms are read canonically")
+	assert.Equal(t, domain.SocialMediaHours("1-3"), audience.SocialMediaHours)
+	require.NoError(t, audience.Validate())
+
+	// Stored assets with unreadable values still load, but cannot be written
+	data, err := repository.EncodeAsset(&domain.Audience{
+		BaseAsset: domain.BaseAsset{ID: "audience2", Type: domain.AssetTypeAudience},
+		AgeGroups: []domain.AgeGroup{"young adults"},
+	})
+	require.NoError(t, err)
+	stored, err := repository.DecodeAsset(data)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AgeGroup{"young adults"}, stored.(*domain.Audience).AgeGroups)
+	assert.ErrorIs(t, stored.Validate(), domain.ErrInvalidInput)
+}
+
+func TestAudienceAttributes_OnIngestion(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, logger.NewLogger())
+	ctx := context.Background()
+
+	audience := domain.NewAudience("audience1", "")
+	audience.AgeGroups = []domain.AgeGroup{"over 65", "25 to 34", "25-34"}
+	audience.SocialMediaHours = "less than 1 hour"
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", audience))
+	stored, err := repo.GetAsset("audience1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AgeGroup{"25-34", "65+"}, stored.(*domain.Audience).AgeGroups)
+	assert.Equal(t, domain.SocialMediaHours("0-1"), stored.(*domain.Audience).SocialMediaHours)
+
+	invalid := domain.NewAudience("audience2", "")
+	invalid.SocialMediaHours = "a lot"
+	err = favorites.AddFavorite(ctx, "user1", invalid)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	assert.Equal(t, "social_media_hours", domain.ContextOf(err)["field"])
+}
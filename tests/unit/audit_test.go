@@ -0,0 +1,214 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/audit"
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog_FavoriteMutations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.OpenFileLog(path)
+	require.NoError(t, err)
+
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	svc := service.NewFavoritesService(repo, logger.NewLogger(), service.WithAuditLog(auditLog))
+	ctx := auth.WithClaims(context.Background(), &auth.Claims{Subject: "user1"})
+
+	require.NoError(t, svc.AddFavorite(ctx, "user1", domain.NewChart("chart1", "Chart", "X", "Y", "before", nil)))
+	require.NoError(t, svc.UpdateFavoriteNote(ctx, "user1", "chart1", "after"))
+	require.NoError(t, svc.RemoveFavorite(context.Background(), "user1", "chart1"))
+	require.NoError(t, auditLog.Close())
+
+	// Entries survive a reopen and the ID sequence continues
+	auditLog, err = audit.OpenFileLog(path)
+	require.NoError(t, err)
+	defer auditLog.Close()
+
+	entries, err := auditLog.Query(audit.Filter{UserIDs: []string{"user1"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, audit.FavoriteRemoved, entries[0].Action)
+	assert.Equal(t, audit.Anonymous, entries[0].Actor)
+	assert.NotEmpty(t, entries[0].Old)
+
+	update := entries[1]
+	assert.Equal(t, audit.FavoriteUpdated, update.Action)
+	assert.Equal(t, "user1", update.Actor)
+	assert.JSONEq(t, `{"note": ""}`, string(update.Old))
+	assert.JSONEq(t, `{"note": "after"}`, string(update.New))
+
+	var added map[string]interface{}
+	require.NoError(t, json.Unmarshal(entries[2].New, &added))
+	assert.Equal(t, "chart1", added["id"])
+
+	require.NoError(t, auditLog.Append(audit.Entry{Action: audit.AssetDeleted, AssetID: "chart1"}))
+	entries, err = auditLog.Query(audit.Filter{Actions: []audit.Action{audit.AssetDeleted}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(4), entries[0].ID)
+}
+
+func TestAuditLog_TransfersAndOrder(t *testing.T) {
+	auditLog := audit.NewMemoryLog()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user2", "", "")))
+	svc := service.NewFavoritesService(repo, logger.NewLogger(), service.WithAuditLog(auditLog))
+	ctx := context.Background()
+
+	for _, id := range []string{"insight1", "insight2"} {
+		require.NoError(t, svc.AddFavorite(ctx, "user1", domain.NewInsight(id, "Growth", "", nil, "")))
+	}
+	require.NoError(t, svc.UpdateFavoriteNote(ctx, "user1", "insight1", "Read later"))
+	require.NoError(t, svc.PinFavorite(ctx, "user1", "insight2", true))
+	require.NoError(t, svc.ReorderFavorites(ctx, "user1", []string{"insight2", "insight1"}))
+
+	entries, err := auditLog.Query(audit.Filter{UserIDs: []string{"user1"}, Actions: []audit.Action{audit.FavoriteUpdated}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Empty(t, entries[0].AssetID, "a reorder changes the favorites as a whole")
+	assert.JSONEq(t, `{"order": []}`, string(entries[0].Old))
+	assert.JSONEq(t, `{"order": ["insight2", "insight1"]}`, string(entries[0].New))
+	assert.Equal(t, "insight2", entries[1].AssetID)
+	assert.JSONEq(t, `{"pinned": false}`, string(entries[1].Old))
+	assert.JSONEq(t, `{"pinned": true}`, string(entries[1].New))
+
+	// Moves record the favorite added to one user and removed from the other
+	_, err = svc.TransferFavorites(ctx, "user1", "user2", []string{"insight1"}, true)
+	require.NoError(t, err)
+	added, err := auditLog.Query(audit.Filter{UserIDs: []string{"user2"}, Actions: []audit.Action{audit.FavoriteAdded}})
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	removed, err := auditLog.Query(audit.Filter{UserIDs: []string{"user1"}, Actions: []audit.Action{audit.FavoriteRemoved}})
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	for _, value := range []json.RawMessage{added[0].New, removed[0].Old} {
+		var favorite struct {
+			Asset struct {
+				ID string `json:"id"`
+			} `json:"asset"`
+			Note string `json:"note"`
+		}
+		require.NoError(t, json.Unmarshal(value, &favorite))
+		assert.Equal(t, "insight1", favorite.Asset.ID)
+		assert.Equal(t, "Read later", favorite.Note)
+	}
+	assert.Empty(t, added[0].Old)
+	assert.Empty(t, removed[0].New)
+}
+
+func TestHandler_AuditQueries(t *testing.T) {
+	for name, open := range map[string]func(t *testing.T) audit.Log{
+		"memory": func(t *testing.T) audit.Log { return audit.NewMemoryLog() },
+		"file": func(t *testing.T) audit.Log {
+			fileLog, err := audit.OpenFileLog(filepath.Join(t.TempDir(), "audit.log"))
+			require.NoError(t, err)
+			t.Cleanup(func() { fileLog.Close() })
+			return fileLog
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			auditLog := open(t)
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			actions := []audit.Action{audit.FavoriteAdded, audit.FavoriteUpdated, audit.FavoriteRemoved}
+			for i := 0; i < 9; i++ {
+				require.NoError(t, auditLog.Append(audit.Entry{
+					Time:    start.Add(time.Duration(i) * time.Hour),
+					Actor:   []string{"alice", "bob", "carol"}[i%3],
+					Action:  actions[i/3],
+					UserID:  "user1",
+					AssetID: fmt.Sprintf("asset%d", i),
+				}))
+			}
+
+			log := logger.NewLogger()
+			routes := asAdmin(handler.NewHandler(service.NewFavoritesService(memory.NewRepository(), log), log,
+				handler.WithAuditLog(auditLog),
+				handler.WithAdminAPIKey(testAdminKey),
+			).SetupRoutes())
+			get := func(path string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				return rec
+			}
+			type page struct {
+				Data     []audit.Entry            `json:"data"`
+				Warnings []string                 `json:"warnings"`
+				Cursor   handler.CursorPagination `json:"cursor"`
+			}
+			query := func(path string) page {
+				rec := get(path)
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				var p page
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+				return p
+			}
+			ids := func(entries []audit.Entry) []uint64 {
+				ids := make([]uint64, len(entries))
+				for i, entry := range entries {
+					ids[i] = entry.ID
+				}
+				return ids
+			}
+
+			// Values of one filter are alternatives; filters are combined
+			p := query("/api/admin/audit?actor=alice,bob&action=favorite.add&action=favorite.remove")
+			assert.Equal(t, []uint64{8, 7, 2, 1}, ids(p.Data))
+			p = query("/api/admin/audit?asset_id=asset3,asset4&since=2025-01-01T04:00:00Z")
+			assert.Equal(t, []uint64{5}, ids(p.Data))
+
+			// Cursors page newest first until the entries run out
+			p = query("/api/admin/audit?limit=4")
+			assert.Equal(t, []uint64{9, 8, 7, 6}, ids(p.Data))
+			require.True(t, p.Cursor.HasMore)
+			p = query("/api/admin/audit?limit=4&cursor=" + p.Cursor.Next)
+			assert.Equal(t, []uint64{5, 4, 3, 2}, ids(p.Data))
+			p = query("/api/admin/audit?limit=4&cursor=" + p.Cursor.Next)
+			assert.Equal(t, []uint64{1}, ids(p.Data))
+			assert.False(t, p.Cursor.HasMore)
+			assert.Empty(t, p.Cursor.Next)
+
+			// Oversized limits are capped rather than refused
+			p = query("/api/admin/audit?limit=5000")
+			assert.Len(t, p.Data, 9)
+			assert.Equal(t, 1000, p.Cursor.Limit)
+			assert.Equal(t, []string{"limit capped at 1000"}, p.Warnings)
+			assert.Equal(t, http.StatusBadRequest, get("/api/admin/audit?cursor=abc").Code)
+			assert.Equal(t, http.StatusBadRequest, get("/api/admin/audit?limit=0").Code)
+
+			rec := get("/api/admin/audit/export?actor=alice&after=1")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Header().Get("Content-Disposition"), ".ndjson")
+			lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+			require.Len(t, lines, 2)
+			var entry audit.Entry
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+			assert.Equal(t, uint64(4), entry.ID, "exports run oldest first")
+
+			rec = get("/api/admin/audit/export?actor=nobody")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Body.String())
+			assert.Equal(t, http.StatusBadRequest, get("/api/admin/audit/export?since=yesterday").Code)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_TokenSubjectMustOwnPath(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user2", "", "")))
+
+	h := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithStorageService(service.NewStorageService(repo, log)),
+	)
+	routes := h.SetupRoutes()
+
+	get := func(path, subject, role string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if subject != "" {
+			token, err := auth.SignHS256("secret", auth.Claims{Subject: subject, Role: role})
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/api/users/user1/favorites", "", ""))
+	assert.Equal(t, http.StatusOK, get("/api/users/user1/favorites", "user1", ""))
+	assert.Equal(t, http.StatusForbidden, get("/api/users/user2/favorites", "user1", ""))
+	assert.Equal(t, http.StatusOK, get("/api/users/user2/favorites", "ops", "admin"))
+
+	// Admin routes need the admin role
+	assert.Equal(t, http.StatusForbidden, get("/api/admin/storage/stats", "user1", ""))
+	assert.Equal(t, http.StatusOK, get("/api/admin/storage/stats", "ops", "admin"))
+}
+
+func TestHandler_AdminAssetCatalog(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAssetService(service.NewAssetService(repo, service.DeleteCascade, log)),
+		handler.WithAdminAPIKey("ops-key"),
+	).SetupRoutes()
+
+	send := func(method, path, key, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("X-Admin-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	chart := `{"id": "chart1", "type": "chart", "title": "Sales", "x_axis_title": "Month", "y_axis_title": "Revenue"}`
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/admin/assets", "", chart))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/admin/assets", "wrong", chart))
+	assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/api/admin/assets", "ops-key", chart))
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/api/admin/assets", "ops-key", chart))
+
+	// Updates keep the asset's ID and type
+	updated := `{"id": "chart1", "type": "chart", "title": "Sales 2025", "x_axis_title": "Month", "y_axis_title": "Revenue"}`
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/api/admin/assets/chart1", "ops-key", updated))
+	asset, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "Sales 2025", asset.(*domain.Chart).Title)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/admin/assets/chart2", "ops-key", updated))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/admin/assets/chart1", "ops-key", `{"id": "chart1", "type": "insight", "text": "Growth"}`))
+
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/admin/assets/chart1", "ops-key", ""))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/admin/assets/chart1", "ops-key", ""))
+
+	// User routes stay open without an authenticator
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/users/user1/favorites", "", ""))
+}
+
+// testAdminKey is the admin API key sent by asAdmin
+const testAdminKey = "ops-key"
+
+// asAdmin sends every request to routes with testAdminKey, for tests of
+// admin routes that are not about authentication. Admin routes fail closed,
+// so the handler must be built with handler.WithAdminAPIKey(testAdminKey).
+func asAdmin(routes http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("X-Admin-Key", testAdminKey)
+		routes.ServeHTTP(w, r)
+	})
+}
+
+func TestHandler_AdminRoutesFailClosed(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+
+	// Neither an admin API key nor an authenticator is configured
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithStorageService(service.NewStorageService(repo, log)),
+	).SetupRoutes()
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/admin/storage/stats", nil),
+		httptest.NewRequest(http.MethodPost, "/api/admin/storage/integrity", nil),
+		httptest.NewRequest(http.MethodPost, "/api/admin/favorites/transfer", strings.NewReader(`{}`)),
+	} {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, request)
+		assert.Equal(t, http.StatusForbidden, rec.Code, request.URL.Path)
+	}
+
+	// User routes stay open without authentication
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/backup"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/s3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backupAddedAt is when user1 first favorited, long before any restore
+var backupAddedAt = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func newBackupSource(t *testing.T) *memory.Repository {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Sales", "Month", "Revenue", "", chartPoints(5))))
+	require.NoError(t, repo.CreateAsset(domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, repo.CreateAsset(domain.NewAudience("unused", "Nobody's favorite")))
+	for _, userID := range []string{"user1", "user2"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")))
+	}
+	chart, _ := repo.GetAsset("chart1")
+	insight, _ := repo.GetAsset("insight1")
+	require.NoError(t, repo.ImportFavorite("user1", chart, backupAddedAt))
+	require.NoError(t, repo.ImportFavorite("user1", insight, backupAddedAt.Add(time.Hour)))
+	require.NoError(t, repo.AddFavorite("user2", chart))
+
+	// user2 keeps their own description of the chart
+	own := *chart.(*domain.Chart)
+	own.Description = "my chart"
+	require.NoError(t, repo.UpdateFavoriteAsset("user2", "chart1", &own))
+	return repo
+}
+
+func TestBackup_ArchiveRoundTrip(t *testing.T) {
+	source := newBackupSource(t)
+
+	archive, err := backup.Dump(source, 2)
+	require.NoError(t, err)
+	assert.Len(t, archive.Assets, 3)
+	assert.Len(t, archive.Users, 2)
+	require.Len(t, archive.Favorites, 3)
+	for _, favorite := range archive.Favorites {
+		assert.Equal(t, favorite.UserID == "user2", favorite.Asset != nil, "only differing copies are kept")
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+	data, err := backup.Encode(archive, key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Sales")
+
+	_, err = backup.Decode(data, nil)
+	assert.ErrorContains(t, err, "encrypted")
+	_, err = backup.Decode(data, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorContains(t, err, "cannot be decrypted")
+
+	decoded, err := backup.Decode(data, key)
+	require.NoError(t, err)
+
+	target := memory.NewRepository()
+	result, err := backup.Restore(target, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreResult{Assets: 3, Users: 2, Favorites: 3}, result)
+
+	favorites, err := target.GetUserFavorites("user2", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	assert.Equal(t, "my chart", favorites[0].Asset.GetDescription())
+	assert.Len(t, favorites[0].Asset.(*domain.Chart).Data, 5)
+
+	favorites, err = target.GetUserFavorites("user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, favorites, 2)
+	assert.True(t, favorites[0].AddedAt.Equal(backupAddedAt), "favorites keep when they were added")
+	assert.True(t, favorites[1].AddedAt.Equal(backupAddedAt.Add(time.Hour)))
+
+	// Restoring again only skips
+	result, err = backup.Restore(target, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreResult{Skipped: 8}, result)
+
+	_, err = backup.ParseKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
+
+func TestBackup_ManagerStoresVersionedEncryptedBackups(t *testing.T) {
+	fake, server := newFakeS3()
+	defer server.Close()
+	client, err := s3.New(httpclient.NewDefault(), s3.Config{Bucket: "bucket", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	opts := backup.Options{Prefix: "backups/", Key: bytes.Repeat([]byte{1}, 32), ServerSideEncryption: "AES256", Retain: 2}
+	manager := backup.NewManager(newBackupSource(t), client, opts)
+	ctx := context.Background()
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		info, err := manager.Backup(ctx)
+		require.NoError(t, err)
+		assert.True(t, info.Encrypted)
+		assert.True(t, strings.HasSuffix(info.Name, ".json.gz.enc"))
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, "AES256", fake.headers["backups/"+names[2]].Get("X-Amz-Server-Side-Encryption"))
+
+	// Only the newest backups are retained, newest first
+	backups, err := manager.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, names[2], backups[0].Name)
+	assert.Equal(t, names[1], backups[1].Name)
+
+	// Another instance restores into an empty store
+	target := memory.NewRepository()
+	result, err := backup.NewManager(target, client, opts).Restore(ctx, names[1])
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Favorites)
+	count, err := target.GetFavoriteCount("user1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = manager.Restore(ctx, names[0])
+	assert.ErrorIs(t, err, domain.ErrBackupNotFound)
+	_, err = manager.Restore(ctx, "../secrets.json")
+	assert.ErrorIs(t, err, domain.ErrBackupNotFound)
+}
+
+func TestHandler_BackupRoutes(t *testing.T) {
+	_, server := newFakeS3()
+	defer server.Close()
+	client, err := s3.New(httpclient.NewDefault(), s3.Config{Bucket: "bucket", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	log := logger.NewLogger()
+	repo := newBackupSource(t)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithBackups(backup.NewManager(repo, client, backup.Options{Prefix: "backups/"})),
+		handler.WithAdminAPIKey("ops-key"),
+	).SetupRoutes()
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Admin-Key", "ops-key")
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/admin/backups")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Data backup.Info `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.False(t, created.Data.Encrypted)
+
+	rec = do(http.MethodGet, "/api/admin/backups")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), created.Data.Name)
+
+	rec = do(http.MethodPost, "/api/admin/backups/"+created.Data.Name+"/restore")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"skipped":8`)
+
+	rec = do(http.MethodPost, "/api/admin/backups/favorites-20000101T000000.000Z.json.gz/restore")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/cache"
+	"gwi-favorites-service/internal/repository/memory"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRepository_Invalidation(t *testing.T) {
+	server := miniredis.RunT(t)
+	backend := memory.NewRepository()
+	repo, err := cache.Open(backend, cache.Options{Addr: server.Addr(), KeyPrefix: "test:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user2", "", "")))
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.AddFavorite("user2", chart))
+
+	// The second read is served from Redis
+	query := domain.FavoritesQuery{Limit: 10}
+	_, err = repo.GetUserFavorites("user1", query)
+	require.NoError(t, err)
+	favorites, err := repo.GetUserFavorites("user1", query)
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	assert.Equal(t, "Chart 1", favorites[0].Asset.(*domain.Chart).Title)
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, repo.Stats())
+
+	isFavorite, err := repo.IsFavorite("user2", "chart1")
+	require.NoError(t, err)
+	assert.True(t, isFavorite)
+
+	// Updating the shared asset invalidates every user holding it
+	require.NoError(t, repo.UpdateAsset(domain.NewChart("chart1", "Renamed", "X", "Y", "", nil)))
+	favorites, err = repo.GetUserFavorites("user1", query)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", favorites[0].Asset.(*domain.Chart).Title)
+
+	// Writes for a user invalidate that user's entries
+	require.NoError(t, repo.RemoveFavorite("user1", "chart1"))
+	favorites, err = repo.GetUserFavorites("user1", query)
+	require.NoError(t, err)
+	assert.Empty(t, favorites)
+
+	// Deleting the asset invalidates cached positive lookups
+	require.NoError(t, repo.DeleteAsset("chart1"))
+	isFavorite, err = repo.IsFavorite("user2", "chart1")
+	require.NoError(t, err)
+	assert.False(t, isFavorite)
+
+	// Reads fall back to the backend when Redis is down
+	server.Close()
+	count, err := repo.GetFavoriteCount("user2")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	favorites, err = repo.GetUserFavorites("user2", query)
+	require.NoError(t, err)
+	assert.Empty(t, favorites)
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHistory_Counts(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 30, 0, time.UTC))
+	history := ratelimit.NewHistory(fake)
+
+	history.Record("user1", false)
+	fake.Advance(40 * time.Minute)
+	history.Record("user1", true)
+	history.Record("user1", false)
+	history.Record("user2", false)
+
+	assert.Equal(t, ratelimit.RequestCounts{CurrentMinute: 2, LastHour: 3, ThrottledLastHour: 1}, history.Counts("user1"))
+	assert.Equal(t, ratelimit.RequestCounts{}, history.Counts("user3"))
+
+	// The first request leaves the hour, then the whole history does
+	fake.Advance(25 * time.Minute)
+	assert.Equal(t, ratelimit.RequestCounts{LastHour: 2, ThrottledLastHour: 1}, history.Counts("user1"))
+	fake.Advance(time.Hour)
+	assert.Equal(t, ratelimit.RequestCounts{}, history.Counts("user1"))
+}
+
+func TestHandler_CallerUsage(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+
+	tiers, err := ratelimit.ParseTiers(map[string]string{"free": "0.001:3"})
+	require.NoError(t, err)
+	limiter, err := ratelimit.NewLimiter(tiers, "free")
+	require.NoError(t, err)
+	budget, err := ratelimit.NewCostBudget(100)
+	require.NoError(t, err)
+
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithRateLimiter(limiter),
+		handler.WithQueryCostBudget(budget, ratelimit.DefaultCostWeights, 0),
+		handler.WithConcurrencyLimiter(ratelimit.NewConcurrencyLimiter(4)),
+		handler.WithRequestHistory(ratelimit.NewHistory(clock.NewFake(time.Now()))),
+	).SetupRoutes()
+
+	token, err := auth.SignHS256("secret", auth.Claims{Subject: "user1"})
+	require.NoError(t, err)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, get("/api/users/user1/favorites").Code)
+	usage := func() handler.CallerUsage {
+		rec := get("/api/users/user1/usage")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response struct {
+			Data handler.CallerUsage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	current := usage()
+	require.NotNil(t, current.RateLimit)
+	assert.Equal(t, "free", current.RateLimit.Tier)
+	assert.Equal(t, 3, current.RateLimit.Burst)
+	assert.Equal(t, 2, current.RateLimit.Remaining)
+	assert.Zero(t, current.RateLimit.RetryAfterSeconds)
+	require.NotNil(t, current.QueryCost)
+	assert.Equal(t, 100, current.QueryCost.BudgetPerMinute)
+	assert.Equal(t, 99, current.QueryCost.Remaining)
+	require.NotNil(t, current.Concurrency)
+	assert.Equal(t, handler.ConcurrencyUsage{Max: 4, InFlight: 1}, *current.Concurrency)
+	assert.Equal(t, 1, current.Requests.LastHour, "requests are counted once they complete")
+
+	// Throttled callers can still see when they may retry
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, get("/api/users/user1/favorites").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, get("/api/users/user1/favorites").Code)
+	current = usage()
+	assert.Zero(t, current.RateLimit.Remaining)
+	assert.Positive(t, current.RateLimit.RetryAfterSeconds)
+	assert.Equal(t, ratelimit.RequestCounts{CurrentMinute: 5, LastHour: 5, ThrottledLastHour: 1}, current.Requests)
+
+	// Only callers' own usage
+	assert.Equal(t, http.StatusForbidden, get("/api/users/user2/usage").Code)
+}
@@ -0,0 +1,28 @@
+package unit
+
+import (
+	"testing"
+
+	"gwi-favorites-service/pkg/canonicaljson"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := canonicaljson.Canonicalize([]byte(`{"b": [1.0, 2e0, 1E21, 0.0000001], "a": "<x>é"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":"<x>é","b":[1,2,1e+21,1e-7]}`, string(a))
+
+	// Field order and number spelling do not change the hash
+	h1, err := canonicaljson.Hash(map[string]interface{}{"x": 1, "y": 2.50})
+	require.NoError(t, err)
+	b, err := canonicaljson.Canonicalize([]byte(`{"y":2.5,"x":1.000}`))
+	require.NoError(t, err)
+	m, err := canonicaljson.Marshal(map[string]float64{"x": 1, "y": 2.5})
+	require.NoError(t, err)
+	assert.Equal(t, string(b), string(m))
+	h2, err := canonicaljson.Hash(map[string]float64{"y": 2.5, "x": 1})
+	require.NoError(t, err)
+	assert.Equal(t, h1, h2)
+}
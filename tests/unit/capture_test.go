@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/capture"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureStore_SessionsAndRecordings(t *testing.T) {
+	store := capture.NewStore(capture.Options{MaxRecordings: 2, MaxWindow: time.Minute})
+
+	session := store.Enable("user1", time.Hour)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), session.Until, time.Second, "window is capped")
+	assert.True(t, store.Active("user1"))
+	assert.False(t, store.Active("user2"))
+
+	store.Record(capture.Recording{
+		UserID:         "user1",
+		Path:           "/api/users/user1/favorites",
+		RequestHeaders: map[string][]string{"Authorization": {"Bearer abc"}, "Accept": {"application/json"}},
+		RequestBody:    `{"id":"chart1","owner":{"email":"a@example.com"},"token":"t"}`,
+		ResponseBody:   `{"success":true`,
+	})
+	store.Record(capture.Recording{UserID: "user2", ResponseBody: "plain text"})
+	store.Record(capture.Recording{UserID: "user1", Path: "/latest"})
+
+	// Oldest recording is dropped at capacity; newest comes first
+	all := store.Recordings("")
+	require.Len(t, all, 2)
+	assert.Equal(t, "/latest", all[0].Path)
+	assert.Equal(t, "plain text", all[1].ResponseBody)
+
+	store.Disable("user1")
+	assert.False(t, store.Active("user1"))
+}
+
+func TestCaptureStore_Sanitizes(t *testing.T) {
+	headers := capture.SanitizeHeaders(map[string][]string{"Authorization": {"Bearer abc"}, "Accept": {"application/json"}})
+	assert.Equal(t, []string{"[REDACTED]"}, headers["Authorization"])
+	assert.Equal(t, []string{"application/json"}, headers["Accept"])
+
+	body := capture.SanitizeBody(`{"id":"chart1","owner":{"Email":"a@example.com"},"items":[{"password":"p"}]}`)
+	assert.JSONEq(t, `{"id":"chart1","owner":{"Email":"[REDACTED]"},"items":[{"password":"[REDACTED]"}]}`, body)
+
+	// Truncated JSON cannot be redacted, so it is not kept
+	assert.NotContains(t, capture.SanitizeBody(`{"token":"secret-value","id":`), "secret-value")
+	assert.Equal(t, "plain text", capture.SanitizeBody("plain text"))
+}
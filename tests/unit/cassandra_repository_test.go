@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/cassandra"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCassandraRepository connects to the cluster in CASSANDRA_TEST_HOSTS,
+// skipping the test when it is not set
+func newCassandraRepository(t *testing.T) *cassandra.Repository {
+	hosts := os.Getenv("CASSANDRA_TEST_HOSTS")
+	if hosts == "" {
+		t.Skip("CASSANDRA_TEST_HOSTS is not set")
+	}
+
+	repo, err := cassandra.Open(cassandra.Options{
+		Hosts:             strings.Split(hosts, ","),
+		Keyspace:          "favorites_test",
+		ReplicationFactor: 1,
+		ReadConsistency:   "ONE",
+		WriteConsistency:  "ONE",
+		Timeout:           10 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestCassandraRepository_UpdatesDoNotResurrectRows(t *testing.T) {
+	repo := newCassandraRepository(t)
+	// The keyspace outlives the test, so every run uses its own IDs
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	userID, assetID := "user-"+suffix, "chart-"+suffix
+
+	chart := domain.NewChart(assetID, "Chart", "X", "Y", "", []domain.ChartDataPoint{{X: "Jan", Y: 1}})
+	require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")))
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.AddFavorite(userID, chart))
+	require.NoError(t, repo.UpdateFavoriteAsset(userID, assetID, chart))
+
+	// A removed favorite stays removed when its asset changes
+	require.NoError(t, repo.RemoveFavorite(userID, assetID))
+	chart.Title = "Renamed"
+	require.NoError(t, repo.UpdateAsset(chart))
+	assert.Equal(t, domain.ErrFavoriteNotFound, repo.UpdateFavoriteAsset(userID, assetID, chart))
+	isFavorite, err := repo.IsFavorite(userID, assetID)
+	require.NoError(t, err)
+	assert.False(t, isFavorite)
+
+	// A deleted asset stays deleted
+	require.NoError(t, repo.DeleteAsset(assetID))
+	assert.Equal(t, domain.ErrAssetNotFound, repo.UpdateAsset(chart))
+	_, err = repo.GetAsset(assetID)
+	assert.Equal(t, domain.ErrAssetNotFound, err)
+}
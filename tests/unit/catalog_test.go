@@ -0,0 +1,162 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedCatalog creates audience0..audience4 and chart0..chart4, with
+// chart2 deleted from the catalog
+func seedCatalog(t *testing.T) *memory.Repository {
+	repo := memory.NewRepository()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.CreateAsset(domain.NewAudience(fmt.Sprintf("audience%d", i), "Audience")))
+		chart := domain.NewChart(fmt.Sprintf("chart%d", i), "Chart", "X", "Y", "", nil)
+		if i == 2 {
+			chart.MarkDeleted(time.Now())
+		}
+		require.NoError(t, repo.CreateAsset(chart))
+	}
+	return repo
+}
+
+func assetIDs(page domain.AssetPage) []string {
+	ids := make([]string, len(page.Assets))
+	for i, asset := range page.Assets {
+		ids[i] = asset.GetID()
+	}
+	return ids
+}
+
+func TestAssetService_ListAssets(t *testing.T) {
+	assets := service.NewAssetService(seedCatalog(t), service.DeleteOrphan, logger.NewLogger())
+	ctx := context.Background()
+
+	page, err := assets.ListAssets(ctx, domain.AssetQuery{Type: domain.AssetTypeChart, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chart0", "chart1"}, assetIDs(page))
+	require.True(t, page.HasMore)
+	assert.Equal(t, 2, *page.NextOffset, "the backend filters by type, so offsets count charts only")
+
+	// The deleted chart is skipped, and the last page has no next offset
+	page, err = assets.ListAssets(ctx, domain.AssetQuery{Type: domain.AssetTypeChart, Limit: 5, Offset: *page.NextOffset})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chart3", "chart4"}, assetIDs(page))
+	assert.False(t, page.HasMore)
+	assert.Nil(t, page.NextOffset)
+
+	page, err = assets.ListAssets(ctx, domain.AssetQuery{Type: domain.AssetTypeChart, Limit: 10, IncludeUnavailable: true})
+	require.NoError(t, err)
+	assert.Len(t, page.Assets, 5)
+
+	_, err = assets.ListAssets(ctx, domain.AssetQuery{Type: "video", Limit: 10})
+	assert.ErrorIs(t, err, domain.ErrInvalidAssetType)
+}
+
+func TestHandler_AssetCatalog(t *testing.T) {
+	repo := seedCatalog(t)
+	log := logger.NewLogger()
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAssetService(service.NewAssetService(repo, service.DeleteOrphan, log)),
+		handler.WithAdminAPIKey(testAdminKey),
+	).SetupRoutes())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	list := func(path string) (ids []string, hasMore bool) {
+		rec := get(path)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body struct {
+			Data struct {
+				Assets []struct {
+					ID string `json:"id"`
+				} `json:"assets"`
+				HasMore bool `json:"has_more"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		for _, asset := range body.Data.Assets {
+			ids = append(ids, asset.ID)
+		}
+		return ids, body.Data.HasMore
+	}
+
+	ids, hasMore := list("/api/assets?type=chart")
+	assert.Equal(t, []string{"chart0", "chart1", "chart3", "chart4"}, ids)
+	assert.False(t, hasMore)
+	ids, _ = list("/api/admin/assets?type=chart")
+	assert.Contains(t, ids, "chart2", "admins see deleted assets")
+	ids, hasMore = list("/api/assets?limit=3")
+	assert.Len(t, ids, 3)
+	assert.True(t, hasMore)
+
+	assert.Equal(t, http.StatusOK, get("/api/assets/chart1").Code)
+	assert.Equal(t, http.StatusGone, get("/api/assets/chart2").Code)
+	assert.Equal(t, http.StatusOK, get("/api/admin/assets/chart2").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/assets/missing").Code)
+	assert.Equal(t, http.StatusOK, get("/api/assets/featured").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/assets?type=video").Code)
+
+	ids, _ = list("/api/assets?type=audience&q=audience&limit=2")
+	assert.Equal(t, []string{"audience0", "audience1"}, ids)
+	ids, _ = list("/api/assets?q=nothing+matches")
+	assert.Empty(t, ids)
+	assert.Equal(t, http.StatusBadRequest, get("/api/assets?q="+strings.Repeat("a+", 9)).Code)
+}
+
+// scanOnlyRepository hides the memory backend's native catalog search, as
+// backends without one are scanned
+type scanOnlyRepository struct {
+	repository.FavoritesRepository
+}
+
+func TestAssetService_SearchAssets(t *testing.T) {
+	repo := seedCatalog(t)
+	sales := domain.NewChart("chart5", "Quarterly Sales", "Quarter", "Revenue", "", nil)
+	require.NoError(t, repo.CreateAsset(sales))
+	require.NoError(t, repo.CreateAsset(domain.NewInsight("insight1", "Sales grew in Q3", "", []string{"growth"}, "")))
+	ctx := context.Background()
+
+	for name, backend := range map[string]repository.FavoritesRepository{
+		"native": repo,
+		"scan":   scanOnlyRepository{repo},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assets := service.NewAssetService(backend, service.DeleteOrphan, logger.NewLogger())
+
+			page, err := assets.ListAssets(ctx, domain.AssetQuery{Search: "SALES", Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"chart5", "insight1"}, assetIDs(page))
+
+			page, err = assets.ListAssets(ctx, domain.AssetQuery{Type: domain.AssetTypeInsight, Search: "growth", Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"insight1"}, assetIDs(page), "tags are searched")
+
+			page, err = assets.ListAssets(ctx, domain.AssetQuery{Search: "sales", Limit: 1})
+			require.NoError(t, err)
+			require.True(t, page.HasMore)
+			page, err = assets.ListAssets(ctx, domain.AssetQuery{Search: "sales", Limit: 1, Offset: *page.NextOffset})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"insight1"}, assetIDs(page))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CheckFavoritesBatch(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	svc := service.NewFavoritesService(repo, log)
+	require.NoError(t, svc.AddFavorite(context.Background(), "user1", domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)))
+	require.NoError(t, svc.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	routes := handler.NewHandler(svc, log).SetupRoutes()
+
+	check := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/users/user1/favorites/check", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := check(`{"asset_ids": ["chart1", "unknown", "insight1", "chart1"]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data map[string]bool `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, map[string]bool{"chart1": true, "insight1": true, "unknown": false}, response.Data)
+
+	ids := make([]string, 101)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%q", fmt.Sprintf("asset%d", i))
+	}
+	assert.Equal(t, http.StatusBadRequest, check(`{"asset_ids": [`+strings.Join(ids, ",")+`]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, check(`{"asset_ids": []}`).Code)
+	assert.Equal(t, http.StatusBadRequest, check(`{"asset_ids": [""]}`).Code)
+
+	// The single-asset route is unaffected
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites/chart1/check", nil)
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	assert.JSONEq(t, `{"success":true,"data":{"is_favorite":true}}`, rec.Body.String())
+}
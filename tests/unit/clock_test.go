@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/repository/sqlstore"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock_Fake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	fake.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), fake.Now())
+
+	assert.Equal(t, clock.System, clock.OrSystem(nil))
+	assert.Equal(t, clock.Clock(fake), clock.OrSystem(fake))
+}
+
+func TestRepositories_UseInjectedClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	backends := map[string]func(c clock.Clock) repository.FavoritesRepository{
+		"memory": func(c clock.Clock) repository.FavoritesRepository {
+			return memory.NewRepositoryWithOptions(memory.Options{Clock: c})
+		},
+		"sqlite": func(c clock.Clock) repository.FavoritesRepository {
+			repo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"), sqlstore.WithClock(c))
+			require.NoError(t, err)
+			t.Cleanup(func() { repo.Close() })
+			return repo
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			fake := clock.NewFake(start)
+			repo := open(fake)
+			require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+			asset := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+			require.NoError(t, repo.CreateAsset(asset))
+			require.NoError(t, repo.AddFavorite("user1", asset))
+
+			fake.Advance(90 * time.Minute)
+			require.NoError(t, repo.UpdateFavoriteAsset("user1", "chart1", asset))
+
+			favorites, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{})
+			require.NoError(t, err)
+			require.Len(t, favorites, 1)
+			assert.True(t, start.Equal(favorites[0].AddedAt), "added at %s", favorites[0].AddedAt)
+			assert.True(t, start.Add(90*time.Minute).Equal(favorites[0].UpdatedAt), "updated at %s", favorites[0].UpdatedAt)
+		})
+	}
+}
+
+func TestServices_UseInjectedClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	repo := memory.NewRepositoryWithOptions(memory.Options{Clock: fake})
+	log := logger.NewLogger()
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log, service.WithClock(fake))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+
+	fake.Advance(time.Hour)
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "renamed"))
+	listed, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, start.Add(time.Hour), listed[0].UpdatedAt)
+
+	fake.Advance(time.Hour)
+	assets := service.NewAssetService(repo, service.DeleteOrphan, log, service.WithAssetClock(fake))
+	require.NoError(t, assets.DeleteAsset(ctx, "insight1"))
+	asset, err := repo.GetAsset("insight1")
+	require.NoError(t, err)
+	require.NotNil(t, asset.GetDeletedAt())
+	assert.Equal(t, start.Add(2*time.Hour), *asset.GetDeletedAt())
+}
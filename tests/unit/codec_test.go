@@ -0,0 +1,34 @@
+package unit
+
+import (
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetCodec_RoundTrip(t *testing.T) {
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "desc", nil)
+
+	data, err := repository.EncodeAsset(chart)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"schema_version":1`)
+
+	decoded, err := repository.DecodeAsset(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Chart 1", decoded.(*domain.Chart).Title)
+}
+
+func TestAssetCodec_UpgradesUnversionedAssets(t *testing.T) {
+	legacy := []byte(`{"id":"insight1","type":"insight","content":"c","created_at":"2023-01-02T03:04:05Z"}`)
+
+	decoded, err := repository.DecodeAsset(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, decoded.GetCreatedAt(), decoded.GetUpdatedAt())
+
+	_, err = repository.DecodeAsset([]byte(`{"id":"x","type":"chart","schema_version":99}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollections_Backends(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepository(),
+		"sqlite": sqliteRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		seedSearchFavorites(t, repo)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+		require.True(t, favorites.SupportsCollections(), name)
+
+		sales, err := favorites.CreateCollection(ctx, "user1", "  Sales  ")
+		require.NoError(t, err, name)
+		assert.Equal(t, "Sales", sales.Name, name)
+
+		_, err = favorites.CreateCollection(ctx, "user1", "Sales")
+		assert.ErrorIs(t, err, domain.ErrCollectionAlreadyExists, name)
+		_, err = favorites.CreateCollection(ctx, "user1", " ")
+		assert.ErrorIs(t, err, domain.ErrMissingRequiredField, name)
+
+		require.NoError(t, favorites.AddToCollection(ctx, "user1", sales.ID, "chart1"), name)
+		require.NoError(t, favorites.AddToCollection(ctx, "user1", sales.ID, "chart1"), name)
+		require.NoError(t, favorites.AddToCollection(ctx, "user1", sales.ID, "audience1"), name)
+		require.NoError(t, favorites.AddToCollection(ctx, "user1", sales.ID, "insight1"), name)
+		assert.ErrorIs(t, favorites.AddToCollection(ctx, "user1", sales.ID, "missing"), domain.ErrFavoriteNotFound, name)
+		assert.ErrorIs(t, favorites.AddToCollection(ctx, "user1", "missing", "chart1"), domain.ErrCollectionNotFound, name)
+
+		page, pagination, err := favorites.ListCollectionFavorites(ctx, "user1", sales.ID, domain.FavoritesQuery{Limit: 2})
+		require.NoError(t, err, name)
+		assert.Len(t, page, 2, name)
+		assert.Equal(t, 3, pagination.Total, name)
+		assert.True(t, pagination.HasMore, name)
+
+		page, _, err = favorites.ListCollectionFavorites(ctx, "user1", sales.ID, domain.FavoritesQuery{Search: "sales"})
+		require.NoError(t, err, name)
+		assert.Len(t, page, 2, name)
+
+		// Removing the favorite takes it out of the collection too
+		require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "insight1"), name)
+		require.NoError(t, favorites.RemoveFromCollection(ctx, "user1", sales.ID, "audience1"), name)
+		assert.ErrorIs(t, favorites.RemoveFromCollection(ctx, "user1", sales.ID, "audience1"), domain.ErrNotInCollection, name)
+
+		collections, err := favorites.ListCollections(ctx, "user1")
+		require.NoError(t, err, name)
+		require.Len(t, collections, 1, name)
+		assert.Equal(t, 1, collections[0].FavoriteCount, name)
+
+		// Deleting the collection keeps the favorites
+		require.NoError(t, favorites.DeleteCollection(ctx, "user1", sales.ID), name)
+		assert.ErrorIs(t, favorites.DeleteCollection(ctx, "user1", sales.ID), domain.ErrCollectionNotFound, name)
+		isFavorite, err := favorites.IsFavorite(ctx, "user1", "chart1")
+		require.NoError(t, err, name)
+		assert.True(t, isFavorite, name)
+	}
+}
+
+func TestCollections_Routes(t *testing.T) {
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	log := logger.NewLogger()
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log).SetupRoutes()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/api/users/user1/collections", `{"name":"Reports"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created struct {
+		Data domain.Collection `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	base := "/api/users/user1/collections/" + created.Data.ID
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/api/users/user1/collections", `{"name":"Reports"}`).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, base+"/favorites", `{"asset_id":"chart1"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, base+"/favorites", `{"asset_id":"missing"}`).Code)
+
+	rec = serve(http.MethodGet, base, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed struct {
+		Data       []map[string]interface{} `json:"data"`
+		Pagination domain.Pagination        `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	assert.Len(t, listed.Data, 1)
+	assert.Equal(t, 1, listed.Pagination.Total)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodDelete, base+"/favorites/chart1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, base+"/favorites/chart1", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodDelete, base, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, base, "").Code)
+
+	rec = serve(http.MethodGet, "/api/capabilities", "")
+	assert.Contains(t, rec.Body.String(), `"collections"`)
+}
@@ -0,0 +1,34 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_RefusesWeakJWTSecret(t *testing.T) {
+	t.Setenv("AUTH_MODE", "none")
+	_, err := config.Load()
+	require.NoError(t, err, "the default secret is fine while it signs nothing")
+
+	t.Setenv("AUTH_MODE", "hs256")
+	for _, secret := range []string{"", config.DefaultJWTSecret, "change-me"} {
+		t.Setenv("JWT_SECRET", secret)
+		_, err = config.Load()
+		assert.ErrorContains(t, err, "JWT_SECRET", "secret %q", secret)
+	}
+	t.Setenv("JWT_SECRET", strings.Repeat("k", config.MinJWTSecretLength))
+	_, err = config.Load()
+	require.NoError(t, err)
+
+	// Issuing tokens needs a strong secret whatever the auth mode
+	t.Setenv("AUTH_MODE", "none")
+	t.Setenv("AUTH_LOGIN_ENABLED", "true")
+	t.Setenv("JWT_SECRET", config.DefaultJWTSecret)
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "JWT_SECRET")
+}
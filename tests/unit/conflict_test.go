@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNoteService(t *testing.T, policy service.ConflictPolicy) *service.FavoritesService {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	svc := service.NewFavoritesService(repo, logger.NewLogger(), service.WithConflictPolicy(policy))
+	require.NoError(t, svc.AddFavorite(context.Background(), "user1", domain.NewChart("chart1", "Sales", "Month", "Revenue", "original", nil)))
+	return svc
+}
+
+func TestFavoritesService_ReportsConcurrentNoteEdits(t *testing.T) {
+	svc := newNoteService(t, service.ConflictReport)
+	ctx := context.Background()
+	base := ""
+
+	// Two clients start from the same note; the first save is clean
+	first, err := svc.EditFavoriteNote(ctx, "user1", "chart1", "from laptop", &base)
+	require.NoError(t, err)
+	assert.False(t, first.Conflict)
+
+	// The second still wins, and learns what it replaced
+	second, err := svc.EditFavoriteNote(ctx, "user1", "chart1", "from phone", &base)
+	require.NoError(t, err)
+	assert.True(t, second.Conflict)
+	assert.Equal(t, "from phone", second.Note)
+	require.NotNil(t, second.ConflictingNote)
+	assert.Equal(t, "from laptop", *second.ConflictingNote)
+
+	favorites, err := svc.GetUserFavorites(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "from phone", favorites[0].Note)
+
+	// Clients that send no base are never reported
+	result, err := svc.EditFavoriteNote(ctx, "user1", "chart1", "anything", nil)
+	require.NoError(t, err)
+	assert.False(t, result.Conflict)
+
+	_, err = service.ParseConflictPolicy("merge")
+	assert.Error(t, err)
+}
+
+func TestHandler_NoteConflictPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy   service.ConflictPolicy
+		body     string
+		conflict string
+	}{
+		{service.ConflictOverwrite, `{"note": "mine", "base_note": "stale"}`, `"conflict":false`},
+		{service.ConflictReport, `{"note": "mine", "base_note": "stale"}`, `"conflict":true,"conflicting_note":"draft"`},
+		// Clients written before notes still send description fields
+		{service.ConflictReport, `{"description": "mine", "base_description": "stale"}`, `"conflict":true,"conflicting_note":"draft"`},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			log := logger.NewLogger()
+			svc := newNoteService(t, tc.policy)
+			require.NoError(t, svc.UpdateFavoriteNote(context.Background(), "user1", "chart1", "draft"))
+			routes := handler.NewHandler(svc, log).SetupRoutes()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/user1/favorites/chart1", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Contains(t, rec.Body.String(), `"message":"Favorite note updated"`)
+			assert.Contains(t, rec.Body.String(), `"note":"mine",`+tc.conflict)
+		})
+	}
+}
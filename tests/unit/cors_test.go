@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	policy := handler.DefaultCORSPolicy()
+	policy.AllowedOrigins = []string{"https://app.example.com", "https://*.partner.com"}
+	policy.AllowCredentials = true
+	policy.MaxAge = time.Hour
+
+	log := logger.NewLogger()
+	router := handler.NewHandler(service.NewFavoritesService(memory.NewRepository(), log), log,
+		handler.WithCORSPolicy(policy),
+	).SetupRoutes()
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/users/user1/favorites", nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Preflight from an allowed origin reaches the middleware despite the
+	// route not serving OPTIONS
+	rec := request(http.MethodOptions, "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "3600", rec.Header().Get("Access-Control-Max-Age"))
+	for _, header := range []string{"Authorization", "If-None-Match", "X-Admin-Key"} {
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), header)
+	}
+
+	// Subdomain wildcards match subdomains only
+	rec = request(http.MethodGet, "https://eu.partner.com")
+	assert.Equal(t, "https://eu.partner.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "ETag")
+	rec = request(http.MethodGet, "https://partner.com")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Other origins get no CORS headers
+	rec = request(http.MethodOptions, "https://evil.example.org")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+
+	// Credentials cannot be combined with any origin
+	policy.AllowedOrigins = []string{"*"}
+	assert.Error(t, policy.Validate())
+}
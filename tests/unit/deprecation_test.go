@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/deprecation"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecation_Parse(t *testing.T) {
+	policies, err := deprecation.Parse("route:favorites.count=2024-01-01,2024-07-01; header:x-api-version=2024-03-01")
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "route:favorites.count", policies[0].Feature())
+	assert.Equal(t, "X-Api-Version", policies[1].Name)
+	assert.True(t, policies[1].Sunset.IsZero())
+
+	for _, spec := range []string{
+		"favorites.count=2024-01-01",
+		"cookie:session=2024-01-01",
+		"query:version=yesterday",
+		"query:version=2024-07-01,2024-01-01",
+	} {
+		_, err := deprecation.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestDeprecationMiddleware(t *testing.T) {
+	policies, err := deprecation.Parse("path:/api/users=2024-01-01,2024-07-01;query:legacy=2024-01-01,2024-02-01;route:favorites.count=2999-01-01")
+	require.NoError(t, err)
+
+	setup := func(enforce bool) http.Handler {
+		repo := memory.NewRepository()
+		require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+		log := logger.NewLogger()
+		svc := service.NewFavoritesService(repo, log)
+		return handler.NewHandler(svc, log,
+			handler.WithDeprecations(deprecation.NewEngine(policies, enforce), "https://example.com/migration"),
+		).SetupRoutes()
+	}
+
+	// Deprecated paths are served with the nearest sunset announced
+	router := setup(false)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites?legacy=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "@1704067200", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Feb 2024 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migration>; rel="deprecation"`, rec.Header().Get("Link"))
+
+	// Deprecations dated in the future are not announced yet
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/capabilities", nil))
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+
+	// Enforced sunsets retire the feature
+	rec = httptest.NewRecorder()
+	setup(true).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites", nil))
+	assert.Equal(t, http.StatusGone, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"sunset"`)
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"gwi-favorites-service/internal/directory"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDirectory_SCIM(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/scim/v2/Users/alice":
+			w.Write([]byte(`{"id": "alice", "userName": "alice", "displayName": "Alice", "emails": [{"value": "alice@example.com", "primary": true}]}`))
+		case "/scim/v2/Users/bob":
+			w.Write([]byte(`{"id": "bob", "userName": "bob", "active": false}`))
+		case "/scim/v2/Users/flaky":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := httpclient.DefaultConfig()
+	cfg.MaxRetries = 0
+	users := directory.NewCached(directory.NewSCIM(httpclient.New(cfg), server.URL+"/scim/v2/", "secret"), directory.CacheOptions{})
+
+	repo := memory.NewRepository()
+	svc := service.NewFavoritesService(repo, logger.NewLogger(), service.WithUserDirectory(users))
+	ctx := context.Background()
+
+	// Directory users are created on their first favorite
+	require.NoError(t, svc.AddFavorite(ctx, "alice", domain.NewChart("chart1", "Chart", "X", "Y", "", nil)))
+	user, err := repo.GetUser("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "Alice", user.Name)
+
+	// Unknown and inactive users are rejected, and unknown IDs are cached
+	assert.ErrorIs(t, svc.AddFavorite(ctx, "mallory", domain.NewChart("chart1", "Chart", "X", "Y", "", nil)), domain.ErrUserNotFound)
+	assert.ErrorIs(t, svc.AddFavorite(ctx, "bob", domain.NewChart("chart1", "Chart", "X", "Y", "", nil)), domain.ErrUserNotFound)
+	before := lookups.Load()
+	_, err = users.LookupUser(ctx, "mallory")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.Equal(t, before, lookups.Load())
+
+	// Directory failures are not cached
+	_, err = users.LookupUser(ctx, "flaky")
+	assert.ErrorIs(t, err, domain.ErrDirectoryUnavailable)
+	_, err = users.LookupUser(ctx, "flaky")
+	assert.ErrorIs(t, err, domain.ErrDirectoryUnavailable)
+	assert.Equal(t, before+2, lookups.Load())
+}
@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/embedded"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedRepository_Favorites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.bolt")
+	repo, err := embedded.Open(path)
+	require.NoError(t, err)
+
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", []domain.ChartDataPoint{{X: "Jan", Y: 1}})
+	insight := domain.NewInsight("insight1", "Insight", "", []string{"tag"}, "")
+
+	assert.Equal(t, domain.ErrUserNotFound, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	assert.Equal(t, domain.ErrAssetNotFound, repo.AddFavorite("user1", chart))
+
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.CreateAsset(insight))
+	assert.Equal(t, domain.ErrAssetAlreadyExists, repo.CreateAsset(chart))
+
+	require.NoError(t, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.AddFavorite("user1", insight))
+	assert.Equal(t, domain.ErrFavoriteAlreadyExists, repo.AddFavorite("user1", chart))
+
+	charts, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{Type: domain.AssetTypeChart, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, charts, 1)
+	assert.Equal(t, "Chart 1", charts[0].Asset.(*domain.Chart).Title)
+	assert.False(t, charts[0].AddedAt.IsZero())
+
+	// Data survives reopening the file
+	require.NoError(t, repo.Close())
+	repo, err = embedded.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	count, err := repo.GetFavoriteCount("user1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Deleting an asset removes it from favorites
+	require.NoError(t, repo.DeleteAsset("chart1"))
+	isFavorite, err := repo.IsFavorite("user1", "chart1")
+	require.NoError(t, err)
+	assert.False(t, isFavorite)
+
+	assert.Equal(t, domain.ErrFavoriteNotFound, repo.RemoveFavorite("user1", "chart1"))
+	require.NoError(t, repo.RemoveFavorite("user1", "insight1"))
+}
@@ -0,0 +1,25 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainErrors_Context(t *testing.T) {
+	err := domain.WithContext(domain.ErrFavoriteNotFound, "user_id", "user1", "asset_id", "")
+	err = domain.WithContext(err, "asset_id", "chart1")
+	wrapped := fmt.Errorf("updating favorite: %w", err)
+
+	assert.ErrorIs(t, wrapped, domain.ErrFavoriteNotFound)
+	assert.Equal(t, "favorite_not_found", domain.CodeOf(wrapped))
+	assert.Equal(t, map[string]string{"user_id": "user1", "asset_id": "chart1"}, domain.ContextOf(wrapped))
+	assert.Equal(t, "favorite not found (asset_id=chart1, user_id=user1)", err.Error())
+
+	assert.Nil(t, domain.WithContext(nil, "user_id", "user1"))
+	assert.Empty(t, domain.CodeOf(errors.New("plain")))
+}
@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+
+	"gwi-favorites-service/internal/experiment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperiment_Parse(t *testing.T) {
+	experiments, err := experiment.Parse("ranking=control:90,recency:10; layout=list,grid")
+	require.NoError(t, err)
+	require.Len(t, experiments, 2)
+	assert.Equal(t, []experiment.Variant{{Name: "control", Weight: 90}, {Name: "recency", Weight: 10}}, experiments[0].Variants)
+	assert.Equal(t, 1, experiments[1].Variants[1].Weight)
+
+	for _, spec := range []string{"ranking", "ranking=a:x", "ranking=a:0", "a=x;a=y"} {
+		_, err := experiment.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestExperiment_AssignmentIsDeterministicAndWeighted(t *testing.T) {
+	experiments, err := experiment.Parse("ranking=control:90,recency:10")
+	require.NoError(t, err)
+
+	var exposures int
+	assigner := experiment.NewAssigner(experiments, func(string, experiment.Assignment) { exposures++ })
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		assignment := assigner.Assign(userID)[0]
+		assert.Equal(t, assignment, assigner.Assign(userID)[0])
+		counts[assignment.Variant]++
+	}
+	assert.InDelta(t, 9000, counts["control"], 300)
+	assert.Zero(t, exposures)
+
+	assignments := assigner.Expose("user1")
+	assert.Equal(t, 1, exposures)
+	assert.Equal(t, "ranking="+assignments[0].Variant, experiment.Header(assignments))
+}
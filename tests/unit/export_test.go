@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/serializer"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFavorites(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user2", "", "")))
+
+	log := logger.NewLogger()
+	favorites := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+
+	chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "Monthly sales", []domain.ChartDataPoint{{X: "Jan", Y: 10.0}})
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", chart))
+	insight := domain.NewInsight("insight1", "=HYPERLINK(\"x\")", "Risky", []string{"a", "b"}, "finance")
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", insight))
+	audience := domain.NewAudience("audience1", "Young")
+	audience.AgeGroups = []domain.AgeGroup{"18-24", "25-34"}
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", audience))
+	// More favorites than one export page
+	for i := 0; i < 600; i++ {
+		require.NoError(t, favorites.AddFavorite(ctx, "user2", domain.NewAudience(fmt.Sprintf("bulk%03d", i), "Bulk")))
+	}
+
+	routes := handler.NewHandler(favorites, log).SetupRoutes()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("json", func(t *testing.T) {
+		rec := get("/api/users/user2/favorites/export")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="favorites-user2-\d{8}\.json"$`, rec.Header().Get("Content-Disposition"))
+
+		var exported []serializer.FavoriteView
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &exported))
+		assert.Len(t, exported, 600, "every page is exported")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rec := get("/api/users/user1/favorites/export?format=csv")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="favorites-user1-\d{8}\.csv"$`, rec.Header().Get("Content-Disposition"))
+
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, serializer.CSVHeader, rows[0])
+
+		byID := make(map[string]map[string]string)
+		for _, row := range rows[1:] {
+			cells := make(map[string]string)
+			for i, column := range rows[0] {
+				cells[column] = row[i]
+			}
+			byID[cells["asset_id"]] = cells
+		}
+		assert.Equal(t, "Sales", byID["chart1"]["title"])
+		assert.JSONEq(t, `[{"x":"Jan","y":10}]`, byID["chart1"]["data"])
+		assert.Empty(t, byID["chart1"]["content"], "columns of other types are left empty")
+		assert.Equal(t, `'=HYPERLINK("x")`, byID["insight1"]["content"], "formulas are neutralized")
+		assert.Equal(t, "a; b", byID["insight1"]["tags"])
+		assert.Equal(t, "18-24; 25-34", byID["audience1"]["age_groups"])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.NoError(t, repo.CreateUser(domain.NewUser("user3", "", "")))
+		rec := get("/api/users/user3/favorites/export")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[]`, rec.Body.String())
+
+		rec = get("/api/users/user3/favorites/export?format=csv")
+		require.Equal(t, http.StatusOK, rec.Code)
+		rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{serializer.CSVHeader}, rows)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/export?format=xml").Code)
+		rec := get("/api/users/nobody/favorites/export")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+}
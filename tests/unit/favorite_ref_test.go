@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavoritesService_AddFavoriteByID(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Monthly Sales", "Month", "Revenue", "Revenue by region", nil)))
+	favorites := service.NewFavoritesService(repo, logger.NewLogger())
+	ctx := context.Background()
+
+	asset, err := favorites.AddFavoriteByID(ctx, "user1", "chart1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AssetTypeChart, asset.GetType())
+
+	page, err := favorites.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "Monthly Sales", page[0].Asset.(*domain.Chart).Title)
+
+	_, err = favorites.AddFavoriteByID(ctx, "user1", "chart1")
+	assert.ErrorIs(t, err, domain.ErrFavoriteAlreadyExists)
+	_, err = favorites.AddFavoriteByID(ctx, "user1", "missing")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+	_, err = repo.GetAsset("missing")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound, "a reference never creates an asset")
+	_, err = favorites.AddFavoriteByID(ctx, "user1", "")
+	assert.ErrorIs(t, err, domain.ErrMissingRequiredField)
+}
+
+func TestHandler_AddFavoriteByID(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Monthly Sales", "Month", "Revenue", "Revenue by region", nil)))
+	require.NoError(t, repo.CreateAsset(domain.NewAudience("audience1", "Sales leads in Europe")))
+	routes := handler.NewHandler(service.NewFavoritesService(repo, logger.NewLogger()), logger.NewLogger()).SetupRoutes()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post("/api/users/user1/favorites/chart1", "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = post("/api/users/user1/favorites/chart1", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = post("/api/users/user1/favorites/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// The collection endpoint takes a reference body as well
+	rec = post("/api/users/user1/favorites", `{"asset_id": "audience1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = post("/api/users/user1/favorites", `{"asset_id": "missing"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data []struct {
+			AssetID string `json:"asset_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Len(t, body.Data, 2)
+}
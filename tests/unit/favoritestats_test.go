@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/repository/sqlstore"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavoriteStats_Backends(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"), sqlstore.WithClock(fake))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+	boltRepo, err := embedded.Open(filepath.Join(t.TempDir(), "favorites.bolt"), embedded.WithClock(fake))
+	require.NoError(t, err)
+	t.Cleanup(func() { boltRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepositoryWithOptions(memory.Options{Clock: fake}),
+		"sqlite": sqliteRepo,
+		// Summarized from the loaded favorites
+		"embedded": boltRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")), name)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger(), service.WithClock(fake))
+
+		for _, added := range []struct {
+			asset domain.Asset
+			at    time.Time
+		}{
+			{domain.NewInsight("insight1", "Growth", "", nil, ""), time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)},
+			{domain.NewInsight("insight2", "Growth", "", nil, ""), time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+			{domain.NewAudience("audience1", ""), time.Date(2025, 3, 9, 23, 59, 0, 0, time.UTC)},
+			{domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil), time.Date(2025, 3, 11, 8, 0, 0, 0, time.UTC)},
+		} {
+			fake.Set(added.at)
+			require.NoError(t, favorites.AddFavorite(ctx, "user1", added.asset), name)
+		}
+
+		// A Wednesday; its week started on Monday 10 March
+		fake.Set(time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC))
+		stats, err := favorites.GetFavoriteStats(ctx, "user1", 3)
+		require.NoError(t, err, name)
+		assert.Equal(t, 4, stats.Total, name)
+		assert.Equal(t, map[domain.AssetType]int{
+			domain.AssetTypeChart:    1,
+			domain.AssetTypeInsight:  2,
+			domain.AssetTypeAudience: 1,
+		}, stats.ByType, name)
+		require.Len(t, stats.Weekly, 3, name)
+		for i, want := range []struct {
+			start string
+			added int
+		}{{"2025-02-24", 0}, {"2025-03-03", 2}, {"2025-03-10", 1}} {
+			assert.Equal(t, want.start, stats.Weekly[i].Start.Format(time.DateOnly), name)
+			assert.Equal(t, want.added, stats.Weekly[i].Added, "%s: week of %s", name, want.start)
+		}
+
+		_, err = favorites.GetFavoriteStats(ctx, "ghost", 3)
+		assert.ErrorIs(t, err, domain.ErrUserNotFound, name)
+	}
+}
+
+func TestHandler_FavoriteStats(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	routes := handler.NewHandler(favorites, log).SetupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/users/user1/favorites/stats")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data repository.FavoriteStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.Total)
+	assert.Equal(t, 1, response.Data.ByType[domain.AssetTypeInsight])
+	assert.Zero(t, response.Data.ByType[domain.AssetTypeChart])
+	require.Len(t, response.Data.Weekly, 12)
+	assert.Equal(t, 1, response.Data.Weekly[11].Added)
+
+	assert.Equal(t, http.StatusOK, get("/api/users/user1/favorites/stats?weeks=52").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/stats?weeks=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/stats?weeks=53").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/users/ghost/favorites/stats").Code)
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/moderation"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeaturedAssets(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	store := moderation.NewStore(1)
+	assets := service.NewAssetService(repo, service.DeleteCascade, logger.NewLogger(),
+		service.WithAssetClock(fake), service.WithAssetModeration(store))
+
+	for _, asset := range []domain.Asset{
+		domain.NewInsight("insight1", "40% of people stream music daily", "Music", nil, "entertainment"),
+		domain.NewAudience("audience1", "Gamers"),
+		domain.NewAudience("audience2", "Travellers"),
+	} {
+		require.NoError(t, assets.CreateAsset(ctx, asset))
+	}
+
+	first, err := assets.FeatureAsset(ctx, "insight1")
+	require.NoError(t, err)
+	fake.Advance(time.Minute)
+	_, err = assets.FeatureAsset(ctx, "audience1")
+	require.NoError(t, err)
+	fake.Advance(time.Minute)
+	_, err = assets.FeatureAsset(ctx, "audience2")
+	require.NoError(t, err)
+
+	// Featuring again keeps the original time
+	again, err := assets.FeatureAsset(ctx, "insight1")
+	require.NoError(t, err)
+	assert.Equal(t, first.FeaturedAt, again.FeaturedAt)
+
+	_, err = assets.FeatureAsset(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+
+	featuredIDs := func() []string {
+		featured, err := assets.FeaturedAssets(ctx)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(featured))
+		for _, f := range featured {
+			ids = append(ids, f.Asset.GetID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"audience2", "audience1", "insight1"}, featuredIDs())
+
+	// Deleted assets drop out; hidden ones are left out until restored
+	require.NoError(t, assets.DeleteAsset(ctx, "audience2"))
+	_, err = store.Add(moderation.Report{AssetID: "audience1", ReporterID: "user2", Reason: moderation.ReasonSpam})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"insight1"}, featuredIDs())
+	assert.False(t, assets.IsFeatured("audience2"))
+	assert.True(t, assets.IsFeatured("audience1"))
+
+	require.NoError(t, assets.UnfeatureAsset(ctx, "insight1"))
+	assert.ErrorIs(t, assets.UnfeatureAsset(ctx, "insight1"), domain.ErrAssetNotFeatured)
+}
+
+func TestFeaturedAssets_Routes(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	assets := service.NewAssetService(repo, service.DeleteCascade, log)
+	require.NoError(t, assets.CreateAsset(context.Background(), domain.NewAudience("audience1", "Gamers")))
+
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAssetService(assets), handler.WithAdminAPIKey(testAdminKey)).SetupRoutes())
+	send := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/api/admin/assets/audience1/featured").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/api/admin/assets/missing/featured").Code)
+
+	rec := send(http.MethodGet, "/api/assets/featured")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"audience1"`)
+	assert.Contains(t, rec.Body.String(), `"featured_at"`)
+
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/admin/assets/audience1/featured").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/api/admin/assets/audience1/featured").Code)
+	assert.JSONEq(t, `{"success":true,"data":[]}`, send(http.MethodGet, "/api/assets/featured").Body.String())
+}
@@ -0,0 +1,196 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/catalog"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCatalog serves chart1 at a version bumped with each title change
+type fakeCatalog struct {
+	mu      sync.Mutex
+	title   string
+	version int
+	down    bool
+	fetches int
+}
+
+func (c *fakeCatalog) setTitle(title string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.title = title
+	c.version++
+}
+
+func (c *fakeCatalog) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *fakeCatalog) fetched() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches
+}
+
+func (c *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetches++
+	switch {
+	case c.down:
+		w.WriteHeader(http.StatusBadGateway)
+	case r.URL.Path != "/assets/chart1":
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, c.version))
+		json.NewEncoder(w).Encode(domain.NewChart("chart1", c.title, "Month", "Revenue", "", nil))
+	}
+}
+
+func newCatalogAssets(t *testing.T) (*fakeCatalog, *memory.Repository, *service.AssetService, *clock.Fake) {
+	upstream := &fakeCatalog{}
+	upstream.setTitle("Sales")
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+
+	repo := memory.NewRepository()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	source := catalog.NewHTTPSource(httpclient.New(httpclient.Config{Timeout: time.Second}), server.URL, "")
+	assets := service.NewAssetService(repo, service.DeleteCascade, logger.NewLogger(),
+		service.WithAssetSource(source),
+		service.WithAssetClock(fake),
+	)
+	return upstream, repo, assets, fake
+}
+
+func TestAssetService_SyncAsset(t *testing.T) {
+	upstream, repo, assets, fake := newCatalogAssets(t)
+	ctx := context.Background()
+
+	asset, err := assets.SyncAsset(ctx, "chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", asset.GetSourceVersion())
+	assert.True(t, fake.Now().Equal(*asset.GetLastSyncedAt()))
+
+	stored, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", stored.GetSourceVersion())
+
+	upstream.setTitle("Sales 2025")
+	asset, err = assets.SyncAsset(ctx, "chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "Sales 2025", asset.(*domain.Chart).Title)
+	assert.Equal(t, "v2", asset.GetSourceVersion())
+
+	_, err = assets.SyncAsset(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+	upstream.setDown(true)
+	_, err = assets.SyncAsset(ctx, "chart1")
+	assert.ErrorIs(t, err, domain.ErrCatalogUnavailable)
+
+	// Without a catalog there is nothing to sync from
+	plain := service.NewAssetService(repo, service.DeleteCascade, logger.NewLogger())
+	_, err = plain.SyncAsset(ctx, "chart1")
+	assert.ErrorIs(t, err, domain.ErrNotSupported)
+}
+
+func TestHandler_MinFreshness(t *testing.T) {
+	upstream, repo, assets, fake := newCatalogAssets(t)
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	local := domain.NewAudience("audience1", "Created locally")
+	require.NoError(t, repo.CreateAsset(local))
+	require.NoError(t, repo.AddFavorite("user1", local))
+
+	log := logger.NewLogger()
+	h := handler.NewHandler(service.NewFavoritesService(repo, log), log, handler.WithAssetService(assets), handler.WithAdminAPIKey(testAdminKey))
+	assert.Contains(t, h.Capabilities().Features, "asset_freshness")
+	routes := asAdmin(h.SetupRoutes())
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	type listing struct {
+		Data []struct {
+			AssetID string `json:"asset_id"`
+			Asset   struct {
+				Title         string `json:"title"`
+				SourceVersion string `json:"source_version"`
+				LastSyncedAt  string `json:"last_synced_at"`
+			} `json:"asset"`
+		} `json:"data"`
+		Warnings []string `json:"warnings"`
+	}
+	list := func(query string) listing {
+		rec := send(http.MethodGet, "/api/users/user1/favorites?sort=type&"+query)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body listing
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data, 2)
+		return body
+	}
+
+	rec := send(http.MethodPost, "/api/admin/assets/chart1/sync")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	chart, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddFavorite("user1", chart))
+
+	body := list("")
+	assert.Equal(t, "v1", body.Data[1].Asset.SourceVersion)
+	assert.NotEmpty(t, body.Data[1].Asset.LastSyncedAt)
+	assert.Empty(t, body.Data[0].Asset.SourceVersion, "assets created locally were never synced")
+
+	// Data within the requested freshness is served as stored
+	upstream.setTitle("Sales 2025")
+	fake.Advance(20 * time.Minute)
+	fetches := upstream.fetched()
+	assert.Equal(t, "Sales", list("min_freshness=30m").Data[1].Asset.Title)
+	assert.Equal(t, fetches, upstream.fetched())
+
+	// Older data is refreshed inline
+	body = list("min_freshness=10m")
+	assert.Equal(t, "Sales 2025", body.Data[1].Asset.Title)
+	assert.Equal(t, "v2", body.Data[1].Asset.SourceVersion)
+	assert.Empty(t, body.Warnings)
+	assert.Equal(t, fetches+1, upstream.fetched(), "only the synced asset is refreshed")
+
+	// A failed refresh serves the stored asset with a warning
+	upstream.setDown(true)
+	fake.Advance(time.Hour)
+	body = list("min_freshness=10m")
+	assert.Equal(t, "Sales 2025", body.Data[1].Asset.Title)
+	require.Len(t, body.Warnings, 1)
+	assert.Contains(t, body.Warnings[0], "chart1")
+
+	upstream.setDown(false)
+	upstream.setTitle("Sales 2026")
+	rec = send(http.MethodGet, "/api/admin/assets/chart1?min_freshness=1m")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "Sales 2026")
+
+	rec = send(http.MethodGet, "/api/users/user1/favorites?min_freshness=soon")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = send(http.MethodPost, "/api/admin/assets/missing/sync")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
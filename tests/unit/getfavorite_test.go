@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFavorite_Backends(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+	boltRepo, err := embedded.Open(filepath.Join(t.TempDir(), "favorites.bolt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { boltRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepository(),
+		"sqlite": sqliteRepo,
+		// Found by scanning the user's favorites
+		"embedded": boltRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")), name)
+		require.NoError(t, repo.CreateUser(domain.NewUser("user2", "", "")), name)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+		require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")), name)
+		require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewAudience("audience1", "")), name)
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "Read later"), name)
+
+		favorite, err := favorites.GetFavorite(ctx, "user1", "insight1")
+		require.NoError(t, err, name)
+		assert.Equal(t, "user1", favorite.UserID, name)
+		assert.Equal(t, "insight1", favorite.AssetID, name)
+		assert.Equal(t, "Read later", favorite.Note, name)
+		assert.Equal(t, domain.AssetTypeInsight, favorite.Asset.GetType(), name)
+		assert.False(t, favorite.AddedAt.IsZero(), name)
+
+		_, err = favorites.GetFavorite(ctx, "user2", "insight1")
+		assert.ErrorIs(t, err, domain.ErrFavoriteNotFound, name)
+		_, err = favorites.GetFavorite(ctx, "ghost", "insight1")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound, name)
+	}
+}
+
+func TestHandler_GetFavorite(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "Read later"))
+	routes := handler.NewHandler(favorites, log).SetupRoutes()
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/users/user1/favorites/insight1", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data struct {
+			AssetID string          `json:"asset_id"`
+			Note    string          `json:"note"`
+			Asset   json.RawMessage `json:"asset"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "insight1", response.Data.AssetID)
+	assert.Equal(t, "Read later", response.Data.Note)
+	assert.Contains(t, string(response.Data.Asset), `"Growth"`)
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, get("/api/users/user1/favorites/insight1", map[string]string{"If-None-Match": etag}).Code)
+
+	rec = get("/api/users/user1/favorites/insight1", map[string]string{"Accept": "application/vnd.api+json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var document struct {
+		Data struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		} `json:"data"`
+		Included []struct {
+			Type string `json:"type"`
+		} `json:"included"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	assert.Equal(t, "favorites", document.Data.Type)
+	assert.Equal(t, "user1:insight1", document.Data.ID)
+	require.Len(t, document.Included, 1)
+	assert.Equal(t, "insights", document.Included[0].Type)
+
+	assert.Equal(t, http.StatusNotFound, get("/api/users/user1/favorites/audience1", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/users/ghost/favorites/insight1", nil).Code)
+}
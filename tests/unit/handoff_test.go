@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/pkg/handoff"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandoffSuccessor is the successor process started by TestHandoff; it
+// is skipped in normal runs
+func TestHandoffSuccessor(t *testing.T) {
+	if os.Getenv("HANDOFF_LISTENERS") == "" {
+		t.Skip("only run as a handoff successor")
+	}
+
+	listeners, err := handoff.New()
+	require.NoError(t, err)
+	require.True(t, listeners.Inherited())
+	require.NoError(t, listeners.Ready())
+	require.NoError(t, listeners.WaitRelease(context.Background()))
+
+	listener, err := listeners.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "successor")
+		if r.URL.Path == "/exit" {
+			close(done)
+		}
+	})}
+	go server.Serve(listener)
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+	}
+	server.Close()
+}
+
+func TestHandoff(t *testing.T) {
+	if os.Getenv("HANDOFF_LISTENERS") != "" {
+		t.Skip("running as a handoff successor")
+	}
+
+	listeners, err := handoff.New()
+	require.NoError(t, err)
+	assert.False(t, listeners.Inherited())
+	listener, err := listeners.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "predecessor")
+	})}
+	go server.Serve(listener)
+
+	get := func(path string) string {
+		client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get(url + path)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+	assert.Equal(t, "predecessor", get("/"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A successor that never reports ready fails the restart; this test
+	// skips itself in a successor
+	listeners.Argv = []string{os.Args[0], "-test.run=^TestHandoff$"}
+	_, err = listeners.Restart(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, "predecessor", get("/"), "a failed restart keeps serving")
+
+	listeners.Argv = []string{os.Args[0], "-test.run=^TestHandoffSuccessor$"}
+	successor, err := listeners.Restart(ctx)
+	require.NoError(t, err)
+
+	// Connections made between shutdown and release wait for the successor
+	require.NoError(t, server.Shutdown(ctx))
+	released := make(chan string)
+	go func() { released <- get("/") }()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, successor.Release())
+
+	assert.Equal(t, "successor", <-released)
+	assert.Equal(t, "successor", get("/exit"))
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/health"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthScore_NoFactorsIsHealthy(t *testing.T) {
+	report := health.NewScorer(nil).Score(context.Background())
+	assert.Equal(t, 100, report.Score)
+	assert.Equal(t, health.StatusHealthy, report.Status)
+	assert.Empty(t, report.Factors)
+}
+
+func TestHealthScore_WeightsFactorsAndSortsWorstFirst(t *testing.T) {
+	scorer := health.NewScorer(nil)
+	scorer.Add(3, health.QueueBacklog("full", func() int { return 200 }, 100))
+	scorer.Add(1, health.QueueBacklog("empty", func() int { return 0 }, 100))
+
+	report := scorer.Score(context.Background())
+	assert.Equal(t, 25, report.Score)
+	assert.Equal(t, health.StatusUnhealthy, report.Status)
+	require.Len(t, report.Factors, 2)
+	assert.Equal(t, "full", report.Factors[0].Name)
+	assert.Zero(t, report.Factors[0].Score, "scores are clamped to [0, 1]")
+	assert.Equal(t, 3.0, report.Factors[0].Weight)
+}
+
+func TestHealthScore_ErrorRateWindow(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	window := health.NewRequestWindow(time.Minute, fake)
+	check := health.ErrorRate("api", window, 0.1)
+
+	for i := 0; i < 19; i++ {
+		window.Record(http.StatusOK)
+	}
+	window.Record(http.StatusServiceUnavailable)
+	window.Record(http.StatusNotFound)
+
+	factor, ok := check(context.Background())
+	require.True(t, ok)
+	assert.InDelta(t, 1.0/21, factor.Value, 1e-9)
+	assert.InDelta(t, 1-(1.0/21)/0.1, factor.Score, 1e-9)
+
+	fake.Advance(2 * time.Minute)
+	factor, _ = check(context.Background())
+	assert.Equal(t, 1.0, factor.Score, "errors outside the window no longer count")
+	assert.Zero(t, factor.Value)
+}
+
+func TestHealthScore_CacheHitRate(t *testing.T) {
+	registry := metrics.NewRegistry()
+	check := health.CacheHitRate("cache", registry, "hits_total", "misses_total", 0.8)
+
+	_, ok := check(context.Background())
+	assert.False(t, ok, "no factor without the cache metrics")
+
+	hits := registry.Counter("hits_total", "", nil)
+	misses := registry.Counter("misses_total", "", nil)
+	hits.Add(90)
+	misses.Add(10)
+	factor, ok := check(context.Background())
+	require.True(t, ok)
+	assert.InDelta(t, 0.9, factor.Value, 1e-9)
+
+	// Only lookups since the previous evaluation count
+	hits.Add(2)
+	misses.Add(8)
+	factor, _ = check(context.Background())
+	assert.InDelta(t, 0.2, factor.Value, 1e-9)
+	assert.InDelta(t, 0.25, factor.Score, 1e-9)
+}
+
+func TestHealthScore_Latency(t *testing.T) {
+	ok := health.Latency("storage", func(context.Context) error { return nil }, time.Second)
+	factor, applies := ok(context.Background())
+	require.True(t, applies)
+	assert.Equal(t, 1.0, factor.Score)
+
+	failing := health.Latency("storage", func(context.Context) error { return errors.New("connection refused") }, time.Second)
+	factor, _ = failing(context.Background())
+	assert.Equal(t, 0.0, factor.Score)
+	assert.Equal(t, "connection refused", factor.Detail)
+
+	unsupported := health.Latency("storage", func(context.Context) error { return domain.ErrNotSupported }, time.Second)
+	_, applies = unsupported(context.Background())
+	assert.False(t, applies)
+}
+
+func TestGetHealthScore(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	window := health.NewRequestWindow(time.Minute, nil)
+	scorer := health.NewScorer(nil)
+	scorer.Add(1, health.ErrorRate("api", window, 0.5))
+
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithHealthScore(scorer, window), handler.WithAdminAPIKey(testAdminKey)).SetupRoutes())
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/nobody/favorites", nil))
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/health/score", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Data health.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, health.StatusHealthy, resp.Data.Status)
+	require.Len(t, resp.Data.Factors, 1)
+	assert.Equal(t, health.KindErrorRate, resp.Data.Factors[0].Kind)
+	assert.Contains(t, resp.Data.Factors[0].Detail, "of 1 requests")
+}
@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClientConfig() httpclient.Config {
+	cfg := httpclient.DefaultConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	return cfg
+}
+
+func TestHTTPClient_RetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := httpclient.New(testClientConfig()).Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPClient_DoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	require.NoError(t, err)
+
+	resp, err := httpclient.New(testClientConfig()).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPClient_PropagatesTrace(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+	}))
+	defer server.Close()
+
+	span := tracing.NewSpanContext(true)
+	ctx := tracing.ContextWithSpan(context.Background(), span)
+
+	resp, err := httpclient.New(testClientConfig()).Get(ctx, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	propagated, ok := tracing.ParseTraceParent(traceParent)
+	require.True(t, ok)
+	assert.Equal(t, span.TraceID, propagated.TraceID)
+	assert.True(t, propagated.Sampled)
+}
+
+func TestHTTPClient_PoolsPerConfig(t *testing.T) {
+	cfg := testClientConfig()
+	small := httpclient.New(cfg).HTTPClient().Transport.(*http.Transport)
+	assert.Same(t, small, httpclient.New(cfg).HTTPClient().Transport, "clients share a pool")
+
+	cfg.MaxIdleConnsPerHost = 64
+	large := httpclient.New(cfg).HTTPClient().Transport.(*http.Transport)
+	assert.NotSame(t, small, large)
+	assert.Equal(t, 64, large.MaxIdleConnsPerHost)
+}
+
+func TestHTTPClient_DialControlVetsConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := testClientConfig()
+	cfg.MaxRetries = 0
+	var dialed string
+	cfg.DialControl = func(network, address string, _ syscall.RawConn) error {
+		dialed = address
+		return errors.New("refused")
+	}
+	client := httpclient.New(cfg)
+	assert.NotSame(t, httpclient.New(testClientConfig()).HTTPClient().Transport, client.HTTPClient().Transport,
+		"vetted clients get their own pool")
+
+	_, err := client.Get(context.Background(), server.URL)
+	require.Error(t, err)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), dialed)
+}
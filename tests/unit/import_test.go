@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFavorites(t *testing.T) {
+	repo := memory.NewRepository()
+	for _, id := range []string{"user1", "user2", "user3"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(id, "", "")))
+	}
+	require.NoError(t, repo.CreateAsset(domain.NewAudience("catalog1", "From the catalog")))
+
+	log := logger.NewLogger()
+	favorites := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Already there", "", nil, "")))
+
+	routes := handler.NewHandler(favorites, log).SetupRoutes()
+	upload := func(path, filename, content string) (*httptest.ResponseRecorder, service.ImportReport) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, path, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+
+		var response struct {
+			Data service.ImportReport `json:"data"`
+		}
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec, response.Data
+	}
+
+	t.Run("json", func(t *testing.T) {
+		rec, report := upload("/api/users/user1/favorites/import", "favorites.json", `[
+			{"asset_id": "chart1", "note": "Q3", "asset": {"id": "chart1", "type": "chart", "title": "Sales", "data": [{"x": 1, "y": 2}]}},
+			{"id": "audience1", "type": "audience", "description": "Gamers"},
+			{"asset_id": "catalog1"},
+			{"asset_id": "insight1", "asset": {"id": "insight1", "type": "insight", "content": "Already there"}},
+			{"asset_id": "missing"},
+			{"id": "bad1", "type": "video"}
+		]`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, 3, report.Created)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 2, report.Failed)
+		assert.Equal(t, []service.ImportResult{
+			{Row: 4, AssetID: "insight1", Status: service.ImportSkipped, Code: "favorite_already_exists"},
+			{Row: 5, AssetID: "missing", Status: service.ImportFailed, Code: "asset_not_found", Error: "asset not found"},
+			{Row: 6, Status: service.ImportFailed, Code: "invalid_asset_type", Error: "invalid asset type"},
+		}, report.Results)
+
+		imported, err := favorites.GetUserFavorites(ctx, "user1", 0, 0)
+		require.NoError(t, err)
+		notes := make(map[string]string)
+		for _, favorite := range imported {
+			notes[favorite.AssetID] = favorite.Note
+		}
+		assert.Equal(t, map[string]string{"insight1": "", "chart1": "Q3", "audience1": "", "catalog1": ""}, notes)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rec, report := upload("/api/users/user2/favorites/import", "legacy.csv",
+			"asset_id,type,note,content,tags,age_groups,purchases_last_month,legacy_column\n"+
+				"insight9,insight,,'=SUM(A1),a; b,,,x\n"+
+				"audience9,audience,Young,,,18-24; 25-34,3,x\n"+
+				"catalog1,,From legacy,,,,,x\n"+
+				"audience10,audience,,,,,many,x\n")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, 3, report.Created)
+		assert.Equal(t, 1, report.Failed)
+
+		insight, err := repo.GetAsset("insight9")
+		require.NoError(t, err)
+		assert.Equal(t, "=SUM(A1)", insight.(*domain.Insight).Content, "export escaping is undone")
+		assert.Equal(t, []string{"a", "b"}, insight.(*domain.Insight).Tags)
+		audience, err := repo.GetAsset("audience9")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AgeGroup{"18-24", "25-34"}, audience.(*domain.Audience).AgeGroups)
+		assert.Equal(t, 3, audience.(*domain.Audience).PurchasesLastMonth)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		exported := httptest.NewRecorder()
+		routes.ServeHTTP(exported, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites/export?format=csv", nil))
+		require.Equal(t, http.StatusOK, exported.Code)
+
+		rec, report := upload("/api/users/user3/favorites/import", "favorites.csv", exported.Body.String())
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, 4, report.Created, report.Results)
+		count, err := repo.GetFavoriteCount("user3")
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		rec, _ := upload("/api/users/user1/favorites/import", "favorites.txt", "[]")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "unknown format")
+		rec, _ = upload("/api/users/user1/favorites/import?format=json", "favorites.txt", "not json")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec, _ = upload("/api/users/user1/favorites/import", "favorites.csv", "type,note\nchart,x\n")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "asset_id column required")
+
+		rec = httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/user1/favorites/import", bytes.NewReader([]byte(`[]`))))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "a multipart upload is required")
+	})
+}
@@ -0,0 +1,130 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrity_SQLite(t *testing.T) {
+	repo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	ctx := context.Background()
+	log := logger.NewLogger()
+
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight2", "Churn", "", nil, "")))
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight2", "gone soon"))
+
+	storage := service.NewStorageService(repo, log)
+	report, err := storage.CheckIntegrity(ctx, false)
+	require.NoError(t, err)
+	assert.Zero(t, report.Issues())
+	assert.Equal(t, 2, report.AssetsChecked)
+	assert.Equal(t, 2, report.FavoritesChecked)
+
+	// As left by a bulk load with foreign keys disabled
+	db := repo.DB()
+	_, err = db.Exec(`PRAGMA foreign_keys = OFF`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM assets WHERE id = 'insight2'`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO assets (id, type, data, created_at, updated_at) VALUES ('video1', 'video', '{}', ?, ?)`, time.Now(), time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec(`PRAGMA foreign_keys = ON`)
+	require.NoError(t, err)
+
+	report, err = storage.CheckIntegrity(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.FavoriteRef{{UserID: "user1", AssetID: "insight2"}}, report.MissingAssets)
+	assert.Equal(t, 1, report.MissingAssetCount)
+	require.Len(t, report.InvalidAssets, 1)
+	assert.Equal(t, "video1", report.InvalidAssets[0].AssetID)
+	assert.Empty(t, report.CounterDrift)
+	assert.Zero(t, report.Repaired)
+
+	report, err = storage.CheckIntegrity(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Repaired)
+	var notes int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM favorite_notes`).Scan(&notes))
+	assert.Zero(t, notes, "the removed favorite's note goes with it")
+
+	report, err = storage.CheckIntegrity(ctx, false)
+	require.NoError(t, err)
+	assert.Zero(t, report.MissingAssetCount)
+	assert.Equal(t, 1, report.InvalidAssetCount, "invalid assets are left alone")
+}
+
+func TestHandler_StorageIntegrity(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	require.NoError(t, service.NewFavoritesService(repo, log).AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	bogus := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+	bogus.Type = "video"
+	require.NoError(t, repo.CreateAsset(bogus))
+
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithStorageService(service.NewStorageService(repo, log)),
+		handler.WithAdminAPIKey(testAdminKey),
+	).SetupRoutes())
+	check := func(method string) repository.IntegrityReport {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, "/api/admin/storage/integrity", nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response struct {
+			Data repository.IntegrityReport `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	report := check(http.MethodGet)
+	assert.Equal(t, 2, report.AssetsChecked)
+	assert.Equal(t, 1, report.FavoritesChecked)
+	assert.Equal(t, []repository.InvalidAsset{{AssetID: "chart1", Reason: `unknown asset type "video"`}}, report.InvalidAssets)
+	assert.Empty(t, report.MissingAssets)
+	assert.Empty(t, report.CounterDrift)
+
+	report = check(http.MethodPost)
+	assert.Zero(t, report.Repaired)
+	_, err := repo.GetAsset("chart1")
+	assert.NoError(t, err, "invalid assets are only reported")
+}
+
+func TestIntegrityReport_Merge(t *testing.T) {
+	shard := repository.NewIntegrityReport()
+	for i := 0; i < repository.MaxIntegrityFindings+5; i++ {
+		shard.AddMissingAsset("user1", "chart1")
+	}
+	shard.AddCounterDrift("favorites", 3, 2)
+
+	total := repository.NewIntegrityReport()
+	total.Merge(shard, "0:")
+	total.Merge(shard, "1:")
+	assert.Equal(t, 2*(repository.MaxIntegrityFindings+5), total.MissingAssetCount)
+	assert.Len(t, total.MissingAssets, repository.MaxIntegrityFindings)
+	assert.Equal(t, []repository.CounterDrift{
+		{Counter: "0:favorites", Stored: 3, Actual: 2},
+		{Counter: "1:favorites", Stored: 3, Actual: 2},
+	}, total.CounterDrift)
+}
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/kpi"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKPITracker_DailyFigures(t *testing.T) {
+	c := clock.NewFake(time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC))
+	tracker := kpi.NewTracker(c)
+	tracker.Resync(10)
+
+	tracker.FavoriteAdded("user1")
+	tracker.FavoriteAdded("user1")
+	tracker.FavoriteRemoved("user2")
+	tracker.FavoriteEdited("user3")
+	assert.Equal(t, kpi.Snapshot{Favorites: 11, ActiveUsers: 3, AddedToday: 2}, tracker.Snapshot())
+
+	// Daily figures restart at midnight UTC; the total carries over
+	c.Advance(2 * time.Hour)
+	assert.Equal(t, kpi.Snapshot{Favorites: 11}, tracker.Snapshot())
+	tracker.FavoriteAdded("user2")
+	assert.Equal(t, kpi.Snapshot{Favorites: 12, ActiveUsers: 1, AddedToday: 1}, tracker.Snapshot())
+
+	tracker.Resync(4)
+	assert.Equal(t, 4, tracker.Snapshot().Favorites)
+
+	var none *kpi.Tracker
+	none.FavoriteAdded("user1")
+}
+
+func TestKPITracker_Register(t *testing.T) {
+	registry := metrics.NewRegistry()
+	kpi.NewTracker(nil).Register(registry)
+	_, ok := registry.Value("business_favorites", nil)
+	assert.False(t, ok, "total is unknown without a resync")
+	_, ok = registry.Value("business_favorites_added_today", nil)
+	assert.True(t, ok)
+
+	registry = metrics.NewRegistry()
+	tracker := kpi.NewTracker(nil)
+	tracker.Resync(7)
+	tracker.Register(registry)
+	tracker.FavoriteAdded("user1")
+	total, ok := registry.Value("business_favorites", nil)
+	require.True(t, ok)
+	assert.Equal(t, 8.0, total)
+}
+
+func TestKPITracker_FedByFavoritesService(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	tracker := kpi.NewTracker(nil)
+	tracker.Resync(0)
+	favorites := service.NewFavoritesService(repo, logger.NewLogger(), service.WithKPITracker(tracker))
+	ctx := context.Background()
+
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewAudience("audience1", "Sales leads")))
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "chart1"))
+
+	// Failed changes are not counted
+	assert.Error(t, favorites.RemoveFavorite(ctx, "user1", "chart1"))
+	assert.Equal(t, kpi.Snapshot{Favorites: 1, ActiveUsers: 1, AddedToday: 2}, tracker.Snapshot())
+}
+
+func TestKPITracker_StartResync(t *testing.T) {
+	tracker := kpi.NewTracker(nil)
+	counted := make(chan struct{}, 1)
+	stop := tracker.StartResync(time.Millisecond, func() (int, error) {
+		select {
+		case counted <- struct{}{}:
+		default:
+		}
+		return 42, nil
+	}, func(error) {})
+	<-counted
+	stop()
+
+	assert.Eventually(t, func() bool { return tracker.Snapshot().Favorites == 42 }, time.Second, time.Millisecond)
+}
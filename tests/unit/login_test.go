@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_LoginAndRefresh(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+
+	credentials := auth.NewCredentials(map[string]string{"ops": "ops-pass"}, map[string]string{"ops": "admin"}, "dev-pass")
+	issuer := auth.NewIssuer("secret", time.Minute, time.Hour)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithAuthService(service.NewAuthService(repo, credentials, issuer, log)),
+		handler.WithStorageService(service.NewStorageService(repo, log)),
+	).SetupRoutes()
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+	tokens := func(rec *httptest.ResponseRecorder) auth.TokenPair {
+		var body struct {
+			Data auth.TokenPair `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Data
+	}
+
+	// Stored users log in with the default password; unknown users cannot
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/auth/login", "", `{"username": "user1", "password": "wrong"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/auth/login", "", `{"username": "ghost", "password": "dev-pass"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/api/auth/login", "", `{"username": "user1"}`).Code)
+
+	rec := send(http.MethodPost, "/api/auth/login", "", `{"username": "user1", "password": "dev-pass"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	pair := tokens(rec)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, int64(60), pair.ExpiresIn)
+
+	// The access token works on the user's own routes; the refresh token does not
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/users/user1/favorites", pair.AccessToken, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/users/user1/favorites", pair.RefreshToken, "").Code)
+
+	// Refresh tokens are single use
+	refreshed := send(http.MethodPost, "/api/auth/refresh", "", `{"refresh_token": "`+pair.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, refreshed.Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/users/user1/favorites", tokens(refreshed).AccessToken, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/auth/refresh", "", `{"refresh_token": "`+pair.RefreshToken+`"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/auth/refresh", "", `{"refresh_token": "`+pair.AccessToken+`"}`).Code)
+
+	// Listed users get their configured role
+	admin := tokens(send(http.MethodPost, "/api/auth/login", "", `{"username": "ops", "password": "ops-pass"}`))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/admin/storage/stats", admin.AccessToken, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/auth/login", "", `{"username": "ops", "password": "dev-pass"}`).Code)
+}
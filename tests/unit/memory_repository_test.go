@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository_EvictsLeastRecentlyUsedAsset(t *testing.T) {
+	repo := memory.NewRepositoryWithOptions(memory.Options{MaxAssets: 2})
+
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)))
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart2", "Chart 2", "X", "Y", "", nil)))
+
+	// Reading chart1 makes chart2 the least recently used
+	_, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart3", "Chart 3", "X", "Y", "", nil)))
+
+	_, err = repo.GetAsset("chart2")
+	assert.Equal(t, domain.ErrAssetNotFound, err)
+	_, err = repo.GetAsset("chart1")
+	assert.NoError(t, err)
+
+	usage := repo.Usage()
+	assert.Equal(t, 2, usage.Assets)
+	assert.Equal(t, uint64(1), usage.Evictions)
+}
+
+func TestMemoryRepository_FavoritesBudget(t *testing.T) {
+	repo := memory.NewRepositoryWithOptions(memory.Options{MaxFavorites: 1})
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+
+	// The catalog asset nobody favorited is the least recently used
+	require.NoError(t, repo.CreateAsset(domain.NewAudience("audience1", "Gamers")))
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)
+	insight := domain.NewInsight("insight1", "Insight", "", nil, "")
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.CreateAsset(insight))
+
+	require.NoError(t, repo.AddFavorite("user1", chart))
+
+	// Adding a second favorite evicts the least recently used favorited
+	// asset and its favorite; evicting the others would free nothing
+	require.NoError(t, repo.AddFavorite("user1", insight))
+	_, err := repo.GetAsset("audience1")
+	assert.NoError(t, err)
+	_, err = repo.GetAsset("chart1")
+	assert.Equal(t, domain.ErrAssetNotFound, err)
+	assert.Equal(t, uint64(1), repo.Usage().Evictions)
+
+	count, err := repo.GetFavoriteCount("user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	isFavorite, err := repo.IsFavorite("user1", "insight1")
+	require.NoError(t, err)
+	assert.True(t, isFavorite)
+}
+
+func TestMemoryRepository_EvictionSkipsUnfavoritedAssets(t *testing.T) {
+	repo := memory.NewRepositoryWithOptions(memory.Options{MaxFavorites: 1})
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)
+	insight := domain.NewInsight("insight1", "Insight", "", nil, "")
+	audience := domain.NewAudience("audience1", "Gamers")
+	for _, asset := range []domain.Asset{chart, insight, audience} {
+		require.NoError(t, repo.CreateAsset(asset))
+	}
+
+	// chart1 is the least recently used, but nobody favorites it any more
+	require.NoError(t, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.RemoveFavorite("user1", "chart1"))
+	require.NoError(t, repo.AddFavorite("user1", insight))
+
+	require.NoError(t, repo.AddFavorite("user1", audience))
+	_, err := repo.GetAsset("chart1")
+	assert.NoError(t, err)
+	_, err = repo.GetAsset("insight1")
+	assert.Equal(t, domain.ErrAssetNotFound, err)
+}
+
+func TestMemoryRepository_CountsFavoritersPerAsset(t *testing.T) {
+	repo := memory.NewRepository()
+	for _, userID := range []string{"user1", "user2"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")))
+	}
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)
+	require.NoError(t, repo.CreateAsset(chart))
+
+	references := func() int {
+		count, err := repo.CountAssetReferences("chart1")
+		require.NoError(t, err)
+		return count
+	}
+
+	require.NoError(t, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.AddFavorite("user2", chart))
+	assert.Equal(t, 2, references())
+	require.NoError(t, repo.RemoveFavorite("user1", "chart1"))
+	assert.Equal(t, 1, references())
+
+	_, err := repo.TransferFavorites("user2", "user1", nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, references(), "moving keeps the count")
+	_, err = repo.TransferFavorites("user1", "user2", nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, references(), "copying adds to it")
+
+	require.NoError(t, repo.DeleteUser("user2"))
+	assert.Equal(t, 1, references())
+	require.NoError(t, repo.DeleteAsset("chart1"))
+	assert.Equal(t, 0, references())
+	assert.Equal(t, 0, repo.Usage().Favorites)
+}
+
+func TestMemoryRepository_SnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	repo := memory.NewRepository()
+
+	restored, err := repo.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.False(t, restored)
+
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "a@b.c", "User")))
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.AddFavorite("user1", chart))
+
+	stop := repo.StartSnapshots(path, time.Hour, nil)
+	require.NoError(t, stop())
+
+	reloaded := memory.NewRepository()
+	restored, err = reloaded.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.True(t, restored)
+
+	favorites, err := reloaded.GetUserFavorites("user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	assert.Equal(t, "Chart 1", favorites[0].Asset.(*domain.Chart).Title)
+	assert.Equal(t, 1, reloaded.Usage().Favorites)
+}
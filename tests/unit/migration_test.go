@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/migration"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/cache"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTarget fails adding favorites for one user, simulating a crash
+type failingTarget struct {
+	repository.FavoritesRepository
+	failUser string
+}
+
+func (f *failingTarget) AddFavorite(userID string, asset domain.Asset) error {
+	if userID == f.failUser {
+		return errors.New("connection reset")
+	}
+	return f.FavoritesRepository.AddFavorite(userID, asset)
+}
+
+func TestMigration_CopiesAndResumes(t *testing.T) {
+	source := memory.NewRepository()
+	for i := 1; i <= 3; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		require.NoError(t, source.CreateUser(domain.NewUser(userID, "", "")))
+		chart := domain.NewChart(fmt.Sprintf("chart%d", i), "Chart", "X", "Y", "", nil)
+		require.NoError(t, source.CreateAsset(chart))
+		require.NoError(t, source.AddFavorite(userID, chart))
+	}
+	// The memory backend keeps a copy of the asset per favorite, which SQL
+	// backends cannot represent, so this favorite fails verification
+	require.NoError(t, source.CreateUser(domain.NewUser("user4", "", "")))
+	require.NoError(t, source.AddFavorite("user4", domain.NewChart("chart1", "Chart", "X", "Y", "Mine", nil)))
+	// Catalog assets nobody favorited are copied too
+	require.NoError(t, source.CreateAsset(domain.NewInsight("insight1", "Growth", "", nil, "")))
+
+	dir := t.TempDir()
+	target, err := sqlite.Open(filepath.Join(dir, "target.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { target.Close() })
+	checkpoint := filepath.Join(dir, "checkpoint.json")
+
+	// The first run stops at user3, after user1 and user2 were committed
+	migrator, err := migration.New(source, &failingTarget{FavoritesRepository: target, failUser: "user3"},
+		migration.Options{BatchSize: 1, CheckpointPath: checkpoint})
+	require.NoError(t, err)
+	_, err = migrator.Run()
+	require.Error(t, err)
+
+	saved, err := migration.LoadCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, migration.PhaseUsers, saved.Phase)
+	assert.Equal(t, "user2", saved.LastUserID)
+	assert.Equal(t, 4, saved.Assets)
+	assert.Equal(t, 2, saved.Users)
+
+	// The second run resumes with user3
+	migrator, err = migration.New(source, target, migration.Options{BatchSize: 1, CheckpointPath: checkpoint})
+	require.NoError(t, err)
+	progress, err := migrator.Run()
+	require.NoError(t, err)
+	assert.Equal(t, migration.PhaseDone, progress.Phase)
+	assert.Equal(t, 4, progress.Users)
+	assert.Equal(t, 4, progress.Favorites)
+	assert.Equal(t, []string{"user4"}, progress.Mismatched)
+	assert.NotEqual(t, progress.SourceChecksum, progress.TargetChecksum)
+
+	_, err = target.GetAsset("insight1")
+	assert.NoError(t, err)
+	favorites, err := target.GetUserFavorites("user3", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+
+	// A finished run is not repeated
+	progress, err = migrator.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 4, progress.Users)
+
+	// Sources that cannot list users are refused
+	_, err = migration.New(&failingTarget{FavoritesRepository: source}, target, migration.Options{})
+	assert.ErrorIs(t, err, domain.ErrNotSupported)
+}
+
+func TestMigration_WarmsCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	backend := memory.NewRepository()
+	chart := domain.NewChart("chart1", "Chart", "X", "Y", "", nil)
+	require.NoError(t, backend.CreateAsset(chart))
+	for _, userID := range []string{"user1", "user2"} {
+		require.NoError(t, backend.CreateUser(domain.NewUser(userID, "", "")))
+		require.NoError(t, backend.AddFavorite(userID, chart))
+	}
+
+	cached, err := cache.Open(backend, cache.Options{Addr: server.Addr(), KeyPrefix: "test:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { cached.Close() })
+
+	migrator, err := migration.New(backend, cached, migration.Options{})
+	require.NoError(t, err)
+	query := domain.FavoritesQuery{Limit: 50}
+	progress, err := migrator.Warm(query)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.Users)
+	assert.Equal(t, cache.Stats{Misses: 2}, cached.Stats())
+
+	// Later reads are served from Redis
+	_, err = cached.GetUserFavorites("user1", query)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), cached.Stats().Hits)
+}
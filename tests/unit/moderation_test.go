@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/moderation"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationService_HidesAssetsPastThreshold(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	store := moderation.NewStore(2)
+	moderationSvc := service.NewModerationService(repo, store, log)
+	favoritesSvc := service.NewFavoritesService(repo, log, service.WithModeration(store))
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	chart := domain.NewChart("chart1", "Chart", "X", "Y", "", nil)
+	require.NoError(t, repo.CreateAsset(chart))
+
+	_, err := moderationSvc.ReportAsset(ctx, "missing", "user1", moderation.ReasonSpam, "")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+	_, err = moderationSvc.ReportAsset(ctx, "chart1", "user1", "bogus", "")
+	assert.Equal(t, domain.ErrInvalidInput, err)
+
+	hidden, err := moderationSvc.ReportAsset(ctx, "chart1", "user1", moderation.ReasonSpam, "")
+	require.NoError(t, err)
+	assert.False(t, hidden)
+
+	// The same reporter is only counted once
+	_, err = moderationSvc.ReportAsset(ctx, "chart1", "user1", moderation.ReasonAbuse, "")
+	assert.ErrorIs(t, err, domain.ErrAlreadyReported)
+
+	hidden, err = moderationSvc.ReportAsset(ctx, "chart1", "user2", moderation.ReasonAbuse, "")
+	require.NoError(t, err)
+	assert.True(t, hidden)
+	assert.ErrorIs(t, favoritesSvc.AddFavorite(ctx, "user1", chart), domain.ErrAssetHidden)
+
+	queue := moderationSvc.Queue(ctx)
+	require.Len(t, queue, 1)
+	assert.Equal(t, 2, len(queue[0].Reports))
+
+	// Restoring the asset clears the queue
+	require.NoError(t, moderationSvc.Resolve(ctx, "chart1", false))
+	assert.Empty(t, moderationSvc.Queue(ctx))
+	assert.NoError(t, favoritesSvc.AddFavorite(ctx, "user1", chart))
+}
+
+func TestHandler_ReportAssetCountsClientsWithoutAuth(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	store := moderation.NewStore(2)
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Chart", "X", "Y", "", nil)))
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithModerationService(service.NewModerationService(repo, store, log)),
+	).SetupRoutes()
+
+	report := func(remoteAddr, reporterID string) int {
+		body := `{"reporter_id": "` + reporterID + `", "reason": "spam"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/assets/chart1/report", bytes.NewBufferString(body))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Unverified reporter ids do not make a client count twice
+	assert.Equal(t, http.StatusAccepted, report("192.0.2.1:1234", "user1"))
+	assert.Equal(t, http.StatusConflict, report("192.0.2.1:5678", "user2"))
+	assert.Equal(t, http.StatusConflict, report("192.0.2.1:5678", ""))
+	assert.False(t, store.IsHidden("chart1"))
+
+	assert.Equal(t, http.StatusAccepted, report("192.0.2.2:1234", "user1"))
+	assert.True(t, store.IsHidden("chart1"))
+
+	_, err := store.Add(moderation.Report{AssetID: "chart1", Reason: moderation.ReasonSpam})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "anonymous reports are refused")
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetNormalize(t *testing.T) {
+	countries := []string{"US", "GR", "UK", "GR"}
+	audience := domain.NewAudience("audience1", "")
+	audience.Gender = []string{"Male", "Female", "Male"}
+	audience.BirthCountries = countries
+	audience.AgeGroups = []domain.AgeGroup{"25-34", "16-24"}
+	audience.Normalize()
+
+	assert.Equal(t, []string{"Female", "Male"}, audience.Gender)
+	assert.Equal(t, []string{"GR", "UK", "US"}, audience.BirthCountries)
+	assert.Equal(t, []domain.AgeGroup{"16-24", "25-34"}, audience.AgeGroups)
+	assert.Equal(t, []string{"US", "GR", "UK", "GR"}, countries, "the caller's slice is left alone")
+
+	insight := domain.NewInsight("insight1", "Growth", "", []string{"social", "gaming", "social"}, "")
+	insight.Normalize()
+	assert.Equal(t, []string{"gaming", "social"}, insight.Tags)
+
+	// Reordered audiences are stored byte for byte alike
+	reordered := domain.NewAudience("audience1", "")
+	reordered.Gender = []string{"Female", "Male"}
+	reordered.BirthCountries = []string{"UK", "US", "GR"}
+	reordered.AgeGroups = []domain.AgeGroup{"16-24", "25-34", "16-24"}
+	reordered.Normalize()
+	reordered.CreatedAt, reordered.UpdatedAt = audience.CreatedAt, audience.UpdatedAt
+	first, err := repository.EncodeAsset(audience)
+	require.NoError(t, err)
+	second, err := repository.EncodeAsset(reordered)
+	require.NoError(t, err)
+	assert.Equal(t, string(first), string(second))
+}
+
+func TestAssetNormalize_OnIngestion(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	ctx := context.Background()
+
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", []string{"b", "a", "b"}, "")))
+	stored, err := repo.GetAsset("insight1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, stored.(*domain.Insight).Tags)
+
+	assets := service.NewAssetService(repo, service.DeleteCascade, log)
+	audience := domain.NewAudience("audience1", "")
+	audience.Gender = []string{"Male", "Female"}
+	require.NoError(t, assets.CreateAsset(ctx, audience))
+	stored, err = repo.GetAsset("audience1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Female", "Male"}, stored.(*domain.Audience).Gender)
+}
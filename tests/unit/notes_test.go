@@ -0,0 +1,138 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/cache"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/redis"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noteOf(t *testing.T, repo repository.FavoritesRepository, userID, assetID string) string {
+	favorites, err := repo.GetUserFavorites(userID, domain.FavoritesQuery{})
+	require.NoError(t, err)
+	for _, favorite := range favorites {
+		if favorite.AssetID == assetID {
+			return favorite.Note
+		}
+	}
+	t.Fatalf("%s has no favorite %s", userID, assetID)
+	return ""
+}
+
+func TestFavoriteNotes_Backends(t *testing.T) {
+	dir := t.TempDir()
+	sqliteRepo, err := sqlite.Open(filepath.Join(dir, "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+	embeddedRepo, err := embedded.Open(filepath.Join(dir, "favorites.bolt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { embeddedRepo.Close() })
+	redisRepo, err := redis.Open(redis.Options{Addr: miniredis.RunT(t).Addr(), KeyPrefix: "test:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisRepo.Close() })
+	cacheRepo, err := cache.Open(memory.NewRepository(), cache.Options{Addr: miniredis.RunT(t).Addr(), KeyPrefix: "test:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { cacheRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory":   memory.NewRepository(),
+		"sqlite":   sqliteRepo,
+		"embedded": embeddedRepo,
+		"redis":    redisRepo,
+		"cache":    cacheRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "Shared description", nil)
+		require.NoError(t, repo.CreateAsset(chart), name)
+		for _, userID := range []string{"user1", "user2"} {
+			require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")), name)
+			require.NoError(t, repo.AddFavorite(userID, chart), name)
+		}
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+
+		// Cached listings are invalidated by the edit
+		assert.Empty(t, noteOf(t, repo, "user1", "chart1"), name)
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "chart1", "Check Q3"), name)
+		assert.Equal(t, "Check Q3", noteOf(t, repo, "user1", "chart1"), name)
+
+		// The note is the user's own; the shared asset is untouched
+		assert.Empty(t, noteOf(t, repo, "user2", "chart1"), name)
+		asset, err := repo.GetAsset("chart1")
+		require.NoError(t, err, name)
+		assert.Equal(t, "Shared description", asset.GetDescription(), name)
+
+		previous, err := repo.UpdateFavoriteNote("user1", "chart1", "")
+		require.NoError(t, err, name)
+		assert.Equal(t, "Check Q3", previous, name)
+		assert.Empty(t, noteOf(t, repo, "user1", "chart1"), name)
+
+		_, err = repo.UpdateFavoriteNote("user1", "missing", "note")
+		assert.ErrorIs(t, err, domain.ErrFavoriteNotFound, name)
+		_, err = repo.UpdateFavoriteNote("nobody", "chart1", "note")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound, name)
+
+		// Removing the favorite drops its note
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user2", "chart1", "Old note"), name)
+		require.NoError(t, repo.RemoveFavorite("user2", "chart1"), name)
+		require.NoError(t, repo.AddFavorite("user2", chart), name)
+		assert.Empty(t, noteOf(t, repo, "user2", "chart1"), name)
+	}
+}
+
+func TestFavoriteNotes_SurviveSnapshot(t *testing.T) {
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	_, err := repo.UpdateFavoriteNote("user1", "chart1", "Check Q3")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, repo.SaveSnapshot(path))
+	restored := memory.NewRepository()
+	loaded, err := restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.True(t, loaded)
+	assert.Equal(t, "Check Q3", noteOf(t, restored, "user1", "chart1"))
+	assert.Empty(t, noteOf(t, restored, "user1", "insight1"))
+}
+
+func TestHandler_UpdateFavoriteNote(t *testing.T) {
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, logger.NewLogger()), logger.NewLogger()).SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/users/user1/favorites/chart1", strings.NewReader(`{"note": "Check Q3"}`))
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites?type=chart", nil)
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"note":"Check Q3"`)
+	assert.Contains(t, rec.Body.String(), `"description":"Revenue by region"`)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/users/user1/favorites/missing", strings.NewReader(`{"note": "x"}`))
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,226 @@
+package unit
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/blobstore"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/offload"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/s3"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chartPoints(n int) []domain.ChartDataPoint {
+	points := make([]domain.ChartDataPoint, n)
+	for i := range points {
+		points[i] = domain.ChartDataPoint{X: fmt.Sprintf("2024-01-%02d", i%28+1), Y: float64(i)}
+	}
+	return points
+}
+
+func countFiles(t *testing.T, dir string) int {
+	count := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func TestOffloadRepository_MovesLargeChartDataToBlobs(t *testing.T) {
+	dir := t.TempDir()
+	blobs, err := blobstore.NewLocal(dir)
+	require.NoError(t, err)
+	backend := memory.NewRepository()
+	repo := offload.New(backend, blobs, offload.Options{Threshold: 512})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	favorites := service.NewFavoritesService(repo, logger)
+	assets := service.NewAssetService(repo, service.DeleteCascade, logger)
+	ctx := context.Background()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+
+	// Small charts stay inline
+	small := domain.NewChart("small", "Small", "X", "Y", "", chartPoints(3))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", small))
+	stored, err := backend.GetAsset("small")
+	require.NoError(t, err)
+	assert.Len(t, stored.(*domain.Chart).Data, 3)
+	assert.Nil(t, stored.(*domain.Chart).DataRef)
+	assert.Equal(t, 0, countFiles(t, dir))
+
+	// Large charts are stored with a reference in place of their data
+	large := domain.NewChart("large", "Large", "X", "Y", "", chartPoints(200))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", large))
+	assert.Len(t, large.Data, 200, "caller's asset is left untouched")
+
+	stored, err = backend.GetAsset("large")
+	require.NoError(t, err)
+	chart := stored.(*domain.Chart)
+	assert.Empty(t, chart.Data)
+	require.NotNil(t, chart.DataRef)
+	assert.Equal(t, 200, chart.DataRef.Points)
+	assert.Equal(t, 1, countFiles(t, dir))
+
+	// Listings carry the reference; the points are served separately
+	listed, err := favorites.GetUserFavorites(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	for _, favorite := range listed {
+		if favorite.AssetID == "large" {
+			assert.Empty(t, favorite.Asset.(*domain.Chart).Data)
+		}
+	}
+	points, err := favorites.GetFavoriteChartData(ctx, "user1", "large")
+	require.NoError(t, err)
+	assert.Len(t, points, 200)
+	points, err = favorites.GetFavoriteChartData(ctx, "user1", "small")
+	require.NoError(t, err)
+	assert.Len(t, points, 3)
+
+	// The catalog returns the full chart
+	asset, err := assets.GetAsset(ctx, "large")
+	require.NoError(t, err)
+	assert.Len(t, asset.(*domain.Chart).Data, 200)
+
+	// Writing unchanged data back keeps the blob; new data replaces it
+	require.NoError(t, assets.UpdateAsset(ctx, "large", asset))
+	assert.Equal(t, 1, countFiles(t, dir))
+	updated := domain.NewChart("large", "Large", "X", "Y", "", chartPoints(300))
+	require.NoError(t, assets.UpdateAsset(ctx, "large", updated))
+	assert.Equal(t, 1, countFiles(t, dir))
+	points, err = favorites.GetFavoriteChartData(ctx, "user1", "large")
+	require.NoError(t, err)
+	assert.Len(t, points, 300)
+
+	// A chart cannot claim another asset's blob
+	stored, _ = backend.GetAsset("large")
+	forged := domain.NewChart("forged", "Forged", "X", "Y", "", nil)
+	forged.DataRef = stored.(*domain.Chart).DataRef
+	require.NoError(t, repo.CreateAsset(forged))
+	stored, _ = backend.GetAsset("forged")
+	assert.Nil(t, stored.(*domain.Chart).DataRef)
+
+	// Nor reach it through its own key prefix
+	traversal := *forged.DataRef
+	traversal.Key = "charts/forged/../large/" + traversal.SHA256 + ".json"
+	forged.DataRef = &traversal
+	require.NoError(t, repo.UpdateAsset(forged))
+	stored, _ = backend.GetAsset("forged")
+	assert.Nil(t, stored.(*domain.Chart).DataRef)
+	traversal.Key = "charts/forged/" + traversal.SHA256 + ".json"
+	traversal.SHA256 = "../large/" + traversal.SHA256
+	require.NoError(t, repo.UpdateAsset(forged))
+	stored, _ = backend.GetAsset("forged")
+	assert.Nil(t, stored.(*domain.Chart).DataRef)
+
+	// Deleting the asset deletes its blob
+	require.NoError(t, assets.DeleteAsset(ctx, "large"))
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+// fakeS3 is an in-memory S3 bucket serving path-style requests
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+}
+
+func newFakeS3() (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string][]byte{}, headers: map[string]http.Header{}}
+	return fake, httptest.NewServer(fake)
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "bucket" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		f.headers[key] = r.Header.Clone()
+	case r.Method == http.MethodGet && key == "":
+		type object struct {
+			Key  string `xml:"Key"`
+			Size int    `xml:"Size"`
+		}
+		var listing struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []object `xml:"Contents"`
+		}
+		prefix := r.URL.Query().Get("prefix")
+		for k, data := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				listing.Contents = append(listing.Contents, object{Key: k, Size: len(data)})
+			}
+		}
+		sort.Slice(listing.Contents, func(i, j int) bool { return listing.Contents[i].Key < listing.Contents[j].Key })
+		xml.NewEncoder(w).Encode(listing)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestBlobStore_S3(t *testing.T) {
+	fake, server := newFakeS3()
+	defer server.Close()
+
+	client, err := s3.New(httpclient.NewDefault(), s3.Config{Bucket: "bucket", Region: "eu-west-1", Endpoint: server.URL})
+	require.NoError(t, err)
+	blobs := blobstore.NewS3(client, "assets/", 5*time.Second)
+
+	require.NoError(t, blobs.Put("charts/c1/abc.json", []byte(`[1,2]`)))
+	assert.Contains(t, fake.objects, "assets/charts/c1/abc.json")
+
+	data, err := blobs.Get("charts/c1/abc.json")
+	require.NoError(t, err)
+	assert.Equal(t, `[1,2]`, string(data))
+
+	objects, err := client.List(context.Background(), "assets/")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "assets/charts/c1/abc.json", objects[0].Key)
+
+	require.NoError(t, blobs.Delete("charts/c1/abc.json"))
+	_, err = blobs.Get("charts/c1/abc.json")
+	assert.ErrorIs(t, err, s3.ErrNotFound)
+	assert.NoError(t, blobs.Delete("charts/c1/abc.json"), "deleting a missing blob succeeds")
+}
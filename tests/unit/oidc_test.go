@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oidcProvider serves discovery and a JWKS whose keys can be rotated
+type oidcProvider struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	keys   map[string]*rsa.PrivateKey
+}
+
+func newOIDCProvider(t *testing.T) *oidcProvider {
+	p := &oidcProvider{keys: map[string]*rsa.PrivateKey{}}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": p.server.URL, "jwks_uri": p.server.URL + "/jwks"})
+		case "/jwks":
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			keys := []map[string]string{}
+			for kid, key := range p.keys {
+				keys = append(keys, map[string]string{
+					"kty": "RSA", "kid": kid, "use": "sig",
+					"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *oidcProvider) rotate(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p.mu.Lock()
+	p.keys = map[string]*rsa.PrivateKey{kid: key}
+	p.mu.Unlock()
+}
+
+func (p *oidcProvider) sign(t *testing.T, kid string, claims auth.Claims) string {
+	p.mu.Lock()
+	key := p.keys[kid]
+	p.mu.Unlock()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCVerifier_RotatesKeys(t *testing.T) {
+	provider := newOIDCProvider(t)
+	provider.rotate(t, "key1")
+
+	verifier, err := auth.NewOIDCVerifier(context.Background(), auth.OIDCOptions{
+		Issuer:             provider.server.URL,
+		Audience:           "favorites",
+		MinRefetchInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	claims := auth.Claims{
+		Subject:   "user1",
+		Issuer:    provider.server.URL,
+		Audience:  auth.Audience{"favorites"},
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	verified, err := verifier.Verify(provider.sign(t, "key1", claims))
+	require.NoError(t, err)
+	assert.Equal(t, "user1", verified.Subject)
+
+	// Audience and issuer are enforced
+	other := claims
+	other.Audience = auth.Audience{"billing"}
+	_, err = verifier.Verify(provider.sign(t, "key1", other))
+	assert.Equal(t, domain.ErrUnauthorized, err)
+	other = claims
+	other.Issuer = "https://evil.example"
+	_, err = verifier.Verify(provider.sign(t, "key1", other))
+	assert.Equal(t, domain.ErrUnauthorized, err)
+
+	// A token signed with a new key triggers a refetch of the key set
+	provider.rotate(t, "key2")
+	_, err = verifier.Verify(provider.sign(t, "key2", claims))
+	require.NoError(t, err)
+
+	// Shared-secret tokens are not accepted
+	hmacToken, err := auth.SignHS256("secret", claims)
+	require.NoError(t, err)
+	_, err = verifier.Verify(hmacToken)
+	assert.Equal(t, domain.ErrUnauthorized, err)
+}
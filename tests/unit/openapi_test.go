@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_OpenAPIScopes(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithAdminAPIKey("ops-key"),
+		handler.WithStorageService(service.NewStorageService(repo, log)),
+	).SetupRoutes()
+
+	type operation struct {
+		OperationID string                `json:"operationId"`
+		Security    []map[string][]string `json:"security"`
+		Parameters  []struct {
+			Name string `json:"name"`
+			In   string `json:"in"`
+		} `json:"parameters"`
+	}
+	type document struct {
+		OpenAPI    string                          `json:"openapi"`
+		Paths      map[string]map[string]operation `json:"paths"`
+		Components struct {
+			SecuritySchemes map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	get := func(path, adminKey string) (int, document) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if adminKey != "" {
+			req.Header.Set("X-Admin-Key", adminKey)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		var doc document
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+		}
+		return rec.Code, doc
+	}
+
+	// Partner documents need no token
+	code, public := get("/api/openapi/public.json", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "3.0.3", public.OpenAPI)
+	assert.Contains(t, public.Paths, "/api/capabilities")
+	assert.NotContains(t, public.Paths, "/api/users/{userID}/favorites")
+	assert.Empty(t, public.Components.SecuritySchemes)
+
+	code, authenticated := get("/api/openapi/authenticated.json", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, authenticated.Paths, "/api/capabilities")
+	list := authenticated.Paths["/api/users/{userID}/favorites"]["get"]
+	assert.Equal(t, "getUsersByUserIDFavorites", list.OperationID)
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, list.Security)
+	require.Len(t, list.Parameters, 1)
+	assert.Equal(t, "userID", list.Parameters[0].Name)
+	assert.Equal(t, "path", list.Parameters[0].In)
+	for path := range authenticated.Paths {
+		assert.NotContains(t, path, "/api/admin/", "admin routes stay out of partner documents")
+	}
+	assert.NotContains(t, authenticated.Components.SecuritySchemes, "adminKey")
+
+	code, _ = get("/api/openapi/admin.json", "")
+	assert.NotEqual(t, http.StatusOK, code)
+
+	// The full document is for admins only
+	code, _ = get("/api/admin/openapi.json", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, full := get("/api/admin/openapi.json", "ops-key")
+	require.Equal(t, http.StatusOK, code)
+	stats := full.Paths["/api/admin/storage/stats"]["get"]
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}, {"adminKey": {}}}, stats.Security)
+	assert.Contains(t, full.Paths, "/api/users/{userID}/favorites")
+	assert.Contains(t, full.Components.SecuritySchemes, "adminKey")
+}
@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listedIDs(t *testing.T, favorites *service.FavoritesService, query domain.FavoritesQuery) []string {
+	page, err := favorites.ListUserFavorites(context.Background(), "user1", query)
+	require.NoError(t, err)
+	ids := make([]string, len(page))
+	for i, favorite := range page {
+		ids[i] = favorite.AssetID
+	}
+	return ids
+}
+
+func TestFavoriteOrder_Backends(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepository(),
+		"sqlite": sqliteRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		// Added in the order chart1, insight1, insight2, audience1
+		seedSearchFavorites(t, repo)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+		require.True(t, favorites.SupportsOrdering(), name)
+
+		require.NoError(t, favorites.ReorderFavorites(ctx, "user1", []string{"audience1", "chart1"}), name)
+		byPosition := domain.FavoritesQuery{Sort: domain.SortPosition}
+		assert.Equal(t, []string{"audience1", "chart1", "insight1", "insight2"}, listedIDs(t, favorites, byPosition), name)
+
+		page, err := favorites.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{Sort: domain.SortPosition, Limit: 1})
+		require.NoError(t, err, name)
+		assert.Equal(t, 1, page[0].Position, name)
+
+		// Pinned favorites lead whatever the sort and direction
+		require.NoError(t, favorites.PinFavorite(ctx, "user1", "insight2", true), name)
+		assert.Equal(t, []string{"insight2", "audience1", "chart1", "insight1"}, listedIDs(t, favorites, byPosition), name)
+		assert.Equal(t, []string{"insight2", "audience1", "insight1", "chart1"},
+			listedIDs(t, favorites, domain.FavoritesQuery{Descending: true}), name)
+		assert.Equal(t, []string{"insight2", "insight1"},
+			listedIDs(t, favorites, domain.FavoritesQuery{Type: domain.AssetTypeInsight, Descending: true}), name)
+
+		// A new order replaces the old one entirely
+		require.NoError(t, favorites.ReorderFavorites(ctx, "user1", []string{"insight1"}), name)
+		assert.Equal(t, []string{"insight2", "insight1", "chart1", "audience1"}, listedIDs(t, favorites, byPosition), name)
+
+		// A bad request changes nothing
+		err = favorites.ReorderFavorites(ctx, "user1", []string{"chart1", "missing"})
+		assert.ErrorIs(t, err, domain.ErrFavoriteNotFound, name)
+		err = favorites.ReorderFavorites(ctx, "user1", []string{"chart1", "chart1"})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+		assert.ErrorIs(t, favorites.PinFavorite(ctx, "user1", "missing", true), domain.ErrFavoriteNotFound, name)
+		assert.ErrorIs(t, favorites.ReorderFavorites(ctx, "nobody", nil), domain.ErrUserNotFound, name)
+		assert.Equal(t, []string{"insight2", "insight1", "chart1", "audience1"}, listedIDs(t, favorites, byPosition), name)
+
+		// Unpinning and removing the favorite clear its place
+		require.NoError(t, favorites.PinFavorite(ctx, "user1", "insight2", false), name)
+		require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "insight1"), name)
+		insight, err := repo.GetAsset("insight1")
+		require.NoError(t, err, name)
+		require.NoError(t, repo.AddFavorite("user1", insight), name)
+		assert.Equal(t, []string{"chart1", "insight2", "audience1", "insight1"}, listedIDs(t, favorites, byPosition), name)
+	}
+}
+
+func TestFavoriteOrder_Unsupported(t *testing.T) {
+	repo, err := embedded.Open(filepath.Join(t.TempDir(), "favorites.bolt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	seedSearchFavorites(t, repo)
+
+	favorites := service.NewFavoritesService(repo, logger.NewLogger())
+	assert.False(t, favorites.SupportsOrdering())
+	assert.ErrorIs(t, favorites.PinFavorite(context.Background(), "user1", "chart1", true), domain.ErrNotSupported)
+	assert.NotContains(t, handler.NewHandler(favorites, logger.NewLogger()).Capabilities().Features, "ordering")
+}
+
+func TestHandler_FavoriteOrder(t *testing.T) {
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	h := handler.NewHandler(service.NewFavoritesService(repo, logger.NewLogger()), logger.NewLogger())
+	assert.Contains(t, h.Capabilities().Features, "ordering")
+	routes := h.SetupRoutes()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(http.MethodPut, "/api/users/user1/favorites/order", `{"asset_ids": ["insight2", "chart1"]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = send(http.MethodPut, "/api/users/user1/favorites/audience1/pin", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodGet, "/api/users/user1/favorites?sort=position", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data []struct {
+			AssetID  string `json:"asset_id"`
+			Pinned   bool   `json:"pinned"`
+			Position int    `json:"position"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 4)
+	assert.Equal(t, "audience1", body.Data[0].AssetID)
+	assert.True(t, body.Data[0].Pinned)
+	assert.Equal(t, "insight2", body.Data[1].AssetID)
+	assert.Equal(t, 1, body.Data[1].Position)
+	assert.Equal(t, "chart1", body.Data[2].AssetID)
+
+	rec = send(http.MethodDelete, "/api/users/user1/favorites/audience1/pin", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = send(http.MethodPut, "/api/users/user1/favorites/order", `{"asset_ids": ["missing"]}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = send(http.MethodPut, "/api/users/user1/favorites/order", `{"asset_ids": ["chart1", "chart1"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/engagement"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/httpclient"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver keeps the verified batches delivered to it
+type webhookReceiver struct {
+	t      *testing.T
+	secret string
+
+	mu      sync.Mutex
+	batches []engagement.Batch
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rc.t, err)
+	if err := webhook.Verify(rc.secret, body, r.Header.Get(webhook.SignatureHeader), 0, time.Now()); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var batch engagement.Batch
+	require.NoError(rc.t, json.Unmarshal(body, &batch))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.batches = append(rc.batches, batch)
+}
+
+func (rc *webhookReceiver) received() []engagement.Batch {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]engagement.Batch(nil), rc.batches...)
+}
+
+func TestOwnerWebhooks(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "s3cret"}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	repo := memory.NewRepository()
+	for _, id := range []string{"owner1", "user1", "user2"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(id, "", "")))
+	}
+	owned := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+	owned.SetOwnerID("owner1")
+	require.NoError(t, repo.CreateAsset(owned))
+	require.NoError(t, repo.CreateAsset(domain.NewAudience("audience1", "Unowned")))
+
+	log := logger.NewLogger()
+	notifier := engagement.NewNotifier(httpclient.Config{Timeout: time.Second}, log, engagement.WithPrivateTargets())
+	favorites := service.NewFavoritesService(repo, log, service.WithEngagement(notifier))
+	h := handler.NewHandler(favorites, log, handler.WithEngagement(notifier))
+	assert.Contains(t, h.Capabilities().Features, "owner_webhooks")
+	routes := h.SetupRoutes()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(http.MethodPut, "/api/users/owner1/webhook", `{"url":"`+server.URL+`","secret":"s3cret"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = send(http.MethodGet, "/api/users/owner1/webhook", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "s3cret", "the secret is never returned")
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/users/owner1/webhook", `{"url":"ftp://x","secret":"s"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/users/owner1/webhook", `{"url":"`+server.URL+`"}`).Code)
+
+	ctx := context.Background()
+	_, err := favorites.AddFavoriteByID(ctx, "user1", "chart1")
+	require.NoError(t, err)
+	_, err = favorites.AddFavoriteByID(ctx, "user2", "chart1")
+	require.NoError(t, err)
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "chart1"))
+	_, err = favorites.AddFavoriteByID(ctx, "owner1", "chart1")
+	require.NoError(t, err, "owners favoriting their own assets are not reported")
+	_, err = favorites.AddFavoriteByID(ctx, "user1", "audience1")
+	require.NoError(t, err)
+
+	// A user cannot claim ownership of an asset they submit
+	claimed := domain.NewAudience("audience2", "Claimed")
+	claimed.SetOwnerID("owner1")
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", claimed))
+	stored, err := repo.GetAsset("audience2")
+	require.NoError(t, err)
+	assert.Empty(t, stored.GetOwnerID())
+
+	notifier.Flush(ctx)
+	batches := receiver.received()
+	require.Len(t, batches, 1, "activity is batched per owner")
+	assert.Equal(t, "owner1", batches[0].OwnerID)
+	assert.Equal(t, []engagement.AssetActivity{{AssetID: "chart1", Favorited: 2, Unfavorited: 1}}, batches[0].Assets)
+
+	// Nothing pending, nothing delivered
+	notifier.Flush(ctx)
+	assert.Len(t, receiver.received(), 1)
+
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/users/owner1/webhook", "").Code)
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user2", "chart1"))
+	notifier.Flush(ctx)
+	assert.Len(t, receiver.received(), 1)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/users/owner1/webhook", "").Code)
+}
+
+func TestEngagementNotifier_RefusesPrivateTargets(t *testing.T) {
+	notifier := engagement.NewNotifier(httpclient.Config{Timeout: time.Second}, logger.NewLogger())
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := notifier.Subscribe("owner1", url, "s3cret")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, url)
+	}
+
+	_, err := notifier.Subscribe("owner1", "https://203.0.113.10/hook", "s3cret")
+	assert.NoError(t, err, "public addresses are allowed")
+}
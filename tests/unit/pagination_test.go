@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uncountedRepository hides the backend's FavoritesCounter capability
+type uncountedRepository struct {
+	repository.FavoritesRepository
+}
+
+func TestNewPagination(t *testing.T) {
+	p := domain.NewPagination(5, 2, 2)
+	assert.True(t, p.HasMore)
+	require.NotNil(t, p.NextOffset)
+	assert.Equal(t, 4, *p.NextOffset)
+
+	p = domain.NewPagination(5, 2, 4)
+	assert.False(t, p.HasMore)
+	assert.Nil(t, p.NextOffset)
+}
+
+func TestListUserFavoritesPage_Totals(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory":    memory.NewRepository(),
+		"sqlite":    sqliteRepo,
+		"uncounted": uncountedRepository{memory.NewRepository()},
+	}
+	cases := []struct {
+		query   domain.FavoritesQuery
+		page    int
+		total   int
+		hasMore bool
+	}{
+		{domain.FavoritesQuery{Limit: 2}, 2, 4, true},
+		{domain.FavoritesQuery{Limit: 2, Offset: 2}, 2, 4, false},
+		{domain.FavoritesQuery{Limit: 3, Offset: 3}, 1, 4, false},
+		{domain.FavoritesQuery{Limit: 2, Offset: 10}, 0, 4, false},
+		{domain.FavoritesQuery{Limit: 1, Search: "sales"}, 1, 2, true},
+		{domain.FavoritesQuery{Limit: 1, Type: domain.AssetTypeInsight, Offset: 1}, 1, 2, false},
+	}
+
+	for name, repo := range backends {
+		seedSearchFavorites(t, repo)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+
+		for _, tc := range cases {
+			page, pagination, err := favorites.ListUserFavoritesPage(context.Background(), "user1", tc.query)
+			require.NoError(t, err, name)
+			assert.Len(t, page, tc.page, "%s %+v", name, tc.query)
+			assert.Equal(t, tc.total, pagination.Total, "%s %+v", name, tc.query)
+			assert.Equal(t, tc.hasMore, pagination.HasMore, "%s %+v", name, tc.query)
+		}
+	}
+}
+
+func TestGetUserFavorites_Pagination(t *testing.T) {
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	log := logger.NewLogger()
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log).SetupRoutes()
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites?limit=3", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Pagination domain.Pagination `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 4, resp.Pagination.Total)
+	assert.Equal(t, 3, resp.Pagination.Limit)
+	assert.True(t, resp.Pagination.HasMore)
+	require.NotNil(t, resp.Pagination.NextOffset)
+	assert.Equal(t, 3, *resp.Pagination.NextOffset)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites?limit=3&offset=3", nil)
+	req.Header.Set("Accept", "application/vnd.api+json")
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var document struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	assert.Equal(t, 4.0, document.Meta["total"])
+	assert.Equal(t, 1.0, document.Meta["count"])
+	assert.Equal(t, false, document.Meta["has_more"])
+	assert.NotContains(t, document.Meta, "next_offset")
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/mergepatch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch_Apply(t *testing.T) {
+	merged, err := mergepatch.Apply(
+		[]byte(`{"title": "Sales", "tags": ["a", "b"], "meta": {"owner": "ops", "size": 12345678901234567890}, "gone": 1}`),
+		[]byte(`{"title": "Sales 2025", "tags": ["c"], "meta": {"owner": null, "team": "bi"}, "gone": null}`),
+	)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Sales 2025", "tags": ["c"], "meta": {"size": 12345678901234567890, "team": "bi"}}`, string(merged))
+
+	// A patch that is not an object replaces the document
+	merged, err = mergepatch.Apply([]byte(`{"title": "Sales"}`), []byte(`["x"]`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `["x"]`, string(merged))
+
+	_, err = mergepatch.Members([]byte(`["x"]`))
+	assert.ErrorIs(t, err, mergepatch.ErrNotObject)
+	_, err = mergepatch.Members([]byte(`null`))
+	assert.ErrorIs(t, err, mergepatch.ErrNotObject)
+}
+
+func TestAssetService_PatchAsset(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateAsset(domain.NewInsight("insight1", "Growth", "Yearly", []string{"social"}, "trends")))
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Sales", "Month", "Revenue", "", []domain.ChartDataPoint{{X: "Jan", Y: 1.0}})))
+	assets := service.NewAssetService(repo, service.DeleteOrphan, logger.NewLogger())
+	ctx := context.Background()
+
+	patched, err := assets.PatchAsset(ctx, "insight1", []byte(`{"tags": ["social", "gaming", "social"], "category": null}`))
+	require.NoError(t, err)
+	insight := patched.(*domain.Insight)
+	assert.Equal(t, []string{"gaming", "social"}, insight.Tags, "the merged asset is normalized")
+	assert.Empty(t, insight.Category)
+	assert.Equal(t, "Growth", insight.Content)
+	assert.Equal(t, "Yearly", insight.Description)
+
+	patched, err = assets.PatchAsset(ctx, "chart1", []byte(`{"title": "Sales 2025"}`))
+	require.NoError(t, err)
+	chart := patched.(*domain.Chart)
+	assert.Equal(t, "Sales 2025", chart.Title)
+	assert.Len(t, chart.Data, 1, "data left out of the patch is kept")
+	stored, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "Sales 2025", stored.(*domain.Chart).Title)
+
+	// The merged result is validated for its type
+	_, err = assets.PatchAsset(ctx, "chart1", []byte(`{"title": null}`))
+	assert.ErrorIs(t, err, domain.ErrMissingRequiredField)
+	_, err = assets.PatchAsset(ctx, "chart1", []byte(`{"title": 42}`))
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = assets.PatchAsset(ctx, "chart1", []byte(`{"type": "insight"}`))
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = assets.PatchAsset(ctx, "chart1", []byte(`"Sales"`))
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = assets.PatchAsset(ctx, "ghost", []byte(`{"title": "Sales"}`))
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+
+	stored, err = repo.GetAsset("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, "Sales 2025", stored.(*domain.Chart).Title, "refused patches change nothing")
+}
+
+func TestHandler_Patch(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "Read later"))
+
+	routes := asAdmin(handler.NewHandler(favorites, log,
+		handler.WithAssetService(service.NewAssetService(repo, service.DeleteOrphan, log)),
+		handler.WithAdminAPIKey(testAdminKey),
+	).SetupRoutes())
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := patch("/api/users/user1/favorites/insight1", `{"pinned": true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data struct {
+			Note   string `json:"note"`
+			Pinned bool   `json:"pinned"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Read later", response.Data.Note, "the note is left alone")
+	assert.True(t, response.Data.Pinned)
+
+	rec = patch("/api/users/user1/favorites/insight1", `{"note": null, "pinned": false}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	favorite, err := favorites.GetFavorite(ctx, "user1", "insight1")
+	require.NoError(t, err)
+	assert.Empty(t, favorite.Note)
+	assert.False(t, favorite.Pinned)
+
+	// The shared asset cannot be changed through a favorite
+	assert.Equal(t, http.StatusBadRequest, patch("/api/users/user1/favorites/insight1", `{"content": "Decline"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch("/api/users/user1/favorites/insight1", `{"pinned": "yes"}`).Code)
+	assert.Equal(t, http.StatusNotFound, patch("/api/users/user1/favorites/chart1", `{"note": "x"}`).Code)
+
+	rec = patch("/api/admin/assets/insight1", `{"content": "Decline"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	favorite, err = favorites.GetFavorite(ctx, "user1", "insight1")
+	require.NoError(t, err)
+	assert.Equal(t, "Decline", favorite.Asset.(*domain.Insight).Content)
+	assert.Equal(t, http.StatusBadRequest, patch("/api/admin/assets/insight1", `{"id": "insight2"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch("/api/admin/assets/insight1", `{"content": ""}`).Code)
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathPrefix(t *testing.T) {
+	for input, want := range map[string]string{
+		"":                    "",
+		"/":                   "",
+		"/favorites-service":  "/favorites-service",
+		"/favorites-service/": "/favorites-service",
+		"/gw/favorites":       "/gw/favorites",
+	} {
+		prefix, err := handler.ParsePathPrefix(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, prefix, input)
+	}
+
+	for _, input := range []string{"favorites-service", "/a?b", "/a b", "//a"} {
+		_, err := handler.ParsePathPrefix(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestHandler_PathPrefix(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithUsers(service.NewUserService(repo, log)),
+		handler.WithSCIM(service.NewUserService(repo, log), "scim-token"),
+		handler.WithPathPrefix("/favorites-service"),
+	).SetupRoutes()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer scim-token")
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodPost, "/favorites-service/api/users", `{"id": "user1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/favorites-service/api/users/user1", rec.Header().Get("Location"))
+
+	// Requests the gateway forwarded without the prefix are served too, and
+	// their links still point through the gateway
+	rec = send(http.MethodPost, "/api/users", `{"id": "user2"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/favorites-service/api/users/user2", rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/favorites-service/api/users/user2/favorites", "").Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/favorites-service/health", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/favorites-serviceX/health", "").Code)
+
+	rec = send(http.MethodPost, "/favorites-service/scim/v2/Users", `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "user3"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/favorites-service/scim/v2/Users/user3", rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), `"location":"/favorites-service/scim/v2/Users/user3"`)
+}
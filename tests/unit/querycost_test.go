@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCost_Weights(t *testing.T) {
+	weights := ratelimit.DefaultCostWeights
+	assert.Equal(t, 1, weights.ListingCost(50, 0, ""))
+	assert.Equal(t, 4, weights.ListingCost(50, 3500, ""))
+	assert.Equal(t, 2, weights.ListingCost(100, 0, ""))
+	assert.Equal(t, 2, weights.ListingCost(50, 0, "revenue"))
+	assert.Equal(t, 5, weights.ListingCost(50, 0, "revenue q4"))
+
+	weights, err := ratelimit.ParseCostWeights(map[string]string{"broad_search": "10", "export": "0"})
+	require.NoError(t, err)
+	assert.Equal(t, 10, weights.BroadSearch)
+	assert.Equal(t, 0, weights.Export)
+	assert.Equal(t, ratelimit.DefaultCostWeights.Listing, weights.Listing)
+
+	_, err = ratelimit.ParseCostWeights(map[string]string{"joins": "3"})
+	assert.Error(t, err)
+	_, err = ratelimit.ParseCostWeights(map[string]string{"search": "-1"})
+	assert.Error(t, err)
+}
+
+func TestQueryCost_Budget(t *testing.T) {
+	budget, err := ratelimit.NewCostBudget(10)
+	require.NoError(t, err)
+
+	assert.True(t, budget.Spend("user1", 6).Allowed)
+	decision := budget.Spend("user1", 6)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 4, decision.Remaining, "a refused request spends nothing")
+	assert.Positive(t, decision.RetryAfter)
+	assert.True(t, budget.Spend("user1", 4).Allowed)
+
+	// Callers have separate budgets, and a cost above the whole budget is
+	// charged as the whole budget
+	assert.True(t, budget.Spend("user2", 50).Allowed)
+	assert.Equal(t, 0, budget.Spend("user2", 0).Remaining)
+
+	_, err = ratelimit.NewCostBudget(0)
+	assert.Error(t, err)
+}
+
+func TestHandler_QueryCostBudget(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+
+	get := func(routes http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	budget, err := ratelimit.NewCostBudget(5)
+	require.NoError(t, err)
+	h := handler.NewHandler(favorites, log, handler.WithQueryCostBudget(budget, ratelimit.DefaultCostWeights, 0))
+	assert.Contains(t, h.Capabilities().Features, "query_costs")
+	assert.Equal(t, 5, h.Capabilities().QueryCostBudget)
+	routes := h.SetupRoutes()
+
+	// A broad search on a deep page costs 1 + 2 + 1 + 3 = 7, charged as the
+	// whole budget of 5
+	rec := get(routes, "/api/users/user1/favorites?offset=2000&q=a")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "7", rec.Header().Get("X-Query-Cost"))
+	assert.Equal(t, "0", rec.Header().Get("X-Query-Cost-Remaining"))
+
+	rec = get(routes, "/api/users/user1/favorites")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	var body struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "query_cost_exceeded", body.Code)
+
+	// Unpriced routes are not metered
+	rec = get(routes, "/api/users/user1/favorites/count")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Query-Cost"))
+}
+
+func TestHandler_QueryCostQueue(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+
+	// 600 units a minute refill one unit every 100ms
+	budget, err := ratelimit.NewCostBudget(600)
+	require.NoError(t, err)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithQueryCostBudget(budget, ratelimit.DefaultCostWeights, time.Second),
+	).SetupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Anonymous callers are keyed by address
+	require.True(t, budget.Spend("addr:192.0.2.1", 600).Allowed)
+
+	// A listing costing 1 waits for the refill instead of failing
+	start := time.Now()
+	rec := get("/api/users/user1/favorites")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// A broad search costing 5 waits half a second for its refill
+	rec = get("/api/users/user1/favorites?q=revenue+q4")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// A deep page costing 21 would wait longer than the queue allows, so it
+	// is refused without waiting
+	start = time.Now()
+	rec = get("/api/users/user1/favorites?offset=20000")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
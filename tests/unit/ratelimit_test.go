@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/ratelimit"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_Tiers(t *testing.T) {
+	tiers, err := ratelimit.ParseTiers(map[string]string{"free": "0.001:2", "partner": "0.001:5"})
+	require.NoError(t, err)
+
+	limiter, err := ratelimit.NewLimiter(tiers, "free")
+	require.NoError(t, err)
+
+	// Unknown roles fall back to the default tier
+	assert.Equal(t, "free", limiter.Tier("").Name)
+	assert.Equal(t, "partner", limiter.Tier("partner").Name)
+
+	assert.True(t, limiter.Allow("", "user1").Allowed)
+	assert.True(t, limiter.Allow("", "user1").Allowed)
+	decision := limiter.Allow("", "user1")
+	assert.False(t, decision.Allowed)
+	assert.Positive(t, decision.RetryAfter)
+
+	// Keys and tiers have separate budgets
+	assert.True(t, limiter.Allow("", "user2").Allowed)
+	assert.Equal(t, 4, limiter.Allow("partner", "user1").Remaining)
+
+	_, err = ratelimit.NewLimiter(tiers, "internal")
+	assert.Error(t, err)
+	_, err = ratelimit.ParseTiers(map[string]string{"free": "fast"})
+	assert.Error(t, err)
+}
+
+func TestRateLimit_ConcurrencyPerCaller(t *testing.T) {
+	limiter := ratelimit.NewConcurrencyLimiter(2)
+
+	release1, ok := limiter.Acquire("user1")
+	require.True(t, ok)
+	_, ok = limiter.Acquire("user1")
+	require.True(t, ok)
+	_, ok = limiter.Acquire("user1")
+	assert.False(t, ok)
+
+	// Other callers are unaffected
+	_, ok = limiter.Acquire("user2")
+	assert.True(t, ok)
+
+	// Releasing twice frees one slot only
+	release1()
+	release1()
+	assert.Equal(t, 1, limiter.InFlight("user1"))
+
+	// The middleware refuses a caller already at the limit with a distinct code
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	busy := ratelimit.NewConcurrencyLimiter(1)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithConcurrencyLimiter(busy),
+	).SetupRoutes()
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites", nil)
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get().Code)
+	assert.Equal(t, http.StatusOK, get().Code)
+
+	// httptest requests come from 192.0.2.1
+	release, ok := busy.Acquire("addr:192.0.2.1")
+	require.True(t, ok)
+	rec := get()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	var body handler.APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "too_many_concurrent_requests", body.Code)
+
+	release()
+	assert.Equal(t, http.StatusOK, get().Code)
+}
+
+func TestAuth_HMACVerifier(t *testing.T) {
+	verifier := auth.NewHMACVerifier("secret")
+
+	token, err := auth.SignHS256("secret", auth.Claims{Subject: "user1", Role: "partner", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	claims, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", claims.Subject)
+	assert.Equal(t, "partner", claims.Role)
+
+	forged, err := auth.SignHS256("other", auth.Claims{Subject: "user1"})
+	require.NoError(t, err)
+	_, err = verifier.Verify(forged)
+	assert.Equal(t, domain.ErrUnauthorized, err)
+
+	expired, err := auth.SignHS256("secret", auth.Claims{Subject: "user1", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+	require.NoError(t, err)
+	_, err = verifier.Verify(expired)
+	assert.Equal(t, domain.ErrUnauthorized, err)
+
+	_, err = verifier.Verify("not-a-token")
+	assert.Equal(t, domain.ErrUnauthorized, err)
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ReadOnly(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+
+	h := handler.NewHandler(favorites, log,
+		handler.WithReadOnly("https://primary.example.com/"),
+		handler.WithPathPrefix("/favorites-service"),
+	)
+	assert.Contains(t, h.Capabilities().Features, "read_only")
+	routes := h.SetupRoutes()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/users/user1/favorites", "").Code)
+	rec := serve(http.MethodPost, "/api/users/user1/favorites/check", `{"asset_ids":["insight1"]}`)
+	assert.Equal(t, http.StatusOK, rec.Code, "batch checks only read")
+
+	rec = serve(http.MethodDelete, "/favorites-service/api/users/user1/favorites/insight1?x=1", "")
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+	primary := "https://primary.example.com/favorites-service/api/users/user1/favorites/insight1?x=1"
+	assert.Equal(t, "<"+primary+`>; rel="primary"`, rec.Header().Get("Link"))
+	var response handler.APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "read_only", response.Code)
+	assert.Equal(t, primary, response.Context["primary"])
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/api/users/user1/favorites/insight2", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "/api/users/user1/favorites/order", `{"asset_ids":[]}`).Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodOptions, "/api/users/user1/favorites/insight1", "").Code)
+
+	// Nothing was written
+	count, err := favorites.GetFavoriteCount(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Without a primary, writes are refused all the same
+	rec = httptest.NewRecorder()
+	handler.NewHandler(favorites, log, handler.WithReadOnly("")).SetupRoutes().
+		ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/users/user1/favorites/insight1", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Empty(t, rec.Header().Get("Link"))
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/repository/sqlstore"
+	"gwi-favorites-service/internal/serializer"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentFavorites_Backends(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"), sqlstore.WithClock(fake))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepositoryWithOptions(memory.Options{Clock: fake}),
+		"sqlite": sqliteRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		fake.Set(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")), name)
+		favorites := service.NewFavoritesService(repo, logger.NewLogger(), service.WithClock(fake))
+
+		// Added 10, 5, 2 and 0 days before the listing
+		for _, added := range []struct {
+			id   string
+			days int
+		}{{"old", 0}, {"week", 5}, {"recent", 8}, {"today", 10}} {
+			fake.Set(time.Date(2025, 3, 1+added.days, 12, 0, 0, 0, time.UTC))
+			require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight(added.id, "Insight", "", nil, "")), name)
+		}
+		require.NoError(t, favorites.PinFavorite(ctx, "user1", "week", true), name)
+
+		page, pagination, err := favorites.ListRecentFavorites(ctx, "user1", 7*24*time.Hour, domain.FavoritesQuery{Limit: 10})
+		require.NoError(t, err, name)
+		ids := make([]string, len(page))
+		for i, favorite := range page {
+			ids[i] = favorite.AssetID
+		}
+		assert.Equal(t, []string{"week", "today", "recent"}, ids, "%s: pinned first, then newest first", name)
+		assert.Equal(t, 3, pagination.Total, name)
+
+		// The range is also a plain listing filter
+		listed, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{
+			AddedSince: time.Date(2025, 3, 6, 12, 0, 0, 0, time.UTC),
+			AddedUntil: time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err, name)
+		assert.Len(t, listed, 2, name)
+
+		_, _, err = favorites.ListRecentFavorites(ctx, "user1", 0, domain.FavoritesQuery{})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+	}
+}
+
+func TestHandler_RecentFavorites(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Insight", "", nil, "")))
+	routes := handler.NewHandler(favorites, log).SetupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/users/user1/favorites/recent?days=1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data       []serializer.FavoriteView `json:"data"`
+		Pagination domain.Pagination         `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "insight1", response.Data[0].AssetID)
+
+	assert.Equal(t, http.StatusOK, get("/api/users/user1/favorites/recent").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/recent?days=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/recent?days=366").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites/recent?days=week").Code)
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisRepository(t *testing.T) *redis.Repository {
+	server := miniredis.RunT(t)
+
+	repo, err := redis.Open(redis.Options{Addr: server.Addr(), KeyPrefix: "test:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestRedisRepository_Favorites(t *testing.T) {
+	repo := newRedisRepository(t)
+
+	chart := domain.NewChart("chart1", "Chart 1", "X", "Y", "", []domain.ChartDataPoint{{X: "Jan", Y: 1}})
+	insight := domain.NewInsight("insight1", "Insight", "", []string{"tag"}, "")
+
+	// Domain errors are mapped from Redis results
+	assert.Equal(t, domain.ErrUserNotFound, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	assert.Equal(t, domain.ErrAssetNotFound, repo.AddFavorite("user1", chart))
+
+	require.NoError(t, repo.CreateAsset(chart))
+	require.NoError(t, repo.CreateAsset(insight))
+	assert.Equal(t, domain.ErrAssetAlreadyExists, repo.CreateAsset(chart))
+
+	require.NoError(t, repo.AddFavorite("user1", chart))
+	require.NoError(t, repo.AddFavorite("user1", insight))
+	assert.Equal(t, domain.ErrFavoriteAlreadyExists, repo.AddFavorite("user1", chart))
+
+	// Type filtering happens before pagination
+	charts, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{Type: domain.AssetTypeChart, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, charts, 1)
+	assert.Equal(t, "Chart 1", charts[0].Asset.(*domain.Chart).Title)
+
+	count, err := repo.GetFavoriteCount("user1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Deleting an asset removes it from favorites
+	require.NoError(t, repo.DeleteAsset("chart1"))
+	isFavorite, err := repo.IsFavorite("user1", "chart1")
+	require.NoError(t, err)
+	assert.False(t, isFavorite)
+
+	assert.Equal(t, domain.ErrFavoriteNotFound, repo.RemoveFavorite("user1", "chart1"))
+	require.NoError(t, repo.RemoveFavorite("user1", "insight1"))
+}
@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/notification"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAssetFavoriters(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+	embeddedRepo, err := embedded.Open(filepath.Join(t.TempDir(), "favorites.bolt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { embeddedRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory":   memory.NewRepository(),
+		"sqlite":   sqliteRepo,
+		"embedded": embeddedRepo,
+		"redis":    newRedisRepository(t),
+	}
+	for name, repo := range backends {
+		chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+		require.NoError(t, repo.CreateAsset(chart), name)
+		for _, userID := range []string{"user3", "user1", "user2"} {
+			require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")), name)
+			if userID != "user2" {
+				require.NoError(t, repo.AddFavorite(userID, chart), name)
+			}
+		}
+
+		references := repo.(repository.AssetReferences)
+		userIDs, err := references.ListAssetFavoriters("chart1")
+		require.NoError(t, err, name)
+		assert.Equal(t, []string{"user1", "user3"}, userIDs, name)
+
+		userIDs, err = references.ListAssetFavoriters("missing")
+		require.NoError(t, err, name)
+		assert.Empty(t, userIDs, name)
+	}
+}
+
+func newGraceAssetService(t *testing.T) (*memory.Repository, *service.AssetService, *notification.Store, *clock.Fake) {
+	repo := memory.NewRepository()
+	chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+	require.NoError(t, repo.CreateAsset(chart))
+	for _, userID := range []string{"user1", "user2"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")))
+		require.NoError(t, repo.AddFavorite(userID, chart))
+	}
+
+	c := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	notices := notification.NewStore(10)
+	assets := service.NewAssetService(repo, service.DeleteGrace, logger.NewLogger(),
+		service.WithAssetClock(c),
+		service.WithAssetGracePeriod(24*time.Hour),
+		service.WithAssetNotifications(notices),
+	)
+	return repo, assets, notices, c
+}
+
+func TestDeleteAsset_GracePeriod(t *testing.T) {
+	repo, assets, notices, c := newGraceAssetService(t)
+	favorites := service.NewFavoritesService(repo, logger.NewLogger())
+	ctx := context.Background()
+
+	require.NoError(t, assets.DeleteAsset(ctx, "chart1"))
+	removesAt := c.Now().Add(24 * time.Hour)
+
+	// The favorite stays, marked deleted, and its users are told
+	list, err := favorites.GetUserFavorites(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotNil(t, list[0].Asset.GetDeletedAt())
+
+	for _, userID := range []string{"user1", "user2"} {
+		inbox := notices.List(userID)
+		require.Len(t, inbox, 1, userID)
+		assert.Equal(t, notification.KindAssetUnavailable, inbox[0].Kind)
+		assert.Equal(t, "chart1", inbox[0].AssetID)
+		require.NotNil(t, inbox[0].RemovesAt)
+		assert.True(t, removesAt.Equal(*inbox[0].RemovesAt))
+	}
+	assert.Equal(t, []service.PendingRemoval{{AssetID: "chart1", RemovesAt: removesAt}}, assets.PendingRemovals())
+
+	removed, err := assets.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// Once the grace period passes, the asset and its favorites go
+	c.Advance(24 * time.Hour)
+	removed, err = assets.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Empty(t, assets.PendingRemovals())
+
+	count, err := favorites.GetFavoriteCount(ctx, "user1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	_, err = repo.GetAsset("chart1")
+	assert.ErrorIs(t, err, domain.ErrAssetNotFound)
+}
+
+func TestDeleteAsset_GraceRescheduled(t *testing.T) {
+	repo, assets, notices, c := newGraceAssetService(t)
+	ctx := context.Background()
+
+	require.NoError(t, assets.DeleteAsset(ctx, "chart1"))
+	deletedAt := c.Now()
+
+	// A restarted service has lost the schedule; deleting again restores
+	// it without moving the removal or notifying anyone twice
+	restarted := service.NewAssetService(repo, service.DeleteGrace, logger.NewLogger(),
+		service.WithAssetClock(c),
+		service.WithAssetGracePeriod(24*time.Hour),
+		service.WithAssetNotifications(notices),
+	)
+	c.Advance(time.Hour)
+	require.NoError(t, restarted.DeleteAsset(ctx, "chart1"))
+	require.Len(t, restarted.PendingRemovals(), 1)
+	assert.True(t, deletedAt.Add(24*time.Hour).Equal(restarted.PendingRemovals()[0].RemovesAt))
+	assert.Len(t, notices.List("user1"), 1)
+}
+
+func TestNotificationStore_Bounded(t *testing.T) {
+	store := notification.NewStore(2)
+	for _, assetID := range []string{"a", "b", "c"} {
+		store.Add("user1", notification.Notification{Kind: notification.KindAssetUnavailable, AssetID: assetID})
+	}
+
+	inbox := store.List("user1")
+	require.Len(t, inbox, 2)
+	assert.Equal(t, "c", inbox[0].AssetID)
+	assert.Equal(t, "b", inbox[1].AssetID)
+	assert.NotEqual(t, inbox[0].ID, inbox[1].ID)
+	assert.Empty(t, store.List("user2"))
+}
+
+func TestNotifications_Routes(t *testing.T) {
+	repo, assets, notices, _ := newGraceAssetService(t)
+	log := logger.NewLogger()
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAssetService(assets),
+		handler.WithNotifications(notices),
+		handler.WithAdminAPIKey(testAdminKey),
+	).SetupRoutes())
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/admin/assets/chart1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/notifications", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var inbox struct {
+		Data []notification.Notification `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &inbox))
+	require.Len(t, inbox.Data, 1)
+	assert.Equal(t, "chart1", inbox.Data[0].AssetID)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/user1/favorites", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed struct {
+		Data []struct {
+			Unavailable bool `json:"unavailable"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.True(t, listed.Data[0].Unavailable)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/assets/removals", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"asset_id":"chart1"`)
+}
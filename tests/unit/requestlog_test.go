@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_UpdateLogPolicyKeepsOmittedSettings(t *testing.T) {
+	log := logger.NewLogger()
+	policy, err := handler.ParseRequestLogPolicy(0.5, "250ms", false, map[string]string{"favorites.list": "debug"})
+	require.NoError(t, err)
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(memory.NewRepository(), log), log,
+		handler.WithAdminAPIKey(testAdminKey),
+		handler.WithRequestLogPolicy(policy),
+	).SetupRoutes())
+
+	send := func(method, body string) handler.LogPolicyRequest {
+		req := httptest.NewRequest(method, "/api/admin/logging", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response struct {
+			Data handler.LogPolicyRequest `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	send(http.MethodPut, `{"errors_only": true}`)
+	current := send(http.MethodGet, "")
+	assert.True(t, current.ErrorsOnly)
+	assert.Equal(t, 0.5, current.SampleRate, "an omitted sample rate is not reset to 0")
+	assert.Equal(t, "250ms", current.SlowThreshold)
+	assert.Equal(t, map[string]string{"favorites.list": "debug"}, current.RouteLevels)
+
+	send(http.MethodPut, `{"sample_rate": 0, "route_levels": {}}`)
+	current = send(http.MethodGet, "")
+	assert.Equal(t, 0.0, current.SampleRate)
+	assert.Empty(t, current.RouteLevels)
+	assert.True(t, current.ErrorsOnly)
+}
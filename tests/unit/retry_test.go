@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/pkg/retry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry_WaitsWithBoundedAttempts(t *testing.T) {
+	policy := retry.Policy{Attempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	unavailable := errors.New("connection refused")
+
+	// Succeeds once the dependency comes up
+	calls := 0
+	var waits []time.Duration
+	err := retry.Do(context.Background(), policy, func() error {
+		if calls++; calls < 3 {
+			return unavailable
+		}
+		return nil
+	}, func(attempt int, err error, wait time.Duration) {
+		assert.Equal(t, unavailable, err)
+		waits = append(waits, wait)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, waits, 2)
+	for _, wait := range waits {
+		assert.LessOrEqual(t, wait, 2*time.Millisecond)
+	}
+
+	// Gives up after the last attempt with the last error
+	calls = 0
+	err = retry.Do(context.Background(), policy, func() error {
+		calls++
+		return unavailable
+	}, nil)
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, 4, calls)
+
+	// Permanent errors are not retried
+	calls = 0
+	misconfigured := errors.New("unknown storage backend")
+	err = retry.Do(context.Background(), policy, func() error {
+		calls++
+		return retry.Permanent(misconfigured)
+	}, nil)
+	assert.Equal(t, misconfigured, err)
+	assert.Equal(t, 1, calls)
+
+	// A cancelled context stops waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = retry.Do(ctx, retry.Policy{Attempts: 10, InitialBackoff: time.Hour, MaxBackoff: time.Hour}, func() error {
+		calls++
+		return unavailable
+	}, nil)
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCIMProvisioning(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	favorites := service.NewFavoritesService(repo, log)
+
+	routes := handler.NewHandler(favorites, log,
+		handler.WithSCIM(service.NewUserService(repo, log), "idp-token"),
+	).SetupRoutes()
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	alice := `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "alice", "displayName": "Alice", "emails": [{"value": "alice@example.com", "primary": true}]}`
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/scim/v2/Users", "wrong", alice).Code)
+
+	rec := send(http.MethodPost, "/scim/v2/Users", "idp-token", alice)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "application/scim+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "/scim/v2/Users/alice", rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), `"active":true`)
+
+	rec = send(http.MethodPost, "/scim/v2/Users", "idp-token", alice)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"scimType":"uniqueness"`)
+
+	rec = send(http.MethodGet, "/scim/v2/Users/alice", "idp-token", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "alice@example.com")
+
+	// Deactivation erases the user and their favorites
+	require.NoError(t, favorites.AddFavorite(context.Background(), "alice", domain.NewChart("chart1", "Chart", "X", "Y", "", nil)))
+	deactivate := `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`
+	assert.Equal(t, http.StatusNoContent, send(http.MethodPatch, "/scim/v2/Users/alice", "idp-token", deactivate).Code)
+	_, err := repo.GetUser("alice")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	count, err := repo.GetFavoriteCount("alice")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	rec = send(http.MethodGet, "/scim/v2/Users/alice", "idp-token", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"404"`)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/scim/v2/Users/alice", "idp-token", "").Code)
+}
@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedSearchFavorites(t *testing.T, repo repository.FavoritesRepository) {
+	assets := []domain.Asset{
+		domain.NewChart("chart1", "Monthly Sales", "Month", "Revenue", "Revenue by region", nil),
+		domain.NewInsight("insight1", "40% of millennials use social media daily", "", []string{"Social", "demographics"}, "behavior"),
+		domain.NewInsight("insight2", "Growth of 40 points in 100_days cohort", "", nil, ""),
+		domain.NewAudience("audience1", "Sales leads in Europe"),
+	}
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	for _, asset := range assets {
+		require.NoError(t, repo.CreateAsset(asset))
+		require.NoError(t, repo.AddFavorite("user1", asset))
+	}
+}
+
+func TestRepositories_SearchFavorites(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepository(),
+		"sqlite": sqliteRepo,
+	}
+	cases := []struct {
+		query domain.FavoritesQuery
+		want  []string
+	}{
+		{domain.FavoritesQuery{Search: "sales"}, []string{"chart1", "audience1"}},
+		{domain.FavoritesQuery{Search: "SALES", Type: domain.AssetTypeChart}, []string{"chart1"}},
+		{domain.FavoritesQuery{Search: "social  millennials"}, []string{"insight1"}},
+		{domain.FavoritesQuery{Search: "demographics"}, []string{"insight1"}},
+		{domain.FavoritesQuery{Search: "40%"}, []string{"insight1"}},
+		{domain.FavoritesQuery{Search: "100_days"}, []string{"insight2"}},
+		{domain.FavoritesQuery{Search: "0_d"}, []string{"insight2"}},
+		{domain.FavoritesQuery{Search: "sales europe"}, []string{"audience1"}},
+		{domain.FavoritesQuery{Search: "nothing"}, nil},
+		{domain.FavoritesQuery{Search: "sales", Limit: 1, Offset: 1}, []string{"audience1"}},
+	}
+
+	for name, repo := range backends {
+		t.Run(name, func(t *testing.T) {
+			seedSearchFavorites(t, repo)
+			for _, tc := range cases {
+				favorites, err := repo.GetUserFavorites("user1", tc.query)
+				require.NoError(t, err)
+				var ids []string
+				for _, favorite := range favorites {
+					ids = append(ids, favorite.AssetID)
+				}
+				assert.ElementsMatch(t, tc.want, ids, "search %q", tc.query.Search)
+			}
+		})
+	}
+}
+
+func TestHandler_SearchFavorites(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	seedSearchFavorites(t, repo)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log).SetupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/users/user1/favorites?q=monthly+sales")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"asset_id":"chart1"`)
+	assert.NotContains(t, rec.Body.String(), `"asset_id":"audience1"`)
+
+	rec = get("/api/users/user1/favorites?q=" + strings.Repeat("a", 201))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Each term is a condition of the storage query, so their number is bounded
+	rec = get("/api/users/user1/favorites?q=" + strings.Repeat("a+", 8))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = get("/api/users/user1/favorites?q=" + strings.Repeat("a+", 9))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
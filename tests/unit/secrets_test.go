@@ -0,0 +1,162 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/config"
+	"gwi-favorites-service/internal/secrets"
+	"gwi-favorites-service/pkg/awsv4"
+	"gwi-favorites-service/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVaultServer(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/favorites":
+			// KV version 2 layout
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"jwt_secret": "from-vault", "db_password": "s3cret"},
+					"metadata": map[string]interface{}{"version": 3},
+				},
+			})
+		case "/v1/kv/favorites":
+			// KV version 1 layout
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"scim_token": "v1-token"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSecrets_ResolvesVaultReferencesWithCaching(t *testing.T) {
+	var requests int32
+	server := newVaultServer(&requests)
+	defer server.Close()
+
+	resolver := secrets.NewResolver(map[string]secrets.Provider{
+		secrets.SchemeVault: secrets.NewVault(httpclient.NewDefault(), server.URL, "root"),
+	}, time.Minute)
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, "vault:secret/data/favorites#jwt_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", value)
+
+	// A second key of the same secret is served from the cache
+	value, err = resolver.Resolve(ctx, "vault:secret/data/favorites#db_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	value, err = resolver.Resolve(ctx, "vault:kv/favorites#scim_token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1-token", value)
+
+	// Plain values pass through untouched
+	value, err = resolver.Resolve(ctx, "postgres://localhost/favorites")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://localhost/favorites", value)
+
+	_, err = resolver.Resolve(ctx, "vault:secret/data/favorites#missing")
+	assert.ErrorContains(t, err, `no key "missing"`)
+
+	_, err = resolver.Resolve(ctx, "vault:secret/data/absent#key")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = resolver.Resolve(ctx, "aws-sm:favorites#key")
+	assert.ErrorContains(t, err, "aws-sm is not configured")
+}
+
+func TestSecrets_AWSSecretsManagerSignsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") ||
+			r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&body)
+		switch body.SecretId {
+		case "favorites/prod":
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"jwt_secret":"from-aws","port":5432}`})
+		case "favorites/plain":
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": "plain-value"})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+		}
+	}))
+	defer server.Close()
+
+	provider := secrets.NewAWSSecretsManager(httpclient.NewDefault(), "eu-west-1", server.URL, awsv4.Credentials{
+		AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session",
+	})
+	resolver := secrets.NewResolver(map[string]secrets.Provider{secrets.SchemeAWS: provider}, time.Minute)
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, "aws-sm:favorites/prod#jwt_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "from-aws", value)
+
+	value, err = resolver.Resolve(ctx, "aws-sm:favorites/prod#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	value, err = resolver.Resolve(ctx, "aws-sm:favorites/plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-value", value)
+
+	_, err = resolver.Resolve(ctx, "aws-sm:favorites/prod")
+	assert.ErrorContains(t, err, "several keys")
+
+	_, err = resolver.Resolve(ctx, "aws-sm:favorites/absent")
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+}
+
+func TestConfig_LoadResolvesSecretReferences(t *testing.T) {
+	var requests int32
+	server := newVaultServer(&requests)
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("JWT_SECRET", "vault:secret/data/favorites#jwt_secret")
+	t.Setenv("AUTH_LOGIN_PASSWORDS", "alice=vault:secret/data/favorites#db_password,bob=plain")
+	t.Setenv("SCIM_TOKEN", "vault:kv/favorites#scim_token")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", cfg.JWTSecret)
+	assert.Equal(t, map[string]string{"alice": "s3cret", "bob": "plain"}, cfg.LoginPasswords)
+	assert.Equal(t, "v1-token", cfg.SCIMToken)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// Failures name the setting but not the secret
+	t.Setenv("VAULT_TOKEN", "wrong")
+	_, err = config.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resolving JWT_SECRET")
+	assert.NotContains(t, err.Error(), "from-vault")
+}
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/seed"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedGenerator_IsDeterministic(t *testing.T) {
+	opts := seed.Options{Users: 5, Assets: 12, FavoritesPerUser: 4, Seed: 42}
+
+	favoriteIDs := func() []string {
+		repo := memory.NewRepository()
+		result, err := seed.NewGenerator(repo, logger.NewLogger()).Generate(opts)
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Users)
+		assert.Equal(t, 12, result.Assets)
+		assert.Equal(t, 20, result.Favorites)
+
+		favorites, err := repo.GetUserFavorites("user3", domain.FavoritesQuery{})
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(favorites))
+		for _, favorite := range favorites {
+			ids = append(ids, favorite.AssetID)
+		}
+		return ids
+	}
+
+	first := favoriteIDs()
+	assert.Len(t, first, 4)
+	assert.ElementsMatch(t, first, favoriteIDs())
+}
+
+func TestSeedGenerator_LeavesExistingDataAlone(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "real@corp.com", "Real Person")))
+	require.NoError(t, repo.CreateAsset(domain.NewChart("chart1", "Real chart", "x", "y", "", nil)))
+
+	// One asset per type, so chart1 is the only chart and every favorite
+	// of user1 includes it
+	result, err := seed.NewGenerator(repo, logger.NewLogger()).Generate(seed.Options{Users: 2, Assets: 3, FavoritesPerUser: 3, Seed: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Users, "existing users are not counted")
+	assert.Equal(t, 2, result.Assets, "existing assets are not counted")
+
+	user, err := repo.GetUser("user1")
+	require.NoError(t, err)
+	assert.Equal(t, "real@corp.com", user.Email)
+	assert.Equal(t, "Real Person", user.Name)
+
+	favorites, err := repo.GetUserFavorites("user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	for _, favorite := range favorites {
+		if favorite.AssetID == "chart1" {
+			assert.Equal(t, "Real chart", favorite.Asset.(*domain.Chart).Title)
+		}
+	}
+	assert.Len(t, favorites, 3)
+}
+
+func TestSeedProfiles_Builtin(t *testing.T) {
+	profiles, err := seed.BuiltinProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"demo", "empty", "load-test"}, profiles.Names())
+
+	demo, err := profiles.Get("demo")
+	require.NoError(t, err)
+	assert.Equal(t, seed.Options{Users: 3, Assets: 9, FavoritesPerUser: 3, Seed: 1}, demo.Options)
+
+	empty, err := profiles.Get("empty")
+	require.NoError(t, err)
+	assert.Zero(t, empty.Scale(10).Users)
+
+	_, err = profiles.Get("missing")
+	assert.ErrorContains(t, err, "demo, empty, load-test")
+}
+
+func TestSeedProfiles_Scale(t *testing.T) {
+	profile := seed.Profile{Options: seed.Options{Users: 10, Assets: 3, FavoritesPerUser: 2, Seed: 5}}
+
+	assert.Equal(t, seed.Options{Users: 25, Assets: 8, FavoritesPerUser: 2, Seed: 5}, profile.Scale(2.5))
+	assert.Equal(t, seed.Options{Users: 1, Assets: 1, FavoritesPerUser: 2, Seed: 5}, profile.Scale(0.01))
+}
+
+func TestSeedProfiles_LoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "demo.json"), []byte(`{"users": 1, "assets": 2, "favorites_per_user": 1, "seed": 9}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staging.json"), []byte(`{"description": "Staging", "users": 20, "assets": 40}`), 0o644))
+
+	profiles, err := seed.LoadProfiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"demo", "empty", "load-test", "staging"}, profiles.Names())
+	assert.Equal(t, 1, profiles["demo"].Users)
+	assert.Equal(t, "Staging", profiles["staging"].Description)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"users": -1}`), 0o644))
+	_, err = seed.LoadProfiles(dir)
+	assert.Error(t, err)
+}
+
+func TestGenerateSeedData_Profile(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	profiles, err := seed.BuiltinProfiles()
+	require.NoError(t, err)
+
+	h := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithSeedGenerator(seed.NewGenerator(repo, log)), handler.WithSeedProfiles(profiles), handler.WithAdminAPIKey(testAdminKey))
+	router := asAdmin(h.SetupRoutes())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/seed", strings.NewReader(`{"profile": "demo", "scale": 2}`)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"users":6`)
+	assert.Contains(t, rec.Body.String(), `"assets":18`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/seed", strings.NewReader(`{"profile": "missing"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/seed/profiles", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"load-test"`)
+}
+
+func TestGenerateSeedData_CapsTotalFavorites(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	h := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithSeedGenerator(seed.NewGenerator(repo, log)), handler.WithAdminAPIKey(testAdminKey))
+	router := asAdmin(h.SetupRoutes())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/seed",
+		strings.NewReader(`{"users": 100000, "assets": 100000, "favorites_per_user": 100000}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "favorites_per_user")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/seed",
+		strings.NewReader(`{"users": 100000, "assets": 1, "favorites_per_user": 100000}`)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"favorites":100000`)
+}
@@ -0,0 +1,218 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/serializer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toJSONMap renders a serialized value the way clients see it
+func toJSONMap(t *testing.T, value interface{}) map[string]interface{} {
+	raw, err := json.Marshal(value)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	return decoded
+}
+
+func TestSerializerRegistry_Versions(t *testing.T) {
+	registry := serializer.DefaultRegistry()
+	assert.Equal(t, []serializer.Version{serializer.V1, serializer.V2}, registry.Versions())
+	assert.Equal(t, serializer.V2, serializer.DefaultVersion)
+
+	registry.Register("v3", domain.AssetTypeInsight, func(asset domain.Asset) interface{} {
+		return map[string]string{"id": asset.GetID(), "format": "v3"}
+	})
+	insight := domain.NewInsight("insight1", "Growth", "", nil, "")
+	chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)
+
+	tests := []struct {
+		name       string
+		version    serializer.Version
+		registered bool
+		asset      domain.Asset
+		want       map[string]interface{}
+	}{
+		{"v1 insight", serializer.V1, true, insight, map[string]interface{}{"id": "insight1", "content": "Growth"}},
+		{"v2 insight", serializer.V2, true, insight, map[string]interface{}{"id": "insight1", "content": "Growth"}},
+		{"added version", "v3", true, insight, map[string]interface{}{"id": "insight1", "format": "v3"}},
+		{"type missing from version falls back to the domain struct", "v3", true, chart, map[string]interface{}{"id": "chart1", "title": "Sales"}},
+		{"unknown version falls back to the domain struct", "v9", false, chart, map[string]interface{}{"id": "chart1", "title": "Sales"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.registered, registry.HasVersion(tt.version))
+			view := toJSONMap(t, registry.SerializeAsset(tt.version, tt.asset))
+			for member, value := range tt.want {
+				assert.Equal(t, value, view[member], member)
+			}
+		})
+	}
+
+	assert.Nil(t, registry.SerializeAsset(serializer.V2, nil))
+
+	// Decorators see every serialized asset, without changing the registry they came from
+	decorated := registry.WithDecorator(func(view interface{}) interface{} {
+		return map[string]interface{}{"decorated": true, "view": view}
+	})
+	assert.Equal(t, true, toJSONMap(t, decorated.SerializeAsset(serializer.V2, insight))["decorated"])
+	assert.NotContains(t, toJSONMap(t, registry.SerializeAsset(serializer.V2, insight)), "decorated")
+}
+
+func TestSerializer_V1SlimsCharts(t *testing.T) {
+	registry := serializer.DefaultRegistry()
+	points := []domain.ChartDataPoint{{X: "Jan", Y: 1}, {X: "Feb", Y: 2}, {X: "Mar", Y: 3}}
+
+	tests := []struct {
+		name      string
+		version   serializer.Version
+		asset     domain.Asset
+		hasData   bool
+		dataCount interface{}
+	}{
+		{"v1 chart", serializer.V1, domain.NewChart("chart1", "Sales", "Month", "Revenue", "", points), false, float64(3)},
+		{"v1 empty chart", serializer.V1, domain.NewChart("chart2", "Empty", "X", "Y", "", nil), false, float64(0)},
+		{"v2 chart", serializer.V2, domain.NewChart("chart1", "Sales", "Month", "Revenue", "", points), true, nil},
+		{"v1 insight", serializer.V1, domain.NewInsight("insight1", "Growth", "", nil, ""), false, nil},
+		{"v1 audience", serializer.V1, domain.NewAudience("audience1", "Gamers"), false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := toJSONMap(t, registry.SerializeAsset(tt.version, tt.asset))
+			assert.Equal(t, tt.asset.GetID(), view["id"])
+			assert.Equal(t, string(tt.asset.GetType()), view["type"])
+
+			_, hasData := view["data"]
+			assert.Equal(t, tt.hasData, hasData)
+			assert.Equal(t, tt.dataCount, view["data_point_count"])
+			if chart, ok := tt.asset.(*domain.Chart); ok {
+				assert.Equal(t, chart.Title, view["title"])
+				assert.Equal(t, chart.XAxisTitle, view["x_axis_title"])
+				assert.Equal(t, chart.YAxisTitle, view["y_axis_title"])
+			}
+		})
+	}
+}
+
+func TestSerializer_FavoritesFollowTheVersion(t *testing.T) {
+	registry := serializer.DefaultRegistry()
+	addedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", []domain.ChartDataPoint{{X: "Jan", Y: 1}})
+	favorites := []*domain.UserFavorite{{UserID: "user1", AssetID: "chart1", Asset: chart, AddedAt: addedAt, UpdatedAt: addedAt}}
+
+	tests := []struct {
+		version serializer.Version
+		hasData bool
+	}{
+		{serializer.V1, false},
+		{serializer.V2, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.version), func(t *testing.T) {
+			views := registry.SerializeFavorites(tt.version, favorites)
+			require.Len(t, views, 1)
+			view := toJSONMap(t, views[0])
+			assert.Equal(t, "user1", view["user_id"])
+			assert.Equal(t, "chart1", view["asset_id"])
+			assert.Equal(t, "2025-03-01T12:00:00Z", view["added_at"])
+
+			asset := view["asset"].(map[string]interface{})
+			_, hasData := asset["data"]
+			assert.Equal(t, tt.hasData, hasData)
+		})
+	}
+
+	assert.Empty(t, registry.SerializeFavorites(serializer.V2, nil))
+}
+
+func TestSerializer_FavoritesJSONAPIDocument(t *testing.T) {
+	registry := serializer.DefaultRegistry()
+	addedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	chart := domain.NewChart("chart1", "Sales", "Month", "Revenue", "", []domain.ChartDataPoint{{X: "Jan", Y: 1}})
+	favorite := func(userID string, asset domain.Asset, assetID string) *domain.UserFavorite {
+		return &domain.UserFavorite{UserID: userID, AssetID: assetID, Asset: asset, AddedAt: addedAt, UpdatedAt: addedAt}
+	}
+	noted := favorite("user1", chart, "chart1")
+	noted.Note, noted.Pinned, noted.Position = "Read later", true, 2
+
+	tests := []struct {
+		name      string
+		version   serializer.Version
+		favorites []*domain.UserFavorite
+		included  []serializer.JSONAPIIdentifier
+		chartData bool
+	}{
+		{"empty", serializer.V2, nil, nil, false},
+		{
+			"assets shared by favorites are included once", serializer.V2,
+			[]*domain.UserFavorite{noted, favorite("user2", chart, "chart1")},
+			[]serializer.JSONAPIIdentifier{{Type: "charts", ID: "chart1"}}, true,
+		},
+		{
+			"included assets follow the version", serializer.V1,
+			[]*domain.UserFavorite{noted},
+			[]serializer.JSONAPIIdentifier{{Type: "charts", ID: "chart1"}}, false,
+		},
+		{
+			"favorites without an asset keep the relationship", serializer.V2,
+			[]*domain.UserFavorite{favorite("user1", nil, "ghost")},
+			nil, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := registry.FavoritesJSONAPIDocument(tt.version, tt.favorites)
+			require.NoError(t, err)
+
+			data := document.Data.([]serializer.JSONAPIResource)
+			require.Len(t, data, len(tt.favorites))
+			assert.Equal(t, len(tt.favorites), document.Meta["count"])
+			for i, resource := range data {
+				fav := tt.favorites[i]
+				assert.Equal(t, "favorites", resource.Type)
+				assert.Equal(t, fav.UserID+":"+fav.AssetID, resource.ID)
+				assert.Equal(t, serializer.JSONAPIIdentifier{Type: "users", ID: fav.UserID}, resource.Relationships["user"].Data)
+				assert.Equal(t, fav.AssetID, resource.Relationships["asset"].Data.ID)
+				assert.Equal(t, addedAt, resource.Attributes["added_at"])
+			}
+
+			identifiers := make([]serializer.JSONAPIIdentifier, 0, len(document.Included))
+			for _, resource := range document.Included {
+				identifiers = append(identifiers, serializer.JSONAPIIdentifier{Type: resource.Type, ID: resource.ID})
+				assert.NotContains(t, resource.Attributes, "id", "identity lives on the resource object")
+				assert.NotContains(t, resource.Attributes, "type")
+				_, hasData := resource.Attributes["data"]
+				assert.Equal(t, tt.chartData, hasData)
+			}
+			assert.ElementsMatch(t, tt.included, identifiers)
+		})
+	}
+
+	// Optional favorite members appear only when set
+	document, err := registry.FavoritesJSONAPIDocument(serializer.V2, []*domain.UserFavorite{noted, favorite("user2", chart, "chart1")})
+	require.NoError(t, err)
+	data := document.Data.([]serializer.JSONAPIResource)
+	assert.Equal(t, "Read later", data[0].Attributes["note"])
+	assert.Equal(t, true, data[0].Attributes["pinned"])
+	assert.Equal(t, 2, data[0].Attributes["position"])
+	for _, member := range []string{"note", "pinned", "position"} {
+		assert.NotContains(t, data[1].Attributes, member)
+	}
+
+	single, err := registry.FavoriteJSONAPIDocument(serializer.V2, noted)
+	require.NoError(t, err)
+	assert.Equal(t, "user1:chart1", single.Data.(serializer.JSONAPIResource).ID)
+	assert.Len(t, single.Included, 1)
+	assert.Nil(t, single.Meta)
+
+	errorDocument := serializer.JSONAPIErrorDocument(404, "favorite_not_found", "Favorite not found", map[string]string{"asset_id": "chart1"})
+	assert.Nil(t, errorDocument.Data)
+	require.Len(t, errorDocument.Errors, 1)
+	assert.Equal(t, serializer.JSONAPIError{Status: "404", Code: "favorite_not_found", Title: "Favorite not found", Meta: map[string]string{"asset_id": "chart1"}}, errorDocument.Errors[0])
+}
@@ -66,3 +66,70 @@ func TestFavoritesService_GetUserFavorites(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, favorites, 2)
 }
+
+func TestFavoritesService_ListUserFavoritesByType(t *testing.T) {
+	// Setup
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	svc := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+
+	user := domain.NewUser("user1", "test@example.com", "Test User")
+	require.NoError(t, repo.CreateUser(user))
+
+	require.NoError(t, svc.AddFavorite(ctx, "user1", domain.NewChart("chart1", "Chart 1", "X", "Y", "", nil)))
+	require.NoError(t, svc.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Insight", "", nil, "")))
+	require.NoError(t, svc.AddFavorite(ctx, "user1", domain.NewChart("chart2", "Chart 2", "X", "Y", "", nil)))
+
+	// Charts page independently of other types
+	charts, err := svc.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{Type: domain.AssetTypeChart, Limit: 1})
+	assert.NoError(t, err)
+	require.Len(t, charts, 1)
+	assert.Equal(t, "chart1", charts[0].AssetID)
+
+	charts, err = svc.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{Type: domain.AssetTypeChart, Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	require.Len(t, charts, 1)
+	assert.Equal(t, "chart2", charts[0].AssetID)
+
+	// Unknown types are rejected
+	_, err = svc.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{Type: "video", Limit: 10})
+	assert.Equal(t, domain.ErrInvalidAssetType, err)
+}
+
+func TestAssetService_DeletePolicies(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger()
+
+	setup := func(policy service.DeletePolicy) (*memory.Repository, *service.AssetService, *service.FavoritesService) {
+		repo := memory.NewRepository()
+		require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+		favorites := service.NewFavoritesService(repo, log)
+		require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewChart("chart1", "Chart", "X", "Y", "", nil)))
+		return repo, service.NewAssetService(repo, policy, log), favorites
+	}
+
+	// Block refuses while the asset is favorited
+	repo, assets, favorites := setup(service.DeleteBlock)
+	assert.ErrorIs(t, assets.DeleteAsset(ctx, "chart1"), domain.ErrAssetInUse)
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "chart1"))
+	require.NoError(t, assets.DeleteAsset(ctx, "chart1"))
+	_, err := repo.GetAsset("chart1")
+	assert.Equal(t, domain.ErrAssetNotFound, err)
+
+	// Orphan keeps the favorite but marks the asset deleted
+	_, assets, favorites = setup(service.DeleteOrphan)
+	require.NoError(t, assets.DeleteAsset(ctx, "chart1"))
+	list, err := favorites.GetUserFavorites(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotNil(t, list[0].Asset.GetDeletedAt())
+	assert.ErrorIs(t, assets.DeleteAsset(ctx, "chart1"), domain.ErrAssetDeleted)
+
+	// Cascade removes the favorites with the asset
+	_, assets, favorites = setup(service.DeleteCascade)
+	require.NoError(t, assets.DeleteAsset(ctx, "chart1"))
+	count, err := favorites.GetFavoriteCount(ctx, "user1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sharded"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShards(n int) []sharded.Shard {
+	shards := make([]sharded.Shard, n)
+	for i := range shards {
+		shards[i] = sharded.Shard{Name: fmt.Sprintf("shard%d", i), Repo: memory.NewRepository()}
+	}
+	return shards
+}
+
+func TestShardedRepository_RoutesUsersByConsistentHash(t *testing.T) {
+	shards := newShards(3)
+	repo, err := sharded.New(shards)
+	require.NoError(t, err)
+
+	// The catalog is copied to every shard
+	chart := domain.NewChart("chart1", "Chart", "X", "Y", "", nil)
+	require.NoError(t, repo.CreateAsset(chart))
+	assert.ErrorIs(t, repo.CreateAsset(chart), domain.ErrAssetAlreadyExists)
+	for _, shard := range shards {
+		_, err := shard.Repo.GetAsset("chart1")
+		assert.NoError(t, err)
+	}
+
+	perShard := map[string]int{}
+	for i := 0; i < 300; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		require.NoError(t, repo.CreateUser(domain.NewUser(userID, "", "")))
+		require.NoError(t, repo.AddFavorite(userID, chart))
+		perShard[repo.ShardFor(userID)]++
+	}
+
+	// Users spread over every shard and live only on theirs
+	for _, shard := range shards {
+		assert.Greater(t, perShard[shard.Name], 50, shard.Name)
+	}
+	home := repo.ShardFor("user7")
+	for _, shard := range shards {
+		_, err := shard.Repo.GetUser("user7")
+		assert.Equal(t, shard.Name == home, err == nil, shard.Name)
+	}
+
+	isFavorite, err := repo.IsFavorite("user7", "chart1")
+	require.NoError(t, err)
+	assert.True(t, isFavorite)
+	references, err := repo.CountAssetReferences("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, 300, references)
+	favoriters, err := repo.ListAssetFavoriters("chart1")
+	require.NoError(t, err)
+	require.Len(t, favoriters, 300)
+	assert.True(t, sort.StringsAreSorted(favoriters))
+
+	ids, err := repo.ListUserIDs("user1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user10", "user100", "user101"}, ids)
+
+	stats, err := repo.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, 300, stats.Users)
+	assert.Equal(t, 1, stats.Assets)
+
+	// Deleting an asset removes it, and its favorites, everywhere
+	require.NoError(t, repo.DeleteAsset("chart1"))
+	references, err = repo.CountAssetReferences("chart1")
+	require.NoError(t, err)
+	assert.Zero(t, references)
+
+	// Adding a shard moves only the users it takes over
+	grown, err := sharded.New(append(newShards(3), sharded.Shard{Name: "shard3", Repo: memory.NewRepository()}))
+	require.NoError(t, err)
+	moved := 0
+	for i := 0; i < 300; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		if before, after := repo.ShardFor(userID), grown.ShardFor(userID); before != after {
+			assert.Equal(t, "shard3", after)
+			moved++
+		}
+	}
+	assert.InDelta(t, 75, moved, 35)
+
+	_, err = sharded.New([]sharded.Shard{{Name: "a", Repo: memory.NewRepository()}, {Name: "a", Repo: memory.NewRepository()}})
+	assert.Error(t, err)
+
+	var _ repository.UserLister = repo
+}
@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/sharing"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharingStore(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	store := sharing.NewStore(fake)
+
+	first, err := store.Create("user1", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Token)
+	userID, err := store.Resolve(first.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+
+	// A new link replaces the old one
+	expiry := fake.Now().Add(time.Hour)
+	second, err := store.Create("user1", &expiry)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token, second.Token)
+	_, err = store.Resolve(first.Token)
+	assert.ErrorIs(t, err, domain.ErrShareNotFound)
+	shown, err := store.Get("user1")
+	require.NoError(t, err)
+	assert.Empty(t, shown.Token, "the token is only returned on creation")
+	assert.Equal(t, &expiry, shown.ExpiresAt)
+
+	fake.Advance(time.Hour)
+	_, err = store.Resolve(second.Token)
+	assert.ErrorIs(t, err, domain.ErrShareNotFound, "expired")
+	_, err = store.Get("user1")
+	assert.ErrorIs(t, err, domain.ErrShareNotFound)
+
+	past := fake.Now()
+	_, err = store.Create("user1", &past)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	third, err := store.Create("user1", nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Revoke("user1"))
+	_, err = store.Resolve(third.Token)
+	assert.ErrorIs(t, err, domain.ErrShareNotFound)
+	assert.ErrorIs(t, store.Revoke("user1"), domain.ErrShareNotFound)
+}
+
+func TestHandler_SharedFavorites(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, log)
+	ctx := context.Background()
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Shared", "", nil, "")))
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "private"))
+
+	issuer := auth.NewIssuer("secret", time.Minute, time.Hour)
+	token, err := issuer.Issue("user1", "")
+	require.NoError(t, err)
+	routes := handler.NewHandler(favorites, log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithSharing(sharing.NewStore(nil)),
+	).SetupRoutes()
+
+	send := func(method, path, bearer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/users/user1/favorites/share", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/api/users/user1/favorites/share", token.AccessToken, `{"expires_at": "soon"}`).Code)
+
+	rec := send(http.MethodPost, "/api/users/user1/favorites/share", token.AccessToken, "")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Data handler.ShareResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Data.Token)
+	assert.Equal(t, "/api/shared/"+created.Data.Token, created.Data.URL)
+
+	// The shared view needs no token and leaves out the user ID and notes
+	rec = send(http.MethodGet, created.Data.URL, "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "private")
+	assert.NotContains(t, rec.Body.String(), "user1")
+	var shared struct {
+		Data       []handler.SharedFavoriteView `json:"data"`
+		Pagination domain.Pagination            `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &shared))
+	require.Len(t, shared.Data, 1)
+	assert.Equal(t, "insight1", shared.Data[0].AssetID)
+	assert.Equal(t, 1, shared.Pagination.Total)
+
+	// Shared views are read only
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodPost, created.Data.URL, "", "").Code)
+
+	rec = send(http.MethodGet, "/api/users/user1/favorites/share", token.AccessToken, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), created.Data.Token)
+
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/users/user1/favorites/share", token.AccessToken, "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, created.Data.URL, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/shared/unknown", "", "").Code)
+}
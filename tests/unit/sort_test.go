@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/repository/sqlstore"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedSortFavorites adds favorites a minute apart, then updates the first
+func seedSortFavorites(t *testing.T, repo repository.FavoritesRepository, fake *clock.Fake) {
+	assets := []domain.Asset{
+		domain.NewChart("chart1", "zebra crossings", "X", "Y", "", nil),
+		domain.NewAudience("audience1", "Millennials"),
+		domain.NewInsight("insight1", "apples are popular", "", nil, ""),
+		domain.NewChart("chart2", "Bananas", "X", "Y", "", nil),
+	}
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	for _, asset := range assets {
+		require.NoError(t, repo.CreateAsset(asset))
+		require.NoError(t, repo.AddFavorite("user1", asset))
+		fake.Advance(time.Minute)
+	}
+	require.NoError(t, repo.UpdateFavoriteAsset("user1", "chart1", assets[0]))
+}
+
+func TestRepositories_SortFavorites(t *testing.T) {
+	cases := []struct {
+		query domain.FavoritesQuery
+		want  []string
+	}{
+		{domain.FavoritesQuery{}, []string{"chart1", "audience1", "insight1", "chart2"}},
+		{domain.FavoritesQuery{Descending: true}, []string{"chart2", "insight1", "audience1", "chart1"}},
+		{domain.FavoritesQuery{Sort: domain.SortUpdatedAt, Descending: true}, []string{"chart1", "chart2", "insight1", "audience1"}},
+		{domain.FavoritesQuery{Sort: domain.SortType}, []string{"audience1", "chart1", "chart2", "insight1"}},
+		{domain.FavoritesQuery{Sort: domain.SortTitle}, []string{"insight1", "chart2", "audience1", "chart1"}},
+		{domain.FavoritesQuery{Sort: domain.SortTitle, Descending: true, Limit: 2, Offset: 1}, []string{"audience1", "chart2"}},
+		{domain.FavoritesQuery{Sort: domain.SortTitle, Type: domain.AssetTypeChart}, []string{"chart2", "chart1"}},
+	}
+
+	backends := map[string]func(c clock.Clock) repository.FavoritesRepository{
+		"memory": func(c clock.Clock) repository.FavoritesRepository {
+			return memory.NewRepositoryWithOptions(memory.Options{Clock: c})
+		},
+		"sqlite": func(c clock.Clock) repository.FavoritesRepository {
+			repo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"), sqlstore.WithClock(c))
+			require.NoError(t, err)
+			t.Cleanup(func() { repo.Close() })
+			return repo
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			fake := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			repo := open(fake)
+			seedSortFavorites(t, repo, fake)
+
+			for _, tc := range cases {
+				favorites, err := repo.GetUserFavorites("user1", tc.query)
+				require.NoError(t, err)
+				ids := make([]string, 0, len(favorites))
+				for _, favorite := range favorites {
+					ids = append(ids, favorite.AssetID)
+				}
+				assert.Equal(t, tc.want, ids, "sort %q descending %t", tc.query.Sort, tc.query.Descending)
+			}
+		})
+	}
+}
+
+func TestHandler_SortFavorites(t *testing.T) {
+	log := logger.NewLogger()
+	fake := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	repo := memory.NewRepositoryWithOptions(memory.Options{Clock: fake})
+	seedSortFavorites(t, repo, fake)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log).SetupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/users/user1/favorites?sort=added_at&order=desc&limit=1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"asset_id":"chart2"`)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), `"asset_id"`))
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites?sort=popularity").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/users/user1/favorites?order=newest").Code)
+}
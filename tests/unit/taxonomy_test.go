@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/taxonomy"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomy_ParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"de-at", "en", "fr"}, taxonomy.ParseAcceptLanguage("fr;q=0.5, de-AT, en;q=0.8, *;q=0.1, es;q=0"))
+	assert.Empty(t, taxonomy.ParseAcceptLanguage(""))
+}
+
+func TestTaxonomy_NameFallsBackToBaseLanguage(t *testing.T) {
+	catalog := taxonomy.NewCatalog()
+	_, err := catalog.Set(taxonomy.KindCategory, "behavior", map[string]string{"EN": "Behavior", "de": "Verhalten", "de-CH": "Verhalte"})
+	require.NoError(t, err)
+
+	name, ok := catalog.Name(taxonomy.KindCategory, "behavior", []string{"de-at", "en"})
+	assert.True(t, ok)
+	assert.Equal(t, "Verhalten", name)
+	name, _ = catalog.Name(taxonomy.KindCategory, "behavior", []string{"de-ch"})
+	assert.Equal(t, "Verhalte", name)
+	_, ok = catalog.Name(taxonomy.KindCategory, "behavior", []string{"fr"})
+	assert.False(t, ok)
+
+	_, err = catalog.Set("colors", "red", map[string]string{"en": "Red"})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	assert.ErrorIs(t, catalog.Delete(taxonomy.KindTag, "missing"), domain.ErrTaxonomyKeyNotFound)
+}
+
+func TestTaxonomy_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taxonomy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"kind": "tags", "key": "social", "names": {"de": "Soziale Medien"}}]`), 0o600))
+
+	catalog := taxonomy.NewCatalog()
+	require.NoError(t, catalog.LoadFile(path))
+	require.Len(t, catalog.List(), 1)
+	assert.Equal(t, "social", catalog.List()[0].Key)
+}
+
+func TestHandler_LocalizedTaxonomy(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	svc := service.NewFavoritesService(repo, log)
+	require.NoError(t, svc.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Growth", "", []string{"social", "demographics"}, "behavior")))
+	routes := asAdmin(handler.NewHandler(svc, log, handler.WithTaxonomy(taxonomy.NewCatalog()), handler.WithAdminAPIKey(testAdminKey)).SetupRoutes())
+
+	do := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/api/admin/taxonomy/categories/behavior", `{"names": {"en": "Behavior", "de": "Verhalten"}}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = do(http.MethodPut, "/api/admin/taxonomy/tags/social", `{"names": {"de": "Soziale Medien"}}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/api/admin/taxonomy/colors/red", `{"names": {"en": "Red"}}`, nil).Code)
+
+	german := http.Header{"Accept-Language": {"de-DE,de;q=0.9,en;q=0.5"}}
+	rec = do(http.MethodGet, "/api/users/user1/favorites?type=insight", "", german)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept-Language")
+	body := rec.Body.String()
+	assert.Contains(t, body, `"category":"behavior"`)
+	assert.Contains(t, body, `"category_name":"Verhalten"`)
+	assert.Contains(t, body, `"tag_names":{"social":"Soziale Medien"}`)
+	assert.Contains(t, body, `"tags":["demographics","social"]`)
+
+	jsonAPI := http.Header{"Accept-Language": {"en"}, "Accept": {"application/vnd.api+json"}}
+	rec = do(http.MethodGet, "/api/users/user1/favorites", "", jsonAPI)
+	assert.Contains(t, rec.Body.String(), `"category_name":"Behavior"`)
+	assert.NotContains(t, rec.Body.String(), "tag_names")
+
+	rec = do(http.MethodGet, "/api/users/user1/favorites", "", nil)
+	assert.NotContains(t, rec.Body.String(), "category_name")
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/api/admin/taxonomy/categories/behavior", "", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/admin/taxonomy/categories/behavior", "", nil).Code)
+	rec = do(http.MethodGet, "/api/admin/taxonomy", "", nil)
+	assert.Contains(t, rec.Body.String(), `"key":"social"`)
+	assert.NotContains(t, rec.Body.String(), "behavior")
+}
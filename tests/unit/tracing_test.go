@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampledParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+const unsampledParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+
+func TestParseTraceSamplingPolicy(t *testing.T) {
+	policy, err := handler.ParseTraceSamplingPolicy(0.1, map[string]string{"assets.list": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, policy.SampleRate)
+	assert.Equal(t, map[string]float64{"assets.list": 1}, policy.RouteRates)
+
+	_, err = handler.ParseTraceSamplingPolicy(1.5, nil)
+	assert.Error(t, err)
+	_, err = handler.ParseTraceSamplingPolicy(0, map[string]string{"assets.list": "always"})
+	assert.Error(t, err)
+}
+
+func TestHandler_TraceSampling(t *testing.T) {
+	repo := memory.NewRepository()
+	log := logger.NewLogger()
+	policy, err := handler.ParseTraceSamplingPolicy(0, map[string]string{"favorites.list": "1"})
+	require.NoError(t, err)
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAdminAPIKey("admin-secret"),
+		handler.WithTraceSampling(policy),
+	).SetupRoutes()
+
+	sampled := func(method, path string, header http.Header) bool {
+		req := httptest.NewRequest(method, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		sc, ok := tracing.ParseTraceParent(rec.Header().Get(tracing.TraceParentHeader))
+		require.True(t, ok)
+		return sc.Sampled
+	}
+
+	assert.True(t, sampled(http.MethodGet, "/api/users/user1/favorites", nil), "the route is always sampled")
+	assert.False(t, sampled(http.MethodGet, "/api/users/user1/favorites/count", nil), "other routes use the default rate")
+
+	// Callers' decisions are kept
+	assert.True(t, sampled(http.MethodGet, "/api/users/user1/favorites/count", http.Header{"Traceparent": {sampledParent}}))
+	assert.False(t, sampled(http.MethodGet, "/api/users/user1/favorites", http.Header{"Traceparent": {unsampledParent}}))
+
+	// Admins may force a trace; others may not
+	forced := http.Header{"X-Force-Trace": {"true"}, "Traceparent": {unsampledParent}}
+	assert.False(t, sampled(http.MethodGet, "/api/users/user1/favorites/count", forced))
+	forced.Set("X-Admin-Key", "admin-secret")
+	assert.True(t, sampled(http.MethodGet, "/api/users/user1/favorites/count", forced))
+
+	// The policy can be changed at runtime
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/tracing", strings.NewReader(`{"sample_rate": 1, "route_rates": {"favorites.list": 0}}`))
+	req.Header.Set("X-Admin-Key", "admin-secret")
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, sampled(http.MethodGet, "/api/users/user1/favorites/count", nil))
+	assert.False(t, sampled(http.MethodGet, "/api/users/user1/favorites", nil))
+
+	req = httptest.NewRequest(http.MethodPut, "/api/admin/tracing", strings.NewReader(`{"sample_rate": 2}`))
+	req.Header.Set("X-Admin-Key", "admin-secret")
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository"
+	"gwi-favorites-service/internal/repository/embedded"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/repository/sqlite"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferFavorites_Backends(t *testing.T) {
+	sqliteRepo, err := sqlite.Open(filepath.Join(t.TempDir(), "favorites.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	backends := map[string]repository.FavoritesRepository{
+		"memory": memory.NewRepository(),
+		"sqlite": sqliteRepo,
+	}
+	ctx := context.Background()
+
+	for name, repo := range backends {
+		favorites := service.NewFavoritesService(repo, logger.NewLogger())
+		require.True(t, favorites.SupportsTransfers(), name)
+		for _, id := range []string{"old", "new", "copy"} {
+			require.NoError(t, repo.CreateUser(domain.NewUser(id, "", "")), name)
+		}
+		for _, id := range []string{"insight1", "insight2", "insight3"} {
+			require.NoError(t, favorites.AddFavorite(ctx, "old", domain.NewInsight(id, "Insight", "", nil, "")), name)
+		}
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "old", "insight1", "from old"), name)
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "old", "insight2", "from old"), name)
+		require.NoError(t, favorites.AddFavorite(ctx, "new", domain.NewInsight("insight2", "Insight", "", nil, "")), name)
+		require.NoError(t, favorites.UpdateFavoriteNote(ctx, "new", "insight2", "kept"), name)
+
+		// A selection naming a non-favorite changes nothing
+		_, err := favorites.TransferFavorites(ctx, "old", "copy", []string{"insight1", "missing"}, false)
+		assert.ErrorIs(t, err, domain.ErrFavoriteNotFound, name)
+		count, err := repo.GetFavoriteCount("copy")
+		require.NoError(t, err, name)
+		assert.Zero(t, count, name)
+
+		result, err := favorites.TransferFavorites(ctx, "old", "copy", []string{"insight3", "insight1"}, false)
+		require.NoError(t, err, name)
+		assert.Equal(t, repository.TransferResult{Transferred: []string{"insight1", "insight3"}, Skipped: []string{}}, result, name)
+		count, err = repo.GetFavoriteCount("old")
+		require.NoError(t, err, name)
+		assert.Equal(t, 3, count, "copying keeps the source's favorites")
+
+		result, err = favorites.TransferFavorites(ctx, "old", "new", nil, true)
+		require.NoError(t, err, name)
+		assert.Equal(t, []string{"insight1", "insight3"}, result.Transferred, name)
+		assert.Equal(t, []string{"insight2"}, result.Skipped, name)
+		count, err = repo.GetFavoriteCount("old")
+		require.NoError(t, err, name)
+		assert.Zero(t, count, "moving empties the source")
+
+		moved, err := repo.GetUserFavorites("new", domain.FavoritesQuery{})
+		require.NoError(t, err, name)
+		notes := make(map[string]string)
+		for _, favorite := range moved {
+			notes[favorite.AssetID] = favorite.Note
+		}
+		assert.Equal(t, map[string]string{"insight1": "from old", "insight2": "kept", "insight3": ""}, notes, name)
+
+		_, err = favorites.TransferFavorites(ctx, "old", "ghost", nil, false)
+		assert.ErrorIs(t, err, domain.ErrUserNotFound, name)
+		_, err = favorites.TransferFavorites(ctx, "old", "old", nil, false)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+	}
+}
+
+func TestHandler_TransferFavorites(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	for _, id := range []string{"user1", "user2"} {
+		require.NoError(t, repo.CreateUser(domain.NewUser(id, "", "")))
+	}
+	favorites := service.NewFavoritesService(repo, log)
+	require.NoError(t, favorites.AddFavorite(context.Background(), "user1", domain.NewInsight("insight1", "Insight", "", nil, "")))
+	routes := asAdmin(handler.NewHandler(favorites, log, handler.WithAdminAPIKey(testAdminKey)).SetupRoutes())
+
+	transfer := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/favorites/transfer", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := transfer(`{"from_user_id": "user1", "to_user_id": "user2", "move": true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data repository.TransferResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, []string{"insight1"}, response.Data.Transferred)
+
+	assert.Equal(t, http.StatusNotFound, transfer(`{"from_user_id": "user1", "to_user_id": "user2", "asset_ids": ["insight1"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, transfer(`{"from_user_id": "user2", "to_user_id": "user2"}`).Code)
+
+	// Backends without transfers answer 501
+	boltRepo, err := embedded.Open(filepath.Join(t.TempDir(), "favorites.bolt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { boltRepo.Close() })
+	routes = asAdmin(handler.NewHandler(service.NewFavoritesService(boltRepo, log), log, handler.WithAdminAPIKey(testAdminKey)).SetupRoutes())
+	assert.Equal(t, http.StatusNotImplemented, transfer(`{"from_user_id": "user1", "to_user_id": "user2"}`).Code)
+}
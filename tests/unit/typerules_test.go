@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAssetTypeRules(t *testing.T) {
+	rules, err := service.ParseAssetTypeRules(map[string]string{"contractor": "audience", "guest": "audience| insight"})
+	require.NoError(t, err)
+	assert.False(t, rules.Allows("contractor", domain.AssetTypeAudience))
+	assert.True(t, rules.Allows("contractor", domain.AssetTypeChart))
+	assert.False(t, rules.Allows("guest", domain.AssetTypeInsight))
+	assert.True(t, rules.Allows("admin", domain.AssetTypeAudience))
+	assert.True(t, rules.Allows("", domain.AssetTypeAudience))
+
+	_, err = service.ParseAssetTypeRules(map[string]string{"contractor": "segment"})
+	assert.Error(t, err)
+}
+
+func TestAddFavorite_AssetTypeBlockedForRole(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	rules, err := service.ParseAssetTypeRules(map[string]string{"contractor": "audience"})
+	require.NoError(t, err)
+	favorites := service.NewFavoritesService(repo, logger.NewLogger(), service.WithAssetTypeRules(rules))
+
+	contractor := auth.WithClaims(context.Background(), &auth.Claims{Subject: "user1", Role: "contractor"})
+	err = favorites.AddFavorite(contractor, "user1", domain.NewAudience("audience1", "Sales leads"))
+	assert.ErrorIs(t, err, domain.ErrAssetTypeBlocked)
+	require.NoError(t, favorites.AddFavorite(contractor, "user1", domain.NewChart("chart1", "Sales", "Month", "Revenue", "", nil)))
+
+	// Other roles are unaffected
+	employee := auth.WithClaims(context.Background(), &auth.Claims{Subject: "user1", Role: "employee"})
+	require.NoError(t, favorites.AddFavorite(employee, "user1", domain.NewAudience("audience1", "Sales leads")))
+
+	// Blocking is soft: existing favorites stay visible to the blocked role
+	list, err := favorites.GetUserFavorites(contractor, "user1", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestHandler_AssetTypeBlocked(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	rules, err := service.ParseAssetTypeRules(map[string]string{"contractor": "audience"})
+	require.NoError(t, err)
+
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log, service.WithAssetTypeRules(rules)), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+	).SetupRoutes()
+
+	token, err := auth.SignHS256("secret", auth.Claims{Subject: "user1", Role: "contractor"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/users/user1/favorites",
+		strings.NewReader(`{"id": "audience1", "type": "audience", "description": "Sales leads"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	var resp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "asset_type_blocked", resp.Code)
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/trash"
+	"gwi-favorites-service/pkg/clock"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash_Store(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	store := trash.NewStore(time.Minute, 2, fake)
+	put := func(assetID string) {
+		store.Put(trash.Item{Favorite: domain.UserFavorite{UserID: "user1", AssetID: assetID}, RemovedAt: fake.Now()})
+	}
+
+	put("chart1")
+	put("chart2")
+	put("chart3")
+	// The same asset again replaces its earlier removal
+	put("chart2")
+
+	for _, want := range []string{"chart2", "chart3"} {
+		item, err := store.Pop("user1")
+		require.NoError(t, err)
+		assert.Equal(t, want, item.Favorite.AssetID)
+	}
+	_, err := store.Pop("user1")
+	assert.ErrorIs(t, err, domain.ErrTrashEmpty, "only the last two are kept")
+
+	put("chart4")
+	fake.Advance(time.Minute)
+	_, err = store.Pop("user1")
+	assert.ErrorIs(t, err, domain.ErrTrashEmpty, "expired")
+}
+
+func TestFavoritesService_UndoRemoval(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	ctx := context.Background()
+
+	fake := clock.NewFake(time.Now())
+	favorites := service.NewFavoritesService(repo, log,
+		service.WithClock(fake),
+		service.WithTrash(trash.NewStore(time.Hour, 0, fake)),
+	)
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight2", "Churn", "", nil, "")))
+	require.NoError(t, favorites.UpdateFavoriteNote(ctx, "user1", "insight1", "check Q3"))
+	require.NoError(t, favorites.PinFavorite(ctx, "user1", "insight1", true))
+
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "insight1"))
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "insight2"))
+
+	assetID, err := favorites.UndoRemoval(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "insight2", assetID, "the most recent removal first")
+	assetID, err = favorites.UndoRemoval(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "insight1", assetID)
+
+	restored, err := favorites.ListUserFavorites(ctx, "user1", domain.FavoritesQuery{})
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+	assert.Equal(t, "insight1", restored[0].AssetID, "pinned first")
+	assert.True(t, restored[0].Pinned)
+	assert.Equal(t, "check Q3", restored[0].Note)
+
+	_, err = favorites.UndoRemoval(ctx, "user1")
+	assert.ErrorIs(t, err, domain.ErrTrashEmpty)
+}
+
+func TestHandler_UndoRemoval(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	ctx := context.Background()
+
+	serve := func(favorites *service.FavoritesService, method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.NewHandler(favorites, log).SetupRoutes().ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	favorites := service.NewFavoritesService(repo, log, service.WithTrash(trash.NewStore(time.Hour, 10, nil)))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	assert.Equal(t, http.StatusOK, serve(favorites, http.MethodDelete, "/api/users/user1/favorites/insight1").Code)
+
+	rec := serve(favorites, http.MethodPost, "/api/users/user1/favorites/undo")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"asset_id":"insight1"`)
+	isFavorite, err := repo.IsFavorite("user1", "insight1")
+	require.NoError(t, err)
+	assert.True(t, isFavorite)
+
+	rec = serve(favorites, http.MethodPost, "/api/users/user1/favorites/undo")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "trash_empty")
+
+	// Without a trash, "undo" is taken for an asset ID
+	rec = serve(service.NewFavoritesService(repo, log), http.MethodPost, "/api/users/user1/favorites/undo")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "trash_empty")
+}
+
+func TestUserService_EraseUserEmptiesTrash(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	ctx := context.Background()
+
+	store := trash.NewStore(time.Hour, 0, nil)
+	favorites := service.NewFavoritesService(repo, log, service.WithTrash(store))
+	users := service.NewUserService(repo, log, service.WithUserTrash(store))
+	require.NoError(t, favorites.AddFavorite(ctx, "user1", domain.NewInsight("insight1", "Growth", "", nil, "")))
+	require.NoError(t, favorites.RemoveFavorite(ctx, "user1", "insight1"))
+
+	require.NoError(t, users.EraseUser(ctx, "user1"))
+	_, err := store.Pop("user1")
+	assert.ErrorIs(t, err, domain.ErrTrashEmpty)
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/usage"
+	"gwi-favorites-service/pkg/logger"
+	"gwi-favorites-service/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker_BoundsValues(t *testing.T) {
+	registry := metrics.NewRegistry()
+	tracker := usage.NewTracker(registry, 2)
+
+	tracker.Record("format", "json")
+	tracker.Record("format", "json")
+	tracker.Record("format", "jsonapi")
+	tracker.Record("format", "xml")
+	tracker.Record("format", "yaml")
+	tracker.Record("paging", "offset")
+
+	summary := tracker.Summary()
+	require.Len(t, summary, 2)
+	assert.Equal(t, "format", summary[0].Name)
+	assert.Equal(t, uint64(5), summary[0].Total)
+	assert.Equal(t, map[string]uint64{"json": 2, "jsonapi": 1, usage.OtherValue: 2}, summary[0].Values)
+	assert.Equal(t, "paging", summary[1].Name)
+
+	assert.Equal(t, uint64(2), registry.Counter("feature_usage_total", "", metrics.Labels{"feature": "format", "value": usage.OtherValue}).Value())
+
+	var disabled *usage.Tracker
+	disabled.Record("format", "json")
+}
+
+func TestHandler_UsageSummary(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	tracker := usage.NewTracker(metrics.NewRegistry(), 0)
+	routes := asAdmin(handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithUsageTracker(tracker),
+		handler.WithAdminAPIKey(testAdminKey),
+	).SetupRoutes())
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/users/user1/favorites", `{"id": "insight1", "type": "insight", "content": "Growth"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	do(http.MethodGet, "/api/users/user1/favorites?type=insight", "")
+	do(http.MethodGet, "/api/users/user1/favorites?type=bogus&offset=5", "")
+
+	rec = do(http.MethodGet, "/api/admin/usage", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response struct {
+		Data handler.UsageSummaryResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	features := make(map[string]map[string]uint64)
+	for _, feature := range response.Data.Features {
+		features[feature.Name] = feature.Values
+	}
+	assert.Equal(t, map[string]uint64{"insight": 1}, features["favorites.add.asset_type"])
+	assert.Equal(t, uint64(1), features["favorites.list.type_filter"]["insight"])
+	assert.False(t, response.Data.Since.IsZero())
+}
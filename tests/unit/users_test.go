@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/auth"
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/handler"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_UserManagement(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	h := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithUsers(service.NewUserService(repo, log)),
+	)
+	assert.Contains(t, h.Capabilities().Features, "user_management")
+	assert.NotContains(t, h.Capabilities().Features, "scim_provisioning")
+	routes := h.SetupRoutes()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(http.MethodPost, "/api/users", `{"id": "user1", "email": "ada@example.com", "name": "Ada"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/api/users/user1", rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/api/users", `{"id": "user1"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/api/users", `{"email": "x@example.com"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/api/users", `{"id": "user2", "email": "not an email"}`).Code)
+
+	// New users can hold favorites straight away
+	rec = send(http.MethodPost, "/api/users/user1/favorites", `{"id": "audience1", "type": "audience", "description": "Gamers"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodPut, "/api/users/user1", `{"email": "ada@example.org", "name": "Ada L."}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = send(http.MethodGet, "/api/users/user1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"email":"ada@example.org"`)
+	assert.Contains(t, rec.Body.String(), `"name":"Ada L."`)
+	count, err := repo.GetFavoriteCount("user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "updates keep the user's favorites")
+
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/api/users/missing", `{"name": "x"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/users/user1", `{"id": "user2"}`).Code)
+
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/users/user1", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/users/user1", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/api/users/user1", "").Code)
+}
+
+func TestHandler_UserManagementRequiresOwnership(t *testing.T) {
+	log := logger.NewLogger()
+	repo := memory.NewRepository()
+	users := service.NewUserService(repo, log)
+	require.NoError(t, users.CreateUser(context.Background(), domain.NewUser("user2", "", "")))
+	routes := handler.NewHandler(service.NewFavoritesService(repo, log), log,
+		handler.WithAuthenticator(auth.NewHMACVerifier("secret")),
+		handler.WithUsers(users),
+	).SetupRoutes()
+
+	send := func(method, path, body, subject string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		token, err := auth.SignHS256("secret", auth.Claims{Subject: subject})
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/api/users", `{"id": "user1"}`, "user1"))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/users", `{"id": "user3"}`, "user1"))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/users/user2", "", "user1"))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodDelete, "/api/users/user2", "", "user1"))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/users/user1", "", "user1"))
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gwi-favorites-service/internal/domain"
+	"gwi-favorites-service/internal/repository/memory"
+	"gwi-favorites-service/internal/service"
+	"gwi-favorites-service/internal/validation"
+	"gwi-favorites-service/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDValidator(t *testing.T) {
+	userRule, err := validation.ParseIDRule("uuid", 0)
+	require.NoError(t, err)
+	assetRule, err := validation.ParseIDRule(`^[a-z]+[0-9]*$`, 16)
+	require.NoError(t, err)
+	validator := &validation.IDValidator{User: userRule, Asset: assetRule}
+
+	assert.NoError(t, validator.ValidateUserID("3f2b8c1e-9d4a-4e6b-8c2d-1a2b3c4d5e6f"))
+	assert.Equal(t, domain.ErrInvalidUserID, validator.ValidateUserID("user1"))
+
+	assert.NoError(t, validator.ValidateAssetID("chart1"))
+	assert.Equal(t, domain.ErrInvalidAssetID, validator.ValidateAssetID("Chart1"))
+	assert.Equal(t, domain.ErrInvalidAssetID, validator.ValidateAssetID(strings.Repeat("a", 17)))
+
+	// Patterns match whole IDs even when written unanchored
+	partialRule, err := validation.ParseIDRule(`[a-z]+|[0-9]+`, 0)
+	require.NoError(t, err)
+	assert.True(t, partialRule.Valid("chart"))
+	assert.False(t, partialRule.Valid("chart1"))
+	assert.False(t, partialRule.Valid("../chart"))
+
+	// Without a pattern only length, emptiness and control characters are checked
+	anyRule, err := validation.ParseIDRule("", 0)
+	require.NoError(t, err)
+	assert.True(t, anyRule.Valid("user@example.com"))
+	assert.False(t, anyRule.Valid(""))
+	assert.False(t, anyRule.Valid("user\x00"))
+
+	_, err = validation.ParseIDRule("([", 0)
+	assert.Error(t, err)
+}
+
+func TestLengthLimits_Policies(t *testing.T) {
+	insight := domain.NewInsight("insight1", "ünïcödé content", "a long description", nil, "")
+
+	reject := validation.LengthLimits{Description: 6, Content: 5, Policy: validation.LengthReject}
+	assert.Equal(t, domain.ErrFieldTooLong, reject.ApplyToAsset(context.Background(), insight))
+
+	ctx, warnings := validation.WithWarnings(context.Background())
+	truncate := validation.LengthLimits{Description: 6, Content: 5, Policy: validation.LengthTruncate}
+	require.NoError(t, truncate.ApplyToAsset(ctx, insight))
+	assert.Equal(t, "a long", insight.Description)
+	assert.Equal(t, "ünïcö", insight.Content, "truncation counts characters, not bytes")
+	assert.Len(t, warnings.List(), 2)
+
+	// Unlimited fields are left alone
+	description, err := validation.LengthLimits{}.ApplyToDescription(ctx, "anything")
+	require.NoError(t, err)
+	assert.Equal(t, "anything", description)
+}
+
+func TestCoerceChartData(t *testing.T) {
+	chart := domain.NewChart("chart1", "Imported", "Month", "Revenue", "", []domain.ChartDataPoint{
+		{X: "2024-01-31", Y: " 1,234.5 "},
+		{X: "Feb 29, 2024", Y: "-2e3"},
+		{X: "2024-03-31T10:00:00+02:00", Y: 7.0},
+		{X: "Q2", Y: "n/a"},
+		{X: "0x10", Y: "NaN"},
+	})
+
+	ctx, warnings := validation.WithWarnings(context.Background())
+	validation.CoerceChartData(ctx, chart)
+
+	assert.Equal(t, []domain.ChartDataPoint{
+		{X: "2024-01-31T00:00:00Z", Y: 1234.5},
+		{X: "2024-02-29T00:00:00Z", Y: -2000.0},
+		{X: "2024-03-31T10:00:00+02:00", Y: 7.0},
+		{X: "Q2", Y: "n/a"},
+		{X: "0x10", Y: "NaN"},
+	}, chart.Data, "text that is neither a number nor a date is kept")
+	assert.Equal(t, []string{
+		"chart data: 2 x values converted to RFC 3339 dates",
+		"chart data: 2 y values converted from text to numbers",
+	}, warnings.List())
+}
+
+func TestFavoritesService_ChartCoercion(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.CreateUser(domain.NewUser("user1", "", "")))
+	favorites := service.NewFavoritesService(repo, logger.NewLogger(), service.WithChartCoercion(true))
+
+	chart := domain.NewChart("chart1", "Imported", "Month", "Revenue", "", []domain.ChartDataPoint{{X: "2024/01/31", Y: "42"}})
+	require.NoError(t, favorites.AddFavorite(context.Background(), "user1", chart))
+
+	stored, err := repo.GetAsset("chart1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ChartDataPoint{{X: "2024-01-31T00:00:00Z", Y: 42.0}}, stored.(*domain.Chart).Data)
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gwi-favorites-service/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_SignAndVerify(t *testing.T) {
+	payload := []byte(`{"event":"favorite.added","user_id":"user1","asset_id":"chart1"}`)
+	sentAt := time.Unix(1700000000, 0)
+
+	header := webhook.Sign("whsec_1", payload, sentAt)
+	assert.True(t, strings.HasPrefix(header, "t=1700000000,v1="))
+	assert.NoError(t, webhook.Verify("whsec_1", payload, header, 0, sentAt.Add(time.Minute)))
+
+	// Wrong secret, altered payload and missing header are rejected
+	assert.ErrorIs(t, webhook.Verify("whsec_2", payload, header, 0, sentAt), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("whsec_1", []byte(`{"event":"favorite.removed"}`), header, 0, sentAt), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("whsec_1", payload, "", 0, sentAt), webhook.ErrMissingSignature)
+	assert.ErrorIs(t, webhook.Verify("whsec_1", payload, "v1=abc", 0, sentAt), webhook.ErrInvalidSignature)
+
+	// Replays outside the tolerance are rejected
+	assert.ErrorIs(t, webhook.Verify("whsec_1", payload, header, time.Minute, sentAt.Add(2*time.Minute)), webhook.ErrExpiredSignature)
+
+	// During rotation a delivery signed with either secret verifies
+	rotated := header + "," + strings.SplitN(webhook.Sign("whsec_2", payload, sentAt), ",", 2)[1]
+	assert.NoError(t, webhook.Verify("whsec_2", payload, rotated, 0, sentAt))
+	assert.NoError(t, webhook.Verify("whsec_1", payload, rotated, 0, sentAt))
+}
+
+func TestWebhook_VerifyRequestKeepsBody(t *testing.T) {
+	payload := `{"event":"favorite.added"}`
+	now := time.Now()
+
+	req := httptest.NewRequest("POST", "/hooks/favorites", strings.NewReader(payload))
+	webhook.SignRequest(req, "whsec_1", []byte(payload), now)
+
+	require.NoError(t, webhook.VerifyRequest(req, "whsec_1", 0, now))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(body))
+}